@@ -62,9 +62,9 @@ EXAMPLES:
 
 func runAccessCmd(ctx *cli.Context) {
 	if !ctx.Args().Present() || ctx.Args().First() == "help" {
-		cli.ShowCommandHelpAndExit(ctx, "access", 1) // last argument is exit code
+		cli.ShowCommandHelpAndExit(ctx, "access", exitUsage) // last argument is exit code
 	}
-	if !isMcConfigExists() {
+	if !isMcConfigAvailable() {
 		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
 	}
 	config := mustGetMcConfig()
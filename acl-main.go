@@ -0,0 +1,100 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var aclCmd = cli.Command{
+	Name:   "acl",
+	Usage:  "Show access control lists of buckets and objects",
+	Action: runACLCmd,
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} get TARGET [TARGET...]
+
+DESCRIPTION:
+   Access control lists are shown as the canned ACL giving the same grants, like private or public-read, and
+   as custom if none does. Bucket ACLs are set with ‘mc access’, object ACLs when copying with ‘mc cp --acl’.
+
+EXAMPLES:
+   1. Show the ACL of a bucket on Amazon S3 object storage.
+      $ mc {{.Name}} get s3:website
+
+   2. Show ACLs of objects, as JSON for scripts.
+      $ mc --json {{.Name}} get s3:website/index.html s3:website/images/logo.png
+`,
+}
+
+// runACLCmd - is a handler for mc acl command
+func runACLCmd(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "acl", exitUsage) // last argument is exit code
+	}
+	if operation := ctx.Args().First(); operation != "get" {
+		console.Fatalf("Unknown operation ‘%s’, please choose from [get]. %s\n", operation, errInvalidArgument{})
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	config := mustGetMcConfig()
+	for _, arg := range ctx.Args().Tail() {
+		targetURL, err := getExpandedURL(arg, config.Aliases)
+		if err != nil {
+			switch e := iodine.ToError(err).(type) {
+			case errUnsupportedScheme:
+				console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+			default:
+				console.Fatalf("Unable to parse argument %s. %s\n", arg, err)
+			}
+		}
+		message, err := doGetACL(targetURL)
+		if err != nil {
+			console.Fatalf("Unable to get ACL of ‘%s’. %s\n", targetURL, err)
+		}
+		console.Print(message)
+	}
+}
+
+// doGetACL - canned ACL of the bucket or object of targetURL
+func doGetACL(targetURL string) (ACLMessage, error) {
+	message := ACLMessage{Target: targetURL}
+	clnt, err := target2Client(targetURL)
+	if err != nil {
+		return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	getter, ok := clnt.(client.ACLGetter)
+	if !ok {
+		return message, NewIodine(iodine.New(errACLNotSupported{URL: targetURL}, nil))
+	}
+	if _, object := url2BucketAndPrefix(clnt.URL()); object == "" {
+		message.ACL, err = getter.GetBucketACL()
+	} else {
+		message.ACL, err = getter.GetObjectACL()
+	}
+	if err != nil {
+		return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	return message, nil
+}
@@ -81,3 +81,16 @@ func (b bucketACL) isPublic() bool {
 func (b bucketACL) isAuthenticated() bool {
 	return b == bucketAuthenticated
 }
+
+// objectACLs - canned ACLs which can be set on copied objects
+var objectACLs = []string{"private", "public-read", "public-read-write", "authenticated-read", "bucket-owner-read", "bucket-owner-full-control"}
+
+// isValidObjectACL - is acl a canned ACL of objects
+func isValidObjectACL(acl string) bool {
+	for _, objectACL := range objectACLs {
+		if acl == objectACL {
+			return true
+		}
+	}
+	return false
+}
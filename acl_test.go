@@ -0,0 +1,67 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/minio/mc/pkg/fakes3"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestACL(c *C) {
+	server := fakes3.NewServer("website")
+	defer server.Close()
+	server.PutObject("website", "index.html", []byte("<html></html>"))
+	server.SetDocument("/website", "acl", []byte(`<AccessControlPolicy><Owner><ID>owner</ID></Owner><AccessControlList>`+
+		`<Grant><Grantee><ID>owner</ID></Grantee><Permission>FULL_CONTROL</Permission></Grant>`+
+		`<Grant><Grantee><URI>http://acs.amazonaws.com/groups/global/AllUsers</URI></Grantee><Permission>READ</Permission></Grant>`+
+		`</AccessControlList></AccessControlPolicy>`))
+
+	target := server.URL + "/website/index.html"
+	message, err := doGetACL(target)
+	c.Assert(err, IsNil)
+	c.Assert(message.String(), Equals, target+"  private\n")
+	c.Assert(setTargetACL(target, "public-read"), IsNil)
+	message, err = doGetACL(target)
+	c.Assert(err, IsNil)
+	c.Assert(message.ACL, Equals, "public-read")
+	message, err = doGetACL(server.URL + "/website")
+	c.Assert(err, IsNil)
+	c.Assert(message.ACL, Equals, "public-read")
+
+	// refused ACLs are not kept
+	server.AddFault(fakes3.Fault{Method: "PUT", Subresource: "acl", Status: http.StatusForbidden, Code: "AccessDenied"})
+	exitCode, _ := getErrorCode(setTargetACL(target, "private"))
+	c.Assert(exitCode, Equals, exitPermission)
+	message, err = doGetACL(target)
+	c.Assert(err, IsNil)
+	c.Assert(message.ACL, Equals, "public-read")
+
+	c.Assert(isValidObjectACL("bucket-owner-full-control"), Equals, true)
+	c.Assert(isValidObjectACL("public"), Equals, false)
+
+	// ACLs cannot be set on local files either
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	err = setTargetACL(root, "public-read")
+	c.Assert(iodine.ToError(err), FitsTypeOf, errACLNotSupported{})
+}
@@ -0,0 +1,121 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var adminCmd = cli.Command{
+	Name:   "admin",
+	Usage:  "Manage Minio servers",
+	Action: runAdminCmd,
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} info TARGET
+
+DESCRIPTION:
+   Info reports the mode, buckets, objects and usage of the Minio deployment of TARGET, followed by a table of
+   its servers with their state, uptime, version, disks in use, disks being healed and disk usage. TARGET is
+   an alias or URL of a server, the keys of its alias must be of an admin user.
+
+EXAMPLES:
+   1. Show the status of a Minio deployment.
+      $ mc {{.Name}} info https://play.minio.io:9000
+
+   2. Check a deployment from a monitoring script.
+      $ mc --json {{.Name}} info myminio
+
+`,
+}
+
+// runAdminCmd - is a handler for mc admin command
+func runAdminCmd(ctx *cli.Context) {
+	if len(ctx.Args()) != 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "admin", exitUsage) // last argument is exit code
+	}
+	operation, arg := ctx.Args().First(), ctx.Args().Get(1)
+	if operation != "info" {
+		console.Fatalf("Unknown operation ‘%s’, please choose from [info]. %s\n", operation, errInvalidArgument{})
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	targetURL, err := getExpandedURL(arg, mustGetMcConfig().Aliases)
+	if err != nil {
+		switch e := iodine.ToError(err).(type) {
+		case errUnsupportedScheme:
+			console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+		default:
+			console.Fatalf("Unable to parse argument %s. %s\n", arg, err)
+		}
+	}
+	message, err := doAdminInfo(targetURL)
+	if err != nil {
+		console.Fatalf("Unable to get server info of ‘%s’. %s\n", targetURL, err)
+	}
+	console.Print(message)
+}
+
+// doAdminInfo - status of the Minio deployment of targetURL
+func doAdminInfo(targetURL string) (AdminInfoMessage, error) {
+	// targets are usually aliases of the server alone, like https://play.minio.io:9000
+	clientURL := targetURL
+	if u, err := client.Parse(targetURL); err == nil && u.Type == client.Object && u.Path == "" {
+		clientURL += "/"
+	}
+	clnt, err := target2Client(clientURL)
+	if err != nil {
+		return AdminInfoMessage{}, NewIodine(iodine.New(err, nil))
+	}
+	getter, ok := clnt.(client.ServerInfoGetter)
+	if !ok {
+		return AdminInfoMessage{}, NewIodine(iodine.New(errAdminNotSupported{URL: targetURL}, nil))
+	}
+	info, err := getter.GetServerInfo()
+	if _, ok := iodine.ToError(err).(client.APINotImplemented); ok {
+		return AdminInfoMessage{}, NewIodine(iodine.New(errAdminNotSupported{URL: targetURL}, nil))
+	}
+	if err != nil {
+		return AdminInfoMessage{}, NewIodine(iodine.New(err, nil))
+	}
+	return AdminInfoMessage{Target: targetURL, ServerInfo: *info}, nil
+}
+
+// formatUptime - uptime in its two largest units, like "3d 4h"
+func formatUptime(seconds int64) string {
+	d := time.Duration(seconds) * time.Second
+	days := int64(d / (24 * time.Hour))
+	hours := int64(d/time.Hour) % 24
+	minutes := int64(d/time.Minute) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
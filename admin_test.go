@@ -0,0 +1,67 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/mc/pkg/fakes3"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestAdminInfoMessage(c *C) {
+	c.Assert(formatUptime(93600), Equals, "1d 2h")
+	c.Assert(formatUptime(3900), Equals, "1h 5m")
+	c.Assert(formatUptime(59), Equals, "0m")
+
+	message := AdminInfoMessage{Target: "https://play.minio.io:9000", ServerInfo: client.ServerInfo{
+		Mode:    "online",
+		Buckets: 2,
+		Objects: 1500,
+		Usage:   1024,
+		Nodes: []client.NodeInfo{
+			{Endpoint: "minio1:9000", State: "online", Version: "2023-01-01T00:00:00Z", Uptime: 93600, Disks: []client.DiskInfo{
+				{Path: "/data1", State: "ok", Total: 2048, Used: 1024},
+				{Path: "/data2", State: "ok", Total: 2048, Healing: true},
+			}},
+			{Endpoint: "minio2:9000", State: "offline"},
+		},
+	}}
+	lines := strings.Split(message.String(), "\n")
+	c.Assert(lines[0], Equals, "‘https://play.minio.io:9000’ is online with 2 buckets and 1,500 objects of 1.0KiB.")
+	c.Assert(lines[1], Equals, "Disks use 1.0KiB of 4.0KiB, 1 disks healing.")
+	c.Assert(strings.Fields(lines[4]), DeepEquals, []string{"minio1:9000", "online", "1d", "2h", "2023-01-01T00:00:00Z", "2/2", "1", "1.0KiB", "/", "4.0KiB"})
+	c.Assert(strings.Fields(lines[5]), DeepEquals, []string{"minio2:9000", "offline", "-", "-", "0/0", "0", "0B", "/", "0B"})
+}
+
+func (s *CmdTestSuite) TestAdminInfo(c *C) {
+	// plain S3 servers reply NoSuchBucket for the admin API
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+	_, err := doAdminInfo(server.URL)
+	_, ok := iodine.ToError(err).(errAdminNotSupported)
+	c.Assert(ok, Equals, true)
+
+	// servers without the admin API are told from servers refusing it
+	server.AddFault(fakes3.Fault{Path: "/minio/admin", Status: http.StatusForbidden, Code: "AccessDenied"})
+	_, err = doAdminInfo(server.URL + "/")
+	c.Assert(s3.ErrorCode(err), Equals, "AccessDenied")
+}
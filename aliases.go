@@ -33,6 +33,8 @@ func isValidAliasName(aliasName string) bool {
 	switch aliasName {
 	case "help":
 		fallthrough
+	case "file": // file: addresses local paths
+		fallthrough
 	case "private":
 		fallthrough
 	case "read-only":
@@ -45,36 +47,32 @@ func isValidAliasName(aliasName string) bool {
 	return validAliasName.MatchString(aliasName)
 }
 
-// aliasExpand expands aliased (name:/path) to full URL, used by url-parser
+// aliasExpand expands aliased (name:/path) to full URL, used by url-parser. Aliases may point below a bucket,
+// the path is joined to the alias URL with exactly one slash and may contain further colons
 func aliasExpand(aliasedURL string, aliases map[string]string) (newURL string, err error) {
 	u, err := client.Parse(aliasedURL)
 	if err != nil {
 		return aliasedURL, iodine.New(errInvalidURL{URL: aliasedURL}, nil)
 	}
-	// proper URL
-	if u.Host != "" {
+	// proper URL or explicit local path
+	if u.Host != "" || strings.HasPrefix(aliasedURL, "file:") {
+		return aliasedURL, nil
+	}
+	i := strings.Index(aliasedURL, ":")
+	if i < 0 {
 		return aliasedURL, nil
 	}
-	for aliasName, expandedURL := range aliases {
-		if strings.HasPrefix(aliasedURL, aliasName+":") {
-			// Match found. Expand it.
-			splits := strings.Split(aliasedURL, ":")
-			// if expandedURL is missing, return aliasedURL treat it like fs
-			if expandedURL == "" {
-				return aliasedURL, nil
-			}
-			// if more splits found return
-			if len(splits) == 2 {
-				// remove any prefixed slashes
-				trimmedURL := expandedURL + "/" + strings.TrimPrefix(strings.TrimPrefix(splits[1], "/"), "\\")
-				u, err := client.Parse(trimmedURL)
-				if err != nil {
-					return aliasedURL, iodine.New(errInvalidURL{URL: aliasedURL}, nil)
-				}
-				return u.String(), nil
-			}
-			return aliasedURL, nil
-		}
+	expandedURL, ok := aliases[aliasedURL[:i]]
+	// if expandedURL is missing, return aliasedURL treat it like fs
+	if !ok || expandedURL == "" {
+		return aliasedURL, nil
+	}
+	// remove any prefixed slashes
+	path := strings.TrimLeft(aliasedURL[i+1:], "/\\")
+	joinedURL := strings.TrimSuffix(expandedURL, "/") + "/" + path
+	u, err = client.Parse(joinedURL)
+	if err != nil {
+		return aliasedURL, iodine.New(errInvalidURL{URL: aliasedURL}, nil)
 	}
-	return aliasedURL, nil
+	return u.String(), nil
 }
@@ -0,0 +1,109 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// attrDefault - rule applied to all files, extension rules override its attributes
+const attrDefault = "*"
+
+// attrRules - object attributes by file extension, loaded from an --attr-file mapping like
+//
+//	{
+//	  "*":     {"Cache-Control": "max-age=3600"},
+//	  ".html": {"Content-Type": "text/html; charset=utf-8", "Cache-Control": "no-cache"},
+//	  ".css":  {"Content-Type": "text/css"}
+//	}
+type attrRules map[string]map[string]string
+
+// isValidAttr - attributes which can be set on upload
+func isValidAttr(key string) bool {
+	switch key {
+	case "Content-Type", "Cache-Control":
+		return true
+	}
+	return false
+}
+
+// loadAttrRules - read extension mapping file, extensions are matched case insensitively
+func loadAttrRules(filename string) (attrRules, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	var mapping map[string]map[string]string
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, NewIodine(iodine.New(err, map[string]string{"File": filename}))
+	}
+	rules := make(attrRules)
+	for ext, attrs := range mapping {
+		if ext != attrDefault {
+			ext = "." + strings.TrimPrefix(strings.ToLower(ext), ".")
+		}
+		rule := make(map[string]string)
+		for key, value := range attrs {
+			key = http.CanonicalHeaderKey(key)
+			if !isValidAttr(key) {
+				return nil, NewIodine(iodine.New(errInvalidArgument{}, map[string]string{"Extension": ext, "Attribute": key}))
+			}
+			rule[key] = value
+		}
+		rules[ext] = rule
+	}
+	return rules, nil
+}
+
+// lookup - attributes for an object name, nil if no rule matches
+func (r attrRules) lookup(name string) map[string]string {
+	if len(r) == 0 {
+		return nil
+	}
+	attrs := make(map[string]string)
+	for key, value := range r[attrDefault] {
+		attrs[key] = value
+	}
+	for key, value := range r[strings.ToLower(path.Ext(name))] {
+		attrs[key] = value
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+// mergeAttrs - attributes of base overridden by those of rules, nil if both are empty
+func mergeAttrs(base, rules map[string]string) map[string]string {
+	if len(base) == 0 {
+		return rules
+	}
+	attrs := make(map[string]string)
+	for key, value := range base {
+		attrs[key] = value
+	}
+	for key, value := range rules {
+		attrs[key] = value
+	}
+	return attrs
+}
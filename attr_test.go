@@ -0,0 +1,65 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestAttrRules(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	attrFile := filepath.Join(root, "attrs.json")
+	err = ioutil.WriteFile(attrFile, []byte(`{
+		"*":    {"cache-control": "max-age=3600"},
+		"HTML": {"Content-Type": "text/html", "Cache-Control": "no-cache"},
+		".css": {"Content-Type": "text/css"}
+	}`), 0600)
+	c.Assert(err, IsNil)
+
+	attrs, err := loadAttrRules(attrFile)
+	c.Assert(err, IsNil)
+	c.Assert(attrs.lookup("https://s3.amazonaws.com/www/index.HTML"), DeepEquals,
+		map[string]string{"Content-Type": "text/html", "Cache-Control": "no-cache"})
+	c.Assert(attrs.lookup("public/style.css"), DeepEquals,
+		map[string]string{"Content-Type": "text/css", "Cache-Control": "max-age=3600"})
+	c.Assert(attrs.lookup("public/logo.png"), DeepEquals, map[string]string{"Cache-Control": "max-age=3600"})
+	c.Assert(attrRules(nil).lookup("public/logo.png"), IsNil)
+
+	err = ioutil.WriteFile(attrFile, []byte(`{".html": {"Content-Encoding": "gzip"}}`), 0600)
+	c.Assert(err, IsNil)
+	_, err = loadAttrRules(attrFile)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errInvalidArgument{})
+
+	_, err = loadAttrRules(filepath.Join(root, "missing.json"))
+	c.Assert(err, Not(IsNil))
+
+	// targets which cannot store metadata get the data alone
+	target := filepath.Join(root, "index.html")
+	c.Assert(putTargetWithMetadata(target, 5, bytes.NewReader([]byte("hello")), attrs.lookup(target)), IsNil)
+	data, err := ioutil.ReadFile(target)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "hello")
+}
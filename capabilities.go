@@ -0,0 +1,116 @@
+/*
+ * Minio Client, (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+)
+
+// URL schemes understood by client.Parse, everything else is a filesystem path. Schemes
+// registered with client.Register are reported after these
+var supportedSchemes = []string{"http", "https", "sftp", "ftp", "ftps", "ftpes", "webdav", "webdavs", "hdfs", "webhdfs", "swebhdfs", "tar"}
+
+// flagCapability - name and value type of a flag
+type flagCapability struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+	Type    string   `json:"type"`
+}
+
+// commandCapability - a command and its command specific flags
+type commandCapability struct {
+	Name  string           `json:"name"`
+	Flags []flagCapability `json:"flags"`
+}
+
+// getFlagCapability - flags only expose their name through String(), so look into known flag types
+func getFlagCapability(flag cli.Flag) flagCapability {
+	var name, flagType string
+	switch f := flag.(type) {
+	case cli.BoolFlag:
+		name, flagType = f.Name, "bool"
+	case cli.BoolTFlag:
+		name, flagType = f.Name, "bool"
+	case cli.StringFlag:
+		name, flagType = f.Name, "string"
+	case cli.StringSliceFlag:
+		name, flagType = f.Name, "string-slice"
+	case cli.IntFlag:
+		name, flagType = f.Name, "int"
+	case cli.IntSliceFlag:
+		name, flagType = f.Name, "int-slice"
+	case cli.Float64Flag:
+		name, flagType = f.Name, "float"
+	case cli.DurationFlag:
+		name, flagType = f.Name, "duration"
+	case cli.GenericFlag:
+		name, flagType = f.Name, "generic"
+		// generic flags going without a value like --debug are bool flags to callers
+		if value, ok := f.Value.(interface {
+			IsBoolFlag() bool
+		}); ok && value.IsBoolFlag() {
+			flagType = "bool"
+		}
+	default:
+		name, flagType = strings.Fields(flag.String())[0], "unknown"
+	}
+	// Name is of the form "name, alias"
+	names := strings.Split(name, ",")
+	capability := flagCapability{Name: strings.TrimSpace(names[0]), Type: flagType}
+	for _, alias := range names[1:] {
+		capability.Aliases = append(capability.Aliases, strings.TrimSpace(alias))
+	}
+	return capability
+}
+
+// getCapabilities - describe this build for wrapper tools to feature detect
+func getCapabilities() CapabilitiesMessage {
+	capabilities := CapabilitiesMessage{
+		Release:     Version,
+		Tag:         Tag,
+		CommitID:    CommitID,
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		Schemes:     append(append([]string{}, supportedSchemes...), client.Schemes()...),
+		APIs:        hostAPIs,
+		GlobalFlags: []flagCapability{},
+		Commands:    []commandCapability{},
+	}
+	for _, flag := range flags {
+		capabilities.GlobalFlags = append(capabilities.GlobalFlags, getFlagCapability(flag))
+	}
+	for _, cmd := range commands {
+		command := commandCapability{Name: cmd.Name, Flags: []flagCapability{}}
+		for _, flag := range cmd.Flags {
+			command.Flags = append(command.Flags, getFlagCapability(flag))
+		}
+		capabilities.Commands = append(capabilities.Commands, command)
+	}
+	sort.Sort(byCommandName(capabilities.Commands))
+	return capabilities
+}
+
+type byCommandName []commandCapability
+
+func (b byCommandName) Len() int           { return len(b) }
+func (b byCommandName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byCommandName) Less(i, j int) bool { return b[i].Name < b[j].Name }
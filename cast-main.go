@@ -17,16 +17,14 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
-	"fmt"
-	"io"
-	"math"
 	"os"
-	"runtime"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/minio/pkg/iodine"
 )
@@ -36,6 +34,7 @@ var castCmd = cli.Command{
 	Name:   "cast",
 	Usage:  "Copy files and folders from a single source to many destinations",
 	Action: runCastCmd,
+	Flags:  []cli.Flag{lockFlag, namePolicyFlag, windowsNamesFlag, attrFileFlag, noSniffFlag, planFlag, yesFlag, noResumeFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
@@ -65,11 +64,77 @@ EXAMPLES:
    5. Cast a local directory of non english character recursively to Amazon s3 object storage and Minio object storage.
       $ mc {{.Name}} 本語/... s3:mylocaldocuments play:backup
 
+   6. Cast a local folder recursively from a cron job, skipping the run if another mc process is copying to any of the targets.
+      $ mc {{.Name}} --lock backup/... https://play.minio.io:9000/archive https://s3.amazonaws.com/archive
+
+   7. Publish a static site to two buckets, setting content type and cache control by file extension.
+      $ mc {{.Name}} --attr-file site-attrs.json public/... s3:www.example.com play:www
+
+   8. Preview where a folder would land on two buckets before casting it.
+      $ mc {{.Name}} --plan backup/... s3:backup play:backup
+
 `,
 }
 
+// recordCast - one operation record per target for log files, putTargets may fail for some of them only
+func recordCast(sourceURL string, targetURLs []string, length int64, duration time.Duration, err error) {
+	failed := make(map[string]error)
+	if e, ok := iodine.ToError(err).(errTargetsFailed); ok {
+		for i := range e.URLs {
+			failed[e.URLs[i]] = e.Errors[i]
+		}
+	}
+	for _, targetURL := range targetURLs {
+		targetErr, ok := failed[targetURL]
+		if !ok && len(failed) == 0 {
+			targetErr = err
+		}
+		console.RecordOperation(console.Operation{
+			Command:  "cast",
+			Source:   sourceURL,
+			Target:   targetURL,
+			Bytes:    length,
+			Duration: duration,
+			Err:      targetErr,
+		})
+	}
+}
+
+// pendingCastTargets - leave out the planned targets of sURLs which were cast before, done is sorted
+func pendingCastTargets(sURLs *castURLs, done []int) {
+	var targetContents []*client.Content
+	sURLs.TargetIndices = nil
+	for i, targetContent := range sURLs.TargetContents {
+		if j := sort.SearchInts(done, i); j < len(done) && done[j] == i {
+			continue
+		}
+		targetContents = append(targetContents, targetContent)
+		sURLs.TargetIndices = append(sURLs.TargetIndices, i)
+	}
+	sURLs.TargetContents = targetContents
+}
+
+// castTargetsDone - planned targets of a failed cast which were written all the same
+func castTargetsDone(cURLs castURLs) []int {
+	e, ok := iodine.ToError(cURLs.Error).(errTargetsFailed)
+	if !ok {
+		return nil
+	}
+	failed := make(map[string]bool)
+	for _, targetURL := range e.URLs {
+		failed[targetURL] = true
+	}
+	var done []int
+	for i, targetContent := range cURLs.TargetContents {
+		if !failed[targetContent.Name] && i < len(cURLs.TargetIndices) {
+			done = append(done, cURLs.TargetIndices[i])
+		}
+	}
+	return done
+}
+
 // doCast - Cast an object to multiple destination. castURLs status contains a copy of sURLs and error if any.
-func doCast(sURLs castURLs, bar *barSend, castQueueCh <-chan bool, wg *sync.WaitGroup, statusCh chan<- castURLs) {
+func doCast(sURLs castURLs, attrs attrRules, sniff bool, bar *barSend, castQueueCh <-chan bool, wg *sync.WaitGroup, statusCh chan<- castURLs) {
 	defer wg.Done() // Notify that this copy routine is done.
 	defer func() {
 		<-castQueueCh
@@ -81,41 +146,43 @@ func doCast(sURLs castURLs, bar *barSend, castQueueCh <-chan bool, wg *sync.Wait
 		return
 	}
 
-	if !globalQuietFlag || !globalJSONFlag {
+	if showProgressBar() {
 		bar.SetCaption(sURLs.SourceContent.Name + ": ")
 	}
 
+	var targetURLs []string
+	for _, targetContent := range sURLs.TargetContents {
+		targetURLs = append(targetURLs, targetContent.Name)
+	}
+
+	start := time.Now()
 	reader, length, err := getSource(sURLs.SourceContent.Name)
 	if err != nil {
-		if !globalQuietFlag || !globalJSONFlag {
+		if showProgressBar() {
 			bar.ErrorGet(int64(length))
 		}
+		recordCast(sURLs.SourceContent.Name, targetURLs, length, time.Since(start), err)
 		sURLs.Error = iodine.New(err, nil)
 		statusCh <- sURLs
 		return
 	}
 
-	var targetURLs []string
-	for _, targetContent := range sURLs.TargetContents {
-		targetURLs = append(targetURLs, targetContent.Name)
-	}
-
-	var newReader io.ReadCloser
-	if globalQuietFlag || globalJSONFlag {
+	newReader := reader
+	if showProgressBar() { // set up progress
+		newReader = bar.NewProxyReader(reader)
+	} else if !globalQuietFlag {
 		console.PrintC(CastMessage{
 			Source:  sURLs.SourceContent.Name,
 			Targets: targetURLs,
 		})
-		newReader = reader
-	} else {
-		// set up progress
-		newReader = bar.NewProxyReader(reader)
 	}
 	defer newReader.Close()
 
-	err = putTargets(targetURLs, length, newReader)
+	putReader, metadata := withContentType(sURLs.SourceContent.Name, newReader, attrs.lookup(sURLs.SourceContent.Name), sniff)
+	err = putTargets(targetURLs, length, putReader, metadata)
+	recordCast(sURLs.SourceContent.Name, targetURLs, length, time.Since(start), err)
 	if err != nil {
-		if !globalQuietFlag || !globalJSONFlag {
+		if showProgressBar() {
 			bar.ErrorPut(int64(length))
 		}
 		sURLs.Error = iodine.New(err, nil)
@@ -127,27 +194,16 @@ func doCast(sURLs castURLs, bar *barSend, castQueueCh <-chan bool, wg *sync.Wait
 	statusCh <- sURLs
 }
 
-// doCastFake - Perform a fake cast to update the progress bar appropriately.
-func doCastFake(sURLs castURLs, bar *barSend) (err error) {
-	if !globalDebugFlag || !globalJSONFlag {
-		bar.Progress(sURLs.SourceContent.Size)
-	}
-	return nil
-}
-
-// doPrepareCastURLs scans the source URL and prepares a list of objects for casting.
-func doPrepareCastURLs(session *sessionV2, trapCh <-chan bool) {
-	sourceURL := session.Header.CommandArgs[0] // first one is source.
-	targetURLs := session.Header.CommandArgs[1:]
-	var totalBytes int64
-	var totalObjects int
-
-	// Create a session data file to store the processed URLs.
-	dataFP := session.NewDataWriter()
+// planCastURLs scans the source URL of header and calls found for every object cast would copy, with the target
+// name rules of header applied. abort is called before exiting on fatal errors and interrupts.
+func planCastURLs(header *sessionV2Header, trapCh <-chan bool, abort func(), found func(castURLs)) {
+	sourceURL := header.CommandArgs[0] // first one is source.
+	targetURLs := header.CommandArgs[1:]
 
-	scanBar := scanBarFactory(sourceURL)
 	URLsCh := prepareCastURLs(sourceURL, targetURLs)
 	done := false
+	// target URLs planned so far, for the name policy to keep them unique.
+	planned := make(map[string]bool)
 	for done == false {
 		select {
 		case sURLs, ok := <-URLsCh:
@@ -156,52 +212,115 @@ func doPrepareCastURLs(session *sessionV2, trapCh <-chan bool) {
 				break
 			}
 			if sURLs.Error != nil {
+				console.Logf(console.LogPlanner, console.LogWarn, "Skipping source. %s\n", sURLs.Error)
 				console.Errorln(sURLs.Error)
 				break
 			}
-			jsonData, err := json.Marshal(sURLs)
-			if err != nil {
-				session.Close()
-				console.Fatalf("Unable to marshal URLs to JSON. %s\n", err)
+			var targetContents []*client.Content
+			for i, targetContent := range sURLs.TargetContents {
+				var baseTargetURL string
+				if i < len(targetURLs) {
+					baseTargetURL = targetURLs[i]
+				}
+				newTargetURL, err := applyNamePolicy(sURLs.SourceContent.Name, targetContent.Name, baseTargetURL,
+					objectNamePolicy(header.NamePolicy), header.WindowsNames, planned)
+				if err != nil {
+					abort()
+					console.Fatalf("Target name validation failed for ‘%s’. %s\n", sURLs.SourceContent.Name, err)
+				}
+				if newTargetURL == "" { // Skipped by name policy.
+					console.Logf(console.LogPlanner, console.LogDebug, "Skipping ‘%s’, rejected by name policy.\n", targetContent.Name)
+					continue
+				}
+				targetContent.Name = newTargetURL
+				targetContents = append(targetContents, targetContent)
 			}
-			fmt.Fprintln(dataFP, string(jsonData))
-			scanBar(sURLs.SourceContent.Name)
-
-			totalBytes += sURLs.SourceContent.Size
-			totalObjects++
+			if len(targetContents) == 0 {
+				break
+			}
+			sURLs.TargetContents = targetContents
+			console.Logf(console.LogPlanner, console.LogDebug, "Planned ‘%s’ -> %d targets.\n", sURLs.SourceContent.Name, len(targetContents))
+			found(sURLs)
 		case <-trapCh:
-			session.Close() // If we are interrupted during the URL scanning, we drop the session.
+			abort()
 			os.Exit(0)
 		}
 	}
+}
+
+// doPrepareCastURLs scans the source URL and prepares a list of objects for casting.
+func doPrepareCastURLs(session *sessionV2, trapCh <-chan bool) {
+	var totalBytes int64
+	var totalObjects int
+
+	// Create a session data file to store the processed URLs.
+	dataWriter, err := session.NewDataWriter()
+	if err != nil {
+		session.Close()
+		console.Fatalf("Unable to create session data file. %s\n", err)
+	}
+	scanBar := scanBarFactory(session.Header.CommandArgs[0])
+	// If we are interrupted during the URL scanning, we drop the session.
+	abort := func() { session.Close() }
+	planCastURLs(session.Header, trapCh, abort, func(sURLs castURLs) {
+		if err := dataWriter.Append(sURLs); err != nil {
+			session.Close()
+			console.Fatalf("Unable to write URLs to session data file. %s\n", err)
+		}
+		scanBar(sURLs.SourceContent.Name)
+
+		totalBytes += sURLs.SourceContent.Size
+		totalObjects++
+	})
 	session.Header.TotalBytes = totalBytes
 	session.Header.TotalObjects = totalObjects
+	console.Logf(console.LogPlanner, console.LogInfo, "Planned %d objects, %d bytes.\n", totalObjects, totalBytes)
+	if err := dataWriter.Commit(); err != nil {
+		session.Close()
+		console.Fatalf("Unable to save session data file. %s\n", err)
+	}
 	session.Save()
 }
 
 func doCastCmdSession(session *sessionV2) {
 	trapCh := signalTrap(os.Interrupt, os.Kill)
 
+	if session.Header.TargetLock {
+		targetURLs := session.Header.CommandArgs[1:]
+		locks, err := acquireTargetLocks(targetURLs)
+		if err != nil {
+			if !session.HasData() { // Keep resumable sessions around for a later attempt.
+				session.Close()
+			}
+			console.Fatalf("Unable to lock targets ‘%s’. %s\n", targetURLs, NewIodine(iodine.New(err, nil)))
+		}
+		defer releaseTargetLocks(locks)
+	}
+
+	pauseCh := pauseTrap()
+	if err := session.Start(); err != nil {
+		console.Fatalf("Unable to save session ‘%s’. %s\n", session.SessionID, err)
+	}
+
 	if !session.HasData() {
 		doPrepareCastURLs(session, trapCh)
 	}
 
-	// Set up progress bar.
+	// Set up progress bar. One bar counts the bytes read from sources, progress of single targets is not shown.
 	var bar barSend
-	if !globalQuietFlag || !globalJSONFlag {
+	if showProgressBar() {
 		bar = newCpBar()
 		bar.Extend(session.Header.TotalBytes)
+		// entries finished before a resume are not cast again, count them right away
+		bar.Progress(session.Header.CopiedBytes)
 	}
 
 	// Prepare URL scanner from session data file.
-	scanner := bufio.NewScanner(session.NewDataReader())
-	// isCopied returns true if an object has been already copied
-	// or not. This is useful when we resume from a session.
-	isCopied := isCopiedFactory(session.Header.LastCopied)
+	scanner := session.NewDataScanner()
 
 	wg := new(sync.WaitGroup)
 	// Limit numner of cast routines based on available CPU resources.
-	castQueue := make(chan bool, int(math.Max(float64(runtime.NumCPU())-1, 1)))
+	castQueue := make(chan bool, getParallel(session.Header.CommandArgs...))
 	defer close(castQueue)
 	// Status channel for receiveing cast return status.
 	statusCh := make(chan castURLs)
@@ -214,59 +333,104 @@ func doCastCmdSession(session *sessionV2) {
 			select {
 			case cURLs, ok := <-statusCh: // Receive status.
 				if !ok { // We are done here. Top level function has returned.
-					bar.Finish()
+					if showProgressBar() {
+						bar.Finish()
+					}
 					return
 				}
 				if cURLs.Error == nil {
-					session.Header.LastCopied = cURLs.SourceContent.Name
-				} else {
+					session.Done(cURLs.SourceContent.Name, cURLs.SourceContent.Size)
+				} else { // failed casts are retried on resume, to the failed targets only
+					session.TargetsDone(cURLs.SourceContent.Name, castTargetsDone(cURLs))
+					session.Failed(cURLs.SourceContent.Name)
 					console.Errorf("Failed to cast ‘%s’, %s\n", cURLs.SourceContent.Name, NewIodine(cURLs.Error))
 				}
+				if err := session.Checkpoint(); err != nil {
+					console.Errorf("Unable to save session ‘%s’. %s\n", session.SessionID, err)
+				}
 			case <-trapCh: // Receive interrupt notification.
-				session.Save()
-				session.Info()
+				session.Terminate()
 				os.Exit(0)
 			}
 		}
 	}()
 
 	// Go routine to perform concurrently casting.
+	paused := false
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		castWg := new(sync.WaitGroup)
 		defer close(statusCh)
 
-		for scanner.Scan() {
+		for index := 0; scanner.Scan(); index++ {
+			if session.IsDone(index) {
+				continue
+			}
 			var sURLs castURLs
 			json.Unmarshal([]byte(scanner.Text()), &sURLs)
-			if isCopied(sURLs.SourceContent.Name) {
-				doCastFake(sURLs, &bar)
-			} else {
-				// Wait for other cast routines to
-				// complete. We only have limited CPU
-				// and network resources.
-				castQueue <- true
-				// Account for each cast routines we start.
-				castWg.Add(1)
-				// Do casting in background concurrently.
-				go doCast(sURLs, &bar, castQueue, castWg, statusCh)
+			pendingCastTargets(&sURLs, session.DoneTargets(index))
+			// Wait for other cast routines to
+			// complete. We only have limited CPU
+			// and network resources.
+			select {
+			case castQueue <- true:
+			case <-pauseCh: // Let running casts finish, the rest is left for resume.
+				paused = true
+				castWg.Wait()
+				return
 			}
+			// Account for each cast routines we start.
+			castWg.Add(1)
+			session.Dispatch(index, sURLs.SourceContent.Name)
+			// Do casting in background concurrently.
+			go doCast(sURLs, session.Header.Attrs, !session.Header.NoSniff, &bar, castQueue, castWg, statusCh)
 		}
 		castWg.Wait()
 	}()
 
 	wg.Wait()
+	if paused {
+		session.Pause()
+		os.Exit(0)
+	}
+	if err := scanner.Err(); err != nil {
+		session.Terminate()
+		console.Fatalf("Unable to read session data. %s\n", err)
+	}
+	if globalQuietFlag { // per object output was left out
+		console.PrintC(session.Summary())
+	}
 }
 
 func runCastCmd(ctx *cli.Context) {
 	checkCastSyntax(ctx)
 
+	namePolicy := objectNamePolicy(ctx.String("name-policy"))
+	if !namePolicy.isValid() {
+		console.Fatalf("Valid name policies are [fail, skip, sanitize]. %s\n", errInvalidArgument{})
+	}
+
+	var attrs attrRules
+	if ctx.String("attr-file") != "" {
+		var err error
+		attrs, err = loadAttrRules(ctx.String("attr-file"))
+		if err != nil {
+			console.Fatalf("Unable to load attributes from ‘%s’. %s\n", ctx.String("attr-file"), err)
+		}
+	}
+
+	expireSessions()
 	session := newSessionV2()
 	defer session.Close()
 
 	var err error
 	session.Header.CommandType = "cast"
+	session.Header.TargetLock = ctx.Bool("lock")
+	session.Header.NamePolicy = string(namePolicy)
+	session.Header.WindowsNames = ctx.Bool("windows-names")
+	session.Header.Attrs = attrs
+	session.Header.NoSniff = ctx.Bool("no-sniff")
 	session.Header.RootPath, err = os.Getwd()
 	if err != nil {
 		session.Close()
@@ -279,6 +443,18 @@ func runCastCmd(ctx *cli.Context) {
 		session.Close()
 		console.Fatalf("One or more unknown URL types found in %s. %s\n", ctx.Args(), err)
 	}
+	session.Header.CommandHash = getCommandHash(session.Header)
 
+	if ctx.Bool("plan") {
+		doPlanCast(session.Header, signalTrap(os.Interrupt, os.Kill))
+		return
+	}
+	if resumeInterruptedSession(session, ctx.Bool("yes"), ctx.Bool("no-resume")) {
+		return
+	}
 	doCastCmdSession(session)
+	if session.Header.Failed > 0 { // Keep the session to retry failed casts.
+		session.Fail()
+		os.Exit(exitPartialFailure)
+	}
 }
@@ -29,6 +29,7 @@ import (
 type castURLs struct {
 	SourceContent  *client.Content
 	TargetContents []*client.Content
+	TargetIndices  []int `json:"-"` // index of each of TargetContents in the planned targets, set on dispatch
 	Error          error `json:"-"`
 }
 
@@ -53,7 +54,7 @@ const (
 // checkCastSyntax(URLs []string)
 func checkCastSyntax(ctx *cli.Context) {
 	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
-		cli.ShowCommandHelpAndExit(ctx, "cast", 1) // last argument is exit code.
+		cli.ShowCommandHelpAndExit(ctx, "cast", exitUsage) // last argument is exit code.
 	}
 
 	// extract URLs.
@@ -68,22 +69,22 @@ func checkCastSyntax(ctx *cli.Context) {
 	/****** Generic rules *******/
 	// Source cannot be a directory (except when recursive)
 	if !isURLRecursive(srcURL) {
-		_, srcContent, err := url2Stat(srcURL)
+		_, srcContent, err := source2Stat(srcURL)
 		// Source exist?.
 		if err != nil {
 			console.Fatalf("Unable to stat source ‘%s’. %s\n", srcURL, iodine.New(err, nil))
 		}
 		if !srcContent.Type.IsRegular() {
 			if srcContent.Type.IsDir() {
-				console.Fatalf("Source ‘%s’ is a directory. Please use ‘%s...’ to recursively copy this directory and its contents.\n", srcURL, srcURL)
+				console.Fatalf("Source ‘%s’ is a directory. Please use ‘%s...’ to recursively copy this directory and its contents. %s\n", srcURL, srcURL, errInvalidArgument{})
 			}
-			console.Fatalf("Source ‘%s’ is not a regular file.\n", srcURL)
+			console.Fatalf("Source ‘%s’ is not a regular file. %s\n", srcURL, errInvalidArgument{})
 		}
 	}
 	// Recursive URLs are not allowed in target.
 	for _, tgtURL := range tgtURLs {
 		if isURLRecursive(tgtURL) {
-			console.Fatalf("Target ‘%s’ cannot be recursive. %s\n", tgtURL, iodine.New(err, nil))
+			console.Fatalf("Target ‘%s’ cannot be recursive. %s\n", tgtURL, errInvalidArgument{})
 		}
 	}
 
@@ -94,14 +95,14 @@ func checkCastSyntax(ctx *cli.Context) {
 		//
 	case castURLsTypeC:
 		srcURL = stripRecursiveURL(srcURL)
-		_, srcContent, err := url2Stat(srcURL)
+		_, srcContent, err := source2Stat(srcURL)
 		// Source exist?.
 		if err != nil {
 			console.Fatalf("Unable to stat source ‘%s’. %s\n", srcURL, iodine.New(err, nil))
 		}
 
 		if srcContent.Type.IsRegular() { // Ellipses is supported only for directories.
-			console.Fatalf("Source ‘%s’ is not a directory. %s\n", stripRecursiveURL(srcURL), iodine.New(err, nil))
+			console.Fatalf("Source ‘%s’ is not a directory. %s\n", stripRecursiveURL(srcURL), errInvalidArgument{})
 		}
 
 	default:
@@ -137,7 +138,7 @@ func guessCastURLType(sourceURL string, targetURLs []string) castURLsType {
 
 // prepareSingleCastURLTypeA - prepares a single source and single target argument for casting.
 func prepareSingleCastURLsTypeA(sourceURL string, targetURL string) castURLs {
-	_, sourceContent, err := url2Stat(sourceURL)
+	_, sourceContent, err := source2Stat(sourceURL)
 	if err != nil { // Source does not exist or insufficient privileges.
 		return castURLs{Error: NewIodine(iodine.New(err, nil))}
 	}
@@ -166,7 +167,7 @@ func prepareCastURLsTypeA(sourceURL string, targetURLs []string) castURLs {
 
 // prepareSingleCastURLsTypeB - prepares a single target and single source URLs for casting.
 func prepareSingleCastURLsTypeB(sourceURL string, targetURL string) castURLs {
-	_, sourceContent, err := url2Stat(sourceURL)
+	_, sourceContent, err := source2Stat(sourceURL)
 	if err != nil {
 		// Source does not exist or insufficient privileges.
 		return castURLs{Error: NewIodine(iodine.New(err, nil))}
@@ -229,7 +230,7 @@ func prepareCastURLsTypeC(sourceURL string, targetURLs []string) <-chan castURLs
 		}
 		// add `/` after trimming off `...` to emulate directories
 		sourceURL = stripRecursiveURL(sourceURL)
-		sourceClient, sourceContent, err := url2Stat(sourceURL)
+		sourceClient, sourceContent, err := source2Stat(sourceURL)
 		// Source exist?
 		if err != nil {
 			// Source does not exist or insufficient privileges.
@@ -62,9 +62,9 @@ EXAMPLES:
 
 func runCatCmd(ctx *cli.Context) {
 	if !ctx.Args().Present() || ctx.Args().First() == "help" {
-		cli.ShowCommandHelpAndExit(ctx, "cat", 1) // last argument is exit code
+		cli.ShowCommandHelpAndExit(ctx, "cat", exitUsage) // last argument is exit code
 	}
-	if !isMcConfigExists() {
+	if !isMcConfigAvailable() {
 		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
 	}
 	config := mustGetMcConfig()
@@ -81,7 +81,7 @@ func runCatCmd(ctx *cli.Context) {
 		}
 		errorMsg, err := doCatCmd(sourceURL)
 		if err != nil {
-			console.Fatalln(errorMsg)
+			console.Fatalf("%s. %s\n", errorMsg, err)
 		}
 	}
 }
@@ -18,17 +18,24 @@ package main
 
 import (
 	"bytes"
+	"crypto/md5"
+	"encoding/json"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"net/http/httptest"
 
+	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/quick"
+	"github.com/minio/minio/pkg/iodine"
 	. "gopkg.in/check.v1"
 )
 
@@ -80,6 +87,14 @@ func (s *CmdTestSuite) TestGetNewClient(c *C) {
 	c.Assert(err, IsNil)
 	_, err = getNewClient("pkg/client", &hostConfig{})
 	c.Assert(err, IsNil)
+	_, err = getNewClient("https://storage.googleapis.com/bucket1", &hostConfig{API: "GCS"})
+	c.Assert(err, Not(IsNil)) // credentials file is mandatory
+	_, err = getNewClient("https://api.backblazeb2.com/bucket1", &hostConfig{API: "B2"})
+	c.Assert(err, IsNil)
+	_, err = getNewClient("https://example.com/releases/file.iso", &hostConfig{API: "HTTP"})
+	c.Assert(err, IsNil)
+	_, err = getNewClient("https://example.com/bucket1", &hostConfig{API: "unknown"})
+	c.Assert(err, Not(IsNil))
 }
 
 func (s *CmdTestSuite) TestNewConfigV1(c *C) {
@@ -118,6 +133,14 @@ func (s *CmdTestSuite) TestNewConfigV1(c *C) {
 			"localhost",
 			"http://localhost:9000",
 		},
+		{
+			"gcs",
+			"https://storage.googleapis.com",
+		},
+		{
+			"b2",
+			"https://api.backblazeb2.com",
+		},
 	}
 	for _, alias := range wantAliases {
 		url, ok := data.Aliases[alias.name]
@@ -129,11 +152,15 @@ func (s *CmdTestSuite) TestNewConfigV1(c *C) {
 		"play.minio.io:9000",
 		"dl.minio.io:9000",
 		"s3*.amazonaws.com",
+		"storage.googleapis.com",
+		"api.backblazeb2.com",
 	}
 	for _, host := range wantHosts {
 		_, ok := data.Hosts[host]
 		c.Assert(ok, Equals, true)
 	}
+	c.Assert(data.Hosts["storage.googleapis.com"].API, Equals, "GCS")
+	c.Assert(data.Hosts["api.backblazeb2.com"].API, Equals, "B2")
 }
 
 func (s *CmdTestSuite) TestRecursiveURL(c *C) {
@@ -145,6 +172,25 @@ func (s *CmdTestSuite) TestRecursiveURL(c *C) {
 	c.Assert(stripRecursiveURL("...url"), Equals, "...url")
 }
 
+func (s *CmdTestSuite) TestLocalURL(c *C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("paths below are slash separated")
+	}
+	aliases := map[string]string{"s3": "https://s3.amazonaws.com"}
+	globalLocalFlag = true
+	defer func() { globalLocalFlag = false }()
+	url, err := getExpandedURL("s3:backup/...", aliases)
+	c.Assert(err, IsNil)
+	c.Assert(url, Equals, "s3:backup/...")
+	url, err = getExpandedURL("https://example.com/dir/...", aliases)
+	c.Assert(err, IsNil)
+	c.Assert(url, Equals, "file:https://example.com/dir/...")
+	c.Assert(isURLRecursive(url), Equals, true)
+	clnt, err := url2Client(stripRecursiveURL(url))
+	c.Assert(err, IsNil)
+	c.Assert(clnt.URL().Path, Equals, "https://example.com/dir/")
+}
+
 func (s *CmdTestSuite) TestValidACL(c *C) {
 	acl := bucketACL("private")
 	c.Assert(acl.isValidBucketACL(), Equals, true)
@@ -221,6 +267,10 @@ func (s *CmdTestSuite) TestEmptyExpansions(c *C) {
 	c.Assert(url, Equals, "http://foo/bar")
 	c.Assert(err, IsNil)
 
+	url, err = aliasExpand("file:foo:bar", map[string]string{"file": "http://foo", "foo": "http://foo"})
+	c.Assert(url, Equals, "file:foo:bar")
+	c.Assert(err, IsNil)
+
 	url, err = aliasExpand("myfoo:bar", nil)
 	c.Assert(url, Equals, "myfoo:bar")
 	c.Assert(err, IsNil)
@@ -234,6 +284,24 @@ func (s *CmdTestSuite) TestEmptyExpansions(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *CmdTestSuite) TestSubPathAliases(c *C) {
+	aliases := map[string]string{
+		"team":   "https://s3.amazonaws.com/bucket/team/project/",
+		"bucket": "https://s3.amazonaws.com/bucket",
+	}
+	for aliased, expanded := range map[string]string{
+		"team:":               "https://s3.amazonaws.com/bucket/team/project/",
+		"team:report.csv":     "https://s3.amazonaws.com/bucket/team/project/report.csv",
+		"team:/docs/...":      "https://s3.amazonaws.com/bucket/team/project/docs/...",
+		"team:2015-06:01.log": "https://s3.amazonaws.com/bucket/team/project/2015-06:01.log",
+		"bucket:team":         "https://s3.amazonaws.com/bucket/team",
+	} {
+		url, err := getExpandedURL(aliased, aliases)
+		c.Assert(err, IsNil)
+		c.Assert(url, Equals, expanded, Commentf("%s", aliased))
+	}
+}
+
 type testAddr struct{}
 
 func (ta *testAddr) Network() string {
@@ -276,3 +344,173 @@ func (s *CmdTestSuite) TestCommonMethods(c *C) {
 	c.Assert(err, Not(IsNil))
 
 }
+
+func (s *CmdTestSuite) TestPutTargets(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	blocker := filepath.Join(root, "blocker")
+	err = ioutil.WriteFile(blocker, []byte("file"), 0600)
+	c.Assert(err, IsNil)
+
+	data := bytes.Repeat([]byte("hello"), 100*1024)
+	targetURLs := []string{
+		filepath.Join(root, "target1"),
+		filepath.Join(blocker, "target2"), // parent is a file, fails to create.
+		filepath.Join(root, "target3"),
+	}
+
+	// source is read only once, for all targets.
+	source := bytes.NewReader(data)
+	err = putTargets(targetURLs, int64(len(data)), source, nil)
+	c.Assert(err, Not(IsNil))
+	c.Assert(source.Len(), Equals, 0)
+
+	failed, ok := iodine.ToError(err).(errTargetsFailed)
+	c.Assert(ok, Equals, true)
+	c.Assert(failed.URLs, DeepEquals, []string{targetURLs[1]})
+	c.Assert(failed.Total, Equals, 3)
+
+	// failing target does not affect the others.
+	for _, targetURL := range []string{targetURLs[0], targetURLs[2]} {
+		result, err := ioutil.ReadFile(targetURL)
+		c.Assert(err, IsNil)
+		c.Assert(result, DeepEquals, data)
+	}
+}
+
+func (s *CmdTestSuite) TestVerifyEndpoint(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	_, err = parseVerifyMix("put=1,delete=1")
+	c.Assert(err, Not(IsNil))
+	_, err = parseVerifyMix("put=0,get=0")
+	c.Assert(err, Not(IsNil))
+	mix, err := parseVerifyMix("put=2, get=2, list=1")
+	c.Assert(err, IsNil)
+
+	config := verifyEndpointConfig{
+		TargetURL: root,
+		Duration:  300 * time.Millisecond,
+		Interval:  100 * time.Millisecond,
+		Mix:       mix,
+		Size:      1024,
+		Objects:   4,
+		Report:    filepath.Join(root, "report.json"),
+	}
+	intervalCh := make(chan *verifyInterval)
+	go func() {
+		for range intervalCh {
+		}
+	}()
+	report, err := doVerifyEndpoint(config, make(chan bool), intervalCh)
+	c.Assert(err, IsNil)
+	c.Assert(report.failed(), Equals, false)
+	c.Assert(report.Totals[verifyOpPut].Count > 0, Equals, true)
+
+	var saved verifyReport
+	reportBytes, err := ioutil.ReadFile(config.Report)
+	c.Assert(err, IsNil)
+	c.Assert(json.Unmarshal(reportBytes, &saved), IsNil)
+	c.Assert(len(saved.Intervals), Equals, len(report.Intervals))
+	c.Assert(len(saved.Intervals) >= 3, Equals, true)
+
+	// content changed behind our back is reported as a mismatch
+	v := &verifyEndpoint{config: config, random: rand.New(rand.NewSource(1)), written: make(map[int][md5.Size]byte)}
+	objectURL, _, err := v.doPut()
+	c.Assert(err, IsNil)
+	c.Assert(ioutil.WriteFile(objectURL, bytes.Repeat([]byte("x"), 1024), 0600), IsNil)
+	_, mismatch, err := v.doGet()
+	c.Assert(mismatch, Equals, true)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errVerifyMismatch{})
+	c.Assert(os.Remove(objectURL), IsNil)
+	_, mismatch, err = v.doList()
+	c.Assert(mismatch, Equals, true)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errVerifyMismatch{})
+}
+
+func (s *CmdTestSuite) TestCapabilities(c *C) {
+	commands = []cli.Command{cpCmd, lsCmd}
+	flags = []cli.Flag{configFlag, jsonFlag}
+	defer func() {
+		commands = []cli.Command{}
+		flags = []cli.Flag{}
+	}()
+
+	var capabilities CapabilitiesMessage
+	err := json.Unmarshal([]byte(getCapabilities().String()), &capabilities)
+	c.Assert(err, IsNil)
+	c.Assert(capabilities.Version, Equals, "1.0.0")
+	c.Assert(capabilities.Schemes, DeepEquals, []string{"http", "https", "sftp", "ftp", "ftps", "ftpes", "webdav", "webdavs", "hdfs", "webhdfs", "swebhdfs", "tar"})
+	c.Assert(capabilities.GlobalFlags, DeepEquals, []flagCapability{
+		{Name: "config", Aliases: []string{"C"}, Type: "string"},
+		{Name: "json", Type: "bool"},
+	})
+	c.Assert(len(capabilities.Commands), Equals, 2)
+	c.Assert(capabilities.Commands[0].Name, Equals, "cp")
+	c.Assert(capabilities.Commands[0].Flags[0], DeepEquals, flagCapability{Name: "lock", Type: "bool"})
+	c.Assert(capabilities.Commands[1].Name, Equals, "ls")
+	c.Assert(capabilities.Commands[1].Flags[0], DeepEquals, flagCapability{Name: "long", Type: "bool"})
+}
+
+func (s *CmdTestSuite) TestManPages(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	app := cli.NewApp()
+	app.Usage = "Minio Client for object storage and filesystems"
+	app.Commands = []cli.Command{rmCmd, registryCmd}
+	app.CustomAppHelpTemplate = "NAME:\n  {{.Name}} - {{.Usage}}\n\nCOMMANDS:\n  {{range .Commands}}{{.Name}}\n  {{end}}\nVERSION:\n  {{.Compiled}}\n"
+	c.Assert(writeManPages(app, root), IsNil)
+
+	page, err := ioutil.ReadFile(filepath.Join(root, "mc.1"))
+	c.Assert(err, IsNil)
+	c.Assert(string(page), Equals, ".TH \"MC\" \"1\" \"2015-06-24\" \"mc\" \"Minio Client\"\n"+
+		".SH \"NAME\"\n.nf\n  mc \\- Minio Client for object storage and filesystems\n.fi\n"+
+		".SH \"COMMANDS\"\n.nf\n  rm\n.fi\n")
+	page, err = ioutil.ReadFile(filepath.Join(root, "mc-rm.1"))
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(page), ".SH \"USAGE\"\n.nf\n   mc rm [ARGS...] TARGET [TARGET...]\n.fi\n"), Equals, true)
+	c.Assert(strings.Contains(string(page), "   \\-\\-incomplete "), Equals, true)
+	_, err = os.Stat(filepath.Join(root, "mc-registry.1"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	c.Assert(manEscape(".sp"), Equals, "\\&.sp")
+}
+
+func (s *CmdTestSuite) TestRegistry(c *C) {
+	commands = []cli.Command{cpCmd, configCmd, registryCmd}
+	flags = []cli.Flag{configFlag, queueMemoryFlag}
+	defer func() {
+		commands = []cli.Command{}
+		flags = []cli.Flag{}
+	}()
+
+	var registry RegistryMessage
+	err := json.Unmarshal([]byte(getRegistry().String()), &registry)
+	c.Assert(err, IsNil)
+	c.Assert(registry.Version, Equals, "1.0.0")
+	c.Assert(registry.GlobalFlags[0].Name, Equals, "config")
+	c.Assert(registry.GlobalFlags[1].Default, Equals, "256MiB")
+	c.Assert(len(registry.Commands), Equals, 3)
+
+	c.Assert(registry.Commands[0].Name, Equals, "config")
+	c.Assert(registry.Commands[0].Args[:3], DeepEquals, [][]argRegistry{
+		{{Name: "generate", Literal: true}},
+		{{Name: "alias", Literal: true}, {Name: "NAME"}, {Name: "HOSTURL"}},
+		{{Name: "alias", Literal: true}, {Name: "add", Literal: true}, {Name: "NAME"}, {Name: "HOSTURL"},
+			{Name: "ACCESSKEY", Optional: true}, {Name: "SECRETKEY", Optional: true}},
+	})
+	c.Assert(registry.Commands[1].Name, Equals, "cp")
+	c.Assert(registry.Commands[1].Flags[0].Type, Equals, "bool")
+	c.Assert(registry.Commands[1].Args, DeepEquals, [][]argRegistry{
+		{{Name: "SOURCE", Repeated: true}, {Name: "TARGET"}},
+	})
+	c.Assert(registry.Commands[2].Name, Equals, "registry")
+	c.Assert(registry.Commands[2].Hidden, Equals, true)
+	c.Assert(registry.Commands[2].Args, DeepEquals, [][]argRegistry{{}})
+}
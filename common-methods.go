@@ -18,14 +18,24 @@ package main
 
 import (
 	"io"
+	"net/url"
 	"os"
 	"runtime"
 	"strings"
 	"sync"
 
 	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/archive"
+	"github.com/minio/mc/pkg/client/b2"
 	"github.com/minio/mc/pkg/client/fs"
+	"github.com/minio/mc/pkg/client/ftp"
+	"github.com/minio/mc/pkg/client/gcs"
+	"github.com/minio/mc/pkg/client/hdfs"
 	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/mc/pkg/client/sftp"
+	"github.com/minio/mc/pkg/client/stdio"
+	"github.com/minio/mc/pkg/client/web"
+	"github.com/minio/mc/pkg/client/webdav"
 	"github.com/minio/minio/pkg/iodine"
 )
 
@@ -60,23 +70,241 @@ func getSource(sourceURL string) (reader io.ReadCloser, length int64, err error)
 	return sourceClnt.GetObject(0, 0)
 }
 
+// getSourceVersion gets a reader of version versionID of the object at URL
+func getSourceVersion(sourceURL, versionID string) (io.ReadCloser, int64, error) {
+	sourceClnt, err := source2Client(sourceURL)
+	if err != nil {
+		return nil, 0, NewIodine(iodine.New(err, map[string]string{"failedURL": sourceURL}))
+	}
+	getter, ok := sourceClnt.(client.VersionGetter)
+	if !ok {
+		return nil, 0, NewIodine(iodine.New(errVersionsNotSupported{URL: sourceURL}, nil))
+	}
+	return getter.GetObjectVersion(versionID, 0, 0)
+}
+
+// getMappedSource - memory mapped reader of local sources of at least mmapSize bytes, other sources and
+// filesystems which cannot map files are read as usual. mmapSize 0 maps nothing
+func getMappedSource(sourceURL string, size, mmapSize int64) (io.ReadCloser, int64, error) {
+	if mmapSize == 0 || size < mmapSize {
+		return getSource(sourceURL)
+	}
+	sourceClnt, err := source2Client(sourceURL)
+	if err != nil {
+		return nil, 0, NewIodine(iodine.New(err, map[string]string{"failedURL": sourceURL}))
+	}
+	getter, ok := sourceClnt.(client.MappedGetter)
+	if !ok {
+		return sourceClnt.GetObject(0, 0)
+	}
+	mapped, length, err := getter.GetObjectMapped()
+	if err != nil {
+		if _, ok := iodine.ToError(err).(client.APINotImplemented); ok {
+			return sourceClnt.GetObject(0, 0)
+		}
+		return nil, length, NewIodine(iodine.New(err, map[string]string{"failedURL": sourceURL}))
+	}
+	return mapped, length, nil
+}
+
+// putObject writes to client from reader, metadata is dropped for clients which cannot store it.
+// Uploads under a prefix configured for server side encryption are encrypted or refused.
+func putObject(clnt client.Client, length int64, reader io.Reader, metadata map[string]string) error {
+	sse, err := getRequiredEncryption(clnt.URL().String())
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	if sse != nil {
+		putter, ok := clnt.(client.EncryptedPutter)
+		if !ok {
+			return NewIodine(iodine.New(errEncryptionRequired{URL: clnt.URL().String()}, nil))
+		}
+		return putter.PutObjectWithEncryption(length, reader, metadata, *sse)
+	}
+	if putter, ok := clnt.(client.MetadataPutter); ok && len(metadata) > 0 {
+		return putter.PutObjectWithMetadata(length, reader, metadata)
+	}
+	return clnt.PutObject(length, reader)
+}
+
 // putTarget writes to URL from reader.
 func putTarget(targetURL string, length int64, reader io.Reader) error {
+	return putTargetWithMetadata(targetURL, length, reader, nil)
+}
+
+// putTargetWithMetadata writes to URL from reader, storing metadata if the target supports it.
+func putTargetWithMetadata(targetURL string, length int64, reader io.Reader, metadata map[string]string) error {
 	targetClnt, err := target2Client(targetURL)
 	if err != nil {
 		return NewIodine(iodine.New(err, nil))
 	}
-	err = targetClnt.PutObject(length, reader)
+	err = putObject(targetClnt, length, reader, metadata)
 	if err != nil {
 		return NewIodine(iodine.New(err, map[string]string{"failedURL": targetURL}))
 	}
 	return nil
 }
 
-// putTargets writes to URL from reader.
-func putTargets(targetURLs []string, length int64, reader io.Reader) error {
-	var tgtReaders []io.ReadCloser
-	var tgtWriters []io.WriteCloser
+// getSourceMetadata reads metadata and tags of sourceURL which targetURL can store, both are
+// empty when either side does not support them. Of an older version only the metadata is read.
+func getSourceMetadata(sourceURL, versionID, targetURL string) (metadata, tags map[string]string, err error) {
+	targetClnt, err := target2Client(targetURL)
+	if err != nil {
+		return nil, nil, NewIodine(iodine.New(err, nil))
+	}
+	_, canPutMetadata := targetClnt.(client.MetadataPutter)
+	_, canSetTags := targetClnt.(client.TagSetter)
+	if !canPutMetadata && !canSetTags {
+		return nil, nil, nil
+	}
+	sourceClnt, err := source2Client(sourceURL)
+	if err != nil {
+		return nil, nil, NewIodine(iodine.New(err, nil))
+	}
+	if versionID != "" {
+		getter, ok := sourceClnt.(client.VersionGetter)
+		if !ok || !canPutMetadata {
+			return nil, nil, nil
+		}
+		content, err := getter.StatVersion(versionID)
+		if err != nil {
+			return nil, nil, NewIodine(iodine.New(err, map[string]string{"failedURL": sourceURL}))
+		}
+		return content.Metadata, nil, nil
+	}
+	if getter, ok := sourceClnt.(client.MetadataGetter); ok && canPutMetadata {
+		metadata, err = getter.GetObjectMetadata()
+		if err != nil {
+			return nil, nil, NewIodine(iodine.New(err, map[string]string{"failedURL": sourceURL}))
+		}
+	}
+	if tagger, ok := sourceClnt.(client.Tagger); ok && canSetTags {
+		tags, err = tagger.GetObjectTags()
+		if _, ok := iodine.ToError(err).(client.APINotImplemented); ok {
+			err = nil // servers without object tagging
+		}
+		switch s3.ErrorCode(err) {
+		case "AccessDenied", "NotImplemented": // users without s3:GetObjectTagging, servers without object tagging
+			tags, err = nil, nil
+		}
+		if err != nil {
+			return nil, nil, NewIodine(iodine.New(err, map[string]string{"failedURL": sourceURL}))
+		}
+	}
+	return metadata, tags, nil
+}
+
+// withTags - metadata to upload to targetURL with tags set along, tags are dropped for targets which cannot
+// store them.
+func withTags(targetURL string, metadata, tags map[string]string) (map[string]string, error) {
+	if len(tags) == 0 {
+		return metadata, nil
+	}
+	targetClnt, err := target2Client(targetURL)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if _, ok := targetClnt.(client.TagSetter); !ok {
+		return metadata, nil
+	}
+	values := url.Values{}
+	for key, value := range tags {
+		values.Set(key, value)
+	}
+	tagged := map[string]string{client.TaggingMetadata: values.Encode()}
+	for key, value := range metadata {
+		tagged[key] = value
+	}
+	return tagged, nil
+}
+
+// setTargetACL sets a canned ACL on the object at URL, it is an error if the target cannot keep ACLs.
+func setTargetACL(targetURL, acl string) error {
+	targetClnt, err := target2Client(targetURL)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	setter, ok := targetClnt.(client.ObjectACLSetter)
+	if !ok {
+		return NewIodine(iodine.New(errACLNotSupported{URL: targetURL}, nil))
+	}
+	if err := setter.SetObjectACL(acl); err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"failedURL": targetURL}))
+	}
+	return nil
+}
+
+// setTargetPOSIXAttrs restores owner and permissions on URL, targets which cannot store them are left as is.
+func setTargetPOSIXAttrs(targetURL string, attrs client.POSIXAttrs) error {
+	targetClnt, err := target2Client(targetURL)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	setter, ok := targetClnt.(client.POSIXAttrsSetter)
+	if !ok {
+		return nil
+	}
+	if err := setter.SetPOSIXAttrs(attrs); err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"failedURL": targetURL}))
+	}
+	return nil
+}
+
+// fanOutWriter duplicates writes to a set of pipe writers. A target
+// which fails is dropped from the set, remaining targets continue to
+// receive data from the single source read.
+type fanOutWriter struct {
+	writers []*io.PipeWriter
+	written []int64
+	errs    []error
+}
+
+func newFanOutWriter(writers []*io.PipeWriter) *fanOutWriter {
+	return &fanOutWriter{
+		writers: writers,
+		written: make([]int64, len(writers)),
+		errs:    make([]error, len(writers)),
+	}
+}
+
+// Write writes p to every target which has not failed yet. It fails
+// only when no target is left to write to.
+func (f *fanOutWriter) Write(p []byte) (int, error) {
+	alive := 0
+	for i, writer := range f.writers {
+		if f.errs[i] != nil {
+			continue
+		}
+		n, err := writer.Write(p)
+		f.written[i] += int64(n)
+		if err == nil && n != len(p) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			f.errs[i] = err
+			writer.CloseWithError(err)
+			continue
+		}
+		alive++
+	}
+	if alive == 0 {
+		return 0, io.ErrClosedPipe
+	}
+	return len(p), nil
+}
+
+// Close closes all pipe writers, targets still reading see err or io.EOF if err is nil.
+func (f *fanOutWriter) Close(err error) {
+	for _, writer := range f.writers {
+		writer.CloseWithError(err)
+	}
+}
+
+// putTargets writes to URL from reader. Source is read only once and streamed
+// to all targets concurrently, failure of a target does not affect others.
+func putTargets(targetURLs []string, length int64, reader io.Reader, metadata map[string]string) error {
+	var tgtReaders []*io.PipeReader
+	var tgtWriters []*io.PipeWriter
 	var tgtClients []client.Client
 
 	for _, targetURL := range targetURLs {
@@ -90,50 +318,60 @@ func putTargets(targetURLs []string, length int64, reader io.Reader) error {
 		tgtWriters = append(tgtWriters, tgtWriter)
 	}
 
+	fanOut := newFanOutWriter(tgtWriters)
+	copyDone := make(chan struct{})
 	go func() {
-		var writers []io.Writer
-		for _, tgtWriter := range tgtWriters {
-			defer tgtWriter.Close()
-			writers = append(writers, io.Writer(tgtWriter))
-		}
-		multiTgtWriter := io.MultiWriter(writers...)
-		io.CopyN(multiTgtWriter, reader, length)
+		defer close(copyDone)
+		_, err := io.CopyN(fanOut, reader, length)
+		fanOut.Close(err) // on source error all targets fail with it.
 	}()
 
 	var wg sync.WaitGroup
-	errorCh := make(chan error, len(tgtClients))
-
-	func() { // Parallel putObject
-		defer close(errorCh) // Each routine gets to return one err status.
-
-		for i := range tgtClients {
-			wg.Add(1)
-			// make local copy for go routine
-			tgtClient := tgtClients[i]
-			tgtReader := tgtReaders[i]
-
-			go func(targetClient client.Client, reader io.ReadCloser, errorCh chan<- error) {
-				defer wg.Done()
-				err := targetClient.PutObject(length, reader)
-				if err != nil {
-					errorCh <- iodine.New(err, map[string]string{"failedURL": targetClient.URL().String()})
-					reader.Close()
-					return
-				}
-				errorCh <- err // return nil error = success.
-			}(tgtClient, tgtReader, errorCh)
-		}
-		wg.Wait()
-	}()
+	tgtErrs := make([]error, len(tgtClients))
+	for i := range tgtClients {
+		wg.Add(1)
+		go func(i int) { // Parallel putObject
+			defer wg.Done()
+			// Unblock the fan out writer if target returns without consuming all data.
+			defer tgtReaders[i].Close()
+			tgtErrs[i] = putObject(tgtClients[i], length, tgtReaders[i], metadata)
+		}(i)
+	}
+	wg.Wait()
+	<-copyDone
 
-	for err := range errorCh {
-		if err != nil { // Return on first error encounter.
-			return err
+	failed := errTargetsFailed{}
+	for i, err := range tgtErrs {
+		if err == nil {
+			continue
 		}
+		failed.URLs = append(failed.URLs, targetURLs[i])
+		failed.Errors = append(failed.Errors, iodine.ToError(err))
+		failed.Written = append(failed.Written, fanOut.written[i])
+	}
+	if len(failed.URLs) > 0 {
+		failed.Total = len(targetURLs)
+		return iodine.New(failed, nil)
 	}
 	return nil // success.
 }
 
+// hostUser - access key or user name of auth, empty while it is the placeholder of a new configuration
+func hostUser(auth *hostConfig) string {
+	if auth.AccessKeyID == globalAccessKeyID {
+		return ""
+	}
+	return auth.AccessKeyID
+}
+
+// hostSecret - secret key or password of auth, empty while it is the placeholder of a new configuration
+func hostSecret(auth *hostConfig) string {
+	if auth.SecretAccessKey == globalSecretAccessKey {
+		return ""
+	}
+	return auth.SecretAccessKey
+}
+
 // getNewClient gives a new client interface
 func getNewClient(urlStr string, auth *hostConfig) (clnt client.Client, err error) {
 	url, err := client.Parse(urlStr)
@@ -145,31 +383,139 @@ func getNewClient(urlStr string, auth *hostConfig) (clnt client.Client, err erro
 		if auth == nil {
 			return nil, NewIodine(iodine.New(errInvalidArgument{}, nil))
 		}
+		if factory, ok := client.Lookup(url.Scheme); ok { // backends registered by programs embedding mc
+			config := new(client.Config)
+			config.HostURL = urlStr
+			config.AccessKeyID = hostUser(auth)
+			config.SecretAccessKey = hostSecret(auth)
+			config.CredentialsFile = auth.CredentialsFile
+			config.AppName = "Minio"
+			config.AppVersion = getVersion()
+			config.AppComments = []string{os.Args[0], runtime.GOOS, runtime.GOARCH}
+			config.Debug = globalDebugFlag
+			return factory(config)
+		}
+		switch url.Scheme {
+		case "sftp": // SSH file transfer, host API does not apply
+			sftpConfig := new(sftp.Config)
+			sftpConfig.User = hostUser(auth)
+			sftpConfig.Password = hostSecret(auth)
+			sftpConfig.PrivateKeyFile = auth.CredentialsFile
+			sftpConfig.AppName = "Minio"
+			sftpConfig.AppVersion = getVersion()
+			sftpConfig.HostURL = urlStr
+			return sftp.New(sftpConfig)
+		case "ftp", "ftps", "ftpes": // FTP with optional TLS, host API does not apply
+			ftpConfig := new(ftp.Config)
+			ftpConfig.User = hostUser(auth)
+			ftpConfig.Password = hostSecret(auth)
+			ftpConfig.HostURL = urlStr
+			return ftp.New(ftpConfig)
+		case "webdav", "webdavs": // WebDAV shares over HTTP or HTTPS, host API does not apply
+			webdavConfig := new(webdav.Config)
+			webdavConfig.User = hostUser(auth)
+			webdavConfig.Password = hostSecret(auth)
+			webdavConfig.AppName = "Minio"
+			webdavConfig.AppVersion = getVersion()
+			webdavConfig.AppComments = []string{os.Args[0], runtime.GOOS, runtime.GOARCH}
+			webdavConfig.HostURL = urlStr
+			webdavConfig.Debug = globalDebugFlag
+			return webdav.New(webdavConfig)
+		case "hdfs", "webhdfs", "swebhdfs": // HDFS through WebHDFS REST API, host API does not apply
+			hdfsConfig := new(hdfs.Config)
+			hdfsConfig.User = hostUser(auth)
+			hdfsConfig.AppName = "Minio"
+			hdfsConfig.AppVersion = getVersion()
+			hdfsConfig.AppComments = []string{os.Args[0], runtime.GOOS, runtime.GOARCH}
+			hdfsConfig.HostURL = urlStr
+			hdfsConfig.Debug = globalDebugFlag
+			return hdfs.New(hdfsConfig)
+		case "tar": // members of a local tar or zip archive, host API does not apply
+			return archive.New(urlStr)
+		}
+		signature := s3.SignatureAuto
+		switch strings.ToUpper(auth.API) {
+		case "", hostAPIS3:
+		case strings.ToUpper(hostAPIS3v2):
+			signature = s3.SignatureV2
+		case strings.ToUpper(hostAPIS3v4):
+			signature = s3.SignatureV4
+		case hostAPIGCS: // Google Cloud Storage JSON API
+			gcsConfig := new(gcs.Config)
+			gcsConfig.CredentialsFile = auth.CredentialsFile
+			gcsConfig.AppName = "Minio"
+			gcsConfig.AppVersion = getVersion()
+			gcsConfig.AppComments = []string{os.Args[0], runtime.GOOS, runtime.GOARCH}
+			gcsConfig.HostURL = urlStr
+			gcsConfig.Debug = globalDebugFlag
+			return gcs.New(gcsConfig)
+		case hostAPIB2: // Backblaze B2 native API
+			b2Config := new(b2.Config)
+			b2Config.AccountID = hostUser(auth)
+			b2Config.ApplicationKey = hostSecret(auth)
+			b2Config.AppName = "Minio"
+			b2Config.AppVersion = getVersion()
+			b2Config.AppComments = []string{os.Args[0], runtime.GOOS, runtime.GOARCH}
+			b2Config.HostURL = urlStr
+			b2Config.Debug = globalDebugFlag
+			return b2.New(b2Config)
+		case hostAPIHTTP: // Plain web server, read-only
+			webConfig := new(web.Config)
+			webConfig.AppName = "Minio"
+			webConfig.AppVersion = getVersion()
+			webConfig.AppComments = []string{os.Args[0], runtime.GOOS, runtime.GOARCH}
+			webConfig.HostURL = urlStr
+			webConfig.Debug = globalDebugFlag
+			return web.New(webConfig)
+		default:
+			return nil, NewIodine(iodine.New(errInvalidHostAPI{API: auth.API}, nil))
+		}
 		s3Config := new(s3.Config)
-		s3Config.AccessKeyID = func() string {
-			if auth.AccessKeyID == globalAccessKeyID {
-				return ""
-			}
-			return auth.AccessKeyID
-		}()
-		s3Config.SecretAccessKey = func() string {
-			if auth.SecretAccessKey == globalSecretAccessKey {
-				return ""
-			}
-			return auth.SecretAccessKey
-		}()
+		s3Config.AccessKeyID = hostUser(auth)
+		s3Config.SecretAccessKey = hostSecret(auth)
 		s3Config.AppName = "Minio"
 		s3Config.AppVersion = getVersion()
 		s3Config.AppComments = []string{os.Args[0], runtime.GOOS, runtime.GOARCH}
 		s3Config.HostURL = urlStr
 		s3Config.Debug = globalDebugFlag
+		s3Config.Signature = signature
+		s3Config.Region = auth.Region
+		s3Config.Anonymous = auth.Anonymous
+		s3Config.Proxy = auth.Proxy
+		s3Config.Insecure = auth.Insecure
+		s3Config.StorageClass = auth.StorageClass
+		s3Config.MaxIdleConnsPerHost = globalMaxIdleConns
+		s3Config.RequestTimeout = globalRequestTimeout
+		s3Config.KeepAlive = globalKeepAlive
+		s3Config.Lookup = auth.Lookup
+		if globalLookup != "" {
+			s3Config.Lookup = globalLookup
+		}
 		return s3.New(s3Config)
 	case client.Filesystem:
-		return fs.New(urlStr)
+		if url.Path == stdio.Name { // standard input or output, a file named "-" is reached with "./-"
+			return stdio.New(), nil
+		}
+		return fs.New(url.Path)
 	}
 	return nil, NewIodine(iodine.New(errInvalidURL{URL: urlStr}, nil))
 }
 
+// source2Stat - Returns client and its stat Content of a URL read from, see getSourceHostConfig
+func source2Stat(urlStr string) (client client.Client, content *client.Content, err error) {
+	client, err = sourceURL2Client(urlStr)
+	if err != nil {
+		return nil, nil, NewIodine(iodine.New(err, map[string]string{"URL": urlStr}))
+	}
+
+	content, err = client.Stat()
+	if err != nil {
+		return nil, nil, NewIodine(iodine.New(err, map[string]string{"URL": urlStr}))
+	}
+
+	return client, content, nil
+}
+
 // url2Stat - Returns client, config and its stat Content from the URL
 func url2Stat(urlStr string) (client client.Client, content *client.Content, err error) {
 	client, err = url2Client(urlStr)
@@ -186,6 +532,16 @@ func url2Stat(urlStr string) (client client.Client, content *client.Content, err
 }
 
 func url2Client(url string) (client.Client, error) {
+	return newURLClient(url, getHostConfig)
+}
+
+// sourceURL2Client - client of a URL read from, see getSourceHostConfig
+func sourceURL2Client(url string) (client.Client, error) {
+	return newURLClient(url, getSourceHostConfig)
+}
+
+// newURLClient - client of url with the host configuration getConfig finds for it
+func newURLClient(url string, getConfig func(string) (*hostConfig, error)) (client.Client, error) {
 	// Empty source arg?
 	urlParse, err := client.Parse(url)
 	if err != nil {
@@ -196,7 +552,7 @@ func url2Client(url string) (client.Client, error) {
 		return nil, NewIodine(iodine.New(errInvalidURL{URL: url}, map[string]string{"URL": url}))
 	}
 
-	urlonfig, err := getHostConfig(url)
+	urlonfig, err := getConfig(url)
 	if err != nil {
 		return nil, NewIodine(iodine.New(err, map[string]string{"URL": url}))
 	}
@@ -211,7 +567,7 @@ func url2Client(url string) (client.Client, error) {
 
 // source2Client returns client and hostconfig objects from the source URL.
 func source2Client(sourceURL string) (client.Client, error) {
-	sourceClient, err := url2Client(sourceURL)
+	sourceClient, err := sourceURL2Client(sourceURL)
 	if err != nil {
 		return nil, NewIodine(iodine.New(errInvalidSource{URL: sourceURL}, map[string]string{"URL": sourceURL}))
 	}
@@ -0,0 +1,187 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/quick"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// loadConfigV1 - configuration file for changes, written back with writeConfig
+func loadConfigV1() (*configV1, error) {
+	conf := newConfigV1()
+	config, err := quick.New(conf)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if err := config.Load(mustGetMcConfigPath()); err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if err := decryptConfigV1(config.Data().(*configV1)); err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return config.Data().(*configV1), nil
+}
+
+// probeAlias - list the root of the alias URL with hostCfg, so unreachable hosts and wrong keys are
+// reported before the alias is saved
+func probeAlias(aliasURL string, hostCfg *hostConfig) error {
+	clnt, err := getNewClient(aliasURL, hostCfg)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	if _, err := clnt.Stat(); err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"URL": aliasURL}))
+	}
+	return nil
+}
+
+// addAliasWithKeys - add alias name for aliasURL, keys are saved for the host and path of aliasURL if given.
+// Unless force is set the alias is probed first and not added if the host can not be reached
+func addAliasWithKeys(name, aliasURL, accessKeyID, secretAccessKey string, force bool) (*configV1, error) {
+	aliasURL = strings.TrimSuffix(aliasURL, "/")
+	if strings.HasPrefix(name, "http") || !isValidAliasName(name) {
+		return nil, NewIodine(iodine.New(errInvalidAliasName{name: name}, nil))
+	}
+	u, err := client.Parse(aliasURL)
+	if err != nil || u.Type != client.Object || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, NewIodine(iodine.New(errInvalidURL{URL: aliasURL}, nil))
+	}
+	if (accessKeyID == "") != (secretAccessKey == "") {
+		return nil, NewIodine(iodine.New(errInvalidAuth{}, nil))
+	}
+	conf, err := loadConfigV1()
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if _, ok := conf.Aliases[name]; ok {
+		return nil, NewIodine(iodine.New(errAliasExists{}, nil))
+	}
+	// keys of aliases below a bucket or prefix are scoped to it
+	hostGlob := aliasHostGlob(u)
+	// settings of an existing host entry are kept, only its keys change
+	hostCfg, ok := conf.Hosts[hostGlob]
+	if ok {
+		copied := *hostCfg
+		hostCfg = &copied
+	} else {
+		hostCfg = new(hostConfig)
+	}
+	if accessKeyID != "" {
+		hostCfg.AccessKeyID = accessKeyID
+		hostCfg.SecretAccessKey = secretAccessKey
+	} else if !ok {
+		if hostCfg, err = getHostConfig(aliasURL); err != nil {
+			hostCfg = new(hostConfig)
+		}
+	}
+	if !force {
+		if err := probeAlias(aliasURL, hostCfg); err != nil {
+			return nil, NewIodine(iodine.New(errAliasProbe{URL: aliasURL, err: iodine.ToError(err)}, nil))
+		}
+	}
+	conf.Aliases[name] = aliasURL
+	if accessKeyID != "" {
+		conf.Hosts[hostGlob] = hostCfg
+	}
+	return conf, nil
+}
+
+// removeAlias - remove alias name, keys of its host are kept as other aliases may use them
+func removeAlias(name string) (*configV1, error) {
+	conf, err := loadConfigV1()
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if _, ok := conf.Aliases[name]; !ok {
+		return nil, NewIodine(iodine.New(errAliasNotFound{name: name}, nil))
+	}
+	delete(conf.Aliases, name)
+	return conf, nil
+}
+
+// listAliases - aliases sorted by name
+func listAliases() ([]AliasMessage, error) {
+	conf, err := getMcConfig()
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	var messages []AliasMessage
+	for name, aliasURL := range conf.Aliases {
+		messages = append(messages, AliasMessage{Alias: name, URL: aliasURL})
+	}
+	sort.Sort(byAliasName(messages))
+	return messages, nil
+}
+
+type byAliasName []AliasMessage
+
+func (b byAliasName) Len() int           { return len(b) }
+func (b byAliasName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byAliasName) Less(i, j int) bool { return b[i].Alias < b[j].Alias }
+
+// saveConfigV1 - write conf to the configuration file
+func saveConfigV1(conf *configV1) error {
+	config, err := quick.New(conf)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	return writeConfig(config)
+}
+
+// runConfigAliasCmd - mc config alias add, remove and list
+func runConfigAliasCmd(operation string, args []string) {
+	switch {
+	case operation == "add" && (len(args) == 2 || len(args) == 4):
+		var accessKeyID, secretAccessKey string
+		if len(args) == 4 {
+			accessKeyID, secretAccessKey = args[2], args[3]
+		}
+		conf, err := addAliasWithKeys(args[0], args[1], accessKeyID, secretAccessKey, globalForceFlag)
+		if err == nil {
+			err = saveConfigV1(conf)
+		}
+		if err != nil {
+			console.Fatalf("Unable to add alias ‘%s’. %s\n", args[0], NewIodine(iodine.New(err, nil)))
+		}
+		console.Infoln("Added alias ‘" + args[0] + "’ for ‘" + strings.TrimSuffix(args[1], "/") + "’.")
+	case operation == "remove" && len(args) == 1:
+		conf, err := removeAlias(args[0])
+		if err == nil {
+			err = saveConfigV1(conf)
+		}
+		if err != nil {
+			console.Fatalf("Unable to remove alias ‘%s’. %s\n", args[0], NewIodine(iodine.New(err, nil)))
+		}
+		console.Infoln("Removed alias ‘" + args[0] + "’.")
+	case operation == "list" && len(args) == 0:
+		messages, err := listAliases()
+		if err != nil {
+			console.Fatalf("Unable to list aliases. %s\n", NewIodine(iodine.New(err, nil)))
+		}
+		for _, message := range messages {
+			console.PrintC(message)
+		}
+	default:
+		console.Fatalf("Incorrect number of arguments, please use \"mc config help\". %s\n", errInvalidArgument{})
+	}
+}
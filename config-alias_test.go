@@ -0,0 +1,80 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net/url"
+
+	"github.com/minio/mc/pkg/fakes3"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestConfigAlias(c *C) {
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	c.Assert(err, IsNil)
+
+	conf, err := addAliasWithKeys("fake", server.URL+"/", "access", "secret", false)
+	c.Assert(err, IsNil)
+	c.Assert(conf.Aliases["fake"], Equals, server.URL)
+	c.Assert(conf.Hosts[u.Host].AccessKeyID, Equals, "access")
+	c.Assert(saveConfigV1(conf), IsNil)
+	defer func() {
+		conf, err := loadConfigV1()
+		c.Assert(err, IsNil)
+		delete(conf.Aliases, "fake")
+		delete(conf.Hosts, u.Host)
+		c.Assert(saveConfigV1(conf), IsNil)
+	}()
+
+	aliases, err := listAliases()
+	c.Assert(err, IsNil)
+	c.Assert(aliases, Not(HasLen), 0)
+	found := false
+	for i, alias := range aliases {
+		c.Assert(i == 0 || aliases[i-1].Alias < alias.Alias, Equals, true)
+		found = found || alias == AliasMessage{Alias: "fake", URL: server.URL}
+	}
+	c.Assert(found, Equals, true)
+
+	_, err = addAliasWithKeys("fake", server.URL, "", "", false)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errAliasExists{})
+	_, err = addAliasWithKeys("help", server.URL, "", "", false)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errInvalidAliasName{})
+	_, err = addAliasWithKeys("other", "ftp://example.com", "", "", false)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errInvalidURL{})
+	_, err = addAliasWithKeys("other", server.URL, "access", "", false)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errInvalidAuth{})
+
+	// nothing listens on the closed server, the alias is added only when forced
+	down := fakes3.NewServer()
+	down.Close()
+	_, err = addAliasWithKeys("down", down.URL, "", "", false)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errAliasProbe{})
+	conf, err = addAliasWithKeys("down", down.URL, "", "", true)
+	c.Assert(err, IsNil)
+	c.Assert(conf.Aliases["down"], Equals, down.URL)
+
+	conf, err = removeAlias("fake")
+	c.Assert(err, IsNil)
+	_, ok := conf.Aliases["fake"]
+	c.Assert(ok, Equals, false)
+	_, err = removeAlias("missing")
+	c.Assert(iodine.ToError(err), FitsTypeOf, errAliasNotFound{})
+}
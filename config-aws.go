@@ -0,0 +1,175 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// awsHostGlob - host entry of profiles without endpoint_url, the same one ‘mc config generate’ writes
+const awsHostGlob = "s3*.amazonaws.com"
+
+// getAWSFilePath - path of AWS shared credentials or config file, env overrides it like AWS tools do
+func getAWSFilePath(env, name string) (string, error) {
+	if path := os.Getenv(env); path != "" {
+		return path, nil
+	}
+	u, err := user.Current()
+	if err != nil {
+		return "", NewIodine(iodine.New(err, nil))
+	}
+	return filepath.Join(u.HomeDir, ".aws", name), nil
+}
+
+// readAWSFile - keys of each section of an AWS INI file, missing files have no sections
+func readAWSFile(path string) (map[string]map[string]string, error) {
+	sections := make(map[string]map[string]string)
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sections, nil
+		}
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	defer file.Close()
+
+	var section map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			section = make(map[string]string)
+			sections[name] = section
+		case section != nil && strings.Contains(line, "="):
+			// indented lines belong to nested settings like "s3 =", they are not read
+			if raw := scanner.Text(); raw[0] == ' ' || raw[0] == '\t' {
+				continue
+			}
+			i := strings.Index(line, "=")
+			section[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return sections, nil
+}
+
+// getAWSProfile - settings of profile from AWS credentials and config files, credentials win
+func getAWSProfile(profile string) (map[string]string, error) {
+	settings := make(map[string]string)
+	configPath, err := getAWSFilePath("AWS_CONFIG_FILE", "config")
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	configSections, err := readAWSFile(configPath)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	// config file names sections "profile NAME" except for default
+	for key, value := range configSections["profile "+profile] {
+		settings[key] = value
+	}
+	if profile == "default" {
+		for key, value := range configSections["default"] {
+			settings[key] = value
+		}
+	}
+	credentialsPath, err := getAWSFilePath("AWS_SHARED_CREDENTIALS_FILE", "credentials")
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	credentialsSections, err := readAWSFile(credentialsPath)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	for key, value := range credentialsSections[profile] {
+		settings[key] = value
+	}
+	return settings, nil
+}
+
+// importAWSProfile - host entry of profile added to the configuration file, for its endpoint_url or Amazon S3.
+// Other settings of an existing host entry are kept
+func importAWSProfile(profile string) (*configV1, string, error) {
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	settings, err := getAWSProfile(profile)
+	if err != nil {
+		return nil, "", NewIodine(iodine.New(err, nil))
+	}
+	if settings["aws_access_key_id"] == "" || settings["aws_secret_access_key"] == "" {
+		return nil, "", NewIodine(iodine.New(errAWSProfileNotFound{profile: profile}, nil))
+	}
+	hostGlob := awsHostGlob
+	if endpoint := settings["endpoint_url"]; endpoint != "" {
+		u, err := client.Parse(strings.TrimSuffix(endpoint, "/"))
+		if err != nil || u.Host == "" {
+			return nil, "", NewIodine(iodine.New(errInvalidURL{URL: endpoint}, nil))
+		}
+		hostGlob = aliasHostGlob(u)
+	}
+	conf, err := loadConfigV1()
+	if err != nil {
+		return nil, "", NewIodine(iodine.New(err, nil))
+	}
+	hostCfg := new(hostConfig)
+	if existing, ok := conf.Hosts[hostGlob]; ok && existing != nil {
+		*hostCfg = *existing
+	}
+	hostCfg.AccessKeyID = settings["aws_access_key_id"]
+	hostCfg.SecretAccessKey = settings["aws_secret_access_key"]
+	if region := settings["region"]; region != "" {
+		hostCfg.Region = region
+	}
+	conf.Hosts[hostGlob] = hostCfg
+	return conf, hostGlob, nil
+}
+
+// runConfigImportCmd - mc config import aws, other arguments are bundles of mc config export
+func runConfigImportCmd(args []string, profile string) {
+	if len(args) != 1 {
+		console.Fatalf("Incorrect number of arguments, please use \"mc config help\". %s\n", errInvalidArgument{})
+	}
+	if args[0] != "aws" {
+		runConfigImportBundleCmd(args)
+		return
+	}
+	conf, hostGlob, err := importAWSProfile(profile)
+	if err == nil {
+		err = saveConfigV1(conf)
+	}
+	if err != nil {
+		console.Fatalf("Unable to import AWS profile. %s\n", NewIodine(iodine.New(err, nil)))
+	}
+	console.Infoln("Imported AWS keys for ‘" + hostGlob + "’.")
+}
@@ -0,0 +1,69 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestConfigImportAWS(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	credentials := filepath.Join(root, "credentials")
+	c.Assert(ioutil.WriteFile(credentials, []byte(`[default]
+aws_access_key_id = DEFAULTACCESS
+aws_secret_access_key = defaultsecret
+
+# keys of a Minio server
+[prod]
+aws_access_key_id=PRODACCESS
+aws_secret_access_key=prodsecret
+`), 0600), IsNil)
+	config := filepath.Join(root, "config")
+	c.Assert(ioutil.WriteFile(config, []byte(`[default]
+region = eu-west-1
+
+[profile prod]
+endpoint_url = https://minio.example.com:9000/
+s3 =
+  addressing_style = path
+region = us-east-1
+`), 0600), IsNil)
+	c.Assert(os.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentials), IsNil)
+	c.Assert(os.Setenv("AWS_CONFIG_FILE", config), IsNil)
+	defer os.Unsetenv("AWS_SHARED_CREDENTIALS_FILE")
+	defer os.Unsetenv("AWS_CONFIG_FILE")
+
+	conf, hostGlob, err := importAWSProfile("")
+	c.Assert(err, IsNil)
+	c.Assert(hostGlob, Equals, awsHostGlob)
+	c.Assert(*conf.Hosts[hostGlob], Equals, hostConfig{AccessKeyID: "DEFAULTACCESS", SecretAccessKey: "defaultsecret", Region: "eu-west-1"})
+
+	conf, hostGlob, err = importAWSProfile("prod")
+	c.Assert(err, IsNil)
+	c.Assert(hostGlob, Equals, "minio.example.com:9000")
+	c.Assert(*conf.Hosts[hostGlob], Equals, hostConfig{AccessKeyID: "PRODACCESS", SecretAccessKey: "prodsecret", Region: "us-east-1"})
+
+	_, _, err = importAWSProfile("missing")
+	c.Assert(iodine.ToError(err), FitsTypeOf, errAWSProfileNotFound{})
+}
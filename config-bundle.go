@@ -0,0 +1,144 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Bundles of ‘mc config export’ are configuration files themselves, aliases, hosts and encryption
+// settings of one machine in a single portable file for provisioning others. Secrets are in plain text
+// unless left out, the bundle is never encrypted with the passphrase of the exporting machine.
+
+// exportConfigBundle - configuration file as bundle, without keys if noSecrets
+func exportConfigBundle(noSecrets bool) ([]byte, error) {
+	conf, err := loadConfigV1()
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	conf.Encrypted = false
+	if noSecrets {
+		for _, hostCfg := range conf.Hosts {
+			if hostCfg != nil {
+				hostCfg.AccessKeyID, hostCfg.SecretAccessKey = "", ""
+			}
+		}
+		for _, encryption := range conf.Encryption {
+			if encryption != nil {
+				encryption.Key = ""
+			}
+		}
+	}
+	data, err := json.MarshalIndent(conf, "", "\t")
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return append(data, '\n'), nil
+}
+
+// importConfigBundle - merge bundle into the configuration file, entries of the bundle replace entries
+// with the same names. Keys of host entries are kept when the bundle has none
+func importConfigBundle(data []byte) (*configV1, error) {
+	bundle := newConfigV1()
+	if err := json.Unmarshal(data, bundle); err != nil {
+		return nil, NewIodine(iodine.New(errInvalidArgument{}, map[string]string{"Error": err.Error()}))
+	}
+	if bundle.Version != mcCurrentConfigVersion {
+		return nil, NewIodine(iodine.New(errUnknownVersion{path: "bundle", version: bundle.Version}, nil))
+	}
+	conf := newConfigV1()
+	if isMcConfigExists() {
+		var err error
+		if conf, err = loadConfigV1(); err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+	}
+	for name, aliasURL := range bundle.Aliases {
+		if !isValidAliasName(name) {
+			return nil, NewIodine(iodine.New(errInvalidAliasName{name: name}, nil))
+		}
+		conf.Aliases[name] = aliasURL
+	}
+	for hostGlob, hostCfg := range bundle.Hosts {
+		if hostCfg == nil {
+			continue
+		}
+		if existing, ok := conf.Hosts[hostGlob]; ok && existing != nil && hostCfg.AccessKeyID == "" && hostCfg.SecretAccessKey == "" {
+			hostCfg.AccessKeyID, hostCfg.SecretAccessKey = existing.AccessKeyID, existing.SecretAccessKey
+		}
+		conf.Hosts[hostGlob] = hostCfg
+	}
+	for prefix, encryption := range bundle.Encryption {
+		if encryption == nil {
+			continue
+		}
+		if conf.Encryption == nil {
+			conf.Encryption = make(map[string]*encryptionConfig)
+		}
+		if existing, ok := conf.Encryption[prefix]; ok && existing != nil && encryption.Key == "" {
+			encryption.Key = existing.Key
+		}
+		conf.Encryption[prefix] = encryption
+	}
+	return conf, nil
+}
+
+// runConfigExportCmd - mc config export FILE, "-" writes to standard output
+func runConfigExportCmd(args []string, noSecrets bool) {
+	if len(args) != 1 {
+		console.Fatalf("Incorrect number of arguments, please use \"mc config help\". %s\n", errInvalidArgument{})
+	}
+	data, err := exportConfigBundle(noSecrets)
+	if err != nil {
+		console.Fatalf("Unable to export configuration. %s\n", NewIodine(iodine.New(err, nil)))
+	}
+	if args[0] == "-" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := ioutil.WriteFile(args[0], data, 0600); err != nil {
+		console.Fatalf("Unable to write ‘%s’. %s\n", args[0], NewIodine(iodine.New(err, nil)))
+	}
+	console.Infoln("Configuration exported to ‘" + args[0] + "’.")
+}
+
+// runConfigImportBundleCmd - mc config import FILE, "-" reads standard input
+func runConfigImportBundleCmd(args []string) {
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(args[0])
+	}
+	if err != nil {
+		console.Fatalf("Unable to read ‘%s’. %s\n", args[0], NewIodine(iodine.New(err, nil)))
+	}
+	conf, err := importConfigBundle(data)
+	if err == nil {
+		err = saveConfigV1(conf)
+	}
+	if err != nil {
+		console.Fatalf("Unable to import ‘%s’. %s\n", args[0], NewIodine(iodine.New(err, nil)))
+	}
+	console.Infoln("Configuration imported from ‘" + args[0] + "’.")
+}
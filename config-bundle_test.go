@@ -0,0 +1,66 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestConfigBundle(c *C) {
+	conf, err := loadConfigV1()
+	c.Assert(err, IsNil)
+	conf.Aliases["fleet"] = "https://fleet.example.com"
+	conf.Hosts["fleet.example.com"] = &hostConfig{AccessKeyID: "access", SecretAccessKey: "secret", Lookup: "path"}
+	c.Assert(saveConfigV1(conf), IsNil)
+	defer func() {
+		conf, err := loadConfigV1()
+		c.Assert(err, IsNil)
+		delete(conf.Aliases, "fleet")
+		delete(conf.Aliases, "fleet2")
+		delete(conf.Hosts, "fleet.example.com")
+		c.Assert(saveConfigV1(conf), IsNil)
+	}()
+
+	data, err := exportConfigBundle(true)
+	c.Assert(err, IsNil)
+	bundle := newConfigV1()
+	c.Assert(json.Unmarshal(data, bundle), IsNil)
+	c.Assert(bundle.Aliases["fleet"], Equals, "https://fleet.example.com")
+	c.Assert(*bundle.Hosts["fleet.example.com"], Equals, hostConfig{Lookup: "path"})
+
+	// importing a bundle without secrets keeps the keys of this machine
+	bundle.Aliases["fleet2"] = "https://fleet.example.com/bucket"
+	bundle.Hosts["fleet.example.com"].Lookup = "dns"
+	data, err = json.Marshal(bundle)
+	c.Assert(err, IsNil)
+	conf, err = importConfigBundle(data)
+	c.Assert(err, IsNil)
+	c.Assert(conf.Aliases["fleet2"], Equals, "https://fleet.example.com/bucket")
+	c.Assert(*conf.Hosts["fleet.example.com"], Equals, hostConfig{AccessKeyID: "access", SecretAccessKey: "secret", Lookup: "dns"})
+
+	data, err = exportConfigBundle(false)
+	c.Assert(err, IsNil)
+	bundle = newConfigV1()
+	c.Assert(json.Unmarshal(data, bundle), IsNil)
+	c.Assert(bundle.Hosts["fleet.example.com"].SecretAccessKey, Equals, "secret")
+
+	_, err = importConfigBundle([]byte(`{"Version": "9.0.0"}`))
+	c.Assert(iodine.ToError(err), FitsTypeOf, errUnknownVersion{})
+}
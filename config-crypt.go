@@ -0,0 +1,255 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Secrets of encrypted configuration files are stored as "enc:" followed by base64 of salt, nonce and
+// AES-256-GCM sealed value. Keys are derived from the passphrase with PBKDF2-HMAC-SHA256, the rest of the
+// file stays plain JSON so aliases and hosts can still be edited by hand.
+const (
+	encryptedValuePrefix = "enc:"
+	passphraseIterations = 100000
+	passphraseSaltSize   = 16
+)
+
+// configPassphraseEnv - passphrase for scripts, prompted on the terminal otherwise
+const configPassphraseEnv = "MC_CONFIG_PASSPHRASE"
+
+// configSecrets - passphrase and derived keys, cached per process so it is prompted once
+var configSecrets = struct {
+	sync.Mutex
+	passphrase string
+	salt       []byte            // salt of values sealed by this process
+	keys       map[string][]byte // by passphrase and salt
+}{keys: make(map[string][]byte)}
+
+// getPassphrase - MC_CONFIG_PASSPHRASE or the passphrase prompted before, confirm asks twice for new passphrases
+func getPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(configPassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	configSecrets.Lock()
+	defer configSecrets.Unlock()
+	if configSecrets.passphrase != "" && !confirm {
+		return configSecrets.passphrase, nil
+	}
+	passphrase, err := console.ReadPassword("Passphrase of configuration file: ")
+	if err != nil {
+		return "", NewIodine(iodine.New(err, nil))
+	}
+	if confirm {
+		again, err := console.ReadPassword("Enter passphrase again: ")
+		if err != nil {
+			return "", NewIodine(iodine.New(err, nil))
+		}
+		if again != passphrase {
+			return "", NewIodine(iodine.New(errPassphraseMismatch{}, nil))
+		}
+	}
+	if passphrase == "" {
+		return "", NewIodine(iodine.New(errWrongPassphrase{}, nil))
+	}
+	configSecrets.passphrase = passphrase
+	return passphrase, nil
+}
+
+// getPassphraseCipher - AEAD of passphrase and salt
+func getPassphraseCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	configSecrets.Lock()
+	id := passphrase + "\x00" + string(salt)
+	key, ok := configSecrets.keys[id]
+	if !ok {
+		key = pbkdf2.Key([]byte(passphrase), salt, passphraseIterations, 32, sha256.New)
+		configSecrets.keys[id] = key
+	}
+	configSecrets.Unlock()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return gcm, nil
+}
+
+// sealConfigValue - encrypt value, all values sealed by a process share one salt to derive the key once
+func sealConfigValue(value, passphrase string) (string, error) {
+	configSecrets.Lock()
+	if configSecrets.salt == nil {
+		salt := make([]byte, passphraseSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			configSecrets.Unlock()
+			return "", NewIodine(iodine.New(err, nil))
+		}
+		configSecrets.salt = salt
+	}
+	salt := configSecrets.salt
+	configSecrets.Unlock()
+	gcm, err := getPassphraseCipher(passphrase, salt)
+	if err != nil {
+		return "", NewIodine(iodine.New(err, nil))
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", NewIodine(iodine.New(err, nil))
+	}
+	sealed := append(append(append([]byte(nil), salt...), nonce...), gcm.Seal(nil, nonce, []byte(value), nil)...)
+	return encryptedValuePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openConfigValue - decrypt a value of sealConfigValue
+func openConfigValue(value, passphrase string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedValuePrefix))
+	if err != nil || len(sealed) < passphraseSaltSize {
+		return "", NewIodine(iodine.New(errWrongPassphrase{}, nil))
+	}
+	gcm, err := getPassphraseCipher(passphrase, sealed[:passphraseSaltSize])
+	if err != nil {
+		return "", NewIodine(iodine.New(err, nil))
+	}
+	sealed = sealed[passphraseSaltSize:]
+	if len(sealed) < gcm.NonceSize() {
+		return "", NewIodine(iodine.New(errWrongPassphrase{}, nil))
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", NewIodine(iodine.New(errWrongPassphrase{}, nil))
+	}
+	return string(plain), nil
+}
+
+// configSecretValues - secret keys of hosts and SSE-C keys of conf
+func configSecretValues(conf *configV1) []*string {
+	var values []*string
+	for _, hostCfg := range conf.Hosts {
+		if hostCfg != nil && hostCfg.SecretAccessKey != "" {
+			values = append(values, &hostCfg.SecretAccessKey)
+		}
+	}
+	for _, encryption := range conf.Encryption {
+		if encryption != nil && encryption.Key != "" {
+			values = append(values, &encryption.Key)
+		}
+	}
+	return values
+}
+
+// decryptConfigV1 - open sealed secrets of conf in place, the passphrase is prompted on first use
+func decryptConfigV1(conf *configV1) error {
+	var passphrase string
+	for _, value := range configSecretValues(conf) {
+		if !strings.HasPrefix(*value, encryptedValuePrefix) {
+			continue
+		}
+		if passphrase == "" {
+			var err error
+			if passphrase, err = getPassphrase(false); err != nil {
+				return NewIodine(iodine.New(err, nil))
+			}
+		}
+		plain, err := openConfigValue(*value, passphrase)
+		if err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+		*value = plain
+	}
+	return nil
+}
+
+// encryptedConfigV1 - copy of conf with secrets sealed, for writing to the configuration file
+func encryptedConfigV1(conf *configV1) (*configV1, error) {
+	copied := *conf
+	copied.Hosts = make(map[string]*hostConfig)
+	for name, hostCfg := range conf.Hosts {
+		if hostCfg != nil {
+			hostCopy := *hostCfg
+			hostCfg = &hostCopy
+		}
+		copied.Hosts[name] = hostCfg
+	}
+	if conf.Encryption != nil {
+		copied.Encryption = make(map[string]*encryptionConfig)
+		for prefix, encryption := range conf.Encryption {
+			if encryption != nil {
+				encryptionCopy := *encryption
+				encryption = &encryptionCopy
+			}
+			copied.Encryption[prefix] = encryption
+		}
+	}
+	var passphrase string
+	for _, value := range configSecretValues(&copied) {
+		if strings.HasPrefix(*value, encryptedValuePrefix) {
+			continue
+		}
+		if passphrase == "" {
+			var err error
+			if passphrase, err = getPassphrase(false); err != nil {
+				return nil, NewIodine(iodine.New(err, nil))
+			}
+		}
+		sealed, err := sealConfigValue(*value, passphrase)
+		if err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+		*value = sealed
+	}
+	return &copied, nil
+}
+
+// runConfigEncryptCmd - mc config encrypt and decrypt
+func runConfigEncryptCmd(operation string, args []string) {
+	if len(args) != 0 {
+		console.Fatalf("Incorrect number of arguments, please use \"mc config help\". %s\n", errInvalidArgument{})
+	}
+	conf, err := loadConfigV1()
+	if err != nil {
+		console.Fatalf("Unable to read configuration file. %s\n", NewIodine(iodine.New(err, nil)))
+	}
+	switch operation {
+	case "encrypt":
+		if os.Getenv(configPassphraseEnv) == "" {
+			// a new passphrase is entered twice, it can not be recovered
+			if _, err := getPassphrase(true); err != nil {
+				console.Fatalf("Unable to encrypt configuration file. %s\n", NewIodine(iodine.New(err, nil)))
+			}
+		}
+		conf.Encrypted = true
+	case "decrypt":
+		conf.Encrypted = false
+	}
+	if err := saveConfigV1(conf); err != nil {
+		console.Fatalf("Unable to %s configuration file. %s\n", operation, NewIodine(iodine.New(err, nil)))
+	}
+	console.Infoln("Secret keys of configuration file are " + operation + "ed.")
+}
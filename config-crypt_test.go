@@ -0,0 +1,67 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestPassphraseKey(c *C) {
+	// values sealed by earlier releases still open
+	value, err := openConfigValue("enc:+ztXOpj9HGSPSl9b+cRkZq4Wb+yr0o6FG3HTRVNHPtajxZT2+5u5UolfHyY8wV2h22fB1Yrj/Lc=", "correct horse")
+	c.Assert(err, IsNil)
+	c.Assert(value, Equals, "crypt-secret")
+}
+
+func (s *CmdTestSuite) TestConfigEncrypt(c *C) {
+	c.Assert(os.Setenv(configPassphraseEnv, "correct horse"), IsNil)
+	defer os.Unsetenv(configPassphraseEnv)
+
+	conf, err := loadConfigV1()
+	c.Assert(err, IsNil)
+	conf.Hosts["crypt.example.com"] = &hostConfig{AccessKeyID: "access", SecretAccessKey: "crypt-secret"}
+	conf.Encrypted = true
+	c.Assert(saveConfigV1(conf), IsNil)
+	defer func() {
+		conf, err := loadConfigV1()
+		c.Assert(err, IsNil)
+		delete(conf.Hosts, "crypt.example.com")
+		conf.Encrypted = false
+		c.Assert(saveConfigV1(conf), IsNil)
+	}()
+	// saving does not seal the secrets of the caller
+	c.Assert(conf.Hosts["crypt.example.com"].SecretAccessKey, Equals, "crypt-secret")
+
+	data, err := ioutil.ReadFile(mustGetMcConfigPath())
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(data), "crypt-secret"), Equals, false)
+	c.Assert(strings.Contains(string(data), encryptedValuePrefix), Equals, true)
+
+	hostCfg, err := getHostConfig("https://crypt.example.com/bucket")
+	c.Assert(err, IsNil)
+	c.Assert(hostCfg.SecretAccessKey, Equals, "crypt-secret")
+
+	c.Assert(os.Setenv(configPassphraseEnv, "wrong"), IsNil)
+	_, err = loadConfigV1()
+	c.Assert(iodine.ToError(err), FitsTypeOf, errWrongPassphrase{})
+	c.Assert(os.Setenv(configPassphraseEnv, "correct horse"), IsNil)
+}
@@ -29,22 +29,39 @@ import (
 //
 //   ----
 //   NOTE: that the configure command only writes values to the config file.
-//   It does not use any configuration values from the environment variables.
-//
-//   One needs to edit configuration file manually, this is purposefully done
-//   so to avoid taking credentials over cli arguments. It is a security precaution
+//   Keys come from the AWS profile with ‘import aws’, from an exported config
+//   file with ‘import’, or as arguments of ‘alias add’ for scripted setups.
+//   Keys given as arguments end up in shell history, editing the config file
+//   or importing keys avoids that. MC_HOST_ variables of the environment are
+//   read by other commands, they are never written to the config file.
 //   ----
 //
 var configCmd = cli.Command{
 	Name:   "config",
 	Usage:  "Generate default configuration file [~/.mc/config.json]",
 	Action: runConfigCmd,
+	Flags:  []cli.Flag{profileFlag, noSecretsFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
    mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} generate
    mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} alias NAME HOSTURL
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} alias add NAME HOSTURL [ACCESSKEY SECRETKEY]
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} alias remove NAME
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} alias list
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} import aws
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} export FILE
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} import FILE
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} verify [ALIAS...]
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} theme list
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} theme set NAME
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} encrypt
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} decrypt{{if .Flags}}
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}{{ end }}
 
 EXAMPLES:
    1. Generate mc config.
@@ -53,6 +70,33 @@ EXAMPLES:
    2. Add alias URLs.
       $ mc config alias zek https://s3.amazonaws.com/
 
+   3. Add an alias for a Minio server with its keys, the server is contacted before the alias is saved.
+      $ mc config alias add myminio https://minio.example.com:9000 BKIKJAA5BMMU2RHO6IBB V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12
+
+   4. Add an alias for a server which is not up yet.
+      $ mc --force config alias add backup https://backup.example.com:9000
+
+   5. Remove an alias and list the remaining ones.
+      $ mc config alias remove zek
+      $ mc config alias list
+
+   6. Import keys and region of the prod profile of ~/.aws/credentials and ~/.aws/config.
+      $ mc config --profile prod import aws
+
+   7. Encrypt secret keys of the configuration file with a passphrase, MC_CONFIG_PASSPHRASE supplies it to scripts.
+      $ mc config encrypt
+
+   8. Check that all aliases are reachable and their keys are accepted, with latency and signature in use.
+      $ mc config verify
+
+   9. Provision another machine with the aliases and hosts of this one, keys are added there separately.
+      $ mc config --no-secrets export mc-fleet.json
+      $ ssh build-42 mc config import - < mc-fleet.json
+
+   10. Use the high contrast theme unless ‘--theme’ says otherwise, themes of the configuration file are listed too.
+      $ mc config theme set highcontrast
+      $ mc config theme list
+
 `,
 }
 
@@ -60,10 +104,37 @@ EXAMPLES:
 func runConfigCmd(ctx *cli.Context) {
 	// show help if nothing is set
 	if !ctx.Args().Present() || ctx.Args().First() == "help" {
-		cli.ShowCommandHelpAndExit(ctx, "config", 1) // last argument is exit code
+		cli.ShowCommandHelpAndExit(ctx, "config", exitUsage) // last argument is exit code
 	}
 	arg := ctx.Args().First()
 	tailArgs := ctx.Args().Tail()
+	if arg == "alias" && len(tailArgs) > 0 {
+		switch tailArgs[0] {
+		case "add", "remove", "list":
+			runConfigAliasCmd(tailArgs[0], tailArgs[1:])
+			return
+		}
+	}
+	if arg == "theme" && len(tailArgs) > 0 {
+		runConfigThemeCmd(tailArgs[0], tailArgs[1:])
+		return
+	}
+	if arg == "export" {
+		runConfigExportCmd(tailArgs, ctx.Bool("no-secrets"))
+		return
+	}
+	if arg == "verify" {
+		runConfigVerifyCmd(tailArgs)
+		return
+	}
+	if arg == "encrypt" || arg == "decrypt" {
+		runConfigEncryptCmd(arg, tailArgs)
+		return
+	}
+	if arg == "import" {
+		runConfigImportCmd(tailArgs, ctx.String("profile"))
+		return
+	}
 	if len(tailArgs) > 2 {
 		console.Fatalf("Incorrect number of arguments, please use \"mc config help\". %s", errInvalidArgument{})
 	}
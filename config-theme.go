@@ -0,0 +1,120 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"sort"
+
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Themes of the configuration file map theme elements to colors and text attributes, e.g.
+//
+//   "Themes": {
+//     "solarized": {"dir": "blue,bold", "file": "cyan", "error": "yellow", "fatal": "hi-white,bg-red"}
+//   }
+//
+// Elements left out are printed without colors, see console.NewTheme for names.
+
+// registerConfigThemes - themes defined in conf, usable with --theme and ‘mc config theme set’
+func registerConfigThemes(conf *configV1) error {
+	for name, colors := range conf.Themes {
+		theme, err := console.NewTheme(colors)
+		if err != nil {
+			return NewIodine(iodine.New(err, map[string]string{"Theme": name}))
+		}
+		if err := console.AddTheme(name, theme); err != nil {
+			return NewIodine(iodine.New(err, map[string]string{"Theme": name}))
+		}
+	}
+	return nil
+}
+
+// getConfigThemeName - theme of conf, the default theme if none is set
+func getConfigThemeName(conf *configV1) string {
+	if conf.Theme == "" {
+		return console.GetDefaultThemeName()
+	}
+	return conf.Theme
+}
+
+// setConfigTheme - set the theme used unless --theme is given, built-in or defined in the configuration file
+func setConfigTheme(name string) (*configV1, error) {
+	conf, err := loadConfigV1()
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if _, ok := conf.Themes[name]; !ok && !console.IsBuiltinTheme(name) {
+		return nil, NewIodine(iodine.New(errInvalidTheme{Theme: name}, nil))
+	}
+	conf.Theme = name
+	return conf, nil
+}
+
+// listThemes - built-in themes and the ones of the configuration file sorted by name
+func listThemes() ([]ThemeMessage, error) {
+	conf, err := getMcConfig()
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	current := getConfigThemeName(conf)
+	var messages []ThemeMessage
+	for _, name := range console.GetThemeNames() {
+		if console.IsBuiltinTheme(name) {
+			messages = append(messages, ThemeMessage{Theme: name, Builtin: true, Default: name == current})
+		}
+	}
+	for name := range conf.Themes {
+		if !console.IsBuiltinTheme(name) {
+			messages = append(messages, ThemeMessage{Theme: name, Default: name == current})
+		}
+	}
+	sort.Sort(byThemeName(messages))
+	return messages, nil
+}
+
+type byThemeName []ThemeMessage
+
+func (b byThemeName) Len() int           { return len(b) }
+func (b byThemeName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byThemeName) Less(i, j int) bool { return b[i].Theme < b[j].Theme }
+
+// runConfigThemeCmd - mc config theme list and set
+func runConfigThemeCmd(operation string, args []string) {
+	switch {
+	case operation == "list" && len(args) == 0:
+		messages, err := listThemes()
+		if err != nil {
+			console.Fatalf("Unable to list themes. %s\n", NewIodine(iodine.New(err, nil)))
+		}
+		for _, message := range messages {
+			console.PrintC(message)
+		}
+	case operation == "set" && len(args) == 1:
+		conf, err := setConfigTheme(args[0])
+		if err == nil {
+			err = saveConfigV1(conf)
+		}
+		if err != nil {
+			console.Fatalf("Unable to set theme ‘%s’. %s\n", args[0], NewIodine(iodine.New(err, nil)))
+		}
+		console.Infoln("Theme set to ‘" + args[0] + "’.")
+	default:
+		console.Fatalf("Incorrect number of arguments, please use \"mc config help\". %s\n", errInvalidArgument{})
+	}
+}
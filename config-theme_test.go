@@ -0,0 +1,61 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestConfigTheme(c *C) {
+	conf, err := loadConfigV1()
+	c.Assert(err, IsNil)
+	conf.Themes = map[string]map[string]string{"solarized": {"dir": "blue,bold", "fatal": "hi-white bg-red"}}
+	c.Assert(saveConfigV1(conf), IsNil)
+	defer func() {
+		conf, err := loadConfigV1()
+		c.Assert(err, IsNil)
+		conf.Theme, conf.Themes = "", nil
+		c.Assert(saveConfigV1(conf), IsNil)
+	}()
+	c.Assert(registerConfigThemes(conf), IsNil)
+	c.Assert(console.IsValidTheme("solarized"), Equals, true)
+
+	conf, err = setConfigTheme("solarized")
+	c.Assert(err, IsNil)
+	c.Assert(saveConfigV1(conf), IsNil)
+	themes, err := listThemes()
+	c.Assert(err, IsNil)
+	found := false
+	for i, theme := range themes {
+		c.Assert(i == 0 || themes[i-1].Theme < theme.Theme, Equals, true)
+		c.Assert(theme.Default, Equals, theme.Theme == "solarized")
+		found = found || theme == ThemeMessage{Theme: "solarized", Default: true}
+	}
+	c.Assert(found, Equals, true)
+
+	_, err = setConfigTheme("highcontrast")
+	c.Assert(err, IsNil)
+	_, err = setConfigTheme("missing")
+	c.Assert(iodine.ToError(err), FitsTypeOf, errInvalidTheme{})
+
+	conf.Themes["broken"] = map[string]string{"dir": "purple"}
+	c.Assert(registerConfigThemes(conf), NotNil)
+	conf.Themes = map[string]map[string]string{"minimal": {"dir": "blue"}}
+	c.Assert(registerConfigThemes(conf), NotNil) // built-in themes are not replaced
+}
@@ -0,0 +1,138 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Status of an alias checked by config verify
+const (
+	verifyStatusOK            = "ok"
+	verifyStatusUnreachable   = "unreachable"
+	verifyStatusNoKeys        = "no-keys"
+	verifyStatusInvalidKey    = "invalid-key"
+	verifyStatusSignature     = "signature-mismatch"
+	verifyStatusAccessDenied  = "access-denied"
+	verifyStatusNoSuchBucket  = "no-such-bucket"
+	verifyStatusClockSkew     = "clock-skew"
+	verifyStatusUnknownFailed = "failed"
+)
+
+// verifyStatus - status of an error of a signed request, with what to check next
+func verifyStatus(err error) (status, hint string) {
+	switch s3.ErrorCode(err) {
+	case "":
+	case "InvalidAccessKeyId":
+		return verifyStatusInvalidKey, "the access key is unknown to the server"
+	case "SignatureDoesNotMatch":
+		return verifyStatusSignature, "check the secret key, or pin ‘API’ to S3v2 or S3v4 for this host"
+	case "AccessDenied":
+		return verifyStatusAccessDenied, "the keys are valid but lack permission to list this URL"
+	case "NoSuchBucket":
+		return verifyStatusNoSuchBucket, "the bucket of the alias does not exist"
+	case "RequestTimeTooSkewed":
+		return verifyStatusClockSkew, "the clock of this machine is off"
+	default:
+		return verifyStatusUnknownFailed, ""
+	}
+	switch iodine.ToError(err).(type) {
+	case net.Error, *url.Error:
+		return verifyStatusUnreachable, "check host, port and proxy settings"
+	}
+	return verifyStatusUnknownFailed, ""
+}
+
+// verifyAlias - list the root of an alias URL with its keys, like any command would
+func verifyAlias(name, aliasURL string) ConfigVerifyMessage {
+	message := ConfigVerifyMessage{Alias: name, URL: aliasURL}
+	hostCfg, err := getHostConfig(aliasURL)
+	if err != nil {
+		message.Status, message.Error = verifyStatusUnknownFailed, iodine.ToError(err).Error()
+		return message
+	}
+	if hostCfg.AccessKeyID == globalAccessKeyID || hostCfg.SecretAccessKey == globalSecretAccessKey {
+		message.Status, message.Hint = verifyStatusNoKeys, "keys are still the placeholders of ‘mc config generate’"
+		return message
+	}
+	clnt, err := getNewClient(aliasURL, hostCfg)
+	if err != nil {
+		message.Status, message.Error = verifyStatusUnknownFailed, iodine.ToError(err).Error()
+		return message
+	}
+	start := time.Now()
+	_, err = clnt.Stat()
+	message.Latency = float64(time.Since(start)) / float64(time.Millisecond)
+	if reporter, ok := clnt.(client.SignatureReporter); ok {
+		message.Signature, message.Region = reporter.Signature()
+	}
+	if err != nil {
+		message.Status, message.Hint = verifyStatus(err)
+		message.Error = iodine.ToError(err).Error()
+		return message
+	}
+	message.Status = verifyStatusOK
+	return message
+}
+
+// verifyAliases - check names, all aliases if empty, in alias order
+func verifyAliases(names []string) ([]ConfigVerifyMessage, error) {
+	conf, err := getMcConfig()
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if len(names) == 0 {
+		for name := range conf.Aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	var messages []ConfigVerifyMessage
+	for _, name := range names {
+		aliasURL, ok := conf.Aliases[name]
+		if !ok {
+			return nil, NewIodine(iodine.New(errAliasNotFound{name: name}, nil))
+		}
+		messages = append(messages, verifyAlias(name, aliasURL))
+	}
+	return messages, nil
+}
+
+// runConfigVerifyCmd - mc config verify, exits with exitPartialFailure if any alias failed
+func runConfigVerifyCmd(args []string) {
+	messages, err := verifyAliases(args)
+	if err != nil {
+		console.Fatalf("Unable to verify aliases. %s\n", NewIodine(iodine.New(err, nil)))
+	}
+	failed := false
+	for _, message := range messages {
+		console.PrintC(message)
+		failed = failed || message.Status != verifyStatusOK
+	}
+	if failed {
+		os.Exit(exitPartialFailure)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/minio/mc/pkg/fakes3"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestConfigVerify(c *C) {
+	server := fakes3.NewServer("bucket", "denied")
+	defer server.Close()
+	server.AddFault(fakes3.Fault{Path: "/denied", Status: http.StatusForbidden, Code: "AccessDenied"})
+	mismatch := fakes3.NewServer()
+	defer mismatch.Close()
+	mismatch.AddFault(fakes3.Fault{Method: "GET", Path: "/", Status: http.StatusForbidden, Code: "SignatureDoesNotMatch"})
+	down := fakes3.NewServer()
+	down.Close()
+
+	aliases := map[string]string{
+		"verify-ok":       server.URL + "/bucket",
+		"verify-denied":   server.URL + "/denied",
+		"verify-mismatch": mismatch.URL,
+		"verify-down":     down.URL,
+	}
+	conf, err := loadConfigV1()
+	c.Assert(err, IsNil)
+	for name, aliasURL := range aliases {
+		conf.Aliases[name] = aliasURL
+	}
+	c.Assert(saveConfigV1(conf), IsNil)
+	defer func() {
+		conf, err := loadConfigV1()
+		c.Assert(err, IsNil)
+		for name := range aliases {
+			delete(conf.Aliases, name)
+		}
+		c.Assert(saveConfigV1(conf), IsNil)
+	}()
+
+	messages, err := verifyAliases([]string{"verify-ok", "verify-denied", "verify-mismatch", "verify-down"})
+	c.Assert(err, IsNil)
+	c.Assert(messages, HasLen, 4)
+	c.Assert(messages[0].Status, Equals, verifyStatusOK)
+	c.Assert(messages[0].Signature, Equals, "S3v4")
+	c.Assert(messages[1].Status, Equals, verifyStatusAccessDenied)
+	c.Assert(messages[2].Status, Equals, verifyStatusSignature)
+	c.Assert(messages[3].Status, Equals, verifyStatusUnreachable)
+
+	_, err = verifyAliases([]string{"verify-missing"})
+	c.Assert(iodine.ToError(err), FitsTypeOf, errAliasNotFound{})
+}
@@ -29,13 +29,22 @@ import (
 )
 
 type configV1 struct {
-	Version string
-	Aliases map[string]string
-	Hosts   map[string]*hostConfig
+	Version    string
+	Aliases    map[string]string
+	Hosts      map[string]*hostConfig
+	Encryption map[string]*encryptionConfig `json:",omitempty"`
+	// Encrypted secret keys are sealed with a passphrase, see config-crypt.go
+	Encrypted bool `json:",omitempty"`
+	// Console theme set by ‘mc config theme set’ and themes defined by users, see config-theme.go
+	Theme  string                       `json:",omitempty"`
+	Themes map[string]map[string]string `json:",omitempty"`
 }
 
 // cached variables should *NEVER* be accessed directly from outside this file.
-var cache sync.Pool
+var (
+	cacheMutex   sync.Mutex
+	cachedConfig *configV1
+)
 
 // customConfigDir contains the whole path to config dir. Only access via get/set functions.
 var mcCustomConfigDir string
@@ -103,9 +112,10 @@ func mustGetMcConfigPath() string {
 	return p
 }
 
-// getMcConfig - reads configuration file and returns config
+// getMcConfig - reads configuration file and returns config, aliases of MC_HOST_ environment
+// variables are added and need no configuration file
 func getMcConfig() (*configV1, error) {
-	if !isMcConfigExists() {
+	if !isMcConfigAvailable() {
 		return nil, NewIodine(iodine.New(errInvalidArgument{}, nil))
 	}
 
@@ -115,8 +125,11 @@ func getMcConfig() (*configV1, error) {
 	}
 
 	// Cached in private global variable.
-	if v := cache.Get(); v != nil { // Use previously cached config.
-		return v.(quick.Config).Data().(*configV1), nil
+	cacheMutex.Lock()
+	cached := cachedConfig
+	cacheMutex.Unlock()
+	if cached != nil { // Use previously cached config.
+		return cached, nil
 	}
 
 	conf := newConfigV1()
@@ -125,11 +138,22 @@ func getMcConfig() (*configV1, error) {
 		return nil, NewIodine(iodine.New(err, nil))
 	}
 
-	err = qconf.Load(configFile)
-	if err != nil {
+	if isMcConfigExists() {
+		err = qconf.Load(configFile)
+		if err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+		if err := decryptConfigV1(qconf.Data().(*configV1)); err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+	}
+	// never written back, changes of the configuration file are made on a fresh copy of it
+	if err := addEnvHosts(qconf.Data().(*configV1)); err != nil {
 		return nil, NewIodine(iodine.New(err, nil))
 	}
-	cache.Put(qconf)
+	cacheMutex.Lock()
+	cachedConfig = qconf.Data().(*configV1)
+	cacheMutex.Unlock()
 	return qconf.Data().(*configV1), nil
 
 }
@@ -166,12 +190,30 @@ func writeConfig(config quick.Config) error {
 	if err != nil {
 		return NewIodine(iodine.New(err, nil))
 	}
+	// secrets of encrypted configuration files are sealed on every write, decrypted copies stay in memory
+	if conf, ok := config.Data().(*configV1); ok && conf.Encrypted {
+		sealed, err := encryptedConfigV1(conf)
+		if err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+		if config, err = quick.New(sealed); err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+	}
 	if err := config.Save(configPath); err != nil {
 		return NewIodine(iodine.New(err, nil))
 	}
+	resetMcConfigCache()
 	return nil
 }
 
+// resetMcConfigCache - drop cached configuration, it is read again on next use
+func resetMcConfigCache() {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	cachedConfig = nil
+}
+
 // newConfigV1() - get new config version 1.0
 func newConfigV1() *configV1 {
 	conf := new(configV1)
@@ -198,6 +240,19 @@ func newConfig() (config quick.Config, err error) {
 	dlHostConfig.AccessKeyID = ""
 	dlHostConfig.SecretAccessKey = ""
 
+	// Path to service account JSON key needs to be filled in
+	gcsHostConfig := new(hostConfig)
+	gcsHostConfig.AccessKeyID = ""
+	gcsHostConfig.SecretAccessKey = ""
+	gcsHostConfig.API = hostAPIGCS
+	gcsHostConfig.CredentialsFile = ""
+
+	// B2 account id and application key
+	b2HostConfig := new(hostConfig)
+	b2HostConfig.AccessKeyID = globalAccessKeyID
+	b2HostConfig.SecretAccessKey = globalSecretAccessKey
+	b2HostConfig.API = hostAPIB2
+
 	// Your example host config
 	exampleHostConf := new(hostConfig)
 	exampleHostConf.AccessKeyID = globalAccessKeyID
@@ -207,12 +262,16 @@ func newConfig() (config quick.Config, err error) {
 	conf.Hosts["s3*.amazonaws.com"] = s3HostConf
 	conf.Hosts["play.minio.io:9000"] = playHostConfig
 	conf.Hosts["dl.minio.io:9000"] = dlHostConfig
+	conf.Hosts["storage.googleapis.com"] = gcsHostConfig
+	conf.Hosts["api.backblazeb2.com"] = b2HostConfig
 
 	aliases := make(map[string]string)
 	aliases["s3"] = "https://s3.amazonaws.com"
 	aliases["play"] = "https://play.minio.io:9000"
 	aliases["dl"] = "https://dl.minio.io:9000"
 	aliases["localhost"] = "http://localhost:9000"
+	aliases["gcs"] = "https://storage.googleapis.com"
+	aliases["b2"] = "https://api.backblazeb2.com"
 	conf.Aliases = aliases
 	config, err = quick.New(conf)
 	if err != nil {
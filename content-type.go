@@ -0,0 +1,61 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/minio/mc/pkg/client"
+)
+
+// sniffLength - bytes examined by http.DetectContentType
+const sniffLength = 512
+
+// withContentType - metadata with Content-Type guessed from the extension of name, or from the first bytes
+// of reader when the extension is unknown and sniff is set. Content-Type already in metadata is kept.
+// Returned reader must be read instead of reader, it replays sniffed bytes.
+func withContentType(name string, reader io.Reader, metadata map[string]string, sniff bool) (io.Reader, map[string]string) {
+	if metadata["Content-Type"] != "" {
+		return reader, metadata
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if mapped, ok := reader.(client.MappedReader); ok && contentType == "" && sniff {
+		// sniffed from the mapping, a buffered reader would hide it from uploaders
+		data := mapped.Bytes()
+		if len(data) > sniffLength {
+			data = data[:sniffLength]
+		}
+		if len(data) > 0 {
+			contentType = http.DetectContentType(data)
+		}
+	} else if contentType == "" && sniff {
+		bufReader := bufio.NewReaderSize(reader, sniffLength)
+		// short files are sniffed as a whole, read errors surface on upload
+		if data, _ := bufReader.Peek(sniffLength); len(data) > 0 {
+			contentType = http.DetectContentType(data)
+		}
+		reader = bufReader
+	}
+	if contentType == "" {
+		return reader, metadata
+	}
+	return reader, mergeAttrs(metadata, map[string]string{"Content-Type": contentType})
+}
@@ -0,0 +1,53 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestWithContentType(c *C) {
+	png := []byte("\x89PNG\r\n\x1a\nrest of image")
+
+	// unknown extension, type is sniffed and sniffed bytes are replayed
+	reader, metadata := withContentType("s3:photos/logo", bytes.NewReader(png), nil, true)
+	c.Assert(metadata, DeepEquals, map[string]string{"Content-Type": "image/png"})
+	data, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, png)
+
+	// known extension wins over content bytes
+	_, metadata = withContentType("s3:www/index.html", bytes.NewReader(png), map[string]string{"Cache-Control": "no-cache"}, true)
+	c.Assert(strings.HasPrefix(metadata["Content-Type"], "text/html"), Equals, true)
+	c.Assert(metadata["Cache-Control"], Equals, "no-cache")
+
+	// explicit types are kept
+	_, metadata = withContentType("s3:photos/logo", bytes.NewReader(png), map[string]string{"Content-Type": "image/x-icon"}, true)
+	c.Assert(metadata["Content-Type"], Equals, "image/x-icon")
+
+	// sniffing disabled
+	_, metadata = withContentType("s3:photos/logo", bytes.NewReader(png), nil, false)
+	c.Assert(metadata, IsNil)
+
+	// empty files have nothing to sniff
+	_, metadata = withContentType("s3:photos/empty", bytes.NewReader(nil), nil, true)
+	c.Assert(metadata, IsNil)
+}
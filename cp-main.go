@@ -17,16 +17,16 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
-	"fmt"
 	"io"
-	"math"
 	"os"
-	"runtime"
+	"path/filepath"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/minio/pkg/iodine"
@@ -37,6 +37,7 @@ var cpCmd = cli.Command{
 	Name:   "cp",
 	Usage:  "Copy files and folders from many sources to a single destination",
 	Action: runCopyCmd,
+	Flags:  []cli.Flag{lockFlag, namePolicyFlag, windowsNamesFlag, parentsFlag, attrFileFlag, tagsFlag, setTagsFlag, aclFlag, preserveFlag, noMetadataFlag, noSniffFlag, manifestFlag, mmapFlag, parallelRangeFlag, versionIDFlag, rewindFlag, planFlag, yesFlag, noResumeFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
@@ -66,76 +67,275 @@ EXAMPLES:
    5. Copy an object of non english characters to Amazon S3 object storage.
       $ mc {{.Name}} 本語 s3:andoria/本語
 
+   6. Copy a folder recursively from a cron job, skipping the run if another mc process is copying to the same target.
+      $ mc {{.Name}} --lock backup/... https://play.minio.io:9000/archive/
+
+   7. Copy a bucket recursively to local filesystem, renaming objects whose names are not valid on Windows.
+      $ mc {{.Name}} --name-policy sanitize --windows-names s3:documents/2014/... C:\backup\2014
+
+   8. Copy a file to Minio object storage, recreating its source directory structure under the target.
+      $ mc {{.Name}} --parents backup/2015/january/report.pdf https://play.minio.io:9000/archive/
+
+   9. Publish a static site to Amazon S3 object storage, setting content type and cache control by file extension.
+      $ mc {{.Name}} --attr-file site-attrs.json public/... s3:www.example.com
+
+   10. Copy only objects tagged as production data to a local folder.
+      $ mc {{.Name}} --tags "env=prod" s3:datasets/... /mnt/datasets/
+
+   11. Stream a database dump from standard input to Amazon S3 object storage.
+      $ pg_dump shop | gzip | mc {{.Name}} - s3:backups/shop.sql.gz
+
+   12. Stream an object from Amazon S3 object storage to standard output.
+      $ mc {{.Name}} s3:backups/shop.sql.gz - | gunzip | psql shop
+
+   13. Restore a home folder from a tar backup as root, keeping owners and permissions of its files.
+      $ sudo mc {{.Name}} --preserve tar:///mnt/backup/home.tar/alice/... /home/alice
+
+   14. Copy a bucket recursively to another Minio server without its metadata and tags.
+      $ mc {{.Name}} --no-metadata https://play.minio.io:9000/photos/... https://minio.example.com:9000/photos
+
+   15. Migrate patient records to Amazon S3 object storage, keeping a signed manifest for ‘mc verify-manifest’.
+      $ mc {{.Name}} --manifest records-2015.json /mnt/records/2015/... s3:records/2015
+
+   16. Preview where files of several folders would land before copying them.
+      $ mc {{.Name}} --plan --parents backup/2014/... backup/2015/... https://play.minio.io:9000/archive/
+
+   17. Upload large disk images from NVMe storage to Minio object storage straight from memory mapped files.
+      $ mc {{.Name}} --mmap 64MiB /srv/images/... https://play.minio.io:9000/images/
+
+   18. Download a multi-GB dataset from Amazon S3 object storage with 8 concurrent ranged GETs per object.
+      $ mc {{.Name}} --parallel-range 8 s3:genomes/hg38/... /data/hg38/
+
+   19. Run a nightly backup from cron, picking up where an interrupted run of it stopped without asking.
+      $ mc {{.Name}} --yes backup/... s3:backup/
+
+   20. Restore an older version of an object from a versioned bucket to a local file.
+      $ mc {{.Name}} --version-id 3HL4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY+MTRCxf3vjVBH40Nr8X8gdRQBpUMLUo s3:docs/contract.pdf contract-v1.pdf
+
+   21. Restore a folder of a versioned bucket as it was a week ago, objects removed since included.
+      $ mc {{.Name}} --rewind 7d s3:docs/contracts/... /mnt/restore/
+
+   22. Upload a dataset to Amazon S3 object storage, tagging every object for lifecycle rules and cost reports.
+      $ mc {{.Name}} --set-tags "project=genomes&tier=hot" /data/hg38/... s3:genomes/hg38/
+
+   23. Publish a folder of images to a bucket on Amazon S3 object storage readable by anyone.
+      $ mc {{.Name}} --acl public-read images/... s3:website/images/
+
 `,
 }
 
-// doCopy - Copy a singe file from source to destination
-func doCopy(cpURLs copyURLs, bar *barSend, cpQueue chan bool, wg *sync.WaitGroup) error {
-	defer wg.Done() // Notify that this copy routine is done.
-	defer func() {
-		<-cpQueue
-	}()
+// copyOptions - how every file of a copy session is copied, from the flags saved in the session header
+type copyOptions struct {
+	attrs         attrRules         // metadata of --attr-file
+	setTags       map[string]string // tags of --set-tags
+	acl           string            // canned ACL of --acl
+	copyMetadata  bool              // metadata and tags of sources are copied, unless --no-metadata
+	sniff         bool              // content type is sniffed from data, unless --no-sniff
+	preserve      bool              // owner and permissions of --preserve
+	mmapSize      int64             // files up to this size are memory mapped, see --mmap
+	parallelRange int               // ranged GETs per download, see --parallel-range
+}
 
-	if !globalQuietFlag || !globalJSONFlag {
+// doCopy - Copy a singe file from source to destination
+func doCopy(cpURLs copyURLs, opts copyOptions, bar *barSend) error {
+	if showProgressBar() {
 		bar.SetCaption(cpURLs.SourceContent.Name + ": ")
 	}
 
-	reader, length, err := getSource(cpURLs.SourceContent.Name)
+	metadata := opts.attrs.lookup(cpURLs.TargetContent.Name)
+	var tags map[string]string
+	if opts.copyMetadata {
+		sourceMetadata, sourceTags, err := getSourceMetadata(cpURLs.SourceContent.Name, cpURLs.SourceContent.VersionID, cpURLs.TargetContent.Name)
+		if err != nil {
+			if showProgressBar() {
+				bar.ErrorGet(cpURLs.SourceContent.Size)
+			}
+			return NewIodine(iodine.New(err, map[string]string{"URL": cpURLs.SourceContent.Name}))
+		}
+		// rules of --attr-file win over metadata of source
+		metadata = mergeAttrs(sourceMetadata, metadata)
+		tags = sourceTags
+	}
+	// tags of --set-tags win over tags of source, they are set by the upload
+	metadata, err := withTags(cpURLs.TargetContent.Name, metadata, mergeAttrs(tags, opts.setTags))
 	if err != nil {
-		if !globalQuietFlag || !globalJSONFlag {
-			bar.ErrorGet(length)
+		if showProgressBar() {
+			bar.ErrorPut(cpURLs.SourceContent.Size)
 		}
-		return NewIodine(iodine.New(err, map[string]string{"URL": cpURLs.SourceContent.Name}))
+		return NewIodine(iodine.New(err, map[string]string{"URL": cpURLs.TargetContent.Name}))
 	}
 
-	var newReader io.ReadCloser
-	if globalQuietFlag || globalJSONFlag {
+	var progress func(int64)
+	if showProgressBar() {
+		progress = bar.Progress
+	} else if !globalQuietFlag {
 		console.PrintC(CopyMessage{
 			Source: cpURLs.SourceContent.Name,
 			Target: cpURLs.TargetContent.Name,
 			Length: cpURLs.SourceContent.Size,
 		})
-		newReader = reader
-	} else {
-		// set up progress
-		newReader = bar.NewProxyReader(reader)
 	}
-	defer newReader.Close()
 
-	err = putTarget(cpURLs.TargetContent.Name, length, newReader)
+	length := cpURLs.SourceContent.Size
+	var copied bool
+	if cpURLs.SourceContent.VersionID == "" { // older versions are read in a single stream
+		copied, err = copyRanges(cpURLs.SourceContent.Name, cpURLs.TargetContent.Name, length, opts.parallelRange, progress)
+	}
+	if !copied {
+		var reader io.ReadCloser
+		switch {
+		case err != nil:
+		case cpURLs.SourceContent.VersionID != "":
+			reader, length, err = getSourceVersion(cpURLs.SourceContent.Name, cpURLs.SourceContent.VersionID)
+		default:
+			reader, length, err = getMappedSource(cpURLs.SourceContent.Name, cpURLs.SourceContent.Size, opts.mmapSize)
+		}
+		if err != nil {
+			if showProgressBar() {
+				bar.ErrorGet(length)
+			}
+			return NewIodine(iodine.New(err, map[string]string{"URL": cpURLs.SourceContent.Name}))
+		}
+		if progress != nil { // set up progress
+			reader = bar.NewProxyReader(reader)
+		}
+		var putReader io.Reader
+		putReader, metadata = withContentType(cpURLs.TargetContent.Name, reader, metadata, opts.sniff)
+		err = putTargetWithMetadata(cpURLs.TargetContent.Name, length, putReader, metadata)
+		reader.Close()
+	}
+	if err == nil && opts.acl != "" {
+		err = setTargetACL(cpURLs.TargetContent.Name, opts.acl)
+	}
 	if err != nil {
-		if !globalQuietFlag || !globalJSONFlag {
+		if showProgressBar() {
 			bar.ErrorPut(length)
 		}
-		console.Println("")
-		console.Errorln(NewIodine(err))
+		return NewIodine(iodine.New(err, map[string]string{"URL": cpURLs.TargetContent.Name}))
+	}
+	if opts.preserve && cpURLs.SourceContent.POSIX != nil {
+		err = setTargetPOSIXAttrs(cpURLs.TargetContent.Name, *cpURLs.SourceContent.POSIX)
+		if err != nil {
+			console.Errorln(NewIodine(iodine.New(err, map[string]string{"URL": cpURLs.TargetContent.Name})))
+		}
 	}
 	return nil
 }
 
-// doCopyFake - Perform a fake copy to update the progress bar appropriately.
-func doCopyFake(sURLs copyURLs, bar *barSend) (err error) {
-	if !globalQuietFlag || !globalJSONFlag {
-		bar.Progress(sURLs.SourceContent.Size)
+// streamFlags are the flags of cp which have no meaning for a stream, they are rejected instead of being ignored.
+var streamFlags = []string{"tags", "preserve", "manifest", "parents", "plan", "parallel-range", "mmap", "version-id", "rewind"}
+
+// targetFlags are the flags of cp which only apply to a target written by a stream, not to standard output.
+var targetFlags = []string{"set-tags", "acl", "name-policy", "windows-names", "lock"}
+
+// checkCopyStreamSyntax rejects flags which cannot be applied to a copy from standard input or to standard output.
+func checkCopyStreamSyntax(ctx *cli.Context, targetURL string) {
+	for _, flag := range streamFlags {
+		if ctx.IsSet(flag) {
+			console.Fatalf("‘--%s’ cannot be used to copy from standard input or to standard output. %s\n", flag, errInvalidArgument{})
+		}
+	}
+	if !isStdioURL(targetURL) {
+		return
+	}
+	for _, flag := range targetFlags {
+		if ctx.IsSet(flag) {
+			console.Fatalf("‘--%s’ cannot be used to copy to standard output. %s\n", flag, errInvalidArgument{})
+		}
 	}
-	return nil
 }
 
-// doPrepareCopyURLs scans the source URL and prepares a list of objects for copying.
-func doPrepareCopyURLs(session *sessionV2, trapCh <-chan bool) {
+// doCopyStream - copy from standard input or to standard output, streams cannot be resumed so no session is kept.
+// header holds the flags of cp which apply to streams.
+func doCopyStream(sourceURLs []string, targetURL string, header *sessionV2Header) {
+	var setTags map[string]string
+	if header.SetTags != "" {
+		var err error
+		if setTags, err = parseTagFilter(header.SetTags); err != nil {
+			console.Fatalf("Invalid tags ‘%s’. %s\n", header.SetTags, err)
+		}
+	}
+	if header.TargetLock {
+		locks, err := acquireTargetLocks([]string{targetURL})
+		if err != nil {
+			console.Fatalf("Unable to lock target ‘%s’. %s\n", targetURL, NewIodine(iodine.New(err, nil)))
+		}
+		defer releaseTargetLocks(locks)
+	}
+	planned := make(map[string]bool)
+	for _, sourceURL := range sourceURLs {
+		streamTargetURL, err := applyNamePolicy(sourceURL, targetURL, "", objectNamePolicy(header.NamePolicy), header.WindowsNames, planned)
+		if err != nil {
+			console.Fatalf("Target name validation failed for ‘%s’. %s\n", sourceURL, err)
+		}
+		if streamTargetURL == "" { // Skipped by name policy.
+			continue
+		}
+		reader, length, err := getSource(sourceURL)
+		if err != nil {
+			console.Fatalf("Unable to read from source ‘%s’. %s\n", sourceURL, NewIodine(iodine.New(err, nil)))
+		}
+		if globalJSONFlag && !isStdioURL(streamTargetURL) {
+			console.PrintC(CopyMessage{
+				Source: sourceURL,
+				Target: streamTargetURL,
+				Length: length,
+			})
+		}
+		// length is 0 for standard input, targets read till EOF
+		putReader, metadata := withContentType(streamTargetURL, reader, header.Attrs.lookup(streamTargetURL), !header.NoSniff)
+		if metadata, err = withTags(streamTargetURL, metadata, setTags); err != nil {
+			console.Fatalf("Unable to set tags on ‘%s’. %s\n", streamTargetURL, NewIodine(iodine.New(err, nil)))
+		}
+		err = putTargetWithMetadata(streamTargetURL, length, putReader, metadata)
+		reader.Close()
+		if err != nil {
+			if e, ok := iodine.ToError(err).(*os.PathError); ok && e.Err == syscall.EPIPE {
+				return // standard output closed by the reader, like ‘mc cp s3:logs/big.log - | head’
+			}
+			console.Fatalf("Unable to write to target ‘%s’. %s\n", streamTargetURL, NewIodine(iodine.New(err, nil)))
+		}
+		if header.ACL != "" {
+			if err = setTargetACL(streamTargetURL, header.ACL); err != nil {
+				console.Fatalf("Unable to set ACL on ‘%s’. %s\n", streamTargetURL, NewIodine(iodine.New(err, nil)))
+			}
+		}
+	}
+}
+
+// planCopyURLs scans the source URLs of header and calls found for every object cp would copy, with the target
+// name rules of header applied. abort is called before exiting on fatal errors and interrupts.
+func planCopyURLs(header *sessionV2Header, trapCh <-chan bool, abort func(), found func(copyURLs)) {
 	// Separate source and target. 'cp' can take only one target,
 	// but any number of sources, even the recursive URLs mixed in-between.
-	sourceURLs := session.Header.CommandArgs[:len(session.Header.CommandArgs)-1]
-	targetURL := session.Header.CommandArgs[len(session.Header.CommandArgs)-1] // Last one is target
-
-	var totalBytes int64
-	var totalObjects int
-
-	// Create a session data file to store the processed URLs.
-	dataFP := session.NewDataWriter()
-	scanBar := scanBarFactory(strings.Join(sourceURLs, " "))
-	URLsCh := prepareCopyURLs(sourceURLs, targetURL)
+	sourceURLs := header.CommandArgs[:len(header.CommandArgs)-1]
+	targetURL := header.CommandArgs[len(header.CommandArgs)-1] // Last one is target
+
+	var URLsCh <-chan copyURLs
+	switch {
+	case header.VersionID != "" || header.Rewind != "":
+		at, err := time.Parse(time.RFC3339Nano, header.Rewind)
+		if err != nil && header.Rewind != "" {
+			abort()
+			console.Fatalf("Invalid rewind time ‘%s’. %s\n", header.Rewind, err)
+		}
+		URLsCh = prepareCopyURLsAt(sourceURLs, targetURL, header.VersionID, at)
+	default:
+		URLsCh = prepareCopyURLs(sourceURLs, targetURL)
+	}
 	done := false
+	// target URLs planned so far, for the name policy to keep them unique.
+	planned := make(map[string]bool)
+
+	var tags map[string]string
+	if header.Tags != "" {
+		var err error
+		tags, err = parseTagFilter(header.Tags)
+		if err != nil {
+			abort()
+			console.Fatalf("Invalid tag filter ‘%s’. %s\n", header.Tags, err)
+		}
+	}
 
 	for done == false {
 		select {
@@ -145,92 +345,332 @@ func doPrepareCopyURLs(session *sessionV2, trapCh <-chan bool) {
 				break
 			}
 			if cpURLs.Error != nil {
+				console.Logf(console.LogPlanner, console.LogWarn, "Skipping source. %s\n", cpURLs.Error)
 				console.Errorln(cpURLs.Error)
 				break
 			}
-
-			jsonData, err := json.Marshal(cpURLs)
+			if len(tags) > 0 {
+				match, err := matchTags(cpURLs.SourceContent.Name, tags)
+				if err != nil {
+					if _, ok := iodine.ToError(err).(errTagsNotSupported); ok {
+						abort()
+						console.Fatalf("Unable to filter by tags. %s\n", err)
+					}
+					console.Errorln(err)
+					break
+				}
+				if !match {
+					console.Logf(console.LogPlanner, console.LogDebug, "Skipping ‘%s’, tags do not match.\n", cpURLs.SourceContent.Name)
+					break
+				}
+			}
+			if header.Parents {
+				parentsURL, err := parentsTargetURL(cpURLs.SourceContent.Name, targetURL)
+				if err != nil {
+					console.Errorln(NewIodine(err))
+					break
+				}
+				cpURLs.TargetContent.Name = parentsURL
+			}
+			newTargetURL, err := applyNamePolicy(cpURLs.SourceContent.Name, cpURLs.TargetContent.Name, targetURL,
+				objectNamePolicy(header.NamePolicy), header.WindowsNames, planned)
 			if err != nil {
-				session.Close()
-				console.Fatalf("Unable to marshal URLs to JSON. %s\n", err)
+				abort()
+				console.Fatalf("Target name validation failed for ‘%s’. %s\n", cpURLs.SourceContent.Name, err)
 			}
-			fmt.Fprintln(dataFP, string(jsonData))
-			scanBar(cpURLs.SourceContent.Name)
-
-			totalBytes += cpURLs.SourceContent.Size
-			totalObjects++
+			if newTargetURL == "" { // Skipped by name policy.
+				console.Logf(console.LogPlanner, console.LogDebug, "Skipping ‘%s’, rejected by name policy.\n", cpURLs.SourceContent.Name)
+				break
+			}
+			cpURLs.TargetContent.Name = newTargetURL
+			console.Logf(console.LogPlanner, console.LogDebug, "Planned ‘%s’ -> ‘%s’.\n", cpURLs.SourceContent.Name, cpURLs.TargetContent.Name)
+			found(cpURLs)
 		case <-trapCh:
-			session.Close() // If we are interrupted during the URL scanning, we drop the session.
+			abort()
 			os.Exit(0)
 		}
 	}
+}
+
+// doPrepareCopyURLs scans the source URL and prepares a list of objects for copying.
+func doPrepareCopyURLs(session *sessionV2, trapCh <-chan bool) {
+	var totalBytes int64
+	var totalObjects int
+
+	// Create a session data file to store the processed URLs.
+	dataWriter, err := session.NewDataWriter()
+	if err != nil {
+		session.Close()
+		console.Fatalf("Unable to create session data file. %s\n", err)
+	}
+	sourceURLs := session.Header.CommandArgs[:len(session.Header.CommandArgs)-1]
+	scanBar := scanBarFactory(strings.Join(sourceURLs, " "))
+	// If we are interrupted during the URL scanning, we drop the session.
+	abort := func() { session.Close() }
+	planCopyURLs(session.Header, trapCh, abort, func(cpURLs copyURLs) {
+		if err := dataWriter.Append(cpURLs); err != nil {
+			session.Close()
+			console.Fatalf("Unable to write URLs to session data file. %s\n", err)
+		}
+		scanBar(cpURLs.SourceContent.Name)
+
+		totalBytes += cpURLs.SourceContent.Size
+		totalObjects++
+	})
 	session.Header.TotalBytes = totalBytes
 	session.Header.TotalObjects = totalObjects
+	console.Logf(console.LogPlanner, console.LogInfo, "Planned %d objects, %d bytes.\n", totalObjects, totalBytes)
+	if err := dataWriter.Commit(); err != nil {
+		session.Close()
+		console.Fatalf("Unable to save session data file. %s\n", err)
+	}
 	session.Save()
 }
 
 func doCopyCmdSession(session *sessionV2) {
 	trapCh := signalTrap(os.Interrupt, os.Kill)
 
+	if session.Header.TargetLock {
+		targetURL := session.Header.CommandArgs[len(session.Header.CommandArgs)-1]
+		locks, err := acquireTargetLocks([]string{targetURL})
+		if err != nil {
+			if !session.HasData() { // Keep resumable sessions around for a later attempt.
+				session.Close()
+			}
+			console.Fatalf("Unable to lock target ‘%s’. %s\n", targetURL, NewIodine(iodine.New(err, nil)))
+		}
+		defer releaseTargetLocks(locks)
+	}
+
+	pauseCh := pauseTrap()
+	if err := session.Start(); err != nil {
+		console.Fatalf("Unable to save session ‘%s’. %s\n", session.SessionID, err)
+	}
+
 	if !session.HasData() {
 		doPrepareCopyURLs(session, trapCh)
 	}
 
+	opts := copyOptions{
+		attrs:         session.Header.Attrs,
+		acl:           session.Header.ACL,
+		copyMetadata:  !session.Header.NoMetadata,
+		sniff:         !session.Header.NoSniff,
+		preserve:      session.Header.Preserve,
+		mmapSize:      session.Header.MmapSize,
+		parallelRange: session.Header.ParallelRange,
+	}
+	if session.Header.SetTags != "" {
+		var err error
+		if opts.setTags, err = parseTagFilter(session.Header.SetTags); err != nil {
+			console.Fatalf("Invalid tags ‘%s’. %s\n", session.Header.SetTags, err)
+		}
+	}
+
 	wg := new(sync.WaitGroup)
-	cpQueue := make(chan bool, int(math.Max(float64(runtime.NumCPU())-1, 1)))
+	// Parallel of the target host wins over the ones of sources
+	args := session.Header.CommandArgs
+	cpQueue := make(chan bool, getParallel(append([]string{args[len(args)-1]}, args[:len(args)-1]...)...))
 
-	scanner := bufio.NewScanner(session.NewDataReader())
-	isCopied := isCopiedFactory(session.Header.LastCopied)
+	scanner := session.NewDataScanner()
 
 	var bar barSend
-	if !globalQuietFlag || !globalJSONFlag { // set up progress bar
+	if showProgressBar() { // set up progress bar
 		bar = newCpBar()
 		defer bar.Finish()
 		bar.Extend(session.Header.TotalBytes)
+		// entries finished before a resume are not copied again, count them right away
+		bar.Progress(session.Header.CopiedBytes)
 	}
 
-	for scanner.Scan() {
+	for index := 0; scanner.Scan(); index++ {
+		if session.IsDone(index) {
+			continue
+		}
 		var cpURLs copyURLs
 		json.Unmarshal([]byte(scanner.Text()), &cpURLs)
-		if isCopied(cpURLs.SourceContent.Name) {
-			doCopyFake(cpURLs, &bar)
-		} else {
-			select {
-			case cpQueue <- true:
-				wg.Add(1)
-				go doCopy(cpURLs, &bar, cpQueue, wg)
-				session.Header.LastCopied = cpURLs.SourceContent.Name
-			case <-trapCh:
-				session.Save()
-				session.Info()
-				os.Exit(0)
-			}
+		select {
+		case cpQueue <- true:
+			wg.Add(1)
+			session.Dispatch(index, cpURLs.SourceContent.Name)
+			go func(cpURLs copyURLs) {
+				defer wg.Done() // Notify that this copy routine is done.
+				defer func() {
+					<-cpQueue
+				}()
+				start := time.Now()
+				err := doCopy(cpURLs, opts, &bar)
+				console.RecordOperation(console.Operation{
+					Command:  "cp",
+					Source:   cpURLs.SourceContent.Name,
+					Target:   cpURLs.TargetContent.Name,
+					Bytes:    cpURLs.SourceContent.Size,
+					Duration: time.Since(start),
+					Err:      err,
+				})
+				if err != nil { // failed copies are retried on resume
+					console.Println("")
+					console.Errorln(NewIodine(err))
+					session.Failed(cpURLs.SourceContent.Name)
+				} else {
+					session.Done(cpURLs.SourceContent.Name, cpURLs.SourceContent.Size)
+				}
+				if err := session.Checkpoint(); err != nil {
+					console.Errorf("Unable to save session ‘%s’. %s\n", session.SessionID, err)
+				}
+			}(cpURLs)
+		case <-trapCh:
+			session.Terminate()
+			os.Exit(0)
+		case <-pauseCh: // Let running copies finish, the rest is left for resume.
+			wg.Wait()
+			session.Pause()
+			os.Exit(0)
 		}
 	}
 	wg.Wait()
+	if err := scanner.Err(); err != nil {
+		session.Terminate()
+		console.Fatalf("Unable to read session data. %s\n", err)
+	}
+
+	if session.Header.Manifest != "" {
+		manifest, err := newCopyManifest(session)
+		if err == nil {
+			err = saveManifest(manifest, session.Header.Manifest)
+		}
+		if err != nil {
+			console.Fatalf("Unable to write manifest ‘%s’. %s\n", session.Header.Manifest, NewIodine(iodine.New(err, nil)))
+		}
+	}
+	if globalQuietFlag { // per object output was left out
+		console.PrintC(session.Summary())
+	}
 }
 
 // runCopyCmd is bound to sub-command
 func runCopyCmd(ctx *cli.Context) {
 	checkCopySyntax(ctx)
 
+	namePolicy := objectNamePolicy(ctx.String("name-policy"))
+	if !namePolicy.isValid() {
+		console.Fatalf("Valid name policies are [fail, skip, sanitize]. %s\n", errInvalidArgument{})
+	}
+
+	if ctx.String("tags") != "" {
+		if _, err := parseTagFilter(ctx.String("tags")); err != nil {
+			console.Fatalf("Invalid tag filter ‘%s’, use key=value pairs joined by ‘&’. %s\n", ctx.String("tags"), err)
+		}
+	}
+	if ctx.String("set-tags") != "" {
+		if _, err := parseTagFilter(ctx.String("set-tags")); err != nil {
+			console.Fatalf("Invalid tags ‘%s’, use key=value pairs joined by ‘&’. %s\n", ctx.String("set-tags"), err)
+		}
+	}
+
+	if ctx.String("acl") != "" && !isValidObjectACL(ctx.String("acl")) {
+		console.Fatalf("Unknown ACL ‘%s’, please choose from [%s]. %s\n", ctx.String("acl"), strings.Join(objectACLs, ", "), errInvalidArgument{})
+	}
+
+	var mmapSize int64
+	if ctx.String("mmap") != "" {
+		size, err := humanize.ParseBytes(ctx.String("mmap"))
+		if err != nil || size == 0 {
+			console.Fatalf("Invalid size ‘%s’ for ‘--mmap’, e.g. 64MiB. %s\n", ctx.String("mmap"), errInvalidArgument{})
+		}
+		mmapSize = int64(size)
+	}
+	var rewind time.Time
+	if ctx.String("rewind") != "" {
+		if ctx.String("version-id") != "" {
+			console.Fatalf("‘--version-id’ and ‘--rewind’ cannot be used together. %s\n", errInvalidArgument{})
+		}
+		var err error
+		rewind, err = parseRewind(ctx.String("rewind"))
+		if err != nil {
+			console.Fatalf("Invalid time ‘%s’ for ‘--rewind’, e.g. 2015-09-01 or 7d. %s\n", ctx.String("rewind"), errInvalidArgument{})
+		}
+	}
+	if ctx.Int("parallel-range") < 0 {
+		console.Fatalf("Invalid number ‘%d’ for ‘--parallel-range’. %s\n", ctx.Int("parallel-range"), errInvalidArgument{})
+	}
+
+	var attrs attrRules
+	if ctx.String("attr-file") != "" {
+		var err error
+		attrs, err = loadAttrRules(ctx.String("attr-file"))
+		if err != nil {
+			console.Fatalf("Unable to load attributes from ‘%s’. %s\n", ctx.String("attr-file"), err)
+		}
+	}
+
+	URLs, err := args2URLs(ctx.Args())
+	if err != nil {
+		console.Fatalf("One or more unknown URL types found %s. %s\n", ctx.Args(), err)
+	}
+	sourceURLs, targetURL := URLs[:len(URLs)-1], URLs[len(URLs)-1]
+	if isStdioURL(targetURL) || isStdioURL(sourceURLs[0]) {
+		checkCopyStreamSyntax(ctx, targetURL)
+		doCopyStream(sourceURLs, targetURL, &sessionV2Header{
+			TargetLock:   ctx.Bool("lock"),
+			NamePolicy:   string(namePolicy),
+			WindowsNames: ctx.Bool("windows-names"),
+			Attrs:        attrs,
+			SetTags:      ctx.String("set-tags"),
+			ACL:          ctx.String("acl"),
+			NoSniff:      ctx.Bool("no-sniff"),
+		})
+		return
+	}
+
+	expireSessions()
 	session := newSessionV2()
 	defer session.Close()
 
-	var err error
 	session.Header.CommandType = "cp"
+	session.Header.TargetLock = ctx.Bool("lock")
+	session.Header.NamePolicy = string(namePolicy)
+	session.Header.WindowsNames = ctx.Bool("windows-names")
+	session.Header.Parents = ctx.Bool("parents")
+	session.Header.Attrs = attrs
+	session.Header.Tags = ctx.String("tags")
+	session.Header.SetTags = ctx.String("set-tags")
+	session.Header.ACL = ctx.String("acl")
+	session.Header.Preserve = ctx.Bool("preserve")
+	session.Header.NoMetadata = ctx.Bool("no-metadata")
+	session.Header.NoSniff = ctx.Bool("no-sniff")
+	session.Header.MmapSize = mmapSize
+	session.Header.ParallelRange = ctx.Int("parallel-range")
+	session.Header.VersionID = ctx.String("version-id")
+	if !rewind.IsZero() { // resolved now, resumed sessions copy the same versions
+		session.Header.Rewind = rewind.UTC().Format(time.RFC3339Nano)
+	}
+	if ctx.String("manifest") != "" { // sessions may be resumed from another working directory
+		session.Header.Manifest, err = filepath.Abs(ctx.String("manifest"))
+		if err != nil {
+			session.Close()
+			console.Fatalf("Unable to get absolute path of manifest ‘%s’. %s\n", ctx.String("manifest"), err)
+		}
+	}
 	session.Header.RootPath, err = os.Getwd()
 	if err != nil {
 		session.Close()
 		console.Fatalf("Unable to get current working directory. %s\n", err)
 	}
 
-	// extract URLs.
-	session.Header.CommandArgs, err = args2URLs(ctx.Args())
-	if err != nil {
-		session.Close()
-		console.Fatalf("One or more unknown URL types found %s. %s\n", ctx.Args(), err)
-	}
+	session.Header.CommandArgs = URLs
+	session.Header.CommandHash = getCommandHash(session.Header)
 
+	if ctx.Bool("plan") {
+		doPlanCopy(session.Header, signalTrap(os.Interrupt, os.Kill))
+		return
+	}
+	if resumeInterruptedSession(session, ctx.Bool("yes"), ctx.Bool("no-resume")) {
+		return
+	}
 	doCopyCmdSession(session)
+	if session.Header.Failed > 0 { // Keep the session to retry failed copies.
+		session.Fail()
+		os.Exit(exitPartialFailure)
+	}
 }
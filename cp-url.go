@@ -19,6 +19,7 @@ package main
 import (
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/client"
@@ -58,7 +59,7 @@ const (
 //
 func checkCopySyntax(ctx *cli.Context) {
 	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
-		cli.ShowCommandHelpAndExit(ctx, "cp", 1) // last argument is exit code.
+		cli.ShowCommandHelpAndExit(ctx, "cp", exitUsage) // last argument is exit code.
 	}
 
 	// extract URLs.
@@ -73,30 +74,61 @@ func checkCopySyntax(ctx *cli.Context) {
 	/****** Generic rules *******/
 	// Recursive URLs are not allowed in target.
 	if isURLRecursive(tgtURL) {
-		console.Fatalf("Target ‘%s’ cannot be recursive. %s\n", tgtURL, iodine.New(err, nil))
+		console.Fatalf("Target ‘%s’ cannot be recursive. %s\n", tgtURL, errInvalidArgument{})
+	}
+
+	/****** Stream rules *******/
+	if isStdioURL(tgtURL) {
+		for _, srcURL := range srcURLs {
+			if isStdioURL(srcURL) || isURLRecursive(srcURL) {
+				console.Fatalf("Source ‘%s’ cannot be written to standard output. %s\n", srcURL, errInvalidArgument{})
+			}
+		}
+		return
+	}
+	for _, srcURL := range srcURLs {
+		if !isStdioURL(srcURL) {
+			continue
+		}
+		if len(srcURLs) > 1 {
+			console.Fatalf("Standard input cannot be copied along with other sources. %s\n", errInvalidArgument{})
+		}
+		if isTargetURLDir(tgtURL) {
+			console.Fatalf("Target ‘%s’ should be an object name when copying from standard input. %s\n", tgtURL, errInvalidArgument{})
+		}
+		return
+	}
+
+	if ctx.String("version-id") != "" && (len(srcURLs) > 1 || isURLRecursive(srcURLs[0])) {
+		console.Fatalf("‘--version-id’ needs a single source object. %s\n", errInvalidArgument{})
 	}
 
 	switch guessCopyURLType(srcURLs, tgtURL) {
 	case copyURLsTypeA: // Source is already a regular file.
-		// no verification needed, pass through
+		if ctx.Bool("parents") {
+			console.Fatalf("Target ‘%s’ should be a directory and exist, when --parents is specified. %s\n", tgtURL, errInvalidArgument{})
+		}
 	case copyURLsTypeB: // Source is already a regular file.
 		// no verification needed, pass through
 	case copyURLsTypeC:
+		if ctx.String("rewind") != "" { // the folder may have been removed since
+			break
+		}
 		for _, srcURL := range srcURLs {
 			srcURL = stripRecursiveURL(srcURL)
-			_, srcContent, err := url2Stat(srcURL)
+			_, srcContent, err := source2Stat(srcURL)
 			// Source exist?.
 			if err != nil {
 				console.Fatalf("Unable to stat source ‘%s’. %s\n", srcURL, iodine.New(err, nil))
 			}
 			if srcContent.Type.IsRegular() { // Ellipses is supported only for directories.
-				console.Fatalf("Source ‘%s’ is not a directory. %s\n", stripRecursiveURL(srcURL), iodine.New(err, nil))
+				console.Fatalf("Source ‘%s’ is not a directory. %s\n", stripRecursiveURL(srcURL), errInvalidArgument{})
 			}
 		}
 	case copyURLsTypeD:
 		// only verify if target is a valid directory and exists
 		if !isTargetURLDir(tgtURL) {
-			console.Fatalf("Target ‘%s’ should be a directory and exist, when we have a mixture of files and folders in source\n", tgtURL)
+			console.Fatalf("Target ‘%s’ should be a directory and exist, when we have a mixture of files and folders in source. %s\n", tgtURL, errInvalidArgument{})
 		}
 	default:
 		console.Fatalln("Invalid arguments. Unable to determine how to copy. Please report this issue at https://github.com/minio/mc/issues")
@@ -134,7 +166,7 @@ func prepareCopyURLsTypeA(sourceURL string, targetURL string) <-chan copyURLs {
 	copyURLsCh := make(chan copyURLs)
 	go func(sourceURL, targetURL string, copyURLsCh chan copyURLs) {
 		defer close(copyURLsCh)
-		_, sourceContent, err := url2Stat(sourceURL)
+		_, sourceContent, err := source2Stat(sourceURL)
 		if err != nil {
 			// Source does not exist or insufficient privileges.
 			copyURLsCh <- copyURLs{Error: NewIodine(iodine.New(err, nil))}
@@ -158,7 +190,7 @@ func prepareCopyURLsTypeB(sourceURL string, targetURL string) <-chan copyURLs {
 	copyURLsCh := make(chan copyURLs)
 	go func(sourceURL, targetURL string, copyURLsCh chan copyURLs) {
 		defer close(copyURLsCh)
-		_, sourceContent, err := url2Stat(sourceURL)
+		_, sourceContent, err := source2Stat(sourceURL)
 		if err != nil {
 			// Source does not exist or insufficient privileges.
 			copyURLsCh <- copyURLs{Error: NewIodine(iodine.New(err, nil))}
@@ -206,7 +238,7 @@ func prepareCopyURLsTypeC(sourceURL, targetURL string) <-chan copyURLs {
 
 		// add `/` after trimming off `...` to emulate directories
 		sourceURL = stripRecursiveURL(sourceURL)
-		sourceClient, sourceContent, err := url2Stat(sourceURL)
+		sourceClient, sourceContent, err := source2Stat(sourceURL)
 		if err != nil {
 			// Source does not exist or insufficient privileges.
 			copyURLsCh <- copyURLs{Error: NewIodine(iodine.New(err, nil))}
@@ -326,3 +358,157 @@ func prepareCopyURLs(sourceURLs []string, targetURL string) <-chan copyURLs {
 
 	return copyURLsCh
 }
+
+// parentsTargetURL - constructs target URL for --parents, full source path is recreated under target directory.
+func parentsTargetURL(sourceURL, targetURL string) (string, error) {
+	sourceURLParse, err := client.Parse(sourceURL)
+	if err != nil {
+		return "", iodine.New(errInvalidSource{URL: sourceURL}, nil)
+	}
+	targetURLParse, err := client.Parse(targetURL)
+	if err != nil {
+		return "", iodine.New(errInvalidTarget{URL: targetURL}, nil)
+	}
+	sourcePath := strings.TrimPrefix(sourceURLParse.Path, filepath.VolumeName(sourceURLParse.Path))
+	// Never let parent references climb out of target directory.
+	var components []string
+	for _, component := range strings.Split(sourcePath, string(sourceURLParse.Separator)) {
+		switch component {
+		case "", ".", "..":
+			continue
+		}
+		components = append(components, component)
+	}
+	targetURLParse.Path = filepath.Join(targetURLParse.Path, filepath.Join(components...))
+	return targetURLParse.String(), nil
+}
+
+// statSourceVersion - the version of the object at sourceURL to copy, versionID if set, otherwise the version
+// current at time at. Named after sourceURL like contents of url2Stat
+func statSourceVersion(sourceURL, versionID string, at time.Time) (*client.Content, error) {
+	sourceClient, err := source2Client(sourceURL)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if versionID != "" {
+		getter, ok := sourceClient.(client.VersionGetter)
+		if !ok {
+			return nil, NewIodine(iodine.New(errVersionsNotSupported{URL: sourceURL}, nil))
+		}
+		content, err := getter.StatVersion(versionID)
+		if err != nil {
+			return nil, NewIodine(iodine.New(err, map[string]string{"URL": sourceURL}))
+		}
+		content.Name = sourceURL
+		return content, nil
+	}
+	lister, ok := sourceClient.(client.VersionLister)
+	if !ok {
+		return nil, NewIodine(iodine.New(errVersionsNotSupported{URL: sourceURL}, nil))
+	}
+	sourceURLParse := sourceClient.URL()
+	base := sourceURLParse.Path[strings.LastIndex(sourceURLParse.Path, string(sourceURLParse.Separator))+1:]
+	// versions of all objects starting with the name are listed, the whole listing is read to end it
+	var found *client.Content
+	for entry := range client.ListAt(lister, false, at) {
+		if entry.Err != nil {
+			err = entry.Err
+			continue
+		}
+		if found == nil && entry.Content.Type.IsRegular() && entry.Content.Name == base {
+			found = entry.Content
+		}
+	}
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, map[string]string{"URL": sourceURL}))
+	}
+	if found == nil {
+		return nil, NewIodine(iodine.New(client.NotFound{Path: sourceURL}, nil))
+	}
+	found.Name = sourceURL
+	return found, nil
+}
+
+// prepareCopyURLsAt - prepares target and source URLs for copying older versions of sources, following the
+// rules of prepareCopyURLs. Single objects are copied in version versionID if set, otherwise all sources are
+// copied as they were at time at, objects removed since included.
+func prepareCopyURLsAt(sourceURLs []string, targetURL, versionID string, at time.Time) <-chan copyURLs {
+	copyURLsCh := make(chan copyURLs)
+	go func(sourceURLs []string, targetURL string, copyURLsCh chan copyURLs) {
+		defer close(copyURLsCh)
+		if guessCopyURLType(sourceURLs, targetURL) == copyURLsTypeInvalid {
+			copyURLsCh <- copyURLs{Error: NewIodine(iodine.New(errInvalidArgument{}, nil))}
+			return
+		}
+		targetURLParse, err := client.Parse(targetURL)
+		if err != nil {
+			copyURLsCh <- copyURLs{Error: NewIodine(iodine.New(errInvalidTarget{URL: targetURL}, nil))}
+			return
+		}
+		for _, sourceURL := range sourceURLs {
+			if isURLRecursive(sourceURL) {
+				for cURLs := range prepareCopyURLsTypeCAt(stripRecursiveURL(sourceURL), *targetURLParse, at) {
+					copyURLsCh <- cURLs
+				}
+				continue
+			}
+			sourceContent, err := statSourceVersion(sourceURL, versionID, at)
+			if err != nil {
+				copyURLsCh <- copyURLs{Error: NewIodine(iodine.New(err, nil))}
+				continue
+			}
+			// Type A copies to target, Type B and D into it
+			newTargetURLParse := *targetURLParse
+			if len(sourceURLs) > 1 || isTargetURLDir(targetURL) {
+				sourceURLParse, err := client.Parse(sourceURL)
+				if err != nil {
+					copyURLsCh <- copyURLs{Error: NewIodine(iodine.New(errInvalidSource{URL: sourceURL}, nil))}
+					continue
+				}
+				newTargetURLParse.Path = filepath.Join(newTargetURLParse.Path, filepath.Base(sourceURLParse.Path))
+			}
+			copyURLsCh <- copyURLs{SourceContent: sourceContent, TargetContent: &client.Content{Name: newTargetURLParse.String()}}
+		}
+	}(sourceURLs, targetURL, copyURLsCh)
+	return copyURLsCh
+}
+
+// prepareCopyURLsTypeCAt - Type C of prepareCopyURLsAt, objects below sourceURL in the versions current at time at
+func prepareCopyURLsTypeCAt(sourceURL string, targetURLParse client.URL, at time.Time) <-chan copyURLs {
+	copyURLsCh := make(chan copyURLs)
+	go func() {
+		defer close(copyURLsCh)
+		sourceClient, err := source2Client(sourceURL)
+		if err != nil {
+			copyURLsCh <- copyURLs{Error: NewIodine(iodine.New(err, nil))}
+			return
+		}
+		lister, ok := sourceClient.(client.VersionLister)
+		if !ok {
+			copyURLsCh <- copyURLs{Error: NewIodine(iodine.New(errVersionsNotSupported{URL: sourceURL}, nil))}
+			return
+		}
+		sourceURLParse, err := client.Parse(sourceURL)
+		if err != nil {
+			copyURLsCh <- copyURLs{Error: NewIodine(iodine.New(errInvalidSource{URL: sourceURL}, nil))}
+			return
+		}
+		sourceURLDelimited := sourceURLParse.String()[:strings.LastIndex(sourceURLParse.String(),
+			string(sourceURLParse.Separator))+1]
+		for entry := range client.ListAt(lister, true, at) {
+			if entry.Err != nil {
+				copyURLsCh <- copyURLs{Error: NewIodine(iodine.New(entry.Err, nil))}
+				continue
+			}
+			sourceContent := entry.Content
+			if !sourceContent.Type.IsRegular() {
+				continue
+			}
+			newTargetURLParse := targetURLParse
+			newTargetURLParse.Path = filepath.Join(newTargetURLParse.Path, sourceContent.Name)
+			sourceContent.Name = sourceURLDelimited + sourceContent.Name
+			copyURLsCh <- copyURLs{SourceContent: sourceContent, TargetContent: &client.Content{Name: newTargetURLParse.String()}}
+		}
+	}()
+	return copyURLsCh
+}
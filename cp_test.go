@@ -0,0 +1,192 @@
+/*
+ * Minio Client, (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/fakes3"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestCopyParents(c *C) {
+	targetURL, err := parentsTargetURL(filepath.Join("dir", "sub", "file.txt"), "target")
+	c.Assert(err, IsNil)
+	c.Assert(targetURL, Equals, filepath.Join("target", "dir", "sub", "file.txt"))
+
+	targetURL, err = parentsTargetURL(filepath.Join("..", "dir", ".", "file.txt"), "target")
+	c.Assert(err, IsNil)
+	c.Assert(targetURL, Equals, filepath.Join("target", "dir", "file.txt"))
+
+	targetURL, err = parentsTargetURL("https://play.minio.io:9000/photos/2015/a.jpg", "target")
+	c.Assert(err, IsNil)
+	c.Assert(targetURL, Equals, filepath.Join("target", "photos", "2015", "a.jpg"))
+
+	targetURL, err = parentsTargetURL(filepath.Join("dir", "file.txt"), "https://play.minio.io:9000/archive")
+	c.Assert(err, IsNil)
+	c.Assert(targetURL, Equals, "https://play.minio.io:9000/archive/dir/file.txt")
+}
+
+func (s *CmdTestSuite) TestCopyRewind(c *C) {
+	versions := map[string]string{"docs/a.txt?v1": "alpha", "docs/b.txt?v2": "bravo", "docs/b.txt?v3": "bravo!"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["versions"]; ok && r.URL.Path == "/bucket" {
+			c.Check(r.URL.Query().Get("prefix"), Equals, "docs")
+			// a.txt was removed on June 3rd, b.txt overwritten on June 4th
+			w.Write([]byte(`<ListVersionsResult><IsTruncated>false</IsTruncated>
+<DeleteMarker><Key>docs/a.txt</Key><VersionId>v2</VersionId><IsLatest>true</IsLatest><LastModified>2015-06-03T10:00:00.000Z</LastModified></DeleteMarker>
+<Version><Key>docs/a.txt</Key><VersionId>v1</VersionId><IsLatest>false</IsLatest><LastModified>2015-06-01T10:00:00.000Z</LastModified><Size>5</Size></Version>
+<Version><Key>docs/b.txt</Key><VersionId>v3</VersionId><IsLatest>true</IsLatest><LastModified>2015-06-04T10:00:00.000Z</LastModified><Size>6</Size></Version>
+<Version><Key>docs/b.txt</Key><VersionId>v2</VersionId><IsLatest>false</IsLatest><LastModified>2015-06-02T10:00:00.000Z</LastModified><Size>5</Size></Version>
+</ListVersionsResult>`))
+			return
+		}
+		data, ok := versions[r.URL.Path[len("/bucket/"):]+"?"+r.URL.Query().Get("versionId")]
+		if r.Method != "GET" || !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(data))
+	}))
+	defer server.Close()
+
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	session := newCopySession(c, server.URL+"/bucket/docs...", root)
+	session.Header.Rewind = time.Date(2015, 6, 2, 12, 0, 0, 0, time.UTC).Format(time.RFC3339Nano)
+	doCopyCmdSession(session)
+	c.Assert(session.Header.Failed, Equals, 0)
+	c.Assert(session.Header.CopiedObjects, Equals, 2)
+	c.Assert(session.Close(), IsNil)
+	for name, expected := range map[string]string{"a.txt": "alpha", "b.txt": "bravo"} {
+		data, err := ioutil.ReadFile(filepath.Join(root, "docs", name))
+		c.Assert(err, IsNil)
+		c.Assert(string(data), Equals, expected)
+	}
+
+	// local sources keep no versions
+	for cpURLs := range prepareCopyURLsAt([]string{filepath.Join(root, "docs", "a.txt")}, root, "v1", time.Time{}) {
+		c.Assert(cpURLs.Error, NotNil)
+	}
+}
+
+func (s *CmdTestSuite) TestCopyStream(c *C) {
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+
+	stdin, err := ioutil.TempFile(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.Remove(stdin.Name())
+	_, err = stdin.WriteString("streamed")
+	c.Assert(err, IsNil)
+	_, err = stdin.Seek(0, 0)
+	c.Assert(err, IsNil)
+	defer func(saved *os.File) { os.Stdin = saved }(os.Stdin)
+	os.Stdin = stdin
+
+	// flags which apply to the target of a stream are not ignored
+	doCopyStream([]string{"-"}, server.URL+"/bucket/logs/a:b.log", &sessionV2Header{
+		NamePolicy:   string(namePolicySanitize),
+		WindowsNames: true,
+		SetTags:      "project=logs",
+		ACL:          "public-read",
+		NoSniff:      true,
+	})
+	stdin.Close()
+	data, ok := server.GetObject("bucket", "logs/a_b.log")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(data), Equals, "streamed")
+	tags, ok := server.Document("/bucket/logs/a_b.log", "tagging")
+	c.Assert(ok, Equals, true)
+	c.Assert(strings.Contains(string(tags), "<Key>project</Key><Value>logs</Value>"), Equals, true)
+	message, err := doGetACL(server.URL + "/bucket/logs/a_b.log")
+	c.Assert(err, IsNil)
+	c.Assert(message.ACL, Equals, "public-read")
+}
+
+func (s *CmdTestSuite) TestCopyTags(c *C) {
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		server.PutObject("bucket", name, []byte("hello"))
+	}
+	server.SetDocument("/bucket/a.txt", "tagging", []byte(`<Tagging><TagSet><Tag><Key>env</Key><Value>prod</Value></Tag></TagSet></Tagging>`))
+	copyObject := func(name string) error {
+		return doCopy(copyURLs{
+			SourceContent: &client.Content{Name: server.URL + "/bucket/" + name, Size: 5},
+			TargetContent: &client.Content{Name: server.URL + "/bucket/copy/" + name},
+		}, copyOptions{copyMetadata: true, setTags: map[string]string{"tier": "hot"}}, nil)
+	}
+
+	// tags of source and --set-tags are sent along with the upload
+	c.Assert(copyObject("a.txt"), IsNil)
+	c.Assert(server.Requests("PUT", "/bucket/copy/a.txt"), Equals, 1)
+	tags, ok := server.Document("/bucket/copy/a.txt", "tagging")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(tags), Equals, `<Tagging><TagSet><Tag><Key>env</Key><Value>prod</Value></Tag><Tag><Key>tier</Key><Value>hot</Value></Tag></TagSet></Tagging>`)
+
+	// users who cannot read tags of the source still copy it
+	server.AddFault(fakes3.Fault{Method: "GET", Path: "/bucket/b.txt", Subresource: "tagging", Code: "AccessDenied", Status: http.StatusForbidden})
+	c.Assert(copyObject("b.txt"), IsNil)
+	data, ok := server.GetObject("bucket", "copy/b.txt")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(data), Equals, "hello")
+	tags, ok = server.Document("/bucket/copy/b.txt", "tagging")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(tags), Equals, `<Tagging><TagSet><Tag><Key>tier</Key><Value>hot</Value></Tag></TagSet></Tagging>`)
+}
+
+func (s *CmdTestSuite) TestCopyStdioArgs(c *C) {
+	var parsed []string
+	var quiet bool
+	app := cli.NewApp()
+	app.Commands = []cli.Command{{
+		Name:  "cp",
+		Flags: []cli.Flag{cli.BoolFlag{Name: "quiet"}, cli.StringFlag{Name: "attr-file"}},
+		Action: func(ctx *cli.Context) {
+			parsed, quiet = ctx.Args(), ctx.Bool("quiet")
+		},
+	}}
+	for _, args := range [][]string{
+		{"mc", "cp", "s3/bucket/object", "-"},
+		{"mc", "cp", "s3/bucket/object", "-", "--quiet"},
+		{"mc", "cp", "s3/bucket/object", "-", "--attr-file", "attrs", "--quiet"},
+		{"mc", "cp", "--quiet", "--", "s3/bucket/object", "-"},
+	} {
+		parsed, quiet = nil, false
+		c.Assert(app.Run(stdioArgs(args, app.Commands)), IsNil)
+		c.Assert(parsed, DeepEquals, []string{"s3/bucket/object", "-"}, Commentf("%v", args))
+		c.Assert(quiet, Equals, len(args) > 4, Commentf("%v", args))
+	}
+	// without stdioArgs the cli swaps "-" with the source
+	c.Assert(app.Run([]string{"mc", "cp", "s3/bucket/object", "-"}), IsNil)
+	c.Assert(parsed, DeepEquals, []string{"-", "s3/bucket/object"})
+
+	// command lines without "-" are left to the cli
+	args := []string{"mc", "--json", "cp", "a", "b", "--quiet"}
+	c.Assert(stdioArgs(args, app.Commands), DeepEquals, args)
+}
@@ -54,9 +54,9 @@ EXAMPLES:
 // runDiffCmd - is a handler for mc diff command
 func runDiffCmd(ctx *cli.Context) {
 	if len(ctx.Args()) != 2 || ctx.Args().First() == "help" {
-		cli.ShowCommandHelpAndExit(ctx, "diff", 1) // last argument is exit code
+		cli.ShowCommandHelpAndExit(ctx, "diff", exitUsage) // last argument is exit code
 	}
-	if !isMcConfigExists() {
+	if !isMcConfigAvailable() {
 		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
 	}
 
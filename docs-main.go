@@ -0,0 +1,193 @@
+/*
+ * Minio Client, (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message. Hidden, it is meant for packagers rather than users
+var docsCmd = cli.Command{
+	Name:   "docs",
+	Usage:  "Generate documentation from the help of commands",
+	Action: runDocsCmd,
+	Hide:   true,
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} man [DIRECTORY]
+
+DESCRIPTION:
+   Writes man pages in section 1, mc.1 for global flags and commands, mc-COMMAND.1 for each command. Pages are
+   written to the current directory if DIRECTORY is not given.
+
+EXAMPLES:
+   1. Generate man pages for a package.
+      $ mc {{.Name}} man debian/mc/usr/share/man/man1
+
+`,
+}
+
+// manSection - heading of a help section like "USAGE:"
+var manSection = regexp.MustCompile(`^([A-Z][A-Z ]*):$`)
+
+// renderHelp - help text of data like printed by mc help, tabs of flag lists expanded
+func renderHelp(helpTemplate string, data interface{}) (string, error) {
+	t, err := template.New("help").Funcs(template.FuncMap{"join": strings.Join}).Parse(helpTemplate)
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	var help bytes.Buffer
+	w := tabwriter.NewWriter(&help, 0, 8, 1, ' ', 0)
+	if err := t.Execute(w, data); err != nil {
+		return "", iodine.New(err, nil)
+	}
+	w.Flush()
+	return help.String(), nil
+}
+
+// manEscape - text safe in a roff line, a leading dot or quote would start a request
+func manEscape(text string) string {
+	text = strings.Replace(text, `\`, `\e`, -1)
+	text = strings.Replace(text, "-", `\-`, -1)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+	return text
+}
+
+// manPage - help text as a man(1) page titled name, sections of the help become sections of the page with
+// their text kept as is
+func manPage(name, help string) string {
+	var page bytes.Buffer
+	date := ""
+	if t, err := time.Parse(time.RFC3339Nano, Version); err == nil {
+		date = t.Format("2006-01-02")
+	}
+	fmt.Fprintf(&page, ".TH \"%s\" \"1\" \"%s\" \"mc\" \"Minio Client\"\n", strings.ToUpper(manEscape(name)), date)
+	inSection, blank := false, false
+	for _, line := range strings.Split(strings.TrimRight(help, "\n"), "\n") {
+		if match := manSection.FindStringSubmatch(line); match != nil {
+			if inSection {
+				page.WriteString(".fi\n")
+			}
+			fmt.Fprintf(&page, ".SH \"%s\"\n.nf\n", match[1])
+			inSection, blank = true, false
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		line = strings.TrimRight(line, " ")
+		if line == "" {
+			blank = true
+			continue
+		}
+		// blank lines between paragraphs of a section only
+		if blank && !strings.HasSuffix(page.String(), ".nf\n") {
+			page.WriteString(".sp\n")
+		}
+		blank = false
+		page.WriteString(manEscape(line) + "\n")
+	}
+	if inSection {
+		page.WriteString(".fi\n")
+	}
+	return page.String()
+}
+
+// writeManPages - mc.1 for app and mc-COMMAND.1 for each of its visible commands in dir
+func writeManPages(app *cli.App, dir string) error {
+	visible := *app
+	visible.Name = "mc" // not the path mc was run as
+	visible.Flags, visible.Commands = visibleFlags(app.Flags), nil
+	for _, cmd := range app.Commands {
+		if !cmd.Hide {
+			visible.Commands = append(visible.Commands, cmd)
+		}
+	}
+	pages := map[string]string{}
+	help, err := renderHelp(app.CustomAppHelpTemplate, visible)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	// version and system details are of the build generating the pages, not of interest to readers
+	if i := strings.Index(help, "\nVERSION:"); i >= 0 {
+		help = help[:i]
+	}
+	pages["mc"] = help
+	for _, cmd := range visible.Commands {
+		cmd.Flags = visibleFlags(cmd.Flags)
+		help, err := renderHelp(cmd.CustomHelpTemplate, cmd)
+		if err != nil {
+			return iodine.New(err, map[string]string{"Command": cmd.Name})
+		}
+		pages["mc-"+cmd.Name] = help
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return iodine.New(err, nil)
+	}
+	for name, help := range pages {
+		if err := ioutil.WriteFile(filepath.Join(dir, name+".1"), []byte(manPage(name, help)), 0644); err != nil {
+			return iodine.New(err, map[string]string{"Page": name})
+		}
+	}
+	return nil
+}
+
+// visibleFlags - flags shown in help
+func visibleFlags(flags []cli.Flag) []cli.Flag {
+	var visible []cli.Flag
+	for _, flag := range flags {
+		if !isHiddenFlag(flag) {
+			visible = append(visible, flag)
+		}
+	}
+	return visible
+}
+
+// isHiddenFlag - flag is left out of help, flags expose Hide only through their concrete types
+func isHiddenFlag(flag cli.Flag) bool {
+	return getFlagRegistry(flag).Hidden
+}
+
+func runDocsCmd(ctx *cli.Context) {
+	if ctx.Args().First() != "man" || len(ctx.Args()) > 2 {
+		cli.ShowCommandHelpAndExit(ctx, "docs", exitUsage) // last argument is exit code
+	}
+	dir := "."
+	if len(ctx.Args()) == 2 {
+		dir = ctx.Args().Get(1)
+	}
+	if err := writeManPages(ctx.App, dir); err != nil {
+		console.Fatalf("Unable to write man pages to ‘%s’. %s\n", dir, NewIodine(iodine.New(err, nil)))
+	}
+}
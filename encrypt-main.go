@@ -0,0 +1,142 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var encryptCmd = cli.Command{
+	Name:   "encrypt",
+	Usage:  "Manage default encryption of buckets",
+	Action: runEncryptCmd,
+	Flags:  []cli.Flag{kmsKeyFlag},
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} set sse-s3|sse-kms [--kms-key ID] TARGET [TARGET...]
+   mc {{.Name}} info|clear TARGET [TARGET...]
+
+DESCRIPTION:
+   Objects uploaded to a bucket with default encryption and without encryption of their own are encrypted
+   by the server, with keys it manages (SSE-S3) or with a key of its key management service (SSE-KMS).
+   Encryption required by the configuration file is still sent with every upload and takes precedence.
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
+
+EXAMPLES:
+   1. Encrypt new objects of a bucket on Amazon S3 object storage with keys managed by S3.
+      $ mc {{.Name}} set sse-s3 s3:backup
+
+   2. Encrypt new objects of a bucket with a KMS key, the default key of the account if ‘--kms-key’ is omitted.
+      $ mc {{.Name}} set sse-kms --kms-key arn:aws:kms:us-east-1:123456789012:key/payroll s3:payroll
+
+   3. Show default encryption of buckets, as JSON for scripts.
+      $ mc --json {{.Name}} info s3:backup s3:payroll
+
+   4. Stop encrypting new objects of a bucket on Minio object storage by default.
+      $ mc {{.Name}} clear https://play.minio.io:9000/scratch
+`,
+}
+
+// runEncryptCmd - is a handler for mc encrypt command
+func runEncryptCmd(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "encrypt", exitUsage) // last argument is exit code
+	}
+	operation := ctx.Args().First()
+	targets := ctx.Args().Tail()
+	var sse *client.SSE
+	switch operation {
+	case "set":
+		if len(targets) < 2 {
+			cli.ShowCommandHelpAndExit(ctx, "encrypt", exitUsage) // last argument is exit code
+		}
+		switch strings.ToUpper(targets[0]) {
+		case client.SSES3:
+			if ctx.String("kms-key") != "" {
+				console.Fatalf("‘--kms-key’ applies only to SSE-KMS. %s\n", errInvalidArgument{})
+			}
+			sse = &client.SSE{Type: client.SSES3}
+		case client.SSEKMS:
+			sse = &client.SSE{Type: client.SSEKMS, KeyID: ctx.String("kms-key")}
+		default:
+			console.Fatalf("Unknown encryption ‘%s’, please choose from [sse-s3, sse-kms]. %s\n", targets[0], errInvalidArgument{})
+		}
+		targets = targets[1:]
+	case "info", "clear":
+	default:
+		console.Fatalf("Unknown operation ‘%s’, please choose from [set, info, clear]. %s\n", operation, errInvalidArgument{})
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	config := mustGetMcConfig()
+	for _, arg := range targets {
+		targetURL, err := getExpandedURL(arg, config.Aliases)
+		if err != nil {
+			switch e := iodine.ToError(err).(type) {
+			case errUnsupportedScheme:
+				console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+			default:
+				console.Fatalf("Unable to parse argument %s. %s\n", arg, err)
+			}
+		}
+		message, err := doEncryptCmd(targetURL, operation, sse)
+		if err != nil {
+			console.Fatalf("Unable to %s default encryption of ‘%s’. %s\n", operation, targetURL, err)
+		}
+		console.Print(message)
+	}
+}
+
+// doEncryptCmd - set default encryption of the bucket of targetURL to sse, clear it or only read it
+func doEncryptCmd(targetURL, operation string, sse *client.SSE) (EncryptMessage, error) {
+	message := EncryptMessage{Target: targetURL, Operation: operation}
+	clnt, err := target2Client(targetURL)
+	if err != nil {
+		return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	encryption, ok := clnt.(client.BucketEncryption)
+	if !ok {
+		return message, NewIodine(iodine.New(errBucketEncryptionNotSupported{URL: targetURL}, nil))
+	}
+	switch operation {
+	case "set", "clear":
+		if err := encryption.SetBucketEncryption(sse); err != nil {
+			return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+		}
+	default:
+		sse, err = encryption.GetBucketEncryption()
+		if err != nil {
+			return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+		}
+	}
+	if sse != nil {
+		message.Type, message.KeyID = sse.Type, sse.KeyID
+	}
+	return message, nil
+}
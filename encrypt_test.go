@@ -0,0 +1,55 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/fakes3"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestEncrypt(c *C) {
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+
+	target := server.URL + "/bucket"
+	message, err := doEncryptCmd(target, "info", nil)
+	c.Assert(err, IsNil)
+	c.Assert(message.String(), Equals, target+"  none\n")
+	message, err = doEncryptCmd(target, "set", &client.SSE{Type: client.SSEKMS, KeyID: "payroll"})
+	c.Assert(err, IsNil)
+	c.Assert(message.String(), Equals, "Set default encryption of ‘"+target+"’ to SSE-KMS payroll.\n")
+	message, err = doEncryptCmd(target, "info", nil)
+	c.Assert(err, IsNil)
+	c.Assert(message.Type, Equals, client.SSEKMS)
+	c.Assert(message.KeyID, Equals, "payroll")
+	message, err = doEncryptCmd(target, "clear", nil)
+	c.Assert(err, IsNil)
+	c.Assert(message.Type, Equals, "")
+	_, ok := server.Document("/bucket", "encryption")
+	c.Assert(ok, Equals, false)
+
+	// refused configurations are not kept
+	server.AddFault(fakes3.Fault{Method: "PUT", Subresource: "encryption", Status: http.StatusForbidden, Code: "AccessDenied", Times: 1})
+	_, err = doEncryptCmd(target, "set", &client.SSE{Type: client.SSES3})
+	exitCode, _ := getErrorCode(err)
+	c.Assert(exitCode, Equals, exitPermission)
+	_, ok = server.Document("/bucket", "encryption")
+	c.Assert(ok, Equals, false)
+}
@@ -0,0 +1,89 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// encryptionConfig - server side encryption every upload under a prefix must use, prefixes are URLs or aliased
+// URLs like "s3:payroll". Uploads to targets which cannot encrypt are refused.
+//
+//	"Encryption": {
+//	  "s3:payroll":       {"Type": "SSE-KMS", "KeyID": "arn:aws:kms:us-east-1:123456789012:key/payroll"},
+//	  "s3:backup/secret": {"Type": "SSE-C", "Key": "MzJieXRlc2xvbmdzZWNyZXRrZXltdXN0cHJvdmlkZWQ="}
+//	}
+type encryptionConfig struct {
+	Type  string
+	KeyID string `json:",omitempty"` // SSE-KMS key, default key of the account if empty
+	Key   string `json:",omitempty"` // SSE-C key, base64 encoded
+}
+
+// isURLUnderPrefix - URL is prefix itself or below it, "s3:photos" does not cover "s3:photos-old"
+func isURLUnderPrefix(urlStr, prefix string) bool {
+	if !strings.HasPrefix(urlStr, prefix) {
+		return false
+	}
+	return len(urlStr) == len(prefix) || strings.HasSuffix(prefix, "/") || urlStr[len(prefix)] == '/'
+}
+
+// toSSE - validate an encryption configuration
+func (e encryptionConfig) toSSE() (client.SSE, bool) {
+	switch e.Type {
+	case client.SSEKMS:
+		return client.SSE{Type: e.Type, KeyID: e.KeyID}, e.Key == ""
+	case client.SSEC:
+		key, err := base64.StdEncoding.DecodeString(e.Key)
+		return client.SSE{Type: e.Type, Key: key}, err == nil && len(key) == 32 && e.KeyID == ""
+	}
+	return client.SSE{}, false
+}
+
+// getRequiredEncryption - encryption of the longest configured prefix of URL, nil if uploads may be plain
+func getRequiredEncryption(urlStr string) (*client.SSE, error) {
+	if !isMcConfigAvailable() {
+		return nil, nil
+	}
+	config, err := getMcConfig()
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	var sse *client.SSE
+	var longest string
+	for prefix, encryption := range config.Encryption {
+		expandedPrefix, err := aliasExpand(prefix, config.Aliases)
+		if err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+		if !isURLUnderPrefix(urlStr, expandedPrefix) || len(expandedPrefix) < len(longest) {
+			continue
+		}
+		if encryption == nil {
+			return nil, NewIodine(iodine.New(errInvalidEncryptionConfig{prefix: prefix}, nil))
+		}
+		required, ok := encryption.toSSE()
+		if !ok {
+			return nil, NewIodine(iodine.New(errInvalidEncryptionConfig{prefix: prefix}, nil))
+		}
+		sse, longest = &required, expandedPrefix
+	}
+	return sse, nil
+}
@@ -0,0 +1,76 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/quick"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestRequiredEncryption(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	config, err := quick.New(newConfigV1())
+	c.Assert(err, IsNil)
+	c.Assert(config.Load(mustGetMcConfigPath()), IsNil)
+	conf := config.Data().(*configV1)
+	conf.Encryption = map[string]*encryptionConfig{
+		filepath.Join(root, "secure"):      {Type: client.SSEKMS, KeyID: "payroll"},
+		filepath.Join(root, "secure", "c"): {Type: client.SSEC, Key: base64.StdEncoding.EncodeToString(make([]byte, 32))},
+	}
+	c.Assert(writeConfig(config), IsNil)
+	defer func() {
+		conf.Encryption = nil
+		c.Assert(writeConfig(config), IsNil)
+	}()
+
+	sse, err := getRequiredEncryption(filepath.Join(root, "secure", "a.txt"))
+	c.Assert(err, IsNil)
+	c.Assert(*sse, DeepEquals, client.SSE{Type: client.SSEKMS, KeyID: "payroll"})
+
+	// longest prefix wins
+	sse, err = getRequiredEncryption(filepath.Join(root, "secure", "c", "a.txt"))
+	c.Assert(err, IsNil)
+	c.Assert(sse.Type, Equals, client.SSEC)
+	c.Assert(sse.Key, DeepEquals, make([]byte, 32))
+
+	sse, err = getRequiredEncryption(filepath.Join(root, "secure-old", "a.txt"))
+	c.Assert(err, IsNil)
+	c.Assert(sse, IsNil)
+
+	// filesystem cannot encrypt, uploads are refused
+	err = putTarget(filepath.Join(root, "secure", "a.txt"), 5, bytes.NewReader([]byte("hello")))
+	c.Assert(iodine.ToError(err), FitsTypeOf, errEncryptionRequired{})
+	_, err = os.Stat(filepath.Join(root, "secure", "a.txt"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+	c.Assert(putTarget(filepath.Join(root, "plain.txt"), 5, bytes.NewReader([]byte("hello"))), IsNil)
+
+	conf.Encryption[filepath.Join(root, "secure")].Key = "not a key"
+	c.Assert(writeConfig(config), IsNil)
+	_, err = getRequiredEncryption(filepath.Join(root, "secure", "a.txt"))
+	c.Assert(iodine.ToError(err), FitsTypeOf, errInvalidEncryptionConfig{})
+}
@@ -16,6 +16,11 @@
 
 package main
 
+import (
+	"strconv"
+	"strings"
+)
+
 type errUnexpected struct{}
 
 func (e errUnexpected) Error() string {
@@ -90,6 +95,30 @@ func (e errInvalidAuth) Error() string {
 	return "Invalid auth keys"
 }
 
+// errWrongPassphrase - encrypted secrets of configuration file can not be opened with the passphrase
+type errWrongPassphrase struct{}
+
+func (e errWrongPassphrase) Error() string {
+	return "Wrong passphrase of configuration file."
+}
+
+// errPassphraseMismatch - passphrase was not entered the same way twice
+type errPassphraseMismatch struct{}
+
+func (e errPassphraseMismatch) Error() string {
+	return "Passphrases do not match."
+}
+
+// errUnknownVersion - file was written by another version of mc and can not be migrated
+type errUnknownVersion struct {
+	path    string
+	version string
+}
+
+func (e errUnknownVersion) Error() string {
+	return "Unknown version ‘" + e.version + "’ of ‘" + e.path + "’, it may be written by a newer mc."
+}
+
 type errNoMatchingHost struct{}
 
 func (e errNoMatchingHost) Error() string {
@@ -109,6 +138,34 @@ func (e errAliasExists) Error() string {
 	return "Already exists."
 }
 
+// errAliasNotFound - alias does not exist
+type errAliasNotFound struct {
+	name string
+}
+
+func (e errAliasNotFound) Error() string {
+	return "Alias ‘" + e.name + "’ does not exist."
+}
+
+// errAliasProbe - host of a new alias could not be reached with its keys
+type errAliasProbe struct {
+	URL string
+	err error
+}
+
+func (e errAliasProbe) Error() string {
+	return "Unable to reach ‘" + e.URL + "’, use ‘--force’ to add it anyway. " + e.err.Error()
+}
+
+// errAWSProfileNotFound - profile of AWS shared credentials files has no keys
+type errAWSProfileNotFound struct {
+	profile string
+}
+
+func (e errAWSProfileNotFound) Error() string {
+	return "AWS profile ‘" + e.profile + "’ has no access keys."
+}
+
 type errInvalidURL struct {
 	URL string
 }
@@ -161,8 +218,284 @@ func (e errSourceIsNotDir) Error() string {
 	return "Source ‘" + e.URL + "’ is not a directory."
 }
 
+type errTargetLocked struct {
+	URL string
+	PID int
+}
+
+func (e errTargetLocked) Error() string {
+	if e.PID == 0 {
+		return "Target ‘" + e.URL + "’ is locked by another mc process."
+	}
+	return "Target ‘" + e.URL + "’ is locked by another mc process with pid " + strconv.Itoa(e.PID) + "."
+}
+
+type errInvalidObjectName struct {
+	name   string
+	reason string
+}
+
+func (e errInvalidObjectName) Error() string {
+	return "Object name ‘" + e.name + "’ " + e.reason + "."
+}
+
+type errTargetNameClash struct {
+	name string
+}
+
+func (e errTargetNameClash) Error() string {
+	return "Object name ‘" + e.name + "’ clashes with the target of another source."
+}
+
 type errSourceListEmpty errInvalidArgument
 
 func (e errSourceListEmpty) Error() string {
 	return "Source list is empty."
 }
+
+type errTargetsFailed struct {
+	URLs    []string
+	Errors  []error
+	Written []int64
+	Total   int
+}
+
+func (e errTargetsFailed) Error() string {
+	msg := "Failed to write to " + strconv.Itoa(len(e.URLs)) + " of " + strconv.Itoa(e.Total) + " targets:"
+	for i := range e.URLs {
+		msg += " ‘" + e.URLs[i] + "’ after " + strconv.FormatInt(e.Written[i], 10) + " bytes, " + e.Errors[i].Error() + "."
+	}
+	return msg
+}
+
+type errInvalidHostAPI struct {
+	API string
+}
+
+func (e errInvalidHostAPI) Error() string {
+	return "Unsupported API ‘" + e.API + "’ in host configuration, valid values are [" + strings.Join(hostAPIs, ", ") + "]."
+}
+
+type errVerifyMismatch struct {
+	URL    string
+	Reason string
+}
+
+func (e errVerifyMismatch) Error() string {
+	return "Verification failed for ‘" + e.URL + "’, " + e.Reason + "."
+}
+
+type errTagsNotSupported struct {
+	URL string
+}
+
+func (e errTagsNotSupported) Error() string {
+	return "Tags are not supported for ‘" + e.URL + "’."
+}
+
+type errIncompleteNotSupported struct {
+	URL string
+}
+
+func (e errIncompleteNotSupported) Error() string {
+	return "Incomplete uploads are not supported for ‘" + e.URL + "’."
+}
+
+type errVersionsNotSupported struct {
+	URL string
+}
+
+func (e errVersionsNotSupported) Error() string {
+	return "Object versions are not supported for ‘" + e.URL + "’."
+}
+
+type errLifecycleNotSupported struct {
+	URL string
+}
+
+func (e errLifecycleNotSupported) Error() string {
+	return "Lifecycle rules are not supported for ‘" + e.URL + "’."
+}
+
+type errBucketEncryptionNotSupported struct {
+	URL string
+}
+
+func (e errBucketEncryptionNotSupported) Error() string {
+	return "Default encryption is not supported for ‘" + e.URL + "’."
+}
+
+type errBucketOptionsNotSupported struct {
+	URL string
+}
+
+func (e errBucketOptionsNotSupported) Error() string {
+	return "Regions and object lock are not supported for ‘" + e.URL + "’."
+}
+
+type errACLNotSupported struct {
+	URL string
+}
+
+func (e errACLNotSupported) Error() string {
+	return "Access control lists are not supported for ‘" + e.URL + "’."
+}
+
+type errNotificationsNotSupported struct {
+	URL string
+}
+
+func (e errNotificationsNotSupported) Error() string {
+	return "Bucket notifications are not supported for ‘" + e.URL + "’."
+}
+
+type errAdminNotSupported struct {
+	URL string
+}
+
+func (e errAdminNotSupported) Error() string {
+	return "‘" + e.URL + "’ is not a Minio server with the admin API."
+}
+
+type errInvalidEvent struct {
+	event string
+}
+
+func (e errInvalidEvent) Error() string {
+	return "Unknown event ‘" + e.event + "’, please choose from [put, delete, get] or an S3 event type."
+}
+
+type errPolicyNotSupported struct {
+	URL string
+}
+
+func (e errPolicyNotSupported) Error() string {
+	return "Bucket policies are not supported for ‘" + e.URL + "’."
+}
+
+type errInvalidPolicy struct {
+	reason string
+}
+
+func (e errInvalidPolicy) Error() string {
+	return "Invalid bucket policy, " + e.reason + "."
+}
+
+type errInvalidLifecycleRule struct {
+	id     string
+	reason string
+}
+
+func (e errInvalidLifecycleRule) Error() string {
+	return "Invalid lifecycle rule ‘" + e.id + "’, " + e.reason + "."
+}
+
+type errObjectLockNotSupported struct {
+	URL string
+}
+
+func (e errObjectLockNotSupported) Error() string {
+	return "Object lock is not supported for ‘" + e.URL + "’."
+}
+
+type errNotDeleted struct {
+	URL string
+}
+
+func (e errNotDeleted) Error() string {
+	return "No removed object with versions to restore found at ‘" + e.URL + "’."
+}
+
+type errRemoveNotSupported struct {
+	URL string
+}
+
+func (e errRemoveNotSupported) Error() string {
+	return "Removing is not supported for ‘" + e.URL + "’."
+}
+
+type errSessionNotRunning struct {
+	id string
+}
+
+func (e errSessionNotRunning) Error() string {
+	return "Session ‘" + e.id + "’ is not running."
+}
+
+type errSessionRunning struct {
+	id string
+}
+
+func (e errSessionRunning) Error() string {
+	return "Session ‘" + e.id + "’ is running, pause it first."
+}
+
+type errPauseNotSupported struct{}
+
+func (e errPauseNotSupported) Error() string {
+	return "Pausing a running session is not supported on this platform, interrupt it and resume the session later."
+}
+
+type errWebhookFailed struct {
+	URL    string
+	Status string
+}
+
+func (e errWebhookFailed) Error() string {
+	return "Webhook ‘" + e.URL + "’ failed with " + e.Status + "."
+}
+
+type errEncryptionRequired struct {
+	URL string
+}
+
+func (e errEncryptionRequired) Error() string {
+	return "Configuration requires server side encryption for ‘" + e.URL + "’, which this target does not support."
+}
+
+type errInvalidEncryptionConfig struct {
+	prefix string
+}
+
+func (e errInvalidEncryptionConfig) Error() string {
+	return "Invalid encryption configuration for ‘" + e.prefix + "’, Type must be SSE-KMS or SSE-C with a base64 encoded 256 bit Key."
+}
+
+type errWatchNotSupported struct {
+	URL string
+}
+
+func (e errWatchNotSupported) Error() string {
+	return "Watching ‘" + e.URL + "’ for changes is not supported."
+}
+
+type errReplayNotSupported struct {
+	URL string
+}
+
+func (e errReplayNotSupported) Error() string {
+	return "Server of ‘" + e.URL + "’ does not keep past events to replay."
+}
+
+type errForwardFailed struct {
+	URL    string
+	Status string
+}
+
+func (e errForwardFailed) Error() string {
+	return "Endpoint ‘" + e.URL + "’ rejected the event with ‘" + e.Status + "’."
+}
+
+type errInvalidManifestKey struct {
+	Path string
+}
+
+func (e errInvalidManifestKey) Error() string {
+	return "Manifest key ‘" + e.Path + "’ is not a PEM encoded ed25519 private key."
+}
+
+type errManifestSignature struct{}
+
+func (e errManifestSignature) Error() string {
+	return "Manifest signature does not match, the manifest was changed or signed with a different key."
+}
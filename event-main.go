@@ -0,0 +1,221 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var eventCmd = cli.Command{
+	Name:   "event",
+	Usage:  "Manage notifications of bucket events",
+	Action: runEventCmd,
+	Flags:  []cli.Flag{eventTypesFlag, eventPrefixFlag, eventSuffixFlag, eventIDFlag, allEventsFlag},
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} add [--event put,delete,get] [--prefix PREFIX] [--suffix SUFFIX] [--id ID] TARGET ARN
+   mc {{.Name}} list TARGET [ARN]
+   mc {{.Name}} remove --id ID|--all TARGET
+   mc {{.Name}} remove TARGET ARN
+
+DESCRIPTION:
+   Buckets send events of their objects to the notification target named by ARN, an SQS queue, SNS topic or
+   Lambda function on Amazon S3, a webhook or AMQP target configured on Minio servers. Events put, delete and
+   get stand for all events of objects created, removed and read, S3 event types like s3:ObjectCreated:Put
+   are taken as they are. Filters limit events to objects whose names start with PREFIX and end with SUFFIX.
+   Remove takes out the notification with an ID, all notifications to ARN or all notifications of a bucket.
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
+
+EXAMPLES:
+   1. Send uploads of photos to an SQS queue of Amazon S3 object storage.
+      $ mc {{.Name}} add --event put --prefix photos/ --suffix .jpg s3:albums arn:aws:sqs:us-west-2:444455556666:thumbnails
+
+   2. Send all changes of a bucket to the webhook target of a Minio server.
+      $ mc {{.Name}} add https://play.minio.io:9000/uploads arn:minio:sqs:us-east-1:1:webhook
+
+   3. List notifications of a bucket.
+      $ mc {{.Name}} list s3:albums
+
+   4. Stop sending events of a bucket to a queue.
+      $ mc {{.Name}} remove s3:albums arn:aws:sqs:us-west-2:444455556666:thumbnails
+`,
+}
+
+// eventTypes - S3 event types of the short names of events
+var eventTypes = map[string]string{
+	"put":    "s3:ObjectCreated:*",
+	"delete": "s3:ObjectRemoved:*",
+	"get":    "s3:ObjectAccessed:*",
+}
+
+// runEventCmd - is a handler for mc event command
+func runEventCmd(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "event", exitUsage) // last argument is exit code
+	}
+	operation, args := ctx.Args().First(), ctx.Args().Tail()
+	switch operation {
+	case "add":
+		if len(args) != 2 {
+			cli.ShowCommandHelpAndExit(ctx, "event", exitUsage) // last argument is exit code
+		}
+	case "list":
+		if len(args) > 2 {
+			cli.ShowCommandHelpAndExit(ctx, "event", exitUsage) // last argument is exit code
+		}
+	case "remove":
+		if len(args) > 2 {
+			cli.ShowCommandHelpAndExit(ctx, "event", exitUsage) // last argument is exit code
+		}
+		// exactly one of ARN, --id and --all chooses what is removed
+		chosen := 0
+		for _, set := range []bool{len(args) == 2, ctx.String("id") != "", ctx.Bool("all")} {
+			if set {
+				chosen++
+			}
+		}
+		if chosen != 1 {
+			console.Fatalf("Please choose notifications with ARN, ‘--id’ or all notifications with ‘--all’. %s\n", errInvalidArgument{})
+		}
+	default:
+		console.Fatalf("Unknown operation ‘%s’, please choose from [add, list, remove]. %s\n", operation, errInvalidArgument{})
+	}
+	var arn string
+	if len(args) == 2 {
+		arn = args[1]
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	targetURL, err := getExpandedURL(args[0], mustGetMcConfig().Aliases)
+	if err != nil {
+		switch e := iodine.ToError(err).(type) {
+		case errUnsupportedScheme:
+			console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+		default:
+			console.Fatalf("Unable to parse argument %s. %s\n", args[0], err)
+		}
+	}
+	clnt, err := target2Client(targetURL)
+	if err != nil {
+		console.Fatalf("Unable to initialize client for ‘%s’. %s\n", targetURL, err)
+	}
+	notifier, ok := clnt.(client.Notifier)
+	if !ok {
+		console.Fatalf("Unable to manage notifications of ‘%s’. %s\n", targetURL, errNotificationsNotSupported{URL: targetURL})
+	}
+	configs, err := notifier.GetNotifications()
+	if err != nil {
+		console.Fatalf("Unable to get notifications of ‘%s’. %s\n", targetURL, NewIodine(iodine.New(err, nil)))
+	}
+
+	switch operation {
+	case "add":
+		events, err := parseEventTypes(ctx.String("event"))
+		if err != nil {
+			console.Fatalln(err)
+		}
+		config := client.NotificationConfig{
+			ID:     ctx.String("id"),
+			ARN:    arn,
+			Events: events,
+			Prefix: ctx.String("prefix"),
+			Suffix: ctx.String("suffix"),
+		}
+		if err := notifier.SetNotifications(addNotification(configs, config)); err != nil {
+			console.Fatalf("Unable to add notification to ‘%s’. %s\n", targetURL, NewIodine(iodine.New(err, nil)))
+		}
+		console.Print(EventMessage{Target: targetURL, Operation: operation, ARN: arn, ID: config.ID})
+	case "list":
+		for _, config := range configs {
+			if arn == "" || config.ARN == arn {
+				console.Print(EventConfigMessage{Target: targetURL, NotificationConfig: config})
+			}
+		}
+	case "remove":
+		var removed int
+		if !ctx.Bool("all") {
+			configs, removed = removeNotifications(configs, arn, ctx.String("id"))
+			if removed == 0 {
+				console.Fatalf("No notification found on ‘%s’. %s\n", targetURL, errInvalidArgument{})
+			}
+		}
+		if err := notifier.SetNotifications(configs); err != nil {
+			console.Fatalf("Unable to remove notifications of ‘%s’. %s\n", targetURL, NewIodine(iodine.New(err, nil)))
+		}
+		console.Print(EventMessage{Target: targetURL, Operation: operation, ARN: arn, ID: ctx.String("id")})
+	}
+}
+
+// parseEventTypes - S3 event types of a comma separated list of short names and event types
+func parseEventTypes(list string) ([]string, error) {
+	var events []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		event, ok := eventTypes[strings.ToLower(name)]
+		switch {
+		case ok:
+		case strings.HasPrefix(name, "s3:"):
+			event = name
+		default:
+			return nil, NewIodine(iodine.New(errInvalidEvent{event: name}, nil))
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// addNotification - configs with config replacing the config of the same ID, or added if it has none
+func addNotification(configs []client.NotificationConfig, config client.NotificationConfig) []client.NotificationConfig {
+	if config.ID != "" {
+		for i := range configs {
+			if configs[i].ID == config.ID {
+				configs[i] = config
+				return configs
+			}
+		}
+	}
+	return append(configs, config)
+}
+
+// removeNotifications - configs without the ones of arn, or the one with id if arn is empty, and how many
+// were removed
+func removeNotifications(configs []client.NotificationConfig, arn, id string) ([]client.NotificationConfig, int) {
+	var kept []client.NotificationConfig
+	for _, config := range configs {
+		if (arn != "" && config.ARN == arn) || (arn == "" && config.ID == id) {
+			continue
+		}
+		kept = append(kept, config)
+	}
+	return kept, len(configs) - len(kept)
+}
@@ -0,0 +1,54 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/minio/mc/pkg/client"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestEventTypes(c *C) {
+	events, err := parseEventTypes("put, delete,s3:ObjectCreated:*,s3:ObjectRemoved:Delete")
+	c.Assert(err, IsNil)
+	c.Assert(events, DeepEquals, []string{"s3:ObjectCreated:*", "s3:ObjectRemoved:*", "s3:ObjectRemoved:Delete"})
+	_, err = parseEventTypes("put,update")
+	c.Assert(err, NotNil)
+}
+
+func (s *CmdTestSuite) TestEventNotifications(c *C) {
+	webhook := client.NotificationConfig{ID: "web", ARN: "arn:minio:sqs:us-east-1:1:webhook", Events: []string{"s3:ObjectCreated:*"}}
+	queue := client.NotificationConfig{ARN: "arn:aws:sqs:us-west-2:444455556666:thumbnails", Events: []string{"s3:ObjectCreated:*"}, Prefix: "photos/"}
+	configs := addNotification(nil, webhook)
+	configs = addNotification(configs, queue)
+	c.Assert(configs, HasLen, 2)
+
+	// same ID replaces
+	webhook.Events = []string{"s3:ObjectRemoved:*"}
+	configs = addNotification(configs, webhook)
+	c.Assert(configs, DeepEquals, []client.NotificationConfig{webhook, queue})
+	c.Assert(EventConfigMessage{NotificationConfig: queue}.String(), Equals,
+		"                     arn:aws:sqs:us-west-2:444455556666:thumbnails    photos/*                 s3:ObjectCreated:*\n")
+
+	kept, removed := removeNotifications(configs, "", "web")
+	c.Assert(removed, Equals, 1)
+	c.Assert(kept, DeepEquals, []client.NotificationConfig{queue})
+	kept, removed = removeNotifications(configs, queue.ARN, "")
+	c.Assert(removed, Equals, 1)
+	c.Assert(kept, DeepEquals, []client.NotificationConfig{webhook})
+	_, removed = removeNotifications(configs, "arn:aws:sns:us-west-2:444455556666:none", "")
+	c.Assert(removed, Equals, 0)
+}
@@ -0,0 +1,74 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Exit codes are part of the interface for scripts and wrapper tools, do not renumber them.
+const (
+	exitFailure        = 1 // failures not listed below
+	exitNotFound       = 2
+	exitPermission     = 3
+	exitPartialFailure = 4 // some objects failed, the rest was done
+	exitUsage          = 5 // invalid arguments, flags or configuration
+)
+
+// Code names of errors, printed in the code field of errors in JSON mode.
+const (
+	errorCodeUsage          = "usage"
+	errorCodeNotFound       = "not-found"
+	errorCodePermission     = "permission-denied"
+	errorCodePartialFailure = "partial-failure"
+	errorCodeError          = "error"
+)
+
+// getErrorCode - exit code and code name of err, registered with the console for Fatal and Error functions
+func getErrorCode(err error) (int, string) {
+	err = iodine.ToError(err)
+	switch err.(type) {
+	case errInvalidArgument, errInvalidAliasName, errInvalidURL, errInvalidSource, errInvalidTarget,
+		errInvalidGlobURL, errInvalidTheme, errSourceListEmpty, errUnsupportedScheme, errInvalidSessionID,
+		errNotConfigured, errInvalidLifecycleRule, errInvalidPolicy, errInvalidEvent, client.InvalidArgument,
+		client.InvalidBucketName, client.InvalidObjectName:
+		return exitUsage, errorCodeUsage
+	case client.NotFound, client.ObjectNotFound, errAliasNotFound, errTargetNotFound, errAWSProfileNotFound, errNotDeleted:
+		return exitNotFound, errorCodeNotFound
+	case errWrongPassphrase:
+		return exitPermission, errorCodePermission
+	case errTargetsFailed, errVerifyMismatch:
+		return exitPartialFailure, errorCodePartialFailure
+	}
+	switch s3.ErrorCode(err) {
+	case "NoSuchKey", "NoSuchBucket", "NoSuchUpload", "NoSuchVersion":
+		return exitNotFound, errorCodeNotFound
+	case "AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch":
+		return exitPermission, errorCodePermission
+	}
+	switch {
+	case os.IsNotExist(err):
+		return exitNotFound, errorCodeNotFound
+	case os.IsPermission(err):
+		return exitPermission, errorCodePermission
+	}
+	return exitFailure, errorCodeError
+}
@@ -0,0 +1,67 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-go"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestErrorCodes(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	_, statErr := os.Stat(filepath.Join(root, "missing"))
+	tests := []struct {
+		err      error
+		exitCode int
+		code     string
+	}{
+		{errInvalidArgument{}, exitUsage, errorCodeUsage},
+		{iodine.New(client.NotFound{Path: "s3:photos/2015"}, nil), exitNotFound, errorCodeNotFound},
+		{errAliasNotFound{name: "play"}, exitNotFound, errorCodeNotFound},
+		{statErr, exitNotFound, errorCodeNotFound},
+		{iodine.New(minio.ErrorResponse{Code: "AccessDenied"}, nil), exitPermission, errorCodePermission},
+		{minio.ErrorResponse{Code: "NoSuchBucket"}, exitNotFound, errorCodeNotFound},
+		{errTargetsFailed{}, exitPartialFailure, errorCodePartialFailure},
+		{errors.New("connection reset by peer"), exitFailure, errorCodeError},
+	}
+	for _, test := range tests {
+		exitCode, code := getErrorCode(test.err)
+		c.Assert(exitCode, Equals, test.exitCode)
+		c.Assert(code, Equals, test.code)
+	}
+
+	// console picks the last error among the arguments of Fatal functions
+	console.SetErrorCoder(getErrorCode)
+	defer console.SetErrorCoder(nil)
+	exitCode, code := console.ErrorCode("Unable to stat ‘s3:photos’. ", errInvalidArgument{}, client.NotFound{})
+	c.Assert(exitCode, Equals, exitNotFound)
+	c.Assert(code, Equals, errorCodeNotFound)
+	exitCode, code = console.ErrorCode("no error at all")
+	c.Assert(exitCode, Equals, exitFailure)
+	c.Assert(code, Equals, errorCodeError)
+}
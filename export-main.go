@@ -0,0 +1,99 @@
+/*
+ * Minio Client, (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var exportCmd = cli.Command{
+	Name:        "export",
+	Usage:       "Export files and folders into a tar archive",
+	Description: "Object metadata such as ETag, storage class and owner is stored in PAX headers with ‘" + exportPAXPrefix + "’ prefix",
+	Action:      runExportCmd,
+	Flags:       []cli.Flag{sinceFlag},
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} SOURCE ARCHIVE {{if .Description}}
+
+DESCRIPTION:
+   {{.Description}}{{end}}{{if .Flags}}
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}{{ end }}
+
+EXAMPLES:
+   1. Export a bucket from Amazon S3 object storage into a tar archive.
+      $ mc {{.Name}} https://s3.amazonaws.com/jukebox jukebox.tar
+
+   2. Export objects of a prefix modified after June 1st for an incremental offsite dump.
+      $ mc {{.Name}} --since 2015-06-01 s3:documents/2015 documents-2015-06.tar
+
+`,
+}
+
+// runExportCmd - is a handler for mc export command
+func runExportCmd(ctx *cli.Context) {
+	if len(ctx.Args()) != 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "export", exitUsage) // last argument is exit code
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	config := mustGetMcConfig()
+	sourceURL, err := getExpandedURL(ctx.Args().First(), config.Aliases)
+	if err != nil {
+		switch e := iodine.ToError(err).(type) {
+		case errUnsupportedScheme:
+			console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+		default:
+			console.Fatalf("Unable to parse argument %s. %s\n", ctx.Args().First(), err)
+		}
+	}
+	var since time.Time
+	if ctx.String("since") != "" {
+		since, err = parseSince(ctx.String("since"))
+		if err != nil {
+			console.Fatalf("Invalid date ‘%s’, use 2015-06-01 or RFC3339 format. %s\n", ctx.String("since"), NewIodine(iodine.New(err, nil)))
+		}
+	}
+	archive := ctx.Args()[1]
+	file, err := os.Create(archive)
+	if err != nil {
+		console.Fatalf("Unable to create archive ‘%s’. %s\n", archive, NewIodine(iodine.New(err, nil)))
+	}
+	// export is all or nothing, an incomplete archive would be mistaken for a full dump
+	err = doExport(stripRecursiveURL(sourceURL), since, file)
+	if err == nil {
+		err = file.Close()
+	} else {
+		file.Close()
+	}
+	if err != nil {
+		os.Remove(archive)
+		console.Fatalf("Unable to export ‘%s’. %s\n", sourceURL, NewIodine(iodine.New(err, nil)))
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// exportPAXPrefix - vendor prefix of PAX records carrying object metadata which ustar headers cannot hold
+const exportPAXPrefix = "MC."
+
+// parseSince - a date "2015-06-01" in local time or a RFC3339 timestamp
+func parseSince(since string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", since, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return time.Time{}, iodine.New(errInvalidArgument{}, map[string]string{"Since": since})
+	}
+	return t, nil
+}
+
+// exportHeader - tar header of an exported object, names always use '/'
+func exportHeader(name, sourceURL string, content *client.Content, size int64) *tar.Header {
+	records := map[string]string{exportPAXPrefix + "source": sourceURL}
+	for key, value := range map[string]string{
+		"etag":          content.ETag,
+		"storage-class": content.StorageClass,
+		"owner":         content.Owner,
+		"owner-id":      content.OwnerID,
+	} {
+		if value != "" {
+			records[exportPAXPrefix+key] = value
+		}
+	}
+	return &tar.Header{
+		Typeflag:   tar.TypeReg,
+		Name:       filepath.ToSlash(name),
+		Size:       size,
+		Mode:       0644,
+		ModTime:    content.Time,
+		PAXRecords: records,
+		Format:     tar.FormatPAX,
+	}
+}
+
+// exportObject - append one object to the archive
+func exportObject(tw *tar.Writer, name, sourceURL string, content *client.Content) error {
+	reader, size, err := getSource(sourceURL)
+	if err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"URL": sourceURL}))
+	}
+	defer reader.Close()
+	if err := tw.WriteHeader(exportHeader(name, sourceURL, content, size)); err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"URL": sourceURL}))
+	}
+	if _, err := io.CopyN(tw, reader, size); err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"URL": sourceURL}))
+	}
+	return nil
+}
+
+// doExport - stream regular files under sourceURL modified after since into a tar archive,
+// entry names are relative to the last folder of sourceURL same as recursive copy
+func doExport(sourceURL string, since time.Time, writer io.Writer) error {
+	sourceClnt, sourceContent, err := url2Stat(sourceURL)
+	if err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"URL": sourceURL}))
+	}
+	if !sourceContent.Type.IsDir() {
+		return NewIodine(iodine.New(errSourceIsNotDir{URL: sourceURL}, nil))
+	}
+	sourceURLParse, err := client.Parse(sourceURL)
+	if err != nil {
+		return NewIodine(iodine.New(errInvalidSource{URL: sourceURL}, nil))
+	}
+	sourceURLDelimited := sourceURLParse.String()[:strings.LastIndex(sourceURLParse.String(),
+		string(sourceURLParse.Separator))+1]
+
+	tw := tar.NewWriter(writer)
+	for entry := range sourceClnt.List(true) {
+		if entry.Err != nil {
+			return NewIodine(iodine.New(entry.Err, nil))
+		}
+		content := entry.Content
+		if !content.Type.IsRegular() || !content.Time.After(since) {
+			continue
+		}
+		objectURL := sourceURLDelimited + content.Name
+		if err := exportObject(tw, content.Name, objectURL, content); err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+		if !globalQuietFlag {
+			console.PrintC(ExportMessage{Source: objectURL, Name: filepath.ToSlash(content.Name), Length: content.Size})
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestExport(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	old := time.Date(2015, 5, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2015, 6, 15, 0, 0, 0, 0, time.UTC)
+	files := map[string]time.Time{
+		"prefix/old.txt":        old,
+		"prefix/new.txt":        recent,
+		"prefix/nested/new.txt": recent,
+	}
+	for name, modTime := range files {
+		objectPath := filepath.Join(root, name)
+		c.Assert(putTarget(objectPath, 5, bytes.NewReader([]byte("hello"))), IsNil)
+		c.Assert(os.Chtimes(objectPath, modTime, modTime), IsNil)
+	}
+
+	since, err := parseSince("2015-06-01T00:00:00Z")
+	c.Assert(err, IsNil)
+	_, err = parseSince("June 1st")
+	c.Assert(err, Not(IsNil))
+
+	var archive bytes.Buffer
+	err = doExport(filepath.Join(root, "prefix"), since, &archive)
+	c.Assert(err, IsNil)
+
+	exported := make(map[string]*tar.Header)
+	tr := tar.NewReader(&archive)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		data, err := ioutil.ReadAll(tr)
+		c.Assert(err, IsNil)
+		c.Assert(string(data), Equals, "hello")
+		exported[header.Name] = header
+	}
+	c.Assert(len(exported), Equals, 2)
+	header := exported["prefix/nested/new.txt"]
+	c.Assert(header, Not(IsNil))
+	c.Assert(header.ModTime.Equal(recent), Equals, true)
+	c.Assert(header.PAXRecords[exportPAXPrefix+"source"], Equals, filepath.Join(root, "prefix", "nested", "new.txt"))
+	c.Assert(exported["prefix/new.txt"], Not(IsNil))
+
+	// single files are not exported
+	err = doExport(filepath.Join(root, "prefix", "old.txt"), since, &archive)
+	c.Assert(err, Not(IsNil))
+}
@@ -0,0 +1,418 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/mc/pkg/fakes3"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+// fakeTree - files of a local tree copied by the tests below, by path relative to its root
+var fakeTree = map[string]string{
+	"a.txt":          "alpha",
+	"b.txt":          "bravo",
+	"docs/c.txt":     "charlie",
+	"docs/d.txt":     "delta",
+	"docs/old/e.txt": "echo",
+	"f.txt":          "foxtrot",
+}
+
+func writeFakeTree(c *C) string {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	for name, data := range fakeTree {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		c.Assert(os.MkdirAll(filepath.Dir(path), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(path, []byte(data), 0600), IsNil)
+	}
+	return root
+}
+
+// newCopySession - a cp session of sourceURL to targetURL, like runCopyCmd starts it. Tests run quiet, so no
+// progress bar is drawn
+func newCopySession(c *C, sourceURL, targetURL string) *sessionV2 {
+	c.Assert(createSessionDir(), IsNil)
+	session := newSessionV2()
+	session.Header.CommandType = "cp"
+	session.Header.CommandArgs = []string{sourceURL, targetURL}
+	session.Header.NoSniff = true
+	return session
+}
+
+func (s *CmdTestSuite) TestCopyFakeS3(c *C) {
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+	server.SetLatency(5 * time.Millisecond)
+	// one object can never be written
+	server.AddFault(fakes3.Fault{Method: "PUT", Path: "/bucket/backup/docs/d.txt", Code: "InternalError"})
+
+	root := writeFakeTree(c)
+	defer os.RemoveAll(root)
+
+	session := newCopySession(c, filepath.Join(root, "..."), server.URL+"/bucket/backup/")
+	doCopyCmdSession(session)
+	c.Assert(session.Header.Failed, Equals, 1)
+	c.Assert(session.Header.CopiedObjects, Equals, len(fakeTree)-1)
+	c.Assert(session.Close(), IsNil)
+
+	// the failing object does not stop the others
+	for name, data := range fakeTree {
+		stored, ok := server.GetObject("bucket", "backup/"+name)
+		if name == "docs/d.txt" {
+			c.Assert(ok, Equals, false)
+			c.Assert(server.Requests("PUT", "/bucket/backup/"+name), Equals, 1)
+			continue
+		}
+		c.Assert(ok, Equals, true, Commentf("%s was not copied", name))
+		c.Assert(string(stored), Equals, data)
+	}
+}
+
+func (s *CmdTestSuite) TestCopyFromFakeS3(c *C) {
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+	for name, data := range fakeTree {
+		server.PutObject("bucket", "photos/"+name, []byte(data))
+	}
+	server.PutObject("bucket", "other/z.txt", []byte("zulu"))
+	// pages of two keys, without NextMarker, force the lister to page with the last key
+	server.SetPageSize(2)
+	server.AddFault(fakes3.Fault{Method: "GET", Path: "/bucket/photos/b.txt", Status: 503, Code: "SlowDown", Times: 1})
+
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	session := newCopySession(c, server.URL+"/bucket/photos/...", root+string(os.PathSeparator))
+	doCopyCmdSession(session)
+	c.Assert(session.Close(), IsNil)
+
+	for name, data := range fakeTree {
+		copied, err := ioutil.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if name == "b.txt" { // not retried within a session
+			c.Assert(os.IsNotExist(err), Equals, true)
+			continue
+		}
+		c.Assert(err, IsNil, Commentf("%s was not copied", name))
+		c.Assert(string(copied), Equals, data)
+	}
+	_, err = os.Stat(filepath.Join(root, "z.txt"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *CmdTestSuite) TestResumeCopyFakeS3(c *C) {
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+
+	root := writeFakeTree(c)
+	defer os.RemoveAll(root)
+
+	// plan the session, then pretend it was interrupted with the first three and the fifth object copied
+	session := newCopySession(c, filepath.Join(root, "..."), server.URL+"/bucket/backup/")
+	c.Assert(session.Start(), IsNil)
+	doPrepareCopyURLs(session, nil)
+	c.Assert(session.Header.TotalObjects, Equals, len(fakeTree))
+	var planned []copyURLs
+	scanner := session.NewDataScanner()
+	for scanner.Scan() {
+		var cpURLs copyURLs
+		c.Assert(json.Unmarshal(scanner.Bytes(), &cpURLs), IsNil)
+		planned = append(planned, cpURLs)
+	}
+	c.Assert(len(planned), Equals, len(fakeTree))
+	var copiedBytes int64
+	for _, i := range []int{4, 0, 2, 1} {
+		session.Dispatch(i, planned[i].SourceContent.Name)
+		session.Done(planned[i].SourceContent.Name, planned[i].SourceContent.Size)
+		copiedBytes += planned[i].SourceContent.Size
+	}
+	c.Assert(session.Header.Copied, Equals, 3)
+	c.Assert(session.Header.Finished, DeepEquals, []int{4})
+	c.Assert(session.Save(), IsNil)
+	c.Assert(session.DataFP.Close(), IsNil)
+
+	resumed, err := loadSessionV2(session.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(resumed.Header.CopiedObjects, Equals, 4)
+	c.Assert(resumed.Header.CopiedBytes, Equals, copiedBytes)
+	doCopyCmdSession(resumed)
+	c.Assert(resumed.Header.CopiedObjects, Equals, len(planned))
+	c.Assert(resumed.Close(), IsNil)
+
+	for i, cpURLs := range planned {
+		name, err := filepath.Rel(root, cpURLs.SourceContent.Name)
+		c.Assert(err, IsNil)
+		puts := server.Requests("PUT", "/bucket/backup/"+filepath.ToSlash(name))
+		if i <= 2 || i == 4 {
+			c.Assert(puts, Equals, 0, Commentf("%s was copied again", name))
+			continue
+		}
+		c.Assert(puts, Equals, 1, Commentf("%s was not resumed", name))
+	}
+}
+
+func (s *CmdTestSuite) TestResumeLastCopiedSession(c *C) {
+	root := writeFakeTree(c)
+	defer os.RemoveAll(root)
+
+	// sessions saved before finished entries were counted only know the last copied entry
+	session := newCopySession(c, filepath.Join(root, "..."), filepath.Join(root, "copy")+string(filepath.Separator))
+	doPrepareCopyURLs(session, nil)
+	var planned []copyURLs
+	scanner := session.NewDataScanner()
+	for scanner.Scan() {
+		var cpURLs copyURLs
+		c.Assert(json.Unmarshal(scanner.Bytes(), &cpURLs), IsNil)
+		planned = append(planned, cpURLs)
+	}
+	session.Header.LastCopied = planned[1].SourceContent.Name
+	c.Assert(session.Save(), IsNil)
+	c.Assert(session.DataFP.Close(), IsNil)
+
+	resumed, err := loadSessionV2(session.SessionID)
+	c.Assert(err, IsNil)
+	defer resumed.Close()
+	c.Assert(resumed.Header.Copied, Equals, 2)
+	c.Assert(resumed.Header.CopiedObjects, Equals, 2)
+	c.Assert(resumed.Header.CopiedBytes, Equals, planned[0].SourceContent.Size+planned[1].SourceContent.Size)
+	c.Assert(resumed.IsDone(1), Equals, true)
+	c.Assert(resumed.IsDone(2), Equals, false)
+}
+
+func (s *CmdTestSuite) TestResumePreparedSession(c *C) {
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+
+	root := writeFakeTree(c)
+	defer os.RemoveAll(root)
+
+	// interrupted after planning, before any copy finished
+	session := newCopySession(c, filepath.Join(root, "..."), server.URL+"/bucket/backup/")
+	c.Assert(session.Start(), IsNil)
+	doPrepareCopyURLs(session, nil)
+	c.Assert(session.HasData(), Equals, true)
+	session.Terminate()
+	c.Assert(session.DataFP.Close(), IsNil)
+
+	// files added later are not part of the planned session
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "z.txt"), []byte("zulu"), 0600), IsNil)
+	resumed, err := loadSessionV2(session.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(resumed.HasData(), Equals, true)
+	doCopyCmdSession(resumed)
+	c.Assert(resumed.Header.CopiedObjects, Equals, len(fakeTree))
+	c.Assert(resumed.Close(), IsNil)
+
+	for name := range fakeTree {
+		c.Assert(server.Requests("PUT", "/bucket/backup/"+name), Equals, 1, Commentf("%s was not copied", name))
+	}
+	c.Assert(server.Requests("PUT", "/bucket/backup/z.txt"), Equals, 0)
+}
+
+func (s *CmdTestSuite) TestResumeInterruptedSession(c *C) {
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+
+	root := writeFakeTree(c)
+	defer os.RemoveAll(root)
+
+	interrupted := newCopySession(c, filepath.Join(root, "..."), server.URL+"/bucket/backup/")
+	interrupted.Header.CommandHash = getCommandHash(interrupted.Header)
+	c.Assert(interrupted.Start(), IsNil)
+	doPrepareCopyURLs(interrupted, nil)
+	interrupted.Terminate()
+	c.Assert(interrupted.DataFP.Close(), IsNil)
+
+	// the same command with other flags is a different command
+	other := newCopySession(c, filepath.Join(root, "..."), server.URL+"/bucket/backup/")
+	other.Header.Parents = true
+	other.Header.CommandHash = getCommandHash(other.Header)
+	defer other.Close()
+	found, err := findInterruptedSession(other.Header.CommandHash, other.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(found, IsNil)
+
+	// sessions which cannot be read do not keep others from being resumed
+	broken := newSID(8)
+	c.Assert(ioutil.WriteFile(getSessionFile(broken), []byte("{"), 0600), IsNil)
+	defer os.Remove(getSessionFile(broken))
+	found, err = findInterruptedSession(interrupted.Header.CommandHash, other.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(found.SessionID, Equals, interrupted.SessionID)
+	c.Assert(found.DataFP.Close(), IsNil)
+
+	again := newCopySession(c, filepath.Join(root, "..."), server.URL+"/bucket/backup/")
+	again.Header.CommandHash = getCommandHash(again.Header)
+	c.Assert(again.Header.CommandHash, Equals, interrupted.Header.CommandHash)
+	c.Assert(resumeInterruptedSession(again, true, true), Equals, false)
+	c.Assert(resumeInterruptedSession(again, true, false), Equals, true)
+	c.Assert(isSession(again.SessionID), Equals, false)
+	c.Assert(isSession(interrupted.SessionID), Equals, false)
+	for name := range fakeTree {
+		c.Assert(server.Requests("PUT", "/bucket/backup/"+name), Equals, 1, Commentf("%s was not copied", name))
+	}
+}
+
+func (s *CmdTestSuite) TestResumeCastFakeS3(c *C) {
+	server := fakes3.NewServer("one", "two")
+	defer server.Close()
+	// one object cannot be written to the second target in the first run
+	server.AddFault(fakes3.Fault{Method: "PUT", Path: "/two/backup/b.txt", Code: "InternalError", Times: 1})
+
+	root := writeFakeTree(c)
+	defer os.RemoveAll(root)
+
+	c.Assert(createSessionDir(), IsNil)
+	session := newSessionV2()
+	session.Header.CommandType = "cast"
+	session.Header.CommandArgs = []string{filepath.Join(root, "..."), server.URL + "/one/backup/", server.URL + "/two/backup/"}
+	session.Header.NoSniff = true
+	doCastCmdSession(session)
+	c.Assert(session.Header.Failed, Equals, 1)
+	c.Assert(len(session.Header.CastTargets), Equals, 1)
+	for _, targets := range session.Header.CastTargets {
+		c.Assert(targets, DeepEquals, []int{0})
+	}
+	c.Assert(session.Save(), IsNil)
+	c.Assert(session.DataFP.Close(), IsNil)
+
+	// the resumed session casts the failed object to the failed target alone
+	resumed, err := loadSessionV2(session.SessionID)
+	c.Assert(err, IsNil)
+	doCastCmdSession(resumed)
+	c.Assert(resumed.Header.Failed, Equals, 0)
+	c.Assert(resumed.Header.CopiedObjects, Equals, len(fakeTree))
+	c.Assert(resumed.Header.CastTargets, IsNil)
+	c.Assert(resumed.Close(), IsNil)
+
+	for name, data := range fakeTree {
+		puts := 1
+		if name == "b.txt" {
+			puts = 2
+		}
+		c.Assert(server.Requests("PUT", "/one/backup/"+name), Equals, 1, Commentf("%s", name))
+		c.Assert(server.Requests("PUT", "/two/backup/"+name), Equals, puts, Commentf("%s", name))
+		stored, ok := server.GetObject("two", "backup/"+name)
+		c.Assert(ok, Equals, true)
+		c.Assert(string(stored), Equals, data)
+	}
+}
+
+// fakeS3Commands - commands reading sub resources of S3 servers, for the error handling all of them share. Tests
+// of each command keep to what is particular to it
+var fakeS3Commands = []struct {
+	name        string
+	path        string // of the target on fakes3, holding the sub resource
+	subresource string
+	unreadable  string // document of the sub resource which cannot be read, none if empty
+	parseErr    error  // error of the command reading unreadable
+	unsupported error  // error of the command on a local target
+	run         func(targetURL string) error
+}{
+	{
+		name: "undelete", path: "/bucket", subresource: "versions",
+		unreadable: "<ListVersionsResult><IsTruncated>false</IsTruncated><DeleteMarker>", parseErr: &xml.SyntaxError{},
+		unsupported: errVersionsNotSupported{},
+		run:         func(targetURL string) error { return doUndeleteCmd(targetURL, true) },
+	},
+	{
+		name: "version", path: "/bucket", subresource: "versioning",
+		unreadable: "<VersioningConfiguration><Status>", parseErr: &xml.SyntaxError{},
+		unsupported: errVersionsNotSupported{},
+		run: func(targetURL string) error {
+			_, err := doVersionCmd(targetURL, "")
+			return err
+		},
+	},
+	{
+		name: "legalhold", path: "/bucket/records/1042.pdf", subresource: "legal-hold",
+		unreadable: "<LegalHold><Status>ON", parseErr: &xml.SyntaxError{},
+		unsupported: errObjectLockNotSupported{},
+		run:         func(targetURL string) error { return doLegalHoldCmd(targetURL, "info", false) },
+	},
+	{
+		name: "encrypt", path: "/bucket", subresource: "encryption",
+		unreadable: "<ServerSideEncryptionConfiguration>", parseErr: &xml.SyntaxError{},
+		unsupported: errBucketEncryptionNotSupported{},
+		run: func(targetURL string) error {
+			_, err := doEncryptCmd(targetURL, "info", nil)
+			return err
+		},
+	},
+	{
+		name: "policy", path: "/bucket", subresource: "policy",
+		unreadable: `{"Statement":[`, parseErr: errInvalidPolicy{},
+		unsupported: errPolicyNotSupported{},
+		run: func(targetURL string) error {
+			_, err := doPolicyCmd(targetURL, "get", "")
+			return err
+		},
+	},
+	{
+		name: "acl", path: "/bucket/records/1042.pdf", subresource: "acl",
+		unreadable: "<AccessControlPolicy>", parseErr: &xml.SyntaxError{},
+		unsupported: errACLNotSupported{},
+		run: func(targetURL string) error {
+			_, err := doGetACL(targetURL)
+			return err
+		},
+	},
+	{
+		name: "admin", path: "", unsupported: errAdminNotSupported{},
+		run: func(targetURL string) error {
+			_, err := doAdminInfo(targetURL)
+			return err
+		},
+	},
+}
+
+func (s *CmdTestSuite) TestFakeS3Errors(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	for _, command := range fakeS3Commands {
+		server := fakes3.NewServer("bucket")
+		server.PutObject("bucket", "records/1042.pdf", []byte("1042"))
+		target := server.URL + command.path
+
+		// access denied is a permission error, whatever the command
+		server.AddFault(fakes3.Fault{Path: command.path, Subresource: command.subresource, Status: http.StatusForbidden, Code: "AccessDenied", Times: 1})
+		exitCode, code := getErrorCode(command.run(target))
+		c.Assert(exitCode, Equals, exitPermission, Commentf("%s", command.name))
+		c.Assert(code, Equals, errorCodePermission, Commentf("%s", command.name))
+
+		if command.unreadable != "" {
+			server.SetDocument(command.path, command.subresource, []byte(command.unreadable))
+			err = command.run(target)
+			c.Assert(iodine.ToError(err), FitsTypeOf, command.parseErr, Commentf("%s", command.name))
+		}
+		server.Close()
+
+		err = command.run(root)
+		c.Assert(iodine.ToError(err), FitsTypeOf, command.unsupported, Commentf("%s", command.name))
+	}
+}
@@ -18,12 +18,43 @@ package main
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client/s3"
 	"github.com/minio/mc/pkg/console"
 )
 
+// debugValue - value of the debug flag, a bool flag optionally naming the file debugging output goes to
+type debugValue struct {
+	enabled bool
+	file    string
+}
+
+// Set - true or false as for bool flags, any other value is a file name and enables debugging
+func (d *debugValue) Set(value string) error {
+	if enabled, err := strconv.ParseBool(value); err == nil {
+		d.enabled, d.file = enabled, ""
+		return nil
+	}
+	d.enabled, d.file = true, value
+	return nil
+}
+
+func (d *debugValue) String() string {
+	if d.file != "" {
+		return d.file
+	}
+	return strconv.FormatBool(d.enabled)
+}
+
+// IsBoolFlag - lets ‘--debug’ go without a value
+func (d *debugValue) IsBoolFlag() bool {
+	return true
+}
+
 // Collection of mc commands currently supported are
 var commands = []cli.Command{}
 
@@ -39,7 +70,12 @@ var (
 
 	quietFlag = cli.BoolFlag{
 		Name:  "quiet, q",
-		Usage: "Suppress chatty console output",
+		Usage: "Suppress chatty console output, copies print only errors and a summary",
+	}
+
+	noColorFlag = cli.BoolFlag{
+		Name:  "no-color",
+		Usage: "Disable colors, also disabled by the NO_COLOR environment variable",
 	}
 
 	forceFlag = cli.BoolFlag{
@@ -53,9 +89,8 @@ var (
 	}
 
 	themeFlag = cli.StringFlag{
-		Name:  "theme",
-		Value: console.GetDefaultThemeName(),
-		Usage: fmt.Sprintf("Choose a console theme from this list [%s]", func() string {
+		Name: "theme",
+		Usage: fmt.Sprintf("Choose a console theme from this list [%s] or ‘mc config theme list’", func() string {
 			keys := []string{}
 			for _, themeName := range console.GetThemeNames() {
 				if console.GetThemeName() == themeName {
@@ -72,14 +107,524 @@ var (
 		Usage: "Enable json formatted output",
 	}
 
-	debugFlag = cli.BoolFlag{
+	ndjsonFlag = cli.BoolFlag{
+		Name:  "ndjson",
+		Usage: "Enable json formatted output with one compact object per line",
+	}
+
+	outputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "Output format, choose from [text, json, ndjson, csv, tsv]",
+	}
+
+	csvFlag = cli.BoolFlag{
+		Name:  "csv",
+		Usage: "Enable comma separated output with a header row for listing commands",
+	}
+
+	tsvFlag = cli.BoolFlag{
+		Name:  "tsv",
+		Usage: "Enable tab separated output with a header row for listing commands",
+	}
+
+	debugFlag = cli.GenericFlag{
 		Name:  "debug",
-		Usage: "Enable debugging output",
+		Value: new(debugValue),
+		Usage: "Enable debugging output, ‘--debug=FILE’ writes it with HTTP traces to FILE instead of standard error",
+	}
+
+	debugBodyFlag = cli.BoolFlag{
+		Name:  "debug-body",
+		Usage: "Include bodies of requests and responses up to 1MiB in HTTP traces",
+	}
+
+	capabilitiesFlag = cli.BoolFlag{
+		Name:  "capabilities",
+		Usage: "Print supported schemes, commands and flags of this build as JSON",
+	}
+
+	lookupFlag = cli.StringFlag{
+		Name:  "lookup",
+		Usage: "Bucket addressing of S3 API requests, overrides host config [auto, dns, path]",
+	}
+
+	logLevelFlag = cli.StringFlag{
+		Name:  "log-level",
+		Usage: "Print diagnostic messages of this level and above to standard error or ‘--log-file’ [debug, info, warn, error]",
+	}
+
+	logFileFlag = cli.StringFlag{
+		Name:  "log-file",
+		Usage: "Append diagnostic messages and results of operations as JSON lines to this file, or ‘syslog’",
+	}
+
+	auditFlag = cli.StringFlag{
+		Name:  "audit",
+		Usage: "Append a JSON line for every request sent to servers to this audit file",
+	}
+
+	logComponentFlag = cli.StringFlag{
+		Name:  "log-component",
+		Usage: fmt.Sprintf("Comma separated components to print diagnostic messages of [%s]", strings.Join(console.LogComponents, ", ")),
+	}
+
+	localFlag = cli.BoolFlag{
+		Name:  "local",
+		Usage: "Treat all arguments as local paths, even when they look like URLs or aliases",
+	}
+
+	maxIdleConnsFlag = cli.IntFlag{
+		Name:  "max-idle-conns",
+		Value: s3.DefaultMaxIdleConnsPerHost,
+		Usage: "Idle connections kept open per host of S3 API, raise for highly parallel copies",
+	}
+
+	requestTimeoutFlag = cli.DurationFlag{
+		Name:  "request-timeout",
+		Usage: "Time to wait for a reply to each S3 API request, transfers of object data are not limited, e.g. 1m",
+	}
+
+	keepAliveFlag = cli.DurationFlag{
+		Name:  "keep-alive",
+		Value: s3.DefaultKeepAlive,
+		Usage: "TCP keep-alive period of S3 API connections, lower it for links dropping idle connections",
+	}
+
+	queueMemoryFlag = cli.StringFlag{
+		Name:  "queue-memory",
+		Value: "256MiB",
+		Usage: "Memory for planned work of enormous trees before it moves to a temporary file, e.g. 1GiB",
 	}
 
 	// Add your new flags starting here
 )
 
+// Collection of command flags shared between cp and cast
+var (
+	lockFlag = cli.BoolFlag{
+		Name:  "lock",
+		Usage: "Refuse to start if another mc process on this host is copying to an overlapping target",
+	}
+
+	namePolicyFlag = cli.StringFlag{
+		Name:  "name-policy",
+		Usage: "Validate target names before upload, choose from [fail, skip, sanitize]",
+	}
+
+	windowsNamesFlag = cli.BoolFlag{
+		Name:  "windows-names",
+		Usage: "Also reject target names which are invalid on Windows, used with --name-policy",
+	}
+
+	attrFileFlag = cli.StringFlag{
+		Name:  "attr-file",
+		Usage: "JSON file mapping file extensions to Content-Type and Cache-Control of uploaded objects",
+	}
+
+	noSniffFlag = cli.BoolFlag{
+		Name:  "no-sniff",
+		Usage: "Do not guess Content-Type from the first bytes of files whose extension is unknown",
+	}
+
+	noMetadataFlag = cli.BoolFlag{
+		Name:  "no-metadata",
+		Usage: "Do not copy content headers, user metadata and tags of source objects",
+	}
+
+	preserveFlag = cli.BoolFlag{
+		Name:  "preserve",
+		Usage: "Restore permissions of copied files, and their owners when running as root",
+	}
+
+	planFlag = cli.BoolFlag{
+		Name:  "plan",
+		Usage: "Print the source to target mapping as a tree grouped by target folder without copying",
+	}
+
+	yesFlag = cli.BoolFlag{
+		Name:  "yes",
+		Usage: "Resume an interrupted session of the same command without asking",
+	}
+
+	noResumeFlag = cli.BoolFlag{
+		Name:  "no-resume",
+		Usage: "Start over even if the same command has an interrupted session",
+	}
+)
+
+// Collection of flags shared between ls and cp
+var (
+	tagsFlag = cli.StringFlag{
+		Name:  "tags",
+		Usage: "Select only objects carrying all the given tags, e.g. \"env=prod&tier=hot\"",
+	}
+)
+
+// Collection of flags used only by ls
+var (
+	longFlag = cli.BoolFlag{
+		Name:  "long",
+		Usage: "Use a long listing format with owner, ETag and storage class",
+	}
+
+	sortFlag = cli.StringFlag{
+		Name:  "sort",
+		Usage: "Order of listed entries, choose from [name, time, size], time lists most recently modified first, size largest first",
+	}
+
+	reverseFlag = cli.BoolFlag{
+		Name:  "reverse",
+		Usage: "Reverse the order of listed entries",
+	}
+
+	limitFlag = cli.IntFlag{
+		Name:  "limit",
+		Usage: "List at most this many entries, with --sort the first ones in that order",
+	}
+
+	summarizeFlag = cli.BoolFlag{
+		Name:  "summarize",
+		Usage: "Print the number and total size of listed objects at the end",
+	}
+
+	formatFlag = cli.StringFlag{
+		Name:  "format",
+		Usage: "Print entries with a Go template instead, e.g. '{{.Bytes}}\\t{{.Name}}'",
+	}
+)
+
+// Collection of flags shared between ls, rm and cp
+var (
+	versionsFlag = cli.BoolFlag{
+		Name:  "versions",
+		Usage: "List all versions of objects in versioned buckets, delete markers included",
+	}
+
+	rewindFlag = cli.StringFlag{
+		Name:  "rewind",
+		Usage: "Use objects of versioned buckets as they were at a date, RFC3339 timestamp or duration ago like 7d",
+	}
+
+	incompleteFlag = cli.BoolFlag{
+		Name:  "incomplete",
+		Usage: "Act on incomplete multipart uploads instead of objects",
+	}
+)
+
+// Collection of flags used only by mb
+var (
+	regionFlag = cli.StringFlag{
+		Name:  "region",
+		Usage: "Make buckets in this region, e.g. us-west-2, instead of the region of the endpoint",
+	}
+
+	withLockFlag = cli.BoolFlag{
+		Name:  "with-lock",
+		Usage: "Enable object lock on new buckets, it cannot be enabled later",
+	}
+)
+
+// Collection of flags used only by rb
+var (
+	emptyBucketFlag = cli.BoolFlag{
+		Name:  "force",
+		Usage: "Remove all objects, versions and incomplete uploads of buckets before removing them",
+	}
+)
+
+// Collection of flags used only by cp
+var (
+	mmapFlag = cli.StringFlag{
+		Name:  "mmap",
+		Usage: "Memory map local source files of at least this size and upload them without copying through read buffers, e.g. 64MiB",
+	}
+
+	setTagsFlag = cli.StringFlag{
+		Name:  "set-tags",
+		Usage: "Tag copied objects with the given tags on top of tags of their source, e.g. \"env=prod&tier=hot\"",
+	}
+
+	aclFlag = cli.StringFlag{
+		Name:  "acl",
+		Usage: "Set a canned ACL on copied objects, e.g. public-read",
+	}
+
+	versionIDFlag = cli.StringFlag{
+		Name:  "version-id",
+		Usage: "Copy this version of a single source object of a versioned bucket instead of its latest",
+	}
+
+	parallelRangeFlag = cli.IntFlag{
+		Name:  "parallel-range",
+		Usage: "Download remote objects larger than 32MiB to local targets with this many concurrent ranged GETs",
+	}
+
+	parentsFlag = cli.BoolFlag{
+		Name:  "parents",
+		Usage: "Recreate full source directory structure under the target directory",
+	}
+
+	manifestFlag = cli.StringFlag{
+		Name:  "manifest",
+		Usage: "Write a signed manifest of copied objects and their SHA-256 checksums to this file",
+	}
+)
+
+// Collection of flags used only by session
+var (
+	olderThanFlag = cli.StringFlag{
+		Name:  "older-than",
+		Usage: "Only sessions older than the given age, e.g. 30d or 12h, used with list, prune and clear all",
+	}
+)
+
+// Collection of flags used only by verify-endpoint
+var (
+	durationFlag = cli.DurationFlag{
+		Name:  "duration",
+		Value: time.Hour,
+		Usage: "Keep exercising the endpoint for this long",
+	}
+
+	intervalFlag = cli.DurationFlag{
+		Name:  "interval",
+		Value: time.Minute,
+		Usage: "Sampling interval of the error and latency time series",
+	}
+
+	mixFlag = cli.StringFlag{
+		Name:  "mix",
+		Value: "put=40,get=40,list=20",
+		Usage: "Relative frequency of operations, choose from [put, get, list]",
+	}
+
+	sizeFlag = cli.StringFlag{
+		Name:  "size",
+		Value: "1MiB",
+		Usage: "Size of each uploaded object",
+	}
+
+	objectsFlag = cli.IntFlag{
+		Name:  "objects",
+		Value: 16,
+		Usage: "Number of objects written and overwritten under the target",
+	}
+
+	reportFlag = cli.StringFlag{
+		Name:  "report",
+		Value: "mc-verify-endpoint.json",
+		Usage: "File to write the JSON report to, rewritten after every interval",
+	}
+)
+
+// Collection of flags used only by verify-mirror
+var (
+	sampleFlag = cli.Float64Flag{
+		Name:  "sample",
+		Value: 10,
+		Usage: "Percentage of source objects compared with the target in each pass",
+	}
+
+	thresholdFlag = cli.IntFlag{
+		Name:  "threshold",
+		Value: 0,
+		Usage: "Number of drifted objects tolerated in a pass before alerting",
+	}
+
+	everyFlag = cli.DurationFlag{
+		Name:  "every",
+		Value: 10 * time.Minute,
+		Usage: "Wait between passes, the first pass starts right away",
+	}
+
+	onceFlag = cli.BoolFlag{
+		Name:  "once",
+		Usage: "Run a single pass and exit with failure status if drift is above threshold",
+	}
+
+	webhookFlag = cli.StringFlag{
+		Name:  "webhook",
+		Usage: "URL to POST drift alerts to as JSON",
+	}
+)
+
+// Collection of flags used only by verify-manifest
+var (
+	publicKeyFlag = cli.StringFlag{
+		Name:  "public-key",
+		Usage: "Base64 ed25519 public key the manifest must be signed with, defaults to the local manifest key",
+	}
+)
+
+// Collection of flags used only by config
+var (
+	profileFlag = cli.StringFlag{
+		Name:  "profile",
+		Usage: "Profile of AWS shared credentials to import, AWS_PROFILE or default if empty",
+	}
+
+	noSecretsFlag = cli.BoolFlag{
+		Name:  "no-secrets",
+		Usage: "Leave access keys and SSE-C keys out of exported configuration",
+	}
+)
+
+// Collection of flags used only by export
+var (
+	sinceFlag = cli.StringFlag{
+		Name:  "since",
+		Usage: "Export only objects modified after this date [2015-06-01] or RFC3339 time",
+	}
+)
+
+// Collection of flags used only by grep
+var (
+	nameFlag = cli.StringFlag{
+		Name:  "name",
+		Usage: "Search only objects whose base name matches this shell pattern, e.g. \"*.log\"",
+	}
+
+	contextFlag = cli.IntFlag{
+		Name:  "context",
+		Usage: "Print this many lines before and after each matching line",
+	}
+
+	ignoreCaseFlag = cli.BoolFlag{
+		Name:  "ignore-case",
+		Usage: "Match the pattern regardless of case",
+	}
+)
+
+// Collection of flags used only by watch
+var (
+	replaySinceFlag = cli.StringFlag{
+		Name:  "replay-since",
+		Usage: "Print events the server kept since this date [2015-06-01] or RFC3339 time before live ones",
+	}
+	execFlag = cli.StringFlag{
+		Name:  "exec",
+		Usage: "Run this command through the shell for every event, with MC_EVENT_TYPE, MC_EVENT_URL, MC_EVENT_TIME and MC_EVENT_SIZE set",
+	}
+	forwardToFlag = cli.StringFlag{
+		Name:  "forward-to",
+		Usage: "POST every event as JSON to this HTTP endpoint, failed deliveries are retried",
+	}
+)
+
+// Collection of flags used only by ilm
+var (
+	ruleIDFlag = cli.StringFlag{
+		Name:  "id",
+		Usage: "ID of the lifecycle rule, a rule with the same ID is replaced",
+	}
+
+	prefixFlag = cli.StringFlag{
+		Name:  "prefix",
+		Usage: "Apply the rule only to objects under this prefix",
+	}
+
+	expiryDaysFlag = cli.IntFlag{
+		Name:  "expiry-days",
+		Usage: "Remove objects this many days after their creation",
+	}
+
+	transitionDaysFlag = cli.IntFlag{
+		Name:  "transition-days",
+		Usage: "Move objects to ‘--storage-class’ this many days after their creation",
+	}
+
+	storageClassFlag = cli.StringFlag{
+		Name:  "storage-class",
+		Usage: "Storage class objects are moved to, e.g. STANDARD_IA or GLACIER",
+	}
+
+	noncurrentExpiryDaysFlag = cli.IntFlag{
+		Name:  "noncurrent-expiry-days",
+		Usage: "Remove older versions of objects this many days after they were replaced",
+	}
+
+	disableFlag = cli.BoolFlag{
+		Name:  "disable",
+		Usage: "Add the rule disabled",
+	}
+
+	allRulesFlag = cli.BoolFlag{
+		Name:  "all",
+		Usage: "Remove all lifecycle rules",
+	}
+
+	xmlFlag = cli.BoolFlag{
+		Name:  "xml",
+		Usage: "Export the lifecycle configuration as S3 XML instead of JSON",
+	}
+)
+
+// Collection of flags used only by event
+var (
+	eventTypesFlag = cli.StringFlag{
+		Name:  "event",
+		Value: "put,delete",
+		Usage: "Comma separated events to send, put, delete, get or S3 event types",
+	}
+
+	eventPrefixFlag = cli.StringFlag{
+		Name:  "prefix",
+		Usage: "Send only events of objects whose names start with this prefix",
+	}
+
+	eventSuffixFlag = cli.StringFlag{
+		Name:  "suffix",
+		Usage: "Send only events of objects whose names end with this suffix, e.g. .jpg",
+	}
+
+	eventIDFlag = cli.StringFlag{
+		Name:  "id",
+		Usage: "ID of the notification, a notification with the same ID is replaced",
+	}
+
+	allEventsFlag = cli.BoolFlag{
+		Name:  "all",
+		Usage: "Remove all notifications of the bucket",
+	}
+)
+
+// Collection of flags used only by encrypt
+var (
+	kmsKeyFlag = cli.StringFlag{
+		Name:  "kms-key",
+		Usage: "ID or ARN of the SSE-KMS key, the default key of the account if omitted",
+	}
+)
+
+// setOutputFlag - sets the output flag named by --output, false if there is no such format
+func setOutputFlag(output string) bool {
+	switch output {
+	case "", "text":
+	case "json":
+		globalJSONFlag = true
+	case "ndjson":
+		globalNDJSONFlag = true
+	case "csv":
+		globalCSVFlag = true
+	case "tsv":
+		globalTSVFlag = true
+	default:
+		return false
+	}
+	return true
+}
+
+// isValidOutputFlags - only one machine readable output format can be chosen at a time
+func isValidOutputFlags() bool {
+	count := 0
+	for _, flag := range []bool{globalJSONFlag || globalNDJSONFlag, globalCSVFlag, globalTSVFlag} {
+		if flag {
+			count++
+		}
+	}
+	return count <= 1
+}
+
 // registerCmd registers a cli command
 func registerCmd(cmd cli.Command) {
 	commands = append(commands, cmd)
@@ -17,16 +17,34 @@
 // This package contains all the global variables and constants. ONLY TO BE ACCESSED VIA GET/SET FUNCTIONS.
 package main
 
-import "github.com/minio/minio/pkg/iodine"
+import (
+	"time"
+
+	"github.com/minio/minio/pkg/iodine"
+)
 
 var (
-	globalQuietFlag = false // Quiet flag set via command line
-	globalForceFlag = false // Force flag set via command line
-	globalAliasFlag = false // Alias flag set via command line
-	globalJSONFlag  = false // Json flag set via command line
-	globalDebugFlag = false // Debug flag set via command line
+	globalQuietFlag  = false // Quiet flag set via command line
+	globalForceFlag  = false // Force flag set via command line
+	globalAliasFlag  = false // Alias flag set via command line
+	globalJSONFlag   = false // Json flag set via command line
+	globalNDJSONFlag = false // Newline delimited json flag set via command line, implies globalJSONFlag
+	globalCSVFlag    = false // CSV flag set via command line
+	globalTSVFlag    = false // TSV flag set via command line
+	globalDebugFlag  = false // Debug flag set via command line
+	globalLocalFlag  = false // Local flag set via command line, arguments are local paths
+	globalLookup     = ""    // Bucket lookup set via command line, overrides host config
+
+	// HTTP transport tuning of S3 API set via command line
+	globalMaxIdleConns   = 0
+	globalRequestTimeout time.Duration
+	globalKeepAlive      time.Duration
+
+	// Memory for planned work set via command line, the rest spills to a temporary file
+	globalQueueMemory uint64 = 256 << 20
 
-	mcCurrentConfigVersion = "1.0.0"
+	mcCurrentConfigVersion  = "1.0.0"
+	mcCurrentSessionVersion = "1.2.0"
 )
 
 // mc configuration related constants.
@@ -38,7 +56,8 @@ const (
 
 // session config related constants
 const (
-	sessionDir = "session"
+	sessionDir     = "session"
+	sessionLockDir = "locks"
 )
 
 // default access and secret key
@@ -0,0 +1,94 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var grepCmd = cli.Command{
+	Name:        "grep",
+	Usage:       "Search contents of objects for a pattern",
+	Description: "Objects are read with ranged GETs. For plain text patterns S3 Select counts matches on the server first and objects without any are not read. Exits with 1 if nothing matched and 2 on errors",
+	Action:      runGrepCmd,
+	Flags:       []cli.Flag{nameFlag, contextFlag, ignoreCaseFlag},
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} PATTERN TARGET [TARGET...] {{if .Description}}
+
+DESCRIPTION:
+   {{.Description}}{{end}}{{if .Flags}}
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}{{ end }}
+
+EXAMPLES:
+   1. Search an object on Amazon S3 object storage for a regular expression.
+      $ mc {{.Name}} "HTTP/1.1\" 5[0-9][0-9]" https://s3.amazonaws.com/weblogs/access.log
+
+   2. Search all logs below a prefix, printing two lines around each match.
+      $ mc {{.Name}} --name "*.log" --context 2 timeout s3:weblogs/2015/...
+
+   3. Search local files regardless of case.
+      $ mc {{.Name}} --ignore-case klingon Documents/...
+
+`,
+}
+
+// runGrepCmd - is a handler for mc grep command
+func runGrepCmd(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "grep", exitUsage) // last argument is exit code
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	options, err := newGrepOptions(ctx.Args().First(), ctx.Bool("ignore-case"), ctx.String("name"), ctx.Int("context"))
+	if err != nil {
+		console.Fatalf("Invalid pattern, name or context. %s\n", err)
+	}
+	URLs, err := args2URLs(ctx.Args().Tail())
+	if err != nil {
+		console.Fatalf("One or more unknown URL types found %s. %s\n", ctx.Args().Tail(), err)
+	}
+	var matches int
+	var failed bool
+	for _, targetURL := range URLs {
+		found, targetFailed, err := doGrep(targetURL, options)
+		matches += found
+		if err != nil {
+			console.Errorln(NewIodine(iodine.New(err, map[string]string{"URL": targetURL})))
+			targetFailed = true
+		}
+		failed = failed || targetFailed
+	}
+	// exit codes of grep, scripts test whether anything matched
+	switch {
+	case failed:
+		os.Exit(2)
+	case matches == 0:
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,282 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// grepRangeSize - bytes fetched by each ranged GET of an object being searched
+const grepRangeSize = 8 * 1024 * 1024
+
+// grepOptions - what grep looks for and which objects it reads
+type grepOptions struct {
+	pattern *regexp.Regexp
+	// literal is the pattern as plain text if it has no regular expression syntax, S3 Select can count its matches
+	literal    string
+	ignoreCase bool
+	name       string // shell pattern base names of objects must match, all objects if empty
+	context    int    // lines printed before and after each match
+}
+
+// newGrepOptions - check and compile pattern and name
+func newGrepOptions(pattern string, ignoreCase bool, name string, context int) (grepOptions, error) {
+	options := grepOptions{ignoreCase: ignoreCase, name: name, context: context}
+	expr := pattern
+	if ignoreCase {
+		expr = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return grepOptions{}, NewIodine(iodine.New(err, nil))
+	}
+	options.pattern = re
+	// quotes and carriage returns change how S3 Select splits lines into fields
+	if regexp.QuoteMeta(pattern) == pattern && !strings.ContainsAny(pattern, "\"\r") {
+		options.literal = pattern
+	}
+	if _, err := path.Match(name, ""); err != nil {
+		return grepOptions{}, NewIodine(iodine.New(err, nil))
+	}
+	if context < 0 {
+		return grepOptions{}, NewIodine(iodine.New(errInvalidArgument{}, nil))
+	}
+	return options, nil
+}
+
+// matchName - true if the base name of an object passes the --name filter
+func (o grepOptions) matchName(name, separator string) bool {
+	if o.name == "" {
+		return true
+	}
+	name = strings.TrimSuffix(name, separator)
+	matched, _ := path.Match(o.name, name[strings.LastIndex(name, separator)+1:])
+	return matched
+}
+
+// grepSelectExpression - SQL counting the lines of an object which contain literal
+func grepSelectExpression(literal string, ignoreCase bool) string {
+	column := "s._1"
+	if ignoreCase {
+		column = "LOWER(s._1)"
+		literal = strings.ToLower(literal)
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `'`, `''`).Replace(literal)
+	return "SELECT COUNT(*) FROM S3Object s WHERE " + column + " LIKE '%" + escaped + "%' ESCAPE '\\'"
+}
+
+// selectCount - number of lines containing literal, counted by the server
+func selectCount(selector client.Selector, options grepOptions) (int64, error) {
+	reader, err := selector.SelectLines(grepSelectExpression(options.literal, options.ignoreCase))
+	if err != nil {
+		return 0, NewIodine(iodine.New(err, nil))
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return 0, NewIodine(iodine.New(err, nil))
+	}
+	count, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, NewIodine(iodine.New(err, nil))
+	}
+	return count, nil
+}
+
+// rangeReader - object contents fetched with consecutive ranged GETs of grepRangeSize,
+// only one range is held open at a time
+type rangeReader struct {
+	clnt   client.Client
+	offset int64
+	size   int64
+	start  int64 // offset the open range starts at
+	body   io.ReadCloser
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	for {
+		if r.body == nil {
+			if r.offset >= r.size {
+				return 0, io.EOF
+			}
+			length := r.size - r.offset
+			if length > grepRangeSize {
+				length = grepRangeSize
+			}
+			body, _, err := r.clnt.GetObject(r.offset, length)
+			if err != nil {
+				return 0, NewIodine(iodine.New(err, nil))
+			}
+			// filesystems return the rest of the file from offset
+			r.body = struct {
+				io.Reader
+				io.Closer
+			}{io.LimitReader(body, length), body}
+			r.start = r.offset
+		}
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err != io.EOF {
+			return n, err
+		}
+		r.body.Close()
+		r.body = nil
+		if r.offset == r.start { // object shrank while being read
+			return n, io.ErrUnexpectedEOF
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (r *rangeReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
+
+// grepLines - call found with the matching lines of reader and up to context lines around them, lines are
+// numbered from 1. Returns the number of matching lines
+func grepLines(reader io.Reader, options grepOptions, found func(line int, text string, match bool)) (int, error) {
+	type numberedLine struct {
+		number int
+		text   string
+	}
+	var before []numberedLine
+	after := 0
+	matches := 0
+	lines := bufio.NewReader(reader)
+	for number := 1; ; number++ {
+		text, err := lines.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return matches, NewIodine(iodine.New(err, nil))
+		}
+		if text == "" && err == io.EOF {
+			return matches, nil
+		}
+		text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+		switch {
+		case options.pattern.MatchString(text):
+			matches++
+			for _, line := range before {
+				found(line.number, line.text, false)
+			}
+			before = before[:0]
+			found(number, text, true)
+			after = options.context
+		case after > 0:
+			found(number, text, false)
+			after--
+		case options.context > 0:
+			before = append(before, numberedLine{number: number, text: text})
+			if len(before) > options.context {
+				before = before[1:]
+			}
+		}
+		if err == io.EOF {
+			return matches, nil
+		}
+	}
+}
+
+// grepObject - print matching lines of an object of size bytes, objects S3 Select finds no match in are not read
+func grepObject(clnt client.Client, objectURL string, size int64, options grepOptions) (int, error) {
+	if selector, ok := clnt.(client.Selector); ok && options.literal != "" {
+		// servers without S3 Select are searched by reading the object
+		if count, err := selectCount(selector, options); err == nil && count == 0 {
+			return 0, nil
+		}
+	}
+	reader := &rangeReader{clnt: clnt, size: size}
+	defer reader.Close()
+	last := 0
+	matches, err := grepLines(reader, options, func(line int, text string, match bool) {
+		if options.context > 0 && last > 0 && line > last+1 && !globalJSONFlag {
+			console.Print("--\n")
+		}
+		last = line
+		console.PrintC(GrepMessage{URL: objectURL, Line: line, Text: text, Match: match})
+	})
+	if err != nil {
+		return matches, NewIodine(iodine.New(err, map[string]string{"URL": objectURL}))
+	}
+	return matches, nil
+}
+
+// doGrep - search an object, or the objects of a folder, recursively if targetURL ends with "...".
+// Objects which can not be read are reported and skipped, failed is set if any was.
+func doGrep(targetURL string, options grepOptions) (matches int, failed bool, err error) {
+	recursive := isURLRecursive(targetURL)
+	targetURL = stripRecursiveURL(targetURL)
+	clnt, content, err := url2Stat(targetURL)
+	if err != nil {
+		return 0, false, NewIodine(iodine.New(err, nil))
+	}
+	if !content.Type.IsDir() {
+		matches, err := grepObject(clnt, targetURL, content.Size, options)
+		if err != nil {
+			return matches, false, NewIodine(iodine.New(err, nil))
+		}
+		return matches, false, nil
+	}
+	targetURLParse, err := client.Parse(targetURL)
+	if err != nil {
+		return 0, false, NewIodine(iodine.New(errInvalidTarget{URL: targetURL}, nil))
+	}
+	separator := string(targetURLParse.Separator)
+	targetURLDelimited := targetURLParse.String()[:strings.LastIndex(targetURLParse.String(), separator)+1]
+	for entry := range clnt.List(recursive) {
+		if entry.Err != nil {
+			return matches, failed, NewIodine(iodine.New(entry.Err, nil))
+		}
+		content := entry.Content
+		if !content.Type.IsRegular() || !options.matchName(content.Name, separator) {
+			continue
+		}
+		// recursive listings name objects from the folder of targetURL, others from targetURL
+		objectURL := targetURLDelimited + content.Name
+		if !recursive {
+			objectURL, err = urlJoinPath(targetURL, content.Name)
+		}
+		var objectClnt client.Client
+		if err == nil {
+			objectClnt, err = url2Client(objectURL)
+		}
+		if err == nil {
+			var found int
+			found, err = grepObject(objectClnt, objectURL, content.Size, options)
+			matches += found
+		}
+		if err != nil {
+			console.Errorln(NewIodine(iodine.New(err, map[string]string{"URL": objectURL})))
+			failed = true
+		}
+	}
+	return matches, failed, nil
+}
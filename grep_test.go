@@ -0,0 +1,87 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestGrepLines(c *C) {
+	options, err := newGrepOptions("warp", true, "", 1)
+	c.Assert(err, IsNil)
+	c.Assert(options.literal, Equals, "warp")
+	text := "one\ntwo\nWarp core\nthree\nfour\nfive\nwarp drive\r\nsix"
+	var found []string
+	matches, err := grepLines(strings.NewReader(text), options, func(line int, text string, match bool) {
+		found = append(found, fmt.Sprintf("%d %v %s", line, match, text))
+	})
+	c.Assert(err, IsNil)
+	c.Assert(matches, Equals, 2)
+	c.Assert(found, DeepEquals, []string{
+		"2 false two", "3 true Warp core", "4 false three",
+		"6 false five", "7 true warp drive", "8 false six",
+	})
+
+	options, err = newGrepOptions("warp.*", false, "", 0)
+	c.Assert(err, IsNil)
+	c.Assert(options.literal, Equals, "")
+	_, err = newGrepOptions("(", false, "", 0)
+	c.Assert(err, Not(IsNil))
+	_, err = newGrepOptions("warp", false, "[", 0)
+	c.Assert(err, Not(IsNil))
+
+	c.Assert(grepSelectExpression("50%_o'k", true), Equals,
+		`SELECT COUNT(*) FROM S3Object s WHERE LOWER(s._1) LIKE '%50\%\_o''k%' ESCAPE '\'`)
+}
+
+func (s *CmdTestSuite) TestGrep(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"logs/a.log":        "started\nwarp core breach\n",
+		"logs/nested/b.log": "warp\nwarp\n",
+		"logs/nested/c.txt": "warp\n",
+	}
+	for name, data := range files {
+		c.Assert(putTarget(filepath.Join(root, name), int64(len(data)), bytes.NewReader([]byte(data))), IsNil)
+	}
+	options, err := newGrepOptions("warp", false, "*.log", 0)
+	c.Assert(err, IsNil)
+	matches, failed, err := doGrep(filepath.Join(root, "logs")+"...", options)
+	c.Assert(err, IsNil)
+	c.Assert(failed, Equals, false)
+	c.Assert(matches, Equals, 3)
+
+	// without "..." only objects directly in the folder
+	matches, _, err = doGrep(filepath.Join(root, "logs"), options)
+	c.Assert(err, IsNil)
+	c.Assert(matches, Equals, 1)
+
+	// single objects regardless of --name
+	matches, _, err = doGrep(filepath.Join(root, "logs", "nested", "c.txt"), options)
+	c.Assert(err, IsNil)
+	c.Assert(matches, Equals, 1)
+}
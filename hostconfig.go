@@ -17,7 +17,9 @@
 package main
 
 import (
+	"math"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/minio/mc/pkg/client"
@@ -28,6 +30,91 @@ import (
 type hostConfig struct {
 	AccessKeyID     string
 	SecretAccessKey string
+	// API selects storage backend for this host, S3 compatible if empty.
+	// For B2 API, AccessKeyID and SecretAccessKey are account id and application key.
+	// For sftp://, ftp:// and webdav:// URLs API is ignored, AccessKeyID and SecretAccessKey are user name and password.
+	// For hdfs:// URLs AccessKeyID is the user name
+	// HTTP API reads files from plain web servers, credentials are ignored. Sources of http and https hosts
+	// without an entry are read with it too
+	// S3v2 and S3v4 pin the signature version of S3 API, S3 tries version 4 and falls back to version 2
+	API string `json:",omitempty"`
+	// CredentialsFile is a service account JSON key file used by GCS API, or SSH private key for sftp:// URLs
+	CredentialsFile string `json:",omitempty"`
+	// Lookup selects how S3 API requests address buckets: "dns" puts the bucket in the Host header,
+	// "path" puts it in the URL path and "auto" (default) uses dns for Amazon S3 endpoints only
+	Lookup string `json:",omitempty"`
+	// Region S3 API requests are signed for, derived from the host if empty and corrected from server replies
+	Region string `json:",omitempty"`
+	// Anonymous sends S3 API requests unsigned so public buckets can be read without keys, keys are ignored
+	Anonymous bool `json:",omitempty"`
+	// Proxy S3 API requests of this host are sent through, e.g. "http://proxy.example.com:3128",
+	// "none" connects directly. HTTP_PROXY, HTTPS_PROXY and NO_PROXY of environment are used if empty
+	Proxy string `json:",omitempty"`
+	// Defaults whenever this host is used, so they need not be repeated in every cron entry:
+	// Insecure accepts self-signed TLS certificates of S3 API servers,
+	// Parallel is the number of objects cp and cast transfer at once, StorageClass the class S3 API uploads
+	// are stored with, e.g. "REDUCED_REDUNDANCY"
+	Insecure     bool   `json:",omitempty"`
+	Parallel     int    `json:",omitempty"`
+	StorageClass string `json:",omitempty"`
+}
+
+// Supported values for hostConfig API
+const (
+	hostAPIS3   = "S3"
+	hostAPIS3v2 = "S3v2"
+	hostAPIS3v4 = "S3v4"
+	hostAPIGCS  = "GCS"
+	hostAPIB2   = "B2"
+	hostAPIHTTP = "HTTP"
+)
+
+// hostAPIs - list of all supported host APIs
+var hostAPIs = []string{hostAPIS3, hostAPIS3v2, hostAPIS3v4, hostAPIGCS, hostAPIB2, hostAPIHTTP}
+
+// matchHostGlob - length of the path prefix of a Hosts entry url matches, -1 if it does not match. Entries are
+// a host glob like "s3*.amazonaws.com", optionally preceded by a scheme and followed by a path prefix like
+// "https://s3.amazonaws.com/team-a/*". Prefixes without a trailing "*" match whole path elements only
+func matchHostGlob(glob string, url *client.URL) (int, error) {
+	pattern := glob
+	if i := strings.Index(pattern, "://"); i >= 0 {
+		if pattern[:i] != url.Scheme {
+			return -1, nil
+		}
+		pattern = pattern[i+len("://"):]
+	}
+	hostGlob, pathPrefix := pattern, ""
+	if i := strings.Index(pattern, "/"); i >= 0 {
+		hostGlob, pathPrefix = pattern[:i], pattern[i:]
+	}
+	match, err := filepath.Match(hostGlob, url.Host)
+	if err != nil || !match {
+		return -1, err
+	}
+	switch {
+	case strings.HasSuffix(pathPrefix, "*"):
+		pathPrefix = strings.TrimSuffix(pathPrefix, "*")
+		if !strings.HasPrefix(url.Path, pathPrefix) {
+			return -1, nil
+		}
+	case pathPrefix != "":
+		pathPrefix = strings.TrimSuffix(pathPrefix, "/")
+		if url.Path != pathPrefix && !strings.HasPrefix(url.Path, pathPrefix+"/") {
+			return -1, nil
+		}
+	}
+	return len(strings.TrimSuffix(pathPrefix, "/")), nil
+}
+
+// isExactHostGlob - true if the host of a Hosts entry has no wildcards, it matches a single host only
+func isExactHostGlob(glob string) bool {
+	if i := strings.Index(glob, "://"); i >= 0 {
+		glob = glob[i+len("://"):]
+	}
+	if i := strings.Index(glob, "/"); i >= 0 {
+		glob = glob[:i]
+	}
+	return !strings.ContainsAny(glob, "*?[\\")
 }
 
 // getHostConfig retrieves host specific configuration such as access keys, certs.
@@ -40,15 +127,40 @@ func getHostConfig(URL string) (*hostConfig, error) {
 	if err != nil {
 		return nil, NewIodine(iodine.New(errInvalidURL{URL: URL}, nil))
 	}
-	// No host matching or keys needed for filesystem requests
-	if url.Type == client.Filesystem {
+	// No host matching or keys needed for filesystem requests and local archives
+	if url.Type == client.Filesystem || url.Scheme == "tar" {
 		hostCfg := &hostConfig{
 			AccessKeyID:     "",
 			SecretAccessKey: "",
 		}
 		return hostCfg, nil
 	}
-	// No host matching or keys needed for 127.0.0.1 URL's skip them
+	// entries scoped to the longest path prefix win, then exact hosts like those of aliases and MC_HOST_
+	// variables over globs. Remaining ties go to the longest entry for a stable choice
+	var matchedGlob string
+	matchedLength, matchedExact := -1, false
+	for globURL := range config.Hosts {
+		length, err := matchHostGlob(globURL, url)
+		if err != nil {
+			return nil, NewIodine(iodine.New(errInvalidGlobURL{glob: globURL, request: URL}, nil))
+		}
+		if length < matchedLength || length == -1 {
+			continue
+		}
+		exact := isExactHostGlob(globURL)
+		if length > matchedLength || (exact && !matchedExact) ||
+			(exact == matchedExact && (len(globURL) > len(matchedGlob) || (len(globURL) == len(matchedGlob) && globURL < matchedGlob))) {
+			matchedGlob, matchedLength, matchedExact = globURL, length, exact
+		}
+	}
+	if matchedLength >= 0 {
+		hostCfg := config.Hosts[matchedGlob]
+		if hostCfg == nil {
+			return nil, NewIodine(iodine.New(errInvalidAuth{}, nil))
+		}
+		return hostCfg, nil
+	}
+	// No keys needed for 127.0.0.1 URL's without a host entry, like MC_HOST_ variables would add
 	if strings.Contains(url.Host, "127.0.0.1") {
 		hostCfg := &hostConfig{
 			AccessKeyID:     "",
@@ -56,21 +168,33 @@ func getHostConfig(URL string) (*hostConfig, error) {
 		}
 		return hostCfg, nil
 	}
-	for globURL, hostCfg := range config.Hosts {
-		match, err := filepath.Match(globURL, url.Host)
-		if err != nil {
-			return nil, NewIodine(iodine.New(errInvalidGlobURL{glob: globURL, request: URL}, nil))
-		}
-		if match {
-			if hostCfg == nil {
-				return nil, NewIodine(iodine.New(errInvalidAuth{}, nil))
-			}
-			return hostCfg, nil
-		}
+	// SFTP may authenticate with user from URL and default SSH keys, FTP falls back to anonymous login
+	// and WebDAV to unauthenticated requests for public shares. HDFS namenode decides identity without user name
+	switch url.Scheme {
+	case "sftp", "ftp", "ftps", "ftpes", "webdav", "webdavs", "hdfs", "webhdfs", "swebhdfs":
+		return &hostConfig{}, nil
+	}
+	if _, ok := client.Lookup(url.Scheme); ok { // registered backends decide whether they need keys
+		return &hostConfig{}, nil
 	}
 	return nil, NewIodine(iodine.New(errNoMatchingHost{}, nil))
 }
 
+// getSourceHostConfig retrieves host specific configuration of URLs read from. Sources of http and https
+// hosts without an entry are read from plain web servers, other URLs of such hosts are not configured
+func getSourceHostConfig(URL string) (*hostConfig, error) {
+	hostCfg, err := getHostConfig(URL)
+	if _, ok := iodine.ToError(err).(errNoMatchingHost); ok {
+		if url, parseErr := client.Parse(URL); parseErr == nil && (url.Scheme == "http" || url.Scheme == "https") {
+			return &hostConfig{API: hostAPIHTTP}, nil
+		}
+	}
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return hostCfg, nil
+}
+
 // mustGetHostConfig retrieves host specific configuration such as access keys, exits upon error
 func mustGetHostConfig(URL string) *hostConfig {
 	hostCfg, err := getHostConfig(URL)
@@ -79,3 +203,14 @@ func mustGetHostConfig(URL string) *hostConfig {
 	}
 	return hostCfg
 }
+
+// getParallel - objects to transfer at once, Parallel of the first host of urls setting it or one less than
+// the number of CPUs
+func getParallel(urls ...string) int {
+	for _, url := range urls {
+		if hostCfg, err := getHostConfig(url); err == nil && hostCfg.Parallel > 0 {
+			return hostCfg.Parallel
+		}
+	}
+	return int(math.Max(float64(runtime.NumCPU())-1, 1))
+}
@@ -0,0 +1,99 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"math"
+	"runtime"
+
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestHostConfigPrefix(c *C) {
+	scoped := map[string]*hostConfig{
+		"https://s3.amazonaws.com/team-a/*":     {AccessKeyID: "team-a"},
+		"s3.amazonaws.com/team-a/reports":       {AccessKeyID: "reports"},
+		"s3.amazonaws.com/team-b":               {AccessKeyID: "team-b"},
+		"http://s3.amazonaws.com/team-c/*":      {AccessKeyID: "insecure"},
+		"s3.amazonaws.com/team-d*":              {AccessKeyID: "team-d"},
+		"s3-*.amazonaws.com/team-a/reports/old": {AccessKeyID: "other-host"},
+	}
+	conf, err := loadConfigV1()
+	c.Assert(err, IsNil)
+	for glob, hostCfg := range scoped {
+		conf.Hosts[glob] = hostCfg
+	}
+	c.Assert(saveConfigV1(conf), IsNil)
+	defer func() {
+		conf, err := loadConfigV1()
+		c.Assert(err, IsNil)
+		for glob := range scoped {
+			delete(conf.Hosts, glob)
+		}
+		c.Assert(saveConfigV1(conf), IsNil)
+	}()
+
+	for url, accessKeyID := range map[string]string{
+		"https://s3.amazonaws.com/team-a/object":            "team-a",
+		"https://s3.amazonaws.com/team-a/reports/2015.csv":  "reports",
+		"https://s3.amazonaws.com/team-a/reports":           "reports",
+		"https://s3.amazonaws.com/team-a/reportsX/2015.csv": "team-a",
+		"https://s3.amazonaws.com/team-b/object":            "team-b",
+		"https://s3.amazonaws.com/team-bb/object":           globalAccessKeyID, // s3*.amazonaws.com of default config
+		"https://s3.amazonaws.com/team-c/object":            globalAccessKeyID,
+		"http://s3.amazonaws.com/team-c/object":             "insecure",
+		"https://s3.amazonaws.com/team-dd/object":           "team-d",
+		"https://s3.amazonaws.com":                          globalAccessKeyID,
+	} {
+		hostCfg, err := getHostConfig(url)
+		c.Assert(err, IsNil)
+		c.Assert(hostCfg.AccessKeyID, Equals, accessKeyID, Commentf("%s", url))
+	}
+}
+
+func (s *CmdTestSuite) TestHostConfigDefaults(c *C) {
+	conf, err := loadConfigV1()
+	c.Assert(err, IsNil)
+	conf.Hosts["parallel.example.com"] = &hostConfig{Parallel: 16, Insecure: true, StorageClass: "REDUCED_REDUNDANCY"}
+	c.Assert(saveConfigV1(conf), IsNil)
+	defer func() {
+		conf, err := loadConfigV1()
+		c.Assert(err, IsNil)
+		delete(conf.Hosts, "parallel.example.com")
+		c.Assert(saveConfigV1(conf), IsNil)
+	}()
+
+	c.Assert(getParallel("https://parallel.example.com/bucket", "https://s3.amazonaws.com/bucket"), Equals, 16)
+	c.Assert(getParallel("https://s3.amazonaws.com/bucket"), Equals, int(math.Max(float64(runtime.NumCPU())-1, 1)))
+}
+
+func (s *CmdTestSuite) TestSourceHostConfig(c *C) {
+	// web servers without an entry are read from, but never written to
+	_, err := getHostConfig("https://releases.example.org/images/file.iso")
+	c.Assert(iodine.ToError(err), FitsTypeOf, errNoMatchingHost{})
+	_, err = target2Client("https://releases.example.org/images/file.iso")
+	c.Assert(err, NotNil)
+	hostCfg, err := getSourceHostConfig("https://releases.example.org/images/file.iso")
+	c.Assert(err, IsNil)
+	c.Assert(hostCfg.API, Equals, hostAPIHTTP)
+
+	hostCfg, err = getSourceHostConfig("https://s3.amazonaws.com/bucket/object")
+	c.Assert(err, IsNil)
+	c.Assert(hostCfg.AccessKeyID, Equals, globalAccessKeyID)
+	c.Assert(hostCfg.API, Not(Equals), hostAPIHTTP)
+}
@@ -0,0 +1,306 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var ilmCmd = cli.Command{
+	Name:   "ilm",
+	Usage:  "Manage lifecycle rules of buckets",
+	Action: runILMCmd,
+	Flags:  []cli.Flag{ruleIDFlag, prefixFlag, expiryDaysFlag, transitionDaysFlag, storageClassFlag, noncurrentExpiryDaysFlag, disableFlag, allRulesFlag, xmlFlag},
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} add [FLAGS] TARGET
+   mc {{.Name}} ls TARGET
+   mc {{.Name}} rm --id ID|--all TARGET
+   mc {{.Name}} export [--xml] TARGET [FILE]
+   mc {{.Name}} import TARGET [FILE]
+
+DESCRIPTION:
+   Lifecycle rules remove or move objects to other storage classes as they age. Export writes the rules of a
+   bucket as JSON, or S3 XML with --xml, import replaces all rules of a bucket with the rules of a file in
+   either format. FILE is standard output or input if left out or ‘-’.
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
+
+EXAMPLES:
+   1. Remove logs a month after they were written on Amazon S3 object storage.
+      $ mc {{.Name}} add --id expire-logs --prefix logs/ --expiry-days 30 s3:webapp
+
+   2. Move backups to Glacier after a week and remove them after a year.
+      $ mc {{.Name}} add --prefix backup/ --transition-days 7 --storage-class GLACIER --expiry-days 365 s3:archive
+
+   3. List lifecycle rules of a bucket on Minio object storage.
+      $ mc {{.Name}} ls https://play.minio.io:9000/webapp
+
+   4. Remove a lifecycle rule.
+      $ mc {{.Name}} rm --id expire-logs s3:webapp
+
+   5. Copy lifecycle rules from one bucket to another.
+      $ mc {{.Name}} export s3:webapp webapp-ilm.json
+      $ mc {{.Name}} import s3:webapp-staging webapp-ilm.json
+`,
+}
+
+// lifecycleDocument - lifecycle rules of a bucket as exported to JSON
+type lifecycleDocument struct {
+	Rules []client.LifecycleRule `json:"rules"`
+}
+
+// runILMCmd - is a handler for mc ilm command
+func runILMCmd(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "ilm", exitUsage) // last argument is exit code
+	}
+	operation, args := ctx.Args().First(), ctx.Args().Tail()
+	switch operation {
+	case "add", "ls", "rm":
+		if len(args) != 1 {
+			cli.ShowCommandHelpAndExit(ctx, "ilm", exitUsage) // last argument is exit code
+		}
+	case "export", "import":
+		if len(args) > 2 {
+			cli.ShowCommandHelpAndExit(ctx, "ilm", exitUsage) // last argument is exit code
+		}
+	default:
+		console.Fatalf("Unknown operation ‘%s’, please choose from [add, ls, rm, export, import]. %s\n", operation, errInvalidArgument{})
+	}
+	file := "-"
+	if len(args) == 2 {
+		file = args[1]
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	targetURL, err := getExpandedURL(args[0], mustGetMcConfig().Aliases)
+	if err != nil {
+		switch e := iodine.ToError(err).(type) {
+		case errUnsupportedScheme:
+			console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+		default:
+			console.Fatalf("Unable to parse argument %s. %s\n", args[0], err)
+		}
+	}
+	clnt, err := target2Client(targetURL)
+	if err != nil {
+		console.Fatalf("Unable to initialize client for ‘%s’. %s\n", targetURL, err)
+	}
+	lifecycler, ok := clnt.(client.Lifecycler)
+	if !ok {
+		console.Fatalf("Unable to manage lifecycle of ‘%s’. %s\n", targetURL, errLifecycleNotSupported{URL: targetURL})
+	}
+
+	switch operation {
+	case "add":
+		rule := client.LifecycleRule{
+			ID:                       ctx.String("id"),
+			Prefix:                   ctx.String("prefix"),
+			Enabled:                  !ctx.Bool("disable"),
+			ExpirationDays:           ctx.Int("expiry-days"),
+			TransitionDays:           ctx.Int("transition-days"),
+			TransitionStorageClass:   ctx.String("storage-class"),
+			NoncurrentExpirationDays: ctx.Int("noncurrent-expiry-days"),
+		}
+		if err := checkLifecycleRule(rule); err != nil {
+			console.Fatalln(err)
+		}
+		rules, err := lifecycler.GetLifecycle()
+		if err != nil {
+			console.Fatalf("Unable to get lifecycle rules of ‘%s’. %s\n", targetURL, NewIodine(iodine.New(err, nil)))
+		}
+		rules, rule = addLifecycleRule(rules, rule)
+		if err := lifecycler.SetLifecycle(rules); err != nil {
+			console.Fatalf("Unable to add lifecycle rule to ‘%s’. %s\n", targetURL, NewIodine(iodine.New(err, nil)))
+		}
+		console.Print(LifecycleMessage{Target: targetURL, Operation: operation, ID: rule.ID})
+	case "ls":
+		rules, err := lifecycler.GetLifecycle()
+		if err != nil {
+			console.Fatalf("Unable to get lifecycle rules of ‘%s’. %s\n", targetURL, NewIodine(iodine.New(err, nil)))
+		}
+		for _, rule := range rules {
+			console.Print(LifecycleRuleMessage{Target: targetURL, LifecycleRule: rule})
+		}
+	case "rm":
+		if (ctx.String("id") == "") == !ctx.Bool("all") {
+			console.Fatalf("Please choose a rule with ‘--id’ or all rules with ‘--all’. %s\n", errInvalidArgument{})
+		}
+		var rules []client.LifecycleRule
+		if !ctx.Bool("all") {
+			rules, err = lifecycler.GetLifecycle()
+			if err != nil {
+				console.Fatalf("Unable to get lifecycle rules of ‘%s’. %s\n", targetURL, NewIodine(iodine.New(err, nil)))
+			}
+			var found bool
+			if rules, found = removeLifecycleRule(rules, ctx.String("id")); !found {
+				console.Fatalf("No lifecycle rule ‘%s’ found on ‘%s’. %s\n", ctx.String("id"), targetURL, errInvalidArgument{})
+			}
+		}
+		if err := lifecycler.SetLifecycle(rules); err != nil {
+			console.Fatalf("Unable to remove lifecycle rules of ‘%s’. %s\n", targetURL, NewIodine(iodine.New(err, nil)))
+		}
+		console.Print(LifecycleMessage{Target: targetURL, Operation: operation, ID: ctx.String("id")})
+	case "export":
+		rules, err := lifecycler.GetLifecycle()
+		if err != nil {
+			console.Fatalf("Unable to get lifecycle rules of ‘%s’. %s\n", targetURL, NewIodine(iodine.New(err, nil)))
+		}
+		data, err := formatLifecycle(rules, ctx.Bool("xml"))
+		if err != nil {
+			console.Fatalf("Unable to export lifecycle rules of ‘%s’. %s\n", targetURL, err)
+		}
+		if file == "-" {
+			os.Stdout.Write(data)
+			return
+		}
+		if err := ioutil.WriteFile(file, data, 0644); err != nil {
+			console.Fatalf("Unable to write ‘%s’. %s\n", file, NewIodine(iodine.New(err, nil)))
+		}
+		console.Print(LifecycleMessage{Target: targetURL, Operation: operation, File: file})
+	case "import":
+		var data []byte
+		if file == "-" {
+			data, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			data, err = ioutil.ReadFile(file)
+		}
+		if err != nil {
+			console.Fatalf("Unable to read ‘%s’. %s\n", file, NewIodine(iodine.New(err, nil)))
+		}
+		rules, err := parseLifecycle(data)
+		if err != nil {
+			console.Fatalf("Unable to parse lifecycle rules of ‘%s’. %s\n", file, err)
+		}
+		if err := lifecycler.SetLifecycle(rules); err != nil {
+			console.Fatalf("Unable to import lifecycle rules to ‘%s’. %s\n", targetURL, NewIodine(iodine.New(err, nil)))
+		}
+		console.Print(LifecycleMessage{Target: targetURL, Operation: operation, File: file})
+	}
+}
+
+// checkLifecycleRule - a rule needs an action, and a storage class to transition objects to
+func checkLifecycleRule(rule client.LifecycleRule) error {
+	var reason string
+	switch {
+	case rule.ExpirationDays < 0 || rule.TransitionDays < 0 || rule.NoncurrentExpirationDays < 0:
+		reason = "days must not be negative"
+	case rule.TransitionDays > 0 && rule.TransitionStorageClass == "":
+		reason = "objects need a storage class to transition to"
+	case rule.ExpirationDays == 0 && rule.TransitionStorageClass == "" && rule.NoncurrentExpirationDays == 0:
+		reason = "it has no expiration or transition"
+	default:
+		return nil
+	}
+	return NewIodine(iodine.New(errInvalidLifecycleRule{id: rule.ID, reason: reason}, nil))
+}
+
+// addLifecycleRule - rules with rule replacing the rule of the same ID, or added with a new ID
+func addLifecycleRule(rules []client.LifecycleRule, rule client.LifecycleRule) ([]client.LifecycleRule, client.LifecycleRule) {
+	if rule.ID == "" {
+		ids := make(map[string]bool)
+		for _, r := range rules {
+			ids[r.ID] = true
+		}
+		for n := len(rules) + 1; rule.ID == "" || ids[rule.ID]; n++ {
+			rule.ID = "rule-" + strconv.Itoa(n)
+		}
+	}
+	for i := range rules {
+		if rules[i].ID == rule.ID {
+			rules[i] = rule
+			return rules, rule
+		}
+	}
+	return append(rules, rule), rule
+}
+
+// removeLifecycleRule - rules without the rule of ID, false if there is none
+func removeLifecycleRule(rules []client.LifecycleRule, id string) ([]client.LifecycleRule, bool) {
+	for i := range rules {
+		if rules[i].ID == id {
+			return append(rules[:i], rules[i+1:]...), true
+		}
+	}
+	return rules, false
+}
+
+// formatLifecycle - rules as indented JSON, or as the XML document of the S3 API
+func formatLifecycle(rules []client.LifecycleRule, asXML bool) ([]byte, error) {
+	if asXML {
+		data, err := s3.MarshalLifecycle(rules)
+		if err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+		return append(data, '\n'), nil
+	}
+	if rules == nil {
+		rules = []client.LifecycleRule{}
+	}
+	data, err := json.MarshalIndent(lifecycleDocument{Rules: rules}, "", "  ")
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return append(data, '\n'), nil
+}
+
+// parseLifecycle - rules of an exported JSON document or an S3 XML document, told apart by their first
+// character. Every rule is checked like rules added by ilm add
+func parseLifecycle(data []byte) ([]client.LifecycleRule, error) {
+	var rules []client.LifecycleRule
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("<")) {
+		var err error
+		if rules, err = s3.UnmarshalLifecycle(data); err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+	} else {
+		document := lifecycleDocument{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+		rules = document.Rules
+	}
+	ids := make(map[string]bool)
+	for _, rule := range rules {
+		if rule.ID != "" && ids[rule.ID] {
+			return nil, NewIodine(iodine.New(errInvalidLifecycleRule{id: rule.ID, reason: "its ID is used twice"}, nil))
+		}
+		ids[rule.ID] = true
+		if err := checkLifecycleRule(rule); err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+	}
+	return rules, nil
+}
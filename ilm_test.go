@@ -0,0 +1,63 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestILM(c *C) {
+	c.Assert(checkLifecycleRule(client.LifecycleRule{ExpirationDays: 30}), IsNil)
+	err := checkLifecycleRule(client.LifecycleRule{ID: "archive", TransitionDays: 7})
+	c.Assert(iodine.ToError(err), FitsTypeOf, errInvalidLifecycleRule{})
+	c.Assert(checkLifecycleRule(client.LifecycleRule{Prefix: "logs/"}), NotNil)
+	c.Assert(checkLifecycleRule(client.LifecycleRule{ExpirationDays: -1}), NotNil)
+
+	// rules without ID are named after their position, rules with an ID replace the rule of the same ID
+	rules, rule := addLifecycleRule(nil, client.LifecycleRule{Prefix: "logs/", ExpirationDays: 30})
+	c.Assert(rule.ID, Equals, "rule-1")
+	rules, _ = addLifecycleRule(rules, client.LifecycleRule{ID: "rule-2", Prefix: "tmp/", ExpirationDays: 1})
+	rules, rule = addLifecycleRule(rules, client.LifecycleRule{Prefix: "backup/", ExpirationDays: 365})
+	c.Assert(rule.ID, Equals, "rule-3")
+	rules, _ = addLifecycleRule(rules, client.LifecycleRule{ID: "rule-1", Prefix: "logs/", ExpirationDays: 7})
+	c.Assert(rules, HasLen, 3)
+	c.Assert(rules[0].ExpirationDays, Equals, 7)
+	rules, found := removeLifecycleRule(rules, "rule-2")
+	c.Assert(found, Equals, true)
+	c.Assert(rules, HasLen, 2)
+	_, found = removeLifecycleRule(rules, "rule-2")
+	c.Assert(found, Equals, false)
+
+	// exported rules import in either format
+	for _, asXML := range []bool{false, true} {
+		data, err := formatLifecycle(rules, asXML)
+		c.Assert(err, IsNil)
+		imported, err := parseLifecycle(data)
+		c.Assert(err, IsNil)
+		c.Assert(imported, DeepEquals, rules)
+	}
+	data, err := formatLifecycle(nil, false)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "{\n  \"rules\": []\n}\n")
+
+	_, err = parseLifecycle([]byte(`{"rules": [{"id": "a", "expiration-days": 1}, {"id": "a", "expiration-days": 2}]}`))
+	c.Assert(iodine.ToError(err), FitsTypeOf, errInvalidLifecycleRule{})
+	_, err = parseLifecycle([]byte(`{"rules": [{"id": "a", "transition-days": 1}]}`))
+	c.Assert(err, NotNil)
+}
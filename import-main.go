@@ -0,0 +1,76 @@
+/*
+ * Minio Client, (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var importCmd = cli.Command{
+	Name:        "import",
+	Usage:       "Import members of a tar or zip archive as individual objects",
+	Description: "Archive is read as a stream, members are never extracted to local disk. Gzip compressed tar archives are detected automatically",
+	Action:      runImportCmd,
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} ARCHIVE TARGET {{if .Description}}
+
+DESCRIPTION:
+   {{.Description}}{{end}}{{if .Flags}}
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}{{ end }}
+
+EXAMPLES:
+   1. Restore an archive made by ‘mc export’ into a bucket on Amazon S3 object storage.
+      $ mc {{.Name}} documents-2015-06.tar s3:documents/
+
+   2. Publish a zipped static site to Minio object storage.
+      $ mc {{.Name}} site.zip https://play.minio.io:9000/www/
+
+`,
+}
+
+// runImportCmd - is a handler for mc import command
+func runImportCmd(ctx *cli.Context) {
+	if len(ctx.Args()) != 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "import", exitUsage) // last argument is exit code
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	config := mustGetMcConfig()
+	archive := ctx.Args().First()
+	targetURL, err := getExpandedURL(ctx.Args()[1], config.Aliases)
+	if err != nil {
+		switch e := iodine.ToError(err).(type) {
+		case errUnsupportedScheme:
+			console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+		default:
+			console.Fatalf("Unable to parse argument %s. %s\n", ctx.Args()[1], err)
+		}
+	}
+	if err := doImport(archive, targetURL); err != nil {
+		console.Fatalf("Unable to import ‘%s’. %s\n", archive, NewIodine(iodine.New(err, nil)))
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// memberName - archive member name safe to join to target, members escaping the target with ".." or
+// absolute paths are kept inside it
+func memberName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.Replace(name, "\\", "/", -1)), "/")
+}
+
+// importMember - upload one archive member under targetURL
+func importMember(targetURL, name string, size int64, reader io.Reader) error {
+	name = memberName(name)
+	if name == "" {
+		return nil
+	}
+	objectURL, err := urlJoinPath(targetURL, name)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	if err := putTarget(objectURL, size, reader); err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"URL": objectURL}))
+	}
+	if !globalQuietFlag {
+		console.PrintC(ImportMessage{Name: name, Target: objectURL, Length: size})
+	}
+	return nil
+}
+
+// doImportTar - upload regular members of a tar stream, gzip compressed streams are detected
+func doImportTar(reader io.Reader, targetURL string) error {
+	buffered := bufio.NewReader(reader)
+	magic, _ := buffered.Peek(2)
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+		defer gz.Close()
+		reader = gz
+	} else {
+		reader = buffered
+	}
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+		// folders are implied by object names, links and devices have no content
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			continue
+		}
+		if err := importMember(targetURL, header.Name, header.Size, tr); err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+	}
+}
+
+// doImportZip - upload regular members of a zip file, members are decompressed while uploading
+func doImportZip(file *os.File, size int64, targetURL string) error {
+	zr, err := zip.NewReader(file, size)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	for _, member := range zr.File {
+		if !member.Mode().IsRegular() {
+			continue
+		}
+		reader, err := member.Open()
+		if err != nil {
+			return NewIodine(iodine.New(err, map[string]string{"Member": member.Name}))
+		}
+		err = importMember(targetURL, member.Name, int64(member.UncompressedSize64), reader)
+		reader.Close()
+		if err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+	}
+	return nil
+}
+
+// doImport - upload members of a tar, tar.gz or zip archive under targetURL, preserving member paths
+func doImport(archive, targetURL string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	defer file.Close()
+	if strings.HasSuffix(strings.ToLower(archive), ".zip") {
+		fi, err := file.Stat()
+		if err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+		return doImportZip(file, fi.Size(), targetURL)
+	}
+	return doImportTar(file, targetURL)
+}
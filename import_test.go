@@ -0,0 +1,84 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestImport(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	c.Assert(memberName("../../etc/passwd"), Equals, "etc/passwd")
+	c.Assert(memberName("/abs/file"), Equals, "abs/file")
+	c.Assert(memberName("dir\\file"), Equals, "dir/file")
+
+	// round trip through export, gzip compressed
+	for _, name := range []string{"source/a.txt", "source/nested/b.txt"} {
+		c.Assert(putTarget(filepath.Join(root, name), 5, bytes.NewReader([]byte("hello"))), IsNil)
+	}
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	c.Assert(doExport(filepath.Join(root, "source"), time.Time{}, gz), IsNil)
+	c.Assert(gz.Close(), IsNil)
+	tarFile := filepath.Join(root, "source.tar.gz")
+	c.Assert(ioutil.WriteFile(tarFile, archive.Bytes(), 0600), IsNil)
+
+	c.Assert(doImport(tarFile, filepath.Join(root, "restored")), IsNil)
+	for _, name := range []string{"restored/source/a.txt", "restored/source/nested/b.txt"} {
+		data, err := ioutil.ReadFile(filepath.Join(root, name))
+		c.Assert(err, IsNil)
+		c.Assert(string(data), Equals, "hello")
+	}
+
+	// zip members are uploaded without extracting
+	var zipArchive bytes.Buffer
+	zw := zip.NewWriter(&zipArchive)
+	for name, content := range map[string]string{"site/index.html": "<html/>", "../escape.txt": "escape"} {
+		w, err := zw.Create(name)
+		c.Assert(err, IsNil)
+		_, err = w.Write([]byte(content))
+		c.Assert(err, IsNil)
+	}
+	_, err = zw.Create("site/empty/")
+	c.Assert(err, IsNil)
+	c.Assert(zw.Close(), IsNil)
+	zipFile := filepath.Join(root, "site.zip")
+	c.Assert(ioutil.WriteFile(zipFile, zipArchive.Bytes(), 0600), IsNil)
+
+	c.Assert(doImport(zipFile, filepath.Join(root, "www")), IsNil)
+	data, err := ioutil.ReadFile(filepath.Join(root, "www", "site", "index.html"))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "<html/>")
+	data, err = ioutil.ReadFile(filepath.Join(root, "www", "escape.txt"))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "escape")
+	_, err = os.Stat(filepath.Join(root, "www", "site", "empty"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	c.Assert(doImport(filepath.Join(root, "missing.tar"), filepath.Join(root, "www")), Not(IsNil))
+}
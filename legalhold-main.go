@@ -0,0 +1,138 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var legalHoldCmd = cli.Command{
+	Name:   "legalhold",
+	Usage:  "Set, clear or show legal holds of objects",
+	Action: runLegalHoldCmd,
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} set|clear|info TARGET [TARGET...]
+
+DESCRIPTION:
+   Objects under legal hold cannot be removed or overwritten until the hold is cleared, regardless of their
+   retention. Legal holds need a bucket created with object lock. Targets ending with ‘...’ apply to all
+   objects under them.
+
+EXAMPLES:
+   1. Put an object under legal hold on Amazon S3 object storage.
+      $ mc {{.Name}} set s3:records/2015/patient-1042.pdf
+
+   2. Put all objects under a prefix under legal hold on Minio object storage.
+      $ mc {{.Name}} set https://play.minio.io:9000/records/2015/...
+
+   3. Release the legal hold of an object.
+      $ mc {{.Name}} clear s3:records/2015/patient-1042.pdf
+
+   4. Show legal holds of all objects under a prefix as JSON.
+      $ mc --json {{.Name}} info s3:records/2015/...
+`,
+}
+
+// runLegalHoldCmd - is a handler for mc legalhold command
+func runLegalHoldCmd(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "legalhold", exitUsage) // last argument is exit code
+	}
+	operation := ctx.Args().First()
+	switch operation {
+	case "set", "clear", "info":
+	default:
+		console.Fatalf("Unknown operation ‘%s’, please choose from [set, clear, info]. %s\n", operation, errInvalidArgument{})
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	config := mustGetMcConfig()
+	for _, arg := range ctx.Args().Tail() {
+		targetURL, err := getExpandedURL(arg, config.Aliases)
+		if err != nil {
+			switch e := iodine.ToError(err).(type) {
+			case errUnsupportedScheme:
+				console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+			default:
+				console.Fatalf("Unable to parse argument %s. %s\n", arg, err)
+			}
+		}
+		if err := doLegalHoldCmd(stripRecursiveURL(targetURL), operation, isURLRecursive(targetURL)); err != nil {
+			console.Fatalf("Unable to %s legal hold of ‘%s’. %s\n", operation, targetURL, err)
+		}
+	}
+}
+
+// doLegalHoldCmd - set, clear or show the legal hold of the object of targetURL, or of all objects under it if
+// recursive
+func doLegalHoldCmd(targetURL, operation string, recursive bool) error {
+	clnt, err := target2Client(targetURL)
+	if err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	if !recursive {
+		return doLegalHold(clnt, operation)
+	}
+	for contentCh := range clnt.List(true) {
+		if contentCh.Err != nil {
+			return NewIodine(iodine.New(contentCh.Err, map[string]string{"Target": targetURL}))
+		}
+		if !contentCh.Content.Type.IsRegular() {
+			continue
+		}
+		objectURL := listedURL(clnt, contentCh.Content, true, false)
+		objectClnt, err := target2Client(objectURL)
+		if err != nil {
+			return NewIodine(iodine.New(err, map[string]string{"Target": objectURL}))
+		}
+		if err := doLegalHold(objectClnt, operation); err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+	}
+	return nil
+}
+
+// doLegalHold - set, clear or show the legal hold of the object of clnt
+func doLegalHold(clnt client.Client, operation string) error {
+	targetURL := clnt.URL().String()
+	holder, ok := clnt.(client.LegalHolder)
+	if !ok {
+		return NewIodine(iodine.New(errObjectLockNotSupported{URL: targetURL}, nil))
+	}
+	message := LegalHoldMessage{Target: targetURL, Operation: operation}
+	var err error
+	switch operation {
+	case "info":
+		message.Hold, err = holder.GetLegalHold()
+	default:
+		message.Hold = operation == "set"
+		err = holder.SetLegalHold(message.Hold)
+	}
+	if err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	console.Print(message)
+	return nil
+}
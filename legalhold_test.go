@@ -0,0 +1,51 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/mc/pkg/fakes3"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestLegalHold(c *C) {
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+	server.PutObject("bucket", "records/1042.pdf", []byte("1042"))
+	server.PutObject("bucket", "records/1043.pdf", []byte("1043"))
+
+	c.Assert(doLegalHoldCmd(server.URL+"/bucket/records", "set", true), IsNil)
+	for _, path := range []string{"/bucket/records/1042.pdf", "/bucket/records/1043.pdf"} {
+		hold, ok := server.Document(path, "legal-hold")
+		c.Assert(ok, Equals, true)
+		c.Assert(string(hold), Equals, "<LegalHold><Status>ON</Status></LegalHold>")
+	}
+
+	message := LegalHoldMessage{Target: "s3:records/1042.pdf", Operation: "info", Hold: true}
+	c.Assert(message.String(), Equals, "s3:records/1042.pdf  ON\n")
+
+	// holds of missing objects are not found, refused holds are not set
+	exitCode, _ := getErrorCode(doLegalHoldCmd(server.URL+"/bucket/records/1044.pdf", "info", false))
+	c.Assert(exitCode, Equals, exitNotFound)
+	server.AddFault(fakes3.Fault{Method: "PUT", Subresource: "legal-hold", Status: http.StatusBadRequest, Code: "InvalidRequest", Times: 1})
+	err := doLegalHoldCmd(server.URL+"/bucket/records/1042.pdf", "clear", false)
+	c.Assert(s3.ErrorCode(err), Equals, "InvalidRequest")
+	hold, _ := server.Document("/bucket/records/1042.pdf", "legal-hold")
+	c.Assert(string(hold), Equals, "<LegalHold><Status>ON</Status></LegalHold>")
+}
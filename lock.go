@@ -0,0 +1,272 @@
+/*
+ * Minio Client, (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Target locks coordinate multiple mc processes on the same host. A
+// process copying into a target prefix holds a lock file in the session
+// directory, other processes refuse to copy into an overlapping prefix
+// until the lock is released or its owner has died.
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+type targetLock struct {
+	Version string    `json:"version"`
+	PID     int       `json:"pid"`
+	Target  string    `json:"target"`
+	When    time.Time `json:"time"`
+
+	path string
+}
+
+func getLockDir() string {
+	return filepath.Join(getSessionDir(), sessionLockDir)
+}
+
+// getLockFile - each target URL maps to a unique lock file name
+func getLockFile(targetURL string) string {
+	sum := sha1.Sum([]byte(targetURL))
+	return filepath.Join(getLockDir(), hex.EncodeToString(sum[:])+".lock")
+}
+
+// normalizeLockTarget expands aliases, makes local paths absolute and strips recursive and
+// trailing separators so that "play:bucket/dir", "https://play.minio.io:9000/bucket/dir" and
+// "bucket/dir/" of the same alias are treated as the same target, as are "./dir" and "/tmp/dir"
+// run from /tmp.
+func normalizeLockTarget(targetURL string) string {
+	if config, err := getMcConfig(); err == nil {
+		if expandedURL, err := aliasExpand(targetURL, config.Aliases); err == nil {
+			targetURL = expandedURL
+		}
+	}
+	targetURL = stripRecursiveURL(targetURL)
+	if u, err := client.Parse(targetURL); err == nil && u.Type == client.Filesystem {
+		if absURL, err := filepath.Abs(targetURL); err == nil {
+			targetURL = absURL
+		}
+	}
+	return strings.TrimRight(targetURL, "/\\")
+}
+
+// isOverlappingTarget - true if the normalized targets are the same or one is below the other,
+// "bucket/data" overlaps "bucket/data/2015" but not "bucket/database"
+func isOverlappingTarget(first, second string) bool {
+	if len(first) > len(second) {
+		first, second = second, first
+	}
+	if !strings.HasPrefix(second, first) {
+		return false
+	}
+	if len(second) == len(first) {
+		return true
+	}
+	return second[len(first)] == '/' || second[len(first)] == '\\'
+}
+
+// unreadableLockAge - lock files are never seen half written, one which cannot be read is corrupted. It is
+// held until it was not modified for this long
+const unreadableLockAge = time.Minute
+
+func loadTargetLock(path string) (*targetLock, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	lock := &targetLock{}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	lock.path = path
+	return lock, nil
+}
+
+// createTargetLock atomically creates the lock file, fails if it already exists. The lock is written to a
+// temporary file first and linked into place, others never read a partially written lock
+func createTargetLock(targetURL string) (*targetLock, error) {
+	lock := &targetLock{
+		Version: "1.0.0",
+		PID:     os.Getpid(),
+		Target:  targetURL,
+		When:    globalClock.Now().UTC(),
+		path:    getLockFile(targetURL),
+	}
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	file, err := ioutil.TempFile(getLockDir(), "tmp-")
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if err := os.Link(file.Name(), lock.path); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// isStaleLock - the lock file at path with content data may be taken over, its owner is no longer running
+// or it is our own. Locks which cannot be read are stale once they were not modified for unreadableLockAge
+func isStaleLock(path string, data []byte) bool {
+	owner := &targetLock{}
+	if err := json.Unmarshal(data, owner); err == nil {
+		return owner.PID == os.Getpid() || !isProcessRunning(owner.PID)
+	}
+	fi, err := os.Stat(path)
+	return err == nil && time.Since(fi.ModTime()) > unreadableLockAge
+}
+
+// breakStaleLock - remove the stale lock at path whose content was data. It is moved aside first, only one
+// process can do so. A lock which was replaced meanwhile is put back, it is not ours to remove
+func breakStaleLock(path string, data []byte) error {
+	aside := path + "." + strconv.Itoa(os.Getpid()) + ".stale"
+	if err := os.Rename(path, aside); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return NewIodine(iodine.New(err, nil))
+	}
+	defer os.Remove(aside)
+	current, err := ioutil.ReadFile(aside)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	if !bytes.Equal(current, data) {
+		if err := os.Link(aside, path); err != nil && !os.IsExist(err) {
+			return NewIodine(iodine.New(err, nil))
+		}
+	}
+	return nil
+}
+
+// acquireTargetLock takes a lock on targetURL. Locks left behind by
+// processes which are no longer running are removed.
+func acquireTargetLock(targetURL string) (*targetLock, error) {
+	targetURL = normalizeLockTarget(targetURL)
+	if err := os.MkdirAll(getLockDir(), 0700); err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+
+	lockPath := getLockFile(targetURL)
+	lock, err := createTargetLock(targetURL)
+	// a stale lock is broken once, losing the race for it to another process leaves the target locked
+	for attempt := 0; os.IsExist(err) && attempt < 2; attempt++ {
+		data, readErr := ioutil.ReadFile(lockPath)
+		if readErr == nil && !isStaleLock(lockPath, data) {
+			break
+		}
+		if readErr == nil {
+			if err := breakStaleLock(lockPath, data); err != nil {
+				return nil, NewIodine(iodine.New(err, nil))
+			}
+		}
+		lock, err = createTargetLock(targetURL)
+	}
+	if os.IsExist(err) {
+		owner, loadErr := loadTargetLock(lockPath)
+		if loadErr != nil {
+			return nil, NewIodine(iodine.New(errTargetLocked{URL: targetURL}, nil))
+		}
+		return nil, NewIodine(iodine.New(errTargetLocked{URL: owner.Target, PID: owner.PID}, nil))
+	}
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+
+	// Look for live locks on overlapping prefixes.
+	lockFiles, err := filepath.Glob(filepath.Join(getLockDir(), "*.lock"))
+	if err != nil {
+		lock.Release()
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	for _, lockFile := range lockFiles {
+		if lockFile == lock.path {
+			continue
+		}
+		data, err := ioutil.ReadFile(lockFile)
+		if err != nil {
+			continue
+		}
+		other := &targetLock{}
+		if err := json.Unmarshal(data, other); err != nil || other.PID == os.Getpid() {
+			continue
+		}
+		if !isProcessRunning(other.PID) {
+			breakStaleLock(lockFile, data)
+			continue
+		}
+		if isOverlappingTarget(targetURL, other.Target) {
+			lock.Release()
+			return nil, NewIodine(iodine.New(errTargetLocked{URL: other.Target, PID: other.PID}, nil))
+		}
+	}
+	return lock, nil
+}
+
+// acquireTargetLocks takes locks on all targetURLs, all or nothing.
+func acquireTargetLocks(targetURLs []string) ([]*targetLock, error) {
+	var locks []*targetLock
+	for _, targetURL := range targetURLs {
+		lock, err := acquireTargetLock(targetURL)
+		if err != nil {
+			console.Logf(console.LogSession, console.LogWarn, "Unable to lock target ‘%s’. %s\n", targetURL, err)
+			releaseTargetLocks(locks)
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+		console.Logf(console.LogSession, console.LogDebug, "Locked target ‘%s’ with ‘%s’.\n", targetURL, lock.path)
+		locks = append(locks, lock)
+	}
+	return locks, nil
+}
+
+// releaseTargetLocks releases all locks, ignoring errors.
+func releaseTargetLocks(locks []*targetLock) {
+	for _, lock := range locks {
+		lock.Release()
+	}
+}
+
+// Release removes the lock file.
+func (l *targetLock) Release() error {
+	if err := os.Remove(l.path); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	return nil
+}
@@ -27,11 +27,12 @@ var lsCmd = cli.Command{
 	Name:   "ls",
 	Usage:  "List files and folders",
 	Action: runListCmd,
+	Flags:  []cli.Flag{longFlag, tagsFlag, sortFlag, reverseFlag, limitFlag, incompleteFlag, formatFlag, summarizeFlag, versionsFlag, rewindFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc {{.Name}} TARGET [TARGET...] {{if .Description}}
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} TARGET [TARGET...] {{if .Description}}
 
 DESCRIPTION:
    {{.Description}}{{end}}{{if .Flags}}
@@ -67,6 +68,58 @@ EXAMPLES:
       [2015-05-19 17:24:19 PDT]    41B 本語.txt
       [2015-05-19 17:28:22 PDT]    41B 本語.md
 
+   6. List objects on Minio object storage as comma separated values for a spreadsheet.
+      $ mc --output csv {{.Name}} https://play.minio.io:9000/backup/...
+      type,last-modified,size,name
+      file,2015-03-28T19:47:50Z,35651584,2006-Jan-1/backup.tar.gz
+      file,2015-03-31T21:46:33Z,57671680,2006-Mar-1/backup.tar.gz
+
+   7. List objects on Amazon S3 object storage with owner, ETag and storage class.
+      $ mc {{.Name}} --long https://s3.amazonaws.com/jukebox/
+      [2015-05-21 11:24:21 PDT]  22KiB minio        b1946ac92492d2347c6235b4d2611184 STANDARD bach.ogg
+      [2015-05-21 11:25:02 PDT]  31KiB minio        0a4d55a8d778e5022fab701977c5d840 STANDARD mozart.ogg
+
+   8. List objects on Amazon S3 object storage tagged for production hot storage.
+      $ mc {{.Name}} --tags "env=prod&tier=hot" s3:datasets/...
+      [2015-05-21 11:24:21 PDT] 1.2GiB clickstream/2015-05-20.gz
+
+   9. Find the latest backup among millions of objects on Amazon S3 object storage.
+      $ mc {{.Name}} --sort time --limit 1 s3:backup/db/...
+      [2015-06-02 03:00:12 PDT] 4.1GiB 2015-06-02/shop.sql.gz
+
+      Find the smallest backups, which are likely incomplete.
+      $ mc {{.Name}} --sort size --reverse --limit 2 s3:backup/db/...
+      [2015-05-14 03:00:10 PDT]  12KiB 2015-05-14/shop.sql.gz
+      [2015-05-29 03:00:11 PDT] 1.2GiB 2015-05-29/shop.sql.gz
+
+   10. List incomplete uploads on Amazon S3 object storage with the size of their uploaded parts.
+      $ mc {{.Name}} --incomplete s3:backup/...
+      [2015-06-01 23:10:05 PDT] 2.3GiB 2015-06-01/shop.sql.gz
+
+   11. Stream a recursive listing of Amazon S3 object storage to jq, one JSON object per line.
+      $ mc --ndjson {{.Name}} s3:backup/... | jq -r 'select(.type == "file") | .name'
+
+   12. Print size in bytes and name of objects on Amazon S3 object storage for a script.
+      $ mc {{.Name}} --format '{{"{{"}}.Bytes{{"}}"}}\t{{"{{"}}.Name{{"}}"}}' s3:backup/...
+      4398046511	2015-06-02/shop.sql.gz
+
+   13. Count objects and their total size below a prefix on Amazon S3 object storage.
+      $ mc {{.Name}} --summarize s3:backup/db/...
+      [2015-06-01 03:00:09 PDT] 4.0GiB 2015-06-01/shop.sql.gz
+      [2015-06-02 03:00:12 PDT] 4.1GiB 2015-06-02/shop.sql.gz
+
+      Total Objects: 2
+         Total Size: 8.1GiB (8730215081 bytes)
+
+   14. List all versions of an object in a versioned bucket on Amazon S3 object storage, the latest is marked by *.
+      $ mc {{.Name}} --versions s3:backup/db/shop.sql.gz
+      [2015-06-03 09:14:02 PDT]     0B 3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrH DEL* shop.sql.gz
+      [2015-06-02 03:00:12 PDT] 4.1GiB ZbTe.0xrEm8BoC3Ae2nYSJFhOa0ffvfo PUT  shop.sql.gz
+
+   15. List objects of a versioned bucket on Amazon S3 object storage as they were two days ago.
+      $ mc {{.Name}} --rewind 2d s3:backup/db/...
+      [2015-06-02 03:00:12 PDT] 4.1GiB shop.sql.gz
+
 `,
 }
 
@@ -78,13 +131,72 @@ func runListCmd(ctx *cli.Context) {
 			args = []string{"."}
 		}
 	} else if !ctx.Args().Present() || ctx.Args().First() == "help" {
-		cli.ShowCommandHelpAndExit(ctx, "ls", 1) // last argument is exit code
+		cli.ShowCommandHelpAndExit(ctx, "ls", exitUsage) // last argument is exit code
 	}
 
-	if !isMcConfigExists() {
+	if !isMcConfigAvailable() {
 		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
 	}
 	config := mustGetMcConfig()
+	options := listOptions{
+		long:    ctx.Bool("long"),
+		sort:    ctx.String("sort"),
+		reverse: ctx.Bool("reverse"),
+		limit:   ctx.Int("limit"),
+
+		incomplete: ctx.Bool("incomplete"),
+		versions:   ctx.Bool("versions"),
+	}
+	if ctx.String("rewind") != "" {
+		var err error
+		options.rewind, err = parseRewind(ctx.String("rewind"))
+		if err != nil {
+			console.Fatalf("Invalid time ‘%s’ for ‘--rewind’, use a date, RFC3339 timestamp or duration like 7d. %s\n", ctx.String("rewind"), err)
+		}
+	}
+	if ctx.String("tags") != "" {
+		var err error
+		options.tags, err = parseTagFilter(ctx.String("tags"))
+		if err != nil {
+			console.Fatalf("Invalid tag filter ‘%s’, use key=value pairs joined by ‘&’. %s\n", ctx.String("tags"), err)
+		}
+	}
+	if options.incomplete && len(options.tags) > 0 {
+		console.Fatalf("Incomplete uploads carry no tags, --incomplete cannot be used with --tags. %s\n", errInvalidArgument{})
+	}
+	if (options.versions || !options.rewind.IsZero()) && (options.incomplete || len(options.tags) > 0) {
+		console.Fatalf("‘--versions’ and ‘--rewind’ cannot be used with ‘--incomplete’ or ‘--tags’. %s\n", errInvalidArgument{})
+	}
+	if options.versions && !options.rewind.IsZero() {
+		console.Fatalf("‘--versions’ cannot be used with ‘--rewind’. %s\n", errInvalidArgument{})
+	}
+	switch options.sort {
+	case "", sortByName, sortByTime, sortBySize:
+	default:
+		console.Fatalf("Invalid sort order ‘%s’, please choose from [name, time, size]. %s\n", options.sort, errInvalidArgument{})
+	}
+	if options.limit < 0 {
+		console.Fatalf("Limit must not be negative. %s\n", errInvalidArgument{})
+	}
+	if ctx.String("format") != "" {
+		if globalJSONFlag || globalCSVFlag || globalTSVFlag {
+			console.Fatalf("‘--format’ cannot be used with ‘--json’, ‘--csv’ or ‘--tsv’. %s\n", errInvalidArgument{})
+		}
+		var err error
+		options.format, err = parseContentFormat(ctx.String("format"))
+		if err != nil {
+			console.Fatalf("Invalid template ‘%s’ for ‘--format’. %s\n", ctx.String("format"), err)
+		}
+	}
+	if ctx.Bool("summarize") {
+		if globalCSVFlag || globalTSVFlag {
+			console.Fatalf("‘--summarize’ cannot be used with ‘--csv’ or ‘--tsv’. %s\n", errInvalidArgument{})
+		}
+		options.summary = &ListSummaryMessage{}
+	}
+	if header := contentHeader(options.long, options.versions); header != "" {
+		console.Print(header)
+	}
 	for _, arg := range args {
 		targetURL, err := getExpandedURL(arg, config.Aliases)
 		if err != nil {
@@ -97,20 +209,23 @@ func runListCmd(ctx *cli.Context) {
 		}
 		// if recursive strip off the "..."
 		newTargetURL := stripRecursiveURL(targetURL)
-		err = doListCmd(newTargetURL, isURLRecursive(targetURL))
+		err = doListCmd(newTargetURL, isURLRecursive(targetURL), options)
 		if err != nil {
 			console.Fatalf("Failed to list : %s. %s\n", targetURL, err)
 		}
 	}
+	if options.summary != nil {
+		console.Print(*options.summary)
+	}
 }
 
 // doListCmd list files on target
-func doListCmd(targetURL string, recursive bool) error {
+func doListCmd(targetURL string, recursive bool, options listOptions) error {
 	clnt, err := target2Client(targetURL)
 	if err != nil {
 		return NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
 	}
-	err = doList(clnt, recursive)
+	err = doList(clnt, recursive, options)
 	if err != nil {
 		return NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
 	}
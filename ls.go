@@ -17,10 +17,14 @@
 package main
 
 import (
+	"container/heap"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"runtime"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/minio/mc/pkg/client"
@@ -34,10 +38,59 @@ const (
 	printDate = "2006-01-02 15:04:05 MST"
 )
 
-// parseContent parse client Content container into printer struct
-func parseContent(c *client.Content) Content {
+// parseContentFormat - template of --format, ‘\t’ and ‘\n’ are unescaped as shells pass them literally. Unknown
+// fields are reported right away instead of on the first listed entry
+func parseContentFormat(format string) (*template.Template, error) {
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+	tmpl, err := template.New("format").Parse(format)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if err := tmpl.Execute(ioutil.Discard, Content{}); err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return tmpl, nil
+}
+
+// parseRewind - time of ‘--rewind’, a duration like "7d" or "1h30m" ago, or a date or timestamp as for
+// parseSince
+func parseRewind(rewind string) (time.Time, error) {
+	if ago, err := parseRetention(rewind); err == nil {
+		return globalClock.Now().Add(-ago), nil
+	}
+	return parseSince(rewind)
+}
+
+// printContent - print a listed entry in the layout chosen by options, objects are counted in the summary
+func printContent(c *client.Content, options listOptions) {
+	if options.summary != nil && !c.Type.IsDir() && !c.IsDeleteMarker {
+		options.summary.Objects++
+		options.summary.Bytes += c.Size
+	}
+	content := parseContent(c, options.long)
+	content.versions = options.versions
+	content.format = options.format
+	console.Print(content)
+}
+
+// parseContent parse client Content container into printer struct, long adds owner, etag, storage class and metadata
+func parseContent(c *client.Content, long bool) Content {
 	content := Content{}
 	content.Time = c.Time.Local().Format(printDate)
+	content.modTime = c.Time
+	content.bytes = c.Size
+	content.etag = c.ETag
+	content.VersionID = c.VersionID
+	content.IsLatest = c.IsLatest
+	content.IsDeleteMarker = c.IsDeleteMarker
+	if long {
+		content.long = true
+		content.Owner = c.Owner
+		content.OwnerID = c.OwnerID
+		content.ETag = c.ETag
+		content.StorageClass = c.StorageClass
+		content.Metadata = c.Metadata
+	}
 
 	// guess file type
 	content.Filetype = func() string {
@@ -71,28 +124,190 @@ func parseContent(c *client.Content) Content {
 	return content
 }
 
-// doList - list all entities inside a folder
-func doList(clnt client.Client, recursive bool) error {
+// Supported values of ls --sort
+const (
+	sortByName = "name" // names in byte order
+	sortByTime = "time" // most recently modified first
+	sortBySize = "size" // largest first
+)
+
+// listOptions - how doList filters, orders and prints a listing
+type listOptions struct {
+	long    bool              // print owner, ETag and storage class
+	tags    map[string]string // list only objects carrying all of them
+	sort    string            // one of sortByName, sortByTime or sortBySize, listing order if empty
+	reverse bool              // reverse the order of sort, names if sort is empty
+	limit   int               // print at most this many entries, 0 for all
+
+	format  *template.Template  // print entries with this template instead of the default layout
+	summary *ListSummaryMessage // totals of printed objects, nil if not summarized
+
+	incomplete bool      // list incomplete uploads instead of objects
+	versions   bool      // list all versions of objects
+	rewind     time.Time // list objects as they were at this time if set
+}
+
+// isSorted - entries are buffered and ordered before printing instead of printed as listed
+func (o listOptions) isSorted() bool {
+	return o.sort != "" || o.reverse
+}
+
+// contentOrder - reports whether a is printed before b in the order of sort, ties are broken by name
+func contentOrder(sort string, reverse bool) func(a, b *client.Content) bool {
+	order := func(a, b *client.Content) bool {
+		switch {
+		case sort == sortByTime && !a.Time.Equal(b.Time):
+			return a.Time.After(b.Time)
+		case sort == sortBySize && a.Size != b.Size:
+			return a.Size > b.Size
+		case a.Name == b.Name: // versions of an object, newest first
+			return a.Time.After(b.Time)
+		}
+		return a.Name < b.Name
+	}
+	if reverse {
+		return func(a, b *client.Content) bool { return order(b, a) }
+	}
+	return order
+}
+
+// contentHeap - heap of listed contents, the one printed last is on top
+type contentHeap struct {
+	contents []*client.Content
+	before   func(a, b *client.Content) bool
+}
+
+func (h contentHeap) Len() int            { return len(h.contents) }
+func (h contentHeap) Less(i, j int) bool  { return h.before(h.contents[j], h.contents[i]) }
+func (h contentHeap) Swap(i, j int)       { h.contents[i], h.contents[j] = h.contents[j], h.contents[i] }
+func (h *contentHeap) Push(x interface{}) { h.contents = append(h.contents, x.(*client.Content)) }
+func (h *contentHeap) Pop() interface{} {
+	content := h.contents[len(h.contents)-1]
+	h.contents = h.contents[:len(h.contents)-1]
+	return content
+}
+
+// topContents - keeps the limit contents pushed to it printed first in an order, all of them if limit is 0.
+// Memory is bounded by limit however long the listing is
+type topContents struct {
+	limit    int
+	contents contentHeap
+}
+
+// newTopContents - keeps the first limit contents in the order of before
+func newTopContents(limit int, before func(a, b *client.Content) bool) *topContents {
+	return &topContents{limit: limit, contents: contentHeap{before: before}}
+}
+
+func (t *topContents) push(content *client.Content) {
+	heap.Push(&t.contents, content)
+	if t.limit > 0 && t.contents.Len() > t.limit {
+		heap.Pop(&t.contents)
+	}
+}
+
+// sorted - kept contents in order
+func (t *topContents) sorted() []*client.Content {
+	sorted := make([]*client.Content, t.contents.Len())
+	for i := len(sorted) - 1; i >= 0; i-- {
+		sorted[i] = heap.Pop(&t.contents).(*client.Content)
+	}
+	return sorted
+}
+
+// doList - list all entities inside a folder, only objects carrying all tags are listed if tags is set
+func doList(clnt client.Client, recursive bool, options listOptions) error {
 	var err error
-	for contentCh := range clnt.List(recursive) {
+	var isDir bool
+	if len(options.tags) > 0 {
+		content, err := clnt.Stat()
+		if err != nil {
+			return NewIodine(iodine.New(err, map[string]string{"Target": clnt.URL().String()}))
+		}
+		isDir = content.Type.IsDir()
+	}
+	contents := clnt.List
+	if options.limit > 0 && !options.isSorted() && len(options.tags) == 0 {
+		// servers stop listing after the entries printed
+		contents = func(recursive bool) <-chan client.ContentOnChannel {
+			listOptions := client.ListOptions{MaxKeys: options.limit}
+			if !recursive {
+				listOptions.Delimiter = string(clnt.URL().Separator)
+			}
+			return client.ListWithOptions(clnt, listOptions)
+		}
+	}
+	if options.incomplete {
+		lister, ok := clnt.(client.IncompleteLister)
+		if !ok {
+			return NewIodine(iodine.New(errIncompleteNotSupported{URL: clnt.URL().String()}, nil))
+		}
+		contents = lister.ListIncomplete
+	}
+	if options.versions {
+		lister, ok := clnt.(client.VersionLister)
+		if !ok {
+			return NewIodine(iodine.New(errVersionsNotSupported{URL: clnt.URL().String()}, nil))
+		}
+		contents = lister.ListVersions
+	}
+	if !options.rewind.IsZero() {
+		lister, ok := clnt.(client.VersionLister)
+		if !ok {
+			return NewIodine(iodine.New(errVersionsNotSupported{URL: clnt.URL().String()}, nil))
+		}
+		contents = func(recursive bool) <-chan client.ContentOnChannel {
+			return client.ListAt(lister, recursive, options.rewind)
+		}
+	}
+	top := newTopContents(options.limit, contentOrder(options.sort, options.reverse))
+	printed := 0
+	for contentCh := range contents(recursive) {
 		if contentCh.Err != nil {
 			switch err := iodine.ToError(contentCh.Err).(type) {
 			// handle this specifically for filesystem
 			case client.ISBrokenSymlink:
-				console.Errorln(NewIodine(iodine.New(err, nil)).Error())
+				console.Errorln(NewIodine(iodine.New(err, nil)))
 				continue
 			}
 			if os.IsNotExist(iodine.ToError(contentCh.Err)) || os.IsPermission(iodine.ToError(contentCh.Err)) {
-				console.Errorln(NewIodine(iodine.New(contentCh.Err, nil)).Error())
+				console.Errorln(NewIodine(iodine.New(contentCh.Err, nil)))
 				continue
 			}
 			err = contentCh.Err
 			break
 		}
-		console.Print(parseContent(contentCh.Content))
+		if len(options.tags) > 0 {
+			if !contentCh.Content.Type.IsRegular() {
+				continue
+			}
+			var match bool
+			match, err = matchTags(listedURL(clnt, contentCh.Content, recursive, isDir), options.tags)
+			if err != nil {
+				break
+			}
+			if !match {
+				continue
+			}
+		}
+		if options.isSorted() {
+			// folders of recursive listings would hide the objects in them
+			if !recursive || !contentCh.Content.Type.IsDir() {
+				top.push(contentCh.Content)
+			}
+			continue
+		}
+		printContent(contentCh.Content, options)
+		printed++
+		if options.limit > 0 && printed == options.limit {
+			break
+		}
 	}
 	if err != nil {
 		return NewIodine(iodine.New(err, map[string]string{"Target": clnt.URL().String()}))
 	}
+	for _, content := range top.sorted() {
+		printContent(content, options)
+	}
 	return nil
 }
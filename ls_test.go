@@ -22,7 +22,10 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/minio/mc/pkg/client"
 	. "gopkg.in/check.v1"
 )
 
@@ -40,10 +43,10 @@ func (s *CmdTestSuite) TestLSCmd(c *C) {
 		c.Assert(err, IsNil)
 	}
 
-	err = doListCmd(root, false)
+	err = doListCmd(root, false, listOptions{})
 	c.Assert(err, IsNil)
 
-	err = doListCmd(root, true)
+	err = doListCmd(root, true, listOptions{long: true})
 	c.Assert(err, IsNil)
 
 	for i := 0; i < 10; i++ {
@@ -53,10 +56,199 @@ func (s *CmdTestSuite) TestLSCmd(c *C) {
 		err := putTarget(objectPath, int64(dataLen), bytes.NewReader([]byte(data)))
 		c.Assert(err, IsNil)
 	}
-	err = doListCmd(server.URL+"/bucket", false)
+	err = doListCmd(server.URL+"/bucket", false, listOptions{long: true})
 	c.Assert(err, IsNil)
 
-	err = doListCmd(server.URL+"/bucket", true)
+	err = doListCmd(server.URL+"/bucket", true, listOptions{})
 	c.Assert(err, IsNil)
 
 }
+
+func (s *CmdTestSuite) TestLSSeparatedValues(c *C) {
+	globalCSVFlag = true
+	defer func() { globalCSVFlag = false }()
+
+	content := parseContent(&client.Content{
+		Name: "backup, 2015.tar.gz",
+		Time: time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC),
+		Size: 1024,
+		ETag: "b1946ac92492d2347c6235b4d2611184",
+	}, false)
+	c.Assert(contentHeader(false, false), Equals, "type,last-modified,size,etag,name\n")
+	c.Assert(content.String(), Equals, "file,2015-06-01T12:00:00Z,1024,b1946ac92492d2347c6235b4d2611184,\"backup, 2015.tar.gz\"\n")
+
+	globalCSVFlag = false
+	globalTSVFlag = true
+	defer func() { globalTSVFlag = false }()
+	c.Assert(contentHeader(false, false), Equals, "type\tlast-modified\tsize\tetag\tname\n")
+	c.Assert(content.String(), Equals, "file\t2015-06-01T12:00:00Z\t1024\tb1946ac92492d2347c6235b4d2611184\tbackup, 2015.tar.gz\n")
+}
+
+func (s *CmdTestSuite) TestOutputFlag(c *C) {
+	defer func() { globalCSVFlag, globalTSVFlag = false, false }()
+
+	c.Assert(setOutputFlag("text"), Equals, true)
+	c.Assert(isValidOutputFlags(), Equals, true)
+	c.Assert(setOutputFlag("csv"), Equals, true)
+	c.Assert(globalCSVFlag, Equals, true)
+	c.Assert(isValidOutputFlags(), Equals, true)
+	c.Assert(setOutputFlag("tsv"), Equals, true)
+	c.Assert(isValidOutputFlags(), Equals, false) // --output tsv with --csv
+	c.Assert(setOutputFlag("xml"), Equals, false)
+}
+
+func (s *CmdTestSuite) TestLSNDJSON(c *C) {
+	content := parseContent(&client.Content{
+		Name: "backup.tar.gz",
+		Time: time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC),
+		Size: 1024,
+	}, false)
+	indented, err := marshalJSON(content, false)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Count(string(indented), "\n") > 1, Equals, true)
+
+	compact, err := marshalJSON(content, true)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Count(string(compact), "\n"), Equals, 0)
+	c.Assert(strings.Contains(string(compact), `"type":"file",`), Equals, true)
+	c.Assert(strings.Contains(string(compact), `"name":"backup.tar.gz"}`), Equals, true)
+}
+
+func (s *CmdTestSuite) TestLSFormat(c *C) {
+	format, err := parseContentFormat(`{{.Bytes}}\t{{.Name}} {{.ModTime.Year}}`)
+	c.Assert(err, IsNil)
+	content := parseContent(&client.Content{
+		Name: "backup.tar.gz",
+		Time: time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC),
+		Size: 1024,
+	}, false)
+	content.format = format
+	c.Assert(content.String(), Equals, "1024\tbackup.tar.gz 2015\n")
+
+	_, err = parseContentFormat("{{.Checksum}}")
+	c.Assert(err, NotNil)
+	_, err = parseContentFormat("{{.Name")
+	c.Assert(err, NotNil)
+}
+
+func (s *CmdTestSuite) TestLSSummarize(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	for i, data := range []string{"hello", "hello world"} {
+		err = putTarget(filepath.Join(root, "backup", "object"+strconv.Itoa(i)), int64(len(data)), bytes.NewReader([]byte(data)))
+		c.Assert(err, IsNil)
+	}
+	summary := &ListSummaryMessage{}
+	err = doListCmd(root, false, listOptions{summary: summary})
+	c.Assert(err, IsNil)
+	c.Assert(*summary, Equals, ListSummaryMessage{}) // folders are not counted
+
+	err = doListCmd(root, true, listOptions{summary: summary})
+	c.Assert(err, IsNil)
+	c.Assert(*summary, Equals, ListSummaryMessage{Objects: 2, Bytes: 16})
+	c.Assert(summary.String(), Equals, "\nTotal Objects: 2\n   Total Size: 16B (16 bytes)\n")
+}
+
+func (s *CmdTestSuite) TestLSVersions(c *C) {
+	clientContent := &client.Content{
+		Name:           "shop.sql.gz",
+		Time:           time.Date(2015, 6, 3, 16, 14, 2, 0, time.UTC),
+		VersionID:      "3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrH",
+		IsLatest:       true,
+		IsDeleteMarker: true,
+	}
+	content := parseContent(clientContent, false)
+	c.Assert(strings.Contains(content.String(), "3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrH"), Equals, false)
+	content.versions = true
+	c.Assert(strings.HasSuffix(content.String(), "3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrH DEL* shop.sql.gz\n"), Equals, true)
+
+	globalCSVFlag = true
+	defer func() { globalCSVFlag = false }()
+	c.Assert(contentHeader(false, true), Equals, "type,last-modified,size,etag,version-id,is-latest,is-delete-marker,name\n")
+	c.Assert(content.String(), Equals, "file,2015-06-03T16:14:02Z,0,,3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrH,true,true,shop.sql.gz\n")
+
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	err = doListCmd(root, false, listOptions{versions: true})
+	c.Assert(err, NotNil) // filesystems keep no versions
+
+	now := time.Date(2015, 6, 3, 12, 0, 0, 0, time.UTC)
+	globalClock = fixedClock(now)
+	defer func() { globalClock = systemClock{} }()
+	rewind, err := parseRewind("2d")
+	c.Assert(err, IsNil)
+	c.Assert(rewind.Equal(now.Add(-48*time.Hour)), Equals, true)
+	rewind, err = parseRewind("2015-06-01T00:00:00Z")
+	c.Assert(err, IsNil)
+	c.Assert(rewind.Equal(time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC)), Equals, true)
+	_, err = parseRewind("yesterday")
+	c.Assert(err, NotNil)
+}
+
+func (s *CmdTestSuite) TestLSLong(c *C) {
+	clientContent := &client.Content{
+		Name:         "backup.tar.gz",
+		Time:         time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC),
+		Size:         1024,
+		ETag:         "b1946ac92492d2347c6235b4d2611184",
+		OwnerID:      "minio",
+		StorageClass: "STANDARD",
+	}
+	content := parseContent(clientContent, false)
+	c.Assert(content.ETag, Equals, "")
+	c.Assert(strings.Contains(content.String(), "STANDARD"), Equals, false)
+
+	content = parseContent(clientContent, true)
+	c.Assert(strings.Contains(content.String(), "minio"), Equals, true) // owner id when display name is missing
+	c.Assert(strings.Contains(content.String(), "b1946ac92492d2347c6235b4d2611184 STANDARD"), Equals, true)
+
+	globalCSVFlag = true
+	defer func() { globalCSVFlag = false }()
+	c.Assert(contentHeader(true, false), Equals, "type,last-modified,size,etag,owner,storage-class,name\n")
+	c.Assert(content.String(), Equals, "file,2015-06-01T12:00:00Z,1024,b1946ac92492d2347c6235b4d2611184,,STANDARD,backup.tar.gz\n")
+}
+
+func (s *CmdTestSuite) TestTopContents(c *C) {
+	base := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	newest := newTopContents(3, contentOrder(sortByTime, false))
+	for i, hours := range []int{5, 1, 9, 3, 7, 9} {
+		newest.push(&client.Content{Name: "backup" + strconv.Itoa(i), Time: base.Add(time.Duration(hours) * time.Hour)})
+	}
+	var names []string
+	for _, content := range newest.sorted() {
+		names = append(names, content.Name)
+	}
+	c.Assert(names, DeepEquals, []string{"backup2", "backup5", "backup4"})
+
+	all := newTopContents(0, contentOrder(sortByTime, false))
+	for i, hours := range []int{2, 4, 1} {
+		all.push(&client.Content{Name: "backup" + strconv.Itoa(i), Time: base.Add(time.Duration(hours) * time.Hour)})
+	}
+	c.Assert(len(all.sorted()), Equals, 3)
+
+	contents := []*client.Content{{Name: "b", Size: 5}, {Name: "c", Size: 1}, {Name: "a", Size: 5}}
+	for _, order := range []struct {
+		sort    string
+		reverse bool
+		names   []string
+	}{
+		{sortByName, false, []string{"a", "b", "c"}},
+		{sortByName, true, []string{"c", "b", "a"}},
+		{sortBySize, false, []string{"a", "b", "c"}},
+		{sortBySize, true, []string{"c", "b", "a"}},
+		{"", true, []string{"c", "b", "a"}},
+	} {
+		top := newTopContents(0, contentOrder(order.sort, order.reverse))
+		for _, content := range contents {
+			top.push(content)
+		}
+		names = nil
+		for _, content := range top.sorted() {
+			names = append(names, content.Name)
+		}
+		c.Assert(names, DeepEquals, order.names)
+	}
+}
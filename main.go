@@ -22,9 +22,13 @@ import (
 	"os/user"
 	"runtime"
 	"strconv"
+	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client/s3"
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
 	"github.com/minio/pb"
 )
 
@@ -41,6 +45,10 @@ func checkConfig() {
 		}
 	}
 
+	// Migrate any old version of config / state files to newer format, the config folder may be
+	// set by a flag so this can not run earlier
+	migrate()
+
 	// If config doesn't exist, do not attempt to read it
 	if !isMcConfigExists() {
 		return
@@ -81,35 +89,75 @@ func getSystemData() map[string]string {
 func migrate() {
 	// Migrate session files if any.
 	migrateSession()
+	// Migrate config file if needed.
+	migrateConfig()
 }
 
 func main() {
-	// Migrate any old version of config / state files to newer format.
-	migrate()
-
 	// Enable GOMAXPROCS to default to number of CPUs.
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
 	// Register all the commands
-	registerCmd(lsCmd)      // List contents of a bucket
-	registerCmd(mbCmd)      // make a bucket
-	registerCmd(catCmd)     // concantenate an object to standard output
-	registerCmd(cpCmd)      // copy objects and files from multiple sources to single destination
-	registerCmd(castCmd)    // cast objects and files from single source to multiple destinations
-	registerCmd(sessionCmd) // session handling for resuming copy and cast operations
-	registerCmd(diffCmd)    // compare two objects
-	registerCmd(accessCmd)  // set permissions [public, private, readonly, authenticated] for buckets and folders.
-	registerCmd(configCmd)  // generate configuration "/home/harsha/.mc/config.json" file.
-	registerCmd(updateCmd)  // update Check for new software updates
+	registerCmd(lsCmd)             // List contents of a bucket
+	registerCmd(mbCmd)             // make a bucket
+	registerCmd(rmCmd)             // remove objects, files or incomplete uploads
+	registerCmd(rbCmd)             // remove buckets, emptying them first if forced
+	registerCmd(undeleteCmd)       // restore removed objects of versioned buckets
+	registerCmd(versionCmd)        // enable, suspend or show versioning of buckets
+	registerCmd(tagCmd)            // set, list or remove tags of objects and buckets
+	registerCmd(legalHoldCmd)      // set, clear or show legal holds of objects
+	registerCmd(ilmCmd)            // manage lifecycle rules of buckets
+	registerCmd(encryptCmd)        // set, clear or show default encryption of buckets
+	registerCmd(policyCmd)         // manage anonymous access to buckets and prefixes
+	registerCmd(aclCmd)            // show access control lists of buckets and objects
+	registerCmd(eventCmd)          // manage notifications of bucket events
+	registerCmd(catCmd)            // concantenate an object to standard output
+	registerCmd(cpCmd)             // copy objects and files from multiple sources to single destination
+	registerCmd(castCmd)           // cast objects and files from single source to multiple destinations
+	registerCmd(sessionCmd)        // session handling for resuming copy and cast operations
+	registerCmd(diffCmd)           // compare two objects
+	registerCmd(accessCmd)         // set permissions [public, private, readonly, authenticated] for buckets and folders.
+	registerCmd(configCmd)         // generate configuration "/home/harsha/.mc/config.json" file.
+	registerCmd(updateCmd)         // update Check for new software updates
+	registerCmd(verifyEndpointCmd) // soak test an endpoint with verified operations
+	registerCmd(exportCmd)         // export a folder or prefix into a tar archive
+	registerCmd(importCmd)         // import members of a tar or zip archive as objects
+	registerCmd(verifyMirrorCmd)   // compare a sample of objects with their mirror and alert on drift
+	registerCmd(watchCmd)          // print changes of files as they happen
+	registerCmd(verifyManifestCmd) // check a target against a signed transfer manifest
+	registerCmd(grepCmd)           // search object contents for a pattern
+	registerCmd(adminCmd)          // status of Minio servers
+	registerCmd(registryCmd)       // commands, flags and arguments as JSON for wrapper tools
+	registerCmd(docsCmd)           // man pages for packaging
 
 	// register all the flags
-	registerFlag(configFlag) // path to config folder
-	registerFlag(quietFlag)  // suppress console output
-	registerFlag(forceFlag)  // force copying data
-	registerFlag(aliasFlag)  // OS toolchain mimic
-	registerFlag(themeFlag)  // console theme flag
-	registerFlag(jsonFlag)   // json formatted output
-	registerFlag(debugFlag)  // enable debugging output
+	registerFlag(configFlag)         // path to config folder
+	registerFlag(quietFlag)          // suppress console output
+	registerFlag(forceFlag)          // force copying data
+	registerFlag(aliasFlag)          // OS toolchain mimic
+	registerFlag(themeFlag)          // console theme flag
+	registerFlag(noColorFlag)        // disable colors
+	registerFlag(jsonFlag)           // json formatted output
+	registerFlag(ndjsonFlag)         // newline delimited json output
+	registerFlag(outputFlag)         // output format by name
+	registerFlag(csvFlag)            // csv formatted output
+	registerFlag(tsvFlag)            // tsv formatted output
+	registerFlag(debugFlag)          // enable debugging output
+	registerFlag(debugBodyFlag)      // bodies in HTTP traces
+	registerFlag(capabilitiesFlag)   // machine readable build capabilities
+	registerFlag(lookupFlag)         // bucket addressing for S3 API
+	registerFlag(logLevelFlag)       // diagnostic messages level
+	registerFlag(logComponentFlag)   // diagnostic messages components
+	registerFlag(logFileFlag)        // log file for automated runs
+	registerFlag(auditFlag)          // audit trail of requests
+	registerFlag(localFlag)          // arguments are local paths
+	registerFlag(maxIdleConnsFlag)   // idle connections per host
+	registerFlag(requestTimeoutFlag) // wait for replies
+	registerFlag(keepAliveFlag)      // TCP keep-alive period
+	registerFlag(queueMemoryFlag)    // memory for planned work
+
+	// Exit codes and JSON errors tell failure causes apart for scripts
+	console.SetErrorCoder(getErrorCode)
 
 	app := cli.NewApp()
 	app.Usage = "Minio Client for object storage and filesystems"
@@ -119,6 +167,14 @@ func main() {
 	app.Flags = flags
 	app.Author = "Minio.io"
 	app.Before = func(ctx *cli.Context) error {
+		// Plain JSON on stdout without theme, wrapper tools call it before anything else
+		if ctx.GlobalBool("capabilities") {
+			fmt.Println(getCapabilities())
+			os.Exit(0)
+		}
+		if ctx.GlobalBool("no-color") {
+			console.DisableColor()
+		}
 		if ctx.GlobalString("config") != "" {
 			setMcConfigDir(ctx.GlobalString("config"))
 		}
@@ -126,13 +182,94 @@ func main() {
 		globalQuietFlag = ctx.GlobalBool("quiet")
 		globalForceFlag = ctx.GlobalBool("force")
 		globalAliasFlag = ctx.GlobalBool("alias")
-		globalDebugFlag = ctx.GlobalBool("debug")
+		debug := ctx.GlobalGeneric("debug").(*debugValue)
+		globalDebugFlag = debug.enabled
 		globalJSONFlag = ctx.GlobalBool("json")
+		globalNDJSONFlag = ctx.GlobalBool("ndjson")
+		globalCSVFlag = ctx.GlobalBool("csv")
+		globalTSVFlag = ctx.GlobalBool("tsv")
+		if !setOutputFlag(ctx.GlobalString("output")) {
+			console.Fatalf("Invalid ‘--output’ value ‘%s’, please choose from [text, json, ndjson, csv, tsv]. %s\n", ctx.GlobalString("output"), errInvalidArgument{})
+		}
+		globalLocalFlag = ctx.GlobalBool("local")
+		globalMaxIdleConns = ctx.GlobalInt("max-idle-conns")
+		globalRequestTimeout = ctx.GlobalDuration("request-timeout")
+		globalKeepAlive = ctx.GlobalDuration("keep-alive")
+		if globalMaxIdleConns < 0 || globalRequestTimeout < 0 || globalKeepAlive <= 0 {
+			console.Fatalf("Connection settings must not be negative and ‘--keep-alive’ must be positive. %s\n", errInvalidArgument{})
+		}
+		queueMemory, err := humanize.ParseBytes(ctx.GlobalString("queue-memory"))
+		if err != nil {
+			console.Fatalf("Invalid size ‘%s’ for ‘--queue-memory’, e.g. 1GiB. %s\n", ctx.GlobalString("queue-memory"), errInvalidArgument{})
+		}
+		globalQueueMemory = queueMemory
+		if !isValidOutputFlags() {
+			console.Fatalf("Only one output format of ‘--output’, ‘--json’, ‘--ndjson’, ‘--csv’ or ‘--tsv’ may be specified. %s\n", errInvalidArgument{})
+		}
+		globalJSONFlag = globalJSONFlag || globalNDJSONFlag
+		console.SetJSONErrors(globalJSONFlag)
+		if ctx.GlobalString("log-level") != "" {
+			level, err := console.ParseLogLevel(ctx.GlobalString("log-level"))
+			if err != nil {
+				console.Fatalf("Invalid ‘--log-level’ value ‘%s’, please choose from [debug, info, warn, error]. %s\n", ctx.GlobalString("log-level"), errInvalidArgument{})
+			}
+			console.SetLogLevel(level)
+		}
+		if ctx.GlobalString("log-file") != "" {
+			logFile, err := console.OpenLogFile(ctx.GlobalString("log-file"))
+			if err != nil {
+				console.Fatalf("Unable to open log file ‘%s’. %s\n", ctx.GlobalString("log-file"), NewIodine(iodine.New(err, nil)))
+			}
+			console.SetLogWriter(logFile)
+			if ctx.GlobalString("log-level") == "" { // log files are for operation records first of all
+				console.SetLogLevel(console.LogInfo)
+			}
+		}
+		if ctx.GlobalString("audit") != "" {
+			auditFile, err := console.OpenLogFile(ctx.GlobalString("audit"))
+			if err != nil {
+				console.Fatalf("Unable to open audit file ‘%s’. %s\n", ctx.GlobalString("audit"), NewIodine(iodine.New(err, nil)))
+			}
+			console.SetAuditWriter(auditFile, ctx.Args().First())
+		}
+		if ctx.GlobalString("log-component") != "" {
+			if err := console.SetLogComponents(strings.Split(ctx.GlobalString("log-component"), ",")); err != nil {
+				console.Fatalf("Invalid ‘--log-component’ value ‘%s’, please choose from %s. %s\n", ctx.GlobalString("log-component"), console.LogComponents, errInvalidArgument{})
+			}
+		}
+		globalLookup = ctx.GlobalString("lookup")
+		if !s3.IsValidLookup(globalLookup) {
+			console.Fatalf("Invalid ‘--lookup’ value ‘%s’, please choose from [auto, dns, path]. %s\n", globalLookup, errInvalidArgument{})
+		}
 		if globalDebugFlag {
 			app.ExtraInfo = getSystemData()
 			console.NoDebugPrint = false
+			if debug.file != "" {
+				traceFile, err := console.OpenLogFile(debug.file)
+				if err != nil {
+					console.Fatalf("Unable to open debug file ‘%s’. %s\n", debug.file, NewIodine(iodine.New(err, nil)))
+				}
+				console.SetDebugWriter(traceFile)
+			}
+			s3.SetTraceBodies(ctx.GlobalBool("debug-body"))
 		}
+		checkConfig()
 		themeName := ctx.GlobalString("theme")
+		if isMcConfigExists() {
+			conf, err := getMcConfig()
+			if err != nil {
+				console.Fatalf("Unable to read config file. %s\n", err)
+			}
+			if err := registerConfigThemes(conf); err != nil {
+				console.Fatalf("Invalid theme in config file. %s\n", err)
+			}
+			if themeName == "" {
+				themeName = getConfigThemeName(conf)
+			}
+		}
+		if themeName == "" {
+			themeName = console.GetDefaultThemeName()
+		}
 		switch {
 		case console.IsValidTheme(themeName) != true:
 			console.Errorf("Invalid theme, please choose from the following list: %s.\n", console.GetThemeNames())
@@ -144,11 +281,10 @@ func main() {
 				return err
 			}
 		}
-		checkConfig()
 		return nil
 	}
 	app.After = func(ctx *cli.Context) error {
-		if !isMcConfigExists() {
+		if !isMcConfigAvailable() {
 			console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
 		}
 		return nil
@@ -173,5 +309,7 @@ VERSION:
   {{$value}}
 {{end}}
 `
+	// standard input or output as "-" is an argument, not the start of the flags
+	os.Args = stdioArgs(os.Args, commands)
 	app.RunAndExitOnError()
 }
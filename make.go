@@ -24,12 +24,14 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"text/template"
 	"time"
 )
 
 type Version struct {
-	Date string
+	Date     string
+	CommitID string
 }
 
 func writeVersion(version Version) error {
@@ -46,6 +48,9 @@ import (
 // Version autogenerated
 var Version = {{if .Date}}"{{.Date}}"{{else}}""{{end}}
 
+// CommitID autogenerated
+var CommitID = "{{.CommitID}}"
+
 // getVersion -
 func getVersion() string {
 	t, _ := time.Parse(time.RFC3339Nano, Version)
@@ -112,8 +117,13 @@ func runMcInstall() {
 func runMcRelease() {
 	t := time.Now().UTC()
 	date := t.Format(time.RFC3339Nano)
-	version := Version{Date: date}
-	err := writeVersion(version)
+	commitID, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		fmt.Print(err)
+		os.Exit(1)
+	}
+	version := Version{Date: date, CommitID: strings.TrimSpace(string(commitID))}
+	err = writeVersion(version)
 	if err != nil {
 		fmt.Print(err)
 		os.Exit(1)
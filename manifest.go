@@ -0,0 +1,276 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// manifestKeyFile - ed25519 private key signing transfer manifests, kept in the mc config directory
+const manifestKeyFile = "manifest.key"
+
+// driftChecksum - reason of a target object whose content no longer matches its manifest entry
+const driftChecksum = "checksum"
+
+// manifestObject - an object written by a transfer, name is relative to the manifest target
+type manifestObject struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// transferManifest - objects and checksums of a transfer, signed with the local manifest key
+type transferManifest struct {
+	Version   string           `json:"version"`
+	Target    string           `json:"target"`
+	Created   time.Time        `json:"created"`
+	Objects   []manifestObject `json:"objects"`
+	PublicKey string           `json:"publicKey"`
+	Signature string           `json:"signature"`
+}
+
+// getManifestKeyPath - path of the manifest signing key
+func getManifestKeyPath() (string, error) {
+	configDir, err := getMcConfigDir()
+	if err != nil {
+		return "", NewIodine(iodine.New(err, nil))
+	}
+	return filepath.Join(configDir, manifestKeyFile), nil
+}
+
+// loadManifestKey - read the manifest signing key, a new key is generated if create is set and none exists
+func loadManifestKey(create bool) (ed25519.PrivateKey, error) {
+	keyPath, err := getManifestKeyPath()
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	data, err := ioutil.ReadFile(keyPath)
+	if os.IsNotExist(err) && create {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+		data = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		if err := ioutil.WriteFile(keyPath, data, 0600); err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+		return privateKey, nil
+	}
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, NewIodine(iodine.New(errInvalidManifestKey{Path: keyPath}, nil))
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, NewIodine(iodine.New(errInvalidManifestKey{Path: keyPath}, nil))
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, NewIodine(iodine.New(errInvalidManifestKey{Path: keyPath}, nil))
+	}
+	return privateKey, nil
+}
+
+// signedBytes - manifest content covered by the signature
+func (m transferManifest) signedBytes() ([]byte, error) {
+	m.Signature = ""
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return data, nil
+}
+
+// sign - sign manifest with privateKey, its public key is recorded to identify the signer
+func (m *transferManifest) sign(privateKey ed25519.PrivateKey) error {
+	m.PublicKey = base64.StdEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey))
+	data, err := m.signedBytes()
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	m.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, data))
+	return nil
+}
+
+// verifySignature - manifest is unchanged since it was signed with the private key of publicKey
+func (m transferManifest) verifySignature(publicKey ed25519.PublicKey) error {
+	if m.PublicKey != base64.StdEncoding.EncodeToString(publicKey) {
+		return NewIodine(iodine.New(errManifestSignature{}, nil))
+	}
+	signature, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return NewIodine(iodine.New(errManifestSignature{}, nil))
+	}
+	data, err := m.signedBytes()
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	if !ed25519.Verify(publicKey, data, signature) {
+		return NewIodine(iodine.New(errManifestSignature{}, nil))
+	}
+	return nil
+}
+
+// hashObject - size and hex sha256 of an object
+func hashObject(objectURL string) (int64, string, error) {
+	reader, _, err := getSource(objectURL)
+	if err != nil {
+		return 0, "", NewIodine(iodine.New(err, nil))
+	}
+	defer reader.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, reader)
+	if err != nil {
+		return 0, "", NewIodine(iodine.New(err, nil))
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// manifestObjectURL - URL of a manifest object under targetURL
+func manifestObjectURL(targetURL, name string) (string, error) {
+	if name == "" { // single object transfers
+		return targetURL, nil
+	}
+	return urlJoinPath(targetURL, name)
+}
+
+// newCopyManifest - checksums of all objects a cp session wrote, taken from the targets after the copy
+func newCopyManifest(session *sessionV2) (*transferManifest, error) {
+	targetURL := session.Header.CommandArgs[len(session.Header.CommandArgs)-1]
+	manifest := &transferManifest{
+		Version: "1.0.0",
+		Target:  targetURL,
+		Created: globalClock.Now().UTC(),
+		Objects: []manifestObject{},
+	}
+	separator := "/"
+	if u, err := client.Parse(targetURL); err == nil {
+		separator = string(u.Separator)
+		// relative folders are checked later from other working directories
+		if u.Type == client.Filesystem && !filepath.IsAbs(targetURL) {
+			manifest.Target = filepath.Join(session.Header.RootPath, targetURL)
+		}
+	}
+	scanner := session.NewDataScanner()
+	for scanner.Scan() {
+		var cpURLs copyURLs
+		if err := json.Unmarshal([]byte(scanner.Text()), &cpURLs); err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+		objectURL := cpURLs.TargetContent.Name
+		size, sum, err := hashObject(objectURL)
+		if err != nil { // failed copies are already reported, they are not part of the transfer
+			console.Errorln(NewIodine(iodine.New(err, map[string]string{"URL": objectURL})))
+			continue
+		}
+		name := strings.TrimLeft(strings.TrimPrefix(objectURL, targetURL), separator)
+		manifest.Objects = append(manifest.Objects, manifestObject{Name: name, Size: size, SHA256: sum})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return manifest, nil
+}
+
+// saveManifest - sign manifest with the local manifest key and write it to manifestPath
+func saveManifest(manifest *transferManifest, manifestPath string) error {
+	privateKey, err := loadManifestKey(true)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	if err := manifest.sign(privateKey); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	data, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	if err := ioutil.WriteFile(manifestPath, append(data, '\n'), 0644); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	return nil
+}
+
+// loadManifest - read a manifest written by saveManifest
+func loadManifest(manifestPath string) (*transferManifest, error) {
+	data, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	manifest := new(transferManifest)
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return manifest, nil
+}
+
+// doVerifyManifest - compare every object of manifest with its copy under targetURL, nothing is written
+func doVerifyManifest(manifest *transferManifest, targetURL string) (VerifyManifestMessage, error) {
+	message := VerifyManifestMessage{
+		Target:  targetURL,
+		Created: manifest.Created,
+	}
+	for _, object := range manifest.Objects {
+		objectURL, err := manifestObjectURL(targetURL, object.Name)
+		if err != nil {
+			return VerifyManifestMessage{}, NewIodine(iodine.New(err, nil))
+		}
+		message.Checked++
+		size, sum, err := hashObject(objectURL)
+		reason := ""
+		switch {
+		case err != nil:
+			reason = driftMissing
+		case size != object.Size:
+			reason = driftSize
+		case sum != object.SHA256:
+			reason = driftChecksum
+		}
+		if reason == "" {
+			continue
+		}
+		message.Failed++
+		if len(message.Failures) < maxReportedDrifts {
+			message.Failures = append(message.Failures, mirrorDrift{URL: objectURL, Reason: reason})
+		}
+	}
+	return message, nil
+}
@@ -0,0 +1,78 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestManifest(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	target := filepath.Join(root, "target")
+	for _, name := range []string{"a.txt", "nested/b.txt", "nested/c.txt"} {
+		c.Assert(putTarget(filepath.Join(target, name), 5, bytes.NewReader([]byte("hello"))), IsNil)
+	}
+	manifest := &transferManifest{Version: "1.0.0", Target: target, Created: globalClock.Now().UTC()}
+	for _, name := range []string{"a.txt", "nested/b.txt", "nested/c.txt"} {
+		size, sum, err := hashObject(filepath.Join(target, name))
+		c.Assert(err, IsNil)
+		c.Assert(size, Equals, int64(5))
+		c.Assert(sum, Equals, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824")
+		manifest.Objects = append(manifest.Objects, manifestObject{Name: name, Size: size, SHA256: sum})
+	}
+
+	manifestPath := filepath.Join(root, "manifest.json")
+	c.Assert(saveManifest(manifest, manifestPath), IsNil)
+	manifest, err = loadManifest(manifestPath)
+	c.Assert(err, IsNil)
+	privateKey, err := loadManifestKey(false)
+	c.Assert(err, IsNil)
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	c.Assert(manifest.verifySignature(publicKey), IsNil)
+
+	otherPublicKey, _, err := ed25519.GenerateKey(nil)
+	c.Assert(err, IsNil)
+	c.Assert(iodine.ToError(manifest.verifySignature(otherPublicKey)), FitsTypeOf, errManifestSignature{})
+	tampered := *manifest
+	tampered.Objects = manifest.Objects[1:]
+	c.Assert(iodine.ToError(tampered.verifySignature(publicKey)), FitsTypeOf, errManifestSignature{})
+
+	message, err := doVerifyManifest(manifest, target)
+	c.Assert(err, IsNil)
+	c.Assert(message.Checked, Equals, 3)
+	c.Assert(message.Failed, Equals, 0)
+
+	c.Assert(putTarget(filepath.Join(target, "nested", "b.txt"), 5, bytes.NewReader([]byte("jello"))), IsNil)
+	c.Assert(os.Remove(filepath.Join(target, "nested", "c.txt")), IsNil)
+	message, err = doVerifyManifest(manifest, target)
+	c.Assert(err, IsNil)
+	c.Assert(message.Failed, Equals, 2)
+	c.Assert(message.Failures, DeepEquals, []mirrorDrift{
+		{URL: filepath.Join(target, "nested", "b.txt"), Reason: driftChecksum},
+		{URL: filepath.Join(target, "nested", "c.txt"), Reason: driftMissing},
+	})
+}
@@ -30,11 +30,12 @@ var mbCmd = cli.Command{
 	Name:   "mb",
 	Usage:  "Make a bucket or folder",
 	Action: runMakeBucketCmd,
+	Flags:  []cli.Flag{regionFlag, withLockFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc {{.Name}} TARGET [TARGET...] {{if .Description}}
+   mc {{.Name}} [--region REGION] [--with-lock] TARGET [TARGET...] {{if .Description}}
 
 DESCRIPTION:
    {{.Description}}{{end}}{{if .Flags}}
@@ -52,18 +53,25 @@ EXAMPLES:
 
    3. Create a bucket on Minio object storage.
       $ mc {{.Name}} https://play.minio.io:9000/mongodb-backup
+
+   4. Create a bucket in the Oregon region of Amazon S3 object storage.
+      $ mc {{.Name}} --region us-west-2 s3:oregon-logs
+
+   5. Create a bucket with object lock, so objects can be put under legal hold with ‘mc legalhold’.
+      $ mc {{.Name}} --with-lock s3:contracts
 `,
 }
 
 // runMakeBucketCmd is the handler for mc mb command
 func runMakeBucketCmd(ctx *cli.Context) {
 	if !ctx.Args().Present() || ctx.Args().First() == "help" {
-		cli.ShowCommandHelpAndExit(ctx, "mb", 1) // last argument is exit code
+		cli.ShowCommandHelpAndExit(ctx, "mb", exitUsage) // last argument is exit code
 	}
-	if !isMcConfigExists() {
+	if !isMcConfigAvailable() {
 		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
 	}
 	config := mustGetMcConfig()
+	options := client.BucketOptions{Region: ctx.String("region"), ObjectLock: ctx.Bool("with-lock")}
 	for _, arg := range ctx.Args() {
 		targetURL, err := getExpandedURL(arg, config.Aliases)
 		if err != nil {
@@ -74,7 +82,7 @@ func runMakeBucketCmd(ctx *cli.Context) {
 				console.Fatalf("Unable to parse argument %s. %s\n", arg, err)
 			}
 		}
-		msg, err := doMakeBucketCmd(targetURL)
+		msg, err := doMakeBucketCmd(targetURL, options)
 		if err != nil {
 			console.Fatalln(msg)
 		}
@@ -82,8 +90,8 @@ func runMakeBucketCmd(ctx *cli.Context) {
 	}
 }
 
-// doMakeBucketCmd -
-func doMakeBucketCmd(targetURL string) (string, error) {
+// doMakeBucketCmd - make the bucket or folder of targetURL with options
+func doMakeBucketCmd(targetURL string, options client.BucketOptions) (string, error) {
 	var err error
 	var clnt client.Client
 	clnt, err = target2Client(targetURL)
@@ -91,12 +99,19 @@ func doMakeBucketCmd(targetURL string) (string, error) {
 		msg := fmt.Sprintf("Unable to initialize client for ‘%s’", targetURL)
 		return msg, NewIodine(iodine.New(err, nil))
 	}
-	return doMakeBucket(clnt)
+	return doMakeBucket(clnt, options)
 }
 
-// doMakeBucket - wrapper around MakeBucket() API
-func doMakeBucket(clnt client.Client) (string, error) {
-	err := clnt.MakeBucket()
+// doMakeBucket - wrapper around MakeBucket() API, options need a client which can make buckets with them
+func doMakeBucket(clnt client.Client, options client.BucketOptions) (string, error) {
+	var err error
+	if options == (client.BucketOptions{}) {
+		err = clnt.MakeBucket()
+	} else if maker, ok := clnt.(client.OptionsMaker); ok {
+		err = maker.MakeBucketWithOptions(options)
+	} else {
+		err = errBucketOptionsNotSupported{URL: clnt.URL().String()}
+	}
 	if err != nil {
 		msg := fmt.Sprintf("Failed to create bucket for URL ‘%s’", clnt.URL().String())
 		return msg, NewIodine(iodine.New(err, nil))
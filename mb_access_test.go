@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/minio/mc/pkg/client"
 	. "gopkg.in/check.v1"
 )
 
@@ -30,16 +31,22 @@ func (s *CmdTestSuite) TestMbAndAccessCmd(c *C) {
 	c.Assert(err, IsNil)
 	defer os.RemoveAll(root)
 
-	_, err = doMakeBucketCmd(filepath.Join(root, "bucket"))
+	_, err = doMakeBucketCmd(filepath.Join(root, "bucket"), client.BucketOptions{})
 	c.Assert(err, IsNil)
 
+	_, err = doMakeBucketCmd(filepath.Join(root, "locked"), client.BucketOptions{ObjectLock: true})
+	c.Assert(err, Not(IsNil))
+
 	_, err = doUpdateAccessCmd(filepath.Join(root, "bucket"), "public-read-write")
 	c.Assert(err, IsNil)
 
 	_, err = doUpdateAccessCmd(filepath.Join(root, "bucket"), "invalid")
 	c.Assert(err, Not(IsNil))
 
-	_, err = doMakeBucketCmd(server.URL + "/bucket")
+	_, err = doMakeBucketCmd(server.URL+"/bucket", client.BucketOptions{})
+	c.Assert(err, IsNil)
+
+	_, err = doMakeBucketCmd(server.URL+"/bucket", client.BucketOptions{Region: "us-west-2", ObjectLock: true})
 	c.Assert(err, IsNil)
 
 	_, err = doUpdateAccessCmd(server.URL+"/bucket", "public-read-write")
@@ -0,0 +1,132 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// migrationStep - change of a file format from one version to the next. Steps work on decoded JSON, so
+// structs of old versions need not be kept around
+type migrationStep struct {
+	From        string
+	To          string
+	Description string
+	Migrate     func(doc map[string]interface{}) error
+}
+
+// Migrations of configuration files and session headers, append a step whenever mcCurrentConfigVersion or
+// mcCurrentSessionVersion changes
+var (
+	configMigrations  = []migrationStep{}
+	sessionMigrations = []migrationStep{
+		{
+			From: "1.1.0", To: "1.2.0",
+			Description: "Session data records are checksummed and may be sealed, records saved before are read as they are.",
+			// headers only gained fields which are empty for older sessions, progress of sessions which only know
+			// the last copied entry is restored when they are loaded
+			Migrate: func(doc map[string]interface{}) error { return nil },
+		},
+	}
+)
+
+// migrateFile - migrate the JSON file at path stepwise to version current. The original file is kept
+// next to it with its version as suffix, files of unknown or newer versions are never touched
+func migrateFile(path, versionKey, current string, steps []migrationStep) ([]MigrationMessage, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, NewIodine(iodine.New(err, map[string]string{"Path": path}))
+	}
+	version, _ := doc[versionKey].(string)
+	if version == current {
+		return nil, nil
+	}
+	backup := path + "." + version + ".bak"
+	var messages []MigrationMessage
+	for version != current {
+		var step *migrationStep
+		for i := range steps {
+			if steps[i].From == version {
+				step = &steps[i]
+				break
+			}
+		}
+		if step == nil {
+			return nil, NewIodine(iodine.New(errUnknownVersion{path: path, version: version}, nil))
+		}
+		if err := step.Migrate(doc); err != nil {
+			return nil, NewIodine(iodine.New(err, map[string]string{"Path": path, "Version": step.To}))
+		}
+		doc[versionKey] = step.To
+		messages = append(messages, MigrationMessage{Path: path, From: step.From, To: step.To, Description: step.Description, Backup: backup})
+		version = step.To
+	}
+	migrated, err := json.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	// the backup is written first, the migrated file replaces the original in one rename
+	if err := ioutil.WriteFile(backup, data, 0600); err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if err := ioutil.WriteFile(path+".tmp", migrated, 0600); err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		os.Remove(path + ".tmp")
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return messages, nil
+}
+
+// migrateConfig - migrate configuration file to mcCurrentConfigVersion
+func migrateConfig() {
+	if !isMcConfigExists() {
+		return
+	}
+	messages, err := migrateFile(mustGetMcConfigPath(), "Version", mcCurrentConfigVersion, configMigrations)
+	if err != nil {
+		console.Fatalf("Unable to migrate configuration file. %s\n", NewIodine(iodine.New(err, nil)))
+	}
+	for _, message := range messages {
+		console.PrintC(message)
+	}
+}
+
+// migrateSessionHeaders - migrate headers of saved sessions to mcCurrentSessionVersion, sessions which can
+// not be migrated are reported and left for ‘mc session clear’
+func migrateSessionHeaders() {
+	for _, sid := range getSessionIDs() {
+		messages, err := migrateFile(getSessionFile(sid), "version", mcCurrentSessionVersion, sessionMigrations)
+		if err != nil {
+			console.Errorf("Unable to migrate session ‘%s’. %s\n", sid, NewIodine(iodine.New(err, nil)))
+			continue
+		}
+		for _, message := range messages {
+			console.PrintC(message)
+		}
+	}
+}
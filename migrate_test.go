@@ -0,0 +1,102 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestMigrateFile(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	path := filepath.Join(root, "config.json")
+	original := []byte(`{"Version": "1.0.0", "Hosts": {"example.com": {"Key": "access"}}}`)
+	c.Assert(ioutil.WriteFile(path, original, 0600), IsNil)
+
+	steps := []migrationStep{
+		{From: "1.1.0", To: "1.2.0", Description: "drop Hosts", Migrate: func(doc map[string]interface{}) error {
+			delete(doc, "Hosts")
+			return nil
+		}},
+		{From: "1.0.0", To: "1.1.0", Description: "add Aliases", Migrate: func(doc map[string]interface{}) error {
+			doc["Aliases"] = map[string]interface{}{"s3": "https://s3.amazonaws.com"}
+			return nil
+		}},
+	}
+	messages, err := migrateFile(path, "Version", "1.2.0", steps)
+	c.Assert(err, IsNil)
+	c.Assert(messages, HasLen, 2)
+	c.Assert(messages[0].From, Equals, "1.0.0")
+	c.Assert(messages[1].To, Equals, "1.2.0")
+
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	var doc map[string]interface{}
+	c.Assert(json.Unmarshal(data, &doc), IsNil)
+	c.Assert(doc["Version"], Equals, "1.2.0")
+	c.Assert(doc["Aliases"], NotNil)
+	c.Assert(doc["Hosts"], IsNil)
+	backup, err := ioutil.ReadFile(messages[0].Backup)
+	c.Assert(err, IsNil)
+	c.Assert(backup, DeepEquals, original)
+
+	// current files are left alone, newer ones are refused
+	messages, err = migrateFile(path, "Version", "1.2.0", steps)
+	c.Assert(err, IsNil)
+	c.Assert(messages, HasLen, 0)
+	_, err = migrateFile(path, "Version", "1.1.0", steps)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errUnknownVersion{})
+}
+
+func (s *CmdTestSuite) TestMigrateSession(c *C) {
+	c.Assert(createSessionDir(), IsNil)
+	// sessions of version 1.1.0 kept plain JSON records and only the last copied entry
+	sid := "oldsessn"
+	header := `{"version": "1.1.0", "time": "2015-09-01T00:00:00Z", "command-type": "cp", "cmd-args": ["a", "b/"],
+"last-copied": "a/1.txt", "total-bytes": 3, "total-objects": 2}`
+	data := `{"SourceContent":{"Name":"a/1.txt","Size":1},"TargetContent":{"Name":"b/1.txt"}}
+{"SourceContent":{"Name":"a/2.txt","Size":2},"TargetContent":{"Name":"b/2.txt"}}
+`
+	c.Assert(ioutil.WriteFile(getSessionFile(sid), []byte(header), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(getSessionDataFile(sid), []byte(data), 0600), IsNil)
+	defer func() {
+		for _, file := range append(getSessionFiles(sid), getSessionFile(sid)+".1.1.0.bak") {
+			os.Remove(file)
+		}
+	}()
+
+	migrateSessionHeaders()
+	session, err := loadSessionV2(sid)
+	c.Assert(err, IsNil)
+	defer session.DataFP.Close()
+	c.Assert(session.Header.Version, Equals, mcCurrentSessionVersion)
+	c.Assert(session.Header.CopiedObjects, Equals, 1)
+	scanner := session.NewDataScanner()
+	records := 0
+	for scanner.Scan() {
+		records++
+	}
+	c.Assert(scanner.Corrupted(), Equals, false)
+	c.Assert(records, Equals, 2)
+}
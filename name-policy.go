@@ -0,0 +1,256 @@
+/*
+ * Minio Client, (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// objectNamePolicy - what to do with target names which fail validation
+type objectNamePolicy string
+
+// different types of object name policies currently supported
+const (
+	namePolicyNone     = objectNamePolicy("")
+	namePolicyFail     = objectNamePolicy("fail")
+	namePolicySkip     = objectNamePolicy("skip")
+	namePolicySanitize = objectNamePolicy("sanitize")
+)
+
+// name length limits of the targets we support
+const (
+	maxObjectNameLength    = 1024 // S3 object key limit in bytes
+	maxFilenameLength      = 255  // most filesystems limit a path component to 255 bytes
+	windowsInvalidNameRune = `<>:"|?*\`
+)
+
+// device names reserved on windows, with or without an extension
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+func (p objectNamePolicy) isValid() bool {
+	switch p {
+	case namePolicyNone, namePolicyFail, namePolicySkip, namePolicySanitize:
+		return true
+	default:
+		return false
+	}
+}
+
+// splitTargetName splits a target URL into the user provided target and the
+// name derived from the source. For a file to file copy the derived name is
+// the base name of the target.
+func splitTargetName(targetURL, baseTargetURL string) (prefix, name string) {
+	if baseTargetURL != "" && strings.HasPrefix(targetURL, baseTargetURL) && targetURL != baseTargetURL {
+		return baseTargetURL, strings.TrimPrefix(targetURL, baseTargetURL)
+	}
+	i := strings.LastIndexAny(targetURL, "/\\")
+	return targetURL[:i+1], targetURL[i+1:]
+}
+
+// splitNameComponents splits name into path components on both separators
+func splitNameComponents(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return r == '/' || r == '\\'
+	})
+}
+
+// validateComponent verifies a single path component
+func validateComponent(component string, windows bool) error {
+	if !utf8.ValidString(component) {
+		return errInvalidObjectName{name: component, reason: "is not valid UTF-8"}
+	}
+	for _, r := range component {
+		if unicode.IsControl(r) {
+			return errInvalidObjectName{name: component, reason: "contains control characters"}
+		}
+	}
+	if !windows {
+		return nil
+	}
+	if strings.ContainsAny(component, windowsInvalidNameRune) {
+		return errInvalidObjectName{name: component, reason: "contains characters invalid on Windows"}
+	}
+	if strings.HasSuffix(component, ".") || strings.HasSuffix(component, " ") {
+		return errInvalidObjectName{name: component, reason: "ends with a dot or space, invalid on Windows"}
+	}
+	base := strings.ToUpper(strings.SplitN(component, ".", 2)[0])
+	if windowsReservedNames[base] {
+		return errInvalidObjectName{name: component, reason: "is a reserved device name on Windows"}
+	}
+	return nil
+}
+
+// validateObjectName verifies the name derived from the source against the constraints of the target.
+func validateObjectName(name string, urlType client.URLType, windows bool) error {
+	if urlType == client.Object && len(name) > maxObjectNameLength {
+		return errInvalidObjectName{name: name, reason: "exceeds the maximum object name length"}
+	}
+	for _, component := range splitNameComponents(name) {
+		if urlType == client.Filesystem && len(component) > maxFilenameLength {
+			return errInvalidObjectName{name: component, reason: "exceeds the maximum file name length"}
+		}
+		if err := validateComponent(component, windows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sanitizeComponent replaces everything validateComponent would reject
+func sanitizeComponent(component string, maxLength int, windows bool) string {
+	component = strings.Map(func(r rune) rune {
+		switch {
+		case r == utf8.RuneError:
+			return '_'
+		case unicode.IsControl(r):
+			return '_'
+		case windows && strings.ContainsRune(windowsInvalidNameRune, r):
+			return '_'
+		}
+		return r
+	}, strings.ToValidUTF8(component, "_"))
+	if windows {
+		if strings.HasSuffix(component, ".") || strings.HasSuffix(component, " ") {
+			component = strings.TrimRight(component, ". ") + "_"
+		}
+		parts := strings.SplitN(component, ".", 2)
+		if windowsReservedNames[strings.ToUpper(parts[0])] {
+			parts[0] = parts[0] + "_"
+			component = strings.Join(parts, ".")
+		}
+	}
+	if maxLength > 0 && len(component) > maxLength {
+		// truncate without splitting a multi-byte character
+		for len(component) > maxLength {
+			_, size := utf8.DecodeLastRuneInString(component)
+			component = component[:len(component)-size]
+		}
+	}
+	return component
+}
+
+// sanitizeObjectName rewrites the name derived from the source so that it passes validateObjectName.
+func sanitizeObjectName(name string, urlType client.URLType, windows bool) string {
+	maxLength := 0
+	if urlType == client.Filesystem {
+		maxLength = maxFilenameLength
+	}
+	var sanitized []rune
+	var component []rune
+	flush := func() {
+		sanitized = append(sanitized, []rune(sanitizeComponent(string(component), maxLength, windows))...)
+		component = nil
+	}
+	for _, r := range name {
+		if r == '/' || r == '\\' {
+			flush()
+			sanitized = append(sanitized, r)
+			continue
+		}
+		component = append(component, r)
+	}
+	flush()
+	newName := string(sanitized)
+	if urlType == client.Object && len(newName) > maxObjectNameLength {
+		newName = sanitizeComponent(newName, maxObjectNameLength, windows)
+	}
+	return newName
+}
+
+// numberedName adds a numbered suffix to the last component of name, ahead of its extension. The stem is
+// shortened as needed to keep the component within maxLength.
+func numberedName(name string, n, maxLength int) string {
+	i := strings.LastIndexAny(name, "/\\")
+	dir, component := name[:i+1], name[i+1:]
+	ext := path.Ext(component)
+	if ext == component {
+		ext = ""
+	}
+	stem := strings.TrimSuffix(component, ext)
+	suffix := "_" + strconv.Itoa(n)
+	if maxLength > 0 && len(stem)+len(suffix)+len(ext) > maxLength {
+		stem = sanitizeComponent(stem, maxLength-len(suffix)-len(ext), false)
+	}
+	return dir + stem + suffix + ext
+}
+
+// applyNamePolicy validates the target name of a prepared copy. It returns the new
+// target URL, or an empty string if the entry has to be skipped. planned holds the
+// target URLs returned so far, two sources never map onto the same target.
+func applyNamePolicy(sourceURL, targetURL, baseTargetURL string, policy objectNamePolicy, windows bool, planned map[string]bool) (string, error) {
+	if policy == namePolicyNone {
+		return targetURL, nil
+	}
+	targetURLParse, err := client.Parse(targetURL)
+	if err != nil {
+		return "", NewIodine(iodine.New(errInvalidTarget{URL: targetURL}, nil))
+	}
+	prefix, name := splitTargetName(targetURL, baseTargetURL)
+	err = validateObjectName(name, targetURLParse.Type, windows)
+	if err == nil && planned[targetURL] {
+		err = errTargetNameClash{name: name}
+	}
+	if err == nil {
+		planned[targetURL] = true
+		return targetURL, nil
+	}
+	switch policy {
+	case namePolicySkip:
+		console.PrintC(NameMappingMessage{Source: sourceURL, Target: targetURL, Action: "skipped", Reason: err.Error()})
+		return "", nil
+	case namePolicySanitize:
+		maxLength := 0
+		if targetURLParse.Type == client.Filesystem {
+			maxLength = maxFilenameLength
+		}
+		join := func(newName string) string {
+			if targetURLParse.Type == client.Filesystem {
+				return filepath.Clean(prefix + newName)
+			}
+			return prefix + newName
+		}
+		newName := sanitizeObjectName(name, targetURLParse.Type, windows)
+		newTargetURL := join(newName)
+		reason := err.Error()
+		for n := 1; planned[newTargetURL]; n++ {
+			if n == 1 && newName != name {
+				reason += " " + errTargetNameClash{name: newName}.Error()
+			}
+			newTargetURL = join(numberedName(newName, n, maxLength))
+		}
+		planned[newTargetURL] = true
+		console.PrintC(NameMappingMessage{Source: sourceURL, Target: targetURL, NewTarget: newTargetURL, Action: "renamed", Reason: reason})
+		return newTargetURL, nil
+	default:
+		return "", NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * Minio Client, (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestValidateObjectName(c *C) {
+	c.Assert(validateObjectName("photos/2015/本語.jpg", client.Object, false), IsNil)
+	c.Assert(validateObjectName("photos/bad\x01name", client.Object, false), Not(IsNil))
+	c.Assert(validateObjectName("photos/bad\xffname", client.Object, false), Not(IsNil))
+	c.Assert(validateObjectName(strings.Repeat("a", 1025), client.Object, false), Not(IsNil))
+	c.Assert(validateObjectName(strings.Repeat("a", 256), client.Filesystem, false), Not(IsNil))
+
+	// valid on unix, not on windows
+	c.Assert(validateObjectName("reports/q1:q2?.txt", client.Object, false), IsNil)
+	c.Assert(validateObjectName("reports/q1:q2?.txt", client.Object, true), Not(IsNil))
+	c.Assert(validateObjectName("devices/con.txt", client.Object, true), Not(IsNil))
+	c.Assert(validateObjectName("trailing./file", client.Object, true), Not(IsNil))
+}
+
+func (s *CmdTestSuite) TestSanitizeObjectName(c *C) {
+	c.Assert(sanitizeObjectName("photos/bad\x01name", client.Object, false), Equals, "photos/bad_name")
+	c.Assert(sanitizeObjectName("photos/bad\xffname", client.Object, false), Equals, "photos/bad_name")
+	c.Assert(sanitizeObjectName("reports/q1:q2?.txt", client.Object, true), Equals, "reports/q1_q2_.txt")
+	c.Assert(sanitizeObjectName("devices/con.txt", client.Object, true), Equals, "devices/con_.txt")
+	c.Assert(sanitizeObjectName("trailing./file", client.Object, true), Equals, "trailing_/file")
+	c.Assert(len(sanitizeObjectName(strings.Repeat("本", 100), client.Filesystem, false)) <= maxFilenameLength, Equals, true)
+
+	for _, name := range []string{"photos/bad\x01name", "reports/q1:q2?.txt", "devices/con.txt", "trailing./file"} {
+		c.Assert(validateObjectName(sanitizeObjectName(name, client.Object, true), client.Object, true), IsNil)
+	}
+}
+
+func (s *CmdTestSuite) TestApplyNamePolicy(c *C) {
+	target, err := applyNamePolicy("src/a", "http://localhost:9000/bucket/con.txt", "http://localhost:9000/bucket", namePolicyNone, true, map[string]bool{})
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "http://localhost:9000/bucket/con.txt")
+
+	_, err = applyNamePolicy("src/a", "http://localhost:9000/bucket/con.txt", "http://localhost:9000/bucket", namePolicyFail, true, map[string]bool{})
+	c.Assert(err, Not(IsNil))
+
+	target, err = applyNamePolicy("src/a", "http://localhost:9000/bucket/con.txt", "http://localhost:9000/bucket", namePolicySkip, true, map[string]bool{})
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "")
+
+	// user provided target prefix is never rewritten.
+	target, err = applyNamePolicy("src/a", "http://localhost:9000/bucket:1/a?b", "http://localhost:9000/bucket:1", namePolicySanitize, true, map[string]bool{})
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "http://localhost:9000/bucket:1/a_b")
+}
+
+func (s *CmdTestSuite) TestApplyNamePolicyClash(c *C) {
+	base := "http://localhost:9000/bucket"
+	planned := make(map[string]bool)
+	target, err := applyNamePolicy("src/a:b.txt", base+"/a:b.txt", base, namePolicySanitize, true, planned)
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, base+"/a_b.txt")
+
+	// a valid name which clashes with an earlier renamed one gets a numbered suffix.
+	target, err = applyNamePolicy("src/a_b.txt", base+"/a_b.txt", base, namePolicySanitize, true, planned)
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, base+"/a_b_1.txt")
+
+	target, err = applyNamePolicy("src/a?b.txt", base+"/a?b.txt", base, namePolicySanitize, true, planned)
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, base+"/a_b_2.txt")
+
+	// names cut at the same length limit.
+	dir := "/tmp/target/"
+	long := strings.Repeat("a", maxFilenameLength)
+	target, err = applyNamePolicy("src/1", dir+long+"1", dir, namePolicySanitize, false, planned)
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, dir+long)
+	target, err = applyNamePolicy("src/2", dir+long+"2", dir, namePolicySanitize, false, planned)
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, dir+long[:maxFilenameLength-2]+"_1")
+
+	_, err = applyNamePolicy("src/a_b.txt", base+"/a_b.txt", base, namePolicyFail, true, planned)
+	c.Assert(err, Not(IsNil))
+	target, err = applyNamePolicy("src/a_b.txt", base+"/a_b.txt", base, namePolicySkip, true, planned)
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "")
+}
@@ -0,0 +1,142 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+	"sync"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// parallelRangeSize - size of each ranged GET, objects up to this size are downloaded in a single stream
+const parallelRangeSize = 32 * 1024 * 1024
+
+// offsetWriter - writes to w sequentially from offset
+type offsetWriter struct {
+	w        io.WriterAt
+	offset   int64
+	progress func(int64)
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.offset)
+	o.offset += int64(n)
+	if o.progress != nil {
+		o.progress(int64(n))
+	}
+	return n, err
+}
+
+// copyRange - write length bytes of source at offset to target
+func copyRange(source client.Client, target io.WriterAt, offset, length int64, progress func(int64)) error {
+	reader, _, err := source.GetObject(offset, length)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	defer reader.Close()
+	if _, err := io.CopyN(&offsetWriter{w: target, offset: offset, progress: progress}, reader, length); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	return nil
+}
+
+// copyRanges - download a remote object of size bytes to a local target with parallel ranged GETs of
+// parallelRangeSize, each written at its offset into the preallocated target, which only replaces an existing
+// target once all ranges are written. Objects which are not
+// larger than a range, and local sources, are not handled and copied returns false
+func copyRanges(sourceURL, targetURL string, size int64, parallel int, progress func(int64)) (copied bool, err error) {
+	if parallel < 2 || size <= parallelRangeSize {
+		return false, nil
+	}
+	sourceClnt, err := source2Client(sourceURL)
+	if err != nil {
+		return false, NewIodine(iodine.New(err, nil))
+	}
+	if sourceClnt.URL().Type == client.Filesystem {
+		return false, nil
+	}
+	targetClnt, err := target2Client(targetURL)
+	if err != nil {
+		return false, NewIodine(iodine.New(err, nil))
+	}
+	rangeWriter, ok := targetClnt.(client.RangeWriter)
+	if !ok {
+		return false, nil
+	}
+	// targets which must be encrypted are written by putObject only
+	if sse, err := getRequiredEncryption(targetURL); err != nil || sse != nil {
+		return false, nil
+	}
+	writer, err := rangeWriter.CreateObjectAt(size)
+	if _, ok := iodine.ToError(err).(client.APINotImplemented); ok {
+		return false, nil
+	}
+	if err != nil {
+		return true, NewIodine(iodine.New(err, map[string]string{"failedURL": targetURL}))
+	}
+
+	offsetCh := make(chan int64)
+	doneCh := make(chan struct{})
+	errCh := make(chan error, parallel)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := range offsetCh {
+				length := size - offset
+				if length > parallelRangeSize {
+					length = parallelRangeSize
+				}
+				if err := copyRange(sourceClnt, writer, offset, length, progress); err != nil {
+					errCh <- err
+					return
+				}
+			}
+		}()
+	}
+	// ranges are handed out until the first failure
+	go func() {
+		defer close(offsetCh)
+		for offset := int64(0); offset < size; offset += parallelRangeSize {
+			select {
+			case offsetCh <- offset:
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(errCh)
+	}()
+	err = <-errCh
+	close(doneCh)
+	for range errCh { // wait for running ranges to finish
+	}
+	// failed downloads leave no partly written target behind
+	if err != nil {
+		writer.Abort()
+		return true, NewIodine(iodine.New(err, map[string]string{"failedURL": sourceURL}))
+	}
+	if err := writer.Close(); err != nil {
+		return true, NewIodine(iodine.New(err, map[string]string{"failedURL": targetURL}))
+	}
+	return true, nil
+}
@@ -0,0 +1,96 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestCopyRanges(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), (2*parallelRangeSize+1024)/16)
+	var ranges int32
+	rangeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			atomic.AddInt32(&ranges, 1)
+		}
+		w.Header().Set("ETag", "b1946ac92492d2347c6235b4d2611184")
+		http.ServeContent(w, r, "dataset", time.Now(), bytes.NewReader(data))
+	}))
+	defer rangeServer.Close()
+	sourceURL := rangeServer.URL + "/bucket/dataset"
+
+	var progress int64
+	targetPath := filepath.Join(root, "nested", "dataset")
+	copied, err := copyRanges(sourceURL, targetPath, int64(len(data)), 4, func(n int64) { atomic.AddInt64(&progress, n) })
+	c.Assert(err, IsNil)
+	c.Assert(copied, Equals, true)
+	c.Assert(ranges, Equals, int32(3))
+	c.Assert(progress, Equals, int64(len(data)))
+	downloaded, err := ioutil.ReadFile(targetPath)
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(downloaded, data), Equals, true)
+
+	// small objects and single streams are left to the usual copy
+	copied, err = copyRanges(sourceURL, targetPath, parallelRangeSize, 4, nil)
+	c.Assert(err, IsNil)
+	c.Assert(copied, Equals, false)
+	copied, err = copyRanges(sourceURL, targetPath, int64(len(data)), 1, nil)
+	c.Assert(err, IsNil)
+	c.Assert(copied, Equals, false)
+
+	// a failed range leaves the previous target in place and no partly written file behind
+	failServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" && r.Header.Get("Range") != "bytes=0-"+strconv.Itoa(parallelRangeSize-1) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", "b1946ac92492d2347c6235b4d2611184")
+		http.ServeContent(w, r, "dataset", time.Now(), bytes.NewReader(data))
+	}))
+	defer failServer.Close()
+	previousPath := filepath.Join(root, "previous", "dataset")
+	c.Assert(os.MkdirAll(filepath.Dir(previousPath), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(previousPath, []byte("previous"), 0600), IsNil)
+	copied, err = copyRanges(failServer.URL+"/bucket/dataset", previousPath, int64(len(data)), 4, nil)
+	c.Assert(err, NotNil)
+	c.Assert(copied, Equals, true)
+	previous, err := ioutil.ReadFile(previousPath)
+	c.Assert(err, IsNil)
+	c.Assert(string(previous), Equals, "previous")
+	entries, err := ioutil.ReadDir(filepath.Dir(previousPath))
+	c.Assert(err, IsNil)
+	c.Assert(len(entries), Equals, 1)
+
+	// local sources are read sequentially
+	copied, err = copyRanges(targetPath, filepath.Join(root, "copy"), int64(len(data)), 4, nil)
+	c.Assert(err, IsNil)
+	c.Assert(copied, Equals, false)
+}
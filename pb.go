@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/pb"
 	"github.com/olekukonko/ts"
@@ -56,6 +57,20 @@ func (r *proxyReader) Close() (err error) {
 	return r.ReadCloser.Close()
 }
 
+// mappedProxyReader - proxyReader of a memory mapped reader, bytes uploaders send without reading are
+// reported when they Advance past them
+type mappedProxyReader struct {
+	proxyReader
+	mapped client.MappedReader
+}
+
+func (r *mappedProxyReader) Bytes() []byte { return r.mapped.Bytes() }
+
+func (r *mappedProxyReader) Advance(n int64) {
+	r.mapped.Advance(n)
+	r.bar.Progress(n)
+}
+
 type barMsg struct {
 	Cmd pbBarCmd
 	Arg interface{}
@@ -66,7 +81,10 @@ type barSend struct {
 	finishCh <-chan bool
 }
 
-func (b *barSend) NewProxyReader(r io.ReadCloser) *proxyReader {
+func (b *barSend) NewProxyReader(r io.ReadCloser) io.ReadCloser {
+	if mapped, ok := r.(client.MappedReader); ok {
+		return &mappedProxyReader{proxyReader{r, b}, mapped}
+	}
 	return &proxyReader{r, b}
 }
 
@@ -97,6 +115,11 @@ func (b barSend) Finish() {
 	console.Println()
 }
 
+// showProgressBar - progress bars are left out in quiet and JSON output, they may have no terminal
+func showProgressBar() bool {
+	return !globalQuietFlag && !globalJSONFlag
+}
+
 func cursorAnimate() <-chan rune {
 	cursorCh := make(chan rune)
 	var cursors string
@@ -214,6 +237,10 @@ type scanBarFunc func(string)
 
 // scanBarFactory returns a progress bar function to report URL scanning.
 func scanBarFactory(prefix string) scanBarFunc {
+	// quiet and JSON output may have no terminal, like in cron jobs
+	if !showProgressBar() {
+		return func(string) {}
+	}
 	prevLineSize := 0
 	fileCount := 0
 	termSize, err := ts.GetSize()
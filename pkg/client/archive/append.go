@@ -0,0 +1,173 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// blockSize - tar archives are made of 512 byte blocks
+const blockSize = 512
+
+// archiveLocks - members are appended one at a time, copy routines of a recursive copy share the archive
+var archiveLocks = struct {
+	sync.Mutex
+	locks map[string]*sync.Mutex
+}{locks: make(map[string]*sync.Mutex)}
+
+// lockArchive - lock archive for appending, returns the unlock function
+func lockArchive(archive string) func() {
+	if abs, err := filepath.Abs(archive); err == nil {
+		archive = abs
+	}
+	archiveLocks.Lock()
+	lock, ok := archiveLocks.locks[archive]
+	if !ok {
+		lock = new(sync.Mutex)
+		archiveLocks.locks[archive] = lock
+	}
+	archiveLocks.Unlock()
+	lock.Lock()
+	return lock.Unlock
+}
+
+// countingReader - counts bytes read to locate the end of the last member
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// appendOffset - offset where the end of archive marker starts, new members overwrite it
+func appendOffset(file *os.File) (int64, error) {
+	counter := &countingReader{reader: file}
+	tr := tar.NewReader(counter)
+	var offset int64
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return offset, nil
+		}
+		if err != nil {
+			return 0, iodine.New(err, nil)
+		}
+		// header blocks are read, data padded to block size follows
+		offset = counter.count + (header.Size+blockSize-1)/blockSize*blockSize
+	}
+}
+
+// PutObject - append member to a tar archive, archive is created if missing
+func (c *archiveClient) PutObject(size int64, data io.Reader) error {
+	if c.format != formatTar {
+		return iodine.New(client.APINotImplemented{API: "PutObject"}, nil)
+	}
+	if c.member == "" {
+		return iodine.New(client.ISFolder{Path: c.hostURL.String()}, nil)
+	}
+	// size could be 0 for virtual files, tar header needs size upfront
+	if size == 0 {
+		buffer, err := ioutil.ReadAll(data)
+		if err != nil {
+			return iodine.New(err, nil)
+		}
+		size = int64(len(buffer))
+		data = bytes.NewReader(buffer)
+	}
+	defer lockArchive(c.archive)()
+	if err := os.MkdirAll(filepath.Dir(c.archive), 0700); err != nil {
+		return iodine.New(err, nil)
+	}
+	file, err := os.OpenFile(c.archive, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	defer file.Close()
+	offset, err := appendOffset(file)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if _, err := file.Seek(offset, os.SEEK_SET); err != nil {
+		return iodine.New(err, nil)
+	}
+	tw := tar.NewWriter(file)
+	header := &tar.Header{
+		Name:     c.member,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  time.Now(),
+		Typeflag: tar.TypeReg,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return iodine.New(err, nil)
+	}
+	if _, err := io.CopyN(tw, data, size); err != nil {
+		// drop the partial member, archive stays valid
+		file.Truncate(offset)
+		file.Seek(offset, os.SEEK_SET)
+		tar.NewWriter(file).Close()
+		return iodine.New(err, nil)
+	}
+	if err := tw.Close(); err != nil {
+		return iodine.New(err, nil)
+	}
+	end, err := file.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	return iodine.New(file.Truncate(end), nil)
+}
+
+// MakeBucket - create an empty tar archive, folders inside archives are implied by member names
+func (c *archiveClient) MakeBucket() error {
+	if c.format != formatTar {
+		return iodine.New(client.APINotImplemented{API: "MakeBucket"}, nil)
+	}
+	if c.member != "" {
+		return nil
+	}
+	defer lockArchive(c.archive)()
+	if _, err := os.Stat(c.archive); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.archive), 0700); err != nil {
+		return iodine.New(err, nil)
+	}
+	file, err := os.OpenFile(c.archive, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if err := tar.NewWriter(file).Close(); err != nil {
+		file.Close()
+		return iodine.New(err, nil)
+	}
+	return iodine.New(file.Close(), nil)
+}
@@ -0,0 +1,423 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// archive formats, detected from file name extension
+const (
+	formatTar = iota
+	formatTarGz
+	formatZip
+)
+
+type archiveClient struct {
+	hostURL *client.URL
+	archive string // path of archive file on local filesystem
+	member  string // member path inside archive, empty for archive root
+	format  int
+}
+
+// entry - archive member, folders are either stored or implied by member names
+type entry struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+	posix   *client.POSIXAttrs // only tar members record ownership
+}
+
+// archiveFormat - format of a file name, false if it is not an archive
+func archiveFormat(name string) (int, bool) {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".tar"):
+		return formatTar, true
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return formatTarGz, true
+	case strings.HasSuffix(name, ".zip"):
+		return formatZip, true
+	}
+	return 0, false
+}
+
+// memberName - clean member name, members escaping the archive with ".." or absolute paths are kept inside it
+func memberName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.Replace(name, "\\", "/", -1)), "/")
+}
+
+// New returns an initialized archiveClient structure. URL is of the form tar://path/archive.tar[/member],
+// first path element with .tar, .tar.gz, .tgz or .zip extension is the archive
+func New(hostURL string) (client.Client, error) {
+	u, err := client.Parse(hostURL)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	if u.Scheme != "tar" {
+		return nil, iodine.New(client.InvalidQueryURL{URL: hostURL}, nil)
+	}
+	elements := strings.Split(strings.Replace(u.Path, "\\", "/", -1), "/")
+	for i, element := range elements {
+		format, ok := archiveFormat(element)
+		if !ok {
+			continue
+		}
+		archive := strings.Join(elements[:i+1], "/")
+		if archive == "" {
+			continue
+		}
+		return &archiveClient{
+			hostURL: u,
+			archive: filepath.FromSlash(archive),
+			member:  memberName(strings.Join(elements[i+1:], "/")),
+			format:  format,
+		}, nil
+	}
+	return nil, iodine.New(client.InvalidQueryURL{URL: hostURL}, nil)
+}
+
+// URL get url
+func (c *archiveClient) URL() *client.URL {
+	return c.hostURL
+}
+
+// urlPath - path of URL without trailing slash, used as name of listed contents
+func (c *archiveClient) urlPath() string {
+	return strings.TrimSuffix(strings.Replace(c.hostURL.Path, "\\", "/", -1), "/")
+}
+
+// archiveReader - tar stream of archive, closer releases the file
+type archiveReader struct {
+	*tar.Reader
+	closers []io.Closer
+}
+
+func (r archiveReader) Close() error {
+	var err error
+	for i := len(r.closers) - 1; i >= 0; i-- {
+		if e := r.closers[i].Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// openTar - open tar or gzip compressed tar archive for reading
+func (c *archiveClient) openTar() (archiveReader, error) {
+	file, err := os.Open(c.archive)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return archiveReader{}, iodine.New(client.NotFound{Path: c.hostURL.String()}, nil)
+		}
+		return archiveReader{}, iodine.New(err, nil)
+	}
+	if c.format != formatTarGz {
+		return archiveReader{Reader: tar.NewReader(file), closers: []io.Closer{file}}, nil
+	}
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return archiveReader{}, iodine.New(err, nil)
+	}
+	return archiveReader{Reader: tar.NewReader(gz), closers: []io.Closer{file, gz}}, nil
+}
+
+// openZip - open zip archive for reading
+func (c *archiveClient) openZip() (*zip.ReadCloser, error) {
+	zr, err := zip.OpenReader(c.archive)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, iodine.New(client.NotFound{Path: c.hostURL.String()}, nil)
+		}
+		return nil, iodine.New(err, nil)
+	}
+	return zr, nil
+}
+
+// entries - all members of the archive, read from headers only
+func (c *archiveClient) entries() ([]entry, error) {
+	var entries []entry
+	if c.format == formatZip {
+		zr, err := c.openZip()
+		if err != nil {
+			return nil, iodine.New(err, nil)
+		}
+		defer zr.Close()
+		for _, member := range zr.File {
+			entries = append(entries, entry{
+				name:    memberName(member.Name),
+				size:    int64(member.UncompressedSize64),
+				modTime: member.ModTime(),
+				dir:     member.Mode().IsDir(),
+			})
+		}
+		return entries, nil
+	}
+	tr, err := c.openTar()
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	defer tr.Close()
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, iodine.New(err, nil)
+		}
+		switch header.Typeflag {
+		case tar.TypeReg, tar.TypeRegA, tar.TypeDir:
+		default:
+			continue // links and devices have no content
+		}
+		entries = append(entries, entry{
+			name:    memberName(header.Name),
+			size:    header.Size,
+			modTime: header.ModTime,
+			dir:     header.Typeflag == tar.TypeDir,
+			posix: &client.POSIXAttrs{
+				UID:  header.Uid,
+				GID:  header.Gid,
+				Mode: header.FileInfo().Mode(),
+			},
+		})
+	}
+}
+
+// toContent - content of an entry named name
+func toContent(name string, e entry) *client.Content {
+	content := new(client.Content)
+	content.Name = name
+	content.Size = e.size
+	content.Time = e.modTime
+	content.Type = os.FileMode(0644)
+	content.POSIX = e.posix
+	if e.dir {
+		content.Size = 0
+		content.Type = os.ModeDir | 0755
+	}
+	return content
+}
+
+// stat - entry of member, folders not stored in archive are implied by member names
+func (c *archiveClient) stat() (entry, error) {
+	if c.member == "" {
+		fi, err := os.Stat(c.archive)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return entry{}, iodine.New(client.NotFound{Path: c.hostURL.String()}, nil)
+			}
+			return entry{}, iodine.New(err, nil)
+		}
+		return entry{modTime: fi.ModTime(), dir: true}, nil
+	}
+	entries, err := c.entries()
+	if err != nil {
+		return entry{}, iodine.New(err, nil)
+	}
+	for _, e := range entries {
+		if e.name == c.member {
+			return e, nil
+		}
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.name, c.member+"/") {
+			return entry{name: c.member, modTime: e.modTime, dir: true}, nil
+		}
+	}
+	return entry{}, iodine.New(client.NotFound{Path: c.hostURL.String()}, nil)
+}
+
+// Stat - get metadata of archive or a member, archive itself is a folder
+func (c *archiveClient) Stat() (*client.Content, error) {
+	e, err := c.stat()
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return toContent(c.urlPath(), e), nil
+}
+
+// List - list members, names follow the same rules as fs client
+func (c *archiveClient) List(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel)
+	go c.listInRoutine(contentCh, recursive)
+	return contentCh
+}
+
+func (c *archiveClient) listInRoutine(contentCh chan client.ContentOnChannel, recursive bool) {
+	defer close(contentCh)
+	e, err := c.stat()
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	if !e.dir {
+		contentCh <- client.ContentOnChannel{Content: toContent(c.urlPath(), e)}
+		return
+	}
+	entries, err := c.entries()
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	prefix := ""
+	if c.member != "" {
+		prefix = c.member + "/"
+	}
+	// recursive names are relative to the last folder of the listed path
+	root := c.urlPath()
+	stripPrefix := root[:strings.LastIndex(root, "/")+1]
+	if strings.HasSuffix(c.hostURL.Path, "/") {
+		stripPrefix = root + "/"
+	}
+
+	// folders implied by member names are listed once, before their members
+	listed := make(map[string]bool)
+	var names []string
+	byName := make(map[string]entry)
+	for _, e := range entries {
+		if e.name == "" || !strings.HasPrefix(e.name, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(e.name, prefix)
+		elements := strings.Split(rest, "/")
+		if !recursive {
+			if len(elements) > 1 {
+				e = entry{name: prefix + elements[0], modTime: e.modTime, dir: true}
+			}
+			elements = elements[:1]
+		}
+		for i := range elements {
+			name := prefix + strings.Join(elements[:i+1], "/")
+			if listed[name] {
+				continue
+			}
+			listed[name] = true
+			names = append(names, name)
+			if i == len(elements)-1 {
+				byName[name] = e
+			} else {
+				byName[name] = entry{name: name, modTime: e.modTime, dir: true}
+			}
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		listName := strings.TrimPrefix(name, prefix)
+		if recursive {
+			listName = strings.TrimPrefix(root+"/"+strings.TrimPrefix(name, c.member+"/"), stripPrefix)
+			if c.member == "" {
+				listName = strings.TrimPrefix(root+"/"+name, stripPrefix)
+			}
+		}
+		contentCh <- client.ContentOnChannel{Content: toContent(listName, byName[name])}
+	}
+}
+
+// memberReader - member data, closer releases the archive
+type memberReader struct {
+	io.Reader
+	io.Closer
+}
+
+// openMember - reader positioned at start of member data
+func (c *archiveClient) openMember() (io.ReadCloser, error) {
+	if c.format == formatZip {
+		zr, err := c.openZip()
+		if err != nil {
+			return nil, iodine.New(err, nil)
+		}
+		for _, member := range zr.File {
+			if memberName(member.Name) != c.member || member.Mode().IsDir() {
+				continue
+			}
+			reader, err := member.Open()
+			if err != nil {
+				zr.Close()
+				return nil, iodine.New(err, nil)
+			}
+			return memberReader{reader, archiveReader{closers: []io.Closer{zr, reader}}}, nil
+		}
+		zr.Close()
+		return nil, iodine.New(client.NotFound{Path: c.hostURL.String()}, nil)
+	}
+	tr, err := c.openTar()
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			tr.Close()
+			return nil, iodine.New(client.NotFound{Path: c.hostURL.String()}, nil)
+		}
+		if err != nil {
+			tr.Close()
+			return nil, iodine.New(err, nil)
+		}
+		if memberName(header.Name) == c.member && (header.Typeflag == tar.TypeReg || header.Typeflag == tar.TypeRegA) {
+			return memberReader{tr.Reader, tr}, nil
+		}
+	}
+}
+
+// GetObject - read a full or part of archive member, archive is scanned up to the member
+func (c *archiveClient) GetObject(offset, length int64) (io.ReadCloser, int64, error) {
+	e, err := c.stat()
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	if e.dir {
+		return nil, length, iodine.New(client.ISFolder{Path: c.hostURL.String()}, nil)
+	}
+	if offset < 0 || offset > e.size || length < 0 || offset+length > e.size {
+		return nil, length, iodine.New(client.InvalidRange{Offset: offset}, nil)
+	}
+	if length == 0 {
+		length = e.size - offset
+	}
+	reader, err := c.openMember()
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	if _, err := io.CopyN(ioutil.Discard, reader, offset); err != nil {
+		reader.Close()
+		return nil, length, iodine.New(err, nil)
+	}
+	return memberReader{io.LimitReader(reader, length), reader}, length, nil
+}
+
+// SetBucketACL - archives have no permissions of their own
+func (c *archiveClient) SetBucketACL(acl string) error {
+	return iodine.New(client.APINotImplemented{API: "SetBucketACL"}, nil)
+}
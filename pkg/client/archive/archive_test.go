@@ -0,0 +1,202 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+var members = map[string]string{
+	"www/index.html":     "<html></html>",
+	"www/css/style.css":  "body {}",
+	"README":             "Hello, World",
+	"../escaped/members": "kept inside",
+}
+
+// writeTar - tar archive of members, gzip compressed if compress is set
+func writeTar(c *C, filename string, compress bool) {
+	file, err := os.Create(filename)
+	c.Assert(err, IsNil)
+	defer file.Close()
+	var writer io.Writer = file
+	if compress {
+		gz := gzip.NewWriter(file)
+		defer gz.Close()
+		writer = gz
+	}
+	tw := tar.NewWriter(writer)
+	c.Assert(tw.WriteHeader(&tar.Header{Name: "www/", Mode: 0755, Typeflag: tar.TypeDir, ModTime: time.Now()}), IsNil)
+	for name, data := range members {
+		c.Assert(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}), IsNil)
+		_, err := tw.Write([]byte(data))
+		c.Assert(err, IsNil)
+	}
+	c.Assert(tw.Close(), IsNil)
+}
+
+// writeZip - zip archive of members
+func writeZip(c *C, filename string) {
+	file, err := os.Create(filename)
+	c.Assert(err, IsNil)
+	defer file.Close()
+	zw := zip.NewWriter(file)
+	for name, data := range members {
+		w, err := zw.Create(name)
+		c.Assert(err, IsNil)
+		_, err = w.Write([]byte(data))
+		c.Assert(err, IsNil)
+	}
+	c.Assert(zw.Close(), IsNil)
+}
+
+func newClient(c *C, urlStr string) client.Client {
+	clnt, err := New(urlStr)
+	c.Assert(err, IsNil)
+	return clnt
+}
+
+func readAll(c *C, clnt client.Client, offset, length int64) string {
+	reader, _, err := clnt.GetObject(offset, length)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(reader.Close(), IsNil)
+	return string(data)
+}
+
+func list(c *C, clnt client.Client, recursive bool) map[string]bool {
+	names := make(map[string]bool)
+	for content := range clnt.List(recursive) {
+		c.Assert(content.Err, IsNil)
+		names[content.Content.Name+map[bool]string{true: "/"}[content.Content.Type.IsDir()]] = true
+	}
+	return names
+}
+
+func (s *MySuite) TestNew(c *C) {
+	_, err := New("tar://backups/site")
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidQueryURL{})
+	_, err = New("https://example.com/site.tar")
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidQueryURL{})
+
+	clnt, err := New("tar://backups/site.tar.gz/www/index.html")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.URL().String(), Equals, "tar://backups/site.tar.gz/www/index.html")
+	c.Assert(clnt.(*archiveClient).archive, Equals, filepath.FromSlash("backups/site.tar.gz"))
+	c.Assert(clnt.(*archiveClient).member, Equals, "www/index.html")
+}
+
+func (s *MySuite) TestReadArchives(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "archive-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	writeTar(c, filepath.Join(root, "site.tar"), false)
+	writeTar(c, filepath.Join(root, "site.tgz"), true)
+	writeZip(c, filepath.Join(root, "site.zip"))
+
+	for _, name := range []string{"site.tar", "site.tgz", "site.zip"} {
+		archiveURL := "tar://" + filepath.ToSlash(root) + "/" + name
+
+		content, err := newClient(c, archiveURL).Stat()
+		c.Assert(err, IsNil)
+		c.Assert(content.Type.IsDir(), Equals, true)
+
+		content, err = newClient(c, archiveURL+"/www").Stat()
+		c.Assert(err, IsNil)
+		c.Assert(content.Type.IsDir(), Equals, true)
+
+		content, err = newClient(c, archiveURL+"/www/css/style.css").Stat()
+		c.Assert(err, IsNil)
+		c.Assert(content.Type.IsRegular(), Equals, true)
+		c.Assert(content.Size, Equals, int64(len("body {}")))
+
+		_, err = newClient(c, archiveURL+"/www/missing").Stat()
+		c.Assert(iodine.ToError(err), FitsTypeOf, client.NotFound{})
+
+		c.Assert(list(c, newClient(c, archiveURL), false), DeepEquals,
+			map[string]bool{"www/": true, "README": true, "escaped/": true})
+		c.Assert(list(c, newClient(c, archiveURL+"/www"), true), DeepEquals,
+			map[string]bool{"www/index.html": true, "www/css/": true, "www/css/style.css": true})
+		c.Assert(list(c, newClient(c, archiveURL+"/www/"), true), DeepEquals,
+			map[string]bool{"index.html": true, "css/": true, "css/style.css": true})
+
+		clnt := newClient(c, archiveURL+"/README")
+		c.Assert(readAll(c, clnt, 0, 0), Equals, "Hello, World")
+		c.Assert(readAll(c, clnt, 7, 5), Equals, "World")
+		_, _, err = clnt.GetObject(7, 10)
+		c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidRange{})
+		c.Assert(readAll(c, newClient(c, archiveURL+"/escaped/members"), 0, 0), Equals, "kept inside")
+
+		_, _, err = newClient(c, archiveURL+"/www").GetObject(0, 0)
+		c.Assert(iodine.ToError(err), FitsTypeOf, client.ISFolder{})
+	}
+
+	// compressed archives cannot be appended to
+	err = newClient(c, "tar://"+filepath.ToSlash(root)+"/site.zip/new").PutObject(5, bytes.NewReader([]byte("hello")))
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.APINotImplemented{})
+}
+
+func (s *MySuite) TestAppendArchive(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "archive-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	archiveURL := "tar://" + filepath.ToSlash(root) + "/backups/new.tar"
+
+	c.Assert(newClient(c, archiveURL).MakeBucket(), IsNil)
+	content, err := newClient(c, archiveURL).Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Type.IsDir(), Equals, true)
+	c.Assert(list(c, newClient(c, archiveURL), true), DeepEquals, map[string]bool{})
+
+	err = newClient(c, archiveURL+"/a/first").PutObject(5, bytes.NewReader([]byte("hello")))
+	c.Assert(err, IsNil)
+	// size 0 members are buffered to learn their size
+	err = newClient(c, archiveURL+"/second").PutObject(0, bytes.NewReader(bytes.Repeat([]byte("x"), 1000)))
+	c.Assert(err, IsNil)
+	// failed copies leave the archive readable
+	err = newClient(c, archiveURL+"/short").PutObject(10, bytes.NewReader([]byte("abc")))
+	c.Assert(err, Not(IsNil))
+
+	c.Assert(readAll(c, newClient(c, archiveURL+"/a/first"), 0, 0), Equals, "hello")
+	c.Assert(readAll(c, newClient(c, archiveURL+"/second"), 998, 2), Equals, "xx")
+	c.Assert(list(c, newClient(c, archiveURL+"/"), true), DeepEquals,
+		map[string]bool{"a/": true, "a/first": true, "second": true})
+
+	// archive is created on first member
+	err = newClient(c, "tar://"+filepath.ToSlash(root)+"/other.tar/member").PutObject(5, bytes.NewReader([]byte("hello")))
+	c.Assert(err, IsNil)
+	c.Assert(readAll(c, newClient(c, "tar://"+filepath.ToSlash(root)+"/other.tar/member"), 0, 0), Equals, "hello")
+}
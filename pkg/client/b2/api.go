@@ -0,0 +1,254 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package b2
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+/// Minimal B2 native API, see https://www.backblaze.com/b2/docs/
+
+// authorization - b2_authorize_account response
+type authorization struct {
+	AccountID               string `json:"accountId"`
+	AuthorizationToken      string `json:"authorizationToken"`
+	APIURL                  string `json:"apiUrl"`
+	DownloadURL             string `json:"downloadUrl"`
+	RecommendedPartSize     int64  `json:"recommendedPartSize"`
+	AbsoluteMinimumPartSize int64  `json:"absoluteMinimumPartSize"`
+}
+
+type bucketResource struct {
+	BucketID   string `json:"bucketId"`
+	BucketName string `json:"bucketName"`
+	BucketType string `json:"bucketType"`
+}
+
+type bucketList struct {
+	Buckets []bucketResource `json:"buckets"`
+}
+
+// fileResource - file entry returned by b2_list_file_names, action is either "upload" or "folder"
+type fileResource struct {
+	FileID          string `json:"fileId"`
+	FileName        string `json:"fileName"`
+	Action          string `json:"action"`
+	Size            int64  `json:"size"`
+	ContentLength   int64  `json:"contentLength"`
+	ContentSha1     string `json:"contentSha1"`
+	UploadTimestamp int64  `json:"uploadTimestamp"`
+}
+
+type fileList struct {
+	Files        []fileResource `json:"files"`
+	NextFileName *string        `json:"nextFileName"`
+}
+
+type uploadURL struct {
+	UploadURL          string `json:"uploadUrl"`
+	AuthorizationToken string `json:"authorizationToken"`
+}
+
+// authorize - b2_authorize_account, authorization is cached for the lifetime of the client
+func (c *b2Client) authorize(force bool) (*authorization, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.auth != nil && !force {
+		return c.auth, nil
+	}
+	req, err := http.NewRequest("GET", c.endpoint+"/b2api/v1/b2_authorize_account", nil)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	req.SetBasicAuth(c.accountID, c.applicationKey)
+	req.Header.Set("User-Agent", c.userAgent)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, iodine.New(toErrorResponse(res), nil)
+	}
+	auth := new(authorization)
+	if err := json.NewDecoder(res.Body).Decode(auth); err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	c.auth = auth
+	return c.auth, nil
+}
+
+// call posts a JSON request to an API operation, an expired token is refreshed once
+func (c *b2Client) call(operation string, body interface{}, v interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	for retry := false; ; retry = true {
+		auth, err := c.authorize(retry)
+		if err != nil {
+			return iodine.New(err, nil)
+		}
+		req, err := http.NewRequest("POST", auth.APIURL+"/b2api/v1/"+operation, bytes.NewReader(data))
+		if err != nil {
+			return iodine.New(err, nil)
+		}
+		req.Header.Set("Authorization", auth.AuthorizationToken)
+		req.Header.Set("User-Agent", c.userAgent)
+		res, err := c.httpClient.Do(req)
+		if err != nil {
+			return iodine.New(err, nil)
+		}
+		if res.StatusCode != http.StatusOK {
+			errResponse := toErrorResponse(res)
+			res.Body.Close()
+			if errResponse.Code == "expired_auth_token" && !retry {
+				continue
+			}
+			return iodine.New(errResponse, nil)
+		}
+		defer res.Body.Close()
+		if v == nil {
+			return nil
+		}
+		return iodine.New(json.NewDecoder(res.Body).Decode(v), nil)
+	}
+}
+
+// getBucket finds bucket id by its name
+func (c *b2Client) getBucket(bucketName string) (bucketResource, error) {
+	auth, err := c.authorize(false)
+	if err != nil {
+		return bucketResource{}, iodine.New(err, nil)
+	}
+	var list bucketList
+	if err := c.call("b2_list_buckets", map[string]string{"accountId": auth.AccountID}, &list); err != nil {
+		return bucketResource{}, iodine.New(err, nil)
+	}
+	for _, bucket := range list.Buckets {
+		if bucket.BucketName == bucketName {
+			return bucket, nil
+		}
+	}
+	return bucketResource{}, iodine.New(BucketNotFound{Bucket: bucketName}, nil)
+}
+
+// encodeFileName - percent encode file name, slashes are kept as is
+func encodeFileName(name string) string {
+	encoded := strings.Replace(url.QueryEscape(name), "+", "%20", -1)
+	return strings.Replace(encoded, "%2F", "/", -1)
+}
+
+// sha1SuffixReader streams data and appends its hex SHA1 at the end, used with "hex_digits_at_end"
+type sha1SuffixReader struct {
+	reader io.Reader
+	hash   hash.Hash
+	suffix *bytes.Reader
+}
+
+func newSHA1SuffixReader(reader io.Reader) *sha1SuffixReader {
+	h := sha1.New()
+	return &sha1SuffixReader{reader: io.TeeReader(reader, h), hash: h}
+}
+
+func (r *sha1SuffixReader) Read(p []byte) (int, error) {
+	if r.suffix == nil {
+		n, err := r.reader.Read(p)
+		if err != io.EOF {
+			return n, err
+		}
+		r.suffix = bytes.NewReader([]byte(r.Sum()))
+		if n > 0 {
+			return n, nil
+		}
+	}
+	return r.suffix.Read(p)
+}
+
+// Sum returns hex SHA1 of data read so far
+func (r *sha1SuffixReader) Sum() string {
+	return hex.EncodeToString(r.hash.Sum(nil))
+}
+
+// upload posts data of known size to an upload URL, SHA1 is computed on the fly
+func (c *b2Client) upload(target uploadURL, size int64, data io.Reader, header map[string]string) (string, error) {
+	body := newSHA1SuffixReader(io.LimitReader(data, size))
+	req, err := http.NewRequest("POST", target.UploadURL, body)
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	req.ContentLength = size + sha1.Size*2
+	req.Header.Set("Authorization", target.AuthorizationToken)
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("X-Bz-Content-Sha1", "hex_digits_at_end")
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", iodine.New(toErrorResponse(res), nil)
+	}
+	return body.Sum(), nil
+}
+
+// uploadFile - b2_upload_file for files up to one part size
+func (c *b2Client) uploadFile(bucketID, fileName string, size int64, data io.Reader, metadata map[string]string) error {
+	var target uploadURL
+	if err := c.call("b2_get_upload_url", map[string]string{"bucketId": bucketID}, &target); err != nil {
+		return iodine.New(err, nil)
+	}
+	header := map[string]string{
+		"X-Bz-File-Name": encodeFileName(fileName),
+		"Content-Type":   contentType(metadata),
+	}
+	for key, value := range fileInfo(metadata) {
+		header["X-Bz-Info-"+key] = encodeFileName(value)
+	}
+	_, err := c.upload(target, size, data, header)
+	return iodine.New(err, nil)
+}
+
+// contentType - content type from metadata, B2 detects it from the file name otherwise
+func contentType(metadata map[string]string) string {
+	if contentType := metadata["Content-Type"]; contentType != "" {
+		return contentType
+	}
+	return "b2/x-auto"
+}
+
+// fileInfo - custom file info stored with the file, B2 serves b2-cache-control as Cache-Control on download
+func fileInfo(metadata map[string]string) map[string]string {
+	info := make(map[string]string)
+	if cacheControl := metadata["Cache-Control"]; cacheControl != "" {
+		info["b2-cache-control"] = cacheControl
+	}
+	return info
+}
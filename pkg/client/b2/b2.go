@@ -0,0 +1,437 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package b2
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Config - account id and application key are used as access and secret keys
+type Config struct {
+	AccountID      string
+	ApplicationKey string
+	HostURL        string
+	AppName        string
+	AppVersion     string
+	AppComments    []string
+	Debug          bool
+}
+
+type b2Client struct {
+	hostURL        *client.URL
+	endpoint       string
+	accountID      string
+	applicationKey string
+	userAgent      string
+	httpClient     *http.Client
+
+	mutex *sync.Mutex
+	auth  *authorization
+}
+
+// New returns an initialized b2Client structure. if debug use a internal trace transport
+func New(config *Config) (client.Client, error) {
+	u, err := client.Parse(config.HostURL)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	var transport http.RoundTripper
+	switch {
+	case config.Debug == true:
+		transport = s3.GetNewTraceTransport(s3.NewTrace(), http.DefaultTransport)
+	default:
+		transport = s3.GetNewLogTransport(http.DefaultTransport)
+	}
+	userAgent := config.AppName + "/" + config.AppVersion
+	if len(config.AppComments) > 0 {
+		userAgent = userAgent + " (" + strings.Join(config.AppComments, "; ") + ")"
+	}
+	return &b2Client{
+		hostURL:        u,
+		endpoint:       u.Scheme + "://" + u.Host,
+		accountID:      config.AccountID,
+		applicationKey: config.ApplicationKey,
+		userAgent:      userAgent,
+		httpClient:     &http.Client{Transport: transport},
+		mutex:          new(sync.Mutex),
+	}, nil
+}
+
+// URL get url
+func (c *b2Client) URL() *client.URL {
+	return c.hostURL
+}
+
+// url2BucketAndObject gives bucketName and objectName from URL path
+func (c *b2Client) url2BucketAndObject() (bucketName, objectName string) {
+	splits := strings.SplitN(c.hostURL.Path, string(c.hostURL.Separator), 3)
+	switch len(splits) {
+	case 0, 1:
+		bucketName = ""
+		objectName = ""
+	case 2:
+		bucketName = splits[1]
+		objectName = ""
+	case 3:
+		bucketName = splits[1]
+		objectName = splits[2]
+	}
+	return bucketName, objectName
+}
+
+// fileContent converts file resource into client Content
+func fileContent(name string, file fileResource) *client.Content {
+	content := new(client.Content)
+	content.Name = name
+	if file.Action == "folder" {
+		content.Time = time.Now()
+		content.Type = os.ModeDir
+		return content
+	}
+	content.Time = time.Unix(0, file.UploadTimestamp*int64(time.Millisecond))
+	content.Size = file.Size
+	if file.ContentLength > content.Size {
+		content.Size = file.ContentLength
+	}
+	content.Type = os.FileMode(0664)
+	// Large files do not have a whole file SHA1
+	if file.ContentSha1 != "none" {
+		content.ETag = file.ContentSha1
+	}
+	return content
+}
+
+// listFileNames lists one page of file names, prefix and delimiter are optional
+func (c *b2Client) listFileNames(bucketID, startFileName, prefix, delimiter string, maxFileCount int) (fileList, error) {
+	request := map[string]interface{}{
+		"bucketId":     bucketID,
+		"maxFileCount": maxFileCount,
+	}
+	if startFileName != "" {
+		request["startFileName"] = startFileName
+	}
+	if prefix != "" {
+		request["prefix"] = prefix
+	}
+	if delimiter != "" {
+		request["delimiter"] = delimiter
+	}
+	var list fileList
+	err := c.call("b2_list_file_names", request, &list)
+	return list, iodine.New(err, nil)
+}
+
+// statFile finds the latest version of a file by its exact name
+func (c *b2Client) statFile(bucketID, fileName string) (fileResource, error) {
+	list, err := c.listFileNames(bucketID, fileName, "", "", 1)
+	if err != nil {
+		return fileResource{}, iodine.New(err, nil)
+	}
+	if len(list.Files) == 0 || list.Files[0].FileName != fileName || list.Files[0].Action != "upload" {
+		return fileResource{}, iodine.New(client.ObjectNotFound{Object: fileName}, nil)
+	}
+	return list.Files[0], nil
+}
+
+// GetObject - get object
+func (c *b2Client) GetObject(offset, length int64) (io.ReadCloser, int64, error) {
+	bucket, object := c.url2BucketAndObject()
+	bucketResource, err := c.getBucket(bucket)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	file, err := c.statFile(bucketResource.BucketID, object)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	size := fileContent(object, file).Size
+	if offset < 0 || offset > size || length < 0 || offset+length > size {
+		return nil, length, iodine.New(client.InvalidRange{Offset: offset}, nil)
+	}
+	if length == 0 {
+		length = size - offset
+	}
+	auth, err := c.authorize(false)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	req, err := http.NewRequest("GET", auth.DownloadURL+"/file/"+bucket+"/"+encodeFileName(object), nil)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	req.Header.Set("Authorization", auth.AuthorizationToken)
+	req.Header.Set("User-Agent", c.userAgent)
+	if offset > 0 || length < size {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+length-1, 10))
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusPartialContent {
+		defer res.Body.Close()
+		return nil, length, iodine.New(toErrorResponse(res), nil)
+	}
+	return res.Body, length, nil
+}
+
+// PutObject - put object, files larger than a part are uploaded as B2 large files
+func (c *b2Client) PutObject(size int64, data io.Reader) error {
+	return c.PutObjectWithMetadata(size, data, nil)
+}
+
+// PutObjectWithMetadata - put object with Content-Type, Cache-Control is kept as b2-cache-control file info
+func (c *b2Client) PutObjectWithMetadata(size int64, data io.Reader, metadata map[string]string) error {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object == "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	bucketResource, err := c.getBucket(bucket)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	// size could be 0 for virtual files, B2 needs content length upfront
+	if size == 0 {
+		buffer, err := ioutil.ReadAll(data)
+		if err != nil {
+			return iodine.New(err, nil)
+		}
+		return c.uploadFile(bucketResource.BucketID, object, int64(len(buffer)), bytes.NewReader(buffer), metadata)
+	}
+	auth, err := c.authorize(false)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if size > partSize(auth, size) {
+		return c.uploadLargeFile(bucketResource.BucketID, object, size, data, metadata)
+	}
+	return c.uploadFile(bucketResource.BucketID, object, size, data, metadata)
+}
+
+// MakeBucket - make a new private bucket
+func (c *b2Client) MakeBucket() error {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	auth, err := c.authorize(false)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	err = c.call("b2_create_bucket", map[string]string{
+		"accountId":  auth.AccountID,
+		"bucketName": bucket,
+		"bucketType": "allPrivate",
+	}, nil)
+	if errResponse, ok := iodine.ToError(err).(ErrorResponse); ok && errResponse.Code == "duplicate_bucket_name" {
+		return iodine.New(client.BucketExists{Bucket: bucket}, nil)
+	}
+	return iodine.New(err, nil)
+}
+
+// SetBucketACL add canned acl's on a bucket, B2 only supports private and public read
+func (c *b2Client) SetBucketACL(acl string) error {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	var bucketType string
+	switch acl {
+	case "private":
+		bucketType = "allPrivate"
+	case "public-read":
+		bucketType = "allPublic"
+	default:
+		return iodine.New(client.InvalidACLType{ACL: acl}, nil)
+	}
+	bucketResource, err := c.getBucket(bucket)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	auth, err := c.authorize(false)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	return iodine.New(c.call("b2_update_bucket", map[string]string{
+		"accountId":  auth.AccountID,
+		"bucketId":   bucketResource.BucketID,
+		"bucketType": bucketType,
+	}, nil), nil)
+}
+
+// Stat - get metadata of a bucket or file
+func (c *b2Client) Stat() (*client.Content, error) {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" && object == "" {
+		// Verifies credentials
+		if _, err := c.authorize(false); err != nil {
+			return nil, iodine.New(err, nil)
+		}
+		return &client.Content{Type: os.ModeDir}, nil
+	}
+	bucketResource, err := c.getBucket(bucket)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	if object == "" {
+		return &client.Content{Name: bucket, Type: os.ModeDir}, nil
+	}
+	file, err := c.statFile(bucketResource.BucketID, object)
+	if err == nil {
+		return fileContent(file.FileName, file), nil
+	}
+	// Not a file, it still is a directory if anything is stored under it
+	list, listErr := c.listFileNames(bucketResource.BucketID, "", strings.TrimSuffix(object, "/")+"/", "", 1)
+	if listErr != nil {
+		return nil, iodine.New(listErr, nil)
+	}
+	if len(list.Files) == 0 {
+		return nil, iodine.New(err, nil)
+	}
+	return &client.Content{Name: object, Time: time.Now(), Type: os.ModeDir}, nil
+}
+
+/// Bucket API operations
+
+// List - list at delimited path, if not recursive
+func (c *b2Client) List(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel)
+	switch recursive {
+	case true:
+		go c.listRecursiveInRoutine(contentCh)
+	default:
+		go c.listInRoutine(contentCh)
+	}
+	return contentCh
+}
+
+// listBuckets lists all buckets of the account
+func (c *b2Client) listBuckets() ([]bucketResource, error) {
+	auth, err := c.authorize(false)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	var list bucketList
+	if err := c.call("b2_list_buckets", map[string]string{"accountId": auth.AccountID}, &list); err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return list.Buckets, nil
+}
+
+// listFiles sends all files under prefix on channel, named by nameFn
+func (c *b2Client) listFiles(contentCh chan client.ContentOnChannel, bucketID, prefix, delimiter string, nameFn func(string) string) bool {
+	startFileName := ""
+	for {
+		list, err := c.listFileNames(bucketID, startFileName, prefix, delimiter, 1000)
+		if err != nil {
+			contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+			return false
+		}
+		for _, file := range list.Files {
+			contentCh <- client.ContentOnChannel{Content: fileContent(nameFn(file.FileName), file)}
+		}
+		if list.NextFileName == nil || *list.NextFileName == "" {
+			return true
+		}
+		startFileName = *list.NextFileName
+	}
+}
+
+func (c *b2Client) listInRoutine(contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	b, o := c.url2BucketAndObject()
+	if b == "" && o == "" {
+		buckets, err := c.listBuckets()
+		if err != nil {
+			contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+			return
+		}
+		for _, bucket := range buckets {
+			contentCh <- client.ContentOnChannel{Content: &client.Content{Name: bucket.BucketName, Type: os.ModeDir}}
+		}
+		return
+	}
+	bucketResource, err := c.getBucket(b)
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	if o != "" {
+		if file, err := c.statFile(bucketResource.BucketID, o); err == nil {
+			contentCh <- client.ContentOnChannel{Content: fileContent(file.FileName, file)}
+			return
+		}
+	}
+	normalizedPrefix := strings.TrimSuffix(o, string(c.hostURL.Separator)) + string(c.hostURL.Separator)
+	c.listFiles(contentCh, bucketResource.BucketID, o, string(c.hostURL.Separator), func(key string) string {
+		if normalizedPrefix != key && strings.HasPrefix(key, normalizedPrefix) {
+			return strings.TrimPrefix(key, normalizedPrefix)
+		}
+		return key
+	})
+}
+
+func (c *b2Client) listRecursiveInRoutine(contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	b, o := c.url2BucketAndObject()
+	if b == "" && o == "" {
+		buckets, err := c.listBuckets()
+		if err != nil {
+			contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+			return
+		}
+		for _, bucket := range buckets {
+			ok := c.listFiles(contentCh, bucket.BucketID, "", "", func(key string) string {
+				return filepath.Join(bucket.BucketName, key)
+			})
+			if !ok {
+				return
+			}
+		}
+		return
+	}
+	bucketResource, err := c.getBucket(b)
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	// Names are relative to the parent of the last URL path element, same as s3 client
+	c.listFiles(contentCh, bucketResource.BucketID, o, "", func(key string) string {
+		switch {
+		case o == "":
+			if !strings.HasSuffix(c.hostURL.Path, string(c.hostURL.Separator)) {
+				return filepath.Join(b, key)
+			}
+		case strings.HasSuffix(o, string(c.hostURL.Separator)):
+			return strings.TrimPrefix(key, o)
+		}
+		return key
+	})
+}
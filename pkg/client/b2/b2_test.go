@@ -0,0 +1,301 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package b2
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+// b2Handler is an http.Handler that emulates B2 native API for a single account
+type b2Handler struct {
+	c        *C
+	server   *httptest.Server
+	partSize int64
+	files    map[string][]byte
+	parts    map[int][]byte
+	buckets  map[string]string
+}
+
+func (h *b2Handler) verifySha1(r *http.Request) []byte {
+	h.c.Assert(r.Header.Get("X-Bz-Content-Sha1"), Equals, "hex_digits_at_end")
+	body, err := ioutil.ReadAll(r.Body)
+	h.c.Assert(err, IsNil)
+	h.c.Assert(int64(len(body)), Equals, r.ContentLength)
+	data, sum := body[:len(body)-40], body[len(body)-40:]
+	expected := sha1.Sum(data)
+	h.c.Assert(string(sum), Equals, hex.EncodeToString(expected[:]))
+	return data
+}
+
+func (h *b2Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/b2api/v1/b2_authorize_account" {
+		accountID, key, ok := r.BasicAuth()
+		if !ok || accountID != "account" || key != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":401,"code":"unauthorized","message":""}`))
+			return
+		}
+		fmt.Fprintf(w, `{"accountId":"account","authorizationToken":"token","apiUrl":"%s","downloadUrl":"%s","recommendedPartSize":%d,"absoluteMinimumPartSize":%d}`,
+			h.server.URL, h.server.URL, h.partSize, h.partSize)
+		return
+	}
+	var request map[string]interface{}
+	if strings.HasPrefix(r.URL.Path, "/b2api/") {
+		h.c.Assert(r.Header.Get("Authorization"), Equals, "token")
+		h.c.Assert(json.NewDecoder(r.Body).Decode(&request), IsNil)
+	}
+	fileResponse := func(name string) string {
+		return fmt.Sprintf(`{"fileId":"id-%s","fileName":"%s","action":"upload","size":%d,"contentSha1":"none","uploadTimestamp":1432232661097}`,
+			name, name, len(h.files[name]))
+	}
+	switch r.URL.Path {
+	case "/b2api/v1/b2_list_buckets":
+		var buckets []string
+		for name, bucketType := range h.buckets {
+			buckets = append(buckets, fmt.Sprintf(`{"bucketId":"id-%s","bucketName":"%s","bucketType":"%s"}`, name, name, bucketType))
+		}
+		fmt.Fprintf(w, `{"buckets":[%s]}`, strings.Join(buckets, ","))
+	case "/b2api/v1/b2_create_bucket":
+		name := request["bucketName"].(string)
+		if _, ok := h.buckets[name]; ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":400,"code":"duplicate_bucket_name","message":"Bucket name is already in use."}`))
+			return
+		}
+		h.buckets[name] = request["bucketType"].(string)
+		w.Write([]byte(`{}`))
+	case "/b2api/v1/b2_update_bucket":
+		h.buckets[strings.TrimPrefix(request["bucketId"].(string), "id-")] = request["bucketType"].(string)
+		w.Write([]byte(`{}`))
+	case "/b2api/v1/b2_list_file_names":
+		var names []string
+		for name := range h.files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		prefix, _ := request["prefix"].(string)
+		delimiter, _ := request["delimiter"].(string)
+		start, _ := request["startFileName"].(string)
+		var files []string
+		seen := make(map[string]bool)
+		for _, name := range names {
+			if name < start || !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			rest := strings.TrimPrefix(name, prefix)
+			if delimiter != "" && strings.Contains(rest, delimiter) {
+				folder := prefix + rest[:strings.Index(rest, delimiter)+1]
+				if !seen[folder] {
+					seen[folder] = true
+					files = append(files, fmt.Sprintf(`{"fileName":"%s","action":"folder","size":0,"uploadTimestamp":0}`, folder))
+				}
+				continue
+			}
+			files = append(files, fileResponse(name))
+		}
+		if max := int(request["maxFileCount"].(float64)); len(files) > max {
+			files = files[:max]
+		}
+		fmt.Fprintf(w, `{"files":[%s],"nextFileName":null}`, strings.Join(files, ","))
+	case "/b2api/v1/b2_get_upload_url":
+		fmt.Fprintf(w, `{"uploadUrl":"%s/upload","authorizationToken":"upload-token"}`, h.server.URL)
+	case "/upload":
+		h.c.Assert(r.Header.Get("Authorization"), Equals, "upload-token")
+		name, err := url.QueryUnescape(r.Header.Get("X-Bz-File-Name"))
+		h.c.Assert(err, IsNil)
+		h.files[name] = h.verifySha1(r)
+		w.Write([]byte(fileResponse(name)))
+	case "/b2api/v1/b2_start_large_file":
+		h.parts = make(map[int][]byte)
+		fmt.Fprintf(w, `{"fileId":"large-%s"}`, request["fileName"].(string))
+	case "/b2api/v1/b2_get_upload_part_url":
+		fmt.Fprintf(w, `{"uploadUrl":"%s/upload-part","authorizationToken":"part-token"}`, h.server.URL)
+	case "/upload-part":
+		h.c.Assert(r.Header.Get("Authorization"), Equals, "part-token")
+		partNumber, err := strconv.Atoi(r.Header.Get("X-Bz-Part-Number"))
+		h.c.Assert(err, IsNil)
+		h.parts[partNumber] = h.verifySha1(r)
+		w.Write([]byte(`{}`))
+	case "/b2api/v1/b2_finish_large_file":
+		var data []byte
+		sums := request["partSha1Array"].([]interface{})
+		h.c.Assert(sums, HasLen, len(h.parts))
+		for i := range sums {
+			sum := sha1.Sum(h.parts[i+1])
+			h.c.Assert(sums[i], Equals, hex.EncodeToString(sum[:]))
+			data = append(data, h.parts[i+1]...)
+		}
+		h.files[strings.TrimPrefix(request["fileId"].(string), "large-")] = data
+		w.Write([]byte(`{}`))
+	default:
+		if !strings.HasPrefix(r.URL.Path, "/file/bucket/") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data := h.files[strings.TrimPrefix(r.URL.Path, "/file/bucket/")]
+		if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+			var start, end int
+			_, err := fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end)
+			h.c.Assert(err, IsNil)
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(data[start : end+1])
+			return
+		}
+		w.Write(data)
+	}
+}
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+func newTestServer(c *C, partSize int64) (*b2Handler, *httptest.Server) {
+	handler := &b2Handler{
+		c:        c,
+		partSize: partSize,
+		files:    make(map[string][]byte),
+		buckets:  make(map[string]string),
+	}
+	handler.server = httptest.NewServer(handler)
+	return handler, handler.server
+}
+
+func newTestClient(c *C, server *httptest.Server, path string) *b2Client {
+	conf := new(Config)
+	conf.AccountID = "account"
+	conf.ApplicationKey = "key"
+	conf.HostURL = server.URL + path
+	clnt, err := New(conf)
+	c.Assert(err, IsNil)
+	return clnt.(*b2Client)
+}
+
+func (s *MySuite) TestBucketOperations(c *C) {
+	handler, server := newTestServer(c, 100)
+	defer server.Close()
+
+	b2c := newTestClient(c, server, "/bucket")
+	c.Assert(b2c.MakeBucket(), IsNil)
+	c.Assert(b2c.MakeBucket(), Not(IsNil))
+	c.Assert(handler.buckets["bucket"], Equals, "allPrivate")
+
+	c.Assert(b2c.SetBucketACL("public-read"), IsNil)
+	c.Assert(handler.buckets["bucket"], Equals, "allPublic")
+	c.Assert(b2c.SetBucketACL("public-read-write"), Not(IsNil))
+
+	content, err := b2c.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Type.IsDir(), Equals, true)
+
+	handler.files["dir/object"] = []byte("hello")
+	for content := range b2c.List(false) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.Name, Equals, "dir/")
+		c.Assert(content.Content.Type.IsDir(), Equals, true)
+	}
+	for content := range b2c.List(true) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.Name, Equals, "bucket/dir/object")
+		c.Assert(content.Content.Size, Equals, int64(5))
+	}
+
+	b2c = newTestClient(c, server, "/")
+	for content := range b2c.List(false) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.Name, Equals, "bucket")
+	}
+
+	conf := new(Config)
+	conf.AccountID = "account"
+	conf.ApplicationKey = "invalid"
+	conf.HostURL = server.URL
+	clnt, err := New(conf)
+	c.Assert(err, IsNil)
+	_, err = clnt.Stat()
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *MySuite) TestObjectOperations(c *C) {
+	handler, server := newTestServer(c, 5)
+	defer server.Close()
+	handler.buckets["bucket"] = "allPrivate"
+
+	// smaller than a part
+	data := "Hello"
+	b2c := newTestClient(c, server, "/bucket/dir/small object")
+	c.Assert(b2c.PutObject(int64(len(data)), strings.NewReader(data)), IsNil)
+	c.Assert(string(handler.files["dir/small object"]), Equals, data)
+
+	// large file in three parts
+	data = "Hello World!"
+	b2c = newTestClient(c, server, "/bucket/dir/object")
+	c.Assert(b2c.PutObject(int64(len(data)), strings.NewReader(data)), IsNil)
+	c.Assert(handler.parts, HasLen, 3)
+	c.Assert(string(handler.files["dir/object"]), Equals, data)
+
+	content, err := b2c.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Name, Equals, "dir/object")
+	c.Assert(content.Size, Equals, int64(len(data)))
+	c.Assert(content.ETag, Equals, "")
+	c.Assert(content.Type.IsRegular(), Equals, true)
+
+	reader, size, err := b2c.GetObject(0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(len(data)))
+	result, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(string(result), Equals, data)
+
+	reader, size, err = b2c.GetObject(6, 5)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(5))
+	result, err = ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(string(result), Equals, "World")
+
+	b2c = newTestClient(c, server, "/bucket/dir")
+	content, err = b2c.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Type.IsDir(), Equals, true)
+
+	b2c = newTestClient(c, server, "/bucket/missing")
+	_, err = b2c.Stat()
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *MySuite) TestPartSize(c *C) {
+	auth := &authorization{RecommendedPartSize: 100, AbsoluteMinimumPartSize: 5}
+	c.Assert(partSize(auth, 1000), Equals, int64(100))
+	c.Assert(partSize(auth, 100*maxParts), Equals, int64(200))
+	c.Assert(partSize(&authorization{}, 1000), Equals, int64(100*1024*1024))
+}
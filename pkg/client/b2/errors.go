@@ -0,0 +1,56 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package b2
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// ErrorResponse - error returned by B2 native API
+type ErrorResponse struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e ErrorResponse) Error() string {
+	return "Backblaze B2 error " + strconv.Itoa(e.Status) + " " + e.Code + ": " + e.Message
+}
+
+// BucketNotFound - bucket name is not known to the account
+type BucketNotFound struct {
+	Bucket string
+}
+
+func (e BucketNotFound) Error() string {
+	return "Bucket ‘" + e.Bucket + "’ not found"
+}
+
+// toErrorResponse decodes B2 error body, falls back to HTTP status
+func toErrorResponse(res *http.Response) ErrorResponse {
+	errResponse := ErrorResponse{}
+	if err := json.NewDecoder(res.Body).Decode(&errResponse); err != nil || errResponse.Code == "" {
+		return ErrorResponse{
+			Status:  res.StatusCode,
+			Code:    "unknown",
+			Message: http.StatusText(res.StatusCode),
+		}
+	}
+	return errResponse
+}
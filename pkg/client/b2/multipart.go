@@ -0,0 +1,91 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package b2
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// B2 allows at most 10000 parts in a large file
+const maxParts = 10000
+
+// partSize picks a part size within B2 limits for a large file of given size
+func partSize(auth *authorization, size int64) int64 {
+	partSize := auth.RecommendedPartSize
+	if partSize < auth.AbsoluteMinimumPartSize {
+		partSize = auth.AbsoluteMinimumPartSize
+	}
+	if partSize <= 0 {
+		partSize = 100 * 1024 * 1024
+	}
+	for size/partSize >= maxParts {
+		partSize = partSize * 2
+	}
+	return partSize
+}
+
+// uploadLargeFile uploads parts sequentially from a single stream, the large file is cancelled on failure
+func (c *b2Client) uploadLargeFile(bucketID, fileName string, size int64, data io.Reader, metadata map[string]string) error {
+	auth, err := c.authorize(false)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	var file fileResource
+	err = c.call("b2_start_large_file", map[string]interface{}{
+		"bucketId":    bucketID,
+		"fileName":    fileName,
+		"contentType": contentType(metadata),
+		"fileInfo":    fileInfo(metadata),
+	}, &file)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if err := c.uploadParts(file.FileID, partSize(auth, size), size, data); err != nil {
+		c.call("b2_cancel_large_file", map[string]string{"fileId": file.FileID}, nil)
+		return iodine.New(err, nil)
+	}
+	return nil
+}
+
+func (c *b2Client) uploadParts(fileID string, partSize, size int64, data io.Reader) error {
+	var target uploadURL
+	if err := c.call("b2_get_upload_part_url", map[string]string{"fileId": fileID}, &target); err != nil {
+		return iodine.New(err, nil)
+	}
+	var partSha1Array []string
+	for partNumber, remaining := 1, size; remaining > 0; partNumber++ {
+		length := partSize
+		if remaining < length {
+			length = remaining
+		}
+		sum, err := c.upload(target, length, data, map[string]string{
+			"X-Bz-Part-Number": strconv.Itoa(partNumber),
+		})
+		if err != nil {
+			return iodine.New(err, map[string]string{"part": strconv.Itoa(partNumber)})
+		}
+		partSha1Array = append(partSha1Array, sum)
+		remaining -= length
+	}
+	return iodine.New(c.call("b2_finish_large_file", map[string]interface{}{
+		"fileId":        fileID,
+		"partSha1Array": partSha1Array,
+	}, nil), nil)
+}
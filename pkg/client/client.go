@@ -19,6 +19,7 @@ package client
 import (
 	"io"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -40,6 +41,403 @@ type Client interface {
 	URL() *URL
 }
 
+// ListOptions - filters of a listing, servers which can apply them send only the entries asked for.
+// Prefix is matched against names as List returns them, names are folders up to the first Delimiter
+// after Prefix, a recursive listing if Delimiter is empty. MaxKeys limits the entries sent, all if 0
+type ListOptions struct {
+	Prefix    string
+	Delimiter string
+	MaxKeys   int
+}
+
+// BucketOptions - options of a new bucket, an empty Region is the region of the endpoint. Buckets made with
+// ObjectLock can hold objects under legal hold or retention, it cannot be turned on later
+type BucketOptions struct {
+	Region     string
+	ObjectLock bool
+}
+
+// OptionsMaker - optional interface for clients which can make buckets with options
+type OptionsMaker interface {
+	MakeBucketWithOptions(options BucketOptions) error
+}
+
+// OptionsLister - optional interface for clients which filter listings on the server
+type OptionsLister interface {
+	ListWithOptions(options ListOptions) <-chan ContentOnChannel
+}
+
+// ListWithOptions - listing of c filtered by options, on the server for OptionsListers and
+// from a List of all entries otherwise
+func ListWithOptions(c Client, options ListOptions) <-chan ContentOnChannel {
+	if lister, ok := c.(OptionsLister); ok {
+		return lister.ListWithOptions(options)
+	}
+	separator := string(c.URL().Separator)
+	// other delimiters than the separator group names of a recursive listing
+	recursive := options.Delimiter != separator
+	contentCh := make(chan ContentOnChannel)
+	go func() {
+		defer close(contentCh)
+		folders := make(map[string]bool)
+		sent := 0
+		entryCh := c.List(recursive)
+		for entry := range entryCh {
+			if entry.Err != nil {
+				contentCh <- entry
+				return
+			}
+			name := entry.Content.Name
+			if !strings.HasPrefix(name, options.Prefix) {
+				continue
+			}
+			if recursive && options.Delimiter != "" {
+				if i := strings.Index(name[len(options.Prefix):], options.Delimiter); i >= 0 {
+					folder := name[:len(options.Prefix)+i+len(options.Delimiter)]
+					if folders[folder] {
+						continue
+					}
+					folders[folder] = true
+					entry = ContentOnChannel{Content: &Content{Name: folder, Time: entry.Content.Time, Type: os.ModeDir}}
+				}
+			}
+			contentCh <- entry
+			sent++
+			if options.MaxKeys > 0 && sent == options.MaxKeys {
+				// the rest of the listing is drained in the background
+				go func() {
+					for range entryCh {
+					}
+				}()
+				return
+			}
+		}
+	}()
+	return contentCh
+}
+
+// MetadataPutter - optional interface for clients which can store object metadata along with the data,
+// metadata keys are canonical HTTP header names like "Content-Type" and "Cache-Control". Keys a backend
+// cannot store are ignored.
+type MetadataPutter interface {
+	PutObjectWithMetadata(size int64, data io.Reader, metadata map[string]string) error
+}
+
+// Server side encryption types
+const (
+	SSES3  = "SSE-S3"  // keys managed by the storage
+	SSEKMS = "SSE-KMS" // keys managed by a key management service
+	SSEC   = "SSE-C"   // keys provided by the client with every request
+)
+
+// SSE - server side encryption of an uploaded object
+type SSE struct {
+	Type  string
+	KeyID string // SSE-KMS key, default key of the account if empty
+	Key   []byte // SSE-C 256 bit key
+}
+
+// EncryptedPutter - optional interface for clients which can upload objects with server side encryption,
+// metadata is the same as for MetadataPutter
+type EncryptedPutter interface {
+	PutObjectWithEncryption(size int64, data io.Reader, metadata map[string]string, sse SSE) error
+}
+
+// BucketEncryption - optional interface for clients which can manage the default server side encryption of
+// the bucket of their URL, applied to objects uploaded without encryption of their own. Buckets can be
+// encrypted with SSE-S3 or SSE-KMS, a nil SSE stands for no default encryption
+type BucketEncryption interface {
+	GetBucketEncryption() (*SSE, error)
+	SetBucketEncryption(sse *SSE) error
+}
+
+// MetadataGetter - optional interface for clients which can read object metadata stored along with the data,
+// keys are the same as for MetadataPutter
+type MetadataGetter interface {
+	GetObjectMetadata() (map[string]string, error)
+}
+
+// Checksummer - optional interface for clients which can only tell the ETag of an object by reading it,
+// the ETag is the hex encoded MD5 of the data
+type Checksummer interface {
+	StatWithChecksum() (*Content, error)
+}
+
+// Tagger - optional interface for clients which can read tags set on an object, or on a bucket for bucket URLs
+type Tagger interface {
+	GetObjectTags() (map[string]string, error)
+}
+
+// TagSetter - optional interface for clients which can replace tags set on an object or bucket
+type TagSetter interface {
+	SetObjectTags(tags map[string]string) error
+}
+
+// TaggingMetadata - metadata key of MetadataPutter and EncryptedPutter carrying tags to set on the uploaded
+// object, encoded like "env=prod&tier=hot". Only clients which are TagSetter store it
+const TaggingMetadata = "X-Amz-Tagging"
+
+// TagRemover - optional interface for clients which can remove all tags set on an object or bucket
+type TagRemover interface {
+	RemoveObjectTags() error
+}
+
+// POSIXAttrsSetter - optional interface for clients which can restore ownership and permissions of a file
+type POSIXAttrsSetter interface {
+	SetPOSIXAttrs(attrs POSIXAttrs) error
+}
+
+// SignatureReporter - optional interface for clients which sign requests, reports the signature version and
+// region in use, auto detected ones after the first request
+type SignatureReporter interface {
+	Signature() (version, region string)
+}
+
+// Remover - optional interface for clients which can remove the object of their URL
+type Remover interface {
+	Remove() error
+}
+
+// BucketRemover - optional interface for clients which can remove the bucket or folder of their URL, it must
+// hold no objects
+type BucketRemover interface {
+	RemoveBucket() error
+}
+
+// IncompleteLister - optional interface for clients which can list uploads started but never completed,
+// Time of listed contents is when the upload started and Size the size of its uploaded parts
+type IncompleteLister interface {
+	ListIncomplete(recursive bool) <-chan ContentOnChannel
+}
+
+// VersionLister - optional interface for clients of versioned storage which can list all versions of objects
+// like ListIncomplete lists uploads, newest first for each object. Removed objects have a delete marker as
+// their latest version
+type VersionLister interface {
+	ListVersions(recursive bool) <-chan ContentOnChannel
+}
+
+// ListAt - objects as they were at time t, the version of each object current then. Objects created later
+// or removed by then are left out, folders are passed on as listed
+func ListAt(lister VersionLister, recursive bool, t time.Time) <-chan ContentOnChannel {
+	contentCh := make(chan ContentOnChannel)
+	go func() {
+		defer close(contentCh)
+		// versions of an object are listed together, newest first
+		var name string
+		found := false
+		for entry := range lister.ListVersions(recursive) {
+			if entry.Err != nil {
+				contentCh <- entry
+				return
+			}
+			content := entry.Content
+			if content.Type.IsDir() {
+				contentCh <- entry
+				continue
+			}
+			if content.Name != name {
+				name, found = content.Name, false
+			}
+			if found || content.Time.After(t) {
+				continue
+			}
+			found = true
+			if !content.IsDeleteMarker {
+				contentCh <- entry
+			}
+		}
+	}()
+	return contentCh
+}
+
+// Versioning - optional interface for clients of buckets which can keep versions of objects, status is
+// "Enabled", "Suspended" or empty for buckets which never had versioning enabled
+type Versioning interface {
+	GetVersioning() (status string, err error)
+	SetVersioning(status string) error
+}
+
+// LifecycleRule - a rule of the lifecycle configuration of a bucket for objects under Prefix. Days count from
+// the creation of an object, or from when it became noncurrent for NoncurrentExpirationDays, 0 leaves an
+// action out. Objects are transitioned only if TransitionStorageClass is set
+type LifecycleRule struct {
+	ID                       string `json:"id"`
+	Prefix                   string `json:"prefix"`
+	Enabled                  bool   `json:"enabled"`
+	ExpirationDays           int    `json:"expiration-days,omitempty"`
+	TransitionDays           int    `json:"transition-days,omitempty"`
+	TransitionStorageClass   string `json:"transition-storage-class,omitempty"`
+	NoncurrentExpirationDays int    `json:"noncurrent-expiration-days,omitempty"`
+}
+
+// Lifecycler - optional interface for clients which can manage the lifecycle configuration of the bucket of
+// their URL, setting no rules removes the configuration
+type Lifecycler interface {
+	GetLifecycle() ([]LifecycleRule, error)
+	SetLifecycle(rules []LifecycleRule) error
+}
+
+// NotificationConfig - events of objects whose names start with Prefix and end with Suffix which a bucket sends
+// to the target named by ARN, an SQS queue, SNS topic or Lambda function of AWS or a webhook or AMQP target of
+// a Minio server like "arn:minio:sqs:us-east-1:1:webhook". Events are S3 event types like "s3:ObjectCreated:*"
+type NotificationConfig struct {
+	ID     string   `json:"id,omitempty"`
+	ARN    string   `json:"arn"`
+	Events []string `json:"events"`
+	Prefix string   `json:"prefix,omitempty"`
+	Suffix string   `json:"suffix,omitempty"`
+}
+
+// Notifier - optional interface for clients which can manage the notification configuration of the bucket of
+// their URL, setting no configurations stops all notifications
+type Notifier interface {
+	GetNotifications() ([]NotificationConfig, error)
+	SetNotifications(configs []NotificationConfig) error
+}
+
+// BucketPolicy - optional interface for clients which can manage the access policy of the bucket of their URL,
+// policies are S3 JSON policy documents and an empty policy removes it
+type BucketPolicy interface {
+	GetBucketPolicy() (string, error)
+	SetBucketPolicy(policy string) error
+}
+
+// ServerInfo - status of a Minio deployment, Mode is "online" once it serves requests. Usage is the size of
+// all objects in bytes
+type ServerInfo struct {
+	Mode         string     `json:"mode"`
+	DeploymentID string     `json:"deployment-id,omitempty"`
+	Buckets      int64      `json:"buckets"`
+	Objects      int64      `json:"objects"`
+	Usage        int64      `json:"usage"`
+	Nodes        []NodeInfo `json:"nodes"`
+}
+
+// NodeInfo - a server of a deployment, State is "online" or "offline". Offline servers report no disks
+type NodeInfo struct {
+	Endpoint string     `json:"endpoint"`
+	State    string     `json:"state"`
+	Version  string     `json:"version,omitempty"`
+	Uptime   int64      `json:"uptime"` // seconds
+	Disks    []DiskInfo `json:"disks,omitempty"`
+}
+
+// DiskInfo - a disk of a server, State is "ok" for disks in use. Healing disks are being rebuilt from the
+// others after a replacement
+type DiskInfo struct {
+	Path    string `json:"path"`
+	State   string `json:"state"`
+	Total   uint64 `json:"total"`
+	Used    uint64 `json:"used"`
+	Healing bool   `json:"healing,omitempty"`
+}
+
+// ServerInfoGetter - optional interface for clients of Minio servers which can report the status of the
+// deployment with the admin API
+type ServerInfoGetter interface {
+	GetServerInfo() (*ServerInfo, error)
+}
+
+// ACLGetter - optional interface for clients which can read the access control list of the bucket or object
+// of their URL as a canned ACL like "private" or "public-read", "custom" for grants no canned ACL gives
+type ACLGetter interface {
+	GetBucketACL() (string, error)
+	GetObjectACL() (string, error)
+}
+
+// ObjectACLSetter - optional interface for clients which can set a canned ACL on the object of their URL
+type ObjectACLSetter interface {
+	SetObjectACL(acl string) error
+}
+
+// LegalHolder - optional interface for clients of buckets with object lock which can set a legal hold on the
+// object of their URL, held objects cannot be removed or overwritten until the hold is cleared
+type LegalHolder interface {
+	GetLegalHold() (bool, error)
+	SetLegalHold(on bool) error
+}
+
+// VersionGetter - optional interface for clients of versioned storage which can read an older version of the
+// object of their URL, by version ID as listed by ListVersions
+type VersionGetter interface {
+	GetObjectVersion(versionID string, offset, length int64) (io.ReadCloser, int64, error)
+	StatVersion(versionID string) (*Content, error)
+}
+
+// VersionRemover - optional interface for clients of versioned storage which can remove a single version of
+// the object of their URL, by version ID as listed by ListVersions
+type VersionRemover interface {
+	RemoveVersion(versionID string) error
+}
+
+// IncompleteRemover - optional interface for clients which can abort uploads started but never completed,
+// of the object of their URL, or of all objects under it if recursive
+type IncompleteRemover interface {
+	RemoveIncomplete(recursive bool) error
+}
+
+// MappedGetter - optional interface for clients which can serve a whole object from a memory mapping
+type MappedGetter interface {
+	GetObjectMapped() (MappedReader, int64, error)
+}
+
+// MappedReader - reader of a memory mapped file. Uploaders may send slices of the unread Bytes without
+// reading them and Advance past them, readers wrapping a MappedReader forward both to report progress
+type MappedReader interface {
+	io.ReadCloser
+	Bytes() []byte
+	Advance(n int64)
+}
+
+// RangeWriter - optional interface for clients which can write an object of known size at any offset,
+// ranges of it may be written concurrently
+type RangeWriter interface {
+	CreateObjectAt(size int64) (WriteAtCloser, error)
+}
+
+// WriteAtCloser - object being written by ranges, complete after Close. Abort discards the ranges written
+// instead, leaving a previous object in place
+type WriteAtCloser interface {
+	io.WriterAt
+	io.Closer
+	Abort() error
+}
+
+// Selector - optional interface for clients which can filter object contents on the server. Lines of the object
+// are CSV records, a line is the only field ‘_1’ unless it contains a quote or a carriage return
+type Selector interface {
+	// SelectLines - CSV output of SQL expression over the lines of the object
+	SelectLines(expression string) (io.ReadCloser, error)
+}
+
+// Event types of EventInfo
+const (
+	EventCreate = "ObjectCreated" // object was created or written to
+	EventRemove = "ObjectRemoved" // object was removed or renamed away
+)
+
+// EventInfo - change of an object below a watched URL
+type EventInfo struct {
+	Type string
+	URL  string
+	Time time.Time
+	Size int64 // size of created objects
+	Err  error
+}
+
+// Watcher - optional interface for clients which report changes below their URL as they happen,
+// events are sent until doneCh is closed
+type Watcher interface {
+	Watch(recursive bool, doneCh <-chan struct{}) (<-chan EventInfo, error)
+}
+
+// Replayer - optional interface for Watchers of servers which persist events, events since the time are
+// sent before live ones
+type Replayer interface {
+	WatchSince(since time.Time, recursive bool, doneCh <-chan struct{}) (<-chan EventInfo, error)
+}
+
 // ContentOnChannel - List contents on channel
 type ContentOnChannel struct {
 	Content *Content
@@ -52,4 +450,24 @@ type Content struct {
 	Time time.Time
 	Size int64
 	Type os.FileMode
+
+	// Optional, filled only by backends which provide them
+	ETag         string
+	Owner        string
+	OwnerID      string
+	StorageClass string
+	POSIX        *POSIXAttrs       // owner and permissions of files on POSIX filesystems
+	Metadata     map[string]string // filled by Stat, keys are the same as for MetadataPutter
+
+	// Filled by ListVersions and StatVersion only
+	VersionID      string
+	IsLatest       bool // current version of the object
+	IsDeleteMarker bool // the object was removed in this version
+}
+
+// POSIXAttrs - owner and permission bits of a file
+type POSIXAttrs struct {
+	UID  int
+	GID  int
+	Mode os.FileMode
 }
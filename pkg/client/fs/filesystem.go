@@ -0,0 +1,148 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Filesystem - all operating system calls made by fs client, embedders and tests
+// may provide a virtual one. Errors should be *os.PathError so that os.IsNotExist works
+type Filesystem interface {
+	Stat(name string) (os.FileInfo, error)
+	Lstat(name string) (os.FileInfo, error)
+	EvalSymlinks(path string) (string, error)
+	Open(name string) (File, error)
+	Create(name string) (File, error)
+	MkdirAll(path string, perm os.FileMode) error
+	Chmod(name string, mode os.FileMode) error
+}
+
+// Chowner - optional interface for filesystems which can change the owner of a file
+type Chowner interface {
+	Chown(name string, uid, gid int) error
+}
+
+// Remover - optional interface for filesystems which can remove files
+type Remover interface {
+	Remove(name string) error
+}
+
+// Renamer - optional interface for filesystems which can rename files, replacing existing files
+type Renamer interface {
+	Rename(oldname, newname string) error
+}
+
+// Mapper - optional interface for filesystems which can map files into memory
+type Mapper interface {
+	Mmap(name string) ([]byte, error)
+	Munmap(data []byte) error
+}
+
+// File - an open file or folder of a Filesystem
+type File interface {
+	io.ReadWriteCloser
+	Name() string
+	Readdir(count int) ([]os.FileInfo, error)
+}
+
+// OS - Filesystem of the operating system, used by New
+var OS Filesystem = osFilesystem{}
+
+type osFilesystem struct{}
+
+// paths are passed through longPath, which lifts the path length limit on windows
+func (osFilesystem) Stat(name string) (os.FileInfo, error)    { return os.Stat(longPath(name)) }
+func (osFilesystem) Lstat(name string) (os.FileInfo, error)   { return os.Lstat(longPath(name)) }
+func (osFilesystem) EvalSymlinks(path string) (string, error) { return filepath.EvalSymlinks(path) }
+func (osFilesystem) Open(name string) (File, error)           { return os.Open(longPath(name)) }
+func (osFilesystem) Create(name string) (File, error)         { return os.Create(longPath(name)) }
+func (osFilesystem) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(longPath(path), perm)
+}
+func (osFilesystem) Chmod(name string, mode os.FileMode) error { return os.Chmod(longPath(name), mode) }
+func (osFilesystem) Chown(name string, uid, gid int) error     { return os.Chown(longPath(name), uid, gid) }
+func (osFilesystem) Remove(name string) error                  { return os.Remove(longPath(name)) }
+func (osFilesystem) Rename(oldname, newname string) error {
+	return os.Rename(longPath(oldname), longPath(newname))
+}
+
+// walk - filepath.Walk on a Filesystem, files are walked in lexical order and symlinks are not followed
+func walk(filesystem Filesystem, root string, walkFn filepath.WalkFunc) error {
+	info, err := filesystem.Lstat(root)
+	if err != nil {
+		return walkFn(root, nil, err)
+	}
+	err = walkPath(filesystem, root, info, walkFn)
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
+
+func walkPath(filesystem Filesystem, path string, info os.FileInfo, walkFn filepath.WalkFunc) error {
+	err := walkFn(path, info, nil)
+	if err != nil {
+		if info.IsDir() && err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return nil
+	}
+	names, err := readDirNames(filesystem, path)
+	if err != nil {
+		return walkFn(path, info, err)
+	}
+	for _, name := range names {
+		filename := filepath.Join(path, name)
+		fileInfo, err := filesystem.Lstat(filename)
+		if err != nil {
+			if err := walkFn(filename, fileInfo, err); err != nil && err != filepath.SkipDir {
+				return err
+			}
+			continue
+		}
+		err = walkPath(filesystem, filename, fileInfo, walkFn)
+		if err != nil && (!fileInfo.IsDir() || err != filepath.SkipDir) {
+			return err
+		}
+	}
+	return nil
+}
+
+func readDirNames(filesystem Filesystem, dirname string) ([]string, error) {
+	dir, err := filesystem.Open(dirname)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	infos, err := dir.Readdir(-1)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
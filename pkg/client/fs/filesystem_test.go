@@ -0,0 +1,192 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this fs except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// memFilesystem - in memory Filesystem, every entry has the same modification time
+type memFilesystem struct {
+	modTime time.Time
+	files   map[string]*bytes.Buffer // nil for folders
+	modes   map[string]os.FileMode
+}
+
+func newMemFilesystem(modTime time.Time) *memFilesystem {
+	return &memFilesystem{
+		modTime: modTime,
+		files:   map[string]*bytes.Buffer{"/": nil},
+		modes:   map[string]os.FileMode{"/": os.ModeDir | 0755},
+	}
+}
+
+type memFileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() os.FileMode  { return i.mode }
+func (i memFileInfo) ModTime() time.Time { return i.modTime }
+func (i memFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memFileInfo) Sys() interface{}   { return nil }
+
+type memFile struct {
+	*bytes.Reader
+	fs   *memFilesystem
+	name string
+	data *bytes.Buffer
+}
+
+func (f *memFile) Name() string                { return f.name }
+func (f *memFile) Close() error                { return nil }
+func (f *memFile) Write(p []byte) (int, error) { return f.data.Write(p) }
+
+func (f *memFile) Readdir(count int) ([]os.FileInfo, error) {
+	var infos []os.FileInfo
+	for name := range f.fs.files {
+		if name != f.name && filepath.Dir(name) == f.name {
+			info, _ := f.fs.Stat(name)
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+func (m *memFilesystem) Stat(name string) (os.FileInfo, error) {
+	name = filepath.Clean(name)
+	data, ok := m.files[name]
+	if !ok {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+	info := memFileInfo{name: filepath.Base(name), mode: m.modes[name], modTime: m.modTime}
+	if data != nil {
+		info.size = int64(data.Len())
+	}
+	return info, nil
+}
+
+func (m *memFilesystem) Lstat(name string) (os.FileInfo, error) { return m.Stat(name) }
+
+func (m *memFilesystem) EvalSymlinks(path string) (string, error) {
+	if _, err := m.Stat(path); err != nil {
+		return "", err
+	}
+	return filepath.Clean(path), nil
+}
+
+func (m *memFilesystem) Open(name string) (File, error) {
+	name = filepath.Clean(name)
+	if _, err := m.Stat(name); err != nil {
+		return nil, err
+	}
+	data := m.files[name]
+	if data == nil {
+		return &memFile{Reader: bytes.NewReader(nil), fs: m, name: name}, nil
+	}
+	return &memFile{Reader: bytes.NewReader(data.Bytes()), fs: m, name: name}, nil
+}
+
+func (m *memFilesystem) Create(name string) (File, error) {
+	name = filepath.Clean(name)
+	if _, ok := m.files[filepath.Dir(name)]; !ok {
+		return nil, &os.PathError{Op: "create", Path: name, Err: os.ErrNotExist}
+	}
+	data := new(bytes.Buffer)
+	m.files[name] = data
+	m.modes[name] = 0644
+	return &memFile{Reader: bytes.NewReader(nil), fs: m, name: name, data: data}, nil
+}
+
+func (m *memFilesystem) MkdirAll(path string, perm os.FileMode) error {
+	for path = filepath.Clean(path); path != "/"; path = filepath.Dir(path) {
+		if _, ok := m.files[path]; !ok {
+			m.files[path] = nil
+			m.modes[path] = os.ModeDir | perm
+		}
+	}
+	return nil
+}
+
+func (m *memFilesystem) Chmod(name string, mode os.FileMode) error {
+	name = filepath.Clean(name)
+	if _, ok := m.files[name]; !ok {
+		return &os.PathError{Op: "chmod", Path: name, Err: os.ErrNotExist}
+	}
+	m.modes[name] = m.modes[name]&os.ModeType | mode
+	return nil
+}
+
+func (s *MySuite) TestVirtualFilesystem(c *C) {
+	if filepath.Separator != '/' {
+		c.Skip("in memory filesystem uses slash separated paths")
+	}
+	modTime := time.Date(2015, time.June, 24, 10, 30, 0, 0, time.UTC)
+	filesystem := newMemFilesystem(modTime)
+
+	data := "hello"
+	for _, name := range []string{"/bucket/object1", "/bucket/dir/object2"} {
+		fsc, err := NewWithFilesystem(name, filesystem)
+		c.Assert(err, IsNil)
+		err = fsc.PutObject(int64(len(data)), strings.NewReader(data))
+		c.Assert(err, IsNil)
+	}
+
+	fsc, err := NewWithFilesystem("/bucket/dir/object2", filesystem)
+	c.Assert(err, IsNil)
+	content, err := fsc.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(len(data)))
+	c.Assert(content.Time, Equals, modTime)
+
+	reader, _, err := fsc.GetObject(0, 0)
+	c.Assert(err, IsNil)
+	result, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(string(result), Equals, data)
+
+	fsc, err = NewWithFilesystem("/bucket/", filesystem)
+	c.Assert(err, IsNil)
+	var names []string
+	for contentCh := range fsc.List(true) {
+		c.Assert(contentCh.Err, IsNil)
+		c.Assert(contentCh.Content.Time, Equals, modTime)
+		names = append(names, contentCh.Content.Name)
+	}
+	sort.Strings(names)
+	c.Assert(names, DeepEquals, []string{"dir", "dir/object2", "object1"})
+
+	c.Assert(fsc.SetBucketACL("public-read-write"), IsNil)
+	c.Assert(filesystem.modes["/bucket"], Equals, os.ModeDir|0777)
+
+	fsc, err = NewWithFilesystem("/bucket/missing", filesystem)
+	c.Assert(err, IsNil)
+	_, err = fsc.Stat()
+	c.Assert(err, Not(IsNil))
+}
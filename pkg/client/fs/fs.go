@@ -17,7 +17,10 @@
 package fs
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"io"
+	"mime"
 	"os"
 	"path/filepath"
 	"strings"
@@ -29,11 +32,17 @@ import (
 )
 
 type fsClient struct {
-	path string
+	path       string
+	filesystem Filesystem
 }
 
 // New - instantiate a new fs client
 func New(path string) (client.Client, error) {
+	return NewWithFilesystem(path, OS)
+}
+
+// NewWithFilesystem - instantiate a new fs client on a given filesystem
+func NewWithFilesystem(path string, filesystem Filesystem) (client.Client, error) {
 	if strings.TrimSpace(path) == "" {
 		return nil, iodine.New(client.EmptyPath{}, nil)
 	}
@@ -42,13 +51,17 @@ func New(path string) (client.Client, error) {
 	if err != nil {
 		return nil, iodine.New(err, nil)
 	}
-	return &fsClient{path: path}, nil
+	return &fsClient{path: path, filesystem: filesystem}, nil
 }
 
 // URL get url
 func (f *fsClient) URL() *client.URL {
-	url, _ := client.Parse(f.path)
-	return url
+	// path is not parsed, local paths may look like URLs
+	return &client.URL{
+		Type:      client.Filesystem,
+		Path:      f.path,
+		Separator: filepath.Separator,
+	}
 }
 
 /// Object operations
@@ -62,14 +75,14 @@ func (f *fsClient) fsStat() (os.FileInfo, error) {
 		fpath = fpath + "."
 	}
 	// Resolve symlinks
-	fpath, err := filepath.EvalSymlinks(fpath)
+	fpath, err := f.filesystem.EvalSymlinks(fpath)
 	if os.IsNotExist(err) {
 		return nil, iodine.New(err, nil)
 	}
 	if err != nil {
 		return nil, iodine.New(err, nil)
 	}
-	st, err := os.Stat(fpath)
+	st, err := f.filesystem.Stat(fpath)
 	if os.IsNotExist(err) {
 		return nil, iodine.New(client.NotFound{Path: fpath}, nil)
 	}
@@ -84,11 +97,11 @@ func (f *fsClient) PutObject(size int64, data io.Reader) error {
 	objectDir, _ := filepath.Split(f.path)
 	objectPath := f.path
 	if objectDir != "" {
-		if err := os.MkdirAll(objectDir, 0700); err != nil {
+		if err := f.filesystem.MkdirAll(objectDir, 0700); err != nil {
 			return iodine.New(err, nil)
 		}
 	}
-	fs, err := os.Create(objectPath)
+	fs, err := f.filesystem.Create(objectPath)
 	if err != nil {
 		return iodine.New(err, nil)
 	}
@@ -111,15 +124,134 @@ func (f *fsClient) PutObject(size int64, data io.Reader) error {
 	return nil
 }
 
+// CreateObjectAt - create the file with size bytes allocated to be written by ranges. Ranges are written to
+// a hidden file next to it, renamed to the file on Close and removed on Abort. Filesystems whose files cannot
+// be written at offsets, renamed or removed return client.APINotImplemented
+func (f *fsClient) CreateObjectAt(size int64) (client.WriteAtCloser, error) {
+	renamer, canRename := f.filesystem.(Renamer)
+	remover, canRemove := f.filesystem.(Remover)
+	if !canRename || !canRemove {
+		return nil, iodine.New(client.APINotImplemented{API: "CreateObjectAt"}, nil)
+	}
+	objectDir, objectName := filepath.Split(f.path)
+	if objectDir != "" {
+		if err := f.filesystem.MkdirAll(objectDir, 0700); err != nil {
+			return nil, iodine.New(err, nil)
+		}
+	}
+	partPath := filepath.Join(objectDir, "."+objectName+".mc-ranges")
+	file, err := f.filesystem.Create(partPath)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	writer, ok := file.(interface {
+		io.WriterAt
+		io.Closer
+		Truncate(size int64) error
+	})
+	if !ok {
+		file.Close()
+		remover.Remove(partPath)
+		return nil, iodine.New(client.APINotImplemented{API: "CreateObjectAt"}, nil)
+	}
+	if err := writer.Truncate(size); err != nil {
+		file.Close()
+		remover.Remove(partPath)
+		return nil, iodine.New(err, nil)
+	}
+	return &rangeFile{writer, partPath, f.path, renamer, remover}, nil
+}
+
+// rangeFile - hidden file written by ranges, becomes the object at path on Close
+type rangeFile struct {
+	file interface {
+		io.WriterAt
+		io.Closer
+	}
+	partPath string
+	path     string
+	renamer  Renamer
+	remover  Remover
+}
+
+func (r *rangeFile) WriteAt(p []byte, offset int64) (int, error) {
+	return r.file.WriteAt(p, offset)
+}
+
+func (r *rangeFile) Close() error {
+	if err := r.file.Close(); err != nil {
+		r.remover.Remove(r.partPath)
+		return iodine.New(err, nil)
+	}
+	if err := r.renamer.Rename(r.partPath, r.path); err != nil {
+		r.remover.Remove(r.partPath)
+		return iodine.New(err, nil)
+	}
+	return nil
+}
+
+func (r *rangeFile) Abort() error {
+	r.file.Close()
+	if err := r.remover.Remove(r.partPath); err != nil {
+		return iodine.New(err, nil)
+	}
+	return nil
+}
+
 // get - download an object from bucket
 func (f *fsClient) get(content *client.Content) (io.ReadCloser, int64, error) {
-	body, err := os.Open(f.path)
+	body, err := f.filesystem.Open(f.path)
 	if err != nil {
 		return nil, content.Size, iodine.New(err, nil)
 	}
 	return body, content.Size, nil
 }
 
+// mappedFile - reader of a memory mapped file, unmapped on Close
+type mappedFile struct {
+	data   []byte // unread part of mapped
+	mapped []byte
+	munmap func([]byte) error
+}
+
+func (m *mappedFile) Read(p []byte) (int, error) {
+	if len(m.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data)
+	m.data = m.data[n:]
+	return n, nil
+}
+
+func (m *mappedFile) Bytes() []byte   { return m.data }
+func (m *mappedFile) Advance(n int64) { m.data = m.data[n:] }
+
+func (m *mappedFile) Close() error {
+	mapped := m.mapped
+	m.data, m.mapped = nil, nil
+	return m.munmap(mapped)
+}
+
+// GetObjectMapped - map the whole file into memory, the file must not be truncated until the reader is closed
+func (f *fsClient) GetObjectMapped() (client.MappedReader, int64, error) {
+	mapper, ok := f.filesystem.(Mapper)
+	if !ok {
+		return nil, 0, iodine.New(client.APINotImplemented{API: "GetObjectMapped"}, nil)
+	}
+	content, err := f.getFSMetadata()
+	if err != nil {
+		return nil, 0, iodine.New(err, nil)
+	}
+	if content.Type.IsDir() {
+		return nil, 0, iodine.New(client.ISFolder{Path: f.path}, nil)
+	}
+	data, err := mapper.Mmap(f.path)
+	if err != nil {
+		return nil, 0, iodine.New(err, nil)
+	}
+	return &mappedFile{data: data, mapped: data, munmap: mapper.Munmap}, int64(len(data)), nil
+}
+
 // GetObject download an full or part object from bucket
 // getobject returns a reader, length and nil for no errors
 // with errors getobject will return nil reader, length and typed errors
@@ -143,7 +275,7 @@ func (f *fsClient) GetObject(offset, length int64) (io.ReadCloser, int64, error)
 	}
 
 	// Resolve symlinks
-	fpath, err = filepath.EvalSymlinks(fpath)
+	fpath, err = f.filesystem.EvalSymlinks(fpath)
 	if os.IsNotExist(err) {
 		return nil, 0, iodine.New(err, nil)
 	}
@@ -153,7 +285,7 @@ func (f *fsClient) GetObject(offset, length int64) (io.ReadCloser, int64, error)
 	if offset == 0 && length == 0 {
 		return f.get(content)
 	}
-	body, err := os.Open(f.path)
+	body, err := f.filesystem.Open(f.path)
 	if err != nil {
 		return nil, length, iodine.New(err, nil)
 
@@ -188,7 +320,7 @@ func (f *fsClient) listInRoutine(contentCh chan client.ContentOnChannel) {
 	}
 
 	// Resolve symlinks
-	fpath, err := filepath.EvalSymlinks(fpath)
+	fpath, err := f.filesystem.EvalSymlinks(fpath)
 	if os.IsNotExist(err) {
 		contentCh <- client.ContentOnChannel{
 			Content: nil,
@@ -204,7 +336,7 @@ func (f *fsClient) listInRoutine(contentCh chan client.ContentOnChannel) {
 		return
 	}
 
-	fi, err := os.Stat(fpath)
+	fi, err := f.filesystem.Stat(fpath)
 	if err != nil {
 		contentCh <- client.ContentOnChannel{
 			Content: nil,
@@ -220,7 +352,7 @@ func (f *fsClient) listInRoutine(contentCh chan client.ContentOnChannel) {
 		// instead we take raw output and provide it back to the
 		// user - this is the correct style when are moving large
 		// quantities of files
-		dir, err := os.Open(fpath)
+		dir, err := f.filesystem.Open(fpath)
 		if err != nil {
 			contentCh <- client.ContentOnChannel{
 				Content: nil,
@@ -241,7 +373,7 @@ func (f *fsClient) listInRoutine(contentCh chan client.ContentOnChannel) {
 		for _, file := range files {
 			fi := file
 			if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
-				fi, err = os.Stat(filepath.Join(dir.Name(), fi.Name()))
+				fi, err = f.filesystem.Stat(filepath.Join(dir.Name(), fi.Name()))
 				if os.IsNotExist(err) {
 					contentCh <- client.ContentOnChannel{
 						Content: nil,
@@ -260,10 +392,11 @@ func (f *fsClient) listInRoutine(contentCh chan client.ContentOnChannel) {
 			}
 			if fi.Mode().IsRegular() || fi.Mode().IsDir() {
 				content := &client.Content{
-					Name: fi.Name(),
-					Time: fi.ModTime(),
-					Size: fi.Size(),
-					Type: fi.Mode(),
+					Name:  fi.Name(),
+					Time:  fi.ModTime(),
+					Size:  fi.Size(),
+					Type:  fi.Mode(),
+					POSIX: posixAttrs(fi),
 				}
 				contentCh <- client.ContentOnChannel{
 					Content: content,
@@ -273,10 +406,11 @@ func (f *fsClient) listInRoutine(contentCh chan client.ContentOnChannel) {
 		}
 	default:
 		content := &client.Content{
-			Name: f.path,
-			Time: fi.ModTime(),
-			Size: fi.Size(),
-			Type: fi.Mode(),
+			Name:  f.path,
+			Time:  fi.ModTime(),
+			Size:  fi.Size(),
+			Type:  fi.Mode(),
+			POSIX: posixAttrs(fi),
 		}
 		contentCh <- client.ContentOnChannel{
 			Content: content,
@@ -308,7 +442,7 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 			return iodine.New(err, nil) // abort
 		}
 		if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
-			fi, err = os.Stat(fp)
+			fi, err = f.filesystem.Stat(fp)
 			if err != nil {
 				if os.IsNotExist(err) || os.IsPermission(err) { // ignore broken symlinks and permission denied
 					return nil
@@ -318,10 +452,11 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 		}
 		if fi.Mode().IsRegular() || fi.Mode().IsDir() {
 			content := &client.Content{
-				Name: f.delimited(fp),
-				Time: fi.ModTime(),
-				Size: fi.Size(),
-				Type: fi.Mode(),
+				Name:  f.delimited(fp),
+				Time:  fi.ModTime(),
+				Size:  fi.Size(),
+				Type:  fi.Mode(),
+				POSIX: posixAttrs(fi),
 			}
 			contentCh <- client.ContentOnChannel{
 				Content: content,
@@ -330,7 +465,7 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 		}
 		return nil
 	}
-	err := filepath.Walk(f.path, visitFS)
+	err := walk(f.filesystem, f.path, visitFS)
 	if err != nil {
 		contentCh <- client.ContentOnChannel{
 			Content: nil,
@@ -375,7 +510,7 @@ func aclToPerm(acl string) os.FileMode {
 
 // MakeBucket - create a new bucket
 func (f *fsClient) MakeBucket() error {
-	err := os.MkdirAll(f.path, 0775)
+	err := f.filesystem.MkdirAll(f.path, 0775)
 	if err != nil {
 		return iodine.New(err, nil)
 	}
@@ -387,11 +522,11 @@ func (f *fsClient) SetBucketACL(acl string) error {
 	if !isValidBucketACL(acl) {
 		return iodine.New(client.InvalidACLType{ACL: acl}, nil)
 	}
-	err := os.MkdirAll(f.path, aclToPerm(acl))
+	err := f.filesystem.MkdirAll(f.path, aclToPerm(acl))
 	if err != nil {
 		return iodine.New(err, nil)
 	}
-	err = os.Chmod(f.path, aclToPerm(acl))
+	err = f.filesystem.Chmod(f.path, aclToPerm(acl))
 	if err != nil {
 		return iodine.New(err, nil)
 	}
@@ -409,10 +544,106 @@ func (f *fsClient) getFSMetadata() (content *client.Content, err error) {
 	content.Size = st.Size()
 	content.Time = st.ModTime()
 	content.Type = st.Mode()
+	content.POSIX = posixAttrs(st)
+	if contentType := mime.TypeByExtension(filepath.Ext(f.path)); contentType != "" && st.Mode().IsRegular() {
+		content.Metadata = map[string]string{"Content-Type": contentType}
+	}
 	return content, nil
 }
 
+// SetPOSIXAttrs - restore permissions, ownership is restored only when running as root
+func (f *fsClient) SetPOSIXAttrs(attrs client.POSIXAttrs) error {
+	// chown clears setuid and setgid bits, change owner first
+	if chowner, ok := f.filesystem.(Chowner); ok && os.Geteuid() == 0 {
+		if err := chowner.Chown(f.path, attrs.UID, attrs.GID); err != nil {
+			return iodine.New(err, nil)
+		}
+	}
+	mode := attrs.Mode & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	if err := f.filesystem.Chmod(f.path, mode); err != nil {
+		return iodine.New(err, nil)
+	}
+	return nil
+}
+
+// Remove - remove the file, folders are not removed
+func (f *fsClient) Remove() error {
+	remover, ok := f.filesystem.(Remover)
+	if !ok {
+		return iodine.New(client.APINotImplemented{API: "Remove"}, nil)
+	}
+	st, err := f.fsStat()
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if st.IsDir() {
+		return iodine.New(client.ISFolder{Path: f.path}, nil)
+	}
+	if err := remover.Remove(f.path); err != nil {
+		return iodine.New(err, nil)
+	}
+	return nil
+}
+
+// RemoveBucket - remove the folder and the empty folders below it, folders with files are not removed
+func (f *fsClient) RemoveBucket() error {
+	remover, ok := f.filesystem.(Remover)
+	if !ok {
+		return iodine.New(client.APINotImplemented{API: "RemoveBucket"}, nil)
+	}
+	st, err := f.fsStat()
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if !st.IsDir() {
+		return iodine.New(client.InvalidArgument{}, nil)
+	}
+	var folders []string
+	err = walk(f.filesystem, f.path, func(fp string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			folders = append(folders, fp)
+		}
+		return nil
+	})
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	// folders below a folder are walked after it
+	for i := len(folders) - 1; i >= 0; i-- {
+		if err := remover.Remove(folders[i]); err != nil {
+			return iodine.New(err, nil)
+		}
+	}
+	return nil
+}
+
 // Stat - get metadata from path
 func (f *fsClient) Stat() (content *client.Content, err error) {
 	return f.getFSMetadata()
 }
+
+// StatWithChecksum - Stat with the ETag of files set to the MD5 of their data, as S3 sets it for objects
+// uploaded in a single request
+func (f *fsClient) StatWithChecksum() (*client.Content, error) {
+	content, err := f.getFSMetadata()
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	if !content.Type.IsRegular() {
+		return content, nil
+	}
+	file, err := f.filesystem.Open(f.path)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	defer file.Close()
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	content.ETag = hex.EncodeToString(hash.Sum(nil))
+	return content, nil
+}
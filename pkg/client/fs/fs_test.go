@@ -22,9 +22,12 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
 	. "gopkg.in/check.v1"
 )
 
@@ -245,4 +248,143 @@ func (s *MySuite) TestStatObject(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(content.Name, Equals, objectPath)
 	c.Assert(content.Size, Equals, int64(dataLen))
+	c.Assert(content.ETag, Equals, "")
+	c.Assert(content.Metadata, IsNil)
+
+	content, err = fsc.(client.Checksummer).StatWithChecksum()
+	c.Assert(err, IsNil)
+	c.Assert(content.ETag, Equals, "5d41402abc4b2a76b9719d911017c592")
+
+	fsc, err = New(filepath.Join(root, "index.html"))
+	c.Assert(err, IsNil)
+	c.Assert(fsc.PutObject(int64(dataLen), bytes.NewReader([]byte(data))), IsNil)
+	content, err = fsc.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Metadata["Content-Type"], Equals, "text/html; charset=utf-8")
+}
+
+func (s *MySuite) TestGetObjectMapped(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	fsc, err := New(filepath.Join(root, "object"))
+	c.Assert(err, IsNil)
+	err = fsc.PutObject(11, bytes.NewReader([]byte("hello world")))
+	c.Assert(err, IsNil)
+
+	getter, ok := fsc.(client.MappedGetter)
+	c.Assert(ok, Equals, true)
+	reader, length, err := getter.GetObjectMapped()
+	if _, ok := iodine.ToError(err).(client.APINotImplemented); ok {
+		c.Skip("memory mapping is not supported on this platform")
+	}
+	c.Assert(err, IsNil)
+	c.Assert(length, Equals, int64(11))
+	c.Assert(string(reader.Bytes()), Equals, "hello world")
+	reader.Advance(6)
+	data, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "world")
+	c.Assert(reader.Close(), IsNil)
+}
+
+func (s *MySuite) TestRemove(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object")
+	fsc, err := New(objectPath)
+	c.Assert(err, IsNil)
+	err = fsc.PutObject(5, bytes.NewReader([]byte("hello")))
+	c.Assert(err, IsNil)
+
+	err = fsc.(client.Remover).Remove()
+	c.Assert(err, IsNil)
+	_, err = os.Stat(objectPath)
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	// folders are left alone
+	fsc, err = New(root)
+	c.Assert(err, IsNil)
+	err = fsc.(client.Remover).Remove()
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.ISFolder{})
+}
+
+func (s *MySuite) TestPOSIXAttrs(c *C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("windows has no POSIX ownership")
+	}
+	root, err := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object")
+	fsc, err := New(objectPath)
+	c.Assert(err, IsNil)
+
+	data := "hello"
+	err = fsc.PutObject(int64(len(data)), bytes.NewReader([]byte(data)))
+	c.Assert(err, IsNil)
+
+	err = fsc.(client.POSIXAttrsSetter).SetPOSIXAttrs(client.POSIXAttrs{UID: os.Getuid(), GID: os.Getgid(), Mode: 0640})
+	c.Assert(err, IsNil)
+
+	content, err := fsc.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.POSIX, Not(IsNil))
+	c.Assert(content.POSIX.UID, Equals, os.Getuid())
+	c.Assert(content.POSIX.GID, Equals, os.Getgid())
+	c.Assert(content.POSIX.Mode.Perm(), Equals, os.FileMode(0640))
+}
+
+func (s *MySuite) TestWatch(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	fsc, err := New(root)
+	c.Assert(err, IsNil)
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	eventCh, err := fsc.(client.Watcher).Watch(true, doneCh)
+	c.Assert(err, IsNil)
+
+	// next event for name, other events like writes of earlier files are skipped
+	next := func(eventType, name string) client.EventInfo {
+		for {
+			select {
+			case event := <-eventCh:
+				c.Assert(event.Err, IsNil)
+				if event.Type == eventType && event.URL == name {
+					return event
+				}
+			case <-time.After(5 * time.Second):
+				c.Fatalf("No %s event for %s", eventType, name)
+			}
+		}
+	}
+
+	object := filepath.Join(root, "object")
+	c.Assert(ioutil.WriteFile(object, []byte("hello"), 0600), IsNil)
+	c.Assert(next(client.EventCreate, object).Size, Equals, int64(5))
+
+	// folders created later are watched too
+	nested := filepath.Join(root, "a", "b")
+	c.Assert(os.MkdirAll(nested, 0700), IsNil)
+	nestedObject := filepath.Join(nested, "object")
+	c.Assert(ioutil.WriteFile(nestedObject, []byte("hello"), 0600), IsNil)
+	next(client.EventCreate, nestedObject)
+
+	c.Assert(os.Remove(object), IsNil)
+	next(client.EventRemove, object)
+
+	// virtual filesystems cannot be watched
+	memfs := newMemFilesystem(time.Now())
+	c.Assert(memfs.MkdirAll("/bucket", 0700), IsNil)
+	fsc, err = NewWithFilesystem("/bucket", memfs)
+	c.Assert(err, IsNil)
+	_, err = fsc.(client.Watcher).Watch(false, doneCh)
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.APINotImplemented{})
 }
@@ -18,6 +18,31 @@
 
 package fs
 
+import (
+	"os"
+	"syscall"
+
+	"github.com/minio/mc/pkg/client"
+)
+
 func normalizePath(path string) (string, error) {
 	return path, nil
 }
+
+// longPath - paths have no length limit other than the filesystem's
+func longPath(path string) string {
+	return path
+}
+
+// posixAttrs - owner and permissions of a file, nil if filesystem does not provide them
+func posixAttrs(fi os.FileInfo) *client.POSIXAttrs {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	return &client.POSIXAttrs{
+		UID:  int(st.Uid),
+		GID:  int(st.Gid),
+		Mode: fi.Mode(),
+	}
+}
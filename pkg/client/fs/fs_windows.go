@@ -19,9 +19,12 @@
 package fs
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"syscall"
 
+	"github.com/minio/mc/pkg/client"
 	"github.com/minio/minio/pkg/iodine"
 )
 
@@ -35,3 +38,29 @@ func normalizePath(path string) (string, error) {
 	}
 	return path, nil
 }
+
+// maxShortPath - longest path win32 calls accept without the extended-length prefix, MAX_PATH less
+// room for an 8.3 file name when creating folders
+const maxShortPath = 248
+
+// longPath - extended-length form \\?\C:\path or \\?\UNC\server\share\path of long paths, see
+// https://msdn.microsoft.com/en-us/library/windows/desktop/aa365247.aspx#maxpath
+func longPath(path string) string {
+	if len(path) < maxShortPath || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	// extended-length paths are not normalized by windows, '.', '..' and '/' must be resolved here
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
+
+// posixAttrs - windows has no POSIX ownership
+func posixAttrs(fi os.FileInfo) *client.POSIXAttrs {
+	return nil
+}
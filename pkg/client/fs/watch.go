@@ -0,0 +1,137 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// addWatches - watch folder and, if recursive, every folder below it. Regular files found are returned,
+// they may have been created before their folder was watched
+func addWatches(watcher *fsnotify.Watcher, filesystem Filesystem, folder string, recursive bool) ([]string, error) {
+	if !recursive {
+		return nil, watcher.Add(folder)
+	}
+	var files []string
+	err := walk(filesystem, folder, func(fp string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) { // removed while walking
+				return nil
+			}
+			return err
+		}
+		switch {
+		case fi.IsDir():
+			return watcher.Add(fp)
+		case fi.Mode().IsRegular():
+			files = append(files, fp)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// Watch - report changes below the folder or file of this client using inotify, kqueue or
+// ReadDirectoryChangesW. Folders created below a recursively watched folder are watched as well.
+func (f *fsClient) Watch(recursive bool, doneCh <-chan struct{}) (<-chan client.EventInfo, error) {
+	if _, ok := f.filesystem.(osFilesystem); !ok {
+		// virtual filesystems have no change notifications
+		return nil, iodine.New(client.APINotImplemented{API: "Watch"}, nil)
+	}
+	fi, err := f.fsStat()
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	root := filepath.Clean(f.path)
+	if _, err := addWatches(watcher, f.filesystem, root, recursive && fi.IsDir()); err != nil {
+		watcher.Close()
+		return nil, iodine.New(err, nil)
+	}
+	eventCh := make(chan client.EventInfo)
+	go func() {
+		defer close(eventCh)
+		defer watcher.Close()
+		send := func(event client.EventInfo) bool {
+			select {
+			case eventCh <- event:
+				return true
+			case <-doneCh:
+				return false
+			}
+		}
+		created := func(fp string, fi os.FileInfo) bool {
+			return send(client.EventInfo{Type: client.EventCreate, URL: fp, Time: time.Now().UTC(), Size: fi.Size()})
+		}
+		for {
+			select {
+			case <-doneCh:
+				return
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				if !send(client.EventInfo{Err: iodine.New(err, nil)}) {
+					return
+				}
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				switch {
+				case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+					if !send(client.EventInfo{Type: client.EventRemove, URL: event.Name, Time: time.Now().UTC()}) {
+						return
+					}
+				case event.Op&(fsnotify.Create|fsnotify.Write) != 0:
+					fi, err := f.filesystem.Lstat(event.Name)
+					if err != nil { // already gone again
+						continue
+					}
+					if fi.Mode().IsRegular() {
+						if !created(event.Name, fi) {
+							return
+						}
+						continue
+					}
+					if !fi.IsDir() || !recursive || event.Op&fsnotify.Create == 0 {
+						continue
+					}
+					files, err := addWatches(watcher, f.filesystem, event.Name, true)
+					if err != nil && !send(client.EventInfo{Err: iodine.New(err, nil)}) {
+						return
+					}
+					for _, file := range files {
+						if fi, err := f.filesystem.Lstat(file); err == nil && !created(file, fi) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}()
+	return eventCh, nil
+}
@@ -0,0 +1,274 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ftp
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Minimal FTP protocol, see RFC 959, RFC 2428 (EPSV), RFC 3659 (MLST, SIZE, MDTM) and RFC 4217 (TLS)
+
+// Security modes of control and data connections
+const (
+	securityNone     = iota // ftp://
+	securityImplicit        // ftps://, TLS from the first byte
+	securityExplicit        // ftpes://, AUTH TLS after greeting
+)
+
+const dialTimeout = 30 * time.Second
+
+// conn - a logged in control connection
+type conn struct {
+	text      *textproto.Conn
+	netConn   net.Conn
+	host      string      // server address for passive data connections
+	tlsConfig *tls.Config // nil without TLS
+	home      string      // working directory after login
+	mlst      bool        // server supports MLST and MLSD
+}
+
+// dial connects, secures and logs in a control connection
+func dial(addr string, security int, tlsConfig *tls.Config, user, password string) (*conn, error) {
+	netConn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, iodine.New(err, map[string]string{"Host": addr})
+	}
+	host, _, _ := net.SplitHostPort(netConn.RemoteAddr().String())
+	if security == securityImplicit {
+		netConn = tls.Client(netConn, tlsConfig)
+	}
+	c := &conn{text: textproto.NewConn(netConn), netConn: netConn, host: host}
+	if err := c.login(security, tlsConfig, user, password); err != nil {
+		c.netConn.Close()
+		return nil, iodine.New(err, map[string]string{"Host": addr})
+	}
+	return c, nil
+}
+
+func (c *conn) login(security int, tlsConfig *tls.Config, user, password string) error {
+	if _, _, err := c.text.ReadResponse(2); err != nil {
+		return iodine.New(err, nil)
+	}
+	if security == securityExplicit {
+		if _, _, err := c.cmd(2, "AUTH TLS"); err != nil {
+			return iodine.New(err, nil)
+		}
+		c.netConn = tls.Client(c.netConn, tlsConfig)
+		c.text = textproto.NewConn(c.netConn)
+	}
+	code, _, err := c.cmd(0, "USER %s", user)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	switch code {
+	case 230:
+	case 331, 332:
+		if _, _, err := c.cmd(2, "PASS %s", password); err != nil {
+			return iodine.New(err, nil)
+		}
+	default:
+		return iodine.New(&textproto.Error{Code: code, Msg: "unexpected reply to USER"}, nil)
+	}
+	if security != securityNone {
+		// protect data connections too
+		if _, _, err := c.cmd(2, "PBSZ 0"); err != nil {
+			return iodine.New(err, nil)
+		}
+		if _, _, err := c.cmd(2, "PROT P"); err != nil {
+			return iodine.New(err, nil)
+		}
+		c.tlsConfig = tlsConfig
+	}
+	if _, _, err := c.cmd(2, "TYPE I"); err != nil {
+		return iodine.New(err, nil)
+	}
+	if _, msg, err := c.cmd(2, "FEAT"); err == nil {
+		for _, line := range strings.Split(msg, "\n") {
+			if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(line)), "MLST") {
+				c.mlst = true
+			}
+		}
+	}
+	_, msg, err := c.cmd(2, "PWD")
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	c.home = parsePWD(msg)
+	return nil
+}
+
+// cmd sends a command and reads its reply, expectCode 0 accepts any reply
+func (c *conn) cmd(expectCode int, format string, args ...interface{}) (int, string, error) {
+	if err := c.text.PrintfLine(format, args...); err != nil {
+		return 0, "", iodine.New(err, nil)
+	}
+	code, msg, err := c.text.ReadResponse(expectCode)
+	if err != nil {
+		return code, msg, iodine.New(err, nil)
+	}
+	return code, msg, nil
+}
+
+// Close sends QUIT and closes control connection
+func (c *conn) Close() error {
+	c.text.PrintfLine("QUIT")
+	return c.netConn.Close()
+}
+
+// parsePWD - path is double quoted with embedded quotes doubled, 257 "/home/user" is current directory
+func parsePWD(msg string) string {
+	start := strings.Index(msg, "\"")
+	end := strings.LastIndex(msg, "\"")
+	if start < 0 || end <= start {
+		return "/"
+	}
+	return strings.Replace(msg[start+1:end], "\"\"", "\"", -1)
+}
+
+// parseEPSV - 229 Entering Extended Passive Mode (|||6446|)
+func parseEPSV(msg string) (int, bool) {
+	start := strings.Index(msg, "(")
+	end := strings.LastIndex(msg, ")")
+	if start < 0 || end < start+2 {
+		return 0, false
+	}
+	fields := strings.Split(msg[start+1:end], msg[start+1:start+2])
+	if len(fields) != 5 {
+		return 0, false
+	}
+	port, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return 0, false
+	}
+	return port, true
+}
+
+// parsePASV - 227 Entering Passive Mode (h1,h2,h3,h4,p1,p2), address is ignored as it is often wrong behind NAT
+func parsePASV(msg string) (int, bool) {
+	start := strings.Index(msg, "(")
+	end := strings.LastIndex(msg, ")")
+	if start < 0 || end < start {
+		return 0, false
+	}
+	fields := strings.Split(msg[start+1:end], ",")
+	if len(fields) != 6 {
+		return 0, false
+	}
+	p1, err1 := strconv.Atoi(strings.TrimSpace(fields[4]))
+	p2, err2 := strconv.Atoi(strings.TrimSpace(fields[5]))
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+	return p1*256 + p2, true
+}
+
+// dataConn opens a passive data connection, EPSV is preferred over PASV
+func (c *conn) dataConn() (net.Conn, error) {
+	_, msg, err := c.cmd(2, "EPSV")
+	port, ok := parseEPSV(msg)
+	if err != nil || !ok {
+		_, msg, err = c.cmd(2, "PASV")
+		if err != nil {
+			return nil, iodine.New(err, nil)
+		}
+		if port, ok = parsePASV(msg); !ok {
+			return nil, iodine.New(&textproto.Error{Code: 227, Msg: msg}, nil)
+		}
+	}
+	netConn, err := net.DialTimeout("tcp", net.JoinHostPort(c.host, strconv.Itoa(port)), dialTimeout)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	if c.tlsConfig != nil {
+		return tls.Client(netConn, c.tlsConfig), nil
+	}
+	return netConn, nil
+}
+
+// transfer opens a data connection for a transfer command such as RETR, STOR or LIST
+func (c *conn) transfer(format string, args ...interface{}) (net.Conn, error) {
+	data, err := c.dataConn()
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	if _, _, err := c.cmd(1, format, args...); err != nil {
+		data.Close()
+		return nil, iodine.New(err, nil)
+	}
+	return data, nil
+}
+
+// finish closes data connection and reads final reply of a transfer
+func (c *conn) finish(data net.Conn) error {
+	if err := data.Close(); err != nil {
+		c.text.ReadResponse(2)
+		return iodine.New(err, nil)
+	}
+	_, _, err := c.text.ReadResponse(2)
+	return iodine.New(err, nil)
+}
+
+// readLines reads a listing from a transfer command
+func (c *conn) readLines(format string, args ...interface{}) ([]string, error) {
+	data, err := c.transfer(format, args...)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	var lines []string
+	reader := textproto.NewReader(bufio.NewReader(data))
+	for {
+		line, err := reader.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			data.Close()
+			c.text.ReadResponse(2)
+			return nil, iodine.New(err, nil)
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := c.finish(data); err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return lines, nil
+}
+
+// isReply - is err a reply of the server, connection is usable after such errors
+func isReply(err error) bool {
+	_, ok := iodine.ToError(err).(*textproto.Error)
+	return ok
+}
+
+// replyCode returns FTP reply code of a server reply error, 0 otherwise
+func replyCode(err error) int {
+	if e, ok := iodine.ToError(err).(*textproto.Error); ok {
+		return e.Code
+	}
+	return 0
+}
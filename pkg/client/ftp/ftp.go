@@ -0,0 +1,420 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ftp
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Config - FTP server access, URL is of the form ftp://user@host[:port]/path,
+// ftps:// for implicit TLS and ftpes:// for explicit TLS
+type Config struct {
+	// User is used when URL has none, anonymous login if both are empty
+	User     string
+	Password string
+	HostURL  string
+}
+
+type ftpClient struct {
+	hostURL   *client.URL
+	path      string
+	addr      string
+	user      string
+	password  string
+	security  int
+	tlsConfig *tls.Config
+}
+
+// idle connections are shared by all clients of same user and host, a connection does one transfer at a time
+var pool = struct {
+	sync.Mutex
+	idle map[string][]*conn
+}{idle: make(map[string][]*conn)}
+
+// New returns an initialized ftpClient structure, connection is made on first use
+func New(config *Config) (client.Client, error) {
+	u, err := client.Parse(config.HostURL)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	var security int
+	var port string
+	switch u.Scheme {
+	case "ftp":
+		security, port = securityNone, "21"
+	case "ftps":
+		security, port = securityImplicit, "990"
+	case "ftpes":
+		security, port = securityExplicit, "21"
+	default:
+		return nil, iodine.New(client.InvalidQueryURL{URL: config.HostURL}, nil)
+	}
+	addr := u.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, port)
+	}
+	host, _, _ := net.SplitHostPort(addr)
+
+	user, password := u.User, config.Password
+	if user == "" {
+		user = config.User
+	}
+	if user == "" {
+		user, password = "anonymous", "anonymous@"
+	}
+	return &ftpClient{
+		hostURL:  u,
+		path:     u.Path,
+		addr:     addr,
+		user:     user,
+		password: password,
+		security: security,
+		tlsConfig: &tls.Config{
+			ServerName: host,
+			// servers commonly require data connections to resume control connection TLS session
+			ClientSessionCache: tls.NewLRUClientSessionCache(0),
+		},
+	}, nil
+}
+
+// URL get url
+func (f *ftpClient) URL() *client.URL {
+	return f.hostURL
+}
+
+func (f *ftpClient) poolKey() string {
+	return f.hostURL.Scheme + "://" + f.user + "@" + f.addr
+}
+
+// connect takes an idle connection or dials a new one
+func (f *ftpClient) connect() (*conn, error) {
+	key := f.poolKey()
+	for {
+		pool.Lock()
+		idle := pool.idle[key]
+		if len(idle) == 0 {
+			pool.Unlock()
+			break
+		}
+		c := idle[len(idle)-1]
+		pool.idle[key] = idle[:len(idle)-1]
+		pool.Unlock()
+		// server may have timed out the idle connection
+		if _, _, err := c.cmd(2, "NOOP"); err == nil {
+			return c, nil
+		}
+		c.netConn.Close()
+	}
+	c, err := dial(f.addr, f.security, f.tlsConfig, f.user, f.password)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return c, nil
+}
+
+// release returns connection to the pool, unless err was not a server reply
+func (f *ftpClient) release(c *conn, err error) {
+	if err != nil && !isReply(err) {
+		c.netConn.Close()
+		return
+	}
+	key := f.poolKey()
+	pool.Lock()
+	pool.idle[key] = append(pool.idle[key], c)
+	pool.Unlock()
+}
+
+// remotePath - path of URL, login folder if URL has none
+func (f *ftpClient) remotePath(c *conn) string {
+	if f.path == "" {
+		return c.home
+	}
+	return f.path
+}
+
+func entryContent(name string, e entry) *client.Content {
+	return &client.Content{
+		Name: name,
+		Time: e.modTime,
+		Size: e.size,
+		Type: e.mode,
+	}
+}
+
+// stat - missing files are reported as client.NotFound
+func (f *ftpClient) stat(c *conn, p string) (entry, error) {
+	e, err := c.stat(p)
+	if replyCode(err) == 550 {
+		return entry{}, iodine.New(client.NotFound{Path: p}, nil)
+	}
+	if err != nil {
+		return entry{}, iodine.New(err, nil)
+	}
+	return e, nil
+}
+
+// Stat - get metadata from path
+func (f *ftpClient) Stat() (*client.Content, error) {
+	c, err := f.connect()
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	p := f.remotePath(c)
+	e, err := f.stat(c, p)
+	f.release(c, err)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return entryContent(p, e), nil
+}
+
+// transferReader - body of a RETR, connection is released when closed
+type transferReader struct {
+	io.Reader
+	f    *ftpClient
+	c    *conn
+	data net.Conn
+}
+
+func (r *transferReader) Close() error {
+	err := r.c.finish(r.data)
+	r.f.release(r.c, err)
+	return iodine.New(err, nil)
+}
+
+// GetObject - read a full or part of remote file
+func (f *ftpClient) GetObject(offset, length int64) (io.ReadCloser, int64, error) {
+	c, err := f.connect()
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	p := f.remotePath(c)
+	e, err := f.stat(c, p)
+	if err != nil {
+		f.release(c, err)
+		return nil, length, iodine.New(err, nil)
+	}
+	if e.mode.IsDir() {
+		f.release(c, nil)
+		return nil, length, iodine.New(client.ISFolder{Path: p}, nil)
+	}
+	if offset < 0 || offset > e.size || length < 0 || offset+length > e.size {
+		f.release(c, nil)
+		return nil, length, iodine.New(client.InvalidRange{Offset: offset}, nil)
+	}
+	if length == 0 {
+		length = e.size - offset
+	}
+	if offset > 0 {
+		if _, _, err := c.cmd(3, "REST %d", offset); err != nil {
+			f.release(c, err)
+			return nil, length, iodine.New(err, nil)
+		}
+	}
+	data, err := c.transfer("RETR %s", p)
+	if err != nil {
+		f.release(c, err)
+		return nil, length, iodine.New(err, nil)
+	}
+	return &transferReader{Reader: io.LimitReader(data, length), f: f, c: c, data: data}, length, nil
+}
+
+// mkdirAll creates every missing folder of p, MKD fails for existing folders so its errors are ignored
+func mkdirAll(c *conn, p string) {
+	for i := 1; i <= len(p); i++ {
+		if i == len(p) || p[i] == '/' {
+			if dir := p[:i]; dir != "." && dir != "/" {
+				c.cmd(2, "MKD %s", dir)
+			}
+		}
+	}
+}
+
+// PutObject - create a remote file, missing parent folders are created
+func (f *ftpClient) PutObject(size int64, data io.Reader) error {
+	c, err := f.connect()
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	p := f.remotePath(c)
+	mkdirAll(c, path.Dir(p))
+	body, err := c.transfer("STOR %s", p)
+	if err != nil {
+		f.release(c, err)
+		return iodine.New(err, nil)
+	}
+	// size could be 0 for virtual files, read till EOF for such files
+	if size > 0 {
+		_, err = io.CopyN(body, data, size)
+	} else {
+		_, err = io.Copy(body, data)
+	}
+	if err != nil {
+		// transfer is left incomplete, do not reuse the connection
+		body.Close()
+		c.netConn.Close()
+		return iodine.New(err, nil)
+	}
+	err = c.finish(body)
+	f.release(c, err)
+	return iodine.New(err, nil)
+}
+
+// List - list files and folders
+func (f *ftpClient) List(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel)
+	switch recursive {
+	case true:
+		go f.listRecursiveInRoutine(contentCh)
+	default:
+		go f.listInRoutine(contentCh)
+	}
+	return contentCh
+}
+
+func (f *ftpClient) listInRoutine(contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	c, err := f.connect()
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	p := f.remotePath(c)
+	e, err := f.stat(c, p)
+	if err != nil {
+		f.release(c, err)
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	if !e.mode.IsDir() {
+		f.release(c, nil)
+		contentCh <- client.ContentOnChannel{Content: entryContent(p, e)}
+		return
+	}
+	entries, err := c.list(p)
+	f.release(c, err)
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	for _, e := range entries {
+		contentCh <- client.ContentOnChannel{Content: entryContent(e.name, e)}
+	}
+}
+
+// listRecursiveInRoutine - names are relative to the last folder of the listed path, same as fs client
+func (f *ftpClient) listRecursiveInRoutine(contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	c, err := f.connect()
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	root := f.remotePath(c)
+	stripPrefix := root[:strings.LastIndex(root, "/")+1]
+	dirs := []string{root}
+	for len(dirs) > 0 {
+		dir := dirs[len(dirs)-1]
+		dirs = dirs[:len(dirs)-1]
+		entries, err := c.list(dir)
+		if err != nil && !isReply(err) {
+			c.netConn.Close()
+			contentCh <- client.ContentOnChannel{Err: iodine.New(err, map[string]string{"Target": dir})}
+			return
+		}
+		if err != nil {
+			contentCh <- client.ContentOnChannel{Err: iodine.New(err, map[string]string{"Target": dir})}
+			continue
+		}
+		for _, e := range entries {
+			p := path.Join(dir, e.name)
+			if e.mode.IsDir() {
+				dirs = append(dirs, p)
+			}
+			contentCh <- client.ContentOnChannel{Content: entryContent(strings.TrimPrefix(p, stripPrefix), e)}
+		}
+	}
+	f.release(c, nil)
+}
+
+// isValidBucketACL - is acl a valid ACL?
+func isValidBucketACL(acl string) bool {
+	switch acl {
+	case "private", "public-read", "public-read-write", "authenticated-read", "":
+		return true
+	default:
+		return false
+	}
+}
+
+// aclToPerm - convert acl to folder mode, same mapping as fs client
+func aclToPerm(acl string) os.FileMode {
+	switch acl {
+	case "public-read":
+		return os.FileMode(0500)
+	case "public-read-write":
+		return os.FileMode(0777)
+	case "authenticated-read":
+		return os.FileMode(0770)
+	default:
+		return os.FileMode(0700)
+	}
+}
+
+// MakeBucket - create a remote folder
+func (f *ftpClient) MakeBucket() error {
+	c, err := f.connect()
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	p := f.remotePath(c)
+	mkdirAll(c, p)
+	e, err := f.stat(c, p)
+	f.release(c, err)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if !e.mode.IsDir() {
+		return iodine.New(client.NotFolder{Path: p}, nil)
+	}
+	return nil
+}
+
+// SetBucketACL - create a remote folder with permissions of the acl, server must support SITE CHMOD
+func (f *ftpClient) SetBucketACL(acl string) error {
+	if !isValidBucketACL(acl) {
+		return iodine.New(client.InvalidACLType{ACL: acl}, nil)
+	}
+	c, err := f.connect()
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	p := f.remotePath(c)
+	mkdirAll(c, p)
+	_, _, err = c.cmd(2, "SITE CHMOD %o %s", uint32(aclToPerm(acl)), p)
+	f.release(c, err)
+	return iodine.New(err, nil)
+}
@@ -0,0 +1,396 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ftp
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+// ftpServer - in memory FTP server, folders have nil content
+type ftpServer struct {
+	mutex    sync.Mutex
+	listener net.Listener
+	mlst     bool
+	modTime  time.Time
+	files    map[string][]byte
+	modes    map[string]uint32
+}
+
+func newFTPServer(c *C, mlst bool) *ftpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	server := &ftpServer{
+		listener: listener,
+		mlst:     mlst,
+		modTime:  time.Date(2015, time.June, 24, 10, 30, 0, 0, time.UTC),
+		files:    map[string][]byte{"/": nil, "/home": nil},
+		modes:    make(map[string]uint32),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *ftpServer) URL(user, p string) string {
+	return "ftp://" + user + "@" + s.listener.Addr().String() + p
+}
+
+func (s *ftpServer) abs(p string) string {
+	if !strings.HasPrefix(p, "/") {
+		p = "/home/" + p
+	}
+	return path.Clean(p)
+}
+
+func (s *ftpServer) children(dir string) []string {
+	var names []string
+	for name := range s.files {
+		if name != dir && path.Dir(name) == dir {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *ftpServer) serve(netConn net.Conn) {
+	conn := textproto.NewConn(netConn)
+	defer conn.Close()
+	var data net.Listener
+	var offset int64
+	conn.PrintfLine("220 test server")
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+		command, arg := line, ""
+		if i := strings.Index(line, " "); i >= 0 {
+			command, arg = line[:i], line[i+1:]
+		}
+		s.mutex.Lock()
+		content, exists := s.files[s.abs(arg)]
+		s.mutex.Unlock()
+		switch command {
+		case "USER":
+			conn.PrintfLine("331 password please")
+		case "PASS":
+			if arg != "secret" {
+				conn.PrintfLine("530 login incorrect")
+				continue
+			}
+			conn.PrintfLine("230 logged in")
+		case "TYPE", "NOOP":
+			conn.PrintfLine("200 ok")
+		case "FEAT":
+			if s.mlst {
+				conn.PrintfLine("211-Features:\r\n MLST type*;size*;modify*;\r\n211 End")
+				continue
+			}
+			conn.PrintfLine("211-Features:\r\n SIZE\r\n211 End")
+		case "PWD":
+			conn.PrintfLine("257 \"/home\" is current directory")
+		case "CWD":
+			if !exists || content != nil {
+				conn.PrintfLine("550 not a folder")
+				continue
+			}
+			conn.PrintfLine("250 ok")
+		case "SIZE":
+			if !exists || content == nil {
+				conn.PrintfLine("550 not a file")
+				continue
+			}
+			conn.PrintfLine("213 %d", len(content))
+		case "MDTM":
+			conn.PrintfLine("213 %s", s.modTime.Format("20060102150405"))
+		case "MLST":
+			if !s.mlst || !exists {
+				conn.PrintfLine("550 not found")
+				continue
+			}
+			conn.PrintfLine("250-Listing %s\r\n %s\r\n250 End", arg, s.facts(s.abs(arg), content))
+		case "MKD":
+			s.mutex.Lock()
+			if _, ok := s.files[path.Dir(s.abs(arg))]; !ok || exists {
+				s.mutex.Unlock()
+				conn.PrintfLine("550 cannot create")
+				continue
+			}
+			s.files[s.abs(arg)] = nil
+			s.mutex.Unlock()
+			conn.PrintfLine("257 created")
+		case "SITE":
+			fields := strings.SplitN(arg, " ", 3)
+			mode, _ := strconv.ParseUint(fields[1], 8, 32)
+			s.mutex.Lock()
+			s.modes[s.abs(fields[2])] = uint32(mode)
+			s.mutex.Unlock()
+			conn.PrintfLine("200 mode changed")
+		case "EPSV":
+			data, err = net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				return
+			}
+			conn.PrintfLine("229 Entering Extended Passive Mode (|||%d|)", data.Addr().(*net.TCPAddr).Port)
+		case "REST":
+			offset, _ = strconv.ParseInt(arg, 10, 64)
+			conn.PrintfLine("350 restarting")
+		case "RETR", "STOR", "LIST", "MLSD":
+			if command == "RETR" && (!exists || content == nil) {
+				conn.PrintfLine("550 not a file")
+				continue
+			}
+			conn.PrintfLine("150 opening data connection")
+			dataConn, err := data.Accept()
+			data.Close()
+			if err != nil {
+				return
+			}
+			switch command {
+			case "RETR":
+				dataConn.Write(content[offset:])
+				offset = 0
+			case "STOR":
+				body, _ := ioutil.ReadAll(dataConn)
+				s.mutex.Lock()
+				s.files[s.abs(arg)] = body
+				s.mutex.Unlock()
+			case "LIST", "MLSD":
+				s.mutex.Lock()
+				for _, name := range s.children(s.abs(arg)) {
+					if command == "MLSD" {
+						fmt.Fprintf(dataConn, "%s\r\n", s.facts(name, s.files[name]))
+						continue
+					}
+					kind, size := "-rw-r--r--", len(s.files[name])
+					if s.files[name] == nil {
+						kind, size = "drwxr-xr-x", 4096
+					}
+					fmt.Fprintf(dataConn, "%s 1 owner group %d %s %s\r\n", kind, size, s.modTime.Format("Jan _2  2006"), path.Base(name))
+				}
+				s.mutex.Unlock()
+			}
+			dataConn.Close()
+			conn.PrintfLine("226 transfer complete")
+		case "QUIT":
+			conn.PrintfLine("221 bye")
+			return
+		default:
+			conn.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func (s *ftpServer) facts(name string, content []byte) string {
+	kind := "file"
+	if content == nil {
+		kind = "dir"
+	}
+	return fmt.Sprintf("type=%s;size=%d;modify=%s; %s", kind, len(content), s.modTime.Format("20060102150405"), path.Base(name))
+}
+
+func newClient(c *C, urlStr string) client.Client {
+	config := new(Config)
+	config.Password = "secret"
+	config.HostURL = urlStr
+	clnt, err := New(config)
+	c.Assert(err, IsNil)
+	return clnt
+}
+
+func (s *MySuite) TestParse(c *C) {
+	port, ok := parseEPSV("229 Entering Extended Passive Mode (|||6446|)")
+	c.Assert(ok, Equals, true)
+	c.Assert(port, Equals, 6446)
+	port, ok = parsePASV("227 Entering Passive Mode (192,168,1,2,19,137)")
+	c.Assert(ok, Equals, true)
+	c.Assert(port, Equals, 19*256+137)
+	c.Assert(parsePWD(`257 "/home/my ""quoted"" dir" is current directory`), Equals, `/home/my "quoted" dir`)
+
+	now := time.Date(2015, time.June, 24, 0, 0, 0, 0, time.UTC)
+	e, ok := parseLIST("-rw-r--r--    1 1000     1000         1024 Jun 20 10:30 name with  spaces", now)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.name, Equals, "name with  spaces")
+	c.Assert(e.size, Equals, int64(1024))
+	c.Assert(e.modTime, Equals, time.Date(2015, time.June, 20, 10, 30, 0, 0, time.UTC))
+	// dates in future belong to previous year
+	e, ok = parseLIST("drwxr-xr-x 2 owner group 4096 Dec 31 23:59 dir", now)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.mode.IsDir(), Equals, true)
+	c.Assert(e.modTime.Year(), Equals, 2014)
+	e, ok = parseLIST("-rw-r--r-- 1 owner group 5 Jan  2  2013 old", now)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.modTime, Equals, time.Date(2013, time.January, 2, 0, 0, 0, 0, time.UTC))
+	_, ok = parseLIST("lrwxrwxrwx 1 owner group 4 Jun 20 10:30 link -> target", now)
+	c.Assert(ok, Equals, false)
+	_, ok = parseLIST("total 12", now)
+	c.Assert(ok, Equals, false)
+
+	e, entryType, ok := parseMLSx("type=file;size=5;modify=20150624103000.123;perm=r; my file")
+	c.Assert(ok, Equals, true)
+	c.Assert(entryType, Equals, "file")
+	c.Assert(e.name, Equals, "my file")
+	c.Assert(e.size, Equals, int64(5))
+	c.Assert(e.modTime, Equals, time.Date(2015, time.June, 24, 10, 30, 0, 0, time.UTC))
+	_, entryType, ok = parseMLSx("type=cdir;modify=20150624103000; /home")
+	c.Assert(ok, Equals, true)
+	c.Assert(entryType, Equals, "cdir")
+}
+
+func (s *MySuite) TestNew(c *C) {
+	_, err := New(&Config{HostURL: "http://example.com/path"})
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidQueryURL{})
+
+	for scheme, addr := range map[string]string{"ftp": "example.com:21", "ftps": "example.com:990", "ftpes": "example.com:21"} {
+		clnt, err := New(&Config{HostURL: scheme + "://example.com/path"})
+		c.Assert(err, IsNil)
+		c.Assert(clnt.(*ftpClient).addr, Equals, addr)
+		c.Assert(clnt.(*ftpClient).user, Equals, "anonymous")
+	}
+}
+
+func (s *MySuite) TestObjectOperations(c *C) {
+	for _, mlst := range []bool{false, true} {
+		server := newFTPServer(c, mlst)
+		data := []byte("Hello, World")
+		clnt := newClient(c, server.URL("mc", "/bucket/dir/object"))
+		c.Assert(clnt.PutObject(int64(len(data)), bytes.NewReader(data)), IsNil)
+		c.Assert(server.files["/bucket/dir/object"], DeepEquals, data)
+
+		content, err := clnt.Stat()
+		c.Assert(err, IsNil)
+		c.Assert(content.Size, Equals, int64(len(data)))
+		c.Assert(content.Type.IsRegular(), Equals, true)
+		c.Assert(content.Time, Equals, server.modTime)
+
+		reader, size, err := clnt.GetObject(0, 0)
+		c.Assert(err, IsNil)
+		c.Assert(size, Equals, int64(len(data)))
+		result, err := ioutil.ReadAll(reader)
+		c.Assert(err, IsNil)
+		c.Assert(reader.Close(), IsNil)
+		c.Assert(result, DeepEquals, data)
+
+		reader, _, err = clnt.GetObject(7, 5)
+		c.Assert(err, IsNil)
+		result, err = ioutil.ReadAll(reader)
+		c.Assert(err, IsNil)
+		c.Assert(reader.Close(), IsNil)
+		c.Assert(string(result), Equals, "World")
+
+		_, err = newClient(c, server.URL("mc", "/bucket/missing")).Stat()
+		c.Assert(iodine.ToError(err), FitsTypeOf, client.NotFound{})
+
+		// relative to login folder
+		clnt = newClient(c, server.URL("mc", ""))
+		content, err = clnt.Stat()
+		c.Assert(err, IsNil)
+		c.Assert(content.Name, Equals, "/home")
+		c.Assert(content.Type.IsDir(), Equals, true)
+
+		server.listener.Close()
+	}
+}
+
+func (s *MySuite) TestList(c *C) {
+	for _, mlst := range []bool{false, true} {
+		server := newFTPServer(c, mlst)
+		for _, name := range []string{"/list/a", "/list/dir/b", "/list/dir/c"} {
+			c.Assert(newClient(c, server.URL("mc", name)).PutObject(1, bytes.NewReader([]byte("x"))), IsNil)
+		}
+
+		var names []string
+		for content := range newClient(c, server.URL("mc", "/list")).List(false) {
+			c.Assert(content.Err, IsNil)
+			names = append(names, content.Content.Name)
+		}
+		sort.Strings(names)
+		c.Assert(names, DeepEquals, []string{"a", "dir"})
+
+		names = nil
+		for content := range newClient(c, server.URL("mc", "/list/")).List(true) {
+			c.Assert(content.Err, IsNil)
+			names = append(names, content.Content.Name)
+		}
+		sort.Strings(names)
+		c.Assert(names, DeepEquals, []string{"a", "dir", "dir/b", "dir/c"})
+
+		names = nil
+		for content := range newClient(c, server.URL("mc", "/list/dir")).List(true) {
+			c.Assert(content.Err, IsNil)
+			c.Assert(content.Content.Type.IsRegular(), Equals, true)
+			names = append(names, content.Content.Name)
+		}
+		sort.Strings(names)
+		c.Assert(names, DeepEquals, []string{"dir/b", "dir/c"})
+
+		server.listener.Close()
+	}
+}
+
+func (s *MySuite) TestBucketOperations(c *C) {
+	server := newFTPServer(c, false)
+	defer server.listener.Close()
+
+	clnt := newClient(c, server.URL("mc", "/newbucket/nested"))
+	c.Assert(clnt.MakeBucket(), IsNil)
+	content, err := clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Type.IsDir(), Equals, true)
+
+	c.Assert(clnt.SetBucketACL("public-read"), IsNil)
+	c.Assert(os.FileMode(server.modes["/newbucket/nested"]), Equals, os.FileMode(0500))
+
+	err = clnt.SetBucketACL("invalid")
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidACLType{})
+
+	config := &Config{HostURL: server.URL("other", "/"), Password: "wrong"}
+	clnt, err = New(config)
+	c.Assert(err, IsNil)
+	_, err = clnt.Stat()
+	c.Assert(err, Not(IsNil))
+}
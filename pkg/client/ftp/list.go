@@ -0,0 +1,186 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ftp
+
+import (
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// entry - a file or folder in a listing
+type entry struct {
+	name    string
+	size    int64
+	modTime time.Time
+	mode    os.FileMode
+}
+
+// parseMLSx - RFC 3659 facts line, "type=file;size=5;modify=20150624103000; name".
+// Type is returned as well, listings contain the folder itself as cdir and its parent as pdir
+func parseMLSx(line string) (entry, string, bool) {
+	i := strings.Index(line, " ")
+	if i < 0 {
+		return entry{}, "", false
+	}
+	e := entry{name: line[i+1:], mode: 0644}
+	var entryType string
+	for _, fact := range strings.Split(line[:i], ";") {
+		kv := strings.SplitN(fact, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch strings.ToLower(kv[0]) {
+		case "type":
+			entryType = strings.ToLower(kv[1])
+			switch entryType {
+			case "file":
+			case "dir", "cdir", "pdir":
+				e.mode = os.ModeDir | 0755
+			default:
+				// OS specific types such as symlinks
+				return entry{}, "", false
+			}
+		case "size":
+			e.size, _ = strconv.ParseInt(kv[1], 10, 64)
+		case "modify":
+			e.modTime = parseTimeVal(kv[1])
+		}
+	}
+	return e, entryType, true
+}
+
+// parseTimeVal - YYYYMMDDHHMMSS[.sss] in UTC, used by MLSx and MDTM
+func parseTimeVal(value string) time.Time {
+	if i := strings.Index(value, "."); i >= 0 {
+		value = value[:i]
+	}
+	t, err := time.Parse("20060102150405", value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// parseLIST - Unix ls style line, "-rw-r--r-- 1 owner group 5 Jun 24 10:30 name"
+func parseLIST(line string, now time.Time) (entry, bool) {
+	// locate first 8 fields, name is the rest of the line and may contain spaces
+	var fields []string
+	rest := line
+	for len(fields) < 8 {
+		rest = strings.TrimLeft(rest, " ")
+		i := strings.Index(rest, " ")
+		if i < 0 {
+			return entry{}, false
+		}
+		fields = append(fields, rest[:i])
+		rest = rest[i+1:]
+	}
+	name := strings.TrimLeft(rest, " ")
+	if len(fields[0]) != 10 || name == "" || name == "." || name == ".." {
+		return entry{}, false
+	}
+	e := entry{name: name, mode: 0644}
+	switch fields[0][0] {
+	case '-':
+	case 'd':
+		e.mode = os.ModeDir | 0755
+	default:
+		// symlinks and devices cannot be copied
+		return entry{}, false
+	}
+	size, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return entry{}, false
+	}
+	e.size = size
+	// "Jun 24 10:30" within last six months, "Jun 24  2014" otherwise
+	if strings.Contains(fields[7], ":") {
+		t, err := time.Parse("Jan 2 15:04 2006", fields[5]+" "+fields[6]+" "+fields[7]+" "+strconv.Itoa(now.Year()))
+		if err == nil && t.After(now.Add(24*time.Hour)) {
+			t = t.AddDate(-1, 0, 0)
+		}
+		e.modTime = t
+	} else {
+		e.modTime, _ = time.Parse("Jan 2 2006", fields[5]+" "+fields[6]+" "+fields[7])
+	}
+	return e, true
+}
+
+// list reads a folder, MLSD is preferred as LIST format is not standardized
+func (c *conn) list(path string) ([]entry, error) {
+	var entries []entry
+	if c.mlst {
+		lines, err := c.readLines("MLSD %s", path)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			if e, entryType, ok := parseMLSx(line); ok && entryType != "cdir" && entryType != "pdir" {
+				entries = append(entries, e)
+			}
+		}
+		return entries, nil
+	}
+	lines, err := c.readLines("LIST %s", path)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for _, line := range lines {
+		if e, ok := parseLIST(line, now); ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// stat returns entry of a file or folder, MLST is preferred over SIZE, MDTM and CWD
+func (c *conn) stat(path string) (entry, error) {
+	if c.mlst {
+		_, msg, err := c.cmd(2, "MLST %s", path)
+		if err != nil {
+			return entry{}, err
+		}
+		for _, line := range strings.Split(msg, "\n")[1:] {
+			if e, _, ok := parseMLSx(strings.TrimSpace(line)); ok {
+				return e, nil
+			}
+		}
+		return entry{}, &textproto.Error{Code: 550, Msg: "no facts for " + path}
+	}
+	if _, msg, err := c.cmd(2, "SIZE %s", path); err == nil {
+		e := entry{name: path, mode: 0644}
+		e.size, _ = strconv.ParseInt(strings.TrimSpace(msg), 10, 64)
+		if _, msg, err := c.cmd(2, "MDTM %s", path); err == nil {
+			e.modTime = parseTimeVal(strings.TrimSpace(msg))
+		}
+		return e, nil
+	} else if !isReply(err) {
+		return entry{}, err
+	}
+	_, _, err := c.cmd(2, "CWD %s", path)
+	if err != nil {
+		return entry{}, err
+	}
+	if _, _, err := c.cmd(2, "CWD %s", c.home); err != nil {
+		return entry{}, err
+	}
+	return entry{name: path, mode: os.ModeDir | 0755}, nil
+}
@@ -0,0 +1,187 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcs
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+const (
+	defaultTokenURI = "https://accounts.google.com/o/oauth2/token"
+	storageScope    = "https://www.googleapis.com/auth/devstorage.full_control"
+	jwtGrantType    = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+)
+
+// serviceAccount - subset of the service account JSON key file downloaded from Google developers console
+type serviceAccount struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// loadServiceAccount reads and validates a service account JSON key file
+func loadServiceAccount(credentialsFile string) (*serviceAccount, *rsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, nil, iodine.New(err, nil)
+	}
+	account := new(serviceAccount)
+	if err := json.Unmarshal(data, account); err != nil {
+		return nil, nil, iodine.New(err, nil)
+	}
+	if account.Type != "service_account" || account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, nil, iodine.New(InvalidCredentials{File: credentialsFile}, nil)
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = defaultTokenURI
+	}
+	key, err := parsePrivateKey([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, nil, iodine.New(InvalidCredentials{File: credentialsFile}, nil)
+	}
+	return account, key, nil
+}
+
+// parsePrivateKey parses PEM encoded PKCS8 or PKCS1 RSA private key
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, iodine.New(InvalidPrivateKey{}, nil)
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, iodine.New(InvalidPrivateKey{}, nil)
+		}
+		return rsaKey, nil
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, iodine.New(InvalidPrivateKey{}, nil)
+	}
+	return key, nil
+}
+
+// tokenSource exchanges a signed JWT assertion for OAuth2 access tokens and caches them until expiry
+type tokenSource struct {
+	mutex      *sync.Mutex
+	account    *serviceAccount
+	key        *rsa.PrivateKey
+	httpClient *http.Client
+
+	accessToken string
+	expiry      time.Time
+}
+
+func newTokenSource(account *serviceAccount, key *rsa.PrivateKey, httpClient *http.Client) *tokenSource {
+	return &tokenSource{
+		mutex:      new(sync.Mutex),
+		account:    account,
+		key:        key,
+		httpClient: httpClient,
+	}
+}
+
+// Token returns a valid access token, refreshing it a minute before it expires
+func (t *tokenSource) Token() (string, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.accessToken != "" && time.Now().Add(time.Minute).Before(t.expiry) {
+		return t.accessToken, nil
+	}
+	assertion, err := t.assertion(time.Now())
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	values := url.Values{}
+	values.Set("grant_type", jwtGrantType)
+	values.Set("assertion", assertion)
+	res, err := t.httpClient.PostForm(t.account.TokenURI, values)
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", iodine.New(toErrorResponse(res), nil)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", iodine.New(err, nil)
+	}
+	if token.AccessToken == "" {
+		return "", iodine.New(InvalidCredentials{File: t.account.ClientEmail}, nil)
+	}
+	t.accessToken = token.AccessToken
+	t.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return t.accessToken, nil
+}
+
+// assertion builds a RS256 signed JWT claim set, see https://developers.google.com/identity/protocols/OAuth2ServiceAccount
+func (t *tokenSource) assertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": t.account.PrivateKeyID,
+	})
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   t.account.ClientEmail,
+		"scope": storageScope,
+		"aud":   t.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	signingInput := encodeSegment(header) + "." + encodeSegment(claims)
+	sum := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, t.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	return signingInput + "." + encodeSegment(signature), nil
+}
+
+// encodeSegment - base64url encoding without padding as required by JWT
+func encodeSegment(data []byte) string {
+	return strings.TrimRight(base64.URLEncoding.EncodeToString(data), "=")
+}
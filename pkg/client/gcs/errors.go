@@ -0,0 +1,78 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcs
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// InvalidCredentials - service account key file is unusable
+type InvalidCredentials struct {
+	File string
+}
+
+func (e InvalidCredentials) Error() string {
+	return "Invalid service account credentials ‘" + e.File + "’"
+}
+
+// InvalidPrivateKey - private key in service account key file could not be parsed
+type InvalidPrivateKey struct{}
+
+func (e InvalidPrivateKey) Error() string {
+	return "Invalid RSA private key in service account credentials"
+}
+
+// ErrorResponse - error returned by Google Cloud Storage JSON API
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e ErrorResponse) Error() string {
+	return "Google Cloud Storage error " + strconv.Itoa(e.Code) + ": " + e.Message
+}
+
+// toErrorResponse decodes JSON API error body, falls back to HTTP status
+func toErrorResponse(res *http.Response) ErrorResponse {
+	errResponse := ErrorResponse{
+		Code:    res.StatusCode,
+		Message: http.StatusText(res.StatusCode),
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return errResponse
+	}
+	var errBody struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &errBody) != nil || len(errBody.Error) == 0 {
+		return errResponse
+	}
+	// JSON API returns an object, OAuth2 token endpoint returns a string
+	var apiErr ErrorResponse
+	if json.Unmarshal(errBody.Error, &apiErr) == nil && apiErr.Message != "" {
+		return apiErr
+	}
+	var tokenErr string
+	if json.Unmarshal(errBody.Error, &tokenErr) == nil && tokenErr != "" {
+		errResponse.Message = tokenErr
+	}
+	return errResponse
+}
@@ -0,0 +1,560 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Config - see https://cloud.google.com/storage/docs/json_api/
+type Config struct {
+	// Path to service account JSON key file
+	CredentialsFile string
+	HostURL         string
+	AppName         string
+	AppVersion      string
+	AppComments     []string
+	Debug           bool
+}
+
+type gcsClient struct {
+	hostURL    *client.URL
+	endpoint   string
+	projectID  string
+	userAgent  string
+	httpClient *http.Client
+	token      *tokenSource
+}
+
+// objectResource - subset of JSON API object resource
+type objectResource struct {
+	Name         string    `json:"name"`
+	Size         string    `json:"size"`
+	Updated      time.Time `json:"updated"`
+	Etag         string    `json:"etag"`
+	MD5Hash      string    `json:"md5Hash"`
+	StorageClass string    `json:"storageClass"`
+	Owner        struct {
+		Entity   string `json:"entity"`
+		EntityID string `json:"entityId"`
+	} `json:"owner"`
+}
+
+type objectList struct {
+	Items         []objectResource `json:"items"`
+	Prefixes      []string         `json:"prefixes"`
+	NextPageToken string           `json:"nextPageToken"`
+}
+
+// bucketResource - subset of JSON API bucket resource
+type bucketResource struct {
+	Name        string    `json:"name"`
+	TimeCreated time.Time `json:"timeCreated"`
+}
+
+type bucketList struct {
+	Items         []bucketResource `json:"items"`
+	NextPageToken string           `json:"nextPageToken"`
+}
+
+// New returns an initialized gcsClient structure. if debug use a internal trace transport
+func New(config *Config) (client.Client, error) {
+	u, err := client.Parse(config.HostURL)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	if config.CredentialsFile == "" {
+		return nil, iodine.New(InvalidCredentials{File: config.CredentialsFile}, nil)
+	}
+	var transport http.RoundTripper
+	switch {
+	case config.Debug == true:
+		transport = s3.GetNewTraceTransport(s3.NewTrace(), http.DefaultTransport)
+	default:
+		transport = s3.GetNewLogTransport(http.DefaultTransport)
+	}
+	httpClient := &http.Client{Transport: transport}
+	account, key, err := loadServiceAccount(config.CredentialsFile)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	userAgent := config.AppName + "/" + config.AppVersion
+	if len(config.AppComments) > 0 {
+		userAgent = userAgent + " (" + strings.Join(config.AppComments, "; ") + ")"
+	}
+	return &gcsClient{
+		hostURL:    u,
+		endpoint:   u.Scheme + "://" + u.Host,
+		projectID:  account.ProjectID,
+		userAgent:  userAgent,
+		httpClient: httpClient,
+		token:      newTokenSource(account, key, httpClient),
+	}, nil
+}
+
+// URL get url
+func (c *gcsClient) URL() *client.URL {
+	return c.hostURL
+}
+
+// escape - object names are a single path segment in JSON API, slashes included
+func escape(name string) string {
+	return strings.Replace(url.QueryEscape(name), "+", "%20", -1)
+}
+
+func (c *gcsClient) bucketURL(bucket string) string {
+	return c.endpoint + "/storage/v1/b/" + escape(bucket)
+}
+
+func (c *gcsClient) objectURL(bucket, object string) string {
+	return c.bucketURL(bucket) + "/o/" + escape(object)
+}
+
+// do executes an authenticated request, non 2xx responses are turned into ErrorResponse
+func (c *gcsClient) do(req *http.Request) (*http.Response, error) {
+	token, err := c.token.Token()
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("User-Agent", c.userAgent)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+		return nil, iodine.New(toErrorResponse(res), nil)
+	}
+	return res, nil
+}
+
+// doJSON executes an authenticated request and decodes JSON response into v, v may be nil
+func (c *gcsClient) doJSON(method, urlStr string, body interface{}, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return iodine.New(err, nil)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, urlStr, reader)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := c.do(req)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	if v == nil {
+		return nil
+	}
+	return iodine.New(json.NewDecoder(res.Body).Decode(v), nil)
+}
+
+// isNotFound - is JSON API 404 response
+func isNotFound(err error) bool {
+	errResponse, ok := iodine.ToError(err).(ErrorResponse)
+	return ok && errResponse.Code == http.StatusNotFound
+}
+
+// url2BucketAndObject gives bucketName and objectName from URL path
+func (c *gcsClient) url2BucketAndObject() (bucketName, objectName string) {
+	splits := strings.SplitN(c.hostURL.Path, string(c.hostURL.Separator), 3)
+	switch len(splits) {
+	case 0, 1:
+		bucketName = ""
+		objectName = ""
+	case 2:
+		bucketName = splits[1]
+		objectName = ""
+	case 3:
+		bucketName = splits[1]
+		objectName = splits[2]
+	}
+	return bucketName, objectName
+}
+
+// objectContent converts object resource into client Content
+func objectContent(name string, object objectResource) *client.Content {
+	content := new(client.Content)
+	content.Name = name
+	content.Time = object.Updated
+	content.Size, _ = strconv.ParseInt(object.Size, 10, 64)
+	content.Type = os.FileMode(0664)
+	// Report MD5 in hex like S3 ETag, composite objects do not have one
+	content.ETag = object.Etag
+	if md5sum, err := base64.StdEncoding.DecodeString(object.MD5Hash); err == nil && len(md5sum) > 0 {
+		content.ETag = hex.EncodeToString(md5sum)
+	}
+	content.Owner = object.Owner.Entity
+	content.OwnerID = object.Owner.EntityID
+	content.StorageClass = object.StorageClass
+	return content
+}
+
+func (c *gcsClient) statObject(bucket, object string) (objectResource, error) {
+	var resource objectResource
+	err := c.doJSON("GET", c.objectURL(bucket, object), nil, &resource)
+	if err != nil {
+		return objectResource{}, iodine.New(err, nil)
+	}
+	return resource, nil
+}
+
+// GetObject - get object
+func (c *gcsClient) GetObject(offset, length int64) (io.ReadCloser, int64, error) {
+	bucket, object := c.url2BucketAndObject()
+	resource, err := c.statObject(bucket, object)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	size, _ := strconv.ParseInt(resource.Size, 10, 64)
+	if offset < 0 || offset > size || length < 0 || offset+length > size {
+		return nil, length, iodine.New(client.InvalidRange{Offset: offset}, nil)
+	}
+	if length == 0 {
+		length = size - offset
+	}
+	req, err := http.NewRequest("GET", c.objectURL(bucket, object)+"?alt=media", nil)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	if offset > 0 || length < size {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+length-1, 10))
+	}
+	res, err := c.do(req)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	return res.Body, length, nil
+}
+
+// PutObject - put object, uses simple media upload
+func (c *gcsClient) PutObject(size int64, data io.Reader) error {
+	return c.PutObjectWithMetadata(size, data, nil)
+}
+
+// PutObjectWithMetadata - put object with Content-Type and Cache-Control, cache control is patched after upload
+func (c *gcsClient) PutObjectWithMetadata(size int64, data io.Reader, metadata map[string]string) error {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object == "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	urlStr := c.endpoint + "/upload/storage/v1/b/" + escape(bucket) + "/o?uploadType=media&name=" + escape(object)
+	if size > 0 {
+		data = io.LimitReader(data, size)
+	}
+	req, err := http.NewRequest("POST", urlStr, data)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	// size could be 0 for virtual files, let transport stream them chunked
+	if size > 0 {
+		req.ContentLength = size
+	}
+	contentType := metadata["Content-Type"]
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+	res, err := c.do(req)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if err := res.Body.Close(); err != nil {
+		return iodine.New(err, nil)
+	}
+	if cacheControl := metadata["Cache-Control"]; cacheControl != "" {
+		return iodine.New(c.doJSON("PATCH", c.objectURL(bucket, object), map[string]string{"cacheControl": cacheControl}, nil), nil)
+	}
+	return nil
+}
+
+// MakeBucket - make a new bucket in the project of the service account
+func (c *gcsClient) MakeBucket() error {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	urlStr := c.endpoint + "/storage/v1/b?predefinedAcl=private&project=" + url.QueryEscape(c.projectID)
+	err := c.doJSON("POST", urlStr, map[string]string{"name": bucket}, nil)
+	if errResponse, ok := iodine.ToError(err).(ErrorResponse); ok && errResponse.Code == http.StatusConflict {
+		return iodine.New(client.BucketExists{Bucket: bucket}, nil)
+	}
+	return iodine.New(err, nil)
+}
+
+// SetBucketACL add canned acl's on a bucket
+func (c *gcsClient) SetBucketACL(acl string) error {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	// JSON API names for S3 canned ACLs
+	var predefinedACL string
+	switch acl {
+	case "private":
+		predefinedACL = "private"
+	case "public-read":
+		predefinedACL = "publicRead"
+	case "public-read-write":
+		predefinedACL = "publicReadWrite"
+	case "authenticated-read":
+		predefinedACL = "authenticatedRead"
+	default:
+		return iodine.New(client.InvalidACLType{ACL: acl}, nil)
+	}
+	urlStr := c.bucketURL(bucket) + "?predefinedAcl=" + predefinedACL
+	return iodine.New(c.doJSON("PATCH", urlStr, map[string]string{}, nil), nil)
+}
+
+// Stat - get metadata of a bucket or object
+func (c *gcsClient) Stat() (*client.Content, error) {
+	bucket, object := c.url2BucketAndObject()
+	switch {
+	case bucket == "" && object == "":
+		// Verifies credentials and project access
+		var list bucketList
+		urlStr := c.endpoint + "/storage/v1/b?maxResults=1&project=" + url.QueryEscape(c.projectID)
+		if err := c.doJSON("GET", urlStr, nil, &list); err != nil {
+			return nil, iodine.New(err, nil)
+		}
+		return &client.Content{Type: os.ModeDir}, nil
+	case object != "":
+		resource, err := c.statObject(bucket, object)
+		if err == nil {
+			return objectContent(resource.Name, resource), nil
+		}
+		if !isNotFound(err) {
+			return nil, iodine.New(err, nil)
+		}
+		// Not an object, it still is a directory if anything is stored under it
+		var list objectList
+		listURL := c.bucketURL(bucket) + "/o?maxResults=1&prefix=" + escape(strings.TrimSuffix(object, "/")+"/")
+		if err := c.doJSON("GET", listURL, nil, &list); err != nil {
+			return nil, iodine.New(err, nil)
+		}
+		if len(list.Items) == 0 && len(list.Prefixes) == 0 {
+			return nil, iodine.New(client.ObjectNotFound{Bucket: bucket, Object: object}, nil)
+		}
+		return &client.Content{Name: object, Time: time.Now(), Type: os.ModeDir}, nil
+	}
+	var resource bucketResource
+	if err := c.doJSON("GET", c.bucketURL(bucket), nil, &resource); err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return &client.Content{Name: resource.Name, Time: resource.TimeCreated, Type: os.ModeDir}, nil
+}
+
+type bucketOnChannel struct {
+	bucket bucketResource
+	err    error
+}
+
+// listBuckets lists all buckets of the service account project
+func (c *gcsClient) listBuckets() <-chan bucketOnChannel {
+	bucketCh := make(chan bucketOnChannel)
+	go func() {
+		defer close(bucketCh)
+		pageToken := ""
+		for {
+			var list bucketList
+			urlStr := c.endpoint + "/storage/v1/b?project=" + url.QueryEscape(c.projectID)
+			if pageToken != "" {
+				urlStr = urlStr + "&pageToken=" + url.QueryEscape(pageToken)
+			}
+			if err := c.doJSON("GET", urlStr, nil, &list); err != nil {
+				bucketCh <- bucketOnChannel{err: iodine.New(err, nil)}
+				return
+			}
+			for _, bucket := range list.Items {
+				bucketCh <- bucketOnChannel{bucket: bucket}
+			}
+			if list.NextPageToken == "" {
+				return
+			}
+			pageToken = list.NextPageToken
+		}
+	}()
+	return bucketCh
+}
+
+type objectOnChannel struct {
+	object objectResource
+	prefix string
+	err    error
+}
+
+// listObjects lists objects under prefix, common prefixes are returned only if not recursive
+func (c *gcsClient) listObjects(bucket, prefix string, recursive bool) <-chan objectOnChannel {
+	objectCh := make(chan objectOnChannel)
+	go func() {
+		defer close(objectCh)
+		pageToken := ""
+		for {
+			var list objectList
+			urlStr := c.bucketURL(bucket) + "/o?prefix=" + escape(prefix)
+			if !recursive {
+				urlStr = urlStr + "&delimiter=" + escape(string(c.hostURL.Separator))
+			}
+			if pageToken != "" {
+				urlStr = urlStr + "&pageToken=" + url.QueryEscape(pageToken)
+			}
+			if err := c.doJSON("GET", urlStr, nil, &list); err != nil {
+				objectCh <- objectOnChannel{err: iodine.New(err, nil)}
+				return
+			}
+			for _, prefix := range list.Prefixes {
+				objectCh <- objectOnChannel{prefix: prefix}
+			}
+			for _, object := range list.Items {
+				objectCh <- objectOnChannel{object: object}
+			}
+			if list.NextPageToken == "" {
+				return
+			}
+			pageToken = list.NextPageToken
+		}
+	}()
+	return objectCh
+}
+
+/// Bucket API operations
+
+// List - list at delimited path, if not recursive
+func (c *gcsClient) List(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel)
+	switch recursive {
+	case true:
+		go c.listRecursiveInRoutine(contentCh)
+	default:
+		go c.listInRoutine(contentCh)
+	}
+	return contentCh
+}
+
+func (c *gcsClient) listInRoutine(contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	b, o := c.url2BucketAndObject()
+	if b == "" && o == "" {
+		for bucket := range c.listBuckets() {
+			if bucket.err != nil {
+				contentCh <- client.ContentOnChannel{Err: bucket.err}
+				return
+			}
+			content := new(client.Content)
+			content.Name = bucket.bucket.Name
+			content.Time = bucket.bucket.TimeCreated
+			content.Type = os.ModeDir
+			contentCh <- client.ContentOnChannel{Content: content}
+		}
+		return
+	}
+	if o != "" {
+		resource, err := c.statObject(b, o)
+		if err == nil {
+			contentCh <- client.ContentOnChannel{Content: objectContent(resource.Name, resource)}
+			return
+		}
+		if !isNotFound(err) {
+			contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+			return
+		}
+	}
+	normalizedPrefix := strings.TrimSuffix(o, string(c.hostURL.Separator)) + string(c.hostURL.Separator)
+	normalizeKey := func(key string) string {
+		if normalizedPrefix != key && strings.HasPrefix(key, normalizedPrefix) {
+			return strings.TrimPrefix(key, normalizedPrefix)
+		}
+		return key
+	}
+	for object := range c.listObjects(b, o, false) {
+		if object.err != nil {
+			contentCh <- client.ContentOnChannel{Err: object.err}
+			return
+		}
+		if object.prefix != "" {
+			content := new(client.Content)
+			content.Name = normalizeKey(object.prefix)
+			content.Time = time.Now()
+			content.Type = os.ModeDir
+			contentCh <- client.ContentOnChannel{Content: content}
+			continue
+		}
+		contentCh <- client.ContentOnChannel{Content: objectContent(normalizeKey(object.object.Name), object.object)}
+	}
+}
+
+func (c *gcsClient) listRecursiveInRoutine(contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	b, o := c.url2BucketAndObject()
+	if b == "" && o == "" {
+		for bucket := range c.listBuckets() {
+			if bucket.err != nil {
+				contentCh <- client.ContentOnChannel{Err: bucket.err}
+				return
+			}
+			for object := range c.listObjects(bucket.bucket.Name, "", true) {
+				if object.err != nil {
+					contentCh <- client.ContentOnChannel{Err: object.err}
+					return
+				}
+				name := filepath.Join(bucket.bucket.Name, object.object.Name)
+				contentCh <- client.ContentOnChannel{Content: objectContent(name, object.object)}
+			}
+		}
+		return
+	}
+	for object := range c.listObjects(b, o, true) {
+		if object.err != nil {
+			contentCh <- client.ContentOnChannel{Err: object.err}
+			return
+		}
+		// Names are relative to the parent of the last URL path element, same as s3 client
+		name := object.object.Name
+		switch {
+		case o == "":
+			if !strings.HasSuffix(c.hostURL.Path, string(c.hostURL.Separator)) {
+				name = filepath.Join(b, object.object.Name)
+			}
+		case strings.HasSuffix(o, string(c.hostURL.Separator)):
+			name = strings.TrimPrefix(object.object.Name, o)
+		}
+		contentCh <- client.ContentOnChannel{Content: objectContent(name, object.object)}
+	}
+}
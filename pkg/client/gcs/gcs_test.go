@@ -0,0 +1,295 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcs
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+const testToken = "ya29.test-token"
+
+// gcsHandler is an http.Handler that emulates OAuth2 token endpoint and JSON API for a single bucket
+type gcsHandler struct {
+	c       *C
+	key     *rsa.PrivateKey
+	objects map[string][]byte
+	headers map[string]http.Header
+}
+
+func decodeSegment(c *C, segment string) []byte {
+	if l := len(segment) % 4; l > 0 {
+		segment += strings.Repeat("=", 4-l)
+	}
+	data, err := base64.URLEncoding.DecodeString(segment)
+	c.Assert(err, IsNil)
+	return data
+}
+
+func (h gcsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/token" {
+		h.c.Assert(r.FormValue("grant_type"), Equals, jwtGrantType)
+		parts := strings.Split(r.FormValue("assertion"), ".")
+		h.c.Assert(parts, HasLen, 3)
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		err := rsa.VerifyPKCS1v15(&h.key.PublicKey, crypto.SHA256, sum[:], decodeSegment(h.c, parts[2]))
+		h.c.Assert(err, IsNil)
+		var claims map[string]interface{}
+		h.c.Assert(json.Unmarshal(decodeSegment(h.c, parts[1]), &claims), IsNil)
+		h.c.Assert(claims["iss"], Equals, "mc@project.iam.gserviceaccount.com")
+		h.c.Assert(claims["scope"], Equals, storageScope)
+		w.Write([]byte(`{"access_token":"` + testToken + `","expires_in":3600,"token_type":"Bearer"}`))
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"code":401,"message":"Invalid Credentials"}}`))
+		return
+	}
+	objectResponse := func(name string) string {
+		return `{"name":"` + name + `","size":"` + strconv.Itoa(len(h.objects[name])) +
+			`","updated":"2015-05-21T18:24:21.097Z","etag":"CKih16GjycICEAE=","md5Hash":"XrY7u+Ae7tCTyyK7j1rNww==","storageClass":"STANDARD",` +
+			`"owner":{"entity":"user-minio","entityId":"00b4903a97"}}`
+	}
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/upload/storage/v1/b/bucket/o":
+		h.c.Assert(r.URL.Query().Get("uploadType"), Equals, "media")
+		data, err := ioutil.ReadAll(r.Body)
+		h.c.Assert(err, IsNil)
+		h.objects[r.URL.Query().Get("name")] = data
+		h.headers[r.URL.Query().Get("name")] = http.Header{"Content-Type": {r.Header.Get("Content-Type")}}
+		w.Write([]byte(objectResponse(r.URL.Query().Get("name"))))
+	case r.Method == "POST" && r.URL.Path == "/storage/v1/b":
+		h.c.Assert(r.URL.Query().Get("project"), Equals, "project")
+		w.Write([]byte(`{"name":"bucket"}`))
+	case r.Method == "PATCH" && r.URL.Path == "/storage/v1/b/bucket":
+		h.c.Assert(r.URL.Query().Get("predefinedAcl"), Equals, "publicReadWrite")
+		w.Write([]byte(`{"name":"bucket"}`))
+	case r.Method == "PATCH" && strings.HasPrefix(r.URL.Path, "/storage/v1/b/bucket/o/"):
+		name := strings.TrimPrefix(r.URL.Path, "/storage/v1/b/bucket/o/")
+		var resource map[string]string
+		h.c.Assert(json.NewDecoder(r.Body).Decode(&resource), IsNil)
+		h.headers[name].Set("Cache-Control", resource["cacheControl"])
+		w.Write([]byte(objectResponse(name)))
+	case r.Method == "GET" && r.URL.Path == "/storage/v1/b":
+		w.Write([]byte(`{"items":[{"name":"bucket","timeCreated":"2015-05-20T23:05:09.230Z"}]}`))
+	case r.Method == "GET" && r.URL.Path == "/storage/v1/b/bucket":
+		w.Write([]byte(`{"name":"bucket","timeCreated":"2015-05-20T23:05:09.230Z"}`))
+	case r.Method == "GET" && r.URL.Path == "/storage/v1/b/bucket/o":
+		var items, prefixes []string
+		for name := range h.objects {
+			if !strings.HasPrefix(name, r.URL.Query().Get("prefix")) {
+				continue
+			}
+			rest := strings.TrimPrefix(name, r.URL.Query().Get("prefix"))
+			if delimiter := r.URL.Query().Get("delimiter"); delimiter != "" && strings.Contains(rest, delimiter) {
+				prefixes = append(prefixes, `"`+r.URL.Query().Get("prefix")+rest[:strings.Index(rest, delimiter)+1]+`"`)
+				continue
+			}
+			items = append(items, objectResponse(name))
+		}
+		w.Write([]byte(`{"items":[` + strings.Join(items, ",") + `],"prefixes":[` + strings.Join(prefixes, ",") + `]}`))
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/storage/v1/b/bucket/o/"):
+		name := strings.TrimPrefix(r.URL.Path, "/storage/v1/b/bucket/o/")
+		data, ok := h.objects[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":404,"message":"Not Found"}}`))
+			return
+		}
+		if r.URL.Query().Get("alt") != "media" {
+			w.Write([]byte(objectResponse(name)))
+			return
+		}
+		if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+			var start, end int
+			_, err := fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end)
+			h.c.Assert(err, IsNil)
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(data[start : end+1])
+			return
+		}
+		w.Write(data)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct {
+	root string
+	key  *rsa.PrivateKey
+}
+
+var _ = Suite(&MySuite{})
+
+func (s *MySuite) SetUpSuite(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "gcs-")
+	c.Assert(err, IsNil)
+	s.root = root
+	s.key, err = rsa.GenerateKey(rand.Reader, 1024)
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TearDownSuite(c *C) {
+	os.RemoveAll(s.root)
+}
+
+// newTestClient writes a service account key file pointing its token URI at server
+func (s *MySuite) newTestClient(c *C, server *httptest.Server, path string) *gcsClient {
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(s.key)})
+	account, err := json.Marshal(serviceAccount{
+		Type:         "service_account",
+		ProjectID:    "project",
+		PrivateKeyID: "1",
+		PrivateKey:   string(privateKey),
+		ClientEmail:  "mc@project.iam.gserviceaccount.com",
+		TokenURI:     server.URL + "/token",
+	})
+	c.Assert(err, IsNil)
+	credentialsFile := filepath.Join(s.root, "service-account.json")
+	c.Assert(ioutil.WriteFile(credentialsFile, account, 0600), IsNil)
+
+	conf := new(Config)
+	conf.CredentialsFile = credentialsFile
+	conf.HostURL = server.URL + path
+	clnt, err := New(conf)
+	c.Assert(err, IsNil)
+	return clnt.(*gcsClient)
+}
+
+func (s *MySuite) TestInvalidCredentials(c *C) {
+	conf := new(Config)
+	conf.HostURL = "https://storage.googleapis.com/bucket"
+	_, err := New(conf)
+	c.Assert(err, Not(IsNil))
+
+	credentialsFile := filepath.Join(s.root, "invalid.json")
+	c.Assert(ioutil.WriteFile(credentialsFile, []byte(`{"type":"authorized_user"}`), 0600), IsNil)
+	conf.CredentialsFile = credentialsFile
+	_, err = New(conf)
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *MySuite) TestBucketOperations(c *C) {
+	handler := gcsHandler{c: c, key: s.key, objects: map[string][]byte{"dir/object": []byte("hello")}, headers: map[string]http.Header{}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	gcsc := s.newTestClient(c, server, "/bucket")
+	c.Assert(gcsc.MakeBucket(), IsNil)
+	c.Assert(gcsc.SetBucketACL("public-read-write"), IsNil)
+	c.Assert(gcsc.SetBucketACL("unknown"), Not(IsNil))
+
+	content, err := gcsc.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Name, Equals, "bucket")
+	c.Assert(content.Type.IsDir(), Equals, true)
+
+	for content := range gcsc.List(false) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.Name, Equals, "dir/")
+		c.Assert(content.Content.Type.IsDir(), Equals, true)
+	}
+	for content := range gcsc.List(true) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.Name, Equals, "bucket/dir/object")
+		c.Assert(content.Content.ETag, Equals, "5eb63bbbe01eeed093cb22bb8f5acdc3")
+		c.Assert(content.Content.StorageClass, Equals, "STANDARD")
+	}
+
+	gcsc = s.newTestClient(c, server, "/")
+	for content := range gcsc.List(false) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.Name, Equals, "bucket")
+	}
+	for content := range gcsc.List(true) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.Name, Equals, filepath.Join("bucket", "dir", "object"))
+	}
+}
+
+func (s *MySuite) TestObjectOperations(c *C) {
+	handler := gcsHandler{c: c, key: s.key, objects: map[string][]byte{}, headers: map[string]http.Header{}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	data := "Hello World"
+	gcsc := s.newTestClient(c, server, "/bucket/dir/object name")
+	err := gcsc.PutObject(int64(len(data)), strings.NewReader(data))
+	c.Assert(err, IsNil)
+	c.Assert(string(handler.objects["dir/object name"]), Equals, data)
+	c.Assert(handler.headers["dir/object name"].Get("Content-Type"), Equals, "application/octet-stream")
+
+	err = s.newTestClient(c, server, "/bucket/index.html").PutObjectWithMetadata(int64(len(data)), strings.NewReader(data),
+		map[string]string{"Content-Type": "text/html", "Cache-Control": "no-cache"})
+	c.Assert(err, IsNil)
+	c.Assert(handler.headers["index.html"].Get("Content-Type"), Equals, "text/html")
+	c.Assert(handler.headers["index.html"].Get("Cache-Control"), Equals, "no-cache")
+
+	content, err := gcsc.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Name, Equals, "dir/object name")
+	c.Assert(content.Size, Equals, int64(len(data)))
+	c.Assert(content.Owner, Equals, "user-minio")
+	c.Assert(content.Type.IsRegular(), Equals, true)
+
+	reader, size, err := gcsc.GetObject(0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(len(data)))
+	result, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(string(result), Equals, data)
+
+	reader, size, err = gcsc.GetObject(6, 5)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(5))
+	result, err = ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(string(result), Equals, "World")
+
+	_, _, err = gcsc.GetObject(6, 50)
+	c.Assert(err, Not(IsNil))
+
+	// prefix of an object is a directory
+	gcsc = s.newTestClient(c, server, "/bucket/dir")
+	content, err = gcsc.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Type.IsDir(), Equals, true)
+
+	gcsc = s.newTestClient(c, server, "/bucket/missing")
+	_, err = gcsc.Stat()
+	c.Assert(err, Not(IsNil))
+}
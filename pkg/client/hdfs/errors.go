@@ -0,0 +1,56 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hdfs
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// RemoteException - error returned by WebHDFS
+type RemoteException struct {
+	Code          int    `json:"-"`
+	Exception     string `json:"exception"`
+	JavaClassName string `json:"javaClassName"`
+	Message       string `json:"message"`
+}
+
+func (e RemoteException) Error() string {
+	return "WebHDFS error " + strconv.Itoa(e.Code) + " " + e.Exception + ": " + e.Message
+}
+
+// toRemoteException decodes WebHDFS error body, falls back to HTTP status
+func toRemoteException(res *http.Response) RemoteException {
+	var body struct {
+		RemoteException RemoteException `json:"RemoteException"`
+	}
+	if json.NewDecoder(res.Body).Decode(&body) != nil || body.RemoteException.Exception == "" {
+		body.RemoteException = RemoteException{Message: http.StatusText(res.StatusCode)}
+	}
+	body.RemoteException.Code = res.StatusCode
+	return body.RemoteException
+}
+
+// MissingRedirect - namenode did not redirect CREATE to a datanode
+type MissingRedirect struct {
+	Path string
+}
+
+func (e MissingRedirect) Error() string {
+	return "WebHDFS did not return a datanode location for ‘" + e.Path + "’"
+}
@@ -0,0 +1,415 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hdfs
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Config - HDFS access through WebHDFS REST API, URL is of the form hdfs://user@namenode[:port]/path.
+// webhdfs:// is same as hdfs://, swebhdfs:// uses HTTPS. Only simple authentication is supported,
+// user name is passed as user.name
+type Config struct {
+	// User is used when URL has none, namenode decides identity if both are empty
+	User        string
+	HostURL     string
+	AppName     string
+	AppVersion  string
+	AppComments []string
+	Debug       bool
+}
+
+type hdfsClient struct {
+	hostURL    *client.URL
+	scheme     string
+	host       string
+	user       string
+	userAgent  string
+	httpClient *http.Client
+}
+
+// fileStatus - WebHDFS FileStatus JSON object
+type fileStatus struct {
+	PathSuffix       string `json:"pathSuffix"`
+	Type             string `json:"type"`
+	Length           int64  `json:"length"`
+	ModificationTime int64  `json:"modificationTime"`
+	Owner            string `json:"owner"`
+	Permission       string `json:"permission"`
+}
+
+// New returns an initialized hdfsClient structure. if debug use a internal trace transport
+func New(config *Config) (client.Client, error) {
+	u, err := client.Parse(config.HostURL)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	var scheme, port string
+	switch u.Scheme {
+	case "hdfs", "webhdfs":
+		scheme, port = "http", "50070"
+	case "swebhdfs":
+		scheme, port = "https", "50470"
+	default:
+		return nil, iodine.New(client.InvalidQueryURL{URL: config.HostURL}, nil)
+	}
+	host := u.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, port)
+	}
+	var transport http.RoundTripper
+	switch {
+	case config.Debug == true:
+		transport = s3.GetNewTraceTransport(s3.NewTrace(), http.DefaultTransport)
+	default:
+		transport = s3.GetNewLogTransport(http.DefaultTransport)
+	}
+	user := u.User
+	if user == "" {
+		user = config.User
+	}
+	userAgent := config.AppName + "/" + config.AppVersion
+	if len(config.AppComments) > 0 {
+		userAgent = userAgent + " (" + strings.Join(config.AppComments, "; ") + ")"
+	}
+	return &hdfsClient{
+		hostURL:    u,
+		scheme:     scheme,
+		host:       host,
+		user:       user,
+		userAgent:  userAgent,
+		httpClient: &http.Client{Transport: transport},
+	}, nil
+}
+
+// URL get url
+func (c *hdfsClient) URL() *client.URL {
+	return c.hostURL
+}
+
+// remotePath - path of URL, root of the filesystem if URL has none
+func (c *hdfsClient) remotePath() string {
+	return path.Clean("/" + c.hostURL.Path)
+}
+
+// opURL - namenode URL of a WebHDFS operation on p
+func (c *hdfsClient) opURL(op, p string, params url.Values) string {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("op", op)
+	if c.user != "" {
+		params.Set("user.name", c.user)
+	}
+	u := &url.URL{Scheme: c.scheme, Host: c.host, Path: "/webhdfs/v1" + p, RawQuery: params.Encode()}
+	return u.String()
+}
+
+// do executes a request, non 2xx responses are turned into RemoteException, 404 into client.NotFound
+func (c *hdfsClient) do(req *http.Request, p string) (*http.Response, error) {
+	req.Header.Set("User-Agent", c.userAgent)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+		if res.StatusCode == http.StatusNotFound {
+			return nil, iodine.New(client.NotFound{Path: p}, nil)
+		}
+		return nil, iodine.New(toRemoteException(res), nil)
+	}
+	return res, nil
+}
+
+// doJSON executes an operation without body and decodes JSON response into v, v may be nil
+func (c *hdfsClient) doJSON(method, op, p string, params url.Values, v interface{}) error {
+	req, err := http.NewRequest(method, c.opURL(op, p, params), nil)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	res, err := c.do(req, p)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	if v == nil {
+		io.Copy(ioutil.Discard, res.Body)
+		return nil
+	}
+	return iodine.New(json.NewDecoder(res.Body).Decode(v), nil)
+}
+
+// toContent - symlinks are reported with ModeSymlink so that callers skip them
+func toContent(name string, status fileStatus) *client.Content {
+	content := &client.Content{
+		Name:  name,
+		Time:  time.Unix(0, status.ModificationTime*int64(time.Millisecond)).UTC(),
+		Size:  status.Length,
+		Owner: status.Owner,
+	}
+	perm, err := strconv.ParseUint(status.Permission, 8, 32)
+	if err != nil {
+		perm = 0644
+	}
+	content.Type = os.FileMode(perm) & os.ModePerm
+	switch status.Type {
+	case "DIRECTORY":
+		content.Type |= os.ModeDir
+	case "SYMLINK":
+		content.Type |= os.ModeSymlink
+	}
+	return content
+}
+
+func (c *hdfsClient) stat(p string) (*client.Content, error) {
+	var body struct {
+		FileStatus fileStatus `json:"FileStatus"`
+	}
+	if err := c.doJSON("GET", "GETFILESTATUS", p, nil, &body); err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return toContent(p, body.FileStatus), nil
+}
+
+// Stat - get metadata from path
+func (c *hdfsClient) Stat() (*client.Content, error) {
+	content, err := c.stat(c.remotePath())
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return content, nil
+}
+
+// GetObject - read a full or part of remote file, namenode redirects OPEN to a datanode
+func (c *hdfsClient) GetObject(offset, length int64) (io.ReadCloser, int64, error) {
+	p := c.remotePath()
+	content, err := c.stat(p)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	if content.Type.IsDir() {
+		return nil, length, iodine.New(client.ISFolder{Path: p}, nil)
+	}
+	if offset < 0 || offset > content.Size || length < 0 || offset+length > content.Size {
+		return nil, length, iodine.New(client.InvalidRange{Offset: offset}, nil)
+	}
+	if length == 0 {
+		length = content.Size - offset
+	}
+	params := url.Values{}
+	params.Set("offset", strconv.FormatInt(offset, 10))
+	params.Set("length", strconv.FormatInt(length, 10))
+	req, err := http.NewRequest("GET", c.opURL("OPEN", p, params), nil)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	res, err := c.do(req, p)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	return res.Body, length, nil
+}
+
+// PutObject - create or overwrite a remote file, missing parent folders are created by namenode.
+// CREATE is sent to namenode without data first, data goes to the datanode it redirects to
+func (c *hdfsClient) PutObject(size int64, data io.Reader) error {
+	p := c.remotePath()
+	params := url.Values{}
+	params.Set("overwrite", "true")
+	req, err := http.NewRequest("PUT", c.opURL("CREATE", p, params), nil)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	res, err := c.httpClient.Transport.RoundTrip(req)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusTemporaryRedirect {
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			return iodine.New(RemoteException{Code: res.StatusCode, Message: http.StatusText(res.StatusCode)}, nil)
+		}
+		return iodine.New(MissingRedirect{Path: p}, nil)
+	}
+	location := res.Header.Get("Location")
+	if location == "" {
+		return iodine.New(MissingRedirect{Path: p}, nil)
+	}
+	req, err = http.NewRequest("PUT", location, data)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	// size could be 0 for virtual files, send chunked till EOF for such files
+	if size > 0 {
+		req.ContentLength = size
+		req.Body = ioutil.NopCloser(io.LimitReader(data, size))
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	res, err = c.do(req, p)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	return iodine.New(res.Body.Close(), nil)
+}
+
+// List - list files and folders
+func (c *hdfsClient) List(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel)
+	switch recursive {
+	case true:
+		go c.listRecursiveInRoutine(contentCh)
+	default:
+		go c.listInRoutine(contentCh)
+	}
+	return contentCh
+}
+
+func (c *hdfsClient) listStatus(p string) ([]fileStatus, error) {
+	var body struct {
+		FileStatuses struct {
+			FileStatus []fileStatus `json:"FileStatus"`
+		} `json:"FileStatuses"`
+	}
+	if err := c.doJSON("GET", "LISTSTATUS", p, nil, &body); err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return body.FileStatuses.FileStatus, nil
+}
+
+func (c *hdfsClient) listInRoutine(contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	p := c.remotePath()
+	content, err := c.stat(p)
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	if !content.Type.IsDir() {
+		contentCh <- client.ContentOnChannel{Content: content}
+		return
+	}
+	statuses, err := c.listStatus(p)
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	for _, status := range statuses {
+		contentCh <- client.ContentOnChannel{Content: toContent(status.PathSuffix, status)}
+	}
+}
+
+// listRecursiveInRoutine - names are relative to the last folder of the listed path, same as fs client
+func (c *hdfsClient) listRecursiveInRoutine(contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	root := c.remotePath()
+	stripPrefix := root[:strings.LastIndex(root, "/")+1]
+	if strings.HasSuffix(c.hostURL.Path, "/") && root != "/" {
+		stripPrefix = root + "/"
+	}
+	dirs := []string{root}
+	for len(dirs) > 0 {
+		dir := dirs[len(dirs)-1]
+		dirs = dirs[:len(dirs)-1]
+		statuses, err := c.listStatus(dir)
+		if err != nil {
+			contentCh <- client.ContentOnChannel{Err: iodine.New(err, map[string]string{"Target": dir})}
+			continue
+		}
+		for _, status := range statuses {
+			p := path.Join(dir, status.PathSuffix)
+			if status.Type == "DIRECTORY" {
+				dirs = append(dirs, p)
+			}
+			contentCh <- client.ContentOnChannel{Content: toContent(strings.TrimPrefix(p, stripPrefix), status)}
+		}
+	}
+}
+
+// isValidBucketACL - is acl a valid ACL?
+func isValidBucketACL(acl string) bool {
+	switch acl {
+	case "private", "public-read", "public-read-write", "authenticated-read", "":
+		return true
+	default:
+		return false
+	}
+}
+
+// aclToPerm - convert acl to folder mode, same mapping as fs client
+func aclToPerm(acl string) os.FileMode {
+	switch acl {
+	case "public-read":
+		return os.FileMode(0500)
+	case "public-read-write":
+		return os.FileMode(0777)
+	case "authenticated-read":
+		return os.FileMode(0770)
+	default:
+		return os.FileMode(0700)
+	}
+}
+
+// mkdirs - create p with its parents, result is false if p could not be created
+func (c *hdfsClient) mkdirs(p string) error {
+	var body struct {
+		Boolean bool `json:"boolean"`
+	}
+	if err := c.doJSON("PUT", "MKDIRS", p, nil, &body); err != nil {
+		return iodine.New(err, nil)
+	}
+	if !body.Boolean {
+		return iodine.New(client.NotFolder{Path: p}, nil)
+	}
+	return nil
+}
+
+// MakeBucket - create a remote folder
+func (c *hdfsClient) MakeBucket() error {
+	return iodine.New(c.mkdirs(c.remotePath()), nil)
+}
+
+// SetBucketACL - create a remote folder with permissions of the acl
+func (c *hdfsClient) SetBucketACL(acl string) error {
+	if !isValidBucketACL(acl) {
+		return iodine.New(client.InvalidACLType{ACL: acl}, nil)
+	}
+	p := c.remotePath()
+	if err := c.mkdirs(p); err != nil {
+		return iodine.New(err, nil)
+	}
+	params := url.Values{}
+	params.Set("permission", strconv.FormatUint(uint64(aclToPerm(acl)), 8))
+	return iodine.New(c.doJSON("PUT", "SETPERMISSION", p, params, nil), nil)
+}
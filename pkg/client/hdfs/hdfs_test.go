@@ -0,0 +1,266 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hdfs
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+// namenode - minimal WebHDFS server on a local folder, redirects data operations to /datanode
+type namenode struct {
+	root string
+}
+
+func (n namenode) status(fi os.FileInfo, suffix string) fileStatus {
+	status := fileStatus{
+		PathSuffix:       suffix,
+		Type:             "FILE",
+		Length:           fi.Size(),
+		ModificationTime: fi.ModTime().UnixNano() / int64(time.Millisecond),
+		Owner:            "hadoop",
+		Permission:       strconv.FormatUint(uint64(fi.Mode().Perm()), 8),
+	}
+	if fi.IsDir() {
+		status.Type, status.Length = "DIRECTORY", 0
+	}
+	return status
+}
+
+func (n namenode) remoteException(w http.ResponseWriter, code int, exception string) {
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]RemoteException{"RemoteException": {Exception: exception, Message: exception}})
+}
+
+func (n namenode) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Query().Get("user.name") != "hadoop" {
+		n.remoteException(w, http.StatusUnauthorized, "SecurityException")
+		return
+	}
+	if strings.HasPrefix(req.URL.Path, "/datanode/") {
+		name := filepath.Join(n.root, filepath.FromSlash(strings.TrimPrefix(req.URL.Path, "/datanode")))
+		switch req.Method {
+		case "PUT":
+			os.MkdirAll(filepath.Dir(name), 0755)
+			data, _ := ioutil.ReadAll(req.Body)
+			ioutil.WriteFile(name, data, 0644)
+			w.WriteHeader(http.StatusCreated)
+		case "GET":
+			data, _ := ioutil.ReadFile(name)
+			offset, _ := strconv.Atoi(req.URL.Query().Get("offset"))
+			length, _ := strconv.Atoi(req.URL.Query().Get("length"))
+			w.Write(data[offset : offset+length])
+		}
+		return
+	}
+	p := strings.TrimPrefix(req.URL.Path, "/webhdfs/v1")
+	name := filepath.Join(n.root, filepath.FromSlash(p))
+	datanode := "http://" + req.Host + "/datanode" + p + "?" + req.URL.RawQuery
+	fi, err := os.Stat(name)
+	switch req.URL.Query().Get("op") {
+	case "GETFILESTATUS":
+		if err != nil {
+			n.remoteException(w, http.StatusNotFound, "FileNotFoundException")
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]fileStatus{"FileStatus": n.status(fi, "")})
+	case "LISTSTATUS":
+		if err != nil {
+			n.remoteException(w, http.StatusNotFound, "FileNotFoundException")
+			return
+		}
+		statuses := []fileStatus{n.status(fi, "")}
+		if fi.IsDir() {
+			statuses = nil
+			infos, _ := ioutil.ReadDir(name)
+			for _, info := range infos {
+				statuses = append(statuses, n.status(info, info.Name()))
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"FileStatuses": map[string][]fileStatus{"FileStatus": statuses}})
+	case "OPEN":
+		http.Redirect(w, req, datanode, http.StatusTemporaryRedirect)
+	case "CREATE":
+		if req.ContentLength > 0 {
+			n.remoteException(w, http.StatusBadRequest, "IllegalArgumentException")
+			return
+		}
+		http.Redirect(w, req, datanode, http.StatusTemporaryRedirect)
+	case "MKDIRS":
+		result := os.MkdirAll(name, 0755) == nil
+		json.NewEncoder(w).Encode(map[string]bool{"boolean": result})
+	case "SETPERMISSION":
+		perm, _ := strconv.ParseUint(req.URL.Query().Get("permission"), 8, 32)
+		os.Chmod(name, os.FileMode(perm))
+	default:
+		n.remoteException(w, http.StatusBadRequest, "IllegalArgumentException")
+	}
+}
+
+func newServer(c *C) (*httptest.Server, string) {
+	root, err := ioutil.TempDir(os.TempDir(), "hdfs-")
+	c.Assert(err, IsNil)
+	return httptest.NewServer(namenode{root: root}), root
+}
+
+func newClient(c *C, server *httptest.Server, p string) client.Client {
+	config := new(Config)
+	config.HostURL = strings.Replace(server.URL, "http://", "hdfs://hadoop@", 1) + p
+	clnt, err := New(config)
+	c.Assert(err, IsNil)
+	return clnt
+}
+
+func (s *MySuite) TestNew(c *C) {
+	_, err := New(&Config{HostURL: "http://namenode/path"})
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidQueryURL{})
+
+	for scheme, host := range map[string]string{"hdfs": "namenode:50070", "webhdfs": "namenode:50070", "swebhdfs": "namenode:50470"} {
+		clnt, err := New(&Config{HostURL: scheme + "://namenode/data", User: "hadoop"})
+		c.Assert(err, IsNil)
+		c.Assert(clnt.(*hdfsClient).host, Equals, host)
+		c.Assert(clnt.(*hdfsClient).user, Equals, "hadoop")
+	}
+	clnt, err := New(&Config{HostURL: "hdfs://alice@namenode:8020/data", User: "hadoop"})
+	c.Assert(err, IsNil)
+	c.Assert(clnt.(*hdfsClient).host, Equals, "namenode:8020")
+	c.Assert(clnt.(*hdfsClient).user, Equals, "alice")
+}
+
+func (s *MySuite) TestObjectOperations(c *C) {
+	server, root := newServer(c)
+	defer os.RemoveAll(root)
+	defer server.Close()
+
+	data := []byte("Hello, World")
+	clnt := newClient(c, server, "/user/hadoop/my data/object")
+	c.Assert(clnt.PutObject(int64(len(data)), bytes.NewReader(data)), IsNil)
+	result, err := ioutil.ReadFile(filepath.Join(root, "user", "hadoop", "my data", "object"))
+	c.Assert(err, IsNil)
+	c.Assert(result, DeepEquals, data)
+
+	content, err := clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Name, Equals, "/user/hadoop/my data/object")
+	c.Assert(content.Size, Equals, int64(len(data)))
+	c.Assert(content.Type.IsRegular(), Equals, true)
+	c.Assert(content.Owner, Equals, "hadoop")
+
+	reader, size, err := clnt.GetObject(0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(len(data)))
+	result, err = ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(reader.Close(), IsNil)
+	c.Assert(result, DeepEquals, data)
+
+	reader, _, err = clnt.GetObject(7, 5)
+	c.Assert(err, IsNil)
+	result, err = ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(reader.Close(), IsNil)
+	c.Assert(string(result), Equals, "World")
+
+	_, err = newClient(c, server, "/user/missing").Stat()
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.NotFound{})
+
+	_, _, err = newClient(c, server, "/user/hadoop").GetObject(0, 0)
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.ISFolder{})
+
+	clnt, err = New(&Config{HostURL: strings.Replace(server.URL, "http://", "hdfs://", 1) + "/user"})
+	c.Assert(err, IsNil)
+	_, err = clnt.Stat()
+	c.Assert(iodine.ToError(err), FitsTypeOf, RemoteException{})
+	c.Assert(iodine.ToError(err).(RemoteException).Exception, Equals, "SecurityException")
+}
+
+func (s *MySuite) TestList(c *C) {
+	server, root := newServer(c)
+	defer os.RemoveAll(root)
+	defer server.Close()
+
+	for _, name := range []string{"/list/a", "/list/dir/b", "/list/dir/c"} {
+		c.Assert(newClient(c, server, name).PutObject(1, bytes.NewReader([]byte("x"))), IsNil)
+	}
+
+	var names []string
+	for content := range newClient(c, server, "/list").List(false) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, content.Content.Name)
+	}
+	sort.Strings(names)
+	c.Assert(names, DeepEquals, []string{"a", "dir"})
+
+	names = nil
+	for content := range newClient(c, server, "/list/").List(true) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, content.Content.Name)
+	}
+	sort.Strings(names)
+	c.Assert(names, DeepEquals, []string{"a", "dir", "dir/b", "dir/c"})
+
+	names = nil
+	for content := range newClient(c, server, "/list/dir").List(true) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.Type.IsRegular(), Equals, true)
+		names = append(names, content.Content.Name)
+	}
+	sort.Strings(names)
+	c.Assert(names, DeepEquals, []string{"dir/b", "dir/c"})
+}
+
+func (s *MySuite) TestBucketOperations(c *C) {
+	server, root := newServer(c)
+	defer os.RemoveAll(root)
+	defer server.Close()
+
+	clnt := newClient(c, server, "/newbucket/nested")
+	c.Assert(clnt.MakeBucket(), IsNil)
+	c.Assert(clnt.SetBucketACL("public-read"), IsNil)
+	fi, err := os.Stat(filepath.Join(root, "newbucket", "nested"))
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+	c.Assert(fi.Mode().Perm(), Equals, os.FileMode(0500))
+
+	err = clnt.SetBucketACL("invalid")
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidACLType{})
+
+	// file in the way
+	c.Assert(newClient(c, server, "/file").PutObject(1, bytes.NewReader([]byte("x"))), IsNil)
+	err = newClient(c, server, "/file").MakeBucket()
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.NotFolder{})
+}
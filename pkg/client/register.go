@@ -0,0 +1,93 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"sort"
+	"sync"
+)
+
+// Config - settings passed to factories of registered backends, keys come from the matching host configuration
+type Config struct {
+	HostURL         string
+	AccessKeyID     string
+	SecretAccessKey string
+	CredentialsFile string
+	AppName         string
+	AppVersion      string
+	AppComments     []string
+	Debug           bool
+}
+
+// Factory - creates a client for URLs of a registered scheme
+type Factory func(config *Config) (Client, error)
+
+var registry = struct {
+	sync.RWMutex
+	factories map[string]Factory
+}{factories: make(map[string]Factory)}
+
+// Register makes a storage backend available for URLs of the form scheme://[user@]host/path.
+// Programs embedding this package call it from an init function, before URLs are parsed.
+// Register panics if scheme is registered twice, is handled by a built in backend or factory is nil.
+func Register(scheme string, factory Factory) {
+	if factory == nil {
+		panic("client: Register factory is nil for scheme " + scheme)
+	}
+	if !validScheme.MatchString(scheme) || isBuiltinScheme(scheme) {
+		panic("client: Register called with invalid or built in scheme " + scheme)
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.factories[scheme]; ok {
+		panic("client: Register called twice for scheme " + scheme)
+	}
+	registry.factories[scheme] = factory
+}
+
+// Lookup returns the factory of a registered scheme
+func Lookup(scheme string) (Factory, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	factory, ok := registry.factories[scheme]
+	return factory, ok
+}
+
+// Schemes returns registered schemes in sorted order
+func Schemes() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	var schemes []string
+	for scheme := range registry.factories {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// New creates a client for a URL of a registered scheme
+func New(config *Config) (Client, error) {
+	u, err := Parse(config.HostURL)
+	if err != nil {
+		return nil, err
+	}
+	factory, ok := Lookup(u.Scheme)
+	if !ok {
+		return nil, InvalidQueryURL{URL: config.HostURL}
+	}
+	return factory(config)
+}
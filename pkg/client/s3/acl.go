@@ -0,0 +1,115 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Groups of grantees in access control lists
+const (
+	allUsersGroup           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	authenticatedUsersGroup = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+// accessControlPolicy - reply of GET Bucket and Object acl
+type accessControlPolicy struct {
+	Owner struct {
+		ID string
+	}
+	AccessControlList struct {
+		Grant []struct {
+			Grantee struct {
+				ID  string
+				URI string
+			}
+			Permission string
+		}
+	}
+}
+
+// cannedACL - canned ACL giving the grants of policy, "custom" if none does. Grants of the owner are left
+// out, every canned ACL gives the owner full control
+func (policy accessControlPolicy) cannedACL() string {
+	var allRead, allWrite, authenticatedRead, others bool
+	for _, grant := range policy.AccessControlList.Grant {
+		switch {
+		case grant.Grantee.ID != "" && grant.Grantee.ID == policy.Owner.ID && grant.Permission == "FULL_CONTROL":
+		case grant.Grantee.URI == allUsersGroup && grant.Permission == "READ":
+			allRead = true
+		case grant.Grantee.URI == allUsersGroup && grant.Permission == "WRITE":
+			allWrite = true
+		case grant.Grantee.URI == authenticatedUsersGroup && grant.Permission == "READ":
+			authenticatedRead = true
+		default:
+			others = true
+		}
+	}
+	switch {
+	case others:
+	case allRead && allWrite && !authenticatedRead:
+		return "public-read-write"
+	case allRead && !allWrite && !authenticatedRead:
+		return "public-read"
+	case authenticatedRead && !allRead && !allWrite:
+		return "authenticated-read"
+	case !allRead && !allWrite && !authenticatedRead:
+		return "private"
+	}
+	return "custom"
+}
+
+// GetBucketACL - canned ACL of the bucket
+func (c *s3Client) GetBucketACL() (string, error) {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object != "" {
+		return "", iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	req, err := c.newRequest("GET", "/"+bucket, "acl=", nil)
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	policy := new(accessControlPolicy)
+	if err := c.do(req, emptySHA256, "GetBucketAcl", policy); err != nil {
+		return "", iodine.New(err, nil)
+	}
+	return policy.cannedACL(), nil
+}
+
+// GetObjectACL - canned ACL of the object
+func (c *s3Client) GetObjectACL() (string, error) {
+	req, err := c.newObjectRequest("GET", "acl=", nil)
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	policy := new(accessControlPolicy)
+	if err := c.do(req, emptySHA256, "GetObjectAcl", policy); err != nil {
+		return "", iodine.New(err, nil)
+	}
+	return policy.cannedACL(), nil
+}
+
+// SetObjectACL - replace the access control list of the object with a canned ACL like "public-read"
+func (c *s3Client) SetObjectACL(acl string) error {
+	req, err := c.newObjectRequest("PUT", "acl=", nil)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	req.Header.Set("X-Amz-Acl", acl)
+	return c.do(req, emptySHA256, "PutObjectAcl", nil)
+}
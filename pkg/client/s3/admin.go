@@ -0,0 +1,139 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-go"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// adminPathPrefix - path of the admin API of Minio servers
+const adminPathPrefix = "/minio/admin/v3/"
+
+// infoMessage - reply of the admin API server info, only the fields reported by GetServerInfo
+type infoMessage struct {
+	Mode         string `json:"mode"`
+	DeploymentID string `json:"deploymentID"`
+	Buckets      struct {
+		Count int64 `json:"count"`
+	} `json:"buckets"`
+	Objects struct {
+		Count int64 `json:"count"`
+	} `json:"objects"`
+	Usage struct {
+		Size int64 `json:"size"`
+	} `json:"usage"`
+	Servers []serverProperties `json:"servers"`
+}
+
+type serverProperties struct {
+	State    string `json:"state"`
+	Endpoint string `json:"endpoint"`
+	Uptime   int64  `json:"uptime"` // seconds
+	Version  string `json:"version"`
+	Drives   []disk `json:"drives"`
+	Disks    []disk `json:"disks"` // servers before drives were named so
+}
+
+type disk struct {
+	Endpoint   string `json:"endpoint"`
+	Path       string `json:"path"`
+	State      string `json:"state"`
+	Healing    bool   `json:"healing"`
+	TotalSpace uint64 `json:"totalspace"`
+	UsedSpace  uint64 `json:"usedspace"`
+}
+
+// GetServerInfo - status of the Minio deployment of this client. Servers without the admin API reply
+// client.APINotImplemented
+func (c *s3Client) GetServerInfo() (*client.ServerInfo, error) {
+	req, err := c.newRequest("GET", adminPathPrefix+"info", "", nil)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	c.sign(req, emptySHA256)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return nil, adminError(res)
+	}
+	var info infoMessage
+	if err := json.NewDecoder(res.Body).Decode(&info); err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	serverInfo := &client.ServerInfo{
+		Mode:         info.Mode,
+		DeploymentID: info.DeploymentID,
+		Buckets:      info.Buckets.Count,
+		Objects:      info.Objects.Count,
+		Usage:        info.Usage.Size,
+	}
+	for _, server := range info.Servers {
+		node := client.NodeInfo{
+			Endpoint: server.Endpoint,
+			State:    server.State,
+			Version:  server.Version,
+			Uptime:   server.Uptime,
+		}
+		for _, d := range append(server.Drives, server.Disks...) {
+			path := d.Path
+			if path == "" {
+				path = d.Endpoint
+			}
+			node.Disks = append(node.Disks, client.DiskInfo{
+				Path:    path,
+				State:   d.State,
+				Total:   d.TotalSpace,
+				Used:    d.UsedSpace,
+				Healing: d.Healing,
+			})
+		}
+		serverInfo.Nodes = append(serverInfo.Nodes, node)
+	}
+	return serverInfo, nil
+}
+
+// adminError - error of a failed admin API response, replied as JSON instead of XML. Servers which do not
+// have the API at all reply with S3 errors, or not at all
+func adminError(res *http.Response) error {
+	data, _ := ioutil.ReadAll(res.Body)
+	errorResponse := minio.ErrorResponse{}
+	if json.Unmarshal(data, &errorResponse) != nil || errorResponse.Code == "" {
+		if res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusMethodNotAllowed ||
+			res.StatusCode == http.StatusNotImplemented {
+			return iodine.New(client.APINotImplemented{API: "ServerInfo"}, nil)
+		}
+		// S3 errors like AccessDenied of proxies and gateways in front of the server
+		errorResponse = minio.ErrorResponse{}
+		if xml.Unmarshal(data, &errorResponse) != nil || errorResponse.Code == "" {
+			errorResponse.Code = res.Status
+		}
+	}
+	if errorResponse.Code == "NotImplemented" || errorResponse.Code == "XMinioAdminNotImplemented" {
+		return iodine.New(client.APINotImplemented{API: "ServerInfo"}, nil)
+	}
+	return iodine.New(errorResponse, nil)
+}
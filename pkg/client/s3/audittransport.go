@@ -0,0 +1,122 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/console"
+)
+
+// presignQueryKeys - query parameters of presigned URLs granting access, redacted in audit records and traces
+var presignQueryKeys = []string{"X-Amz-Credential", "X-Amz-Signature", "X-Amz-Security-Token", "AWSAccessKeyId", "Signature"}
+
+// RoundTripAudit records method, URL, status, duration and size of each HTTP call in the audit file
+type RoundTripAudit struct {
+	Transport http.RoundTripper // HTTP transport whose requests are recorded
+}
+
+// RoundTrip sends req, the record is written once the response body is read or closed
+func (t RoundTripAudit) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	record := console.AuditRecord{
+		Method: req.Method,
+		URL:    redactURL(req.URL).String(),
+	}
+	if req.ContentLength > 0 {
+		record.BytesSent = req.ContentLength
+	}
+	res, err := t.Transport.RoundTrip(req)
+	if err != nil {
+		record.Duration = time.Since(start).Seconds()
+		record.Error = err.Error()
+		console.Audit(record)
+		return res, err
+	}
+	record.Status = res.StatusCode
+	res.Body = &auditBody{ReadCloser: res.Body, record: record, start: start}
+	return res, nil
+}
+
+// redactURL - u without credentials of presigned URLs, u itself if it has none
+func redactURL(u *url.URL) *url.URL {
+	query := u.Query()
+	redacted := false
+	for _, key := range presignQueryKeys {
+		if query.Get(key) != "" {
+			query.Set(key, "**REDACTED**")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return u
+	}
+	copied := *u
+	copied.RawQuery = query.Encode()
+	return &copied
+}
+
+// auditBody - response body counting bytes received, writes the audit record at end of body or on close
+type auditBody struct {
+	io.ReadCloser
+	record console.AuditRecord
+	start  time.Time
+	once   sync.Once
+}
+
+func (b *auditBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.record.BytesReceived += int64(n)
+	switch {
+	case err == io.EOF:
+		b.finish(nil)
+	case err != nil:
+		b.finish(err)
+	}
+	return n, err
+}
+
+func (b *auditBody) Close() error {
+	b.finish(nil)
+	return b.ReadCloser.Close()
+}
+
+// finish - write the record once, err of reading the body if any
+func (b *auditBody) finish(err error) {
+	b.once.Do(func() {
+		b.record.Duration = time.Since(b.start).Seconds()
+		if err != nil {
+			b.record.Error = err.Error()
+		}
+		console.Audit(b.record)
+	})
+}
+
+// auditTransport - transport wrapped to record its requests if an audit file is set, transport otherwise
+func auditTransport(transport http.RoundTripper) http.RoundTripper {
+	if !console.IsAuditEnabled() {
+		return transport
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return RoundTripAudit{Transport: transport}
+}
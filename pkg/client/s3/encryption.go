@@ -0,0 +1,177 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// maxSinglePutSize - largest object S3 accepts in a single PUT, the API library has no encrypted multipart upload
+const maxSinglePutSize = 5 * 1024 * 1024 * 1024
+
+// unsignedPayload - payload hash of requests whose body is not signed, the body is too large to hash upfront
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// ObjectTooLarge - object cannot be uploaded with a single PUT
+type ObjectTooLarge struct {
+	Object string
+	Size   int64
+}
+
+func (e ObjectTooLarge) Error() string {
+	return "Object #" + e.Object + " is too large for an encrypted upload."
+}
+
+// InsecureCustomerKey - SSE-C keys are only sent over https
+type InsecureCustomerKey struct {
+	URL string
+}
+
+func (e InsecureCustomerKey) Error() string {
+	return "SSE-C keys are sent over https only, refusing to upload to " + e.URL + "."
+}
+
+// setSSEHeaders - request headers for server side encryption
+func setSSEHeaders(header http.Header, sse client.SSE) error {
+	switch sse.Type {
+	case client.SSES3:
+		header.Set("X-Amz-Server-Side-Encryption", "AES256")
+	case client.SSEKMS:
+		header.Set("X-Amz-Server-Side-Encryption", "aws:kms")
+		if sse.KeyID != "" {
+			header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", sse.KeyID)
+		}
+	case client.SSEC:
+		if len(sse.Key) != 32 {
+			return iodine.New(client.InvalidArgument{}, nil)
+		}
+		md5sum := md5.Sum(sse.Key)
+		header.Set("X-Amz-Server-Side-Encryption-Customer-Algorithm", "AES256")
+		header.Set("X-Amz-Server-Side-Encryption-Customer-Key", base64.StdEncoding.EncodeToString(sse.Key))
+		header.Set("X-Amz-Server-Side-Encryption-Customer-Key-Md5", base64.StdEncoding.EncodeToString(md5sum[:]))
+	default:
+		return iodine.New(client.InvalidArgument{}, nil)
+	}
+	return nil
+}
+
+// PutObjectWithEncryption - put object encrypted by the server, objects are uploaded with a single PUT
+func (c *s3Client) PutObjectWithEncryption(size int64, data io.Reader, metadata map[string]string, sse client.SSE) error {
+	if sse.Type == client.SSEC && c.hostURL.Scheme != "https" {
+		return iodine.New(InsecureCustomerKey{URL: c.hostURL.String()}, nil)
+	}
+	header := c.objectHeader(metadata)
+	if err := setSSEHeaders(header, sse); err != nil {
+		return iodine.New(err, nil)
+	}
+	if size == 0 {
+		spool, spoolSize, err := spoolObject(data)
+		if err != nil {
+			return iodine.New(err, nil)
+		}
+		defer os.Remove(spool.Name())
+		defer spool.Close()
+		size, data = spoolSize, spool
+	}
+	if size > maxSinglePutSize {
+		_, object := c.url2BucketAndObject()
+		return iodine.New(ObjectTooLarge{Object: object, Size: size}, nil)
+	}
+	return c.putSingle(header, size, data, "PutObjectWithEncryption")
+}
+
+// serverSideEncryptionConfiguration - body of GET and PUT Bucket encryption
+type serverSideEncryptionConfiguration struct {
+	XMLName xml.Name                   `xml:"ServerSideEncryptionConfiguration"`
+	Rules   []serverSideEncryptionRule `xml:"Rule"`
+}
+
+// serverSideEncryptionRule - encryption applied to objects uploaded without encryption headers
+type serverSideEncryptionRule struct {
+	ApplyServerSideEncryptionByDefault struct {
+		SSEAlgorithm   string
+		KMSMasterKeyID string `xml:",omitempty"`
+	}
+}
+
+// GetBucketEncryption - default encryption of the bucket, nil if it has none
+func (c *s3Client) GetBucketEncryption() (*client.SSE, error) {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object != "" {
+		return nil, iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	query := url.Values{}
+	query.Set("encryption", "")
+	configuration := new(serverSideEncryptionConfiguration)
+	err := c.doXMLRequest("GET", "/"+bucket, query, "GetBucketEncryption", configuration)
+	if ErrorCode(err) == "ServerSideEncryptionConfigurationNotFoundError" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	for _, rule := range configuration.Rules {
+		switch rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm {
+		case "AES256":
+			return &client.SSE{Type: client.SSES3}, nil
+		case "aws:kms":
+			return &client.SSE{Type: client.SSEKMS, KeyID: rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID}, nil
+		}
+	}
+	return nil, nil
+}
+
+// SetBucketEncryption - set the default encryption of the bucket, or remove it if sse is nil
+func (c *s3Client) SetBucketEncryption(sse *client.SSE) error {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object != "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	if sse == nil {
+		query := url.Values{}
+		query.Set("encryption", "")
+		return c.doXMLRequest("DELETE", "/"+bucket, query, "DeleteBucketEncryption", nil)
+	}
+	configuration := serverSideEncryptionConfiguration{Rules: make([]serverSideEncryptionRule, 1)}
+	byDefault := &configuration.Rules[0].ApplyServerSideEncryptionByDefault
+	switch sse.Type {
+	case client.SSES3:
+		byDefault.SSEAlgorithm = "AES256"
+	case client.SSEKMS:
+		byDefault.SSEAlgorithm, byDefault.KMSMasterKeyID = "aws:kms", sse.KeyID
+	default: // customer keys are sent with every request, buckets cannot default to them
+		return iodine.New(client.InvalidArgument{}, nil)
+	}
+	body, err := xml.Marshal(configuration)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	req, err := c.newRequest("PUT", "/"+bucket, "encryption=", body)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	return c.doWithBody(req, body, "PutBucketEncryption")
+}
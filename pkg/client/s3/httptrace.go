@@ -45,17 +45,20 @@ func (t RoundTripTrace) RoundTrip(req *http.Request) (res *http.Response, err er
 		return nil, iodine.New(client.InvalidArgument{}, nil)
 	}
 
-	res, err = t.Transport.RoundTrip(req)
-	if err != nil {
-		return res, iodine.New(err, nil)
-	}
-
+	// trace the request before sending, its body is gone afterwards
 	if t.Trace != nil {
 		err = t.Trace.Request(req)
 		if err != nil {
 			return nil, iodine.New(err, nil)
 		}
+	}
 
+	res, err = t.Transport.RoundTrip(req)
+	if err != nil {
+		return res, iodine.New(err, nil)
+	}
+
+	if t.Trace != nil {
 		err = t.Trace.Response(res)
 		if err != nil {
 			return nil, iodine.New(err, nil)
@@ -67,8 +70,8 @@ func (t RoundTripTrace) RoundTrip(req *http.Request) (res *http.Response, err er
 	return res, iodine.New(err, nil)
 }
 
-// GetNewTraceTransport returns a traceable transport
+// GetNewTraceTransport returns a traceable transport, recording its requests in the audit file if one is set
 func GetNewTraceTransport(trace HTTPTracer, transport http.RoundTripper) RoundTripTrace {
 	return RoundTripTrace{Trace: trace,
-		Transport: transport}
+		Transport: auditTransport(transport)}
 }
@@ -0,0 +1,162 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"encoding/xml"
+	"net/url"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// lifecycleConfiguration - body of GET and PUT Bucket lifecycle
+type lifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration"`
+	Rules   []lifecycleRule `xml:"Rule"`
+}
+
+type lifecycleFilter struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type lifecycleExpiration struct {
+	Days int `xml:"Days"`
+}
+
+type lifecycleTransition struct {
+	Days         int    `xml:"Days"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type noncurrentVersionExpiration struct {
+	NoncurrentDays int `xml:"NoncurrentDays"`
+}
+
+// lifecycleRule - a Rule of a lifecycle configuration, rules written before filters were introduced have a
+// Prefix of their own
+type lifecycleRule struct {
+	ID                          string                       `xml:"ID,omitempty"`
+	Prefix                      *string                      `xml:"Prefix,omitempty"`
+	Filter                      *lifecycleFilter             `xml:"Filter,omitempty"`
+	Status                      string                       `xml:"Status"`
+	Expiration                  *lifecycleExpiration         `xml:"Expiration,omitempty"`
+	Transition                  *lifecycleTransition         `xml:"Transition,omitempty"`
+	NoncurrentVersionExpiration *noncurrentVersionExpiration `xml:"NoncurrentVersionExpiration,omitempty"`
+}
+
+// MarshalLifecycle - lifecycle configuration document of rules as sent to S3
+func MarshalLifecycle(rules []client.LifecycleRule) ([]byte, error) {
+	configuration := lifecycleConfiguration{}
+	for _, rule := range rules {
+		r := lifecycleRule{ID: rule.ID, Filter: &lifecycleFilter{Prefix: rule.Prefix}, Status: "Disabled"}
+		if rule.Enabled {
+			r.Status = "Enabled"
+		}
+		if rule.ExpirationDays > 0 {
+			r.Expiration = &lifecycleExpiration{Days: rule.ExpirationDays}
+		}
+		if rule.TransitionStorageClass != "" {
+			r.Transition = &lifecycleTransition{Days: rule.TransitionDays, StorageClass: rule.TransitionStorageClass}
+		}
+		if rule.NoncurrentExpirationDays > 0 {
+			r.NoncurrentVersionExpiration = &noncurrentVersionExpiration{NoncurrentDays: rule.NoncurrentExpirationDays}
+		}
+		configuration.Rules = append(configuration.Rules, r)
+	}
+	data, err := xml.Marshal(configuration)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return data, nil
+}
+
+// UnmarshalLifecycle - rules of a lifecycle configuration document
+func UnmarshalLifecycle(data []byte) ([]client.LifecycleRule, error) {
+	configuration := new(lifecycleConfiguration)
+	if err := xml.Unmarshal(data, configuration); err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return configuration.toRules(), nil
+}
+
+// toRules - rules of the configuration
+func (l *lifecycleConfiguration) toRules() []client.LifecycleRule {
+	var rules []client.LifecycleRule
+	for _, r := range l.Rules {
+		rule := client.LifecycleRule{ID: r.ID, Enabled: r.Status == "Enabled"}
+		switch {
+		case r.Filter != nil:
+			rule.Prefix = r.Filter.Prefix
+		case r.Prefix != nil:
+			rule.Prefix = *r.Prefix
+		}
+		if r.Expiration != nil {
+			rule.ExpirationDays = r.Expiration.Days
+		}
+		if r.Transition != nil {
+			rule.TransitionDays = r.Transition.Days
+			rule.TransitionStorageClass = r.Transition.StorageClass
+		}
+		if r.NoncurrentVersionExpiration != nil {
+			rule.NoncurrentExpirationDays = r.NoncurrentVersionExpiration.NoncurrentDays
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// GetLifecycle - rules of the lifecycle configuration of the bucket, none if it has no configuration
+func (c *s3Client) GetLifecycle() ([]client.LifecycleRule, error) {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object != "" {
+		return nil, iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	query := url.Values{}
+	query.Set("lifecycle", "")
+	configuration := new(lifecycleConfiguration)
+	err := c.doXMLRequest("GET", "/"+bucket, query, "GetBucketLifecycle", configuration)
+	if ErrorCode(err) == "NoSuchLifecycleConfiguration" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return configuration.toRules(), nil
+}
+
+// SetLifecycle - replace the lifecycle configuration of the bucket with rules, or remove it if there are none
+func (c *s3Client) SetLifecycle(rules []client.LifecycleRule) error {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object != "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	if len(rules) == 0 {
+		query := url.Values{}
+		query.Set("lifecycle", "")
+		return c.doXMLRequest("DELETE", "/"+bucket, query, "DeleteBucketLifecycle", nil)
+	}
+	body, err := MarshalLifecycle(rules)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	req, err := c.newRequest("PUT", "/"+bucket, "lifecycle=", body)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	return c.doWithBody(req, body, "PutBucketLifecycle")
+}
@@ -0,0 +1,120 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"encoding/xml"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-go"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// maxListKeys - most keys a page of List Objects holds
+const maxListKeys = 1000
+
+// listBucketResult - a page of List Objects
+type listBucketResult struct {
+	XMLName        xml.Name           `xml:"ListBucketResult"`
+	Contents       []minio.ObjectStat `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string
+	}
+	IsTruncated bool
+	NextMarker  string
+}
+
+// ListWithOptions - listing filtered by List Objects with prefix, delimiter and max-keys. Only URLs of folders,
+// whose names List gives relative to them, are filtered on the server, others are listed in full
+func (c *s3Client) ListWithOptions(options client.ListOptions) <-chan client.ContentOnChannel {
+	bucket, object := c.url2BucketAndObject()
+	separator := string(c.hostURL.Separator)
+	folder := strings.HasSuffix(object, separator) ||
+		(bucket != "" && object == "" && (options.Delimiter == separator || strings.HasSuffix(c.hostURL.Path, separator)))
+	if !folder {
+		return client.ListWithOptions(struct{ client.Client }{c}, options)
+	}
+	contentCh := make(chan client.ContentOnChannel)
+	go func() {
+		defer close(contentCh)
+		if err := c.listObjects(bucket, object, options, contentCh); err != nil {
+			contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		}
+	}()
+	return contentCh
+}
+
+// listObjects - send entries of List Objects below folder, names relative to it, common prefixes as folders
+func (c *s3Client) listObjects(bucket, folder string, options client.ListOptions, contentCh chan<- client.ContentOnChannel) error {
+	query := url.Values{}
+	query.Set("prefix", folder+options.Prefix)
+	if options.Delimiter != "" {
+		query.Set("delimiter", options.Delimiter)
+	}
+	sent := 0
+	for {
+		maxKeys := maxListKeys
+		if options.MaxKeys > 0 && options.MaxKeys-sent < maxKeys {
+			maxKeys = options.MaxKeys - sent
+		}
+		query.Set("max-keys", strconv.Itoa(maxKeys))
+		result := new(listBucketResult)
+		if err := c.doXMLRequest("GET", "/"+bucket, query, "ListObjects", result); err != nil {
+			return iodine.New(err, nil)
+		}
+		// both lists are sorted, merged to keep the order of keys
+		objects, prefixes := result.Contents, result.CommonPrefixes
+		for len(objects) > 0 || len(prefixes) > 0 {
+			content := new(client.Content)
+			if len(prefixes) == 0 || (len(objects) > 0 && objects[0].Key < prefixes[0].Prefix) {
+				content.Name = strings.TrimPrefix(objects[0].Key, folder)
+				content.Size = objects[0].Size
+				content.Time = objects[0].LastModified
+				content.Type = os.FileMode(0664)
+				setObjectMetadata(content, objects[0])
+				objects = objects[1:]
+			} else {
+				content.Name = strings.TrimPrefix(prefixes[0].Prefix, folder)
+				content.Time = time.Now()
+				content.Type = os.ModeDir
+				prefixes = prefixes[1:]
+			}
+			contentCh <- client.ContentOnChannel{Content: content}
+			sent++
+			if options.MaxKeys > 0 && sent == options.MaxKeys {
+				return nil
+			}
+		}
+		if !result.IsTruncated {
+			return nil
+		}
+		// NextMarker is sent only with a delimiter, the last key marks the page otherwise
+		marker := result.NextMarker
+		if marker == "" && len(result.Contents) > 0 {
+			marker = result.Contents[len(result.Contents)-1].Key
+		}
+		if marker == "" {
+			return nil
+		}
+		query.Set("marker", marker)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/minio/mc/pkg/console"
+)
+
+// RoundTripLog prints a diagnostic line per HTTP request, a lighter alternative to RoundTripTrace
+type RoundTripLog struct {
+	Transport http.RoundTripper // HTTP transport whose requests are logged
+}
+
+// RoundTrip logs method, URL, status and duration of each HTTP call
+func (t RoundTripLog) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.Transport.RoundTrip(req)
+	// credentials are in headers only, URLs are safe to print
+	switch {
+	case err != nil:
+		console.Logf(console.LogTransport, console.LogError, "%s %s failed after %s. %s\n", req.Method, req.URL, time.Since(start), err)
+	case res.StatusCode >= 500:
+		console.Logf(console.LogTransport, console.LogWarn, "%s %s %s in %s\n", req.Method, req.URL, res.Status, time.Since(start))
+	default:
+		console.Logf(console.LogTransport, console.LogDebug, "%s %s %s in %s\n", req.Method, req.URL, res.Status, time.Since(start))
+	}
+	return res, err
+}
+
+// GetNewLogTransport returns a transport logging its requests at the configured log level, and recording them
+// in the audit file if one is set
+func GetNewLogTransport(transport http.RoundTripper) RoundTripLog {
+	return RoundTripLog{Transport: auditTransport(transport)}
+}
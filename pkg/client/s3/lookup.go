@@ -0,0 +1,100 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Supported values for Config Lookup
+const (
+	LookupAuto = "auto" // virtual host style for AWS endpoints, path style elsewhere
+	LookupDNS  = "dns"  // bucket in the Host header, bucket.example.com/object
+	LookupPath = "path" // bucket in the path, example.com/bucket/object
+)
+
+// IsValidLookup - lookup is a supported bucket addressing mode, empty means auto
+func IsValidLookup(lookup string) bool {
+	switch lookup {
+	case "", LookupAuto, LookupDNS, LookupPath:
+		return true
+	}
+	return false
+}
+
+// InvalidLookup - unsupported bucket addressing mode
+type InvalidLookup struct {
+	Lookup string
+}
+
+func (e InvalidLookup) Error() string {
+	return "Invalid bucket lookup ‘" + e.Lookup + "’, please choose from [auto, dns, path]."
+}
+
+// dnsBucketName - bucket names which are also valid host name labels, names with dots break TLS wildcard certificates
+var dnsBucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,61}[a-z0-9]$`)
+
+// isVirtualHostStyle - requests on bucket send it in the Host header instead of the path
+func (c *s3Client) isVirtualHostStyle(bucket string) bool {
+	if !dnsBucketName.MatchString(bucket) {
+		return false
+	}
+	switch c.lookup {
+	case LookupDNS:
+		return true
+	case LookupPath:
+		return false
+	}
+	return strings.HasSuffix(strings.Split(c.hostURL.Host, ":")[0], ".amazonaws.com")
+}
+
+// bucketLookup - rewrites path style requests of the API library to virtual host style and signs them again
+type bucketLookup struct {
+	client    *s3Client
+	transport http.RoundTripper
+}
+
+// RoundTrip moves the bucket from the path into the host of the request
+func (b bucketLookup) RoundTrip(req *http.Request) (*http.Response, error) {
+	escapedPath := req.URL.EscapedPath()
+	bucket := strings.SplitN(strings.TrimPrefix(escapedPath, "/"), "/", 2)[0]
+	// the admin API of Minio servers is not a bucket
+	if bucket == "" || strings.HasPrefix(escapedPath, adminPathPrefix) || !b.client.isVirtualHostStyle(bucket) {
+		return b.transport.RoundTrip(req)
+	}
+	rawPath := strings.TrimPrefix(escapedPath, "/"+bucket)
+	if rawPath == "" {
+		rawPath = "/"
+	}
+	path, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return b.transport.RoundTrip(req)
+	}
+	newReq := cloneRequest(req)
+	newReq.URL.Host = bucket + "." + req.URL.Host
+	newReq.URL.Path = path
+	newReq.URL.RawPath = rawPath
+	newReq.Host = newReq.URL.Host
+	if newReq.Header.Get("Authorization") != "" {
+		newReq.Header.Del("Authorization")
+		b.client.sign(newReq, newReq.Header.Get("X-Amz-Content-Sha256"))
+	}
+	return b.transport.RoundTrip(newReq)
+}
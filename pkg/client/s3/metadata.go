@@ -0,0 +1,166 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-go"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// userMetadataPrefix - prefix of user defined metadata headers
+const userMetadataPrefix = "X-Amz-Meta-"
+
+// isStorableMetadata - content headers, user metadata and tags are stored along with an object
+func isStorableMetadata(key string) bool {
+	switch key {
+	case "Content-Type", "Cache-Control", "Content-Disposition", "Content-Encoding", "Content-Language", "Expires",
+		client.TaggingMetadata:
+		return true
+	}
+	return strings.HasPrefix(key, userMetadataPrefix)
+}
+
+// GetObjectMetadata - content headers and user metadata of an object, fetched with HEAD Object
+func (c *s3Client) GetObjectMetadata() (map[string]string, error) {
+	_, metadata, err := c.headObject("")
+	if errorResponse := minio.ToErrorResponse(err); errorResponse != nil && errorResponse.Code == "NoSuchKey" {
+		return nil, iodine.New(client.NotFound{Path: c.hostURL.String()}, nil)
+	}
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return metadata, nil
+}
+
+// headObject - stat and stored metadata of an object with a single HEAD Object, query selects a version like
+// "versionId=ID". Errors are ErrorResponse as of StatObject of the API library, missing objects are NoSuchKey
+func (c *s3Client) headObject(query string) (minio.ObjectStat, map[string]string, error) {
+	req, err := c.newObjectRequest("HEAD", query, nil)
+	if err != nil {
+		return minio.ObjectStat{}, nil, err
+	}
+	c.sign(req, emptySHA256)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return minio.ObjectStat{}, nil, err
+	}
+	defer res.Body.Close()
+	bucket, object := c.url2BucketAndObject()
+	errorResponse := minio.ErrorResponse{
+		Code:      res.Status,
+		Message:   res.Status,
+		Resource:  "/" + bucket + "/" + object,
+		RequestID: res.Header.Get("x-amz-request-id"),
+		HostID:    res.Header.Get("x-amz-id-2"),
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		errorResponse.Code, errorResponse.Message = "NoSuchKey", "The specified key does not exist."
+		return minio.ObjectStat{}, nil, errorResponse
+	case http.StatusForbidden:
+		errorResponse.Code, errorResponse.Message = "AccessDenied", "Access Denied"
+		return minio.ObjectStat{}, nil, errorResponse
+	default:
+		return minio.ObjectStat{}, nil, errorResponse
+	}
+	stat := minio.ObjectStat{
+		Key:          object,
+		ETag:         strings.Trim(res.Header.Get("ETag"), "\""),
+		ContentType:  res.Header.Get("Content-Type"),
+		StorageClass: res.Header.Get("X-Amz-Storage-Class"),
+	}
+	// S3 sends the storage class only for objects not stored as STANDARD
+	if stat.StorageClass == "" {
+		stat.StorageClass = "STANDARD"
+	}
+	stat.Size, err = strconv.ParseInt(res.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		errorResponse.Code, errorResponse.Message = "InternalError", "Content-Length not recognized"
+		return minio.ObjectStat{}, nil, errorResponse
+	}
+	stat.LastModified, err = time.Parse(http.TimeFormat, res.Header.Get("Last-Modified"))
+	if err != nil {
+		errorResponse.Code, errorResponse.Message = "InternalError", "Last-Modified time format not recognized"
+		return minio.ObjectStat{}, nil, errorResponse
+	}
+	metadata := make(map[string]string)
+	for key := range res.Header {
+		if isStorableMetadata(key) {
+			metadata[key] = res.Header.Get(key)
+		}
+	}
+	return stat, metadata, nil
+}
+
+// objectHeader - headers of an upload carrying the content headers, user metadata and storage class of the
+// object, the API library would send Content-Type only
+func (c *s3Client) objectHeader(metadata map[string]string) http.Header {
+	header := make(http.Header)
+	for key, value := range metadata {
+		if isStorableMetadata(key) {
+			header.Set(key, value)
+		}
+	}
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "application/octet-stream")
+	}
+	if c.storageClass != "" {
+		header.Set("X-Amz-Storage-Class", c.storageClass)
+	}
+	return header
+}
+
+// putWithHeader - upload size bytes of data sending header along, with a single PUT up to maxSinglePutSize
+// and a multipart upload beyond
+func (c *s3Client) putWithHeader(bucket, object string, header http.Header, size int64, data io.Reader) error {
+	if size > maxSinglePutSize {
+		return c.putStream(bucket, object, header, size, data)
+	}
+	return c.putSingle(header, size, data, "PutObject")
+}
+
+// putSingle - upload size bytes of data to the object of this client with a single PUT sending header along
+func (c *s3Client) putSingle(header http.Header, size int64, data io.Reader, api string) error {
+	req, err := c.newObjectRequest("PUT", "", nil)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	for key := range header {
+		req.Header.Set(key, header.Get(key))
+	}
+	req.Body = ioutil.NopCloser(io.LimitReader(data, size))
+	req.ContentLength = size
+	c.sign(req, unsignedPayload)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return c.toClientError(res, api)
+	}
+	return nil
+}
@@ -0,0 +1,466 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// incompleteUpload - multipart upload which was started but neither completed nor aborted
+type incompleteUpload struct {
+	Key       string
+	UploadID  string `xml:"UploadId"`
+	Initiated time.Time
+}
+
+// listMultipartUploadsResult - a page of ListMultipartUploads
+type listMultipartUploadsResult struct {
+	XMLName            xml.Name `xml:"ListMultipartUploadsResult"`
+	IsTruncated        bool
+	NextKeyMarker      string
+	NextUploadIDMarker string             `xml:"NextUploadIdMarker"`
+	Uploads            []incompleteUpload `xml:"Upload"`
+	CommonPrefixes     []struct {
+		Prefix string
+	}
+}
+
+// listPartsResult - a page of ListParts
+type listPartsResult struct {
+	XMLName              xml.Name `xml:"ListPartsResult"`
+	IsTruncated          bool
+	NextPartNumberMarker int
+	Parts                []struct {
+		Size int64
+	} `xml:"Part"`
+}
+
+// initiateMultipartUploadResult - reply of InitiateMultipartUpload
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	UploadID string   `xml:"UploadId"`
+}
+
+// completePart - uploaded part, identified by number and the ETag UploadPart replied with
+type completePart struct {
+	PartNumber int
+	ETag       string
+}
+
+// completeMultipartUpload - parts CompleteMultipartUpload joins into the object
+type completeMultipartUpload struct {
+	XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+	Parts   []completePart `xml:"Part"`
+}
+
+// completeMultipartUploadResult - reply of CompleteMultipartUpload, failures may come with status 200
+// as an Error document which does not decode into it
+type completeMultipartUploadResult struct {
+	XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+}
+
+// Part sizes of uploads from memory mapped files, limits of S3 API
+const (
+	minMappedPartSize = 5 * 1024 * 1024
+	maxMappedParts    = 10000
+)
+
+// streamPartSize - part size of uploads of unknown length like standard input, streams up to 160GiB fit in
+// the parts S3 allows
+const streamPartSize = 16 * 1024 * 1024
+
+// canonicalQuery - query string in the sorted and encoded form signature version 4 expects
+func canonicalQuery(query url.Values) string {
+	return strings.Replace(query.Encode(), "+", "%20", -1)
+}
+
+// doXMLRequest - send a signed request without body and decode its XML reply into v
+func (c *s3Client) doXMLRequest(method, p string, query url.Values, api string, v interface{}) error {
+	req, err := c.newRequest(method, p, canonicalQuery(query), nil)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	return c.do(req, emptySHA256, api, v)
+}
+
+// do - sign and send a request, decoding its XML reply into v unless v is nil
+func (c *s3Client) do(req *http.Request, payloadSHA256, api string, v interface{}) error {
+	c.sign(req, payloadSHA256)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return c.toClientError(res, api)
+	}
+	if v == nil {
+		return nil
+	}
+	if err := xml.NewDecoder(res.Body).Decode(v); err != nil {
+		return iodine.New(err, nil)
+	}
+	return nil
+}
+
+// listIncompleteUploads - send incomplete uploads of bucket under prefix, and folders below prefix if delimiter is set
+func (c *s3Client) listIncompleteUploads(bucket, prefix, delimiter string, uploadCh chan<- incompleteUpload, prefixCh chan<- string) error {
+	query := url.Values{}
+	query.Set("uploads", "")
+	query.Set("prefix", prefix)
+	if delimiter != "" {
+		query.Set("delimiter", delimiter)
+	}
+	for {
+		result := new(listMultipartUploadsResult)
+		if err := c.doXMLRequest("GET", "/"+bucket, query, "ListMultipartUploads", result); err != nil {
+			return iodine.New(err, nil)
+		}
+		for _, commonPrefix := range result.CommonPrefixes {
+			prefixCh <- commonPrefix.Prefix
+		}
+		for _, upload := range result.Uploads {
+			uploadCh <- upload
+		}
+		if !result.IsTruncated {
+			return nil
+		}
+		query.Set("key-marker", result.NextKeyMarker)
+		query.Set("upload-id-marker", result.NextUploadIDMarker)
+	}
+}
+
+// uploadedSize - total size of the parts uploaded so far
+func (c *s3Client) uploadedSize(bucket string, upload incompleteUpload) (int64, error) {
+	query := url.Values{}
+	query.Set("uploadId", upload.UploadID)
+	var size int64
+	for {
+		result := new(listPartsResult)
+		if err := c.doXMLRequest("GET", "/"+bucket+"/"+upload.Key, query, "ListParts", result); err != nil {
+			return 0, iodine.New(err, nil)
+		}
+		for _, part := range result.Parts {
+			size += part.Size
+		}
+		if !result.IsTruncated {
+			return size, nil
+		}
+		query.Set("part-number-marker", strconv.Itoa(result.NextPartNumberMarker))
+	}
+}
+
+// abortUpload - abort an incomplete upload, freeing its parts
+func (c *s3Client) abortUpload(bucket string, upload incompleteUpload) error {
+	query := url.Values{}
+	query.Set("uploadId", upload.UploadID)
+	return c.doXMLRequest("DELETE", "/"+bucket+"/"+upload.Key, query, "AbortMultipartUpload", nil)
+}
+
+// mappedPartSize - smallest part size which uploads size bytes in at most maxMappedParts parts
+func mappedPartSize(size int64) int64 {
+	partSize := (size + maxMappedParts - 1) / maxMappedParts
+	if partSize < minMappedPartSize {
+		return minMappedPartSize
+	}
+	return partSize
+}
+
+// putMapped - multipart upload sending slices of a memory mapped file as part bodies, the API library
+// would read every part into a buffer of its own first. Headers of header are sent on initiate
+func (c *s3Client) putMapped(bucket, object string, header http.Header, data client.MappedReader) error {
+	p := "/" + bucket + "/" + object
+	upload, err := c.initiateUpload(bucket, object, header)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	var parts []completePart
+	partSize := mappedPartSize(int64(len(data.Bytes())))
+	for number := 1; len(data.Bytes()) > 0; number++ {
+		part := data.Bytes()
+		if int64(len(part)) > partSize {
+			part = part[:partSize]
+		}
+		etag, err := c.uploadPart(p, upload.UploadID, number, part)
+		if err != nil {
+			c.abortUpload(bucket, upload)
+			return iodine.New(err, nil)
+		}
+		data.Advance(int64(len(part)))
+		parts = append(parts, completePart{PartNumber: number, ETag: etag})
+	}
+	return c.completeUpload(bucket, upload, parts)
+}
+
+// putStream - multipart upload of size bytes of data read part by part into a buffer, for objects too large
+// for a single PUT. Data of unknown length, size 0, is read till EOF and sent with a single PUT if it fits in
+// a part. Headers of header are sent on initiate
+func (c *s3Client) putStream(bucket, object string, header http.Header, size int64, data io.Reader) error {
+	known := size > 0
+	buffer := make([]byte, streamPartSize)
+	if known {
+		buffer = make([]byte, mappedPartSize(size))
+	}
+	// readPart - next part of data, empty at the end
+	readPart := func() ([]byte, error) {
+		part := buffer
+		if known && int64(len(part)) > size {
+			part = part[:size]
+		}
+		n, err := io.ReadFull(data, part)
+		if !known && (err == io.EOF || err == io.ErrUnexpectedEOF) {
+			err = nil
+		}
+		size -= int64(n)
+		return part[:n], err
+	}
+	part, err := readPart()
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if !known && len(part) < len(buffer) {
+		return c.putSingle(header, int64(len(part)), bytes.NewReader(part), "PutObject")
+	}
+	p := "/" + bucket + "/" + object
+	upload, err := c.initiateUpload(bucket, object, header)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	var parts []completePart
+	for number := 1; len(part) > 0; number++ {
+		etag, err := c.uploadPart(p, upload.UploadID, number, part)
+		if err != nil {
+			c.abortUpload(bucket, upload)
+			return iodine.New(err, nil)
+		}
+		parts = append(parts, completePart{PartNumber: number, ETag: etag})
+		if part, err = readPart(); err != nil {
+			c.abortUpload(bucket, upload)
+			return iodine.New(err, nil)
+		}
+	}
+	return c.completeUpload(bucket, upload, parts)
+}
+
+// initiateUpload - start a multipart upload, content headers, metadata and storage class of the object
+// are sent with header
+func (c *s3Client) initiateUpload(bucket, object string, header http.Header) (incompleteUpload, error) {
+	query := url.Values{}
+	query.Set("uploads", "")
+	req, err := c.newRequest("POST", "/"+bucket+"/"+object, canonicalQuery(query), nil)
+	if err != nil {
+		return incompleteUpload{}, iodine.New(err, nil)
+	}
+	for key := range header {
+		req.Header.Set(key, header.Get(key))
+	}
+	initiated := new(initiateMultipartUploadResult)
+	if err := c.do(req, emptySHA256, "InitiateMultipartUpload", initiated); err != nil {
+		return incompleteUpload{}, iodine.New(err, nil)
+	}
+	return incompleteUpload{Key: object, UploadID: initiated.UploadID}, nil
+}
+
+// completeUpload - join parts into the object, the upload is aborted if that fails
+func (c *s3Client) completeUpload(bucket string, upload incompleteUpload, parts []completePart) error {
+	body, err := xml.Marshal(completeMultipartUpload{Parts: parts})
+	if err != nil {
+		c.abortUpload(bucket, upload)
+		return iodine.New(err, nil)
+	}
+	query := url.Values{}
+	query.Set("uploadId", upload.UploadID)
+	req, err := c.newRequest("POST", "/"+bucket+"/"+upload.Key, canonicalQuery(query), body)
+	if err != nil {
+		c.abortUpload(bucket, upload)
+		return iodine.New(err, nil)
+	}
+	bodySHA256 := sha256.Sum256(body)
+	if err := c.do(req, hex.EncodeToString(bodySHA256[:]), "CompleteMultipartUpload", new(completeMultipartUploadResult)); err != nil {
+		c.abortUpload(bucket, upload)
+		return iodine.New(err, nil)
+	}
+	return nil
+}
+
+// uploadPart - upload one part, its body is sent as is without a copy
+func (c *s3Client) uploadPart(p, uploadID string, number int, part []byte) (string, error) {
+	query := url.Values{}
+	query.Set("partNumber", strconv.Itoa(number))
+	query.Set("uploadId", uploadID)
+	req, err := c.newRequest("PUT", p, canonicalQuery(query), part)
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	md5Sum := md5.Sum(part)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum[:]))
+	c.sign(req, unsignedPayload)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", c.toClientError(res, "UploadPart")
+	}
+	return res.Header.Get("ETag"), nil
+}
+
+// ListIncomplete - list incomplete uploads below the URL, names are relative to its folder
+func (c *s3Client) ListIncomplete(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel)
+	go c.listIncompleteInRoutine(recursive, contentCh)
+	return contentCh
+}
+
+func (c *s3Client) listIncompleteInRoutine(recursive bool, contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	b, o := c.url2BucketAndObject()
+	buckets := []string{b}
+	if b == "" { // all buckets, names carry the bucket
+		buckets = nil
+		for bucket := range c.api.ListBuckets() {
+			if bucket.Err != nil {
+				contentCh <- client.ContentOnChannel{Err: iodine.New(bucket.Err, nil)}
+				return
+			}
+			buckets = append(buckets, bucket.Stat.Name)
+		}
+	}
+	separator := string(c.hostURL.Separator)
+	folder := o[:strings.LastIndex(o, separator)+1]
+	delimiter := separator
+	if recursive || b == "" {
+		delimiter = ""
+	}
+	for _, bucket := range buckets {
+		uploadCh := make(chan incompleteUpload)
+		prefixCh := make(chan string)
+		errCh := make(chan error, 1)
+		go func(bucket string) {
+			defer close(uploadCh)
+			defer close(prefixCh)
+			errCh <- c.listIncompleteUploads(bucket, o, delimiter, uploadCh, prefixCh)
+		}(bucket)
+		name := func(key string) string {
+			if b == "" {
+				return bucket + separator + key
+			}
+			return strings.TrimPrefix(key, folder)
+		}
+		for uploadCh != nil || prefixCh != nil {
+			select {
+			case upload, ok := <-uploadCh:
+				if !ok {
+					uploadCh = nil
+					continue
+				}
+				size, err := c.uploadedSize(bucket, upload)
+				if err != nil {
+					contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+					continue
+				}
+				contentCh <- client.ContentOnChannel{Content: &client.Content{
+					Name: name(upload.Key),
+					Time: upload.Initiated,
+					Size: size,
+					Type: os.FileMode(0664),
+				}}
+			case prefix, ok := <-prefixCh:
+				if !ok {
+					prefixCh = nil
+					continue
+				}
+				contentCh <- client.ContentOnChannel{Content: &client.Content{
+					Name: name(prefix),
+					Time: time.Now(),
+					Type: os.ModeDir,
+				}}
+			}
+		}
+		if err := <-errCh; err != nil {
+			contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+			return
+		}
+	}
+}
+
+// RemoveIncomplete - abort incomplete uploads of the object, or of all objects under the URL if recursive
+func (c *s3Client) RemoveIncomplete(recursive bool) error {
+	b, o := c.url2BucketAndObject()
+	if b == "" || (o == "" && !recursive) {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	uploadCh := make(chan incompleteUpload)
+	prefixCh := make(chan string)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(uploadCh)
+		defer close(prefixCh)
+		errCh <- c.listIncompleteUploads(b, o, "", uploadCh, prefixCh)
+	}()
+	var abortErr error
+	for upload := range uploadCh {
+		if abortErr != nil || (!recursive && upload.Key != o) {
+			continue // keep draining the listing
+		}
+		abortErr = c.abortUpload(b, upload)
+	}
+	if err := <-errCh; err != nil {
+		return iodine.New(err, nil)
+	}
+	if abortErr != nil {
+		return iodine.New(abortErr, nil)
+	}
+	return nil
+}
+
+// RemoveBucket - remove the bucket, S3 refuses buckets with objects, versions or incomplete uploads
+func (c *s3Client) RemoveBucket() error {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object != "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	return c.doXMLRequest("DELETE", "/"+bucket, nil, "DeleteBucket", nil)
+}
+
+// Remove - remove the object
+func (c *s3Client) Remove() error {
+	b, o := c.url2BucketAndObject()
+	if b == "" || o == "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	if err := c.api.RemoveObject(b, o); err != nil {
+		return iodine.New(err, nil)
+	}
+	return nil
+}
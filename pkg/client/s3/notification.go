@@ -0,0 +1,143 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"encoding/xml"
+	"net/url"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// notificationConfiguration - body of GET and PUT Bucket notification, targets are grouped by their kind
+type notificationConfiguration struct {
+	XMLName        xml.Name             `xml:"NotificationConfiguration"`
+	Queues         []notificationTarget `xml:"QueueConfiguration"`
+	Topics         []notificationTarget `xml:"TopicConfiguration"`
+	CloudFunctions []notificationTarget `xml:"CloudFunctionConfiguration"`
+}
+
+// notificationTarget - a Queue, Topic or CloudFunction configuration, only the ARN of its kind is set
+type notificationTarget struct {
+	ID            string              `xml:"Id,omitempty"`
+	Filter        *notificationFilter `xml:"Filter,omitempty"`
+	Queue         string              `xml:"Queue,omitempty"`
+	Topic         string              `xml:"Topic,omitempty"`
+	CloudFunction string              `xml:"CloudFunction,omitempty"`
+	Events        []string            `xml:"Event"`
+}
+
+type notificationFilter struct {
+	Rules []filterRule `xml:"S3Key>FilterRule"`
+}
+
+type filterRule struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+// toConfig - notification config of target with the ARN of its kind
+func (target notificationTarget) toConfig(arn string) client.NotificationConfig {
+	config := client.NotificationConfig{ID: target.ID, ARN: arn, Events: target.Events}
+	if target.Filter != nil {
+		for _, rule := range target.Filter.Rules {
+			switch strings.ToLower(rule.Name) {
+			case "prefix":
+				config.Prefix = rule.Value
+			case "suffix":
+				config.Suffix = rule.Value
+			}
+		}
+	}
+	return config
+}
+
+// GetNotifications - notification configs of the bucket, queues first then topics and cloud functions
+func (c *s3Client) GetNotifications() ([]client.NotificationConfig, error) {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object != "" {
+		return nil, iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	query := url.Values{}
+	query.Set("notification", "")
+	configuration := new(notificationConfiguration)
+	if err := c.doXMLRequest("GET", "/"+bucket, query, "GetBucketNotification", configuration); err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	var configs []client.NotificationConfig
+	for _, target := range configuration.Queues {
+		configs = append(configs, target.toConfig(target.Queue))
+	}
+	for _, target := range configuration.Topics {
+		configs = append(configs, target.toConfig(target.Topic))
+	}
+	for _, target := range configuration.CloudFunctions {
+		configs = append(configs, target.toConfig(target.CloudFunction))
+	}
+	return configs, nil
+}
+
+// SetNotifications - replace the notification configuration of the bucket. The kind of each target is the
+// service of its ARN, sqs, sns or lambda
+func (c *s3Client) SetNotifications(configs []client.NotificationConfig) error {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object != "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	configuration := notificationConfiguration{}
+	for _, config := range configs {
+		target := notificationTarget{ID: config.ID, Events: config.Events}
+		if config.Prefix != "" || config.Suffix != "" {
+			target.Filter = new(notificationFilter)
+			if config.Prefix != "" {
+				target.Filter.Rules = append(target.Filter.Rules, filterRule{Name: "prefix", Value: config.Prefix})
+			}
+			if config.Suffix != "" {
+				target.Filter.Rules = append(target.Filter.Rules, filterRule{Name: "suffix", Value: config.Suffix})
+			}
+		}
+		// arn:partition:service:region:account-id:resource
+		fields := strings.SplitN(config.ARN, ":", 6)
+		if len(fields) != 6 || fields[0] != "arn" {
+			return iodine.New(client.InvalidArgument{}, map[string]string{"ARN": config.ARN})
+		}
+		switch fields[2] {
+		case "sqs":
+			target.Queue = config.ARN
+			configuration.Queues = append(configuration.Queues, target)
+		case "sns":
+			target.Topic = config.ARN
+			configuration.Topics = append(configuration.Topics, target)
+		case "lambda":
+			target.CloudFunction = config.ARN
+			configuration.CloudFunctions = append(configuration.CloudFunctions, target)
+		default:
+			return iodine.New(client.InvalidArgument{}, map[string]string{"ARN": config.ARN})
+		}
+	}
+	body, err := xml.Marshal(configuration)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	req, err := c.newRequest("PUT", "/"+bucket, "notification=", body)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	return c.doWithBody(req, body, "PutBucketNotification")
+}
@@ -0,0 +1,72 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// legalHold - body of GET and PUT Object legal hold
+type legalHold struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	Status  string   `xml:"Status"`
+}
+
+// doWithBody - sign and send a request with an XML body, object lock APIs require its Content-MD5
+func (c *s3Client) doWithBody(req *http.Request, body []byte, api string) error {
+	md5sum := md5.Sum(body)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5sum[:]))
+	sum := sha256.Sum256(body)
+	return c.do(req, hex.EncodeToString(sum[:]), api, nil)
+}
+
+// GetLegalHold - true if a legal hold is set on the object, which cannot be removed while it is
+func (c *s3Client) GetLegalHold() (bool, error) {
+	req, err := c.newObjectRequest("GET", "legal-hold=", nil)
+	if err != nil {
+		return false, iodine.New(err, nil)
+	}
+	hold := new(legalHold)
+	if err := c.do(req, emptySHA256, "GetObjectLegalHold", hold); err != nil {
+		return false, iodine.New(err, nil)
+	}
+	return hold.Status == "ON", nil
+}
+
+// SetLegalHold - set or clear the legal hold of the object, only in buckets created with object lock
+func (c *s3Client) SetLegalHold(on bool) error {
+	hold := legalHold{Status: "OFF"}
+	if on {
+		hold.Status = "ON"
+	}
+	body, err := xml.Marshal(hold)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	req, err := c.newObjectRequest("PUT", "legal-hold=", body)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	return c.doWithBody(req, body, "PutObjectLegalHold")
+}
@@ -0,0 +1,77 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// GetBucketPolicy - JSON policy document of the bucket, empty if it has none. Policies are not XML, so the
+// reply is read as is
+func (c *s3Client) GetBucketPolicy() (string, error) {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket == "" {
+		return "", iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	req, err := c.newRequest("GET", "/"+bucket, "policy=", nil)
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	c.sign(req, emptySHA256)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		err := c.toClientError(res, "GetBucketPolicy")
+		if ErrorCode(err) == "NoSuchBucketPolicy" {
+			return "", nil
+		}
+		return "", err
+	}
+	policy, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	return string(policy), nil
+}
+
+// SetBucketPolicy - replace the policy of the bucket, or remove it if policy is empty
+func (c *s3Client) SetBucketPolicy(policy string) error {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket == "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	if policy == "" {
+		query := url.Values{}
+		query.Set("policy", "")
+		return c.doXMLRequest("DELETE", "/"+bucket, query, "DeleteBucketPolicy", nil)
+	}
+	req, err := c.newRequest("PUT", "/"+bucket, "policy=", []byte(policy))
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	sum := sha256.Sum256([]byte(policy))
+	return c.do(req, hex.EncodeToString(sum[:]), "PutBucketPolicy", nil)
+}
@@ -17,7 +17,9 @@
 package s3
 
 import (
+	"encoding/xml"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -38,6 +40,26 @@ type Config struct {
 	AppVersion      string
 	AppComments     []string
 	Debug           bool
+	// Lookup selects bucket addressing, one of LookupAuto, LookupDNS or LookupPath. Empty is LookupAuto
+	Lookup string
+	// Signature is one of SignatureAuto, SignatureV2 or SignatureV4
+	Signature string
+	// Region requests are signed for, derived from the endpoint if empty
+	Region string
+	// Anonymous sends requests unsigned, keys are ignored. Only public buckets can be accessed
+	Anonymous bool
+	// MaxIdleConnsPerHost connections are kept open for reuse, DefaultMaxIdleConnsPerHost if 0
+	MaxIdleConnsPerHost int
+	// RequestTimeout limits the wait for a reply to each request, not the transfer of its body. No limit if 0
+	RequestTimeout time.Duration
+	// KeepAlive period of TCP connections, DefaultKeepAlive if 0
+	KeepAlive time.Duration
+	// Proxy URL requests are sent through, ProxyNone connects directly. Taken from environment if empty
+	Proxy string
+	// Insecure accepts any TLS certificate, for servers with self-signed certificates
+	Insecure bool
+	// StorageClass uploaded objects are stored with, default of the bucket if empty
+	StorageClass string
 
 	// Used for SSL transport layer
 	CertPEM string
@@ -53,6 +75,16 @@ type TLSConfig struct {
 type s3Client struct {
 	api     minio.API
 	hostURL *client.URL
+
+	// used for requests the API library has no call for
+	httpClient      *http.Client
+	accessKeyID     string
+	secretAccessKey string
+	userAgent       string
+	lookup          string
+	signatureConfig string
+	storageClass    string
+	state           *signatureState
 }
 
 // New returns an initialized s3Client structure. if debug use a internal trace transport
@@ -61,29 +93,61 @@ func New(config *Config) (client.Client, error) {
 	if err != nil {
 		return nil, iodine.New(err, nil)
 	}
+	if !IsValidLookup(config.Lookup) {
+		return nil, iodine.New(InvalidLookup{Lookup: config.Lookup}, nil)
+	}
+	switch config.Signature {
+	case SignatureAuto, SignatureV2, SignatureV4:
+	default:
+		return nil, iodine.New(client.InvalidArgument{}, nil)
+	}
+	if config.MaxIdleConnsPerHost < 0 || config.RequestTimeout < 0 || config.KeepAlive < 0 {
+		return nil, iodine.New(client.InvalidArgument{}, nil)
+	}
+	if _, err := proxyFunc(config.Proxy); err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	accessKeyID, secretAccessKey := config.AccessKeyID, config.SecretAccessKey
+	if config.Anonymous { // neither the API library nor our signers sign without keys
+		accessKeyID, secretAccessKey = "", ""
+	}
 	var transport http.RoundTripper
 	switch {
 	case config.Debug == true:
-		transport = GetNewTraceTransport(NewTrace(), http.DefaultTransport)
+		transport = GetNewTraceTransport(NewTrace(), newTransport(config))
 	default:
-		transport = http.DefaultTransport
+		transport = GetNewLogTransport(newTransport(config))
 	}
+	c := &s3Client{
+		hostURL:         u,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		userAgent:       config.AppName + "/" + config.AppVersion,
+		lookup:          config.Lookup,
+		signatureConfig: config.Signature,
+		storageClass:    config.StorageClass,
+		state: &signatureState{
+			signature: config.Signature,
+			region:    config.Region,
+			detected:  config.Signature == SignatureV2, // the API library signs with version 4 only
+		},
+	}
+	transport = bucketLookup{client: c, transport: signatureVersion{client: c, transport: transport}}
 	s3Conf := minio.Config{
-		AccessKeyID:     config.AccessKeyID,
-		SecretAccessKey: config.SecretAccessKey,
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
 		Transport:       transport,
 		Endpoint:        u.Scheme + "://" + u.Host,
 	}
-	s3Conf.AccessKeyID = config.AccessKeyID
-	s3Conf.SecretAccessKey = config.SecretAccessKey
-	s3Conf.Transport = transport
 	s3Conf.SetUserAgent(config.AppName, config.AppVersion, config.AppComments...)
-	s3Conf.Endpoint = u.Scheme + "://" + u.Host
+	s3Conf.Region = config.Region
 	api, err := minio.New(s3Conf)
 	if err != nil {
 		return nil, err
 	}
-	return &s3Client{api: api, hostURL: u}, nil
+	c.api = api
+	c.httpClient = &http.Client{Transport: transport}
+	return c, nil
 }
 
 // URL get url
@@ -112,13 +176,31 @@ func (e ObjectAlreadyExists) Error() string {
 
 // PutObject - put object
 func (c *s3Client) PutObject(size int64, data io.Reader) error {
+	return c.PutObjectWithMetadata(size, data, nil)
+}
+
+// PutObjectWithMetadata - put object with content headers and user metadata, see isStorableMetadata
+func (c *s3Client) PutObjectWithMetadata(size int64, data io.Reader, metadata map[string]string) error {
 	// md5 is purposefully ignored since AmazonS3 does not return proper md5sum
 	// for a multipart upload and there is no need to cross verify,
 	// invidual parts are properly verified
 	bucket, object := c.url2BucketAndObject()
-	err := c.api.PutObject(bucket, object, "application/octet-stream", size, data)
+	header := c.objectHeader(metadata)
+	var err error
+	switch mapped, ok := data.(client.MappedReader); {
+	// size could be 0 for virtual files and standard input, they are streamed part by part till EOF
+	case size == 0:
+		err = c.putStream(bucket, object, header, 0, data)
+	// whole memory mapped files worth a multipart upload are sent without copying
+	case ok && size >= minMappedPartSize && int64(len(mapped.Bytes())) == size:
+		err = c.putMapped(bucket, object, header, mapped)
+	case len(header) > 1:
+		err = c.putWithHeader(bucket, object, header, size, data)
+	default:
+		err = c.api.PutObject(bucket, object, header.Get("Content-Type"), size, data)
+	}
 	if err != nil {
-		if minio.ToErrorResponse(err).Code == "MethodNotAllowed" {
+		if ErrorCode(err) == "MethodNotAllowed" {
 			return iodine.New(ObjectAlreadyExists{Object: object}, nil)
 		}
 		return iodine.New(err, nil)
@@ -126,15 +208,65 @@ func (c *s3Client) PutObject(size int64, data io.Reader) error {
 	return nil
 }
 
-// MakeBucket - make a new bucket
+// spoolObject - copy data of unknown length to a temporary file rewound for reading, caller removes it
+func spoolObject(data io.Reader) (*os.File, int64, error) {
+	spool, err := ioutil.TempFile("", "mc-spool-")
+	if err != nil {
+		return nil, 0, iodine.New(err, nil)
+	}
+	size, err := io.Copy(spool, data)
+	if err == nil {
+		_, err = spool.Seek(0, os.SEEK_SET)
+	}
+	if err != nil {
+		spool.Close()
+		os.Remove(spool.Name())
+		return nil, 0, iodine.New(err, nil)
+	}
+	return spool, size, nil
+}
+
+// MakeBucket - make a new bucket in the region of the endpoint
 func (c *s3Client) MakeBucket() error {
+	return c.MakeBucketWithOptions(client.BucketOptions{})
+}
+
+// createBucketConfiguration - body of PUT Bucket for buckets outside of the default region
+type createBucketConfiguration struct {
+	XMLName            xml.Name `xml:"CreateBucketConfiguration"`
+	LocationConstraint string
+}
+
+// MakeBucketWithOptions - make a new bucket in options.Region, or in the region of the endpoint if empty.
+// Buckets of the default region us-east-1 are made without a location constraint
+func (c *s3Client) MakeBucketWithOptions(options client.BucketOptions) error {
 	bucket, object := c.url2BucketAndObject()
-	if object != "" {
+	if bucket == "" || object != "" {
 		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
 	}
-	// location string is intentionally left out
-	err := c.api.MakeBucket(bucket, minio.BucketACL("private"))
-	return iodine.New(err, nil)
+	location := options.Region
+	if location == "" {
+		location = c.getRegion()
+	}
+	var body []byte
+	if location != "us-east-1" && location != "milkyway" {
+		var err error
+		body, err = xml.Marshal(createBucketConfiguration{LocationConstraint: location})
+		if err != nil {
+			return iodine.New(err, nil)
+		}
+	}
+	req, err := c.newRequest("PUT", "/"+bucket, "", body)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if options.ObjectLock {
+		req.Header.Set("X-Amz-Bucket-Object-Lock-Enabled", "true")
+	}
+	if body == nil {
+		return c.do(req, emptySHA256, "PutBucket", nil)
+	}
+	return c.doWithBody(req, body, "PutBucket")
 }
 
 // SetBucketACL add canned acl's on a bucket
@@ -162,7 +294,7 @@ func (c *s3Client) Stat() (*client.Content, error) {
 		}
 	}
 	if object != "" {
-		metadata, err := c.api.StatObject(bucket, object)
+		metadata, storedMetadata, err := c.headObject("")
 		if err != nil {
 			errResponse := minio.ToErrorResponse(err)
 			if errResponse != nil {
@@ -183,6 +315,8 @@ func (c *s3Client) Stat() (*client.Content, error) {
 		objectMetadata.Name = metadata.Key
 		objectMetadata.Time = metadata.LastModified
 		objectMetadata.Size = metadata.Size
+		setObjectMetadata(objectMetadata, metadata)
+		objectMetadata.Metadata = storedMetadata
 		objectMetadata.Type = os.FileMode(0664)
 		return objectMetadata, nil
 	}
@@ -213,6 +347,14 @@ func (c *s3Client) url2BucketAndObject() (bucketName, objectName string) {
 	return bucketName, objectName
 }
 
+// setObjectMetadata copies optional object metadata returned by the server into content
+func setObjectMetadata(content *client.Content, stat minio.ObjectStat) {
+	content.ETag = strings.Trim(stat.ETag, "\"")
+	content.Owner = stat.Owner.DisplayName
+	content.OwnerID = stat.Owner.ID
+	content.StorageClass = stat.StorageClass
+}
+
 /// Bucket API operations
 
 // List - list at delimited path, if not recursive
@@ -259,6 +401,7 @@ func (c *s3Client) listInRoutine(contentCh chan client.ContentOnChannel) {
 			content.Time = metadata.LastModified
 			content.Size = metadata.Size
 			content.Type = os.FileMode(0664)
+			setObjectMetadata(content, metadata)
 			contentCh <- client.ContentOnChannel{
 				Content: content,
 				Err:     nil,
@@ -287,6 +430,7 @@ func (c *s3Client) listInRoutine(contentCh chan client.ContentOnChannel) {
 					content.Size = object.Stat.Size
 					content.Time = object.Stat.LastModified
 					content.Type = os.FileMode(0664)
+					setObjectMetadata(content, object.Stat)
 				}
 				contentCh <- client.ContentOnChannel{
 					Content: content,
@@ -323,6 +467,7 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 				content.Size = object.Stat.Size
 				content.Time = object.Stat.LastModified
 				content.Type = os.FileMode(0664)
+				setObjectMetadata(content, object.Stat)
 				contentCh <- client.ContentOnChannel{
 					Content: content,
 					Err:     nil,
@@ -357,6 +502,7 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 			content.Size = object.Stat.Size
 			content.Time = object.Stat.LastModified
 			content.Type = os.FileMode(0664)
+			setObjectMetadata(content, object.Stat)
 			contentCh <- client.ContentOnChannel{
 				Content: content,
 				Err:     nil,
@@ -364,3 +510,11 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 		}
 	}
 }
+
+// ErrorCode - S3 error code of an error reply like "AccessDenied", empty for other errors
+func ErrorCode(err error) string {
+	if errorResponse := minio.ToErrorResponse(iodine.ToError(err)); errorResponse != nil {
+		return errorResponse.Code
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net/http"
+
+	"github.com/minio/minio-go"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// selectCSVInput - lines are records, carriage returns separate fields so a line is one field
+type selectCSVInput struct {
+	FileHeaderInfo  string
+	RecordDelimiter string
+	FieldDelimiter  string
+}
+
+// selectRequest - body of Select Object Content
+type selectRequest struct {
+	XMLName            xml.Name `xml:"SelectObjectContentRequest"`
+	Expression         string
+	ExpressionType     string
+	InputSerialization struct {
+		CompressionType string
+		CSV             selectCSVInput
+	}
+	OutputSerialization struct {
+		CSV struct{}
+	}
+}
+
+// errMalformedEvent - reply of Select Object Content is not a valid event stream
+var errMalformedEvent = errors.New("malformed event stream message")
+
+// SelectLines - CSV output of SQL expression over the lines of the object, run with Select Object Content
+func (c *s3Client) SelectLines(expression string) (io.ReadCloser, error) {
+	request := selectRequest{Expression: expression, ExpressionType: "SQL"}
+	request.InputSerialization.CompressionType = "NONE"
+	request.InputSerialization.CSV = selectCSVInput{FileHeaderInfo: "NONE", RecordDelimiter: "\n", FieldDelimiter: "\r"}
+	body, err := xml.Marshal(request)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	req, err := c.newObjectRequest("POST", "select=&select-type=2", body)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	sum := sha256.Sum256(body)
+	c.sign(req, hex.EncodeToString(sum[:]))
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, c.toClientError(res, "SelectObjectContent")
+	}
+	return &selectReader{body: res.Body}, nil
+}
+
+// selectReader - payloads of Records events of an event stream, errors sent in the stream are returned by Read
+type selectReader struct {
+	body    io.ReadCloser
+	payload []byte
+	end     bool
+}
+
+func (r *selectReader) Read(p []byte) (int, error) {
+	for len(r.payload) == 0 {
+		if r.end {
+			return 0, io.EOF
+		}
+		headers, payload, err := readEventMessage(r.body)
+		if err == io.EOF { // the stream must finish with an End event
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return 0, iodine.New(err, nil)
+		}
+		if headers[":message-type"] == "error" {
+			return 0, iodine.New(minio.ErrorResponse{Code: headers[":error-code"], Message: headers[":error-message"]}, nil)
+		}
+		switch headers[":event-type"] {
+		case "Records":
+			r.payload = payload
+		case "End":
+			r.end = true
+		}
+		// Stats, Progress and Cont keep the connection alive only
+	}
+	n := copy(p, r.payload)
+	r.payload = r.payload[n:]
+	return n, nil
+}
+
+func (r *selectReader) Close() error {
+	return r.body.Close()
+}
+
+// readEventMessage - headers and payload of the next message of an event stream, io.EOF at its end
+func readEventMessage(reader io.Reader) (map[string]string, []byte, error) {
+	// prelude is total length, headers length and their CRC
+	prelude := make([]byte, 12)
+	if _, err := io.ReadFull(reader, prelude); err != nil {
+		return nil, nil, err
+	}
+	totalLength := binary.BigEndian.Uint32(prelude[0:4])
+	headersLength := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[0:8]) != binary.BigEndian.Uint32(prelude[8:12]) || totalLength < 16+headersLength {
+		return nil, nil, errMalformedEvent
+	}
+	message := make([]byte, totalLength)
+	copy(message, prelude)
+	if _, err := io.ReadFull(reader, message[12:]); err != nil {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	if crc32.ChecksumIEEE(message[:totalLength-4]) != binary.BigEndian.Uint32(message[totalLength-4:]) {
+		return nil, nil, errMalformedEvent
+	}
+	headers := make(map[string]string)
+	buf := message[12 : 12+headersLength]
+	for len(buf) > 0 {
+		nameLength := int(buf[0])
+		// name, value type 7 for strings, the only type S3 sends, and value length
+		if len(buf) < 1+nameLength+3 || buf[1+nameLength] != 7 {
+			return nil, nil, errMalformedEvent
+		}
+		name := string(buf[1 : 1+nameLength])
+		buf = buf[1+nameLength+1:]
+		valueLength := int(binary.BigEndian.Uint16(buf[0:2]))
+		if len(buf) < 2+valueLength {
+			return nil, nil, errMalformedEvent
+		}
+		headers[name] = string(buf[2 : 2+valueLength])
+		buf = buf[2+valueLength:]
+	}
+	return headers, message[12+headersLength : totalLength-4], nil
+}
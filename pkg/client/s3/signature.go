@@ -0,0 +1,303 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/console"
+)
+
+// Supported values for Config Signature
+const (
+	SignatureAuto = ""     // signature version 4, falling back to version 2 for servers rejecting it
+	SignatureV2   = "S3v2" // signature version 2 of older gateways
+	SignatureV4   = "S3v4" // signature version 4 only
+)
+
+// subResources - query parameters which are part of the signature version 2 canonical resource
+var subResources = map[string]bool{
+	"acl": true, "cors": true, "delete": true, "encryption": true, "legal-hold": true, "lifecycle": true,
+	"location": true, "logging": true, "notification": true, "object-lock": true, "partNumber": true, "policy": true,
+	"requestPayment": true, "retention": true, "select": true, "select-type": true, "tagging": true,
+	"torrent": true, "uploadId": true, "uploads": true, "versionId": true, "versioning": true,
+	"versions": true, "website": true, "response-cache-control": true, "response-content-disposition": true,
+	"response-content-encoding": true, "response-content-language": true, "response-content-type": true,
+	"response-expires": true,
+}
+
+// signatureState - signature version and region, auto detected ones are learnt from server replies
+type signatureState struct {
+	mutex     sync.RWMutex
+	signature string
+	region    string
+	detected  bool // signature or region differ from the ones the API library signs with
+	settled   bool // a reply told whether the server accepts the signature, nothing is detected anymore
+}
+
+// getRegion - signing region, configured or detected, else derived from the endpoint host
+func (c *s3Client) getRegion() string {
+	c.state.mutex.RLock()
+	defer c.state.mutex.RUnlock()
+	if c.state.region != "" {
+		return c.state.region
+	}
+	return region(c.hostURL.Host)
+}
+
+// getSignature - signature version requests are sent with
+func (c *s3Client) getSignature() string {
+	c.state.mutex.RLock()
+	defer c.state.mutex.RUnlock()
+	return c.state.signature
+}
+
+// Signature - signature version and region requests are signed with, version 4 until another one is detected
+func (c *s3Client) Signature() (string, string) {
+	signature := c.getSignature()
+	if signature == SignatureAuto {
+		signature = SignatureV4
+	}
+	return signature, c.getRegion()
+}
+
+// sign - sign a request with the signature version of this client
+func (c *s3Client) sign(req *http.Request, payloadSHA256 string) {
+	if c.getSignature() == SignatureV2 {
+		c.signV2(req)
+		return
+	}
+	c.signV4(req, payloadSHA256)
+}
+
+// signV2 - sign a request with signature version 2, see
+// http://docs.aws.amazon.com/AmazonS3/latest/dev/RESTAuthentication.html
+func (c *s3Client) signV2(req *http.Request) {
+	// version 4 headers would be part of the signed x-amz-* headers
+	req.Header.Del("X-Amz-Date")
+	req.Header.Del("X-Amz-Content-Sha256")
+	if req.Header.Get("Date") == "" {
+		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+	if c.accessKeyID == "" { // anonymous access
+		return
+	}
+	var amzHeaders []string
+	for key, values := range req.Header {
+		if strings.HasPrefix(strings.ToLower(key), "x-amz-") {
+			amzHeaders = append(amzHeaders, strings.ToLower(key)+":"+strings.TrimSpace(strings.Join(values, ",")))
+		}
+	}
+	sort.Strings(amzHeaders)
+
+	// virtual host style requests carry the bucket in the host only
+	resource := req.URL.EscapedPath()
+	if host := strings.Split(req.URL.Host, ":")[0]; strings.HasSuffix(host, "."+strings.Split(c.hostURL.Host, ":")[0]) {
+		resource = "/" + strings.TrimSuffix(host, "."+strings.Split(c.hostURL.Host, ":")[0]) + resource
+	}
+	var params []string
+	for key, values := range req.URL.Query() {
+		if !subResources[key] {
+			continue
+		}
+		if values[0] == "" {
+			params = append(params, key)
+			continue
+		}
+		params = append(params, key+"="+values[0])
+	}
+	sort.Strings(params)
+	if len(params) > 0 {
+		resource += "?" + strings.Join(params, "&")
+	}
+
+	stringToSign := req.Method + "\n" + req.Header.Get("Content-Md5") + "\n" + req.Header.Get("Content-Type") + "\n" +
+		req.Header.Get("Date") + "\n"
+	for _, header := range amzHeaders {
+		stringToSign += header + "\n"
+	}
+	stringToSign += resource
+	hash := hmac.New(sha1.New, []byte(c.secretAccessKey))
+	hash.Write([]byte(stringToSign))
+	req.Header.Set("Authorization", "AWS "+c.accessKeyID+":"+base64.StdEncoding.EncodeToString(hash.Sum(nil)))
+}
+
+// signatureVersion - signs requests again with the signature version and region of the client, the API library
+// only knows version 4 and the region of the endpoint. In auto mode a request rejected by a server not speaking
+// version 4 is retried with version 2, a request signed for the wrong region is retried with the region named
+// by the server. Both are remembered for later requests
+type signatureVersion struct {
+	client    *s3Client
+	transport http.RoundTripper
+}
+
+// isSignatureVersionError - reply of a server rejecting the signature version of a request, rather than the
+// keys or permissions. Servers without version 4 mostly fail to verify it, some tell it is not supported
+func isSignatureVersionError(code, message string) bool {
+	if code == "SignatureDoesNotMatch" {
+		return true
+	}
+	switch code {
+	case "InvalidArgument", "InvalidRequest", "NotImplemented", "AccessDenied":
+		message = strings.ToLower(message)
+		for _, unsupported := range []string{"aws4-hmac-sha256", "signature version", "authorization mechanism"} {
+			if strings.Contains(message, unsupported) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// settle - stop detecting, later replies are passed on as they are
+func (s signatureVersion) settle() {
+	s.client.state.mutex.Lock()
+	s.client.state.settled = true
+	s.client.state.mutex.Unlock()
+}
+
+// isReplayable - request can be sent a second time
+func isReplayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// cloneRequest - copy of a request with its own URL and headers, RoundTrippers must not modify their request
+func cloneRequest(req *http.Request) *http.Request {
+	newReq := new(http.Request)
+	*newReq = *req
+	newURL := *req.URL
+	newReq.URL = &newURL
+	newReq.Header = make(http.Header, len(req.Header))
+	for key, values := range req.Header {
+		newReq.Header[key] = append([]string(nil), values...)
+	}
+	return newReq
+}
+
+// resign - copy of req signed with the current signature version and region
+func (s signatureVersion) resign(req *http.Request, payloadSHA256 string) (*http.Request, error) {
+	newReq := cloneRequest(req)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		newReq.Body = body
+	}
+	newReq.Header.Del("Authorization")
+	s.client.sign(newReq, payloadSHA256)
+	return newReq, nil
+}
+
+// RoundTrip re-signs version 4 requests when the client needs another signature or region, detected until
+// the first reply which tells whether the server accepts the signature
+func (s signatureVersion) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256") {
+		return s.transport.RoundTrip(req)
+	}
+	payloadSHA256 := req.Header.Get("X-Amz-Content-Sha256")
+	s.client.state.mutex.RLock()
+	detected := s.client.state.detected
+	s.client.state.mutex.RUnlock()
+	if detected {
+		newReq, err := s.resign(req, payloadSHA256)
+		if err != nil {
+			return nil, err
+		}
+		return s.transport.RoundTrip(newReq)
+	}
+	res, err := s.transport.RoundTrip(req)
+	if err != nil || s.client.signatureConfig != SignatureAuto || !isReplayable(req) {
+		return res, err
+	}
+	s.client.state.mutex.RLock()
+	settled := s.client.state.settled
+	s.client.state.mutex.RUnlock()
+	if settled {
+		return res, nil
+	}
+	if res.StatusCode != http.StatusBadRequest && res.StatusCode != http.StatusForbidden {
+		s.settle()
+		return res, nil
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	errorResponse := struct {
+		Code    string
+		Message string
+		Region  string
+	}{}
+	xml.Unmarshal(body, &errorResponse)
+	if errorResponse.Region == "" { // replies to HEAD requests have no body
+		errorResponse.Region = res.Header.Get("X-Amz-Bucket-Region")
+	}
+
+	state := signatureState{signature: SignatureV2}
+	switch {
+	case errorResponse.Region != "" && errorResponse.Region != s.client.getRegion():
+		state = signatureState{signature: SignatureV4, region: errorResponse.Region}
+	case isSignatureVersionError(errorResponse.Code, errorResponse.Message):
+	case errorResponse.Code == "":
+		// replies to HEAD requests have no body to tell, a later request does
+		return res, nil
+	default:
+		s.settle()
+		return res, nil
+	}
+	defer s.settle()
+	retryClient := *s.client
+	retryClient.state = &state
+	newReq := cloneRequest(req)
+	if req.GetBody != nil {
+		if newReq.Body, err = req.GetBody(); err != nil {
+			return res, nil
+		}
+	}
+	newReq.Header.Del("Authorization")
+	retryClient.sign(newReq, payloadSHA256)
+	retryRes, err := s.transport.RoundTrip(newReq)
+	if err != nil || retryRes.StatusCode >= 400 { // the first reply explains the failure best
+		if err == nil {
+			retryRes.Body.Close()
+		}
+		return res, nil
+	}
+	console.Logf(console.LogTransport, console.LogInfo, "Server ‘%s’ accepted signature %s region ‘%s’, using it from now on.\n",
+		s.client.hostURL.Host, state.signature, retryClient.getRegion())
+	s.client.state.mutex.Lock()
+	s.client.state.signature = state.signature
+	if state.region != "" {
+		s.client.state.region = state.region
+	}
+	s.client.state.detected = true
+	s.client.state.mutex.Unlock()
+	return retryRes, nil
+}
@@ -0,0 +1,258 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-go"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+const (
+	iso8601Format = "20060102T150405Z"
+	yyyymmdd      = "20060102"
+
+	// sha256 of an empty payload
+	emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+type tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+// byTagKey - sort tags by key, tags are sent in a stable order
+type byTagKey []tag
+
+func (t byTagKey) Len() int           { return len(t) }
+func (t byTagKey) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t byTagKey) Less(i, j int) bool { return t[i].Key < t[j].Key }
+
+// tagging - body of GET and PUT Object tagging
+type tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	TagSet  struct {
+		Tags []tag `xml:"Tag"`
+	} `xml:"TagSet"`
+}
+
+// region - signing region for an endpoint host, same fallback as the API library for non AWS hosts
+func region(host string) string {
+	host = strings.Split(host, ":")[0]
+	switch {
+	case host == "s3.amazonaws.com", host == "s3-external-1.amazonaws.com":
+		return "us-east-1"
+	case strings.HasPrefix(host, "s3-") && strings.HasSuffix(host, ".amazonaws.com"):
+		return strings.TrimSuffix(strings.TrimPrefix(host, "s3-"), ".amazonaws.com")
+	}
+	return "milkyway"
+}
+
+// encodePath - URI encode every path segment as required by signature version 4
+func encodePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = strings.Replace(url.QueryEscape(segment), "+", "%20", -1)
+	}
+	return strings.Join(segments, "/")
+}
+
+func sumHMAC(key []byte, data string) []byte {
+	hash := hmac.New(sha256.New, key)
+	hash.Write([]byte(data))
+	return hash.Sum(nil)
+}
+
+// signV4 - sign a request with host and all x-amz-* headers, payloadSHA256 is the hex sha256 of the body, see
+// http://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-authenticating-requests.html
+func (c *s3Client) signV4(req *http.Request, payloadSHA256 string) {
+	t := time.Now().UTC()
+	req.Header.Set("X-Amz-Date", t.Format(iso8601Format))
+	req.Header.Set("X-Amz-Content-Sha256", payloadSHA256)
+	if c.accessKeyID == "" { // anonymous access
+		return
+	}
+	headers := []string{"host"}
+	for key := range req.Header {
+		if strings.HasPrefix(strings.ToLower(key), "x-amz-") {
+			headers = append(headers, strings.ToLower(key))
+		}
+	}
+	sort.Strings(headers)
+	var canonicalHeaders []string
+	for _, header := range headers {
+		value := req.URL.Host
+		if header != "host" {
+			value = strings.TrimSpace(req.Header.Get(header))
+		}
+		canonicalHeaders = append(canonicalHeaders, header+":"+value)
+	}
+	signedHeaders := strings.Join(headers, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		strings.Join(canonicalHeaders, "\n"),
+		"",
+		signedHeaders,
+		payloadSHA256,
+	}, "\n")
+	scope := strings.Join([]string{t.Format(yyyymmdd), c.getRegion(), "s3", "aws4_request"}, "/")
+	sum := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + t.Format(iso8601Format) + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	signingKey := sumHMAC([]byte("AWS4"+c.secretAccessKey), t.Format(yyyymmdd))
+	signingKey = sumHMAC(signingKey, c.getRegion())
+	signingKey = sumHMAC(signingKey, "s3")
+	signingKey = sumHMAC(signingKey, "aws4_request")
+	signature := hex.EncodeToString(sumHMAC(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+c.accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// newObjectRequest - signed request on the object of this client, query is a raw query like "tagging="
+func (c *s3Client) newObjectRequest(method, query string, body []byte) (*http.Request, error) {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object == "" {
+		return nil, iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	return c.newRequest(method, "/"+bucket+"/"+object, query, body)
+}
+
+// newRequest - request on path p of the endpoint of this client, to be signed by the caller
+func (c *s3Client) newRequest(method, p, query string, body []byte) (*http.Request, error) {
+	u := &url.URL{Scheme: c.hostURL.Scheme, Host: c.hostURL.Host, Path: p, RawPath: encodePath(p), RawQuery: query}
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	return req, nil
+}
+
+// toClientError - error of a failed response, missing objects are client.NotFound and
+// requests a server does not understand are client.APINotImplemented
+func (c *s3Client) toClientError(res *http.Response, api string) error {
+	errorResponse := minio.ErrorResponse{}
+	if xml.NewDecoder(res.Body).Decode(&errorResponse) != nil || errorResponse.Code == "" {
+		errorResponse.Code = res.Status
+	}
+	switch {
+	case res.StatusCode == http.StatusNotFound && (errorResponse.Code == "NoSuchKey" || res.Request.Method == "HEAD"):
+		return iodine.New(client.NotFound{Path: c.hostURL.String()}, nil)
+	case res.StatusCode == http.StatusNotImplemented || errorResponse.Code == "NotImplemented":
+		return iodine.New(client.APINotImplemented{API: api}, nil)
+	}
+	return iodine.New(errorResponse, nil)
+}
+
+// newTaggingRequest - request on the tagging subresource of the object of this client, or of the bucket for
+// bucket URLs
+func (c *s3Client) newTaggingRequest(method string, body []byte) (*http.Request, error) {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" {
+		return nil, iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	if object == "" {
+		return c.newRequest(method, "/"+bucket, "tagging=", body)
+	}
+	return c.newObjectRequest(method, "tagging=", body)
+}
+
+// GetObjectTags - tags set on an object or bucket, fetched with GET Object or Bucket tagging. Buckets without
+// tags have none instead of a NoSuchTagSet error
+func (c *s3Client) GetObjectTags() (map[string]string, error) {
+	req, err := c.newTaggingRequest("GET", nil)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	c.sign(req, emptySHA256)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		err := c.toClientError(res, "GetObjectTags")
+		if ErrorCode(err) == "NoSuchTagSet" {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	var result tagging
+	if err := xml.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	tags := make(map[string]string)
+	for _, tag := range result.TagSet.Tags {
+		tags[tag.Key] = tag.Value
+	}
+	return tags, nil
+}
+
+// SetObjectTags - replace tags of an object or bucket with PUT Object or Bucket tagging
+func (c *s3Client) SetObjectTags(tags map[string]string) error {
+	var request tagging
+	for key, value := range tags {
+		request.TagSet.Tags = append(request.TagSet.Tags, tag{Key: key, Value: value})
+	}
+	sort.Sort(byTagKey(request.TagSet.Tags))
+	body, err := xml.Marshal(request)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	req, err := c.newTaggingRequest("PUT", body)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	md5sum := md5.Sum(body)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5sum[:]))
+	sum := sha256.Sum256(body)
+	c.sign(req, hex.EncodeToString(sum[:]))
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 { // 204 for buckets
+		return c.toClientError(res, "SetObjectTags")
+	}
+	return nil
+}
+
+// RemoveObjectTags - remove all tags of an object or bucket with DELETE Object or Bucket tagging
+func (c *s3Client) RemoveObjectTags() error {
+	req, err := c.newTaggingRequest("DELETE", nil)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	return c.do(req, emptySHA256, "RemoveObjectTags", nil)
+}
@@ -0,0 +1,90 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Defaults of the transport tuning, Go keeps only two idle connections per host which parallel
+// copies exhaust, every further request would pay for a new TCP and TLS handshake
+const (
+	DefaultMaxIdleConnsPerHost = 16
+	DefaultKeepAlive           = 30 * time.Second
+	DefaultDialTimeout         = 30 * time.Second
+)
+
+// ProxyNone as Config.Proxy connects directly, proxies set in environment are ignored
+const ProxyNone = "none"
+
+// proxyFunc - proxy selection of a transport for Config.Proxy, HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY of environment if empty
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	switch proxy {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case ProxyNone:
+		return nil, nil
+	}
+	// "proxy.example.com:3128" as accepted in environment
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		return nil, iodine.New(client.InvalidArgument{}, map[string]string{"Proxy": proxy})
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+// newTransport - HTTP transport tuned by config, config.Proxy must have been checked with proxyFunc
+func newTransport(config *Config) *http.Transport {
+	maxIdleConnsPerHost := config.MaxIdleConnsPerHost
+	if maxIdleConnsPerHost == 0 {
+		maxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	keepAlive := config.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = DefaultKeepAlive
+	}
+	proxy, _ := proxyFunc(config.Proxy)
+	dialer := &net.Dialer{
+		Timeout:   DefaultDialTimeout,
+		KeepAlive: keepAlive,
+	}
+	var tlsConfig *tls.Config
+	if config.Insecure {
+		tlsConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &http.Transport{
+		TLSClientConfig:     tlsConfig,
+		Proxy:               proxy,
+		Dial:                dialer.Dial,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConnsPerHost: maxIdleConnsPerHost,
+		// whole requests are not limited, uploads and downloads of large objects take as long as they take
+		ResponseHeaderTimeout: config.RequestTimeout,
+	}
+}
@@ -0,0 +1,222 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-go"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// versionEntry - a Version or DeleteMarker of List Object Versions, delete markers have no size and ETag
+type versionEntry struct {
+	XMLName xml.Name
+	minio.ObjectStat
+	VersionID string `xml:"VersionId"`
+	IsLatest  bool
+}
+
+// listVersionsResult - a page of List Object Versions. Versions and delete markers are listed together in key
+// order, newest first for each key, so both are decoded into Entries
+type listVersionsResult struct {
+	XMLName        xml.Name       `xml:"ListVersionsResult"`
+	Entries        []versionEntry `xml:",any"`
+	CommonPrefixes []struct {
+		Prefix string
+	}
+	IsTruncated         bool
+	NextKeyMarker       string
+	NextVersionIDMarker string `xml:"NextVersionIdMarker"`
+}
+
+// ListVersions - all versions and delete markers of objects below the URL, names relative to its folder like
+// ListIncomplete. Buckets without versioning list one version "null" per object
+func (c *s3Client) ListVersions(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel)
+	go func() {
+		defer close(contentCh)
+		bucket, object := c.url2BucketAndObject()
+		if bucket == "" {
+			contentCh <- client.ContentOnChannel{Err: iodine.New(client.InvalidArgument{}, nil)}
+			return
+		}
+		separator := string(c.hostURL.Separator)
+		delimiter := separator
+		if recursive {
+			delimiter = ""
+		}
+		if err := c.listVersions(bucket, object, object[:strings.LastIndex(object, separator)+1], delimiter, contentCh); err != nil {
+			contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		}
+	}()
+	return contentCh
+}
+
+// listVersions - send versions of objects in bucket under prefix with names relative to folder, and folders
+// below prefix if delimiter is set
+func (c *s3Client) listVersions(bucket, prefix, folder, delimiter string, contentCh chan<- client.ContentOnChannel) error {
+	query := url.Values{}
+	query.Set("versions", "")
+	query.Set("prefix", prefix)
+	if delimiter != "" {
+		query.Set("delimiter", delimiter)
+	}
+	for {
+		result := new(listVersionsResult)
+		if err := c.doXMLRequest("GET", "/"+bucket, query, "ListObjectVersions", result); err != nil {
+			return iodine.New(err, nil)
+		}
+		// both lists are sorted, merged to keep the order of keys
+		entries, prefixes := result.Entries, result.CommonPrefixes
+		for len(entries) > 0 || len(prefixes) > 0 {
+			content := new(client.Content)
+			if len(prefixes) == 0 || (len(entries) > 0 && entries[0].Key < prefixes[0].Prefix) {
+				entry := entries[0]
+				entries = entries[1:]
+				if entry.XMLName.Local != "Version" && entry.XMLName.Local != "DeleteMarker" {
+					continue
+				}
+				content.Name = strings.TrimPrefix(entry.Key, folder)
+				content.Size = entry.Size
+				content.Time = entry.LastModified
+				content.Type = os.FileMode(0664)
+				content.VersionID = entry.VersionID
+				content.IsLatest = entry.IsLatest
+				content.IsDeleteMarker = entry.XMLName.Local == "DeleteMarker"
+				setObjectMetadata(content, entry.ObjectStat)
+			} else {
+				content.Name = strings.TrimPrefix(prefixes[0].Prefix, folder)
+				content.Time = time.Now()
+				content.Type = os.ModeDir
+				prefixes = prefixes[1:]
+			}
+			contentCh <- client.ContentOnChannel{Content: content}
+		}
+		if !result.IsTruncated {
+			return nil
+		}
+		query.Set("key-marker", result.NextKeyMarker)
+		query.Set("version-id-marker", result.NextVersionIDMarker)
+	}
+}
+
+// GetObjectVersion - reader of version versionID of the object from offset, length 0 reads to its end
+func (c *s3Client) GetObjectVersion(versionID string, offset, length int64) (io.ReadCloser, int64, error) {
+	req, err := c.newObjectRequest("GET", "versionId="+url.QueryEscape(versionID), nil)
+	if err != nil {
+		return nil, 0, iodine.New(err, nil)
+	}
+	switch {
+	case length > 0:
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	case offset > 0:
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	c.sign(req, emptySHA256)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, iodine.New(err, nil)
+	}
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusPartialContent {
+		defer res.Body.Close()
+		return nil, 0, c.toClientError(res, "GetObjectVersion")
+	}
+	return res.Body, res.ContentLength, nil
+}
+
+// StatVersion - size, time and stored metadata of version versionID of the object
+func (c *s3Client) StatVersion(versionID string) (*client.Content, error) {
+	stat, metadata, err := c.headObject("versionId=" + url.QueryEscape(versionID))
+	if errorResponse := minio.ToErrorResponse(err); errorResponse != nil && errorResponse.Code == "NoSuchKey" {
+		return nil, iodine.New(client.NotFound{Path: c.hostURL.String()}, nil)
+	}
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	content := &client.Content{
+		Name:      stat.Key,
+		Time:      stat.LastModified,
+		Size:      stat.Size,
+		Type:      os.FileMode(0664),
+		VersionID: versionID,
+		Metadata:  metadata,
+	}
+	setObjectMetadata(content, stat)
+	return content, nil
+}
+
+// RemoveVersion - remove version versionID of the object for good, removing a delete marker makes the version
+// below it current again
+func (c *s3Client) RemoveVersion(versionID string) error {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object == "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	query := url.Values{}
+	query.Set("versionId", versionID)
+	return c.doXMLRequest("DELETE", "/"+bucket+"/"+object, query, "DeleteObjectVersion", nil)
+}
+
+// versioningConfiguration - body of GET and PUT Bucket versioning
+type versioningConfiguration struct {
+	XMLName xml.Name `xml:"VersioningConfiguration"`
+	Status  string   `xml:"Status,omitempty"`
+}
+
+// GetVersioning - versioning status of the bucket, empty if it was never enabled
+func (c *s3Client) GetVersioning() (string, error) {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object != "" {
+		return "", iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	query := url.Values{}
+	query.Set("versioning", "")
+	configuration := new(versioningConfiguration)
+	if err := c.doXMLRequest("GET", "/"+bucket, query, "GetBucketVersioning", configuration); err != nil {
+		return "", iodine.New(err, nil)
+	}
+	return configuration.Status, nil
+}
+
+// SetVersioning - enable versioning of the bucket with status "Enabled" or suspend it with "Suspended"
+func (c *s3Client) SetVersioning(status string) error {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" || object != "" {
+		return iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	body, err := xml.Marshal(versioningConfiguration{Status: status})
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	req, err := c.newRequest("PUT", "/"+bucket, "versioning=", body)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	sum := sha256.Sum256(body)
+	return c.do(req, hex.EncodeToString(sum[:]), "PutBucketVersioning", nil)
+}
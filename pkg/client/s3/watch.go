@@ -0,0 +1,172 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// notificationEvents - event types requested from Listen Bucket Notification
+var notificationEvents = []string{"s3:ObjectCreated:*", "s3:ObjectRemoved:*"}
+
+// notificationRecord - event of a Listen Bucket Notification reply, in the format of bucket notifications
+type notificationRecord struct {
+	EventName string `json:"eventName"`
+	EventTime string `json:"eventTime"`
+	S3        struct {
+		Object struct {
+			Key  string `json:"key"`
+			Size int64  `json:"size"`
+		} `json:"object"`
+	} `json:"s3"`
+}
+
+// notificationInfo - line of a Listen Bucket Notification reply, empty lines keep the connection alive
+type notificationInfo struct {
+	Records []notificationRecord `json:"Records"`
+}
+
+// Watch - report changes of objects below the prefix of this client with Minio Listen Bucket Notification
+func (c *s3Client) Watch(recursive bool, doneCh <-chan struct{}) (<-chan client.EventInfo, error) {
+	return c.listen(recursive, time.Time{}, doneCh)
+}
+
+// WatchSince - events persisted by the server since the time, then live events as by Watch. Servers without
+// event persistence reply NotImplemented
+func (c *s3Client) WatchSince(since time.Time, recursive bool, doneCh <-chan struct{}) (<-chan client.EventInfo, error) {
+	return c.listen(recursive, since, doneCh)
+}
+
+// listen - send Listen Bucket Notification for the prefix of this client, asking for past events since the time
+// unless it is zero, and send events of its reply until doneCh is closed
+func (c *s3Client) listen(recursive bool, since time.Time, doneCh <-chan struct{}) (<-chan client.EventInfo, error) {
+	bucket, prefix := c.url2BucketAndObject()
+	if bucket == "" {
+		return nil, iodine.New(client.InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") { // watched prefixes are folders
+		prefix = prefix + "/"
+	}
+	query := url.Values{}
+	query["events"] = notificationEvents
+	query.Set("prefix", prefix)
+	if !since.IsZero() {
+		query.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	req, err := c.newRequest("GET", "/"+bucket, canonicalQuery(query), nil)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	c.sign(req, emptySHA256)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, c.toClientError(res, "ListenBucketNotification")
+	}
+	eventCh := make(chan client.EventInfo)
+	stopCh := make(chan struct{})
+	// the reply never ends on its own, closing it unblocks the reader
+	go func() {
+		select {
+		case <-doneCh:
+		case <-stopCh:
+		}
+		res.Body.Close()
+	}()
+	go func() {
+		defer close(eventCh)
+		defer close(stopCh)
+		send := func(event client.EventInfo) bool {
+			select {
+			case eventCh <- event:
+				return true
+			case <-doneCh:
+				return false
+			}
+		}
+		reader := bufio.NewReader(res.Body)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				var info notificationInfo
+				if jsonErr := json.Unmarshal(line, &info); jsonErr != nil {
+					if !send(client.EventInfo{Err: iodine.New(jsonErr, nil)}) {
+						return
+					}
+				}
+				for _, record := range info.Records {
+					event, ok := c.notificationEvent(bucket, prefix, recursive, since, record)
+					if ok && !send(event) {
+						return
+					}
+				}
+			}
+			if err != nil {
+				select {
+				case <-doneCh:
+				default:
+					if err == io.EOF {
+						err = io.ErrUnexpectedEOF
+					}
+					send(client.EventInfo{Err: iodine.New(err, nil)})
+				}
+				return
+			}
+		}
+	}()
+	return eventCh, nil
+}
+
+// notificationEvent - event of a record, ok is false for records not below prefix, folders below prefix
+// if not recursive, and records older than since
+func (c *s3Client) notificationEvent(bucket, prefix string, recursive bool, since time.Time, record notificationRecord) (event client.EventInfo, ok bool) {
+	key, err := url.QueryUnescape(record.S3.Object.Key)
+	if err != nil {
+		key = record.S3.Object.Key
+	}
+	if !strings.HasPrefix(key, prefix) || (!recursive && strings.Contains(key[len(prefix):], "/")) {
+		return client.EventInfo{}, false
+	}
+	eventTime, err := time.Parse(time.RFC3339, record.EventTime)
+	if err != nil {
+		eventTime = time.Now().UTC()
+	}
+	if eventTime.Before(since) {
+		return client.EventInfo{}, false
+	}
+	objectURL := *c.hostURL
+	objectURL.Path = "/" + bucket + "/" + key
+	event = client.EventInfo{URL: objectURL.String(), Time: eventTime, Type: client.EventRemove}
+	if strings.HasPrefix(record.EventName, "s3:ObjectCreated:") {
+		event.Type = client.EventCreate
+		event.Size = record.S3.Object.Size
+	}
+	return event, true
+}
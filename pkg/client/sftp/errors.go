@@ -0,0 +1,53 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sftp
+
+// MissingUser - no user name in URL nor in host configuration
+type MissingUser struct {
+	Host string
+}
+
+func (e MissingUser) Error() string {
+	return "No user name for SFTP host ‘" + e.Host + "’, use sftp://user@host/path"
+}
+
+// MissingAuth - neither password nor private key is available
+type MissingAuth struct {
+	Host string
+}
+
+func (e MissingAuth) Error() string {
+	return "No password or private key for SFTP host ‘" + e.Host + "’"
+}
+
+// InvalidPrivateKey - private key file could not be parsed
+type InvalidPrivateKey struct {
+	File string
+}
+
+func (e InvalidPrivateKey) Error() string {
+	return "Unable to parse SSH private key ‘" + e.File + "’, passphrase protected keys are not supported"
+}
+
+// InvalidKnownHosts - known hosts file is missing or unreadable, host keys are never accepted blindly
+type InvalidKnownHosts struct {
+	File string
+}
+
+func (e InvalidKnownHosts) Error() string {
+	return "Unable to read SSH known hosts file ‘" + e.File + "’, add the server host key with ssh-keyscan"
+}
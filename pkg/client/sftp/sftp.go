@@ -0,0 +1,495 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sftp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+	pkgsftp "github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Config - SFTP server access, URL is of the form sftp://user@host[:port]/path
+type Config struct {
+	// User is used when URL has none
+	User     string
+	Password string
+	// PrivateKeyFile defaults to ~/.ssh/id_rsa, id_ecdsa or id_ed25519 when no password is set
+	PrivateKeyFile string
+	// KnownHostsFile defaults to ~/.ssh/known_hosts, server host key must be listed in it
+	KnownHostsFile string
+	HostURL        string
+	AppName        string
+	AppVersion     string
+}
+
+type sftpClient struct {
+	hostURL   *client.URL
+	path      string
+	addr      string
+	sshConfig *ssh.ClientConfig
+	// key of the shared connection, user, host and credentials used
+	key string
+}
+
+// connection - sftp session and the ssh connection it runs on
+type connection struct {
+	sftp *pkgsftp.Client
+	ssh  *ssh.Client
+}
+
+// connections are shared by all clients of same user, host and credentials, mc creates a client for every URL
+var connections = struct {
+	sync.Mutex
+	clients map[string]connection
+}{clients: make(map[string]connection)}
+
+// homeDir - current user home directory, empty if unknown
+func homeDir() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.HomeDir
+}
+
+// defaultPrivateKeyFile - first of the well known private key files present in ~/.ssh
+func defaultPrivateKeyFile() string {
+	for _, name := range []string{"id_rsa", "id_ecdsa", "id_ed25519"} {
+		file := filepath.Join(homeDir(), ".ssh", name)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+	}
+	return ""
+}
+
+func loadPrivateKey(file string) (ssh.Signer, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	if err != nil {
+		return nil, iodine.New(InvalidPrivateKey{File: file}, nil)
+	}
+	return signer, nil
+}
+
+// New returns an initialized sftpClient structure, connection is made on first use
+func New(config *Config) (client.Client, error) {
+	u, err := client.Parse(config.HostURL)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	if u.Scheme != "sftp" {
+		return nil, iodine.New(client.InvalidQueryURL{URL: config.HostURL}, nil)
+	}
+	userName := u.User
+	if userName == "" {
+		userName = config.User
+	}
+	if userName == "" {
+		return nil, iodine.New(MissingUser{Host: u.Host}, nil)
+	}
+	addr := u.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "22")
+	}
+
+	var auths []ssh.AuthMethod
+	privateKeyFile := config.PrivateKeyFile
+	if privateKeyFile == "" && config.Password == "" {
+		privateKeyFile = defaultPrivateKeyFile()
+	}
+	if privateKeyFile != "" {
+		signer, err := loadPrivateKey(privateKeyFile)
+		if err != nil {
+			return nil, iodine.New(err, nil)
+		}
+		auths = append(auths, ssh.PublicKeys(signer))
+	}
+	if config.Password != "" {
+		password := config.Password
+		auths = append(auths, ssh.Password(password))
+		// many servers ask for password through keyboard-interactive
+		auths = append(auths, ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+			answers := make([]string, len(questions))
+			for i := range answers {
+				answers[i] = password
+			}
+			return answers, nil
+		}))
+	}
+	if len(auths) == 0 {
+		return nil, iodine.New(MissingAuth{Host: u.Host}, nil)
+	}
+
+	knownHostsFile := config.KnownHostsFile
+	if knownHostsFile == "" {
+		knownHostsFile = filepath.Join(homeDir(), ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, iodine.New(InvalidKnownHosts{File: knownHostsFile}, nil)
+	}
+
+	// aliases with different credentials for the same user must not share a session
+	key := userName + "@" + addr + "?key=" + privateKeyFile
+	if config.Password != "" {
+		sum := sha256.Sum256([]byte(config.Password))
+		key += "&password=" + hex.EncodeToString(sum[:])
+	}
+
+	remotePath := u.Path
+	if remotePath == "" {
+		// relative to login directory
+		remotePath = "."
+	}
+	return &sftpClient{
+		hostURL: u,
+		path:    remotePath,
+		addr:    addr,
+		key:     key,
+		sshConfig: &ssh.ClientConfig{
+			User:            userName,
+			Auth:            auths,
+			HostKeyCallback: hostKeyCallback,
+			ClientVersion:   "SSH-2.0-" + config.AppName + "_" + config.AppVersion,
+		},
+	}, nil
+}
+
+// URL get url
+func (c *sftpClient) URL() *client.URL {
+	return c.hostURL
+}
+
+// connect returns shared sftp session for user, host and credentials, dialing it if needed
+func (c *sftpClient) connect() (*pkgsftp.Client, error) {
+	connections.Lock()
+	defer connections.Unlock()
+	if conn, ok := connections.clients[c.key]; ok {
+		return conn.sftp, nil
+	}
+	sshConn, err := ssh.Dial("tcp", c.addr, c.sshConfig)
+	if err != nil {
+		return nil, iodine.New(err, map[string]string{"Host": c.addr})
+	}
+	clnt, err := pkgsftp.NewClient(sshConn)
+	if err != nil {
+		sshConn.Close()
+		return nil, iodine.New(err, map[string]string{"Host": c.addr})
+	}
+	connections.clients[c.key] = connection{sftp: clnt, ssh: sshConn}
+	// forget the session as soon as the connection drops, next operation dials again
+	go func() {
+		sshConn.Wait()
+		evict(c.key, clnt)
+	}()
+	return clnt, nil
+}
+
+// evict removes a broken session from the cache and closes its connection
+func evict(key string, clnt *pkgsftp.Client) {
+	connections.Lock()
+	defer connections.Unlock()
+	conn, ok := connections.clients[key]
+	if !ok || conn.sftp != clnt {
+		return
+	}
+	delete(connections.clients, key)
+	// the sftp reader shuts the session down itself once the ssh connection is closed
+	conn.ssh.Close()
+}
+
+// check evicts the session used by the failed operation if the connection broke, err is returned as is
+func (c *sftpClient) check(clnt *pkgsftp.Client, err error) error {
+	if err == nil {
+		return nil
+	}
+	// transport errors are not typed by sftp, a session that cannot answer a request is broken
+	if _, probeErr := clnt.Getwd(); probeErr != nil {
+		evict(c.key, clnt)
+	}
+	return err
+}
+
+// fileContent converts remote file info into client Content
+func fileContent(name string, fi os.FileInfo) *client.Content {
+	content := &client.Content{
+		Name: name,
+		Time: fi.ModTime(),
+		Size: fi.Size(),
+		Type: fi.Mode(),
+	}
+	if stat, ok := fi.Sys().(*pkgsftp.FileStat); ok {
+		content.OwnerID = strconv.FormatUint(uint64(stat.UID), 10)
+	}
+	return content
+}
+
+// stat follows symlinks, missing files are reported as client.NotFound
+func (c *sftpClient) stat(clnt *pkgsftp.Client, p string) (os.FileInfo, error) {
+	fi, err := clnt.Stat(p)
+	if os.IsNotExist(err) {
+		return nil, iodine.New(client.NotFound{Path: p}, nil)
+	}
+	if err != nil {
+		return nil, iodine.New(c.check(clnt, err), nil)
+	}
+	return fi, nil
+}
+
+// Stat - get metadata from path
+func (c *sftpClient) Stat() (*client.Content, error) {
+	clnt, err := c.connect()
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	fi, err := c.stat(clnt, c.path)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return fileContent(c.path, fi), nil
+}
+
+// file is a remote file limited to the requested range
+type file struct {
+	io.Reader
+	io.Closer
+}
+
+// GetObject - read a full or part of remote file
+func (c *sftpClient) GetObject(offset, length int64) (io.ReadCloser, int64, error) {
+	clnt, err := c.connect()
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	fi, err := c.stat(clnt, c.path)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	if fi.IsDir() {
+		return nil, length, iodine.New(client.ISFolder{Path: c.path}, nil)
+	}
+	size := fi.Size()
+	if offset < 0 || offset > size || length < 0 || offset+length > size {
+		return nil, length, iodine.New(client.InvalidRange{Offset: offset}, nil)
+	}
+	if length == 0 {
+		length = size - offset
+	}
+	body, err := clnt.Open(c.path)
+	if err != nil {
+		return nil, length, iodine.New(c.check(clnt, err), nil)
+	}
+	if offset > 0 {
+		if _, err := body.Seek(offset, 0); err != nil {
+			body.Close()
+			return nil, length, iodine.New(c.check(clnt, err), nil)
+		}
+	}
+	return file{io.LimitReader(body, length), body}, length, nil
+}
+
+// PutObject - create a remote file, missing parent directories are created
+func (c *sftpClient) PutObject(size int64, data io.Reader) error {
+	clnt, err := c.connect()
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if dir := path.Dir(c.path); dir != "." && dir != "/" {
+		if err := clnt.MkdirAll(dir); err != nil {
+			return iodine.New(c.check(clnt, err), nil)
+		}
+	}
+	body, err := clnt.Create(c.path)
+	if err != nil {
+		return iodine.New(c.check(clnt, err), nil)
+	}
+	// size could be 0 for virtual files, read till EOF for such files
+	if size > 0 {
+		_, err = io.CopyN(body, data, size)
+	} else {
+		_, err = io.Copy(body, data)
+	}
+	if err != nil {
+		body.Close()
+		return iodine.New(err, nil)
+	}
+	return iodine.New(c.check(clnt, body.Close()), nil)
+}
+
+// List - list files and folders
+func (c *sftpClient) List(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel)
+	switch recursive {
+	case true:
+		go c.listRecursiveInRoutine(contentCh)
+	default:
+		go c.listInRoutine(contentCh)
+	}
+	return contentCh
+}
+
+func (c *sftpClient) listInRoutine(contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	clnt, err := c.connect()
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	fi, err := c.stat(clnt, c.path)
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	if !fi.IsDir() {
+		contentCh <- client.ContentOnChannel{Content: fileContent(c.path, fi)}
+		return
+	}
+	files, err := clnt.ReadDir(c.path)
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(c.check(clnt, err), nil)}
+		return
+	}
+	for _, fi := range files {
+		if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
+			target, err := c.stat(clnt, path.Join(c.path, fi.Name()))
+			if err != nil {
+				contentCh <- client.ContentOnChannel{
+					Err: iodine.New(client.ISBrokenSymlink{Path: fi.Name()}, map[string]string{"Target": fi.Name()}),
+				}
+				continue
+			}
+			fi = renamedFileInfo{target, fi.Name()}
+		}
+		if fi.Mode().IsRegular() || fi.Mode().IsDir() {
+			contentCh <- client.ContentOnChannel{Content: fileContent(fi.Name(), fi)}
+		}
+	}
+}
+
+// renamedFileInfo - stat of a symlink target under the name of the symlink
+type renamedFileInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (r renamedFileInfo) Name() string {
+	return r.name
+}
+
+// delimited - names are relative to the last directory of the listed path, same as fs client
+func (c *sftpClient) delimited(p string) string {
+	return strings.TrimPrefix(p, c.path[:strings.LastIndex(c.path, "/")+1])
+}
+
+func (c *sftpClient) listRecursiveInRoutine(contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	clnt, err := c.connect()
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	walker := clnt.Walk(c.path)
+	for root := true; walker.Step(); root = false {
+		if err := walker.Err(); err != nil {
+			contentCh <- client.ContentOnChannel{Err: iodine.New(c.check(clnt, err), map[string]string{"Target": walker.Path()})}
+			continue
+		}
+		// walk also returns the path itself, we don't need it
+		if root {
+			continue
+		}
+		fi := walker.Stat()
+		if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
+			// ignore broken symlinks, symlinked directories are not followed
+			target, err := clnt.Stat(walker.Path())
+			if err != nil {
+				continue
+			}
+			fi = target
+		}
+		if fi.Mode().IsRegular() || fi.Mode().IsDir() {
+			contentCh <- client.ContentOnChannel{Content: fileContent(c.delimited(walker.Path()), fi)}
+		}
+	}
+}
+
+// isValidBucketACL - is acl a valid ACL?
+func isValidBucketACL(acl string) bool {
+	switch acl {
+	case "private", "public-read", "public-read-write", "authenticated-read", "":
+		return true
+	default:
+		return false
+	}
+}
+
+// aclToPerm - convert acl to directory mode, same mapping as fs client
+func aclToPerm(acl string) os.FileMode {
+	switch acl {
+	case "public-read":
+		return os.FileMode(0500)
+	case "public-read-write":
+		return os.FileMode(0777)
+	case "authenticated-read":
+		return os.FileMode(0770)
+	default:
+		return os.FileMode(0700)
+	}
+}
+
+// MakeBucket - create a remote directory
+func (c *sftpClient) MakeBucket() error {
+	clnt, err := c.connect()
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	return iodine.New(c.check(clnt, clnt.MkdirAll(c.path)), nil)
+}
+
+// SetBucketACL - create a remote directory with permissions of the acl
+func (c *sftpClient) SetBucketACL(acl string) error {
+	if !isValidBucketACL(acl) {
+		return iodine.New(client.InvalidACLType{ACL: acl}, nil)
+	}
+	clnt, err := c.connect()
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if err := clnt.MkdirAll(c.path); err != nil {
+		return iodine.New(c.check(clnt, err), nil)
+	}
+	return iodine.New(c.check(clnt, clnt.Chmod(c.path, aclToPerm(acl))), nil)
+}
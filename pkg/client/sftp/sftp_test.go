@@ -0,0 +1,290 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sftp
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+	pkgsftp "github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct {
+	root       string
+	addr       string
+	knownHosts string
+	listener   net.Listener
+}
+
+var _ = Suite(&MySuite{})
+
+func newSigner(c *C) ssh.Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	signer, err := ssh.NewSignerFromKey(key)
+	c.Assert(err, IsNil)
+	return signer
+}
+
+// serveSFTP accepts ssh connections and serves sftp subsystem on local filesystem
+func serveSFTP(listener net.Listener, config *ssh.ServerConfig) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			_, channels, requests, err := ssh.NewServerConn(conn, config)
+			if err != nil {
+				return
+			}
+			go ssh.DiscardRequests(requests)
+			for newChannel := range channels {
+				if newChannel.ChannelType() != "session" {
+					newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+					continue
+				}
+				channel, channelRequests, err := newChannel.Accept()
+				if err != nil {
+					return
+				}
+				go func(in <-chan *ssh.Request) {
+					for req := range in {
+						// payload is a length prefixed subsystem name
+						req.Reply(req.Type == "subsystem" && string(req.Payload[4:]) == "sftp", nil)
+					}
+				}(channelRequests)
+				server, err := pkgsftp.NewServer(channel)
+				if err != nil {
+					return
+				}
+				go server.Serve()
+			}
+		}(conn)
+	}
+}
+
+func (s *MySuite) SetUpSuite(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "sftp-")
+	c.Assert(err, IsNil)
+	s.root = filepath.ToSlash(root)
+
+	hostKey := newSigner(c)
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "mc" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, iodine.New(MissingAuth{Host: "test"}, nil)
+		},
+	}
+	config.AddHostKey(hostKey)
+	s.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	s.addr = s.listener.Addr().String()
+	go serveSFTP(s.listener, config)
+
+	s.knownHosts = filepath.Join(root, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(s.addr)}, hostKey.PublicKey())
+	c.Assert(ioutil.WriteFile(s.knownHosts, []byte(line+"\n"), 0600), IsNil)
+}
+
+func (s *MySuite) TearDownSuite(c *C) {
+	s.listener.Close()
+	os.RemoveAll(s.root)
+}
+
+func (s *MySuite) newClient(c *C, objectPath string) client.Client {
+	config := new(Config)
+	config.HostURL = "sftp://mc@" + s.addr + s.root + objectPath
+	config.Password = "secret"
+	config.KnownHostsFile = s.knownHosts
+	config.AppName = "Minio"
+	config.AppVersion = "test"
+	clnt, err := New(config)
+	c.Assert(err, IsNil)
+	return clnt
+}
+
+func (s *MySuite) TestNewErrors(c *C) {
+	config := new(Config)
+	config.HostURL = "sftp://" + s.addr + "/path"
+	config.Password = "secret"
+	config.KnownHostsFile = s.knownHosts
+	_, err := New(config)
+	c.Assert(iodine.ToError(err), FitsTypeOf, MissingUser{})
+
+	config.User = "mc"
+	config.KnownHostsFile = filepath.Join(s.root, "missing_known_hosts")
+	_, err = New(config)
+	c.Assert(iodine.ToError(err), FitsTypeOf, InvalidKnownHosts{})
+
+	config.HostURL = "http://" + s.addr + "/path"
+	_, err = New(config)
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidQueryURL{})
+}
+
+func (s *MySuite) TestUnknownHostKey(c *C) {
+	knownHosts := filepath.Join(s.root, "other_known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize("127.0.0.2:22")}, newSigner(c).PublicKey())
+	c.Assert(ioutil.WriteFile(knownHosts, []byte(line+"\n"), 0600), IsNil)
+	defer os.Remove(knownHosts)
+
+	config := new(Config)
+	config.HostURL = "sftp://unknown@" + s.addr + s.root
+	config.Password = "secret"
+	config.KnownHostsFile = knownHosts
+	clnt, err := New(config)
+	c.Assert(err, IsNil)
+	_, err = clnt.Stat()
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *MySuite) TestObjectOperations(c *C) {
+	data := []byte("Hello, World")
+	clnt := s.newClient(c, "/bucket/dir/object")
+	c.Assert(clnt.PutObject(int64(len(data)), bytes.NewReader(data)), IsNil)
+
+	content, err := clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(len(data)))
+	c.Assert(content.Type.IsRegular(), Equals, true)
+	if runtime.GOOS != "windows" {
+		c.Assert(content.OwnerID, Equals, strconv.Itoa(os.Getuid()))
+	}
+
+	reader, size, err := clnt.GetObject(0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(len(data)))
+	result, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(reader.Close(), IsNil)
+	c.Assert(result, DeepEquals, data)
+
+	reader, size, err = clnt.GetObject(7, 5)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(5))
+	result, err = ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(reader.Close(), IsNil)
+	c.Assert(string(result), Equals, "World")
+
+	_, _, err = clnt.GetObject(10, 5)
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidRange{})
+
+	_, err = s.newClient(c, "/bucket/missing").Stat()
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.NotFound{})
+}
+
+func (s *MySuite) TestList(c *C) {
+	for _, name := range []string{"/list/a", "/list/dir/b", "/list/dir/c"} {
+		c.Assert(s.newClient(c, name).PutObject(1, bytes.NewReader([]byte("x"))), IsNil)
+	}
+
+	var names []string
+	for content := range s.newClient(c, "/list").List(false) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, content.Content.Name)
+	}
+	sort.Strings(names)
+	c.Assert(names, DeepEquals, []string{"a", "dir"})
+
+	names = nil
+	for content := range s.newClient(c, "/list/").List(true) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, content.Content.Name)
+	}
+	sort.Strings(names)
+	c.Assert(names, DeepEquals, []string{"a", "dir", "dir/b", "dir/c"})
+
+	names = nil
+	for content := range s.newClient(c, "/list/dir").List(true) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, content.Content.Name)
+	}
+	sort.Strings(names)
+	c.Assert(names, DeepEquals, []string{"dir/b", "dir/c"})
+}
+
+func (s *MySuite) TestBucketOperations(c *C) {
+	clnt := s.newClient(c, "/newbucket/nested")
+	c.Assert(clnt.MakeBucket(), IsNil)
+	content, err := clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Type.IsDir(), Equals, true)
+
+	c.Assert(clnt.SetBucketACL("public-read-write"), IsNil)
+	fi, err := os.Stat(filepath.Join(s.root, "newbucket", "nested"))
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode().Perm(), Equals, os.FileMode(0777))
+
+	err = clnt.SetBucketACL("invalid")
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidACLType{})
+}
+
+func (s *MySuite) TestConnectionKey(c *C) {
+	config := new(Config)
+	config.HostURL = "sftp://mc@" + s.addr + s.root
+	config.Password = "secret"
+	config.KnownHostsFile = s.knownHosts
+	first, err := New(config)
+	c.Assert(err, IsNil)
+	second, err := New(config)
+	c.Assert(err, IsNil)
+	c.Assert(first.(*sftpClient).key, Equals, second.(*sftpClient).key)
+
+	config.Password = "other"
+	other, err := New(config)
+	c.Assert(err, IsNil)
+	c.Assert(other.(*sftpClient).key, Not(Equals), first.(*sftpClient).key)
+}
+
+func (s *MySuite) TestDroppedConnection(c *C) {
+	clnt := s.newClient(c, "/dropped")
+	c.Assert(clnt.MakeBucket(), IsNil)
+
+	key := clnt.(*sftpClient).key
+	connections.Lock()
+	dropped := connections.clients[key]
+	connections.Unlock()
+	dropped.ssh.Close()
+
+	// the broken session is evicted either by the failing operation or by the connection watcher
+	clnt.Stat()
+	_, err := clnt.Stat()
+	c.Assert(err, IsNil)
+	connections.Lock()
+	c.Assert(connections.clients[key].sftp, Not(Equals), dropped.sftp)
+	connections.Unlock()
+}
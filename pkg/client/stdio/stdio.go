@@ -0,0 +1,107 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stdio
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Name - pseudo URL of standard input as a source and standard output as a target
+const Name = "-"
+
+type stdioClient struct {
+	hostURL *client.URL
+	stdin   io.Reader
+	stdout  io.Writer
+}
+
+// New returns a client reading from standard input and writing to standard output
+func New() client.Client {
+	return newClient(os.Stdin, os.Stdout)
+}
+
+func newClient(stdin io.Reader, stdout io.Writer) client.Client {
+	return &stdioClient{
+		hostURL: &client.URL{Type: client.Filesystem, Path: Name, Separator: '/'},
+		stdin:   stdin,
+		stdout:  stdout,
+	}
+}
+
+// URL get url
+func (c *stdioClient) URL() *client.URL {
+	return c.hostURL
+}
+
+// Stat - streams are files of unknown size
+func (c *stdioClient) Stat() (*client.Content, error) {
+	content := new(client.Content)
+	content.Name = Name
+	content.Time = time.Now()
+	content.Type = os.FileMode(0644)
+	return content, nil
+}
+
+// List - stream is listed as a single file
+func (c *stdioClient) List(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel, 1)
+	content, err := c.Stat()
+	contentCh <- client.ContentOnChannel{Content: content, Err: err}
+	close(contentCh)
+	return contentCh
+}
+
+// GetObject - read standard input, offset is skipped since streams cannot seek
+func (c *stdioClient) GetObject(offset, length int64) (io.ReadCloser, int64, error) {
+	if offset < 0 || length < 0 {
+		return nil, length, iodine.New(client.InvalidRange{Offset: offset}, nil)
+	}
+	if _, err := io.CopyN(ioutil.Discard, c.stdin, offset); err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	if length > 0 {
+		return ioutil.NopCloser(io.LimitReader(c.stdin, length)), length, nil
+	}
+	// size is unknown, read till EOF
+	return ioutil.NopCloser(c.stdin), 0, nil
+}
+
+// PutObject - write to standard output
+func (c *stdioClient) PutObject(size int64, data io.Reader) error {
+	if size > 0 {
+		_, err := io.CopyN(c.stdout, data, size)
+		return iodine.New(err, nil)
+	}
+	_, err := io.Copy(c.stdout, data)
+	return iodine.New(err, nil)
+}
+
+// MakeBucket - streams have no folders
+func (c *stdioClient) MakeBucket() error {
+	return iodine.New(client.APINotImplemented{API: "MakeBucket"}, nil)
+}
+
+// SetBucketACL - streams have no permissions
+func (c *stdioClient) SetBucketACL(acl string) error {
+	return iodine.New(client.APINotImplemented{API: "SetBucketACL"}, nil)
+}
@@ -0,0 +1,61 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stdio
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+func (s *MySuite) TestStreams(c *C) {
+	var stdout bytes.Buffer
+	clnt := newClient(bytes.NewReader([]byte("Hello, World")), &stdout)
+	c.Assert(clnt.URL().String(), Equals, Name)
+
+	content, err := clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Type.IsRegular(), Equals, true)
+	c.Assert(content.Size, Equals, int64(0))
+
+	reader, size, err := clnt.GetObject(0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(0))
+	data, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "Hello, World")
+
+	reader, size, err = newClient(bytes.NewReader([]byte("Hello, World")), nil).GetObject(7, 5)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(5))
+	data, err = ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "World")
+
+	c.Assert(clnt.PutObject(0, bytes.NewReader([]byte("Hello"))), IsNil)
+	c.Assert(clnt.PutObject(3, bytes.NewReader([]byte(", World"))), IsNil)
+	c.Assert(stdout.String(), Equals, "Hello, W")
+	c.Assert(clnt.MakeBucket(), Not(IsNil))
+}
@@ -29,6 +29,7 @@ type URL struct {
 	Type      URLType
 	Scheme    string
 	Host      string
+	User      string // only for schemes of remote filesystems, scheme://user@host
 	Path      string
 	Separator rune
 }
@@ -42,12 +43,13 @@ const (
 	Filesystem        // POSIX compatible file systems
 )
 
+// validScheme - numbers are ignored in scheme
+var validScheme = regexp.MustCompile("^[a-zA-Z]+$")
+
 // Maybe rawurl is of the form scheme:path. (Scheme must be [a-zA-Z][a-zA-Z0-9+-.]*)
 // If so, return scheme, path; else return "", rawurl.
 func getScheme(rawurl string) (scheme, path string) {
 	scheme, uri := splitSpecial(rawurl, ":", true)
-	// ignore numbers in scheme
-	validScheme := regexp.MustCompile("^[a-zA-Z]+$")
 	if uri != "" {
 		if validScheme.MatchString(scheme) {
 			return scheme, uri
@@ -73,53 +75,146 @@ func splitSpecial(s string, delimiter string, cutdelimiter bool) (string, string
 	return s[0:i], s[i:]
 }
 
-// getHost - extract host from authority string, we do not support ftp style username@ yet
-func getHost(authority string) (host string) {
+// getHost - extract host and username from authority string, password is not accepted in userinfo
+func getHost(authority string) (user, host string) {
 	i := strings.LastIndex(authority, "@")
 	if i >= 0 {
-		// TODO support, username@password style userinfo, useful for ftp support
-		return
+		user = authority[:i]
+		if strings.Contains(user, ":") {
+			return "", ""
+		}
+		return user, authority[i+1:]
+	}
+	return "", authority
+}
+
+// isWindowsPath - drive letter paths like C:\Users and C:file, UNC and extended-length paths like
+// \\server\share and \\?\C:\Users. Aliases are at least two letters long, so a drive is never an alias
+func isWindowsPath(urlStr string) bool {
+	if len(urlStr) >= 2 && urlStr[1] == ':' && validScheme.MatchString(urlStr[:1]) {
+		return true
 	}
-	return authority
+	return strings.HasPrefix(urlStr, `\\`)
 }
 
 // Parse url
 func Parse(urlStr string) (*URL, error) {
+	if isWindowsPath(urlStr) {
+		// drive colon is not a scheme and '?' of extended-length paths is not a query
+		return &URL{
+			Type:      Filesystem,
+			Path:      filepath.FromSlash(urlStr),
+			Separator: filepath.Separator,
+		}, nil
+	}
 	scheme, rest := getScheme(urlStr)
+	if scheme == "file" {
+		return parseFileURL(rest), nil
+	}
 	rest, _ = splitSpecial(rest, "?", true)
+	if scheme == "tar" && strings.HasPrefix(rest, "//") {
+		// archives are local files, path after '//' is the archive path followed by member path
+		return &URL{
+			Scheme:    scheme,
+			Type:      Object,
+			Path:      rest[2:],
+			Separator: '/',
+		}, nil
+	}
 	if strings.HasPrefix(rest, "//") {
 		// if rest has '//' prefix, skip them
 		authority, rest := splitSpecial(rest[2:], "/", false)
-		host := getHost(authority)
-		if host != "" && (scheme == "http" || scheme == "https") {
+		user, host := getHost(authority)
+		if host != "" && user == "" && (scheme == "http" || scheme == "https") {
+			return &URL{
+				Scheme:    scheme,
+				Type:      Object,
+				Host:      host,
+				Path:      rest,
+				Separator: '/',
+			}, nil
+		}
+		if host != "" && isFilesystemScheme(scheme) {
 			return &URL{
 				Scheme:    scheme,
 				Type:      Object,
 				Host:      host,
+				User:      user,
 				Path:      rest,
 				Separator: '/',
 			}, nil
 		}
 	}
+	// anything else is a local path as typed, like automount paths host:/export or a folder named https:
 	return &URL{
 		Type:      Filesystem,
-		Path:      rest,
+		Path:      filepath.FromSlash(urlStr),
 		Separator: filepath.Separator,
 	}, nil
 }
 
+// parseFileURL - file:///path, file://localhost/path and file:path address local paths taken literally,
+// without query, host or alias. Other hosts are kept as part of the path, file://server/share is //server/share
+func parseFileURL(rest string) *URL {
+	path := rest
+	if strings.HasPrefix(rest, "//") {
+		authority, absPath := splitSpecial(rest[2:], "/", false)
+		if authority == "" || authority == "localhost" {
+			path = absPath
+		}
+	}
+	// file:///C:/Users
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' && validScheme.MatchString(path[1:2]) {
+		path = path[1:]
+	}
+	return &URL{
+		Type:      Filesystem,
+		Path:      filepath.FromSlash(path),
+		Separator: filepath.Separator,
+	}
+}
+
+// isFilesystemScheme - remote filesystems and registered backends accept a user name in URL
+func isFilesystemScheme(scheme string) bool {
+	switch scheme {
+	case "sftp", "ftp", "ftps", "ftpes", "webdav", "webdavs", "hdfs", "webhdfs", "swebhdfs":
+		return true
+	}
+	_, ok := Lookup(scheme)
+	return ok
+}
+
+// isBuiltinScheme - schemes with a backend in this package tree, they cannot be registered
+func isBuiltinScheme(scheme string) bool {
+	switch scheme {
+	case "file", "http", "https", "tar", "sftp", "ftp", "ftps", "ftpes", "webdav", "webdavs", "hdfs", "webhdfs", "swebhdfs":
+		return true
+	}
+	return false
+}
+
 // String convert URL into its canonical form
 func (u *URL) String() string {
 	var buf bytes.Buffer
-	// if fileystem no translation needed, return as is
+	// if fileystem no translation needed, return as is unless it would be taken for a URL
 	if u.Type == Filesystem {
-		return u.Path
+		if p, _ := Parse(u.Path); p.Type == Filesystem && p.Path == u.Path {
+			return u.Path
+		}
+		if strings.HasPrefix(u.Path, "/") {
+			return "file://" + u.Path
+		}
+		return "file:" + u.Path
 	}
 	// if Object convert from any non standard paths to a supported URL path style
 	if u.Type == Object {
 		buf.WriteString(u.Scheme)
 		buf.WriteByte(':')
 		buf.WriteString("//")
+		if u.User != "" {
+			buf.WriteString(u.User)
+			buf.WriteByte('@')
+		}
 		if h := u.Host; h != "" {
 			buf.WriteString(h)
 		}
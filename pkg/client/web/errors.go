@@ -0,0 +1,42 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// ErrorResponse - unexpected HTTP status returned by web server
+type ErrorResponse struct {
+	Code   int
+	Method string
+	URL    string
+}
+
+func (e ErrorResponse) Error() string {
+	return "HTTP " + e.Method + " ‘" + e.URL + "’ failed with " + strconv.Itoa(e.Code) + " " + http.StatusText(e.Code)
+}
+
+// ReadOnly - web URLs can only be read from
+type ReadOnly struct {
+	URL string
+}
+
+func (e ReadOnly) Error() string {
+	return "‘" + e.URL + "’ is a read-only web URL"
+}
@@ -0,0 +1,212 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Config - plain HTTP or HTTPS server serving files, URL is used as is
+type Config struct {
+	HostURL     string
+	AppName     string
+	AppVersion  string
+	AppComments []string
+	Debug       bool
+}
+
+type webClient struct {
+	hostURL    *client.URL
+	userAgent  string
+	httpClient *http.Client
+}
+
+// New returns an initialized webClient structure. if debug use a internal trace transport
+func New(config *Config) (client.Client, error) {
+	u, err := client.Parse(config.HostURL)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, iodine.New(client.InvalidQueryURL{URL: config.HostURL}, nil)
+	}
+	var transport http.RoundTripper
+	switch {
+	case config.Debug == true:
+		transport = s3.GetNewTraceTransport(s3.NewTrace(), http.DefaultTransport)
+	default:
+		transport = s3.GetNewLogTransport(http.DefaultTransport)
+	}
+	userAgent := config.AppName + "/" + config.AppVersion
+	if len(config.AppComments) > 0 {
+		userAgent = userAgent + " (" + strings.Join(config.AppComments, "; ") + ")"
+	}
+	return &webClient{
+		hostURL:    u,
+		userAgent:  userAgent,
+		httpClient: &http.Client{Transport: transport},
+	}, nil
+}
+
+// URL get url
+func (c *webClient) URL() *client.URL {
+	return c.hostURL
+}
+
+// do executes a request, expected status codes are passed back, others turned into ErrorResponse
+func (c *webClient) do(method string, header http.Header, expect ...int) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.hostURL.String(), nil)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	for _, code := range expect {
+		if res.StatusCode == code {
+			return res, nil
+		}
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, iodine.New(client.NotFound{Path: c.hostURL.String()}, nil)
+	}
+	return nil, iodine.New(ErrorResponse{Code: res.StatusCode, Method: method, URL: c.hostURL.String()}, nil)
+}
+
+// toContent - content from response headers, size is 0 if server does not send a length
+func (c *webClient) toContent(res *http.Response) *client.Content {
+	content := new(client.Content)
+	content.Name = c.hostURL.Path
+	content.Type = os.FileMode(0644)
+	if res.ContentLength > 0 {
+		content.Size = res.ContentLength
+	}
+	content.Time = time.Now().UTC()
+	if lastModified, err := http.ParseTime(res.Header.Get("Last-Modified")); err == nil {
+		content.Time = lastModified
+	}
+	content.ETag = strings.Trim(res.Header.Get("ETag"), "\"")
+	return content
+}
+
+// Stat - get metadata with HEAD, servers which do not allow HEAD are asked with GET
+func (c *webClient) Stat() (*client.Content, error) {
+	res, err := c.do("HEAD", nil, http.StatusOK, http.StatusMethodNotAllowed, http.StatusNotImplemented)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		res, err = c.do("GET", nil, http.StatusOK)
+		if err != nil {
+			return nil, iodine.New(err, nil)
+		}
+		res.Body.Close()
+	}
+	return c.toContent(res), nil
+}
+
+// List - a web URL is a single file
+func (c *webClient) List(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel, 1)
+	content, err := c.Stat()
+	contentCh <- client.ContentOnChannel{Content: content, Err: err}
+	close(contentCh)
+	return contentCh
+}
+
+// rangeReader - body of a GET, offset is skipped when server ignores Range
+type rangeReader struct {
+	io.Reader
+	io.Closer
+}
+
+// GetObject - read a full or part of file, ranged GET is used where server supports it
+func (c *webClient) GetObject(offset, length int64) (io.ReadCloser, int64, error) {
+	if offset < 0 || length < 0 {
+		return nil, length, iodine.New(client.InvalidRange{Offset: offset}, nil)
+	}
+	header := http.Header{}
+	if offset > 0 || length > 0 {
+		rangeSpec := "bytes=" + strconv.FormatInt(offset, 10) + "-"
+		if length > 0 {
+			rangeSpec += strconv.FormatInt(offset+length-1, 10)
+		}
+		header.Set("Range", rangeSpec)
+	}
+	res, err := c.do("GET", header, http.StatusOK, http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	if res.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		res.Body.Close()
+		return nil, length, iodine.New(client.InvalidRange{Offset: offset}, nil)
+	}
+	size := res.ContentLength
+	if res.StatusCode == http.StatusOK && offset > 0 {
+		if _, err := io.CopyN(ioutil.Discard, res.Body, offset); err != nil {
+			res.Body.Close()
+			return nil, length, iodine.New(client.InvalidRange{Offset: offset}, nil)
+		}
+		if size > 0 {
+			size -= offset
+		}
+	}
+	if length > 0 {
+		if size >= 0 && size < length {
+			res.Body.Close()
+			return nil, length, iodine.New(client.InvalidRange{Offset: offset}, nil)
+		}
+		return rangeReader{io.LimitReader(res.Body, length), res.Body}, length, nil
+	}
+	if size < 0 { // unknown length, read till EOF
+		size = 0
+	}
+	return res.Body, size, nil
+}
+
+// PutObject - not allowed, web URLs are read-only
+func (c *webClient) PutObject(size int64, data io.Reader) error {
+	return iodine.New(ReadOnly{URL: c.hostURL.String()}, nil)
+}
+
+// MakeBucket - not allowed, web URLs are read-only
+func (c *webClient) MakeBucket() error {
+	return iodine.New(ReadOnly{URL: c.hostURL.String()}, nil)
+}
+
+// SetBucketACL - not allowed, web URLs are read-only
+func (c *webClient) SetBucketACL(acl string) error {
+	return iodine.New(ReadOnly{URL: c.hostURL.String()}, nil)
+}
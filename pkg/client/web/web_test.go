@@ -0,0 +1,127 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+// plainHandler - serves a file ignoring Range and refusing HEAD, like minimal web servers do
+type plainHandler struct {
+	data []byte
+}
+
+func (h plainHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Write(h.data)
+}
+
+func newClient(c *C, urlStr string) client.Client {
+	clnt, err := New(&Config{HostURL: urlStr})
+	c.Assert(err, IsNil)
+	return clnt
+}
+
+func readAll(c *C, clnt client.Client, offset, length int64) string {
+	reader, _, err := clnt.GetObject(offset, length)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(reader.Close(), IsNil)
+	return string(data)
+}
+
+func (s *MySuite) TestNew(c *C) {
+	_, err := New(&Config{HostURL: "ftp://example.com/file.iso"})
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidQueryURL{})
+	_, err = New(&Config{HostURL: "https://example.com/file.iso"})
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestObjectOperations(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "web-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	data := []byte("Hello, World")
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "file.iso"), data, 0644), IsNil)
+
+	server := httptest.NewServer(http.FileServer(http.Dir(root)))
+	defer server.Close()
+
+	clnt := newClient(c, server.URL+"/file.iso")
+	content, err := clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Name, Equals, "/file.iso")
+	c.Assert(content.Size, Equals, int64(len(data)))
+	c.Assert(content.Type.IsRegular(), Equals, true)
+
+	for content := range clnt.List(true) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.Name, Equals, "/file.iso")
+	}
+
+	reader, size, err := clnt.GetObject(0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(len(data)))
+	reader.Close()
+	c.Assert(readAll(c, clnt, 0, 0), Equals, "Hello, World")
+	c.Assert(readAll(c, clnt, 7, 5), Equals, "World")
+	c.Assert(readAll(c, clnt, 7, 0), Equals, "World")
+
+	_, _, err = clnt.GetObject(7, 50)
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidRange{})
+
+	_, err = newClient(c, server.URL+"/missing.iso").Stat()
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.NotFound{})
+
+	err = clnt.PutObject(int64(len(data)), bytes.NewReader(data))
+	c.Assert(iodine.ToError(err), FitsTypeOf, ReadOnly{})
+	c.Assert(iodine.ToError(clnt.MakeBucket()), FitsTypeOf, ReadOnly{})
+}
+
+func (s *MySuite) TestWithoutRangeSupport(c *C) {
+	server := httptest.NewServer(plainHandler{data: []byte("Hello, World")})
+	defer server.Close()
+
+	clnt := newClient(c, server.URL+"/file.iso")
+	content, err := clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(12))
+
+	c.Assert(readAll(c, clnt, 7, 5), Equals, "World")
+	c.Assert(readAll(c, clnt, 0, 5), Equals, "Hello")
+	c.Assert(readAll(c, clnt, 7, 0), Equals, "World")
+}
@@ -0,0 +1,42 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webdav
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// ErrorResponse - unexpected HTTP status returned by WebDAV server
+type ErrorResponse struct {
+	Code   int
+	Method string
+	Path   string
+}
+
+func (e ErrorResponse) Error() string {
+	return "WebDAV " + e.Method + " ‘" + e.Path + "’ failed with " + strconv.Itoa(e.Code) + " " + http.StatusText(e.Code)
+}
+
+// InvalidResponse - multistatus body could not be parsed
+type InvalidResponse struct {
+	Path string
+}
+
+func (e InvalidResponse) Error() string {
+	return "Invalid PROPFIND response for ‘" + e.Path + "’"
+}
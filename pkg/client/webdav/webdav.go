@@ -0,0 +1,447 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webdav
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Config - WebDAV share access, URL is of the form webdav://user@host[:port]/path
+// over HTTP or webdavs:// over HTTPS. Nextcloud and ownCloud shares live under
+// /remote.php/webdav/
+type Config struct {
+	// User is used when URL has none, requests are unauthenticated if both are empty
+	User        string
+	Password    string
+	HostURL     string
+	AppName     string
+	AppVersion  string
+	AppComments []string
+	Debug       bool
+}
+
+type webdavClient struct {
+	hostURL    *client.URL
+	scheme     string
+	host       string
+	user       string
+	password   string
+	userAgent  string
+	httpClient *http.Client
+}
+
+// New returns an initialized webdavClient structure. if debug use a internal trace transport
+func New(config *Config) (client.Client, error) {
+	u, err := client.Parse(config.HostURL)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	var scheme string
+	switch u.Scheme {
+	case "webdav":
+		scheme = "http"
+	case "webdavs":
+		scheme = "https"
+	default:
+		return nil, iodine.New(client.InvalidQueryURL{URL: config.HostURL}, nil)
+	}
+	var transport http.RoundTripper
+	switch {
+	case config.Debug == true:
+		transport = s3.GetNewTraceTransport(s3.NewTrace(), http.DefaultTransport)
+	default:
+		transport = s3.GetNewLogTransport(http.DefaultTransport)
+	}
+	user := u.User
+	if user == "" {
+		user = config.User
+	}
+	userAgent := config.AppName + "/" + config.AppVersion
+	if len(config.AppComments) > 0 {
+		userAgent = userAgent + " (" + strings.Join(config.AppComments, "; ") + ")"
+	}
+	return &webdavClient{
+		hostURL:    u,
+		scheme:     scheme,
+		host:       u.Host,
+		user:       user,
+		password:   config.Password,
+		userAgent:  userAgent,
+		httpClient: &http.Client{Transport: transport},
+	}, nil
+}
+
+// URL get url
+func (c *webdavClient) URL() *client.URL {
+	return c.hostURL
+}
+
+// remotePath - path of URL, root of the server if URL has none
+func (c *webdavClient) remotePath() string {
+	return path.Clean("/" + c.hostURL.Path)
+}
+
+// do executes a request, expected status codes are passed back, others turned into ErrorResponse
+func (c *webdavClient) do(method, p string, header http.Header, body io.Reader, expect ...int) (*http.Response, error) {
+	u := &url.URL{Scheme: c.scheme, Host: c.host, Path: p}
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	for _, code := range expect {
+		if res.StatusCode == code {
+			return res, nil
+		}
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, iodine.New(client.NotFound{Path: p}, nil)
+	}
+	return nil, iodine.New(ErrorResponse{Code: res.StatusCode, Method: method, Path: p}, nil)
+}
+
+// propfindBody - properties needed for client.Content
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:"><d:prop><d:resourcetype/><d:getcontentlength/><d:getlastmodified/><d:getetag/></d:prop></d:propfind>`
+
+// multistatus - subset of RFC 4918 PROPFIND response
+type multistatus struct {
+	Responses []struct {
+		Href      string `xml:"DAV: href"`
+		Propstats []struct {
+			Prop struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"DAV: collection"`
+				} `xml:"DAV: resourcetype"`
+				ContentLength string `xml:"DAV: getcontentlength"`
+				LastModified  string `xml:"DAV: getlastmodified"`
+				ETag          string `xml:"DAV: getetag"`
+			} `xml:"DAV: prop"`
+			Status string `xml:"DAV: status"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// resource - a file or collection of a PROPFIND response, path is unescaped without trailing slash
+type resource struct {
+	path    string
+	content client.Content
+}
+
+// propfind - depth 0 returns p itself, depth 1 also its members
+func (c *webdavClient) propfind(p string, depth int) ([]resource, error) {
+	header := http.Header{}
+	header.Set("Depth", strconv.Itoa(depth))
+	header.Set("Content-Type", "application/xml; charset=utf-8")
+	res, err := c.do("PROPFIND", p, header, strings.NewReader(propfindBody), 207)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	var ms multistatus
+	if err := xml.NewDecoder(res.Body).Decode(&ms); err != nil {
+		return nil, iodine.New(InvalidResponse{Path: p}, map[string]string{"Error": err.Error()})
+	}
+	var resources []resource
+	for _, response := range ms.Responses {
+		// href is either an absolute path or a full URL
+		href, err := url.Parse(response.Href)
+		if err != nil {
+			return nil, iodine.New(InvalidResponse{Path: p}, map[string]string{"Href": response.Href})
+		}
+		r := resource{path: strings.TrimSuffix(href.Path, "/")}
+		if r.path == "" {
+			r.path = "/"
+		}
+		r.content.Type = os.FileMode(0644)
+		for _, propstat := range response.Propstats {
+			if !strings.Contains(propstat.Status, " 200") {
+				continue
+			}
+			prop := propstat.Prop
+			if prop.ResourceType.Collection != nil {
+				r.content.Type = os.ModeDir | 0755
+			}
+			if prop.ContentLength != "" {
+				r.content.Size, _ = strconv.ParseInt(prop.ContentLength, 10, 64)
+			}
+			if prop.LastModified != "" {
+				r.content.Time, _ = http.ParseTime(prop.LastModified)
+			}
+			if prop.ETag != "" {
+				r.content.ETag = strings.Trim(prop.ETag, "\"")
+			}
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+// stat - properties of p itself
+func (c *webdavClient) stat(p string) (*client.Content, error) {
+	resources, err := c.propfind(p, 0)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	if len(resources) == 0 {
+		return nil, iodine.New(InvalidResponse{Path: p}, nil)
+	}
+	content := resources[0].content
+	content.Name = p
+	return &content, nil
+}
+
+// Stat - get metadata from path
+func (c *webdavClient) Stat() (*client.Content, error) {
+	content, err := c.stat(c.remotePath())
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return content, nil
+}
+
+// rangeReader - body of a GET, offset is skipped when server ignores Range
+type rangeReader struct {
+	io.Reader
+	io.Closer
+}
+
+// GetObject - read a full or part of remote file
+func (c *webdavClient) GetObject(offset, length int64) (io.ReadCloser, int64, error) {
+	p := c.remotePath()
+	content, err := c.stat(p)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	if content.Type.IsDir() {
+		return nil, length, iodine.New(client.ISFolder{Path: p}, nil)
+	}
+	if offset < 0 || offset > content.Size || length < 0 || offset+length > content.Size {
+		return nil, length, iodine.New(client.InvalidRange{Offset: offset}, nil)
+	}
+	if length == 0 {
+		length = content.Size - offset
+	}
+	header := http.Header{}
+	if offset > 0 || length < content.Size {
+		header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+length-1, 10))
+	}
+	res, err := c.do("GET", p, header, nil, http.StatusOK, http.StatusPartialContent)
+	if err != nil {
+		return nil, length, iodine.New(err, nil)
+	}
+	if res.StatusCode == http.StatusOK && offset > 0 {
+		if _, err := io.CopyN(ioutil.Discard, res.Body, offset); err != nil {
+			res.Body.Close()
+			return nil, length, iodine.New(err, nil)
+		}
+	}
+	return rangeReader{io.LimitReader(res.Body, length), res.Body}, length, nil
+}
+
+// mkcol creates collection p, missing parents are created first. MKCOL fails with 409 when
+// parent is missing and with 405 when p exists, ancestors above the share are never touched
+func (c *webdavClient) mkcol(p string) error {
+	res, err := c.do("MKCOL", p, nil, nil, http.StatusCreated, http.StatusMethodNotAllowed, http.StatusConflict)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusConflict {
+		return nil
+	}
+	parent := path.Dir(p)
+	if parent == p {
+		return iodine.New(ErrorResponse{Code: res.StatusCode, Method: "MKCOL", Path: p}, nil)
+	}
+	if err := c.mkcol(parent); err != nil {
+		return iodine.New(err, nil)
+	}
+	res, err = c.do("MKCOL", p, nil, nil, http.StatusCreated, http.StatusMethodNotAllowed)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	res.Body.Close()
+	return nil
+}
+
+// PutObject - create a remote file, missing parent collections are created
+func (c *webdavClient) PutObject(size int64, data io.Reader) error {
+	return c.PutObjectWithMetadata(size, data, nil)
+}
+
+// PutObjectWithMetadata - put object sending metadata as request headers, servers typically keep only Content-Type
+func (c *webdavClient) PutObjectWithMetadata(size int64, data io.Reader, metadata map[string]string) error {
+	p := c.remotePath()
+	if dir := path.Dir(p); dir != "/" {
+		if err := c.mkcol(dir); err != nil {
+			return iodine.New(err, nil)
+		}
+	}
+	u := &url.URL{Scheme: c.scheme, Host: c.host, Path: p}
+	req, err := http.NewRequest("PUT", u.String(), data)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	// size could be 0 for virtual files, send chunked till EOF for such files
+	if size > 0 {
+		req.ContentLength = size
+		req.Body = ioutil.NopCloser(io.LimitReader(data, size))
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	for key, value := range metadata {
+		req.Header.Set(key, value)
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return iodine.New(ErrorResponse{Code: res.StatusCode, Method: "PUT", Path: p}, nil)
+	}
+	return nil
+}
+
+// List - list files and folders
+func (c *webdavClient) List(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel)
+	switch recursive {
+	case true:
+		go c.listRecursiveInRoutine(contentCh)
+	default:
+		go c.listInRoutine(contentCh)
+	}
+	return contentCh
+}
+
+// members - resources inside collection p, the collection itself is left out
+func (c *webdavClient) members(p string) ([]resource, error) {
+	resources, err := c.propfind(p, 1)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	var members []resource
+	for _, r := range resources {
+		if r.path != p {
+			members = append(members, r)
+		}
+	}
+	return members, nil
+}
+
+func (c *webdavClient) listInRoutine(contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	p := c.remotePath()
+	content, err := c.stat(p)
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	if !content.Type.IsDir() {
+		contentCh <- client.ContentOnChannel{Content: content}
+		return
+	}
+	members, err := c.members(p)
+	if err != nil {
+		contentCh <- client.ContentOnChannel{Err: iodine.New(err, nil)}
+		return
+	}
+	for _, r := range members {
+		content := r.content
+		content.Name = path.Base(r.path)
+		contentCh <- client.ContentOnChannel{Content: &content}
+	}
+}
+
+// listRecursiveInRoutine - names are relative to the last folder of the listed path, same as fs client.
+// Depth infinity is disabled on most servers, collections are walked one level at a time
+func (c *webdavClient) listRecursiveInRoutine(contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	root := c.remotePath()
+	stripPrefix := root[:strings.LastIndex(root, "/")+1]
+	if strings.HasSuffix(c.hostURL.Path, "/") && root != "/" {
+		stripPrefix = root + "/"
+	}
+	dirs := []string{root}
+	for len(dirs) > 0 {
+		dir := dirs[len(dirs)-1]
+		dirs = dirs[:len(dirs)-1]
+		members, err := c.members(dir)
+		if err != nil {
+			contentCh <- client.ContentOnChannel{Err: iodine.New(err, map[string]string{"Target": dir})}
+			continue
+		}
+		for _, r := range members {
+			if r.content.Type.IsDir() {
+				dirs = append(dirs, r.path)
+			}
+			content := r.content
+			content.Name = strings.TrimPrefix(r.path, stripPrefix)
+			contentCh <- client.ContentOnChannel{Content: &content}
+		}
+	}
+}
+
+// MakeBucket - create a remote collection
+func (c *webdavClient) MakeBucket() error {
+	p := c.remotePath()
+	if err := c.mkcol(p); err != nil {
+		return iodine.New(err, nil)
+	}
+	content, err := c.stat(p)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if !content.Type.IsDir() {
+		return iodine.New(client.NotFolder{Path: p}, nil)
+	}
+	return nil
+}
+
+// SetBucketACL - WebDAV has no portable permissions, sharing is configured on the server
+func (c *webdavClient) SetBucketACL(acl string) error {
+	return iodine.New(client.APINotImplemented{API: "SetBucketACL"}, nil)
+}
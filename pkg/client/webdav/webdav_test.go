@@ -0,0 +1,271 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webdav
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+// davHandler - minimal WebDAV server on a local folder, shares are served under /remote.php/webdav
+type davHandler struct {
+	root string
+}
+
+func (h davHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if user, password, ok := req.BasicAuth(); !ok || user != "mc" || password != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	p := path.Clean(req.URL.Path)
+	if p != "/remote.php/webdav" && !strings.HasPrefix(p, "/remote.php/webdav/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	name := filepath.Join(h.root, filepath.FromSlash(strings.TrimPrefix(p, "/remote.php/webdav")))
+	switch req.Method {
+	case "PROPFIND":
+		fi, err := os.Stat(name)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var buf bytes.Buffer
+		buf.WriteString(`<?xml version="1.0" encoding="utf-8"?><d:multistatus xmlns:d="DAV:">`)
+		writeResponse(&buf, p, fi)
+		if fi.IsDir() && req.Header.Get("Depth") == "1" {
+			infos, _ := ioutil.ReadDir(name)
+			for _, info := range infos {
+				writeResponse(&buf, path.Join(p, info.Name()), info)
+			}
+		}
+		buf.WriteString(`</d:multistatus>`)
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(207)
+		w.Write(buf.Bytes())
+	case "GET":
+		file, err := os.Open(name)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		defer file.Close()
+		fi, _ := file.Stat()
+		http.ServeContent(w, req, name, fi.ModTime(), file)
+	case "PUT":
+		file, err := os.Create(name)
+		if err != nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		defer file.Close()
+		io.Copy(file, req.Body)
+		w.WriteHeader(http.StatusCreated)
+	case "MKCOL":
+		if _, err := os.Stat(name); err == nil {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if err := os.Mkdir(name, 0700); err != nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func writeResponse(buf *bytes.Buffer, p string, fi os.FileInfo) {
+	href := (&url.URL{Path: p}).EscapedPath()
+	resourceType := ""
+	if fi.IsDir() {
+		href += "/"
+		resourceType = "<d:collection/>"
+	}
+	fmt.Fprintf(buf, `<d:response><d:href>%s</d:href><d:propstat><d:prop>`, href)
+	fmt.Fprintf(buf, `<d:resourcetype>%s</d:resourcetype>`, resourceType)
+	if !fi.IsDir() {
+		fmt.Fprintf(buf, `<d:getcontentlength>%d</d:getcontentlength><d:getetag>"%x"</d:getetag>`, fi.Size(), fi.ModTime().UnixNano())
+	}
+	fmt.Fprintf(buf, `<d:getlastmodified>%s</d:getlastmodified>`, fi.ModTime().UTC().Format(http.TimeFormat))
+	buf.WriteString(`</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`)
+}
+
+func newServer(c *C) (*httptest.Server, string) {
+	root, err := ioutil.TempDir(os.TempDir(), "webdav-")
+	c.Assert(err, IsNil)
+	return httptest.NewServer(davHandler{root: root}), root
+}
+
+func newClient(c *C, server *httptest.Server, p string) client.Client {
+	config := new(Config)
+	config.User = "mc"
+	config.Password = "secret"
+	config.HostURL = strings.Replace(server.URL, "http://", "webdav://", 1) + "/remote.php/webdav" + p
+	clnt, err := New(config)
+	c.Assert(err, IsNil)
+	return clnt
+}
+
+func (s *MySuite) TestNew(c *C) {
+	_, err := New(&Config{HostURL: "http://example.com/path"})
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidQueryURL{})
+
+	clnt, err := New(&Config{HostURL: "webdavs://user@cloud.example.com/remote.php/webdav/"})
+	c.Assert(err, IsNil)
+	c.Assert(clnt.(*webdavClient).scheme, Equals, "https")
+	c.Assert(clnt.(*webdavClient).user, Equals, "user")
+}
+
+func (s *MySuite) TestObjectOperations(c *C) {
+	server, root := newServer(c)
+	defer os.RemoveAll(root)
+	defer server.Close()
+
+	data := []byte("Hello, World")
+	clnt := newClient(c, server, "/bucket/my dir/object")
+	c.Assert(clnt.PutObject(int64(len(data)), bytes.NewReader(data)), IsNil)
+	result, err := ioutil.ReadFile(filepath.Join(root, "bucket", "my dir", "object"))
+	c.Assert(err, IsNil)
+	c.Assert(result, DeepEquals, data)
+
+	content, err := clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Name, Equals, "/remote.php/webdav/bucket/my dir/object")
+	c.Assert(content.Size, Equals, int64(len(data)))
+	c.Assert(content.Type.IsRegular(), Equals, true)
+	c.Assert(content.ETag, Not(Equals), "")
+
+	reader, size, err := clnt.GetObject(0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(len(data)))
+	result, err = ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(reader.Close(), IsNil)
+	c.Assert(result, DeepEquals, data)
+
+	reader, size, err = clnt.GetObject(7, 5)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(5))
+	result, err = ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(reader.Close(), IsNil)
+	c.Assert(string(result), Equals, "World")
+
+	_, _, err = clnt.GetObject(7, 10)
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.InvalidRange{})
+
+	_, err = newClient(c, server, "/bucket/missing").Stat()
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.NotFound{})
+
+	clnt = newClient(c, server, "/bucket/my dir")
+	content, err = clnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Type.IsDir(), Equals, true)
+	_, _, err = clnt.GetObject(0, 0)
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.ISFolder{})
+
+	config := &Config{HostURL: strings.Replace(server.URL, "http://", "webdav://", 1) + "/remote.php/webdav/bucket", User: "mc", Password: "wrong"}
+	clnt, err = New(config)
+	c.Assert(err, IsNil)
+	_, err = clnt.Stat()
+	c.Assert(iodine.ToError(err), FitsTypeOf, ErrorResponse{})
+}
+
+func (s *MySuite) TestList(c *C) {
+	server, root := newServer(c)
+	defer os.RemoveAll(root)
+	defer server.Close()
+
+	for _, name := range []string{"/list/a", "/list/dir/b", "/list/dir/c"} {
+		c.Assert(newClient(c, server, name).PutObject(1, bytes.NewReader([]byte("x"))), IsNil)
+	}
+
+	var names []string
+	for content := range newClient(c, server, "/list").List(false) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, content.Content.Name)
+	}
+	sort.Strings(names)
+	c.Assert(names, DeepEquals, []string{"a", "dir"})
+
+	names = nil
+	for content := range newClient(c, server, "/list/").List(true) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, content.Content.Name)
+	}
+	sort.Strings(names)
+	c.Assert(names, DeepEquals, []string{"a", "dir", "dir/b", "dir/c"})
+
+	names = nil
+	for content := range newClient(c, server, "/list/dir").List(true) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.Type.IsRegular(), Equals, true)
+		names = append(names, content.Content.Name)
+	}
+	sort.Strings(names)
+	c.Assert(names, DeepEquals, []string{"dir/b", "dir/c"})
+
+	// single file lists itself with full path
+	for content := range newClient(c, server, "/list/a").List(false) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.Name, Equals, "/remote.php/webdav/list/a")
+	}
+}
+
+func (s *MySuite) TestBucketOperations(c *C) {
+	server, root := newServer(c)
+	defer os.RemoveAll(root)
+	defer server.Close()
+
+	clnt := newClient(c, server, "/newbucket/nested")
+	c.Assert(clnt.MakeBucket(), IsNil)
+	fi, err := os.Stat(filepath.Join(root, "newbucket", "nested"))
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+	// existing collections are fine
+	c.Assert(clnt.MakeBucket(), IsNil)
+
+	c.Assert(newClient(c, server, "/newbucket/file").PutObject(1, bytes.NewReader([]byte("x"))), IsNil)
+	err = newClient(c, server, "/newbucket/file").MakeBucket()
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.NotFolder{})
+
+	err = clnt.SetBucketACL("public-read")
+	c.Assert(iodine.ToError(err), FitsTypeOf, client.APINotImplemented{})
+}
@@ -0,0 +1,75 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package console
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// AuditRecord - line of the audit file, one for each HTTP request sent to a server
+type AuditRecord struct {
+	Time          string  `json:"time"`
+	Command       string  `json:"command,omitempty"`
+	Method        string  `json:"method"`
+	URL           string  `json:"url"`
+	Status        int     `json:"status,omitempty"`
+	Duration      float64 `json:"duration"` // seconds, until the response body was read
+	BytesSent     int64   `json:"bytes-sent"`
+	BytesReceived int64   `json:"bytes-received"`
+	Error         string  `json:"error,omitempty"`
+}
+
+var (
+	// auditWriter - audit file, nil if requests are not audited
+	auditWriter io.Writer
+	// auditCommand - command recorded with each request
+	auditCommand string
+)
+
+// SetAuditWriter - write an AuditRecord for each request to w as JSON lines, nil disables auditing. command
+// is recorded with every request to tell runs apart
+func SetAuditWriter(w io.Writer, command string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	auditWriter = w
+	auditCommand = command
+}
+
+// IsAuditEnabled - requests are recorded in an audit file
+func IsAuditEnabled() bool {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return auditWriter != nil
+}
+
+// Audit writes record to the audit file, time and command are filled in
+func Audit(record AuditRecord) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if auditWriter == nil {
+		return
+	}
+	record.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	record.Command = auditCommand
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		panic(err)
+	}
+	auditWriter.Write(append(recordBytes, '\n'))
+}
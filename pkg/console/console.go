@@ -18,7 +18,9 @@ package console
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"sync"
 
 	"path/filepath"
@@ -31,6 +33,27 @@ import (
 // NoDebugPrint defines if the input should be printed in debug or not. By default it's set to true.
 var NoDebugPrint = true
 
+// debugWriter - file debug messages are written to instead of standard error, nil if not set
+var debugWriter io.Writer
+
+// SetDebugWriter - write debug messages such as HTTP traces to w without colors, nil restores standard error
+func SetDebugWriter(w io.Writer) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	debugWriter = w
+}
+
+// writeDebug - message to the debug file, returns false if none is set
+func writeDebug(message string) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if debugWriter == nil {
+		return false
+	}
+	io.WriteString(debugWriter, message)
+	return true
+}
+
 // Theme holds console color scheme
 type Theme struct {
 	Fatal     *color.Color
@@ -57,19 +80,28 @@ var (
 
 	// themesDB contains supported list of Themes
 	themesDB = map[string]Theme{
-		"minimal": MiniTheme,
-		"nocolor": NoColorTheme,
-		"white":   WhiteTheme,
+		"minimal":      MiniTheme,
+		"nocolor":      NoColorTheme,
+		"white":        WhiteTheme,
+		"highcontrast": HighContrastTheme,
+		"monochrome":   MonochromeTheme,
 	}
 
+	// builtinThemes can not be replaced by AddTheme
+	builtinThemes = map[string]bool{"minimal": true, "nocolor": true, "white": true, "highcontrast": true, "monochrome": true}
+
+	// colorDisabled keeps the nocolor theme whatever theme is set, for pipes, dumb terminals and NO_COLOR
+	// (https://no-color.org)
+	colorDisabled = !isatty(os.Stdout.Fd()) || !isatty(os.Stderr.Fd()) ||
+		os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+
 	// currTheme is current theme
 	currThemeName = func() string {
-		theme := GetDefaultThemeName()
-		// if not a TTY disable color
-		if !isatty(os.Stdout.Fd()) || !isatty(os.Stderr.Fd()) {
-			theme = "nocolor"
+		if colorDisabled {
+			color.NoColor = true // empty colors of the nocolor theme print escape codes too
+			return "nocolor"
 		}
-		return theme
+		return GetDefaultThemeName()
 	}()
 
 	// Bar print progress bar
@@ -102,39 +134,72 @@ var (
 
 	// Fatal print a error message and exit
 	Fatal = func(data ...interface{}) {
-		defer os.Exit(1)
+		exitCode, code := ErrorCode(data...)
+		defer os.Exit(exitCode)
+		logFatal(exitCode, fmt.Sprint(data...))
+		if jsonErrors {
+			printJSONError(code, exitCode, fmt.Sprint(data...))
+			return
+		}
 		print(themesDB[currThemeName].Fatal, data...)
 		return
 	}
 
 	// Fatalf print a error message with a format specified and exit
 	Fatalf = func(f string, data ...interface{}) {
-		defer os.Exit(1)
+		exitCode, code := ErrorCode(data...)
+		defer os.Exit(exitCode)
+		logFatal(exitCode, fmt.Sprintf(f, data...))
+		if jsonErrors {
+			printJSONError(code, exitCode, fmt.Sprintf(f, data...))
+			return
+		}
 		printf(themesDB[currThemeName].Fatal, f, data...)
 		return
 	}
 
 	// Fatalln print a error message with a new line and exit
 	Fatalln = func(data ...interface{}) {
-		defer os.Exit(1)
+		exitCode, code := ErrorCode(data...)
+		defer os.Exit(exitCode)
+		logFatal(exitCode, fmt.Sprintln(data...))
+		if jsonErrors {
+			printJSONError(code, exitCode, fmt.Sprintln(data...))
+			return
+		}
 		println(themesDB[currThemeName].Fatal, data...)
 		return
 	}
 
 	// Error prints a error message
 	Error = func(data ...interface{}) {
+		if jsonErrors {
+			_, code := ErrorCode(data...)
+			printJSONError(code, 0, fmt.Sprint(data...))
+			return
+		}
 		print(themesDB[currThemeName].Error, data...)
 		return
 	}
 
 	// Errorf print a error message with a format specified
 	Errorf = func(f string, data ...interface{}) {
+		if jsonErrors {
+			_, code := ErrorCode(data...)
+			printJSONError(code, 0, fmt.Sprintf(f, data...))
+			return
+		}
 		printf(themesDB[currThemeName].Error, f, data...)
 		return
 	}
 
 	// Errorln prints a error message with a new line
 	Errorln = func(data ...interface{}) {
+		if jsonErrors {
+			_, code := ErrorCode(data...)
+			printJSONError(code, 0, fmt.Sprintln(data...))
+			return
+		}
 		println(themesDB[currThemeName].Error, data...)
 		return
 	}
@@ -160,39 +225,39 @@ var (
 	// Debug prints a debug message without a new line
 	// Debug prints a debug message
 	Debug = func(data ...interface{}) {
-		if !NoDebugPrint {
+		if !NoDebugPrint && !writeDebug(fmt.Sprint(data...)) {
 			print(themesDB[currThemeName].Debug, data...)
 		}
 	}
 
 	// Debugf prints a debug message with a new line
 	Debugf = func(f string, data ...interface{}) {
-		if !NoDebugPrint {
+		if !NoDebugPrint && !writeDebug(fmt.Sprintf(f, data...)) {
 			printf(themesDB[currThemeName].Debug, f, data...)
 		}
 	}
 
 	// Debugln prints a debug message with a new line
 	Debugln = func(data ...interface{}) {
-		if !NoDebugPrint {
+		if !NoDebugPrint && !writeDebug(fmt.Sprintln(data...)) {
 			println(themesDB[currThemeName].Debug, data...)
 		}
 	}
 
 	// Time helper to print Time theme
-	Time = themesDB[currThemeName].Time.SprintfFunc()
+	Time = themeSprintf(func(t Theme) *color.Color { return t.Time })
 	// Size helper to print Size theme
-	Size = themesDB[currThemeName].Size.SprintfFunc()
+	Size = themeSprintf(func(t Theme) *color.Color { return t.Size })
 	// File helper to print File theme
-	File = themesDB[currThemeName].File.SprintfFunc()
+	File = themeSprintf(func(t Theme) *color.Color { return t.File })
 	// Dir helper to print Dir theme
-	Dir = themesDB[currThemeName].Dir.SprintfFunc()
+	Dir = themeSprintf(func(t Theme) *color.Color { return t.Dir })
 	// Command helper to print command theme
-	Command = themesDB[currThemeName].Command.SprintfFunc()
+	Command = themeSprintf(func(t Theme) *color.Color { return t.Command })
 	// SessionID helper to print sessionid theme
-	SessionID = themesDB[currThemeName].SessionID.SprintfFunc()
+	SessionID = themeSprintf(func(t Theme) *color.Color { return t.SessionID })
 	// JSON helper to print json strings
-	JSON = themesDB[currThemeName].JSON.SprintfFunc()
+	JSON = themeSprintf(func(t Theme) *color.Color { return t.JSON })
 )
 
 var (
@@ -315,6 +380,14 @@ var (
 	}
 )
 
+// themeSprintf - Sprintf in a color of the current theme, looked up on every call as themes are set after
+// these helpers are created. Not locked, message printers call them while printing
+func themeSprintf(themeColor func(Theme) *color.Color) func(string, ...interface{}) string {
+	return func(format string, a ...interface{}) string {
+		return themeColor(themesDB[currThemeName]).SprintfFunc()(format, a...)
+	}
+}
+
 // Lock console
 func Lock() {
 	mutex.Lock()
@@ -333,9 +406,12 @@ func SetTheme(themeName string) error {
 
 	mutex.Lock()
 
+	if colorDisabled {
+		themeName = "nocolor"
+	}
 	// Just another additional precaution to completely disable color.
 	// Color theme is also necessary, because it does other useful things like exit-on-fatal..
-	switch currThemeName {
+	switch themeName {
 	case "nocolor":
 		color.NoColor = true
 	default:
@@ -349,6 +425,30 @@ func SetTheme(themeName string) error {
 	return nil
 }
 
+// DisableColor switches to the nocolor theme, themes set later are ignored
+func DisableColor() {
+	mutex.Lock()
+	colorDisabled = true
+	mutex.Unlock()
+	SetTheme("nocolor")
+}
+
+// AddTheme registers a theme under name, built-in themes can not be replaced
+func AddTheme(name string, theme Theme) error {
+	if name == "" || builtinThemes[name] {
+		return iodine.New(fmt.Errorf("Theme name [%s] is reserved or empty", name), nil)
+	}
+	mutex.Lock()
+	themesDB[name] = theme
+	mutex.Unlock()
+	return nil
+}
+
+// IsBuiltinTheme returns true for themes which come with mc
+func IsBuiltinTheme(themeName string) bool {
+	return builtinThemes[themeName]
+}
+
 // GetThemeName returns currently set theme name
 func GetThemeName() string {
 	return currThemeName
@@ -364,6 +464,7 @@ func GetThemeNames() (themeNames []string) {
 	for themeName := range themesDB {
 		themeNames = append(themeNames, themeName)
 	}
+	sort.Strings(themeNames)
 	return themeNames
 }
 
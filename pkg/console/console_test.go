@@ -17,7 +17,12 @@
 package console
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	. "gopkg.in/check.v1"
 )
@@ -37,6 +42,85 @@ func (s *MySuite) TestSetTheme(c *C) {
 	c.Assert(GetThemeName(), Equals, "nocolor")
 }
 
+func (s *MySuite) TestDisableColor(c *C) {
+	theme, disabled := GetThemeName(), colorDisabled
+	defer func() {
+		colorDisabled = disabled
+		SetTheme(theme)
+	}()
+
+	DisableColor()
+	c.Assert(GetThemeName(), Equals, "nocolor")
+	c.Assert(SetTheme("white"), IsNil)
+	c.Assert(GetThemeName(), Equals, "nocolor")
+}
+
+func (s *MySuite) TestNewTheme(c *C) {
+	theme, err := NewTheme(map[string]string{"dir": "hi-cyan,bold", "Error": "yellow italic"})
+	c.Assert(err, IsNil)
+	c.Assert(theme.Dir, Not(IsNil))
+	c.Assert(theme.File, Not(IsNil)) // left out, printed without colors
+	_, err = NewTheme(map[string]string{"dir": "purple"})
+	c.Assert(err, NotNil)
+	_, err = NewTheme(map[string]string{"folder": "blue"})
+	c.Assert(err, NotNil)
+
+	c.Assert(AddTheme("custom", theme), IsNil)
+	c.Assert(IsValidTheme("custom"), Equals, true)
+	c.Assert(IsBuiltinTheme("custom"), Equals, false)
+	c.Assert(AddTheme("minimal", theme), NotNil)
+	c.Assert(IsBuiltinTheme("highcontrast"), Equals, true)
+	c.Assert(IsBuiltinTheme("monochrome"), Equals, true)
+}
+
+func (s *MySuite) TestLogFile(c *C) {
+	var buf bytes.Buffer
+	SetLogWriter(&buf)
+	SetLogLevel(LogInfo)
+	defer SetLogWriter(nil)
+	defer SetLogLevel(LogOff)
+
+	RecordOperation(Operation{Command: "cp", Source: "a.txt", Target: "s3:bucket/a.txt", Bytes: 3, Duration: time.Second})
+	RecordOperation(Operation{Command: "rm", Target: "s3:bucket/b.txt", Err: errors.New("Access Denied")})
+	Logf(LogSession, LogDebug, "dropped below info level\n")
+	Logf(LogSession, LogWarn, "Session %s interrupted.\n", "abcd")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	c.Assert(lines, HasLen, 3)
+	var entries []logEntry
+	for _, line := range lines {
+		var entry logEntry
+		c.Assert(json.Unmarshal([]byte(line), &entry), IsNil)
+		entry.Time = ""
+		entries = append(entries, entry)
+	}
+	c.Assert(entries[0], Equals, logEntry{Level: "info", Component: LogOperation, Command: "cp", Source: "a.txt", Target: "s3:bucket/a.txt", Bytes: 3, Duration: 1})
+	c.Assert(entries[1], Equals, logEntry{Level: "error", Component: LogOperation, Command: "rm", Target: "s3:bucket/b.txt", Error: "Access Denied"})
+	c.Assert(entries[2], Equals, logEntry{Level: "warn", Component: LogSession, Message: "Session abcd interrupted."})
+}
+
 func (s *MySuite) TestDefaultTheme(c *C) {
 	c.Assert(GetDefaultThemeName(), Equals, "minimal")
 }
+
+func (s *MySuite) TestLogLevels(c *C) {
+	defer SetLogLevel(LogOff)
+	defer SetLogComponents(nil)
+
+	c.Assert(IsLogEnabled(LogTransport, LogError), Equals, false)
+	level, err := ParseLogLevel("WARN")
+	c.Assert(err, IsNil)
+	c.Assert(level, Equals, LogWarn)
+	_, err = ParseLogLevel("verbose")
+	c.Assert(err, NotNil)
+
+	SetLogLevel(level)
+	c.Assert(IsLogEnabled(LogTransport, LogInfo), Equals, false)
+	c.Assert(IsLogEnabled(LogTransport, LogWarn), Equals, true)
+	c.Assert(IsLogEnabled(LogSession, LogError), Equals, true)
+
+	c.Assert(SetLogComponents([]string{LogSession}), IsNil)
+	c.Assert(IsLogEnabled(LogTransport, LogError), Equals, false)
+	c.Assert(IsLogEnabled(LogSession, LogError), Equals, true)
+	c.Assert(SetLogComponents([]string{"disk"}), NotNil)
+}
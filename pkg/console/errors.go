@@ -0,0 +1,88 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package console
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrorMessage is printed on standard error for failures in JSON mode.
+type ErrorMessage struct {
+	Version  string `json:"version"`
+	Status   string `json:"status"`
+	Code     string `json:"code"`
+	ExitCode int    `json:"exit-code,omitempty"`
+	Error    string `json:"error"`
+}
+
+var (
+	// errorCoder classifies errors passed to Fatal and Error functions
+	errorCoder = defaultErrorCoder
+
+	// jsonErrors prints errors as ErrorMessage
+	jsonErrors = false
+)
+
+// defaultErrorCoder - every failure exits with 1
+func defaultErrorCoder(err error) (int, string) {
+	return 1, "error"
+}
+
+// SetErrorCoder sets the function mapping errors to an exit code and a short code name. Fatal functions exit
+// with the code of the last error among their arguments, or with 1 if there is none.
+func SetErrorCoder(coder func(error) (int, string)) {
+	if coder == nil {
+		coder = defaultErrorCoder
+	}
+	errorCoder = coder
+}
+
+// SetJSONErrors prints errors as JSON objects with a code field instead of plain text
+func SetJSONErrors(enable bool) {
+	jsonErrors = enable
+}
+
+// ErrorCode returns the exit code and code name of the last error in data
+func ErrorCode(data ...interface{}) (int, string) {
+	for i := len(data) - 1; i >= 0; i-- {
+		if err, ok := data[i].(error); ok && err != nil {
+			return errorCoder(err)
+		}
+	}
+	return defaultErrorCoder(nil)
+}
+
+// printJSONError - error object of message on standard error, exitCode is 0 for errors not ending the program
+func printJSONError(code string, exitCode int, message string) {
+	errorMessage := ErrorMessage{
+		Version:  "1.0.0",
+		Status:   "error",
+		Code:     code,
+		ExitCode: exitCode,
+		Error:    strings.TrimSpace(message),
+	}
+	errorJSON, err := json.Marshal(errorMessage)
+	if err != nil {
+		panic(err)
+	}
+	mutex.Lock()
+	fmt.Fprintln(os.Stderr, string(errorJSON))
+	mutex.Unlock()
+}
@@ -25,3 +25,4 @@ import "syscall"
 // http://fxr.watson.org/fxr/source/sys/ttycom.h?v=FREEBSD6;im=3#L69
 //
 const ioctlReadTermios = syscall.TIOCGETA
+const ioctlWriteTermios = syscall.TIOCSETA
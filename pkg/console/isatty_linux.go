@@ -22,3 +22,6 @@ import "syscall"
 
 // Standard ioctls from Linux - /usr/include/asm-generic/ioctls.h:#define TCGETS  0x5401
 const ioctlReadTermios = syscall.TCGETS
+
+// #define TCSETS  0x5402
+const ioctlWriteTermios = syscall.TCSETS
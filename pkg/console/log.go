@@ -0,0 +1,252 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package console
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// LogLevel is the severity of a diagnostic message
+type LogLevel int
+
+// Diagnostic log levels in increasing severity, LogOff disables diagnostics.
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+	LogOff
+)
+
+// Components emitting diagnostic messages
+const (
+	LogTransport = "transport" // HTTP requests and responses
+	LogPlanner   = "planner"   // source and target URL preparation of copy operations
+	LogSession   = "session"   // session life cycle, locks and resumes
+	LogOperation = "operation" // results of operations on objects, written to log files only
+)
+
+// LogComponents - list of all components emitting diagnostic messages
+var LogComponents = []string{LogTransport, LogPlanner, LogSession, LogOperation}
+
+var logLevelNames = map[LogLevel]string{
+	LogDebug: "debug",
+	LogInfo:  "info",
+	LogWarn:  "warn",
+	LogError: "error",
+}
+
+var (
+	// logLevel - messages below this level are dropped, diagnostics are off by default
+	logLevel = LogOff
+	// logComponents - components whose messages are printed, nil means all of them
+	logComponents map[string]bool
+	// logWriter - log file taking messages as JSON lines instead of standard error, nil if not set
+	logWriter io.Writer
+)
+
+// logEntry - line of a log file
+type logEntry struct {
+	Time      string  `json:"time"`
+	Level     string  `json:"level"`
+	Component string  `json:"component"`
+	Message   string  `json:"message,omitempty"`
+	Command   string  `json:"command,omitempty"`
+	Source    string  `json:"source,omitempty"`
+	Target    string  `json:"target,omitempty"`
+	Bytes     int64   `json:"bytes,omitempty"`
+	Duration  float64 `json:"duration,omitempty"` // seconds
+	Error     string  `json:"error,omitempty"`
+	ExitCode  int     `json:"exit-code,omitempty"`
+}
+
+// Operation - result of an operation on an object for log files, e.g. a copy with its source, target and
+// duration
+type Operation struct {
+	Command  string
+	Source   string
+	Target   string
+	Bytes    int64
+	Duration time.Duration
+	Err      error
+}
+
+// OpenLogFile - appends to the file at path, "syslog" sends messages to the local syslog daemon instead
+func OpenLogFile(path string) (io.WriteCloser, error) {
+	if path == "syslog" {
+		return newSyslogWriter()
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return file, nil
+}
+
+// SetLogWriter - write diagnostic messages and operation records to w as JSON lines, independent of console
+// output. Diagnostic messages are no longer printed to standard error, nil restores it
+func SetLogWriter(w io.Writer) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	logWriter = w
+}
+
+// writeLogEntry - entry as a single line to the log file, a single write each for syslog
+func writeLogEntry(entry logEntry) {
+	entry.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	entryBytes, err := json.Marshal(entry)
+	if err != nil {
+		panic(err)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if logWriter != nil {
+		logWriter.Write(append(entryBytes, '\n'))
+	}
+}
+
+// RecordOperation writes op to the log file, failed operations at error level
+func RecordOperation(op Operation) {
+	level := LogInfo
+	if op.Err != nil {
+		level = LogError
+	}
+	mutex.RLock()
+	enabled := logWriter != nil
+	mutex.RUnlock()
+	if !enabled || !IsLogEnabled(LogOperation, level) {
+		return
+	}
+	entry := logEntry{
+		Level:     logLevelNames[level],
+		Component: LogOperation,
+		Command:   op.Command,
+		Source:    op.Source,
+		Target:    op.Target,
+		Bytes:     op.Bytes,
+		Duration:  op.Duration.Seconds(),
+	}
+	if op.Err != nil {
+		entry.Error = op.Err.Error()
+	}
+	writeLogEntry(entry)
+}
+
+// logFatal - errors ending the program are written to the log file as well
+func logFatal(exitCode int, message string) {
+	mutex.RLock()
+	enabled := logWriter != nil
+	mutex.RUnlock()
+	if !enabled {
+		return
+	}
+	writeLogEntry(logEntry{
+		Level:     logLevelNames[LogError],
+		Component: LogOperation,
+		Error:     strings.TrimSpace(message),
+		ExitCode:  exitCode,
+	})
+}
+
+// ParseLogLevel - level for one of debug, info, warn or error
+func ParseLogLevel(level string) (LogLevel, error) {
+	for logLevel, name := range logLevelNames {
+		if name == strings.ToLower(level) {
+			return logLevel, nil
+		}
+	}
+	return LogOff, iodine.New(errors.New("invalid log level "+level), nil)
+}
+
+// SetLogLevel - print diagnostic messages of level and above
+func SetLogLevel(level LogLevel) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	logLevel = level
+}
+
+// SetLogComponents - print diagnostic messages of these components only, empty list selects all of them
+func SetLogComponents(components []string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(components) == 0 {
+		logComponents = nil
+		return nil
+	}
+	logComponents = make(map[string]bool)
+	for _, component := range components {
+		valid := false
+		for _, known := range LogComponents {
+			if component == known {
+				valid = true
+			}
+		}
+		if !valid {
+			return iodine.New(errors.New("unknown log component "+component), nil)
+		}
+		logComponents[component] = true
+	}
+	return nil
+}
+
+// IsLogEnabled - messages of component at level would be printed, lets callers skip building expensive messages
+func IsLogEnabled(component string, level LogLevel) bool {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if level < logLevel || level >= LogOff {
+		return false
+	}
+	return logComponents == nil || logComponents[component]
+}
+
+// Logf prints a diagnostic message of component at level to standard error
+func Logf(component string, level LogLevel, f string, data ...interface{}) {
+	if !IsLogEnabled(component, level) {
+		return
+	}
+	mutex.RLock()
+	toFile := logWriter != nil
+	mutex.RUnlock()
+	if toFile {
+		writeLogEntry(logEntry{
+			Level:     logLevelNames[level],
+			Component: component,
+			Message:   strings.TrimSpace(fmt.Sprintf(f, data...)),
+		})
+		return
+	}
+	c := themesDB[currThemeName].Debug
+	if level >= LogWarn {
+		c = themesDB[currThemeName].Error
+	}
+	mutex.Lock()
+	output := color.Output
+	color.Output = stderrColoredOutput
+	c.Printf("%s: <%s> %s: ", ProgramName(), strings.ToUpper(logLevelNames[level]), component)
+	c.Printf(f, data...)
+	color.Output = output
+	mutex.Unlock()
+}
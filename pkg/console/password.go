@@ -0,0 +1,82 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package console
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// ReadPassword prints prompt to standard error and reads a line of standard input, without echo on terminals
+func ReadPassword(prompt string) (string, error) {
+	fd := os.Stdin.Fd()
+	fmt.Fprint(os.Stderr, prompt)
+	if isatty(fd) {
+		restore, err := disableEcho(fd)
+		if err != nil {
+			return "", iodine.New(err, nil)
+		}
+		defer fmt.Fprintln(os.Stderr)
+		defer restore()
+	}
+	return readLine()
+}
+
+// Confirm prints prompt to standard error and reads a yes or no answer of standard input. Empty answers
+// and standard input which is no terminal are taken as answer def
+func Confirm(prompt string, def bool) (bool, error) {
+	if !isatty(os.Stdin.Fd()) {
+		return def, nil
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	answer, err := readLine()
+	if err != nil {
+		return false, iodine.New(err, nil)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "":
+		return def, nil
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// readLine - a line of standard input without its line ending
+func readLine() (string, error) {
+	// one byte at a time, nothing after the line is consumed
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(b)
+		if n == 1 && b[0] != '\n' {
+			line = append(line, b[0])
+			continue
+		}
+		if n == 1 || len(line) > 0 {
+			break
+		}
+		if err != nil {
+			return "", iodine.New(err, nil)
+		}
+	}
+	return strings.TrimSuffix(string(line), "\r"), nil
+}
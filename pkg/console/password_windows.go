@@ -0,0 +1,43 @@
+// +build windows
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package console
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procSetConsoleMode = kernel32.NewProc("SetConsoleMode")
+
+// https://msdn.microsoft.com/en-us/library/windows/desktop/ms686033%28v=vs.85%29.aspx
+const enableEchoInput = 0x0004
+
+// disableEcho - turn off echo of console fd, the returned function turns it back on
+func disableEcho(fd uintptr) (func(), error) {
+	var mode uint32
+	if r, _, err := syscall.Syscall(procGetConsoleMode.Addr(), 2, fd, uintptr(unsafe.Pointer(&mode)), 0); r == 0 {
+		return nil, err
+	}
+	if r, _, err := syscall.Syscall(procSetConsoleMode.Addr(), 2, fd, uintptr(mode&^enableEchoInput), 0); r == 0 {
+		return nil, err
+	}
+	return func() {
+		syscall.Syscall(procSetConsoleMode.Addr(), 2, fd, uintptr(mode), 0)
+	}, nil
+}
@@ -0,0 +1,35 @@
+// +build !windows
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package console
+
+import (
+	"io"
+	"log/syslog"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// newSyslogWriter - every write is sent as one message of the user facility
+func newSyslogWriter() (io.WriteCloser, error) {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, ProgramName())
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return w, nil
+}
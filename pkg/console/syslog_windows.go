@@ -0,0 +1,31 @@
+// +build windows
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package console
+
+import (
+	"errors"
+	"io"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// newSyslogWriter - there is no syslog daemon on windows
+func newSyslogWriter() (io.WriteCloser, error) {
+	return nil, iodine.New(errors.New("syslog is not supported on windows"), nil)
+}
@@ -16,7 +16,13 @@
 
 package console
 
-import "github.com/fatih/color"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/minio/pkg/iodine"
+)
 
 // MiniTheme - Minio's default color theme
 var MiniTheme = Theme{
@@ -71,3 +77,138 @@ var NoColorTheme = Theme{
 	PrintC:    (color.New()),
 	Print:     (color.New()),
 }
+
+// Bright foreground colors, the color package names the standard ones only
+const (
+	fgHiRed color.Attribute = iota + 91
+	fgHiGreen
+	fgHiYellow
+	fgHiBlue
+	fgHiMagenta
+	fgHiCyan
+	fgHiWhite
+)
+
+// HighContrastTheme - bright and bold colors for low vision and washed out terminals
+var HighContrastTheme = Theme{
+	Debug:     (color.New(fgHiWhite)),
+	Fatal:     (color.New(fgHiWhite, color.BgRed, color.Bold)),
+	Error:     (color.New(fgHiYellow, color.Bold)),
+	Info:      (color.New(fgHiGreen, color.Bold)),
+	File:      (color.New(fgHiWhite, color.Bold)),
+	Dir:       (color.New(fgHiCyan, color.Bold)),
+	Command:   (color.New(fgHiWhite, color.Bold, color.Underline)),
+	SessionID: (color.New(fgHiYellow, color.Bold)),
+	Size:      (color.New(fgHiYellow, color.Bold)),
+	Time:      (color.New(fgHiGreen, color.Bold)),
+	JSON:      (color.New(fgHiWhite)),
+	Bar:       (color.New(fgHiGreen, color.Bold)),
+	PrintC:    (color.New(fgHiGreen, color.Bold)),
+	Print:     (color.New()),
+}
+
+// MonochromeTheme - no colors, emphasis by bold, underlined and reversed text only
+var MonochromeTheme = Theme{
+	Debug:     (color.New(color.Faint)),
+	Fatal:     (color.New(color.Bold, color.ReverseVideo)),
+	Error:     (color.New(color.Bold)),
+	Info:      (color.New(color.Bold)),
+	File:      (color.New()),
+	Dir:       (color.New(color.Bold)),
+	Command:   (color.New(color.Bold)),
+	SessionID: (color.New(color.Underline)),
+	Size:      (color.New()),
+	Time:      (color.New()),
+	JSON:      (color.New()),
+	Bar:       (color.New(color.Bold)),
+	PrintC:    (color.New(color.Bold)),
+	Print:     (color.New()),
+}
+
+// ThemeElements - names of theme colors in theme definitions
+var ThemeElements = []string{"debug", "fatal", "error", "info", "file", "dir", "command", "sessionid", "size", "time", "json", "bar", "printc", "print"}
+
+// themeAttributes - names of colors and text attributes in theme definitions
+var themeAttributes = map[string]color.Attribute{
+	"bold":       color.Bold,
+	"faint":      color.Faint,
+	"italic":     color.Italic,
+	"underline":  color.Underline,
+	"reverse":    color.ReverseVideo,
+	"black":      color.FgBlack,
+	"red":        color.FgRed,
+	"green":      color.FgGreen,
+	"yellow":     color.FgYellow,
+	"blue":       color.FgBlue,
+	"magenta":    color.FgMagenta,
+	"cyan":       color.FgCyan,
+	"white":      color.FgWhite,
+	"hi-red":     fgHiRed,
+	"hi-green":   fgHiGreen,
+	"hi-yellow":  fgHiYellow,
+	"hi-blue":    fgHiBlue,
+	"hi-magenta": fgHiMagenta,
+	"hi-cyan":    fgHiCyan,
+	"hi-white":   fgHiWhite,
+	"bg-black":   color.BgBlack,
+	"bg-red":     color.BgRed,
+	"bg-green":   color.BgGreen,
+	"bg-yellow":  color.BgYellow,
+	"bg-blue":    color.BgBlue,
+	"bg-magenta": color.BgMagenta,
+	"bg-cyan":    color.BgCyan,
+	"bg-white":   color.BgWhite,
+}
+
+// NewTheme returns a theme of colors by element name, e.g. {"dir": "hi-cyan,bold", "error": "yellow"}.
+// Elements left out are printed without colors.
+func NewTheme(colors map[string]string) (Theme, error) {
+	theme := Theme{
+		Debug: color.New(), Fatal: color.New(), Error: color.New(), Info: color.New(), File: color.New(),
+		Dir: color.New(), Command: color.New(), SessionID: color.New(), Size: color.New(), Time: color.New(),
+		JSON: color.New(), Bar: color.New(), PrintC: color.New(), Print: color.New(),
+	}
+	for element, value := range colors {
+		c := color.New()
+		for _, name := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+			attribute, ok := themeAttributes[strings.ToLower(name)]
+			if !ok {
+				return Theme{}, iodine.New(fmt.Errorf("Unknown color ‘%s’ of theme element ‘%s’", name, element), nil)
+			}
+			c.Add(attribute)
+		}
+		switch strings.ToLower(element) {
+		case "debug":
+			theme.Debug = c
+		case "fatal":
+			theme.Fatal = c
+		case "error":
+			theme.Error = c
+		case "info":
+			theme.Info = c
+		case "file":
+			theme.File = c
+		case "dir":
+			theme.Dir = c
+		case "command":
+			theme.Command = c
+		case "sessionid":
+			theme.SessionID = c
+		case "size":
+			theme.Size = c
+		case "time":
+			theme.Time = c
+		case "json":
+			theme.JSON = c
+		case "bar":
+			theme.Bar = c
+		case "printc":
+			theme.PrintC = c
+		case "print":
+			theme.Print = c
+		default:
+			return Theme{}, iodine.New(fmt.Errorf("Unknown theme element ‘%s’, please choose from %s", element, ThemeElements), nil)
+		}
+	}
+	return theme, nil
+}
@@ -0,0 +1,638 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fakes3 is an in-memory S3 server for end-to-end tests of mc. Buckets are addressed by path, requests
+// are not authenticated. Latency, failing requests and small List Objects pages can be configured to check that
+// copies and sessions survive a misbehaving server. Sub resources like ?versioning are kept as documents, which
+// tests set to whatever a server could reply.
+package fakes3
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxKeys - most keys of a List Objects page, like S3
+const maxKeys = 1000
+
+// Fault - requests failing with an S3 error reply
+type Fault struct {
+	Method string // all methods if empty
+	Path   string // prefix of "/bucket/key" the requests match, all paths if empty
+	Status int    // HTTP status, 500 if zero
+	Code   string // S3 error code, InternalError if empty
+	Times  int    // number of matching requests failed before the fault clears, all of them if zero
+
+	Subresource string // sub resource like "versioning" the requests ask for, all requests if empty
+}
+
+// object - data of an object and what HEAD reports about it
+type object struct {
+	data        []byte
+	etag        string
+	contentType string
+	modTime     time.Time
+}
+
+// Server - S3 server backed by memory
+type Server struct {
+	*httptest.Server
+	mutex    *sync.Mutex
+	buckets  map[string]map[string]object
+	faults   []*Fault
+	latency  time.Duration
+	pageSize int
+	requests map[string]int
+
+	documents       map[string][]byte // sub resources by "/bucket/key?subresource"
+	removedVersions []string
+}
+
+// subresources - sub resources kept as documents, with the error code replied to GET while there is none or
+// the document replied instead. PUT of some requires Content-MD5 like on S3
+var subresources = map[string]struct {
+	code, document string
+	md5            bool
+}{
+	"acl":          {document: aclDocument(cannedGrants["private"])},
+	"encryption":   {code: "ServerSideEncryptionConfigurationNotFoundError"},
+	"legal-hold":   {document: "<LegalHold><Status>OFF</Status></LegalHold>", md5: true},
+	"lifecycle":    {code: "NoSuchLifecycleConfiguration", md5: true},
+	"notification": {document: "<NotificationConfiguration></NotificationConfiguration>"},
+	"policy":       {code: "NoSuchBucketPolicy"},
+	"tagging":      {document: "<Tagging><TagSet></TagSet></Tagging>"},
+	"versioning":   {document: "<VersioningConfiguration></VersioningConfiguration>"},
+	"versions":     {document: "<ListVersionsResult><IsTruncated>false</IsTruncated></ListVersionsResult>"},
+}
+
+// NewServer - start a server with the buckets, close it with Close
+func NewServer(buckets ...string) *Server {
+	s := &Server{
+		mutex:    new(sync.Mutex),
+		buckets:  make(map[string]map[string]object),
+		pageSize: maxKeys,
+		requests: make(map[string]int),
+
+		documents: make(map[string][]byte),
+	}
+	for _, bucket := range buckets {
+		s.buckets[bucket] = make(map[string]object)
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// SetLatency - delay every reply by latency
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.latency = latency
+}
+
+// SetPageSize - cap pages of List Objects at size keys whatever max-keys asks for, as servers may
+func (s *Server) SetPageSize(size int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pageSize = size
+}
+
+// AddFault - fail matching requests, faults are checked in the order they were added
+func (s *Server) AddFault(fault Fault) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if fault.Status == 0 {
+		fault.Status = http.StatusInternalServerError
+	}
+	if fault.Code == "" {
+		fault.Code = "InternalError"
+	}
+	s.faults = append(s.faults, &fault)
+}
+
+// PutObject - store an object without a request
+func (s *Server) PutObject(bucket, key string, data []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.buckets[bucket] == nil {
+		s.buckets[bucket] = make(map[string]object)
+	}
+	s.buckets[bucket][key] = newObject(data, "")
+}
+
+// GetObject - data of an object, ok is false if there is none
+func (s *Server) GetObject(bucket, key string) (data []byte, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	o, ok := s.buckets[bucket][key]
+	return o.data, ok
+}
+
+// Keys - sorted keys of a bucket
+func (s *Server) Keys(bucket string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return sortedKeys(s.buckets[bucket])
+}
+
+// SetDocument - set the document of a sub resource like "versioning" of "/bucket" or "/bucket/key" without a
+// request, documents GET of the sub resource cannot parse check how clients handle them
+func (s *Server) SetDocument(path, subresource string, document []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.documents[path+"?"+subresource] = document
+}
+
+// Document - document of a sub resource, ok is false if there is none
+func (s *Server) Document(path, subresource string) (document []byte, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	document, ok = s.documents[path+"?"+subresource]
+	return document, ok
+}
+
+// RemovedVersions - "/bucket/key?versionId=ID" of the versions removed, in the order of the requests. Versions
+// are not kept, only listed from the "versions" document of the bucket
+func (s *Server) RemovedVersions() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.removedVersions...)
+}
+
+// Requests - number of requests received for method and "/bucket/key", failed ones included
+func (s *Server) Requests(method, path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests[method+" "+path]
+}
+
+func newObject(data []byte, contentType string) object {
+	sum := md5.Sum(data)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return object{data: data, etag: hex.EncodeToString(sum[:]), contentType: contentType, modTime: time.Now().UTC()}
+}
+
+func sortedKeys(objects map[string]object) []string {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// errorResponse - body of S3 error replies
+type errorResponse struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string
+	Message   string
+	Resource  string
+	RequestID string `xml:"RequestId"`
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if r.Method != "HEAD" {
+		writeXML(w, errorResponse{Code: code, Message: code, Resource: r.URL.Path, RequestID: "fakes3"})
+	}
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+}
+
+// fault - the fault failing a request, nil if it is not failed
+func (s *Server) fault(r *http.Request) *Fault {
+	for _, fault := range s.faults {
+		if fault.Method != "" && fault.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, fault.Path) {
+			continue
+		}
+		if _, ok := r.URL.Query()[fault.Subresource]; fault.Subresource != "" && !ok {
+			continue
+		}
+		if fault.Times < 0 {
+			continue
+		}
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				fault.Times = -1 // cleared
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	s.requests[r.Method+" "+r.URL.Path]++
+	latency := s.latency
+	fault := s.fault(r)
+	s.mutex.Unlock()
+	time.Sleep(latency)
+	if fault != nil {
+		writeError(w, r, fault.Status, fault.Code)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if path == "" {
+		s.serveService(w, r)
+		return
+	}
+	bucket, key := path, ""
+	if i := strings.Index(path, "/"); i >= 0 {
+		bucket, key = path[:i], path[i+1:]
+	}
+	if key == "" {
+		s.serveBucket(w, r, bucket)
+		return
+	}
+	s.serveObject(w, r, bucket, key)
+}
+
+// listAllMyBucketsResult - reply of List Buckets
+type listAllMyBucketsResult struct {
+	XMLName xml.Name `xml:"ListAllMyBucketsResult"`
+	Buckets struct {
+		Bucket []struct {
+			Name         string
+			CreationDate string
+		}
+	}
+}
+
+func (s *Server) serveService(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed")
+		return
+	}
+	result := listAllMyBucketsResult{}
+	names := make([]string, 0, len(s.buckets))
+	for name := range s.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result.Buckets.Bucket = append(result.Buckets.Bucket, struct {
+			Name         string
+			CreationDate string
+		}{name, "2015-05-20T23:05:09.230Z"})
+	}
+	writeXML(w, result)
+}
+
+// listBucketResult - reply of List Objects
+type listBucketResult struct {
+	XMLName        xml.Name `xml:"ListBucketResult"`
+	Name           string
+	Prefix         string
+	Marker         string
+	NextMarker     string `xml:",omitempty"`
+	MaxKeys        int
+	Delimiter      string `xml:",omitempty"`
+	IsTruncated    bool
+	Contents       []listEntry
+	CommonPrefixes []struct {
+		Prefix string
+	}
+}
+
+// listEntry - an object of List Objects
+type listEntry struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int64
+	StorageClass string
+}
+
+func (s *Server) serveBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	query := r.URL.Query()
+	objects, ok := s.buckets[bucket]
+	switch {
+	case r.Method == "PUT" && len(query) == 0:
+		if ok {
+			writeError(w, r, http.StatusConflict, "BucketAlreadyOwnedByYou")
+			return
+		}
+		s.buckets[bucket] = make(map[string]object)
+		return
+	case !ok:
+		writeError(w, r, http.StatusNotFound, "NoSuchBucket")
+		return
+	case r.Method == "HEAD":
+		return
+	case r.Method == "DELETE" && len(query) == 0:
+		if len(objects) > 0 {
+			writeError(w, r, http.StatusConflict, "BucketNotEmpty")
+			return
+		}
+		delete(s.buckets, bucket)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case r.Method == "GET" && len(query["location"]) > 0:
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"LocationConstraint"`
+		}{})
+		return
+	case subresourceOf(query) != "":
+		s.serveSubresource(w, r, "/"+bucket, subresourceOf(query))
+		return
+	case r.Method == "GET" && !hasSubresource(query):
+		s.listObjects(w, r, bucket, objects)
+		return
+	}
+	writeError(w, r, http.StatusNotImplemented, "NotImplemented")
+}
+
+// hasSubresource - true if the query names a sub resource like ?acl rather than List Objects parameters
+func hasSubresource(query map[string][]string) bool {
+	for name := range query {
+		switch name {
+		case "prefix", "marker", "delimiter", "max-keys", "encoding-type":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// cannedGrants - grants S3 keeps for a canned ACL of X-Amz-Acl, besides full control of the owner
+var cannedGrants = map[string]string{
+	"private":                   "",
+	"public-read":               grant("<URI>"+allUsers+"</URI>", "READ"),
+	"public-read-write":         grant("<URI>"+allUsers+"</URI>", "READ") + grant("<URI>"+allUsers+"</URI>", "WRITE"),
+	"authenticated-read":        grant("<URI>"+authenticatedUsers+"</URI>", "READ"),
+	"bucket-owner-read":         grant("<ID>bucket-owner</ID>", "READ"),
+	"bucket-owner-full-control": grant("<ID>bucket-owner</ID>", "FULL_CONTROL"),
+}
+
+const (
+	allUsers           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	authenticatedUsers = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+func grant(grantee, permission string) string {
+	return "<Grant><Grantee>" + grantee + "</Grantee><Permission>" + permission + "</Permission></Grant>"
+}
+
+// aclDocument - access control policy of the owner "fakes3" with grants
+func aclDocument(grants string) string {
+	return "<AccessControlPolicy><Owner><ID>fakes3</ID></Owner><AccessControlList>" +
+		grant("<ID>fakes3</ID>", "FULL_CONTROL") + grants + "</AccessControlList></AccessControlPolicy>"
+}
+
+// subresourceOf - sub resource of the query kept as document, empty if there is none
+func subresourceOf(query map[string][]string) string {
+	for name := range query {
+		if _, ok := subresources[name]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// taggingDocument - tagging document of the tags sent along with PUT Object, encoded like "env=prod&tier=hot"
+func taggingDocument(header string) ([]byte, error) {
+	values, err := url.ParseQuery(header)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var document bytes.Buffer
+	document.WriteString("<Tagging><TagSet>")
+	for _, key := range keys {
+		document.WriteString("<Tag><Key>")
+		xml.EscapeText(&document, []byte(key))
+		document.WriteString("</Key><Value>")
+		xml.EscapeText(&document, []byte(values.Get(key)))
+		document.WriteString("</Value></Tag>")
+	}
+	document.WriteString("</TagSet></Tagging>")
+	return document.Bytes(), nil
+}
+
+// serveSubresource - PUT sets the document of the sub resource of path, GET replies with it and DELETE
+// removes it. Canned ACLs are kept as the access control policy S3 would reply
+func (s *Server) serveSubresource(w http.ResponseWriter, r *http.Request, path, subresource string) {
+	name := path + "?" + subresource
+	switch r.Method {
+	case "PUT":
+		if acl := r.Header.Get("X-Amz-Acl"); subresource == "acl" && acl != "" {
+			grants, ok := cannedGrants[acl]
+			if !ok {
+				writeError(w, r, http.StatusBadRequest, "InvalidArgument")
+				return
+			}
+			s.documents[name] = []byte(aclDocument(grants))
+			return
+		}
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		sum := md5.Sum(data)
+		switch contentMD5 := r.Header.Get("Content-MD5"); {
+		case contentMD5 == "" && subresources[subresource].md5:
+			writeError(w, r, http.StatusBadRequest, "InvalidRequest")
+			return
+		case contentMD5 != "" && contentMD5 != base64.StdEncoding.EncodeToString(sum[:]):
+			writeError(w, r, http.StatusBadRequest, "BadDigest")
+			return
+		}
+		s.documents[name] = data
+	case "GET":
+		document, ok := s.documents[name]
+		if !ok {
+			missing := subresources[subresource]
+			if missing.code != "" {
+				writeError(w, r, http.StatusNotFound, missing.code)
+				return
+			}
+			document = []byte(missing.document)
+		}
+		w.Write(document)
+	case "DELETE":
+		delete(s.documents, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// listObjects - a page of keys after marker, truncated at max-keys or the page size of the server.
+// NextMarker is sent only with a delimiter, like S3 does
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request, bucket string, objects map[string]object) {
+	query := r.URL.Query()
+	result := listBucketResult{
+		Name:      bucket,
+		Prefix:    query.Get("prefix"),
+		Marker:    query.Get("marker"),
+		Delimiter: query.Get("delimiter"),
+		MaxKeys:   maxKeys,
+	}
+	if query.Get("max-keys") != "" {
+		n, err := strconv.Atoi(query.Get("max-keys"))
+		if err != nil || n < 0 {
+			writeError(w, r, http.StatusBadRequest, "InvalidArgument")
+			return
+		}
+		result.MaxKeys = n
+	}
+	limit := result.MaxKeys
+	if s.pageSize < limit {
+		limit = s.pageSize
+	}
+	count := 0
+	last := ""
+	for _, key := range sortedKeys(objects) {
+		if !strings.HasPrefix(key, result.Prefix) || key <= result.Marker {
+			continue
+		}
+		name := key
+		isPrefix := false
+		if result.Delimiter != "" {
+			if i := strings.Index(key[len(result.Prefix):], result.Delimiter); i >= 0 {
+				name = key[:len(result.Prefix)+i+len(result.Delimiter)]
+				isPrefix = true
+			}
+		}
+		if isPrefix && name == last { // rest of a common prefix already sent
+			continue
+		}
+		if name <= result.Marker {
+			continue
+		}
+		if count == limit {
+			result.IsTruncated = true
+			break
+		}
+		if isPrefix {
+			result.CommonPrefixes = append(result.CommonPrefixes, struct{ Prefix string }{name})
+		} else {
+			o := objects[key]
+			result.Contents = append(result.Contents, listEntry{
+				Key:          key,
+				LastModified: o.modTime.Format("2006-01-02T15:04:05.000Z"),
+				ETag:         "\"" + o.etag + "\"",
+				Size:         int64(len(o.data)),
+				StorageClass: "STANDARD",
+			})
+		}
+		last = name
+		count++
+	}
+	if result.IsTruncated && result.Delimiter != "" {
+		result.NextMarker = last
+	}
+	writeXML(w, result)
+}
+
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	objects, ok := s.buckets[bucket]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	query := r.URL.Query()
+	switch {
+	case subresourceOf(query) != "":
+		if _, ok := objects[key]; !ok {
+			writeError(w, r, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		s.serveSubresource(w, r, "/"+bucket+"/"+key, subresourceOf(query))
+		return
+	case r.Method == "DELETE" && query.Get("versionId") != "":
+		s.removedVersions = append(s.removedVersions, "/"+bucket+"/"+key+"?versionId="+query.Get("versionId"))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case len(query) > 0: // multipart uploads and the like
+		writeError(w, r, http.StatusNotImplemented, "NotImplemented")
+		return
+	}
+	switch r.Method {
+	case "PUT":
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		if r.ContentLength >= 0 && int64(len(data)) != r.ContentLength {
+			writeError(w, r, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		var tagging []byte
+		if header := r.Header.Get("X-Amz-Tagging"); header != "" {
+			if tagging, err = taggingDocument(header); err != nil {
+				writeError(w, r, http.StatusBadRequest, "InvalidArgument")
+				return
+			}
+		}
+		o := newObject(data, r.Header.Get("Content-Type"))
+		objects[key] = o
+		if tagging != nil {
+			s.documents["/"+bucket+"/"+key+"?tagging"] = tagging
+		}
+		w.Header().Set("ETag", "\""+o.etag+"\"")
+	case "GET", "HEAD":
+		o, ok := objects[key]
+		if !ok {
+			writeError(w, r, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", "\""+o.etag+"\"")
+		w.Header().Set("Content-Type", o.contentType)
+		// handles Range and conditional requests
+		http.ServeContent(w, r, key, o.modTime, bytes.NewReader(o.data))
+	case "DELETE":
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
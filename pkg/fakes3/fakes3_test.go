@@ -0,0 +1,193 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fakes3
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+func list(c *C, url string) listBucketResult {
+	res, err := http.Get(url)
+	c.Assert(err, IsNil)
+	defer res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	var result listBucketResult
+	c.Assert(xml.NewDecoder(res.Body).Decode(&result), IsNil)
+	return result
+}
+
+func (s *MySuite) TestObjects(c *C) {
+	server := NewServer("bucket")
+	defer server.Close()
+
+	req, err := http.NewRequest("PUT", server.URL+"/bucket/dir/object", bytes.NewReader([]byte("hello world")))
+	c.Assert(err, IsNil)
+	res, err := http.DefaultClient.Do(req)
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	data, ok := server.GetObject("bucket", "dir/object")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(data), Equals, "hello world")
+
+	req, err = http.NewRequest("GET", server.URL+"/bucket/dir/object", nil)
+	c.Assert(err, IsNil)
+	req.Header.Set("Range", "bytes=6-")
+	res, err = http.DefaultClient.Do(req)
+	c.Assert(err, IsNil)
+	data, err = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusPartialContent)
+	c.Assert(string(data), Equals, "world")
+
+	res, err = http.Get(server.URL + "/other/object")
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusNotFound)
+	c.Assert(server.Requests("GET", "/bucket/dir/object"), Equals, 1)
+}
+
+func (s *MySuite) TestListObjects(c *C) {
+	server := NewServer("bucket")
+	defer server.Close()
+	for _, key := range []string{"a", "b/1", "b/2", "c", "d/1"} {
+		server.PutObject("bucket", key, []byte(key))
+	}
+	server.SetPageSize(2)
+
+	// without a delimiter the last key is the marker of the next page
+	result := list(c, server.URL+"/bucket")
+	c.Assert(result.IsTruncated, Equals, true)
+	c.Assert(result.NextMarker, Equals, "")
+	c.Assert(len(result.Contents), Equals, 2)
+	c.Assert(result.Contents[1].Key, Equals, "b/1")
+	result = list(c, server.URL+"/bucket?marker=b/1&max-keys=1")
+	c.Assert(result.IsTruncated, Equals, true)
+	c.Assert(result.Contents[0].Key, Equals, "b/2")
+
+	result = list(c, server.URL+"/bucket?delimiter=/")
+	c.Assert(result.IsTruncated, Equals, true)
+	c.Assert(result.NextMarker, Equals, "b/")
+	c.Assert(result.CommonPrefixes[0].Prefix, Equals, "b/")
+	result = list(c, server.URL+"/bucket?delimiter=/&marker=b/")
+	c.Assert(result.IsTruncated, Equals, false)
+	c.Assert(result.Contents[0].Key, Equals, "c")
+	c.Assert(result.CommonPrefixes[0].Prefix, Equals, "d/")
+}
+
+func (s *MySuite) TestFaults(c *C) {
+	server := NewServer("bucket")
+	defer server.Close()
+	server.PutObject("bucket", "object", []byte("data"))
+	server.AddFault(Fault{Method: "GET", Path: "/bucket/object", Status: http.StatusServiceUnavailable, Code: "SlowDown", Times: 2})
+
+	for i := 0; i < 2; i++ {
+		res, err := http.Get(server.URL + "/bucket/object")
+		c.Assert(err, IsNil)
+		var reply errorResponse
+		c.Assert(xml.NewDecoder(res.Body).Decode(&reply), IsNil)
+		res.Body.Close()
+		c.Assert(res.StatusCode, Equals, http.StatusServiceUnavailable)
+		c.Assert(reply.Code, Equals, "SlowDown")
+	}
+	res, err := http.Get(server.URL + "/bucket/object")
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(server.Requests("GET", "/bucket/object"), Equals, 3)
+}
+
+func (s *MySuite) TestDocuments(c *C) {
+	server := NewServer("bucket")
+	defer server.Close()
+	server.PutObject("bucket", "object", []byte("data"))
+
+	res, err := http.Get(server.URL + "/bucket?policy")
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusNotFound)
+	res, err = http.Get(server.URL + "/bucket?versioning")
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "<VersioningConfiguration></VersioningConfiguration>")
+
+	req, err := http.NewRequest("PUT", server.URL+"/bucket/object?tagging", bytes.NewReader([]byte("<Tagging/>")))
+	c.Assert(err, IsNil)
+	res, err = http.DefaultClient.Do(req)
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	document, ok := server.Document("/bucket/object", "tagging")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(document), Equals, "<Tagging/>")
+	req, err = http.NewRequest("PUT", server.URL+"/bucket/object?legal-hold", bytes.NewReader([]byte("<LegalHold/>")))
+	c.Assert(err, IsNil)
+	res, err = http.DefaultClient.Do(req)
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusBadRequest)
+	res, err = http.Get(server.URL + "/bucket/missing?tagging")
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusNotFound)
+
+	// canned ACLs are kept as their grants
+	req, err = http.NewRequest("PUT", server.URL+"/bucket/object?acl", nil)
+	c.Assert(err, IsNil)
+	req.Header.Set("X-Amz-Acl", "public-read")
+	res, err = http.DefaultClient.Do(req)
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	document, _ = server.Document("/bucket/object", "acl")
+	c.Assert(string(document), Equals, aclDocument(grant("<URI>"+allUsers+"</URI>", "READ")))
+
+	// faults may be limited to a sub resource
+	server.AddFault(Fault{Subresource: "tagging", Status: http.StatusForbidden, Code: "AccessDenied"})
+	res, err = http.Get(server.URL + "/bucket/object?tagging")
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusForbidden)
+	res, err = http.Get(server.URL + "/bucket/object")
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+
+	req, err = http.NewRequest("DELETE", server.URL+"/bucket/object?versionId=v1", nil)
+	c.Assert(err, IsNil)
+	res, err = http.DefaultClient.Do(req)
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(server.RemovedVersions(), DeepEquals, []string{"/bucket/object?versionId=v1"})
+	_, ok = server.GetObject("bucket", "object")
+	c.Assert(ok, Equals, true)
+}
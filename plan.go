@@ -0,0 +1,215 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// planEntry - a source object and the target it would be copied to
+type planEntry struct {
+	Source string
+	Target string
+	Size   int64
+}
+
+// planNode - a folder or object of the target tree of a plan, objects carry their entry
+type planNode struct {
+	children map[string]*planNode
+	entry    *planEntry
+}
+
+func newPlanNode() *planNode {
+	return &planNode{children: make(map[string]*planNode)}
+}
+
+// insert - add entry under the path of names below this node
+func (n *planNode) insert(names []string, entry *planEntry) {
+	node := n
+	for _, name := range names {
+		child, ok := node.children[name]
+		if !ok {
+			child = newPlanNode()
+			node.children[name] = child
+		}
+		node = child
+	}
+	node.entry = entry
+}
+
+// format - indented lines of the nodes below this one, folders first, then objects with their source
+func (n *planNode) format(separator, indent string) string {
+	var folders, objects []string
+	for name, child := range n.children {
+		if len(child.children) > 0 {
+			folders = append(folders, name)
+		}
+		if child.entry != nil {
+			objects = append(objects, name)
+		}
+	}
+	sort.Strings(folders)
+	sort.Strings(objects)
+	var tree string
+	for _, name := range folders {
+		tree += indent + console.Dir("%s%s", name, separator) + "\n"
+		tree += n.children[name].format(separator, indent+"   ")
+	}
+	for _, name := range objects {
+		entry := n.children[name].entry
+		tree += indent + console.File("%s", name) + console.Size(" %s", humanize.IBytes(uint64(entry.Size))) +
+			fmt.Sprintf(" ← ‘%s’\n", entry.Source)
+	}
+	return tree
+}
+
+// formatPlan - entries as one tree per target URL, grouped by target folder
+func formatPlan(targetURLs []string, entries []planEntry) string {
+	roots := make([]*planNode, len(targetURLs))
+	for i := range roots {
+		roots[i] = newPlanNode()
+	}
+	var totalBytes int64
+	for i := range entries {
+		entry := &entries[i]
+		totalBytes += entry.Size
+		// longest target URL the entry is under, a target object is its own root
+		root := -1
+		for j, targetURL := range targetURLs {
+			if strings.HasPrefix(entry.Target, targetURL) && (root < 0 || len(targetURL) > len(targetURLs[root])) {
+				root = j
+			}
+		}
+		if root < 0 {
+			continue
+		}
+		separator := separatorOf(targetURLs[root])
+		relative := strings.Trim(strings.TrimPrefix(entry.Target, targetURLs[root]), separator)
+		if relative == "" {
+			roots[root].entry = entry
+			continue
+		}
+		roots[root].insert(strings.Split(relative, separator), entry)
+	}
+	var plan string
+	for i, targetURL := range targetURLs {
+		if roots[i].entry != nil {
+			plan += console.File("%s", targetURL) + console.Size(" %s", humanize.IBytes(uint64(roots[i].entry.Size))) +
+				fmt.Sprintf(" ← ‘%s’\n", roots[i].entry.Source)
+		}
+		if len(roots[i].children) > 0 {
+			plan += console.Dir("%s", targetURL) + "\n" + roots[i].format(separatorOf(targetURL), "   ")
+		}
+	}
+	plan += fmt.Sprintf("%d objects, %s to copy.\n", len(entries), humanize.IBytes(uint64(totalBytes)))
+	return plan
+}
+
+// separatorOf - path separator of a URL
+func separatorOf(urlStr string) string {
+	if u, err := client.Parse(urlStr); err == nil {
+		return string(u.Separator)
+	}
+	return "/"
+}
+
+// printPlan - print queued entries as a tree, or as one message per entry with --json,
+// entries spilled out of memory are listed one per line without the tree
+func printPlan(targetURLs []string, queue *spillQueue) {
+	defer queue.Close()
+	if !globalJSONFlag && !queue.Spilled() {
+		entries := make([]planEntry, 0, queue.Len())
+		err := queue.Range(func(data []byte) error {
+			var entry planEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+		if err != nil {
+			console.Fatalf("Unable to read the plan. %s\n", NewIodine(iodine.New(err, nil)))
+		}
+		console.Print(formatPlan(targetURLs, entries))
+		return
+	}
+	var totalBytes int64
+	err := queue.Range(func(data []byte) error {
+		var entry planEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return err
+		}
+		totalBytes += entry.Size
+		if globalJSONFlag {
+			console.PrintC(PlanMessage{Source: entry.Source, Target: entry.Target, Size: entry.Size})
+			return nil
+		}
+		console.Print(console.File("%s", entry.Target) + console.Size(" %s", humanize.IBytes(uint64(entry.Size))) +
+			fmt.Sprintf(" ← ‘%s’\n", entry.Source))
+		return nil
+	})
+	if err != nil {
+		console.Fatalf("Unable to read the plan. %s\n", NewIodine(iodine.New(err, nil)))
+	}
+	if !globalJSONFlag {
+		console.Print(fmt.Sprintf("%d objects, %s to copy.\n", queue.Len(), humanize.IBytes(uint64(totalBytes))))
+	}
+}
+
+// pushPlanEntry - queue entry, planning stops if it can not be stored
+func pushPlanEntry(queue *spillQueue, entry planEntry) {
+	if err := queue.Push(entry); err != nil {
+		queue.Close()
+		console.Fatalf("Unable to queue the plan. %s\n", NewIodine(iodine.New(err, nil)))
+	}
+}
+
+// doPlanCopy - print what cp would copy with the rules of header, nothing is copied
+func doPlanCopy(header *sessionV2Header, trapCh <-chan bool) {
+	queue := newSpillQueue(globalQueueMemory)
+	planCopyURLs(header, trapCh, func() { queue.Close() }, func(cpURLs copyURLs) {
+		pushPlanEntry(queue, planEntry{
+			Source: cpURLs.SourceContent.Name,
+			Target: cpURLs.TargetContent.Name,
+			Size:   cpURLs.SourceContent.Size,
+		})
+	})
+	printPlan(header.CommandArgs[len(header.CommandArgs)-1:], queue)
+}
+
+// doPlanCast - print what cast would copy with the rules of header, nothing is copied
+func doPlanCast(header *sessionV2Header, trapCh <-chan bool) {
+	queue := newSpillQueue(globalQueueMemory)
+	planCastURLs(header, trapCh, func() { queue.Close() }, func(sURLs castURLs) {
+		for _, targetContent := range sURLs.TargetContents {
+			pushPlanEntry(queue, planEntry{
+				Source: sURLs.SourceContent.Name,
+				Target: targetContent.Name,
+				Size:   sURLs.SourceContent.Size,
+			})
+		}
+	})
+	printPlan(header.CommandArgs[1:], queue)
+}
@@ -0,0 +1,81 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestFormatPlan(c *C) {
+	entries := []planEntry{
+		{Source: "backup/2015/b.txt", Target: "s3:archive/2015/b.txt", Size: 2048},
+		{Source: "backup/a.txt", Target: "s3:archive/a.txt", Size: 1024},
+		{Source: "backup/2015/nested/c.txt", Target: "s3:archive/2015/nested/c.txt", Size: 1024},
+	}
+	// theme colors are not part of the layout
+	plan := regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(formatPlan([]string{"s3:archive/"}, entries), "")
+	lines := strings.Split(plan, "\n")
+	c.Assert(lines, DeepEquals, []string{
+		"s3:archive/",
+		"   2015/",
+		"      nested/",
+		"         c.txt 1.0KiB ← ‘backup/2015/nested/c.txt’",
+		"      b.txt 2.0KiB ← ‘backup/2015/b.txt’",
+		"   a.txt 1.0KiB ← ‘backup/a.txt’",
+		"3 objects, 4.0KiB to copy.",
+		"",
+	})
+}
+
+func (s *CmdTestSuite) TestSpillQueue(c *C) {
+	for _, budget := range []uint64{1 << 20, 64} {
+		queue := newSpillQueue(budget)
+		for i := 0; i < 10; i++ {
+			c.Assert(queue.Push(planEntry{Source: "backup/" + strconv.Itoa(i), Target: "s3:archive/" + strconv.Itoa(i), Size: int64(i)}), IsNil)
+		}
+		c.Assert(queue.Len(), Equals, 10)
+		c.Assert(queue.Spilled(), Equals, budget == 64)
+
+		var sizes []int64
+		err := queue.Range(func(data []byte) error {
+			var entry planEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return err
+			}
+			sizes = append(sizes, entry.Size)
+			return nil
+		})
+		c.Assert(err, IsNil)
+		c.Assert(sizes, DeepEquals, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+
+		var name string
+		if queue.Spilled() {
+			name = queue.file.Name()
+		}
+		c.Assert(queue.Close(), IsNil)
+		if name != "" {
+			_, err := os.Stat(name)
+			c.Assert(os.IsNotExist(err), Equals, true)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var policyCmd = cli.Command{
+	Name:   "policy",
+	Usage:  "Manage anonymous access to buckets and prefixes",
+	Action: runPolicyCmd,
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} set none|download|upload|public TARGET
+   mc {{.Name}} get TARGET
+   mc {{.Name}} set-json FILE TARGET
+
+DESCRIPTION:
+   Canned policies give anonymous users access to objects under TARGET, a bucket or a prefix of it. Download
+   allows reading and listing objects, upload allows writing them, public allows both and none removes the
+   access given by a canned policy. Set-json replaces the whole bucket policy with the S3 JSON policy of
+   FILE, standard input if FILE is ‘-’. Get prints the canned policy of TARGET, with ‘--json’ together with
+   the bucket policy.
+
+EXAMPLES:
+   1. Allow anyone to download objects under a prefix of a bucket on Amazon S3 object storage.
+      $ mc {{.Name}} set download s3:website/public
+
+   2. Allow anonymous uploads to a bucket on Minio object storage.
+      $ mc {{.Name}} set upload https://play.minio.io:9000/dropbox
+
+   3. Show anonymous access to a prefix and the bucket policy.
+      $ mc --json {{.Name}} get s3:website/public
+
+   4. Replace the bucket policy with a policy of your own.
+      $ mc {{.Name}} set-json website-policy.json s3:website
+`,
+}
+
+// runPolicyCmd - is a handler for mc policy command
+func runPolicyCmd(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "policy", exitUsage) // last argument is exit code
+	}
+	operation, args := ctx.Args().First(), ctx.Args().Tail()
+	var value string
+	switch operation {
+	case "set":
+		if len(args) != 2 {
+			cli.ShowCommandHelpAndExit(ctx, "policy", exitUsage) // last argument is exit code
+		}
+		value = strings.ToLower(args[0])
+		if !isCannedPolicy(value) {
+			console.Fatalf("Unknown policy ‘%s’, please choose from [%s]. %s\n", args[0], strings.Join(cannedPolicies, ", "), errInvalidArgument{})
+		}
+	case "set-json":
+		if len(args) != 2 {
+			cli.ShowCommandHelpAndExit(ctx, "policy", exitUsage) // last argument is exit code
+		}
+		var data []byte
+		var err error
+		if args[0] == "-" {
+			data, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			data, err = ioutil.ReadFile(args[0])
+		}
+		if err != nil {
+			console.Fatalf("Unable to read ‘%s’. %s\n", args[0], NewIodine(iodine.New(err, nil)))
+		}
+		value = string(data)
+	case "get":
+		if len(args) != 1 {
+			cli.ShowCommandHelpAndExit(ctx, "policy", exitUsage) // last argument is exit code
+		}
+	default:
+		console.Fatalf("Unknown operation ‘%s’, please choose from [set, get, set-json]. %s\n", operation, errInvalidArgument{})
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	arg := args[len(args)-1]
+	targetURL, err := getExpandedURL(arg, mustGetMcConfig().Aliases)
+	if err != nil {
+		switch e := iodine.ToError(err).(type) {
+		case errUnsupportedScheme:
+			console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+		default:
+			console.Fatalf("Unable to parse argument %s. %s\n", arg, err)
+		}
+	}
+	message, err := doPolicyCmd(targetURL, operation, value)
+	if err != nil {
+		console.Fatalf("Unable to %s policy of ‘%s’. %s\n", operation, targetURL, err)
+	}
+	console.Print(message)
+}
+
+// isCannedPolicy - name is one of cannedPolicies
+func isCannedPolicy(name string) bool {
+	for _, canned := range cannedPolicies {
+		if name == canned {
+			return true
+		}
+	}
+	return false
+}
+
+// url2BucketAndPrefix - bucket of a URL and the prefix below it
+func url2BucketAndPrefix(u *client.URL) (bucket, prefix string) {
+	splits := strings.SplitN(strings.TrimPrefix(u.Path, string(u.Separator)), string(u.Separator), 2)
+	bucket = splits[0]
+	if len(splits) == 2 {
+		prefix = splits[1]
+	}
+	return bucket, prefix
+}
+
+// doPolicyCmd - set the canned policy of the prefix of targetURL, replace the bucket policy with a JSON policy
+// or read the canned policy of the prefix
+func doPolicyCmd(targetURL, operation, value string) (PolicyMessage, error) {
+	message := PolicyMessage{Target: targetURL, Operation: operation}
+	clnt, err := target2Client(targetURL)
+	if err != nil {
+		return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	bucketPolicy, ok := clnt.(client.BucketPolicy)
+	if !ok {
+		return message, NewIodine(iodine.New(errPolicyNotSupported{URL: targetURL}, nil))
+	}
+	bucket, prefix := url2BucketAndPrefix(clnt.URL())
+	if operation == "set-json" {
+		document, err := parsePolicy(value)
+		if err != nil {
+			return message, NewIodine(iodine.New(err, nil))
+		}
+		if err := bucketPolicy.SetBucketPolicy(document.String()); err != nil {
+			return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+		}
+		message.Permission = getCannedPolicy(document, bucket, prefix)
+		return message, nil
+	}
+	policy, err := bucketPolicy.GetBucketPolicy()
+	if err != nil {
+		return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	document, err := parsePolicy(policy)
+	if err != nil {
+		return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	if operation == "set" {
+		if err := setCannedPolicy(document, bucket, prefix, value); err != nil {
+			return message, NewIodine(iodine.New(err, nil))
+		}
+		if err := bucketPolicy.SetBucketPolicy(document.String()); err != nil {
+			return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+		}
+	}
+	message.Permission = getCannedPolicy(document, bucket, prefix)
+	if policy := document.String(); policy != "" {
+		message.Policy = json.RawMessage(policy)
+	}
+	return message, nil
+}
@@ -0,0 +1,213 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Canned policies grant anonymous access to objects under a prefix of a bucket. Each is made of a statement
+// on the objects under the prefix and, for downloads, one listing the prefix. Setting a canned policy
+// replaces only these statements of the prefix, other statements of the bucket policy are kept as they are.
+
+// cannedPolicies - names of canned policies accepted by policy set
+var cannedPolicies = []string{"none", "download", "upload", "public"}
+
+var (
+	readObjectActions  = []string{"s3:GetObject"}
+	writeObjectActions = []string{"s3:PutObject", "s3:AbortMultipartUpload", "s3:ListMultipartUploadParts"}
+	readBucketActions  = []string{"s3:ListBucket"}
+)
+
+// policyDocument - bucket policy, statements are kept raw so ones mc does not manage are written back unchanged
+type policyDocument struct {
+	Version   string
+	ID        string            `json:"Id,omitempty"`
+	Statement []json.RawMessage `json:"Statement"`
+}
+
+// policyStatement - fields of a statement looked at to tell canned statements apart
+type policyStatement struct {
+	Effect    string                             `json:"Effect"`
+	Principal json.RawMessage                    `json:"Principal"`
+	Action    policyValues                       `json:"Action"`
+	Resource  policyValues                       `json:"Resource"`
+	Condition map[string]map[string]policyValues `json:",omitempty"`
+}
+
+// policyValues - a single string or a list of strings in policy documents
+type policyValues []string
+
+// UnmarshalJSON - accept a string as a list of one
+func (v *policyValues) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*v = policyValues{value}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(v))
+}
+
+// isAnonymous - principal is everyone, written as "*" or {"AWS": "*"}
+func (s policyStatement) isAnonymous() bool {
+	var principal string
+	if json.Unmarshal(s.Principal, &principal) == nil {
+		return principal == "*"
+	}
+	var principals struct{ AWS policyValues }
+	return json.Unmarshal(s.Principal, &principals) == nil && reflect.DeepEqual(principals.AWS, policyValues{"*"})
+}
+
+// bucketARN, objectsARN - resources of a bucket and of the objects under prefix
+func bucketARN(bucket string) string {
+	return "arn:aws:s3:::" + bucket
+}
+
+func objectsARN(bucket, prefix string) string {
+	return "arn:aws:s3:::" + bucket + "/" + prefix + "*"
+}
+
+// prefixCondition - condition limiting listing to prefix, none for the whole bucket
+func prefixCondition(prefix string) map[string]map[string]policyValues {
+	if prefix == "" {
+		return nil
+	}
+	return map[string]map[string]policyValues{"StringEquals": {"s3:prefix": {prefix}}}
+}
+
+// isCannedStatement - statement is one of a canned policy of prefix
+func (s policyStatement) isCannedStatement(bucket, prefix string) bool {
+	if s.Effect != "Allow" || !s.isAnonymous() {
+		return false
+	}
+	switch {
+	case reflect.DeepEqual(s.Resource, policyValues{objectsARN(bucket, prefix)}):
+		return len(s.Condition) == 0
+	case reflect.DeepEqual(s.Resource, policyValues{bucketARN(bucket)}):
+		return reflect.DeepEqual([]string(s.Action), readBucketActions) && reflect.DeepEqual(s.Condition, prefixCondition(prefix))
+	}
+	return false
+}
+
+// parsePolicy - policy document of a bucket, an empty one if there is no policy
+func parsePolicy(policy string) (*policyDocument, error) {
+	document := &policyDocument{Version: "2012-10-17"}
+	if strings.TrimSpace(policy) == "" {
+		return document, nil
+	}
+	if err := json.Unmarshal([]byte(policy), document); err != nil {
+		return nil, NewIodine(iodine.New(errInvalidPolicy{reason: err.Error()}, nil))
+	}
+	for _, raw := range document.Statement {
+		statement := policyStatement{}
+		if err := json.Unmarshal(raw, &statement); err != nil {
+			return nil, NewIodine(iodine.New(errInvalidPolicy{reason: err.Error()}, nil))
+		}
+	}
+	return document, nil
+}
+
+// getCannedPolicy - name of the canned policy granting what the statements of document give anonymous users
+// on objects under prefix, statements of other prefixes do not count
+func getCannedPolicy(document *policyDocument, bucket, prefix string) string {
+	var read, write bool
+	for _, raw := range document.Statement {
+		statement := policyStatement{}
+		if json.Unmarshal(raw, &statement) != nil || !statement.isCannedStatement(bucket, prefix) {
+			continue
+		}
+		for _, action := range statement.Action {
+			switch action {
+			case "s3:GetObject":
+				read = true
+			case "s3:PutObject":
+				write = true
+			}
+		}
+	}
+	switch {
+	case read && write:
+		return "public"
+	case read:
+		return "download"
+	case write:
+		return "upload"
+	}
+	return "none"
+}
+
+// setCannedPolicy - replace the canned statements of prefix in document with those of canned
+func setCannedPolicy(document *policyDocument, bucket, prefix, canned string) error {
+	var statements []json.RawMessage
+	for _, raw := range document.Statement {
+		statement := policyStatement{}
+		if json.Unmarshal(raw, &statement) == nil && statement.isCannedStatement(bucket, prefix) {
+			continue
+		}
+		statements = append(statements, raw)
+	}
+	var objectActions []string
+	if canned == "download" || canned == "public" {
+		objectActions = append(objectActions, readObjectActions...)
+	}
+	if canned == "upload" || canned == "public" {
+		objectActions = append(objectActions, writeObjectActions...)
+	}
+	var added []policyStatement
+	if objectActions != nil {
+		added = append(added, policyStatement{
+			Effect:    "Allow",
+			Principal: json.RawMessage(`{"AWS":["*"]}`),
+			Action:    objectActions,
+			Resource:  policyValues{objectsARN(bucket, prefix)},
+		})
+	}
+	if canned == "download" || canned == "public" {
+		added = append(added, policyStatement{
+			Effect:    "Allow",
+			Principal: json.RawMessage(`{"AWS":["*"]}`),
+			Action:    readBucketActions,
+			Resource:  policyValues{bucketARN(bucket)},
+			Condition: prefixCondition(prefix),
+		})
+	}
+	for _, statement := range added {
+		raw, err := json.Marshal(statement)
+		if err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+		statements = append(statements, raw)
+	}
+	document.Statement = statements
+	return nil
+}
+
+// String - policy document as JSON, empty if it has no statements left
+func (d *policyDocument) String() string {
+	if len(d.Statement) == 0 {
+		return ""
+	}
+	policy, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	return string(policy)
+}
@@ -0,0 +1,92 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/minio/mc/pkg/fakes3"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestCannedPolicy(c *C) {
+	// statements of others are kept, a plain string principal is anonymous too
+	document, err := parsePolicy(`{"Version":"2012-10-17","Statement":[
+{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::123456789012:root"},"Action":"s3:*","Resource":"arn:aws:s3:::website/*"},
+{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::website/public/*"}]}`)
+	c.Assert(err, IsNil)
+	c.Assert(getCannedPolicy(document, "website", "public/"), Equals, "download")
+	c.Assert(getCannedPolicy(document, "website", ""), Equals, "none")
+
+	c.Assert(setCannedPolicy(document, "website", "public/", "upload"), IsNil)
+	c.Assert(getCannedPolicy(document, "website", "public/"), Equals, "upload")
+	c.Assert(setCannedPolicy(document, "website", "", "public"), IsNil)
+	c.Assert(getCannedPolicy(document, "website", ""), Equals, "public")
+	c.Assert(getCannedPolicy(document, "website", "public/"), Equals, "upload")
+	c.Assert(document.Statement, HasLen, 4)
+	c.Assert(string(document.Statement[2]), Equals, `{"Effect":"Allow","Principal":{"AWS":["*"]},`+
+		`"Action":["s3:GetObject","s3:PutObject","s3:AbortMultipartUpload","s3:ListMultipartUploadParts"],"Resource":["arn:aws:s3:::website/*"]}`)
+	c.Assert(string(document.Statement[3]), Equals, `{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:ListBucket"],"Resource":["arn:aws:s3:::website"]}`)
+
+	c.Assert(setCannedPolicy(document, "website", "", "none"), IsNil)
+	c.Assert(setCannedPolicy(document, "website", "public/", "none"), IsNil)
+	c.Assert(document.Statement, HasLen, 1)
+
+	_, err = parsePolicy(`{"Statement":[{"Action":1}]}`)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errInvalidPolicy{})
+}
+
+func (s *CmdTestSuite) TestPolicy(c *C) {
+	server := fakes3.NewServer("website")
+	defer server.Close()
+
+	target := server.URL + "/website/public/"
+	message, err := doPolicyCmd(target, "get", "")
+	c.Assert(err, IsNil)
+	c.Assert(message.String(), Equals, "Access permission for ‘"+target+"’ is ‘none’.\n")
+	message, err = doPolicyCmd(target, "set", "download")
+	c.Assert(err, IsNil)
+	c.Assert(message.String(), Equals, "Access permission for ‘"+target+"’ is set to ‘download’.\n")
+	policy, _ := server.Document("/website", "policy")
+	c.Assert(string(policy), Equals, `{"Version":"2012-10-17","Statement":[`+
+		`{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::website/public/*"]},`+
+		`{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:ListBucket"],"Resource":["arn:aws:s3:::website"],`+
+		`"Condition":{"StringEquals":{"s3:prefix":["public/"]}}}]}`)
+	message, err = doPolicyCmd(server.URL+"/website", "get", "")
+	c.Assert(err, IsNil)
+	c.Assert(message.Permission, Equals, "none")
+	message, err = doPolicyCmd(target, "set", "none")
+	c.Assert(err, IsNil)
+	_, ok := server.Document("/website", "policy")
+	c.Assert(ok, Equals, false)
+
+	message, err = doPolicyCmd(server.URL+"/website", "set-json", `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::website/*"}]}`)
+	c.Assert(err, IsNil)
+	c.Assert(message.Permission, Equals, "download")
+	_, err = doPolicyCmd(server.URL+"/website", "set-json", `not a policy`)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errInvalidPolicy{})
+
+	// refused policies are not kept
+	server.AddFault(fakes3.Fault{Method: "PUT", Subresource: "policy", Status: http.StatusForbidden, Code: "AccessDenied"})
+	_, err = doPolicyCmd(server.URL+"/website", "set", "public")
+	exitCode, _ := getErrorCode(err)
+	c.Assert(exitCode, Equals, exitPermission)
+	policy, _ = server.Document("/website", "policy")
+	c.Assert(string(policy), Equals, `{"Version":"2012-10-17","Statement":[`+
+		`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::website/*"}]}`)
+}
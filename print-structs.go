@@ -18,37 +18,72 @@
 package main
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/console"
 )
 
+// marshalJSONMessage - JSON of a message for the console, indented unless --ndjson asks for one compact line
+// per message
+func marshalJSONMessage(message interface{}) ([]byte, error) {
+	return marshalJSON(message, globalNDJSONFlag)
+}
+
+// marshalJSON - JSON of a message, indented or on one compact line
+func marshalJSON(message interface{}, compact bool) ([]byte, error) {
+	if compact {
+		return json.Marshal(message)
+	}
+	return json.MarshalIndent(message, "", "\t")
+}
+
 // SessionJSONMessage json container for session messages
 type SessionJSONMessage struct {
-	Version     string   `json:"version"`
-	SessionID   string   `json:"sessionid"`
-	Time        string   `json:"time"`
-	CommandType string   `json:"command-type"`
-	CommandArgs []string `json:"command-args"`
+	Version        string   `json:"version"`
+	SessionID      string   `json:"sessionid"`
+	Time           string   `json:"time"`
+	CommandType    string   `json:"command-type"`
+	CommandArgs    []string `json:"command-args"`
+	Status         string   `json:"status"`
+	Progress       int      `json:"progress"`
+	TotalBytes     int64    `json:"total-bytes"`
+	BytesRemaining int64    `json:"bytes-remaining"`
+	Failed         int      `json:"failed,omitempty"`
 }
 
 func (s sessionV2) String() string {
 	if !globalJSONFlag {
 		message := console.SessionID("%s -> ", s.SessionID)
 		message = message + console.Time("[%s]", s.Header.When.Local().Format(printDate))
+		message = message + fmt.Sprintf(" %s %3d%%", s.Status(), s.Progress())
+		message = message + console.Size(" %s remaining", humanize.IBytes(uint64(s.Header.TotalBytes-s.Header.CopiedBytes)))
 		message = message + console.Command(" %s %s", s.Header.CommandType, strings.Join(s.Header.CommandArgs, " "))
 		return message + "\n"
 	}
 	sessionMesage := SessionJSONMessage{
-		Version:     s.Header.Version,
-		SessionID:   s.SessionID,
-		Time:        s.Header.When.Local().Format(printDate),
-		CommandType: s.Header.CommandType,
-		CommandArgs: s.Header.CommandArgs,
+		Version:        s.Header.Version,
+		SessionID:      s.SessionID,
+		Time:           s.Header.When.Local().Format(printDate),
+		CommandType:    s.Header.CommandType,
+		CommandArgs:    s.Header.CommandArgs,
+		Status:         s.Status(),
+		Progress:       s.Progress(),
+		TotalBytes:     s.Header.TotalBytes,
+		BytesRemaining: s.Header.TotalBytes - s.Header.CopiedBytes,
+		Failed:         s.Header.Failed,
 	}
-	sessionJSONBytes, err := json.MarshalIndent(sessionMesage, "", "\t")
+	sessionJSONBytes, err := marshalJSONMessage(sessionMesage)
 	if err != nil {
 		panic(err)
 	}
@@ -62,13 +97,123 @@ type Content struct {
 	Time     string `json:"last-modified"`
 	Size     string `json:"size"`
 	Name     string `json:"name"`
+
+	// long listing only, empty when backend does not provide them
+	Owner        string            `json:"owner,omitempty"`
+	OwnerID      string            `json:"owner-id,omitempty"`
+	ETag         string            `json:"etag,omitempty"`
+	StorageClass string            `json:"storage-class,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+
+	// version listing only
+	VersionID      string `json:"version-id,omitempty"`
+	IsLatest       bool   `json:"is-latest,omitempty"`
+	IsDeleteMarker bool   `json:"is-delete-marker,omitempty"`
+
+	// raw values used by separated value printers
+	modTime  time.Time
+	bytes    int64
+	etag     string
+	long     bool
+	versions bool
+
+	format *template.Template // template of ‘ls --format’, default layout if nil
+}
+
+// Bytes size in bytes, for templates of ‘ls --format’
+func (c Content) Bytes() int64 {
+	return c.bytes
+}
+
+// ModTime last modified time, for templates of ‘ls --format’
+func (c Content) ModTime() time.Time {
+	return c.modTime
+}
+
+// contentHeader returns the header row for separated value listings, empty otherwise. Version listings have
+// version columns before the name
+func contentHeader(long, versions bool) string {
+	if !globalCSVFlag && !globalTSVFlag {
+		return ""
+	}
+	header := []string{"type", "last-modified", "size", "etag"}
+	if long {
+		header = append(header, "owner", "storage-class")
+	}
+	if versions {
+		header = append(header, "version-id", "is-latest", "is-delete-marker")
+	}
+	return separatedValues(append(header, "name"))
+}
+
+// orDash - placeholder for empty long listing columns
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// separatedValues quotes and joins a single record in CSV or TSV format
+func separatedValues(record []string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if globalTSVFlag {
+		w.Comma = '\t'
+	}
+	w.Write(record)
+	w.Flush()
+	return buf.String()
 }
 
 // String string printer for Content metadata
 func (c Content) String() string {
+	if c.format != nil {
+		var buf bytes.Buffer
+		if err := c.format.Execute(&buf, c); err != nil {
+			panic(err)
+		}
+		if !strings.HasSuffix(buf.String(), "\n") {
+			buf.WriteString("\n")
+		}
+		return buf.String()
+	}
+	if globalCSVFlag || globalTSVFlag {
+		record := []string{
+			c.Filetype,
+			c.modTime.UTC().Format(time.RFC3339),
+			strconv.FormatInt(c.bytes, 10),
+			c.etag,
+		}
+		if c.long {
+			record = append(record, c.Owner, c.StorageClass)
+		}
+		if c.versions {
+			record = append(record, c.VersionID, strconv.FormatBool(c.IsLatest), strconv.FormatBool(c.IsDeleteMarker))
+		}
+		return separatedValues(append(record, c.Name))
+	}
 	if !globalJSONFlag {
 		message := console.Time("[%s] ", c.Time)
 		message = message + console.Size("%6s ", c.Size)
+		if c.long {
+			owner := c.Owner
+			if owner == "" {
+				owner = c.OwnerID
+			}
+			message = message + fmt.Sprintf("%-12s %-32s %-8s ", orDash(owner), orDash(c.ETag), orDash(c.StorageClass))
+		}
+		if c.versions && c.Filetype != "directory" {
+			// PUT for versions with data, DEL for delete markers, an asterisk marks the latest version
+			operation := "PUT"
+			if c.IsDeleteMarker {
+				operation = "DEL"
+			}
+			if c.IsLatest {
+				operation = operation + "*"
+			}
+			message = message + fmt.Sprintf("%-32s %-4s ", c.VersionID, operation)
+		}
 		message = func() string {
 			if c.Filetype == "directory" {
 				return message + console.Dir("%s", c.Name)
@@ -78,7 +223,7 @@ func (c Content) String() string {
 		return message + "\n"
 	}
 	c.Version = "1.0.0"
-	jsonMessageBytes, err := json.MarshalIndent(c, "", "\t")
+	jsonMessageBytes, err := marshalJSONMessage(c)
 	if err != nil {
 		panic(err)
 	}
@@ -99,13 +244,39 @@ func (c CopyMessage) String() string {
 		return fmt.Sprintf("‘%s’ -> ‘%s’\n", c.Source, c.Target)
 	}
 	c.Version = "1.0.0"
-	copyMessageBytes, err := json.MarshalIndent(c, "", "\t")
+	copyMessageBytes, err := marshalJSONMessage(c)
 	if err != nil {
 		panic(err)
 	}
 	return console.JSON(string(copyMessageBytes) + "\n")
 }
 
+// NameMappingMessage container for target names changed by the object name policy
+type NameMappingMessage struct {
+	Version   string `json:"version"`
+	Source    string `json:"source"`
+	Target    string `json:"target"`
+	NewTarget string `json:"new-target,omitempty"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason"`
+}
+
+// String string printer for name mapping message
+func (n NameMappingMessage) String() string {
+	if !globalJSONFlag {
+		if n.NewTarget == "" {
+			return fmt.Sprintf("\nSkipped ‘%s’. %s\n", n.Target, n.Reason)
+		}
+		return fmt.Sprintf("\nRenamed ‘%s’ -> ‘%s’. %s\n", n.Target, n.NewTarget, n.Reason)
+	}
+	n.Version = "1.0.0"
+	nameMappingMessageBytes, err := marshalJSONMessage(n)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(nameMappingMessageBytes) + "\n")
+}
+
 // CastMessage container for file cast messages
 type CastMessage struct {
 	Version string   `json:"version"`
@@ -120,9 +291,880 @@ func (s CastMessage) String() string {
 		return fmt.Sprintf("‘%s’ -> ‘%s’\n", s.Source, s.Targets)
 	}
 	s.Version = "1.0.0"
-	castMessageBytes, err := json.MarshalIndent(s, "", "\t")
+	castMessageBytes, err := marshalJSONMessage(s)
 	if err != nil {
 		panic(err)
 	}
 	return console.JSON(string(castMessageBytes) + "\n")
 }
+
+// TransferSummaryMessage container for totals of a copy or cast, printed at the end in quiet mode
+type TransferSummaryMessage struct {
+	Version   string `json:"version"`
+	SessionID string `json:"sessionid"`
+	Objects   int    `json:"objects"`
+	Bytes     int64  `json:"bytes"`
+	Failed    int    `json:"failed"`
+}
+
+// String string printer for transfer summary message
+func (t TransferSummaryMessage) String() string {
+	if !globalJSONFlag {
+		message := fmt.Sprintf("Copied %d objects, %s.", t.Objects, humanize.IBytes(uint64(t.Bytes)))
+		if t.Failed > 0 {
+			message = message + fmt.Sprintf(" %d failed.", t.Failed)
+		}
+		return message + "\n"
+	}
+	t.Version = "1.0.0"
+	transferSummaryMessageBytes, err := marshalJSONMessage(t)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(transferSummaryMessageBytes) + "\n")
+}
+
+// ListSummaryMessage container for totals of a listing, printed at the end with --summarize
+type ListSummaryMessage struct {
+	Version string `json:"version"`
+	Objects int64  `json:"objects"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// String string printer for list summary message
+func (l ListSummaryMessage) String() string {
+	if !globalJSONFlag {
+		return fmt.Sprintf("\nTotal Objects: %d\n   Total Size: %s (%d bytes)\n", l.Objects, humanize.IBytes(uint64(l.Bytes)), l.Bytes)
+	}
+	l.Version = "1.0.0"
+	listSummaryMessageBytes, err := marshalJSONMessage(l)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(listSummaryMessageBytes) + "\n")
+}
+
+// CapabilitiesMessage container for build capabilities, always printed as JSON
+type CapabilitiesMessage struct {
+	Version     string              `json:"version"`
+	Release     string              `json:"release"`
+	Tag         string              `json:"tag"`
+	CommitID    string              `json:"commit"`
+	OS          string              `json:"os"`
+	Arch        string              `json:"arch"`
+	Schemes     []string            `json:"schemes"`
+	APIs        []string            `json:"apis"`
+	GlobalFlags []flagCapability    `json:"global-flags"`
+	Commands    []commandCapability `json:"commands"`
+}
+
+// String string printer for capabilities message
+func (c CapabilitiesMessage) String() string {
+	c.Version = "1.0.0"
+	capabilitiesMessageBytes, err := marshalJSONMessage(c)
+	if err != nil {
+		panic(err)
+	}
+	return string(capabilitiesMessageBytes) + "\n"
+}
+
+// RegistryMessage container for commands, flags and arguments, always printed as JSON
+type RegistryMessage struct {
+	Version     string            `json:"version"`
+	Release     string            `json:"release"`
+	GlobalFlags []flagRegistry    `json:"global-flags"`
+	Commands    []commandRegistry `json:"commands"`
+}
+
+// String string printer for registry message
+func (r RegistryMessage) String() string {
+	r.Version = "1.0.0"
+	registryMessageBytes, err := marshalJSONMessage(r)
+	if err != nil {
+		panic(err)
+	}
+	return string(registryMessageBytes) + "\n"
+}
+
+// VerifyEndpointMessage container for one interval of verify-endpoint time series
+type VerifyEndpointMessage struct {
+	Version    string                  `json:"version"`
+	Start      time.Time               `json:"start"`
+	Operations map[string]*verifyStats `json:"operations"`
+	Errors     []verifyError           `json:"errors,omitempty"`
+}
+
+// String string printer for verify endpoint message
+func (v VerifyEndpointMessage) String() string {
+	if !globalJSONFlag {
+		var ops []string
+		for op := range v.Operations {
+			ops = append(ops, op)
+		}
+		sort.Strings(ops)
+		message := ""
+		for _, op := range ops {
+			s := v.Operations[op]
+			message += console.Time("[%s] ", v.Start.Local().Format(printDate))
+			message += fmt.Sprintf("%s: %d operations, %d errors, %d mismatches, latency min/avg/max %.1f/%.1f/%.1f ms\n",
+				op, s.Count, s.Errors, s.Mismatches, s.MinLatency, s.AvgLatency, s.MaxLatency)
+		}
+		for _, e := range v.Errors {
+			message += console.Time("[%s] ", e.Time.Local().Format(printDate))
+			message += fmt.Sprintf("%s ‘%s’ failed. %s\n", e.Op, e.URL, e.Message)
+		}
+		return message
+	}
+	v.Version = "1.0.0"
+	verifyEndpointMessageBytes, err := marshalJSONMessage(v)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(verifyEndpointMessageBytes) + "\n")
+}
+
+// ExportMessage container for objects added to an export archive
+type ExportMessage struct {
+	Version string `json:"version"`
+	Source  string `json:"source"`
+	Name    string `json:"name"`
+	Length  int64  `json:"length"`
+}
+
+// String string printer for export message
+func (e ExportMessage) String() string {
+	if !globalJSONFlag {
+		return fmt.Sprintf("‘%s’ -> ‘%s’\n", e.Source, e.Name)
+	}
+	e.Version = "1.0.0"
+	exportMessageBytes, err := marshalJSONMessage(e)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(exportMessageBytes) + "\n")
+}
+
+// ImportMessage container for archive members uploaded by import
+type ImportMessage struct {
+	Version string `json:"version"`
+	Name    string `json:"name"`
+	Target  string `json:"target"`
+	Length  int64  `json:"length"`
+}
+
+// String string printer for import message
+func (i ImportMessage) String() string {
+	if !globalJSONFlag {
+		return fmt.Sprintf("‘%s’ -> ‘%s’\n", i.Name, i.Target)
+	}
+	i.Version = "1.0.0"
+	importMessageBytes, err := marshalJSONMessage(i)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(importMessageBytes) + "\n")
+}
+
+// SessionPruneMessage container for sessions removed by prune and space used by the rest
+type SessionPruneMessage struct {
+	Version       string `json:"version"`
+	Removed       int    `json:"removed"`
+	RemovedSize   int64  `json:"removed-size"`
+	Remaining     int    `json:"remaining"`
+	RemainingSize int64  `json:"remaining-size"`
+}
+
+// String string printer for session prune message
+func (s SessionPruneMessage) String() string {
+	if !globalJSONFlag {
+		return fmt.Sprintf("Removed %d sessions, freed %s. %d sessions remain using %s.\n", s.Removed,
+			humanize.IBytes(uint64(s.RemovedSize)), s.Remaining, humanize.IBytes(uint64(s.RemainingSize)))
+	}
+	s.Version = "1.0.0"
+	sessionPruneMessageBytes, err := marshalJSONMessage(s)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(sessionPruneMessageBytes) + "\n")
+}
+
+// SessionClearMessage container for a session removed by clear
+type SessionClearMessage struct {
+	Version      string   `json:"version"`
+	SessionID    string   `json:"session-id"`
+	PartialFiles []string `json:"partial-files,omitempty"`
+}
+
+// String string printer for session clear message
+func (s SessionClearMessage) String() string {
+	if !globalJSONFlag {
+		message := "Session ‘" + s.SessionID + "’ cleared"
+		if len(s.PartialFiles) > 0 {
+			message += fmt.Sprintf(", removed %d partially copied files", len(s.PartialFiles))
+		}
+		return message + ".\n"
+	}
+	s.Version = "1.0.0"
+	sessionClearMessageBytes, err := marshalJSONMessage(s)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(sessionClearMessageBytes) + "\n")
+}
+
+// VerifyMirrorMessage container for a pass comparing a sample of source objects with their mirror
+type VerifyMirrorMessage struct {
+	Version string        `json:"version"`
+	Source  string        `json:"source"`
+	Target  string        `json:"target"`
+	Start   time.Time     `json:"start"`
+	Checked int           `json:"checked"`
+	Drifted int           `json:"drifted"`
+	Drifts  []mirrorDrift `json:"drifts,omitempty"`
+	Alert   bool          `json:"alert"`
+}
+
+// String string printer for verify mirror message
+func (v VerifyMirrorMessage) String() string {
+	if !globalJSONFlag {
+		message := console.Time("[%s] ", v.Start.Local().Format(printDate))
+		message += fmt.Sprintf("Checked %d objects of ‘%s’, %d drifted in ‘%s’.\n", v.Checked, v.Source, v.Drifted, v.Target)
+		for _, drift := range v.Drifts {
+			if drift.Reason == driftMissing {
+				message += fmt.Sprintf("‘%s’ is missing.\n", drift.URL)
+				continue
+			}
+			message += fmt.Sprintf("‘%s’ differs in %s.\n", drift.URL, drift.Reason)
+		}
+		return message
+	}
+	v.Version = "1.0.0"
+	verifyMirrorMessageBytes, err := marshalJSONMessage(v)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(verifyMirrorMessageBytes) + "\n")
+}
+
+// WatchMessage container for an event reported by watch
+type WatchMessage struct {
+	Version string    `json:"version"`
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	URL     string    `json:"url"`
+	Size    int64     `json:"size,omitempty"`
+}
+
+// String string printer for watch message
+func (w WatchMessage) String() string {
+	if !globalJSONFlag {
+		message := console.Time("[%s] ", w.Time.Local().Format(printDate))
+		if w.Type == client.EventRemove {
+			return message + fmt.Sprintf("‘%s’ removed.\n", w.URL)
+		}
+		return message + console.Size("%6s ", humanize.IBytes(uint64(w.Size))) + fmt.Sprintf("‘%s’\n", w.URL)
+	}
+	w.Version = "1.0.0"
+	watchMessageBytes, err := marshalJSONMessage(w)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(watchMessageBytes) + "\n")
+}
+
+// GrepMessage container for a matching line, or a line around it, found by grep
+type GrepMessage struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+	Line    int    `json:"line"`
+	Text    string `json:"text"`
+	Match   bool   `json:"match"`
+}
+
+// String string printer for grep message, context lines are separated with ‘-’ as by grep -n
+func (g GrepMessage) String() string {
+	if !globalJSONFlag {
+		separator := "-"
+		if g.Match {
+			separator = ":"
+		}
+		return console.File("%s", g.URL) + fmt.Sprintf("%s%d%s%s\n", separator, g.Line, separator, g.Text)
+	}
+	g.Version = "1.0.0"
+	grepMessageBytes, err := marshalJSONMessage(g)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(grepMessageBytes) + "\n")
+}
+
+// VerifyManifestMessage container for the result of checking a target against a transfer manifest
+type VerifyManifestMessage struct {
+	Version  string        `json:"version"`
+	Target   string        `json:"target"`
+	Created  time.Time     `json:"created"`
+	Checked  int           `json:"checked"`
+	Failed   int           `json:"failed"`
+	Failures []mirrorDrift `json:"failures,omitempty"`
+}
+
+// String string printer for verify manifest message
+func (v VerifyManifestMessage) String() string {
+	if !globalJSONFlag {
+		message := fmt.Sprintf("Checked %d objects of ‘%s’ against manifest of %s, %d failed.\n", v.Checked, v.Target,
+			v.Created.Local().Format(printDate), v.Failed)
+		for _, failure := range v.Failures {
+			if failure.Reason == driftMissing {
+				message += fmt.Sprintf("‘%s’ is missing.\n", failure.URL)
+				continue
+			}
+			message += fmt.Sprintf("‘%s’ differs in %s.\n", failure.URL, failure.Reason)
+		}
+		return message
+	}
+	v.Version = "1.0.0"
+	verifyManifestMessageBytes, err := marshalJSONMessage(v)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(verifyManifestMessageBytes) + "\n")
+}
+
+// PlanMessage container for an object cp or cast would copy with --plan
+type PlanMessage struct {
+	Version string `json:"version"`
+	Source  string `json:"source"`
+	Target  string `json:"target"`
+	Size    int64  `json:"size"`
+}
+
+// String string printer for plan message
+func (p PlanMessage) String() string {
+	if !globalJSONFlag {
+		return fmt.Sprintf("‘%s’ -> ‘%s’\n", p.Source, p.Target)
+	}
+	p.Version = "1.0.0"
+	planMessageBytes, err := marshalJSONMessage(p)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(planMessageBytes) + "\n")
+}
+
+// RemoveMessage container for an object removed by rm, or the target whose incomplete uploads were removed
+type RemoveMessage struct {
+	Version    string `json:"version"`
+	Target     string `json:"target"`
+	Incomplete bool   `json:"incomplete,omitempty"`
+}
+
+// String string printer for remove message
+func (r RemoveMessage) String() string {
+	if !globalJSONFlag {
+		if r.Incomplete {
+			return fmt.Sprintf("Removed incomplete uploads of ‘%s’.\n", r.Target)
+		}
+		return fmt.Sprintf("Removed ‘%s’.\n", r.Target)
+	}
+	r.Version = "1.0.0"
+	removeMessageBytes, err := marshalJSONMessage(r)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(removeMessageBytes) + "\n")
+}
+
+// UndeleteMessage container for an object restored by undelete, with the version now current
+type UndeleteMessage struct {
+	Version   string `json:"version"`
+	Target    string `json:"target"`
+	VersionID string `json:"version-id"`
+}
+
+// String string printer for undelete message
+func (u UndeleteMessage) String() string {
+	if !globalJSONFlag {
+		return fmt.Sprintf("Restored ‘%s’.\n", u.Target)
+	}
+	u.Version = "1.0.0"
+	undeleteMessageBytes, err := marshalJSONMessage(u)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(undeleteMessageBytes) + "\n")
+}
+
+// VersioningMessage container for the versioning status of a bucket, "enabled", "suspended" or "unversioned"
+type VersioningMessage struct {
+	Version string `json:"version"`
+	Target  string `json:"target"`
+	Status  string `json:"status"`
+}
+
+// String string printer for versioning message
+func (v VersioningMessage) String() string {
+	if !globalJSONFlag {
+		return fmt.Sprintf("Versioning of ‘%s’ is %s.\n", v.Target, v.Status)
+	}
+	v.Version = "1.0.0"
+	versioningMessageBytes, err := marshalJSONMessage(v)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(versioningMessageBytes) + "\n")
+}
+
+// EncryptMessage container for default encryption of a bucket after encrypt set, info or clear
+type EncryptMessage struct {
+	Version   string `json:"version"`
+	Target    string `json:"target"`
+	Operation string `json:"operation"`
+	Type      string `json:"type,omitempty"`
+	KeyID     string `json:"key-id,omitempty"`
+}
+
+// String string printer for encrypt message
+func (e EncryptMessage) String() string {
+	if !globalJSONFlag {
+		encryption := e.Type
+		if e.KeyID != "" {
+			encryption += " " + e.KeyID
+		}
+		switch {
+		case e.Operation == "clear":
+			return fmt.Sprintf("Cleared default encryption of ‘%s’.\n", e.Target)
+		case e.Operation == "set":
+			return fmt.Sprintf("Set default encryption of ‘%s’ to %s.\n", e.Target, encryption)
+		case encryption == "":
+			return fmt.Sprintf("%s  none\n", e.Target)
+		}
+		return fmt.Sprintf("%s  %s\n", e.Target, encryption)
+	}
+	e.Version = "1.0.0"
+	encryptMessageBytes, err := marshalJSONMessage(e)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(encryptMessageBytes) + "\n")
+}
+
+// PolicyMessage container for the canned policy of a bucket or prefix after policy set, get or set-json,
+// with the bucket policy it is part of
+type PolicyMessage struct {
+	Version    string          `json:"version"`
+	Target     string          `json:"target"`
+	Operation  string          `json:"operation"`
+	Permission string          `json:"permission"`
+	Policy     json.RawMessage `json:"policy,omitempty"`
+}
+
+// String string printer for policy message
+func (p PolicyMessage) String() string {
+	if !globalJSONFlag {
+		switch p.Operation {
+		case "set":
+			return fmt.Sprintf("Access permission for ‘%s’ is set to ‘%s’.\n", p.Target, p.Permission)
+		case "set-json":
+			return fmt.Sprintf("Bucket policy of ‘%s’ is replaced.\n", p.Target)
+		}
+		return fmt.Sprintf("Access permission for ‘%s’ is ‘%s’.\n", p.Target, p.Permission)
+	}
+	p.Version = "1.0.0"
+	policyMessageBytes, err := marshalJSONMessage(p)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(policyMessageBytes) + "\n")
+}
+
+// ACLMessage container for the canned ACL of a bucket or object
+type ACLMessage struct {
+	Version string `json:"version"`
+	Target  string `json:"target"`
+	ACL     string `json:"acl"`
+}
+
+// String string printer for ACL message
+func (a ACLMessage) String() string {
+	if !globalJSONFlag {
+		return fmt.Sprintf("%s  %s\n", a.Target, a.ACL)
+	}
+	a.Version = "1.0.0"
+	aclMessageBytes, err := marshalJSONMessage(a)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(aclMessageBytes) + "\n")
+}
+
+// RemoveBucketMessage container for a removed bucket, with the number of objects and object versions removed
+// to empty it
+type RemoveBucketMessage struct {
+	Version  string `json:"version"`
+	Target   string `json:"target"`
+	Objects  int    `json:"objects"`
+	Versions int    `json:"versions"`
+}
+
+// String string printer for remove bucket message
+func (r RemoveBucketMessage) String() string {
+	if !globalJSONFlag {
+		if r.Versions > 0 {
+			return fmt.Sprintf("Removed ‘%s’ and %s object versions in it.\n", r.Target, humanize.Comma(int64(r.Versions)))
+		}
+		if r.Objects > 0 {
+			return fmt.Sprintf("Removed ‘%s’ and %s objects in it.\n", r.Target, humanize.Comma(int64(r.Objects)))
+		}
+		return fmt.Sprintf("Removed ‘%s’.\n", r.Target)
+	}
+	r.Version = "1.0.0"
+	removeBucketMessageBytes, err := marshalJSONMessage(r)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(removeBucketMessageBytes) + "\n")
+}
+
+// TagMessage container for tags of an object or bucket after tag set, list or remove
+type TagMessage struct {
+	Version   string            `json:"version"`
+	Target    string            `json:"target"`
+	Operation string            `json:"operation"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// String string printer for tag message, tags are printed in the syntax of tag set
+func (t TagMessage) String() string {
+	if !globalJSONFlag {
+		values := url.Values{}
+		for key, value := range t.Tags {
+			values.Set(key, value)
+		}
+		switch t.Operation {
+		case "set":
+			return fmt.Sprintf("Set tags of ‘%s’ to ‘%s’.\n", t.Target, values.Encode())
+		case "remove":
+			return fmt.Sprintf("Removed tags of ‘%s’.\n", t.Target)
+		}
+		return fmt.Sprintf("%s  %s\n", t.Target, values.Encode())
+	}
+	t.Version = "1.0.0"
+	tagMessageBytes, err := marshalJSONMessage(t)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(tagMessageBytes) + "\n")
+}
+
+// LegalHoldMessage container for the legal hold of an object after legalhold set, clear or info
+type LegalHoldMessage struct {
+	Version   string `json:"version"`
+	Target    string `json:"target"`
+	Operation string `json:"operation"`
+	Hold      bool   `json:"legal-hold"`
+}
+
+// String string printer for legal hold message
+func (l LegalHoldMessage) String() string {
+	if !globalJSONFlag {
+		switch {
+		case l.Operation == "set":
+			return fmt.Sprintf("Set legal hold on ‘%s’.\n", l.Target)
+		case l.Operation == "clear":
+			return fmt.Sprintf("Cleared legal hold of ‘%s’.\n", l.Target)
+		case l.Hold:
+			return fmt.Sprintf("%s  ON\n", l.Target)
+		}
+		return fmt.Sprintf("%s  OFF\n", l.Target)
+	}
+	l.Version = "1.0.0"
+	legalHoldMessageBytes, err := marshalJSONMessage(l)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(legalHoldMessageBytes) + "\n")
+}
+
+// LifecycleMessage container for lifecycle rules of a bucket changed, exported or imported by ilm
+type LifecycleMessage struct {
+	Version   string `json:"version"`
+	Target    string `json:"target"`
+	Operation string `json:"operation"`
+	ID        string `json:"id,omitempty"`
+	File      string `json:"file,omitempty"`
+}
+
+// String string printer for lifecycle message
+func (l LifecycleMessage) String() string {
+	if !globalJSONFlag {
+		switch {
+		case l.Operation == "add":
+			return fmt.Sprintf("Added lifecycle rule ‘%s’ to ‘%s’.\n", l.ID, l.Target)
+		case l.Operation == "rm" && l.ID != "":
+			return fmt.Sprintf("Removed lifecycle rule ‘%s’ of ‘%s’.\n", l.ID, l.Target)
+		case l.Operation == "rm":
+			return fmt.Sprintf("Removed all lifecycle rules of ‘%s’.\n", l.Target)
+		case l.Operation == "export":
+			return fmt.Sprintf("Exported lifecycle rules of ‘%s’ to ‘%s’.\n", l.Target, l.File)
+		}
+		return fmt.Sprintf("Imported lifecycle rules of ‘%s’ from ‘%s’.\n", l.Target, l.File)
+	}
+	l.Version = "1.0.0"
+	lifecycleMessageBytes, err := marshalJSONMessage(l)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(lifecycleMessageBytes) + "\n")
+}
+
+// LifecycleRuleMessage container for a lifecycle rule listed by ilm ls
+type LifecycleRuleMessage struct {
+	Version string `json:"version"`
+	Target  string `json:"target"`
+	client.LifecycleRule
+}
+
+// String string printer for lifecycle rule message, one line per rule with its actions
+func (l LifecycleRuleMessage) String() string {
+	if !globalJSONFlag {
+		status := "Enabled"
+		if !l.Enabled {
+			status = "Disabled"
+		}
+		var actions []string
+		if l.TransitionStorageClass != "" {
+			actions = append(actions, fmt.Sprintf("transition to %s after %dd", l.TransitionStorageClass, l.TransitionDays))
+		}
+		if l.ExpirationDays > 0 {
+			actions = append(actions, fmt.Sprintf("expire after %dd", l.ExpirationDays))
+		}
+		if l.NoncurrentExpirationDays > 0 {
+			actions = append(actions, fmt.Sprintf("expire noncurrent versions after %dd", l.NoncurrentExpirationDays))
+		}
+		return fmt.Sprintf("%-20s %-8s %-24s %s\n", l.ID, status, l.Prefix, strings.Join(actions, ", "))
+	}
+	l.Version = "1.0.0"
+	lifecycleRuleMessageBytes, err := marshalJSONMessage(l)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(lifecycleRuleMessageBytes) + "\n")
+}
+
+// EventMessage container for a notification added or removed by event add or remove
+type EventMessage struct {
+	Version   string `json:"version"`
+	Target    string `json:"target"`
+	Operation string `json:"operation"`
+	ARN       string `json:"arn,omitempty"`
+	ID        string `json:"id,omitempty"`
+}
+
+// String string printer for event message
+func (e EventMessage) String() string {
+	if !globalJSONFlag {
+		switch {
+		case e.Operation == "add":
+			return fmt.Sprintf("Added notification of ‘%s’ to ‘%s’.\n", e.Target, e.ARN)
+		case e.ARN != "":
+			return fmt.Sprintf("Removed notifications of ‘%s’ to ‘%s’.\n", e.Target, e.ARN)
+		case e.ID != "":
+			return fmt.Sprintf("Removed notification ‘%s’ of ‘%s’.\n", e.ID, e.Target)
+		}
+		return fmt.Sprintf("Removed all notifications of ‘%s’.\n", e.Target)
+	}
+	e.Version = "1.0.0"
+	eventMessageBytes, err := marshalJSONMessage(e)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(eventMessageBytes) + "\n")
+}
+
+// EventConfigMessage container for a notification listed by event list
+type EventConfigMessage struct {
+	Version string `json:"version"`
+	Target  string `json:"target"`
+	client.NotificationConfig
+}
+
+// String string printer for event config message, one line per notification with its filter and events
+func (e EventConfigMessage) String() string {
+	if !globalJSONFlag {
+		var filter string
+		if e.Prefix != "" || e.Suffix != "" {
+			filter = e.Prefix + "*" + e.Suffix
+		}
+		return fmt.Sprintf("%-20s %-48s %-24s %s\n", e.ID, e.ARN, filter, strings.Join(e.Events, ","))
+	}
+	e.Version = "1.0.0"
+	eventConfigMessageBytes, err := marshalJSONMessage(e)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(eventConfigMessageBytes) + "\n")
+}
+
+// AdminInfoMessage container for the status of a Minio deployment reported by admin info
+type AdminInfoMessage struct {
+	Version string `json:"version"`
+	Target  string `json:"target"`
+	client.ServerInfo
+}
+
+// String string printer for admin info message, a summary of the deployment followed by a table of its servers
+func (a AdminInfoMessage) String() string {
+	if !globalJSONFlag {
+		var total, used uint64
+		var healing int
+		for _, node := range a.Nodes {
+			for _, disk := range node.Disks {
+				total += disk.Total
+				used += disk.Used
+				if disk.Healing {
+					healing++
+				}
+			}
+		}
+		message := fmt.Sprintf("‘%s’ is %s with %s buckets and %s objects of %s.\n", a.Target, a.Mode,
+			humanize.Comma(a.Buckets), humanize.Comma(a.Objects), humanize.IBytes(uint64(a.Usage)))
+		message += fmt.Sprintf("Disks use %s of %s", humanize.IBytes(used), humanize.IBytes(total))
+		if healing > 0 {
+			message += fmt.Sprintf(", %d disks healing", healing)
+		}
+		message += ".\n\n"
+		message += fmt.Sprintf("%-28s %-8s %-8s %-24s %-7s %-7s %s\n", "ENDPOINT", "STATE", "UPTIME", "VERSION", "DISKS", "HEALING", "USED")
+		for _, node := range a.Nodes {
+			var online, healing int
+			var total, used uint64
+			for _, disk := range node.Disks {
+				if disk.State == "ok" {
+					online++
+				}
+				if disk.Healing {
+					healing++
+				}
+				total += disk.Total
+				used += disk.Used
+			}
+			uptime := "-"
+			if node.State == "online" {
+				uptime = formatUptime(node.Uptime)
+			}
+			message += fmt.Sprintf("%-28s %-8s %-8s %-24s %-7s %-7d %s / %s\n", node.Endpoint, node.State, uptime,
+				orDash(node.Version), fmt.Sprintf("%d/%d", online, len(node.Disks)), healing, humanize.IBytes(used), humanize.IBytes(total))
+		}
+		return message
+	}
+	a.Version = "1.0.0"
+	adminInfoMessageBytes, err := marshalJSONMessage(a)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(adminInfoMessageBytes) + "\n")
+}
+
+// AliasMessage container for an alias of config alias list
+type AliasMessage struct {
+	Version string `json:"version"`
+	Alias   string `json:"alias"`
+	URL     string `json:"url"`
+}
+
+// String string printer for alias message
+func (a AliasMessage) String() string {
+	if !globalJSONFlag {
+		return fmt.Sprintf("%-12s %s\n", a.Alias, a.URL)
+	}
+	a.Version = "1.0.0"
+	aliasMessageBytes, err := marshalJSONMessage(a)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(aliasMessageBytes) + "\n")
+}
+
+// ThemeMessage container for a theme of config theme list
+type ThemeMessage struct {
+	Version string `json:"version"`
+	Theme   string `json:"theme"`
+	Builtin bool   `json:"builtin"`
+	Default bool   `json:"default"`
+}
+
+// String string printer for theme message
+func (t ThemeMessage) String() string {
+	if !globalJSONFlag {
+		kind := "custom"
+		if t.Builtin {
+			kind = "built-in"
+		}
+		message := fmt.Sprintf("%-14s %s", t.Theme, kind)
+		if t.Default {
+			message = message + " (default)"
+		}
+		return message + "\n"
+	}
+	t.Version = "1.0.0"
+	themeMessageBytes, err := marshalJSONMessage(t)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(themeMessageBytes) + "\n")
+}
+
+// MigrationMessage container for one migration step applied to a config or session file
+type MigrationMessage struct {
+	Version     string `json:"version"`
+	Path        string `json:"path"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Description string `json:"description"`
+	Backup      string `json:"backup"`
+}
+
+// String string printer for migration message
+func (m MigrationMessage) String() string {
+	if !globalJSONFlag {
+		return fmt.Sprintf("Migrated ‘%s’ from version %s to %s, %s. Previous version saved to ‘%s’.\n", m.Path, m.From, m.To, m.Description, m.Backup)
+	}
+	m.Version = "1.0.0"
+	migrationMessageBytes, err := marshalJSONMessage(m)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(migrationMessageBytes) + "\n")
+}
+
+// ConfigVerifyMessage container for the result of checking an alias with config verify
+type ConfigVerifyMessage struct {
+	Version   string  `json:"version"`
+	Alias     string  `json:"alias"`
+	URL       string  `json:"url"`
+	Status    string  `json:"status"`
+	Latency   float64 `json:"latency-ms"`
+	Signature string  `json:"signature,omitempty"`
+	Region    string  `json:"region,omitempty"`
+	Hint      string  `json:"hint,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// String string printer for config verify message
+func (v ConfigVerifyMessage) String() string {
+	if !globalJSONFlag {
+		message := fmt.Sprintf("%-12s %-18s %8.1fms %-5s %-12s %s\n", v.Alias, v.Status, v.Latency, v.Signature, v.Region, v.URL)
+		if v.Hint != "" {
+			message += "             " + v.Hint + "\n"
+		}
+		if v.Error != "" {
+			message += "             " + v.Error + "\n"
+		}
+		return message
+	}
+	v.Version = "1.0.0"
+	verifyMessageBytes, err := marshalJSONMessage(v)
+	if err != nil {
+		panic(err)
+	}
+	return console.JSON(string(verifyMessageBytes) + "\n")
+}
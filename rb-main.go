@@ -0,0 +1,243 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var rbCmd = cli.Command{
+	Name:   "rb",
+	Usage:  "Remove a bucket or folder",
+	Action: runRemoveBucketCmd,
+	Flags:  []cli.Flag{emptyBucketFlag},
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [--force] TARGET [TARGET...]
+
+DESCRIPTION:
+   Buckets are removed only if they are empty. With --force every object is removed first, all versions of
+   objects in versioned buckets and incomplete uploads included. Removed objects cannot be restored.
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
+
+EXAMPLES:
+   1. Remove an empty bucket on Amazon S3 object storage.
+      $ mc {{.Name}} s3:scratch
+
+   2. Remove a bucket on Minio object storage with all objects in it.
+      $ mc {{.Name}} --force https://play.minio.io:9000/mongodb-backup
+
+   3. Remove an empty folder on local filesystem.
+      $ mc {{.Name}} /tmp/build
+`,
+}
+
+// runRemoveBucketCmd - is a handler for mc rb command
+func runRemoveBucketCmd(ctx *cli.Context) {
+	if !ctx.Args().Present() || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "rb", exitUsage) // last argument is exit code
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	config := mustGetMcConfig()
+	force := ctx.Bool("force") || globalForceFlag
+	for _, arg := range ctx.Args() {
+		targetURL, err := getExpandedURL(arg, config.Aliases)
+		if err != nil {
+			switch e := iodine.ToError(err).(type) {
+			case errUnsupportedScheme:
+				console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+			default:
+				console.Fatalf("Unable to parse argument %s. %s\n", arg, err)
+			}
+		}
+		message, err := doRemoveBucketCmd(targetURL, force)
+		if err != nil {
+			console.Fatalf("Failed to remove bucket : %s. %s\n", targetURL, err)
+		}
+		console.Print(message)
+	}
+}
+
+// doRemoveBucketCmd - remove the bucket of targetURL, emptying it first if force is set
+func doRemoveBucketCmd(targetURL string, force bool) (RemoveBucketMessage, error) {
+	message := RemoveBucketMessage{Target: targetURL}
+	clnt, err := target2Client(targetURL)
+	if err != nil {
+		return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	bucketRemover, ok := clnt.(client.BucketRemover)
+	if !ok {
+		return message, NewIodine(iodine.New(errRemoveNotSupported{URL: targetURL}, nil))
+	}
+	if force {
+		// objects are listed relative to the bucket, as by a recursive rm of all objects under it
+		separator := string(clnt.URL().Separator)
+		listClnt, err := target2Client(strings.TrimSuffix(targetURL, separator) + separator)
+		if err != nil {
+			return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+		}
+		message.Objects, message.Versions, err = emptyBucket(listClnt)
+		if message.Objects+message.Versions > 0 && showProgressBar() {
+			console.PrintC("\n") // end the line of the running count
+		}
+		if err != nil {
+			return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+		}
+	}
+	start := time.Now()
+	err = bucketRemover.RemoveBucket()
+	console.RecordOperation(console.Operation{Command: "rb", Target: targetURL, Duration: time.Since(start), Err: err})
+	if err != nil {
+		return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	return message, nil
+}
+
+// isVersionedBucket - versioning was ever enabled on the bucket of clnt, its objects may have older versions
+func isVersionedBucket(clnt client.Client) bool {
+	versioning, ok := clnt.(client.Versioning)
+	if !ok {
+		return false
+	}
+	status, err := versioning.GetVersioning()
+	return err == nil && status != ""
+}
+
+// removeCounterFactory - returns a function reporting the running count of objects and versions removed
+func removeCounterFactory(target string) func(objects, versions int) {
+	// quiet and JSON output may have no terminal, like in cron jobs
+	if !showProgressBar() {
+		return func(int, int) {}
+	}
+	return func(objects, versions int) {
+		console.PrintC(fmt.Sprintf("\rEmptying ‘%s’: %s objects, %s versions removed.", target,
+			humanize.Comma(int64(objects)), humanize.Comma(int64(versions))))
+	}
+}
+
+// emptyBucket - remove incomplete uploads and all objects under the URL of clnt, every version of them in
+// versioned buckets. Objects are removed in parallel like cp copies them. Returns the number of objects and
+// versions removed
+func emptyBucket(clnt client.Client) (objects, versions int, err error) {
+	if remover, ok := clnt.(client.IncompleteRemover); ok {
+		err := remover.RemoveIncomplete(true)
+		if _, ok := iodine.ToError(err).(client.APINotImplemented); err != nil && !ok {
+			return 0, 0, NewIodine(iodine.New(err, nil))
+		}
+	}
+	var entries <-chan client.ContentOnChannel
+	lister, versioned := clnt.(client.VersionLister)
+	versioned = versioned && isVersionedBucket(clnt)
+	if versioned {
+		entries = lister.ListVersions(true)
+	} else {
+		entries = clnt.List(true)
+	}
+
+	counter := removeCounterFactory(clnt.URL().String())
+	wg := new(sync.WaitGroup)
+	rbQueue := make(chan bool, getParallel(clnt.URL().String()))
+	var mutex sync.Mutex
+	// failed - record the first error, true if there was one
+	failed := func(e error) bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if err == nil {
+			err = e
+		}
+		return err != nil
+	}
+	for entry := range entries {
+		if entry.Err != nil {
+			failed(NewIodine(iodine.New(entry.Err, nil)))
+			break
+		}
+		// folders of the filesystem are removed along with the bucket
+		if entry.Content.Type.IsDir() || !versioned && !entry.Content.Type.IsRegular() {
+			continue
+		}
+		if failed(nil) {
+			break
+		}
+		rbQueue <- true
+		wg.Add(1)
+		go func(content *client.Content) {
+			defer wg.Done()
+			defer func() {
+				<-rbQueue
+			}()
+			if e := removeListed(clnt, content, versioned); e != nil {
+				failed(e)
+				return
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			if versioned {
+				versions++
+			} else {
+				objects++
+			}
+			counter(objects, versions)
+		}(entry.Content)
+	}
+	wg.Wait()
+	return objects, versions, err
+}
+
+// removeListed - remove an object listed under the URL of clnt, the listed version of it if versioned is set
+func removeListed(clnt client.Client, content *client.Content, versioned bool) error {
+	objectURL := listedURL(clnt, content, true, false)
+	objectClnt, err := target2Client(objectURL)
+	if err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"Target": objectURL}))
+	}
+	if versioned {
+		remover, ok := objectClnt.(client.VersionRemover)
+		if !ok {
+			return NewIodine(iodine.New(errVersionsNotSupported{URL: objectURL}, nil))
+		}
+		if err := remover.RemoveVersion(content.VersionID); err != nil {
+			return NewIodine(iodine.New(err, map[string]string{"Target": objectURL}))
+		}
+		return nil
+	}
+	remover, ok := objectClnt.(client.Remover)
+	if !ok {
+		return NewIodine(iodine.New(errRemoveNotSupported{URL: objectURL}, nil))
+	}
+	if err := remover.Remove(); err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"Target": objectURL}))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/minio/mc/pkg/fakes3"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestRemoveBucket(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	bucket := filepath.Join(root, "bucket")
+	c.Assert(os.MkdirAll(filepath.Join(bucket, "docs", "empty"), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(bucket, "docs", "a.txt"), []byte("a"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(bucket, "b.txt"), []byte("b"), 0600), IsNil)
+
+	// buckets with objects are kept unless forced
+	_, err = doRemoveBucketCmd(bucket, false)
+	c.Assert(err, NotNil)
+	message, err := doRemoveBucketCmd(bucket, true)
+	c.Assert(err, IsNil)
+	c.Assert(message.Objects, Equals, 2)
+	c.Assert(message.String(), Equals, "Removed ‘"+bucket+"’ and 2 objects in it.\n")
+	_, err = os.Stat(bucket)
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *CmdTestSuite) TestRemoveVersionedBucket(c *C) {
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+	server.SetDocument("/bucket", "versioning", []byte(`<VersioningConfiguration><Status>Suspended</Status></VersioningConfiguration>`))
+	server.SetDocument("/bucket", "versions", []byte(`<ListVersionsResult><IsTruncated>false</IsTruncated>
+<Version><Key>a.txt</Key><VersionId>v2</VersionId><IsLatest>true</IsLatest><LastModified>2015-09-02T00:00:00.000Z</LastModified><Size>1</Size></Version>
+<DeleteMarker><Key>a.txt</Key><VersionId>v1</VersionId><IsLatest>false</IsLatest><LastModified>2015-09-01T00:00:00.000Z</LastModified></DeleteMarker>
+<Version><Key>docs/b.txt</Key><VersionId>v3</VersionId><IsLatest>true</IsLatest><LastModified>2015-09-03T00:00:00.000Z</LastModified><Size>1</Size></Version>
+</ListVersionsResult>`))
+
+	message, err := doRemoveBucketCmd(server.URL+"/bucket", true)
+	c.Assert(err, IsNil)
+	c.Assert(message.Versions, Equals, 3)
+	c.Assert(message.Objects, Equals, 0)
+	c.Assert(message.String(), Equals, "Removed ‘"+server.URL+"/bucket’ and 3 object versions in it.\n")
+	// versions are removed in parallel, in no particular order
+	removed := server.RemovedVersions()
+	sort.Strings(removed)
+	c.Assert(removed, DeepEquals, []string{"/bucket/a.txt?versionId=v1", "/bucket/a.txt?versionId=v2", "/bucket/docs/b.txt?versionId=v3"})
+	c.Assert(server.Requests("DELETE", "/bucket"), Equals, 1)
+
+	// the first failed removal stops emptying, the bucket is kept
+	server = fakes3.NewServer("bucket")
+	defer server.Close()
+	server.SetDocument("/bucket", "versioning", []byte(`<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>`))
+	server.SetDocument("/bucket", "versions", []byte(`<ListVersionsResult><IsTruncated>false</IsTruncated>
+<Version><Key>a.txt</Key><VersionId>v1</VersionId><IsLatest>true</IsLatest><LastModified>2015-09-01T00:00:00.000Z</LastModified><Size>1</Size></Version>
+</ListVersionsResult>`))
+	server.AddFault(fakes3.Fault{Method: "DELETE", Path: "/bucket/a.txt", Code: "AccessDenied", Status: http.StatusForbidden})
+	_, err = doRemoveBucketCmd(server.URL+"/bucket", true)
+	exitCode, _ := getErrorCode(err)
+	c.Assert(exitCode, Equals, exitPermission)
+	c.Assert(server.Requests("DELETE", "/bucket"), Equals, 0)
+}
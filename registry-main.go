@@ -0,0 +1,204 @@
+/*
+ * Minio Client, (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/minio/cli"
+)
+
+// Help message. Hidden, it is meant for completion engines and documentation generators rather than users
+var registryCmd = cli.Command{
+	Name:   "registry",
+	Usage:  "Print commands, flags and arguments as JSON",
+	Action: runRegistryCmd,
+	Hide:   true,
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}}
+
+EXAMPLES:
+   1. Generate shell completions from the commands of this build.
+      $ mc {{.Name}} | completion-generator
+
+`,
+}
+
+// flagRegistry - a flag with its help text and default value
+type flagRegistry struct {
+	flagCapability
+	Usage   string `json:"usage"`
+	Default string `json:"default,omitempty"`
+	EnvVar  string `json:"env,omitempty"`
+	Hidden  bool   `json:"hidden,omitempty"`
+}
+
+// argRegistry - an argument of a usage line, literals are typed as is like sub commands
+type argRegistry struct {
+	Name     string `json:"name"`
+	Literal  bool   `json:"literal,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+	Repeated bool   `json:"repeated,omitempty"`
+}
+
+// commandRegistry - a command, its flags and the arguments of each of its usage lines
+type commandRegistry struct {
+	Name        string          `json:"name"`
+	Aliases     []string        `json:"aliases,omitempty"`
+	Usage       string          `json:"usage"`
+	Description string          `json:"description,omitempty"`
+	Hidden      bool            `json:"hidden,omitempty"`
+	Flags       []flagRegistry  `json:"flags"`
+	Args        [][]argRegistry `json:"args"`
+}
+
+// getFlagRegistry - like getFlagCapability, with the fields all known flag types share
+func getFlagRegistry(flag cli.Flag) flagRegistry {
+	registry := flagRegistry{flagCapability: getFlagCapability(flag)}
+	switch f := flag.(type) {
+	case cli.BoolFlag:
+		registry.Usage, registry.EnvVar, registry.Hidden = f.Usage, f.EnvVar, f.Hide
+	case cli.BoolTFlag:
+		registry.Usage, registry.EnvVar, registry.Hidden = f.Usage, f.EnvVar, f.Hide
+		registry.Default = "true"
+	case cli.StringFlag:
+		registry.Usage, registry.EnvVar, registry.Hidden = f.Usage, f.EnvVar, f.Hide
+		registry.Default = f.Value
+	case cli.StringSliceFlag:
+		registry.Usage, registry.EnvVar, registry.Hidden = f.Usage, f.EnvVar, f.Hide
+		if f.Value != nil && len(*f.Value) > 0 {
+			registry.Default = f.Value.String()
+		}
+	case cli.IntFlag:
+		registry.Usage, registry.EnvVar, registry.Hidden = f.Usage, f.EnvVar, f.Hide
+		registry.Default = fmt.Sprint(f.Value)
+	case cli.IntSliceFlag:
+		registry.Usage, registry.EnvVar, registry.Hidden = f.Usage, f.EnvVar, f.Hide
+		if f.Value != nil && len(*f.Value) > 0 {
+			registry.Default = f.Value.String()
+		}
+	case cli.Float64Flag:
+		registry.Usage, registry.EnvVar, registry.Hidden = f.Usage, f.EnvVar, f.Hide
+		registry.Default = fmt.Sprint(f.Value)
+	case cli.DurationFlag:
+		registry.Usage, registry.EnvVar, registry.Hidden = f.Usage, f.EnvVar, f.Hide
+		registry.Default = f.Value.String()
+	case cli.GenericFlag:
+		registry.Usage, registry.EnvVar, registry.Hidden = f.Usage, f.EnvVar, f.Hide
+		if f.Value != nil {
+			registry.Default = f.Value.String()
+		}
+	}
+	return registry
+}
+
+// templateAction - {{...}} of help templates
+var templateAction = regexp.MustCompile(`{{[^}]*}}`)
+
+// getArgRegistry - arguments of each USAGE line of a help template, "mc", the command name and
+// the [ARGS...] placeholder for flags are left out
+func getArgRegistry(name, template string) [][]argRegistry {
+	usages := [][]argRegistry{}
+	lines := strings.Split(template, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "USAGE:" {
+			continue
+		}
+		for _, usage := range lines[i+1:] {
+			fields := strings.Fields(templateAction.ReplaceAllString(strings.Replace(usage, "{{.Name}}", name, -1), " "))
+			if len(fields) < 2 || fields[0] != "mc" || fields[1] != name {
+				break
+			}
+			args := []argRegistry{}
+			optional := false // inside of an optional group like [ACCESSKEY SECRETKEY]
+			for _, field := range fields[2:] {
+				if field == "[ARGS...]" {
+					continue
+				}
+				arg := argRegistry{Name: field, Optional: optional}
+				if strings.HasPrefix(arg.Name, "[") {
+					arg.Name, arg.Optional, optional = arg.Name[1:], true, true
+				}
+				if strings.HasSuffix(arg.Name, "]") {
+					arg.Name, optional = arg.Name[:len(arg.Name)-1], false
+				}
+				if strings.HasSuffix(arg.Name, "...") {
+					arg.Name, arg.Repeated = strings.TrimSuffix(arg.Name, "..."), true
+				}
+				arg.Literal = strings.ToUpper(arg.Name) != arg.Name
+				// SOURCE [SOURCE...] is one argument given once or more
+				if last := len(args) - 1; last >= 0 && arg.Optional && arg.Repeated && args[last].Name == arg.Name && !args[last].Optional {
+					args[last].Repeated = true
+					continue
+				}
+				args = append(args, arg)
+			}
+			usages = append(usages, args)
+		}
+		break
+	}
+	return usages
+}
+
+// getRegistry - all commands and flags of this build, hidden ones included
+func getRegistry() RegistryMessage {
+	registry := RegistryMessage{
+		Release:     Version,
+		GlobalFlags: []flagRegistry{},
+		Commands:    []commandRegistry{},
+	}
+	for _, flag := range flags {
+		registry.GlobalFlags = append(registry.GlobalFlags, getFlagRegistry(flag))
+	}
+	for _, cmd := range commands {
+		command := commandRegistry{
+			Name:        cmd.Name,
+			Aliases:     cmd.Aliases,
+			Usage:       cmd.Usage,
+			Description: cmd.Description,
+			Hidden:      cmd.Hide,
+			Flags:       []flagRegistry{},
+			Args:        getArgRegistry(cmd.Name, cmd.CustomHelpTemplate),
+		}
+		for _, flag := range cmd.Flags {
+			command.Flags = append(command.Flags, getFlagRegistry(flag))
+		}
+		registry.Commands = append(registry.Commands, command)
+	}
+	sort.Sort(byRegistryName(registry.Commands))
+	return registry
+}
+
+type byRegistryName []commandRegistry
+
+func (b byRegistryName) Len() int           { return len(b) }
+func (b byRegistryName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byRegistryName) Less(i, j int) bool { return b[i].Name < b[j].Name }
+
+// runRegistryCmd - plain JSON on stdout without theme, like --capabilities
+func runRegistryCmd(ctx *cli.Context) {
+	if ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "registry", exitUsage) // last argument is exit code
+	}
+	fmt.Print(getRegistry())
+}
@@ -0,0 +1,147 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var rmCmd = cli.Command{
+	Name:   "rm",
+	Usage:  "Remove objects, files or incomplete uploads",
+	Action: runRemoveCmd,
+	Flags:  []cli.Flag{incompleteFlag},
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} TARGET [TARGET...] {{if .Description}}
+
+DESCRIPTION:
+   {{.Description}}{{end}}{{if .Flags}}
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}{{ end }}
+
+EXAMPLES:
+   1. Remove an object on Amazon S3 object storage.
+      $ mc {{.Name}} https://s3.amazonaws.com/jukebox/bach.ogg
+
+   2. Remove all objects under a prefix on Minio object storage, recursive removal needs --force.
+      $ mc --force {{.Name}} https://play.minio.io:9000/backup/2006-Jan-1/...
+
+   3. Abort the incomplete upload of an object on Amazon S3 object storage.
+      $ mc {{.Name}} --incomplete s3:backup/2006-Mar-1/backup.tar.gz
+
+   4. Abort all incomplete uploads of a bucket, freeing the space their parts use.
+      $ mc {{.Name}} --incomplete s3:backup/...
+`,
+}
+
+// runRemoveCmd - is a handler for mc rm command
+func runRemoveCmd(ctx *cli.Context) {
+	if !ctx.Args().Present() || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "rm", exitUsage) // last argument is exit code
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	config := mustGetMcConfig()
+	incomplete := ctx.Bool("incomplete")
+	for _, arg := range ctx.Args() {
+		targetURL, err := getExpandedURL(arg, config.Aliases)
+		if err != nil {
+			switch e := iodine.ToError(err).(type) {
+			case errUnsupportedScheme:
+				console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+			default:
+				console.Fatalf("Unable to parse argument %s. %s\n", arg, err)
+			}
+		}
+		recursive := isURLRecursive(targetURL)
+		// incomplete uploads hold no data worth keeping, only removing objects recursively is guarded
+		if recursive && !incomplete && !globalForceFlag {
+			console.Fatalf("Removing ‘%s’ removes every object under it, please use --force to proceed. %s\n", targetURL, errInvalidArgument{})
+		}
+		if err := doRemoveCmd(stripRecursiveURL(targetURL), recursive, incomplete); err != nil {
+			console.Fatalf("Failed to remove : %s. %s\n", targetURL, err)
+		}
+	}
+}
+
+// doRemoveCmd - remove the target, all objects under it if recursive, or its incomplete uploads
+func doRemoveCmd(targetURL string, recursive, incomplete bool) error {
+	clnt, err := target2Client(targetURL)
+	if err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	if incomplete {
+		remover, ok := clnt.(client.IncompleteRemover)
+		if !ok {
+			return NewIodine(iodine.New(errIncompleteNotSupported{URL: targetURL}, nil))
+		}
+		if err := remover.RemoveIncomplete(recursive); err != nil {
+			return NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+		}
+		console.Print(RemoveMessage{Target: targetURL, Incomplete: true})
+		return nil
+	}
+	if !recursive {
+		return doRemove(clnt)
+	}
+	for contentCh := range clnt.List(true) {
+		if contentCh.Err != nil {
+			return NewIodine(iodine.New(contentCh.Err, map[string]string{"Target": targetURL}))
+		}
+		// folders of the filesystem are left in place, objects carry no folders
+		if !contentCh.Content.Type.IsRegular() {
+			continue
+		}
+		objectURL := listedURL(clnt, contentCh.Content, true, false)
+		objectClnt, err := target2Client(objectURL)
+		if err != nil {
+			return NewIodine(iodine.New(err, map[string]string{"Target": objectURL}))
+		}
+		if err := doRemove(objectClnt); err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+	}
+	return nil
+}
+
+// doRemove - wrapper around Remove() API
+func doRemove(clnt client.Client) error {
+	remover, ok := clnt.(client.Remover)
+	if !ok {
+		return NewIodine(iodine.New(errRemoveNotSupported{URL: clnt.URL().String()}, nil))
+	}
+	start := time.Now()
+	err := remover.Remove()
+	console.RecordOperation(console.Operation{Command: "rm", Target: clnt.URL().String(), Duration: time.Since(start), Err: err})
+	if err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"Target": clnt.URL().String()}))
+	}
+	console.Print(RemoveMessage{Target: clnt.URL().String()})
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Sessions started while the configuration file is encrypted are encrypted with its passphrase as well.
+// URLs and paths of the header are sealed like secrets of the configuration file, records of session data
+// as a whole. Times, counters and flags stay readable, so sessions can be listed by age and pruned
+// without the passphrase.
+
+// isSessionEncryptionEnabled - new sessions are encrypted if the configuration file is
+func isSessionEncryptionEnabled() bool {
+	if !isMcConfigExists() {
+		return false
+	}
+	conf, err := getMcConfig()
+	return err == nil && conf.Encrypted
+}
+
+// sessionSecretValues - URLs and paths of header
+func sessionSecretValues(header *sessionV2Header) []*string {
+	values := []*string{&header.RootPath, &header.LastCopied, &header.Manifest, &header.Tags, &header.SetTags}
+	for i := range header.CommandArgs {
+		values = append(values, &header.CommandArgs[i])
+	}
+	return values
+}
+
+// encryptedSessionHeader - copy of header with URLs and paths sealed, for writing to the session file
+func encryptedSessionHeader(header *sessionV2Header, passphrase string) (*sessionV2Header, error) {
+	copied := *header
+	copied.CommandArgs = append([]string(nil), header.CommandArgs...)
+	for _, value := range sessionSecretValues(&copied) {
+		if *value == "" || strings.HasPrefix(*value, encryptedValuePrefix) {
+			continue
+		}
+		sealed, err := sealConfigValue(*value, passphrase)
+		if err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+		*value = sealed
+	}
+	return &copied, nil
+}
+
+// Decrypt opens the header of an encrypted session in place, the passphrase is prompted on first use.
+// Session data is opened record by record while scanning.
+func (s *sessionV2) Decrypt() error {
+	if !s.Header.Encrypted || s.passphrase != "" {
+		return nil
+	}
+	passphrase, err := getPassphrase(false)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	for _, value := range sessionSecretValues(s.Header) {
+		if !strings.HasPrefix(*value, encryptedValuePrefix) {
+			continue
+		}
+		plain, err := openConfigValue(*value, passphrase)
+		if err != nil {
+			return NewIodine(iodine.New(err, map[string]string{"SessionID": s.SessionID}))
+		}
+		*value = plain
+	}
+	s.passphrase = passphrase
+	return nil
+}
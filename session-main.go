@@ -20,6 +20,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/console"
@@ -31,6 +32,7 @@ var sessionCmd = cli.Command{
 	Name:   "session",
 	Usage:  "Manage sessions for cp and sync",
 	Action: runSessionCmd,
+	Flags:  []cli.Flag{olderThanFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
@@ -45,15 +47,28 @@ FLAGS:
    {{end}}{{ end }}
 
 EXAMPLES:
-   1. List sessions
+   1. List sessions with their status and progress
       $ mc {{.Name}} list
 
-   2. Resume session
+   2. List sessions started more than a week ago.
+      $ mc {{.Name}} list --older-than 7d
+
+   3. Resume session
       $ mc {{.Name}} resume [SESSION]
 
-   3. Clear session
+   4. Clear session, partially copied files of its interrupted copies are removed too
       $ mc {{.Name}} clear [SESSION]|[all]
 
+   5. Clear all sessions started more than a week ago.
+      $ mc {{.Name}} clear all --older-than 7d
+
+   6. Remove sessions older than 30 days and report space used by the remaining sessions.
+      $ mc {{.Name}} prune --older-than 30d
+
+   7. Pause a running session after its in-flight copies complete, resume it later.
+      $ mc {{.Name}} pause [SESSION]
+      $ mc {{.Name}} resume [SESSION]
+
 `,
 }
 
@@ -64,13 +79,22 @@ func (b bySessionWhen) Len() int           { return len(b) }
 func (b bySessionWhen) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
 func (b bySessionWhen) Less(i, j int) bool { return b[i].Header.When.Before(b[j].Header.When) }
 
-func listSessions() error {
+// listSessions - print sessions started before now - olderThan, all of them if olderThan is 0
+func listSessions(olderThan time.Duration) error {
 	var bySessions []*sessionV2
+	expiry := globalClock.Now().Add(-olderThan)
 	for _, sid := range getSessionIDs() {
 		s, err := loadSessionV2(sid)
 		if err != nil {
 			return NewIodine(iodine.New(err, nil))
 		}
+		s.DataFP.Close()
+		if olderThan > 0 && !s.Header.When.Before(expiry) {
+			continue
+		}
+		if err := s.Decrypt(); err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
 		bySessions = append(bySessions, s)
 	}
 	// sort sessions based on time
@@ -81,27 +105,54 @@ func listSessions() error {
 	return nil
 }
 
-func clearSession(sid string) {
-	if sid == "all" {
-		for _, sid := range getSessionIDs() {
-			session, err := loadSessionV2(sid)
-			if err != nil {
-				console.Fatalf("Unable to load session ‘%s’, %s", sid, NewIodine(iodine.New(err, nil)))
-			}
-			session.Close()
-		}
-		return
+// pauseSession - signal the process running a session to pause
+func pauseSession(sid string) error {
+	s, err := loadSessionV2(sid)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	defer s.DataFP.Close()
+	if s.Header.PID == 0 || !isProcessRunning(s.Header.PID) {
+		return NewIodine(iodine.New(errSessionNotRunning{id: sid}, nil))
+	}
+	if err := pauseProcess(s.Header.PID); err != nil {
+		return NewIodine(iodine.New(err, nil))
 	}
+	return nil
+}
 
-	if !isSession(sid) {
-		console.Fatalf("Session ‘%s’ not found.\n", sid)
+// resumeSession - continue a loaded session from its working directory, sessions with failed copies are kept
+func resumeSession(s *sessionV2) {
+	if err := s.checkData(); err != nil {
+		console.Fatalf("Unable to read session ‘%s’. %s\n", s.SessionID, err)
+	}
+	if err := s.Decrypt(); err != nil {
+		console.Fatalf("Unable to decrypt session ‘%s’. %s\n", s.SessionID, err)
+	}
+
+	savedCwd, err := os.Getwd()
+	if err != nil {
+		console.Fatalf("Unable to verify your current working directory. %s\n", err)
+	}
+	if s.Header.RootPath != "" {
+		// chdir to RootPath
+		os.Chdir(s.Header.RootPath)
 	}
 
-	session, err := loadSessionV2(sid)
+	console.Logf(console.LogSession, console.LogInfo, "Resuming session ‘%s’, %d of %d objects and %d of %d bytes copied.\n",
+		s.SessionID, s.Header.CopiedObjects, s.Header.TotalObjects, s.Header.CopiedBytes, s.Header.TotalBytes)
+	sessionExecute(s)
+	if s.Header.Failed > 0 { // Keep the session to retry failed copies again.
+		s.Fail()
+		os.Exit(exitPartialFailure)
+	}
+	err = s.Close()
 	if err != nil {
-		console.Fatalf("Unable to load session ‘%s’, %s", sid, NewIodine(iodine.New(err, nil)))
+		console.Fatalf("Unable to close session file properly. %s\n", err)
 	}
-	session.Close()
+
+	// change dir back
+	os.Chdir(savedCwd)
 }
 
 func sessionExecute(s *sessionV2) {
@@ -115,10 +166,10 @@ func sessionExecute(s *sessionV2) {
 
 func runSessionCmd(ctx *cli.Context) {
 	if len(ctx.Args()) < 1 || ctx.Args().First() == "help" {
-		cli.ShowCommandHelpAndExit(ctx, "session", 1) // last argument is exit code
+		cli.ShowCommandHelpAndExit(ctx, "session", exitUsage) // last argument is exit code
 	}
 	if strings.TrimSpace(ctx.Args().First()) == "" {
-		cli.ShowCommandHelpAndExit(ctx, "session", 1) // last argument is exit code
+		cli.ShowCommandHelpAndExit(ctx, "session", exitUsage) // last argument is exit code
 	}
 	if !isSessionDirExists() {
 		if err := createSessionDir(); err != nil {
@@ -128,16 +179,26 @@ func runSessionCmd(ctx *cli.Context) {
 	switch strings.TrimSpace(ctx.Args().First()) {
 	// list resumable sessions
 	case "list":
-		err := listSessions()
+		if len(ctx.Args().Tail()) != 0 {
+			cli.ShowCommandHelpAndExit(ctx, "session", exitUsage) // last argument is exit code
+		}
+		var olderThan time.Duration
+		if ctx.String("older-than") != "" {
+			var err error
+			if olderThan, err = parseRetention(ctx.String("older-than")); err != nil {
+				console.Fatalf("Invalid age, use days like ‘30d’ or durations like ‘12h’. %s\n", err)
+			}
+		}
+		err := listSessions(olderThan)
 		if err != nil {
 			console.Fatalln(err)
 		}
 	case "resume":
 		if len(ctx.Args().Tail()) != 1 {
-			cli.ShowCommandHelpAndExit(ctx, "session", 1) // last argument is exit code
+			cli.ShowCommandHelpAndExit(ctx, "session", exitUsage) // last argument is exit code
 		}
 		if strings.TrimSpace(ctx.Args().Tail().First()) == "" {
-			cli.ShowCommandHelpAndExit(ctx, "session", 1) // last argument is exit code
+			cli.ShowCommandHelpAndExit(ctx, "session", exitUsage) // last argument is exit code
 		}
 
 		sid := strings.TrimSpace(ctx.Args().Tail().First())
@@ -147,39 +208,85 @@ func runSessionCmd(ctx *cli.Context) {
 			console.Fatalln(errInvalidSessionID{id: sid})
 		}
 
-		s, err := loadSessionV2(sid)
+		s, err := loadResumableSession(sid)
+		if _, ok := iodine.ToError(err).(errSessionRunning); ok {
+			console.Fatalf("Unable to resume session ‘%s’. %s\n", sid, err)
+		}
 		if err != nil {
 			console.Fatalln(errInvalidSessionID{id: sid})
 		}
+		resumeSession(s)
 
-		savedCwd, err := os.Getwd()
-		if err != nil {
-			console.Fatalln("Unable to verify your current working directory. %s\n", err)
+	// purge a requested session, with "all" every session which is not running
+	case "clear":
+		if len(ctx.Args().Tail()) != 1 {
+			cli.ShowCommandHelpAndExit(ctx, "session", exitUsage) // last argument is exit code
 		}
-		if s.Header.RootPath != "" {
-			// chdir to RootPath
-			os.Chdir(s.Header.RootPath)
+		if strings.TrimSpace(ctx.Args().Tail().First()) == "" {
+			cli.ShowCommandHelpAndExit(ctx, "session", exitUsage) // last argument is exit code
+		}
+		sid := strings.TrimSpace(ctx.Args().Tail().First())
+		if sid != "all" {
+			if ctx.String("older-than") != "" {
+				cli.ShowCommandHelpAndExit(ctx, "session", exitUsage) // last argument is exit code
+			}
+			if !isSession(sid) {
+				console.Fatalln(errInvalidSessionID{id: sid})
+			}
+			message, err := clearSession(sid)
+			if err != nil {
+				console.Fatalf("Unable to clear session ‘%s’. %s\n", sid, err)
+			}
+			console.PrintC(message)
+			return
+		}
+		var olderThan time.Duration
+		if ctx.String("older-than") != "" {
+			var err error
+			if olderThan, err = parseRetention(ctx.String("older-than")); err != nil {
+				console.Fatalf("Invalid age, use days like ‘30d’ or durations like ‘12h’. %s\n", err)
+			}
+		}
+		messages, err := clearSessions(olderThan)
+		for _, message := range messages {
+			console.PrintC(message)
 		}
-
-		sessionExecute(s)
-		err = s.Close()
 		if err != nil {
-			console.Fatalln("Unable to close session file properly. %s\n", err)
+			console.Fatalf("Unable to clear sessions. %s\n", err)
 		}
-
-		// change dir back
-		os.Chdir(savedCwd)
-
-	// purge a requested pending session, if "*" purge everything
-	case "clear":
+	// ask the process running a session to pause, it saves the session and exits
+	case "pause":
 		if len(ctx.Args().Tail()) != 1 {
-			cli.ShowCommandHelpAndExit(ctx, "session", 1) // last argument is exit code
+			cli.ShowCommandHelpAndExit(ctx, "session", exitUsage) // last argument is exit code
 		}
-		if strings.TrimSpace(ctx.Args().Tail().First()) == "" {
-			cli.ShowCommandHelpAndExit(ctx, "session", 1) // last argument is exit code
+		sid := strings.TrimSpace(ctx.Args().Tail().First())
+		if !isSession(sid) {
+			console.Fatalln(errInvalidSessionID{id: sid})
+		}
+		if err := pauseSession(sid); err != nil {
+			console.Fatalf("Unable to pause session ‘%s’. %s\n", sid, err)
+		}
+		console.Infoln("Pausing session ‘" + sid + "’ after its in-flight copies complete.")
+	// remove stale sessions, retention is used if no age is given
+	case "prune":
+		if len(ctx.Args().Tail()) != 0 {
+			cli.ShowCommandHelpAndExit(ctx, "session", exitUsage) // last argument is exit code
+		}
+		olderThan, err := getSessionRetention()
+		if ctx.String("older-than") != "" {
+			olderThan, err = parseRetention(ctx.String("older-than"))
+		} else if err == nil && olderThan == 0 {
+			console.Fatalf("Sessions do not expire with MC_SESSION_RETENTION=0, please give an age with --older-than. %s\n", errInvalidArgument{})
+		}
+		if err != nil {
+			console.Fatalf("Invalid age, use days like ‘30d’ or durations like ‘12h’. %s\n", err)
+		}
+		message, err := pruneSessions(olderThan)
+		if err != nil {
+			console.Fatalf("Unable to prune sessions. %s\n", err)
 		}
-		clearSession(strings.TrimSpace(ctx.Args().Tail().First()))
+		console.PrintC(message)
 	default:
-		cli.ShowCommandHelpAndExit(ctx, "session", 1) // last argument is exit code
+		cli.ShowCommandHelpAndExit(ctx, "session", exitUsage) // last argument is exit code
 	}
 }
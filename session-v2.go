@@ -19,11 +19,19 @@
 package main
 
 import (
-	"io"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/mc/pkg/quick"
 	"github.com/minio/minio/pkg/iodine"
@@ -42,39 +50,86 @@ func migrateSessionV1ToV2() {
 }
 
 type sessionV2Header struct {
-	Version      string    `json:"version"`
-	When         time.Time `json:"time"`
-	RootPath     string    `json:"working-directory"`
-	CommandType  string    `json:"command-type"`
-	CommandArgs  []string  `json:"cmd-args"`
-	LastCopied   string    `json:"last-copied"`
-	TotalBytes   int64     `json:"total-bytes"`
-	TotalObjects int       `json:"total-objects"`
+	Version       string        `json:"version"`
+	When          time.Time     `json:"time"`
+	RootPath      string        `json:"working-directory"`
+	CommandType   string        `json:"command-type"`
+	CommandArgs   []string      `json:"cmd-args"`
+	LastCopied    string        `json:"last-copied"`              // last finished entry
+	Copied        int           `json:"copied,omitempty"`         // entries of session data before this index are finished
+	Finished      []int         `json:"finished,omitempty"`       // finished entries after Copied, sorted
+	CopiedBytes   int64         `json:"copied-bytes,omitempty"`   // bytes of all finished entries
+	CopiedObjects int           `json:"copied-objects,omitempty"` // number of all finished entries
+	InFlight      []int         `json:"in-flight,omitempty"`      // entries being copied when saved, their targets may be partially written
+	CastTargets   map[int][]int `json:"cast-targets,omitempty"`   // targets cast by entries which failed for others, by entry index
+	Failed        int           `json:"failed,omitempty"`         // entries which failed in the last run, they are retried on resume
+	Status        string        `json:"status,omitempty"`         // how the last run ended, empty while running
+	Prepared      bool          `json:"prepared,omitempty"`       // session data holds all planned entries, they are not planned again on resume
+	Encrypted     bool          `json:"encrypted,omitempty"`      // URLs and paths are sealed with the passphrase of the configuration file
+	CommandHash   string        `json:"command-hash,omitempty"`   // identical commands have the same hash, see getCommandHash
+	TotalBytes    int64         `json:"total-bytes"`
+	TotalObjects  int           `json:"total-objects"`
+	TargetLock    bool          `json:"target-lock"`
+	NamePolicy    string        `json:"name-policy"`
+	WindowsNames  bool          `json:"windows-names"`
+	Parents       bool          `json:"parents"`
+	Attrs         attrRules     `json:"attrs,omitempty"`
+	Tags          string        `json:"tags,omitempty"`
+	SetTags       string        `json:"set-tags,omitempty"`
+	ACL           string        `json:"acl,omitempty"` // canned ACL set on copied objects
+	Preserve      bool          `json:"preserve,omitempty"`
+	NoMetadata    bool          `json:"no-metadata,omitempty"`
+	NoSniff       bool          `json:"no-sniff,omitempty"`
+	MmapSize      int64         `json:"mmap-size,omitempty"`      // local sources of at least this size are memory mapped, 0 for none
+	ParallelRange int           `json:"parallel-range,omitempty"` // concurrent ranged GETs of large remote sources, 0 for one stream
+	Manifest      string        `json:"manifest,omitempty"`
+	VersionID     string        `json:"version-id,omitempty"` // version of the single source object to copy
+	Rewind        string        `json:"rewind,omitempty"`     // sources are copied as they were at this RFC3339 time
+	PID           int           `json:"pid,omitempty"`        // process running this session, 0 when paused or terminated
 }
 
 type sessionV2 struct {
-	Header    *sessionV2Header
-	SessionID string
-	mutex     *sync.Mutex
-	DataFP    *os.File
-	sigCh     bool
+	Header     *sessionV2Header
+	SessionID  string
+	mutex      *sync.Mutex
+	DataFP     *os.File
+	sigCh      bool
+	started    map[string]int // index in session data of entries being copied, by source URL
+	passphrase string         // of encrypted sessions, empty until Decrypt
+	unsaved    int            // entries finished or failed since the last save
+	savedAt    time.Time      // of the last save
 }
 
+// progress of running sessions is saved after this many finished entries, or this long after the last save,
+// a crash or kill loses no more than that
+const (
+	sessionSaveEntries  = 100
+	sessionSaveInterval = 5 * time.Second
+)
+
 // provides a new session
 func newSessionV2() *sessionV2 {
-	if !isMcConfigExists() {
+	if !isMcConfigAvailable() {
 		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
 	}
 
 	s := &sessionV2{}
 	s.Header = &sessionV2Header{}
-	s.Header.Version = "1.1.0"
+	s.Header.Version = mcCurrentSessionVersion
 	// map of command and files copied
 	s.Header.CommandArgs = nil
-	s.Header.When = time.Now().UTC()
+	s.Header.When = globalClock.Now().UTC()
 	s.mutex = new(sync.Mutex)
+	s.started = make(map[string]int)
 	s.SessionID = newSID(8)
 	var err error
+	if isSessionEncryptionEnabled() {
+		s.passphrase, err = getPassphrase(false)
+		if err != nil {
+			console.Fatalf("Unable to encrypt session. %s\n", NewIodine(iodine.New(err, nil)))
+		}
+		s.Header.Encrypted = true
+	}
 	s.DataFP, err = os.Create(getSessionDataFile(s.SessionID))
 	if err != nil {
 		console.Fatalf("Unable to create session data file \""+getSessionDataFile(s.SessionID)+"\". %s\n", err)
@@ -86,74 +141,319 @@ func (s sessionV2) Info() {
 	console.Infoln("Session terminated. To resume session type ‘mc session resume " + s.SessionID + "’")
 }
 
-// NewDataReader provides reader interface to session data file.
-func (s sessionV2) HasData() bool {
-	if s.Header.LastCopied == "" {
-		return false
+// Session status as shown by ‘mc session list’
+const (
+	sessionRunning     = "running"
+	sessionPaused      = "paused"
+	sessionInterrupted = "interrupted"
+	sessionFailed      = "failed"
+)
+
+// Start records this process as running the session, ‘mc session pause’ signals it
+func (s *sessionV2) Start() error {
+	s.Header.PID = os.Getpid()
+	s.Header.Status = ""
+	s.Header.Failed = 0
+	console.Logf(console.LogSession, console.LogInfo, "Session ‘%s’ started %s %s.\n", s.SessionID, s.Header.CommandType, strings.Join(s.Header.CommandArgs, " "))
+	return s.Save()
+}
+
+// Pause saves the session for a later resume, in-flight copies must be complete
+func (s *sessionV2) Pause() {
+	s.Header.PID = 0
+	s.Header.Status = sessionPaused
+	s.Save()
+	console.Logf(console.LogSession, console.LogInfo, "Session ‘%s’ paused after ‘%s’.\n", s.SessionID, s.Header.LastCopied)
+	console.Infoln("Session paused. To resume session type ‘mc session resume " + s.SessionID + "’")
+}
+
+// Terminate saves the session for a later resume, after an interrupt
+func (s *sessionV2) Terminate() {
+	s.Header.PID = 0
+	s.Header.Status = sessionInterrupted
+	s.Save()
+	console.Logf(console.LogSession, console.LogInfo, "Session ‘%s’ terminated after ‘%s’.\n", s.SessionID, s.Header.LastCopied)
+	s.Info()
+}
+
+// Fail saves the session for a later resume, after some of its copies failed
+func (s *sessionV2) Fail() {
+	s.Header.PID = 0
+	s.Header.Status = sessionFailed
+	s.Save()
+	console.Logf(console.LogSession, console.LogWarn, "Session ‘%s’ finished with %d failed copies.\n", s.SessionID, s.Header.Failed)
+	console.Infoln(fmt.Sprintf("%d copies failed. To retry them type ‘mc session resume %s’", s.Header.Failed, s.SessionID))
+}
+
+// Status tells if the session is running, or how its last run ended. Sessions of processes which died
+// without saving are interrupted too
+func (s sessionV2) Status() string {
+	if s.Header.PID != 0 {
+		if isProcessRunning(s.Header.PID) {
+			return sessionRunning
+		}
+		return sessionInterrupted
 	}
-	return true
+	if s.Header.Status == "" {
+		return sessionInterrupted
+	}
+	return s.Header.Status
 }
 
-// NewDataReader provides reader interface to session data file.
-func (s *sessionV2) NewDataReader() io.Reader {
-	// DataFP is always intitialized, either via new or load functions.
-	s.DataFP.Seek(0, os.SEEK_SET)
-	return io.Reader(s.DataFP)
+// Progress - percentage of bytes copied, sessions with nothing to copy are complete
+func (s sessionV2) Progress() int {
+	if s.Header.TotalBytes == 0 {
+		if s.Header.TotalObjects == 0 || s.Header.CopiedObjects < s.Header.TotalObjects {
+			return 0
+		}
+		return 100
+	}
+	return int(s.Header.CopiedBytes * 100 / s.Header.TotalBytes)
+}
+
+// Summary - totals of the session so far
+func (s sessionV2) Summary() TransferSummaryMessage {
+	return TransferSummaryMessage{
+		SessionID: s.SessionID,
+		Objects:   s.Header.CopiedObjects,
+		Bytes:     s.Header.CopiedBytes,
+		Failed:    s.Header.Failed,
+	}
 }
 
-// NewDataReader provides writer interface to session data file.
-func (s *sessionV2) NewDataWriter() io.Writer {
+// HasData tells if session data was planned before, sessions saved before Prepared was kept have copied
+// at least one entry.
+func (s sessionV2) HasData() bool {
+	return s.Header.Prepared || s.Header.LastCopied != ""
+}
+
+// NewDataScanner provides a scanner of the records in session data file.
+func (s *sessionV2) NewDataScanner() *sessionDataScanner {
 	// DataFP is always intitialized, either via new or load functions.
 	s.DataFP.Seek(0, os.SEEK_SET)
-	return io.Writer(s.DataFP)
+	return &sessionDataScanner{scanner: bufio.NewScanner(s.DataFP), passphrase: s.passphrase}
 }
 
-// save this session
-func (s *sessionV2) Save() error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+// NewDataWriter provides a writer of new session data, which replaces the current one on Commit.
+func (s *sessionV2) NewDataWriter() (*sessionDataWriter, error) {
+	file, err := os.Create(getSessionDataFile(s.SessionID) + ".tmp")
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return &sessionDataWriter{session: s, file: file, writer: bufio.NewWriter(file)}, nil
+}
 
-	err := s.DataFP.Sync()
+// sessionDataWriter - session data is written to a temporary file, renamed into place once complete. A
+// power loss leaves either the old or the new data behind, never a mix of them
+type sessionDataWriter struct {
+	session *sessionV2
+	file    *os.File
+	writer  *bufio.Writer
+}
+
+// Append adds v as JSON record, followed by the checksum of the record
+func (w *sessionDataWriter) Append(v interface{}) error {
+	record, err := json.Marshal(v)
 	if err != nil {
 		return NewIodine(iodine.New(err, nil))
 	}
+	if w.session.Header.Encrypted {
+		sealed, err := sealConfigValue(string(record), w.session.passphrase)
+		if err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+		record = []byte(sealed)
+	}
+	if _, err := fmt.Fprintf(w.writer, "%s\t%08x\n", record, crc32.ChecksumIEEE(record)); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	return nil
+}
 
-	qs, err := quick.New(s.Header)
+// Commit makes the written records the session data
+func (w *sessionDataWriter) Commit() error {
+	err := w.writer.Flush()
+	if err == nil {
+		err = w.file.Sync()
+	}
+	if err != nil {
+		w.file.Close()
+		return NewIodine(iodine.New(err, nil))
+	}
+	if err := w.file.Close(); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	w.session.mutex.Lock()
+	defer w.session.mutex.Unlock()
+	// files which are open can not be replaced on windows
+	w.session.DataFP.Close()
+	dataFile := getSessionDataFile(w.session.SessionID)
+	if err := os.Rename(w.file.Name(), dataFile); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	w.session.DataFP, err = os.Open(dataFile)
 	if err != nil {
 		return NewIodine(iodine.New(err, nil))
 	}
+	w.session.Header.Prepared = true
+	return nil
+}
 
-	return qs.Save(getSessionFile(s.SessionID))
+// sessionDataScanner - records of session data are verified with their checksum. Scanning stops at the
+// first corrupted record, records after it were written last and can be lost after a power loss
+type sessionDataScanner struct {
+	scanner    *bufio.Scanner
+	record     []byte
+	corrupted  bool
+	raw        bool   // records of encrypted sessions are not opened
+	passphrase string // of encrypted sessions
+	err        error
 }
 
-// Close ends this session and removes all associated session files.
-func (s *sessionV2) Close() error {
+// Scan advances to the next valid record
+func (d *sessionDataScanner) Scan() bool {
+	if d.corrupted || d.err != nil || !d.scanner.Scan() {
+		return false
+	}
+	line := d.scanner.Bytes()
+	i := bytes.LastIndex(line, []byte("\t"))
+	if i == -1 {
+		// data of sessions saved before records had checksums
+		var v interface{}
+		if json.Unmarshal(line, &v) != nil {
+			d.corrupted = true
+			return false
+		}
+		d.record = line
+		return true
+	}
+	sum, err := strconv.ParseUint(string(line[i+1:]), 16, 32)
+	if err != nil || uint32(sum) != crc32.ChecksumIEEE(line[:i]) {
+		d.corrupted = true
+		return false
+	}
+	d.record = line[:i]
+	if d.raw || !bytes.HasPrefix(d.record, []byte(encryptedValuePrefix)) {
+		return true
+	}
+	if d.passphrase == "" {
+		d.err = NewIodine(iodine.New(errWrongPassphrase{}, nil))
+		return false
+	}
+	plain, err := openConfigValue(string(d.record), d.passphrase)
+	if err != nil {
+		d.err = NewIodine(iodine.New(err, nil))
+		return false
+	}
+	d.record = []byte(plain)
+	return true
+}
+
+// Bytes is the current record
+func (d *sessionDataScanner) Bytes() []byte {
+	return d.record
+}
+
+// Text is the current record
+func (d *sessionDataScanner) Text() string {
+	return string(d.record)
+}
+
+// Corrupted tells if scanning stopped at a corrupted record
+func (d *sessionDataScanner) Corrupted() bool {
+	return d.corrupted
+}
+
+// Err is the first error reading or opening the session data
+func (d *sessionDataScanner) Err() error {
+	if d.err != nil {
+		return d.err
+	}
+	return d.scanner.Err()
+}
+
+// save this session
+func (s *sessionV2) Save() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	err := s.DataFP.Close()
+	err := s.DataFP.Sync()
 	if err != nil {
 		return NewIodine(iodine.New(err, nil))
 	}
 
-	err = os.Remove(getSessionDataFile(s.SessionID))
+	s.unsaved = 0
+	s.savedAt = globalClock.Now()
+	s.Header.InFlight = nil
+	for _, index := range s.started {
+		s.Header.InFlight = append(s.Header.InFlight, index)
+	}
+	sort.Ints(s.Header.InFlight)
+
+	header := s.Header
+	if s.Header.Encrypted && s.passphrase != "" {
+		header, err = encryptedSessionHeader(s.Header, s.passphrase)
+		if err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+	}
+	qs, err := quick.New(header)
 	if err != nil {
 		return NewIodine(iodine.New(err, nil))
 	}
 
-	err = os.Remove(getSessionFile(s.SessionID))
+	// replaced in one step, a power loss while saving leaves the previous header behind
+	sessionFile := getSessionFile(s.SessionID)
+	if err := qs.Save(sessionFile + ".tmp"); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	if err := syncFile(sessionFile + ".tmp"); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	if err := os.Rename(sessionFile+".tmp", sessionFile); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	console.Logf(console.LogSession, console.LogDebug, "Session ‘%s’ saved, last copied ‘%s’.\n", s.SessionID, s.Header.LastCopied)
+	return nil
+}
+
+// syncFile - flush a file written by others to disk
+func syncFile(path string) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0600)
 	if err != nil {
 		return NewIodine(iodine.New(err, nil))
 	}
+	defer file.Close()
+	if err := file.Sync(); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	return nil
+}
 
+// Close ends this session and removes all associated session files, closing it again does nothing.
+func (s *sessionV2) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.DataFP == nil {
+		return nil
+	}
+	err := s.DataFP.Close()
 	if err != nil {
 		return NewIodine(iodine.New(err, nil))
 	}
+	s.DataFP = nil
+
+	for _, file := range getSessionFiles(s.SessionID) {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return NewIodine(iodine.New(err, nil))
+		}
+	}
 	return nil
 }
 
-// loadSession - reads session file if exists and re-initiates internal variables
-func loadSessionV2(sid string) (*sessionV2, error) {
+// loadSessionV2Header - reads the header of a session alone, its data file is left untouched
+func loadSessionV2Header(sid string) (*sessionV2Header, error) {
 	if !isSessionDirExists() {
 		return nil, NewIodine(iodine.New(errInvalidArgument{}, nil))
 	}
@@ -164,11 +464,9 @@ func loadSessionV2(sid string) (*sessionV2, error) {
 		return nil, NewIodine(iodine.New(err, nil))
 	}
 
-	s := &sessionV2{}
-	s.Header = &sessionV2Header{}
-	s.SessionID = sid
-	s.Header.Version = "1.1.0"
-	qs, err := quick.New(s.Header)
+	header := &sessionV2Header{}
+	header.Version = mcCurrentSessionVersion
+	qs, err := quick.New(header)
 	if err != nil {
 		return nil, NewIodine(iodine.New(err, nil))
 	}
@@ -176,37 +474,173 @@ func loadSessionV2(sid string) (*sessionV2, error) {
 	if err != nil {
 		return nil, NewIodine(iodine.New(err, nil))
 	}
+	return qs.Data().(*sessionV2Header), nil
+}
+
+// loadSession - reads session file if exists and re-initiates internal variables
+func loadSessionV2(sid string) (*sessionV2, error) {
+	header, err := loadSessionV2Header(sid)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
 
+	s := &sessionV2{}
+	s.SessionID = sid
 	s.mutex = new(sync.Mutex)
-	s.Header = qs.Data().(*sessionV2Header)
+	s.started = make(map[string]int)
+	s.Header = header
 
 	s.DataFP, err = os.Open(getSessionDataFile(s.SessionID))
 	if err != nil {
 		console.Fatalf("Unable to open session data file \""+getSessionDataFile(s.SessionID)+"\". %s", NewIodine(iodine.New(errNotConfigured{}, nil)))
 	}
+	// sessions saved before finished entries were counted only know the last copied entry
+	if s.Header.LastCopied != "" && s.Header.CopiedObjects == 0 {
+		if err := s.restoreCopied(); err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+	}
+	console.Logf(console.LogSession, console.LogDebug, "Session ‘%s’ loaded, %d objects, last copied ‘%s’.\n", sid, s.Header.TotalObjects, s.Header.LastCopied)
 
 	return s, nil
 }
 
-// Create a factory function to simplify checking if an
-// object has been copied or not.
-// isCopied(URL) -> true or false
-func isCopiedFactory(lastCopied string) func(string) bool {
-	copied := true // closure
-	return func(sourceURL string) bool {
-		if sourceURL == "" {
-			console.Fatalf("Empty source URL passed to isCopied() function. %s\n", NewIodine(iodine.New(errUnexpected{}, nil)))
+// checkData - warn of records lost at the end of session data, the session is resumed without them. It
+// reads all of session data, only sessions about to be resumed are checked
+func (s *sessionV2) checkData() error {
+	scanner := s.NewDataScanner()
+	scanner.raw = true
+	records := 0
+	for scanner.Scan() {
+		records++
+	}
+	if err := scanner.Err(); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	if scanner.Corrupted() {
+		console.Logf(console.LogSession, console.LogWarn, "Session ‘%s’ data is corrupted after %d records.\n", s.SessionID, records)
+		console.Errorf("Session ‘%s’ data is corrupted after %d of %d records, the remaining objects are skipped.\n",
+			s.SessionID, records, s.Header.TotalObjects)
+	}
+	return nil
+}
+
+// restoreCopied - count all entries of session data up to and including LastCopied as finished
+func (s *sessionV2) restoreCopied() error {
+	scanner := s.NewDataScanner()
+	for index := 0; scanner.Scan(); index++ {
+		var entry struct {
+			SourceContent *client.Content
 		}
-		if lastCopied == "" {
-			return false
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.SourceContent == nil {
+			return NewIodine(iodine.New(errUnexpected{}, map[string]string{"Index": strconv.Itoa(index)}))
 		}
-
-		if copied {
-			if lastCopied == sourceURL {
-				copied = false // from next call onwards we say false.
-			}
-			return true
+		s.Header.Copied = index + 1
+		s.Header.CopiedBytes += entry.SourceContent.Size
+		s.Header.CopiedObjects++
+		if entry.SourceContent.Name == s.Header.LastCopied {
+			return nil
 		}
-		return false
 	}
+	if err := scanner.Err(); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	return NewIodine(iodine.New(errUnexpected{}, map[string]string{"LastCopied": s.Header.LastCopied}))
+}
+
+// Dispatch records that the entry at index of session data is being copied
+func (s *sessionV2) Dispatch(index int, sourceURL string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.started[sourceURL] = index
+}
+
+// Done records a dispatched entry as finished, it is skipped and counted as copied on resume
+func (s *sessionV2) Done(sourceURL string, size int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	index, ok := s.started[sourceURL]
+	if !ok {
+		return
+	}
+	delete(s.started, sourceURL)
+	s.unsaved++
+	delete(s.Header.CastTargets, index)
+	if len(s.Header.CastTargets) == 0 {
+		s.Header.CastTargets = nil
+	}
+	s.Header.LastCopied = sourceURL
+	s.Header.CopiedBytes += size
+	s.Header.CopiedObjects++
+	i := sort.SearchInts(s.Header.Finished, index)
+	s.Header.Finished = append(s.Header.Finished, 0)
+	copy(s.Header.Finished[i+1:], s.Header.Finished[i:])
+	s.Header.Finished[i] = index
+	// entries finish out of order with parallel copies, only a contiguous run moves Copied
+	for len(s.Header.Finished) > 0 && s.Header.Finished[0] == s.Header.Copied {
+		s.Header.Finished = s.Header.Finished[1:]
+		s.Header.Copied++
+	}
+	if len(s.Header.Finished) == 0 {
+		s.Header.Finished = nil
+	}
+}
+
+// TargetsDone records targets of a dispatched cast entry as finished, before the entry is recorded as failed
+// for its other targets. targets are indices in the planned targets of the entry, they are not cast again on resume
+func (s *sessionV2) TargetsDone(sourceURL string, targets []int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	index, ok := s.started[sourceURL]
+	if !ok || len(targets) == 0 {
+		return
+	}
+	if s.Header.CastTargets == nil {
+		s.Header.CastTargets = make(map[int][]int)
+	}
+	done := append(s.Header.CastTargets[index], targets...)
+	sort.Ints(done)
+	s.Header.CastTargets[index] = done
+}
+
+// DoneTargets tells the planned targets of the cast entry at index of session data which were finished before
+func (s *sessionV2) DoneTargets(index int) []int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.Header.CastTargets[index]
+}
+
+// Failed records a dispatched entry as failed, it is retried on resume
+func (s *sessionV2) Failed(sourceURL string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.started, sourceURL)
+	s.unsaved++
+	s.Header.Failed++
+}
+
+// Checkpoint saves the progress of a running session every sessionSaveEntries finished entries or every
+// sessionSaveInterval, whichever comes first. Finished entries saved are not copied again after a crash
+func (s *sessionV2) Checkpoint() error {
+	s.mutex.Lock()
+	due := s.unsaved >= sessionSaveEntries || (s.unsaved > 0 && globalClock.Now().Sub(s.savedAt) >= sessionSaveInterval)
+	if due { // claimed, concurrent callers do not save again
+		s.unsaved = 0
+	}
+	s.mutex.Unlock()
+	if !due {
+		return nil
+	}
+	return s.Save()
+}
+
+// IsDone tells if the entry at index of session data was finished before
+func (s *sessionV2) IsDone(index int) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if index < s.Header.Copied {
+		return true
+	}
+	i := sort.SearchInts(s.Header.Finished, index)
+	return i < len(s.Header.Finished) && s.Header.Finished[i] == index
 }
@@ -17,12 +17,19 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/minio/pkg/iodine"
 )
@@ -30,6 +37,8 @@ import (
 func migrateSession() {
 	// Migrate session V1 to V2
 	migrateSessionV1ToV2()
+	// Migrate older headers of session V2
+	migrateSessionHeaders()
 }
 
 func isSessionDirExists() bool {
@@ -95,3 +104,307 @@ func isSession(sid string) bool {
 
 	return true
 }
+
+// defaultSessionRetention - sessions older than this are removed automatically, MC_SESSION_RETENTION overrides it
+const defaultSessionRetention = 30 * 24 * time.Hour
+
+// parseRetention - parse durations like "30d", days are not understood by time.ParseDuration
+func parseRetention(retention string) (time.Duration, error) {
+	if strings.HasSuffix(retention, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(retention, "d"))
+		if err != nil || days < 0 {
+			return 0, NewIodine(iodine.New(errInvalidArgument{}, map[string]string{"Retention": retention}))
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(retention)
+	if err != nil || duration < 0 {
+		return 0, NewIodine(iodine.New(errInvalidArgument{}, map[string]string{"Retention": retention}))
+	}
+	return duration, nil
+}
+
+// getSessionRetention - retention from MC_SESSION_RETENTION, "0" disables automatic expiry
+func getSessionRetention() (time.Duration, error) {
+	retention := os.Getenv("MC_SESSION_RETENTION")
+	if retention == "" {
+		return defaultSessionRetention, nil
+	}
+	return parseRetention(retention)
+}
+
+// getSessionFiles - header and data files of a session, along with their temporary files while being written
+func getSessionFiles(sid string) []string {
+	return []string{getSessionFile(sid), getSessionDataFile(sid), getSessionFile(sid) + ".tmp", getSessionDataFile(sid) + ".tmp"}
+}
+
+// getSessionSize - bytes used by session header and data files
+func getSessionSize(sid string) int64 {
+	var size int64
+	for _, file := range getSessionFiles(sid) {
+		if fi, err := os.Stat(file); err == nil {
+			size += fi.Size()
+		}
+	}
+	return size
+}
+
+// getSessionStart - start of a session and if its process is running, read from its header alone.
+// Sessions whose header cannot be read started when their header file was last written
+func getSessionStart(sid string) (time.Time, bool) {
+	header, err := loadSessionV2Header(sid)
+	if err == nil {
+		return header.When, header.PID != 0 && isProcessRunning(header.PID)
+	}
+	fi, err := os.Stat(getSessionFile(sid))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return fi.ModTime(), false
+}
+
+// pruneSessions - remove sessions started before now - olderThan, running sessions are left alone. Session
+// data is never read, pruning has to stay cheap and quiet as it runs along with copies
+func pruneSessions(olderThan time.Duration) (SessionPruneMessage, error) {
+	var message SessionPruneMessage
+	if !isSessionDirExists() {
+		return message, nil
+	}
+	expiry := globalClock.Now().Add(-olderThan)
+	for _, sid := range getSessionIDs() {
+		size := getSessionSize(sid)
+		if when, running := getSessionStart(sid); running || !when.Before(expiry) {
+			message.Remaining++
+			message.RemainingSize += size
+			continue
+		}
+		for _, file := range getSessionFiles(sid) {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return message, NewIodine(iodine.New(err, nil))
+			}
+		}
+		message.Removed++
+		message.RemovedSize += size
+	}
+	return message, nil
+}
+
+// getPartialTargets - local files written by copies in flight when the session was saved, remote targets
+// are never visible before their upload completes
+func getPartialTargets(s *sessionV2) ([]string, error) {
+	if len(s.Header.InFlight) == 0 {
+		return nil, nil
+	}
+	var partials []string
+	scanner := s.NewDataScanner()
+	for index := 0; scanner.Scan(); index++ {
+		i := sort.SearchInts(s.Header.InFlight, index)
+		if i == len(s.Header.InFlight) || s.Header.InFlight[i] != index {
+			continue
+		}
+		// entries of cp have one target, entries of cast several
+		var entry struct {
+			TargetContent  *client.Content
+			TargetContents []*client.Content
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+		targets := entry.TargetContents
+		if entry.TargetContent != nil {
+			targets = append(targets, entry.TargetContent)
+		}
+		for _, target := range targets {
+			u, err := client.Parse(target.Name)
+			if err != nil || u.Type != client.Filesystem {
+				continue
+			}
+			path := target.Name
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(s.Header.RootPath, path)
+			}
+			partials = append(partials, path)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return partials, nil
+}
+
+// loadResumableSession - load a session to resume, sessions still running in another process are refused so
+// that two processes never copy the same session
+func loadResumableSession(sid string) (*sessionV2, error) {
+	s, err := loadSessionV2(sid)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if s.Header.PID != 0 && isProcessRunning(s.Header.PID) {
+		s.DataFP.Close()
+		return nil, NewIodine(iodine.New(errSessionRunning{id: sid}, nil))
+	}
+	return s, nil
+}
+
+// clearSession - remove a session which is not running along with partial files of its interrupted copies
+func clearSession(sid string) (SessionClearMessage, error) {
+	message := SessionClearMessage{SessionID: sid}
+	s, err := loadSessionV2(sid)
+	if err != nil {
+		return message, NewIodine(iodine.New(err, nil))
+	}
+	if s.Header.PID != 0 && isProcessRunning(s.Header.PID) {
+		s.DataFP.Close()
+		return message, NewIodine(iodine.New(errSessionRunning{id: sid}, nil))
+	}
+	if err := s.Decrypt(); err != nil {
+		s.DataFP.Close()
+		return message, NewIodine(iodine.New(err, nil))
+	}
+	partials, err := getPartialTargets(s)
+	if err != nil {
+		s.DataFP.Close()
+		return message, NewIodine(iodine.New(err, nil))
+	}
+	for _, path := range partials {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			s.DataFP.Close()
+			return message, NewIodine(iodine.New(err, nil))
+		}
+		message.PartialFiles = append(message.PartialFiles, path)
+	}
+	if err := s.Close(); err != nil {
+		return message, NewIodine(iodine.New(err, nil))
+	}
+	return message, nil
+}
+
+// clearSessions - clear all sessions started before now - olderThan, running sessions are left alone
+func clearSessions(olderThan time.Duration) ([]SessionClearMessage, error) {
+	var messages []SessionClearMessage
+	expiry := globalClock.Now().Add(-olderThan)
+	for _, sid := range getSessionIDs() {
+		s, err := loadSessionV2(sid)
+		if err != nil {
+			return messages, NewIodine(iodine.New(err, nil))
+		}
+		s.DataFP.Close()
+		if !s.Header.When.Before(expiry) || (s.Header.PID != 0 && isProcessRunning(s.Header.PID)) {
+			continue
+		}
+		message, err := clearSession(sid)
+		if err != nil {
+			return messages, NewIodine(iodine.New(err, nil))
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// getCommandHash - SHA-256 of command, working directory, arguments and flags of header
+func getCommandHash(header *sessionV2Header) string {
+	command := struct {
+		CommandType   string
+		RootPath      string
+		CommandArgs   []string
+		TargetLock    bool
+		NamePolicy    string
+		WindowsNames  bool
+		Parents       bool
+		Attrs         attrRules
+		Tags          string
+		SetTags       string
+		ACL           string
+		Preserve      bool
+		NoMetadata    bool
+		NoSniff       bool
+		MmapSize      int64
+		ParallelRange int
+		Manifest      string
+		VersionID     string
+		Rewind        string
+	}{header.CommandType, header.RootPath, header.CommandArgs, header.TargetLock, header.NamePolicy, header.WindowsNames,
+		header.Parents, header.Attrs, header.Tags, header.SetTags, header.ACL, header.Preserve, header.NoMetadata, header.NoSniff, header.MmapSize,
+		header.ParallelRange, header.Manifest, header.VersionID, header.Rewind}
+	data, err := json.Marshal(command)
+	if err != nil {
+		panic(err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// findInterruptedSession - the latest session of the command with hash which is not running, nil if there is none.
+// Only headers are read to find it, sessions whose header cannot be read are not resumable
+func findInterruptedSession(hash, excludeSID string) (*sessionV2, error) {
+	var latest *sessionV2Header
+	var latestSID string
+	for _, sid := range getSessionIDs() {
+		if sid == excludeSID {
+			continue
+		}
+		header, err := loadSessionV2Header(sid)
+		if err != nil {
+			continue
+		}
+		if header.CommandHash != hash || (sessionV2{Header: header}).Status() == sessionRunning ||
+			(latest != nil && header.When.Before(latest.When)) {
+			continue
+		}
+		latest, latestSID = header, sid
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	s, err := loadSessionV2(latestSID)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return s, nil
+}
+
+// resumeInterruptedSession - resume an interrupted session of the command of session instead of starting it
+// over, asks first unless yes is set. Returns false if session is to be run
+func resumeInterruptedSession(session *sessionV2, yes, noResume bool) bool {
+	if noResume {
+		return false
+	}
+	previous, err := findInterruptedSession(session.Header.CommandHash, session.SessionID)
+	if err != nil {
+		session.Close()
+		console.Fatalf("Unable to look for interrupted sessions. %s\n", err)
+	}
+	if previous == nil {
+		return false
+	}
+	if !yes {
+		prompt := fmt.Sprintf("Resume previous session ‘%s’ of %s, %d%% copied? [Y/n] ", previous.SessionID,
+			previous.Header.When.Local().Format(printDate), previous.Progress())
+		if yes, err = console.Confirm(prompt, true); err != nil {
+			session.Close()
+			console.Fatalf("Unable to read answer. %s\n", err)
+		}
+	}
+	if !yes {
+		previous.DataFP.Close()
+		return false
+	}
+	session.Close()
+	resumeSession(previous)
+	return true
+}
+
+// expireSessions - remove sessions older than the configured retention, done by commands starting a session
+func expireSessions() {
+	retention, err := getSessionRetention()
+	if err != nil {
+		console.Errorf("Invalid MC_SESSION_RETENTION, sessions are not expired. %s\n", err)
+		return
+	}
+	if retention == 0 {
+		return
+	}
+	if _, err := pruneSessions(retention); err != nil {
+		console.Errorf("Unable to remove expired sessions. %s\n", err)
+	}
+}
@@ -17,11 +17,28 @@
 package main
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
 	. "gopkg.in/check.v1"
 )
 
+// fixedClock - always reports the same time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
 func (s *CmdTestSuite) TestValidSessionID(c *C) {
 	validSid := regexp.MustCompile("^[a-zA-Z]+$")
 	sid := newSID(8)
@@ -34,9 +51,14 @@ func (s *CmdTestSuite) TestSession(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(isSessionDirExists(), Equals, true)
 
+	when := time.Date(2015, time.June, 24, 10, 30, 0, 0, time.UTC)
+	globalClock = fixedClock(when)
+	defer func() { globalClock = systemClock{} }()
+
 	session := newSessionV2()
 	c.Assert(session.Header.CommandArgs, IsNil)
 	c.Assert(len(session.SessionID), Equals, 8)
+	c.Assert(session.Header.When, Equals, when)
 
 	err = session.Save()
 	c.Assert(err, IsNil)
@@ -44,7 +66,398 @@ func (s *CmdTestSuite) TestSession(c *C) {
 	savedSession, err := loadSessionV2(session.SessionID)
 	c.Assert(err, IsNil)
 	c.Assert(session.SessionID, Equals, savedSession.SessionID)
+	c.Assert(savedSession.Header.When.Equal(when), Equals, true)
 
 	err = session.Close()
 	c.Assert(err, IsNil)
 }
+
+func (s *CmdTestSuite) TestPruneSessions(c *C) {
+	err := createSessionDir()
+	c.Assert(err, IsNil)
+
+	retention, err := parseRetention("30d")
+	c.Assert(err, IsNil)
+	c.Assert(retention, Equals, 30*24*time.Hour)
+	retention, err = parseRetention("12h")
+	c.Assert(err, IsNil)
+	c.Assert(retention, Equals, 12*time.Hour)
+	_, err = parseRetention("-1d")
+	c.Assert(err, Not(IsNil))
+	_, err = parseRetention("month")
+	c.Assert(err, Not(IsNil))
+
+	now := time.Date(2015, time.July, 30, 10, 30, 0, 0, time.UTC)
+	defer func() { globalClock = systemClock{} }()
+
+	globalClock = fixedClock(now.Add(-40 * 24 * time.Hour))
+	stale := newSessionV2()
+	c.Assert(stale.Save(), IsNil)
+	c.Assert(stale.DataFP.Close(), IsNil)
+
+	globalClock = fixedClock(now.Add(-24 * time.Hour))
+	recent := newSessionV2()
+	c.Assert(recent.Save(), IsNil)
+
+	// sessions still running are never removed, however old
+	globalClock = fixedClock(now.Add(-40 * 24 * time.Hour))
+	running := newSessionV2()
+	c.Assert(running.Start(), IsNil)
+
+	// headers which cannot be read are aged by their modification time
+	broken := newSID(8)
+	c.Assert(ioutil.WriteFile(getSessionFile(broken), []byte("{"), 0600), IsNil)
+	c.Assert(os.Chtimes(getSessionFile(broken), now.Add(-40*24*time.Hour), now.Add(-40*24*time.Hour)), IsNil)
+	written := newSID(8)
+	c.Assert(ioutil.WriteFile(getSessionFile(written), []byte("{"), 0600), IsNil)
+	c.Assert(os.Chtimes(getSessionFile(written), now, now), IsNil)
+
+	globalClock = fixedClock(now)
+	message, err := pruneSessions(30 * 24 * time.Hour)
+	c.Assert(err, IsNil)
+	c.Assert(message.Removed >= 2, Equals, true)
+	c.Assert(message.RemovedSize > 0, Equals, true)
+	c.Assert(message.Remaining >= 3, Equals, true)
+	c.Assert(isSession(stale.SessionID), Equals, false)
+	c.Assert(isSession(broken), Equals, false)
+	c.Assert(isSession(recent.SessionID), Equals, true)
+	c.Assert(isSession(running.SessionID), Equals, true)
+	c.Assert(isSession(written), Equals, true)
+
+	c.Assert(recent.Close(), IsNil)
+	c.Assert(running.Close(), IsNil)
+	c.Assert(os.Remove(getSessionFile(written)), IsNil)
+}
+
+func (s *CmdTestSuite) TestPauseSession(c *C) {
+	err := createSessionDir()
+	c.Assert(err, IsNil)
+
+	session := newSessionV2()
+	defer session.Close()
+	c.Assert(session.Start(), IsNil)
+
+	pauseCh := pauseTrap()
+	err = pauseSession(session.SessionID)
+	if runtime.GOOS == "windows" {
+		c.Assert(iodine.ToError(err), FitsTypeOf, errPauseNotSupported{})
+		return
+	}
+	c.Assert(err, IsNil)
+	select {
+	case <-pauseCh:
+	case <-time.After(5 * time.Second):
+		c.Fatal("pause request was not delivered")
+	}
+
+	// paused sessions have no process to signal
+	session.Pause()
+	err = pauseSession(session.SessionID)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errSessionNotRunning{})
+}
+
+func (s *CmdTestSuite) TestTargetLock(c *C) {
+	err := createSessionDir()
+	c.Assert(err, IsNil)
+	err = os.MkdirAll(getLockDir(), 0700)
+	c.Assert(err, IsNil)
+
+	// Simulate a lock held by another live process, pid 1 always exists.
+	target := "http://localhost:9000/bucket/prefix"
+	lockData, err := json.Marshal(targetLock{Version: "1.0.0", PID: 1, Target: target})
+	c.Assert(err, IsNil)
+	err = ioutil.WriteFile(getLockFile(target), lockData, 0600)
+	c.Assert(err, IsNil)
+
+	// Overlapping prefixes are refused while the lock is held.
+	_, err = acquireTargetLock(target + "/...")
+	c.Assert(err, Not(IsNil))
+	_, err = acquireTargetLock(target + "/dir")
+	c.Assert(err, Not(IsNil))
+
+	// Targets sharing a prefix without a separator do not overlap.
+	sibling, err := acquireTargetLock(target + "ion")
+	c.Assert(err, IsNil)
+	c.Assert(sibling.Release(), IsNil)
+	c.Assert(isOverlappingTarget("/tmp/a", "/tmp/ab"), Equals, false)
+	c.Assert(isOverlappingTarget("/tmp/a", "/tmp/a/b"), Equals, true)
+	c.Assert(isOverlappingTarget("C:\\data\\x", "C:\\data"), Equals, true)
+	c.Assert(normalizeLockTarget("play:bucket/x/..."), Equals, "https://play.minio.io:9000/bucket/x")
+	c.Assert(normalizeLockTarget("https://play.minio.io:9000/bucket/x/"), Equals, "https://play.minio.io:9000/bucket/x")
+	// local targets are locked by absolute path whatever the working directory
+	cwd, err := os.Getwd()
+	c.Assert(err, IsNil)
+	c.Assert(normalizeLockTarget(filepath.Join(".", "dir")+string(filepath.Separator)), Equals, filepath.Join(cwd, "dir"))
+	c.Assert(normalizeLockTarget(filepath.Join(cwd, "dir")), Equals, filepath.Join(cwd, "dir"))
+
+	other, err := acquireTargetLock("http://localhost:9000/other")
+	c.Assert(err, IsNil)
+	c.Assert(other.Target, Equals, "http://localhost:9000/other")
+	c.Assert(other.Release(), IsNil)
+
+	// Locks of dead processes are taken over.
+	lockData, err = json.Marshal(targetLock{Version: "1.0.0", PID: -1, Target: target})
+	c.Assert(err, IsNil)
+	err = ioutil.WriteFile(getLockFile(target), lockData, 0600)
+	c.Assert(err, IsNil)
+
+	lock, err := acquireTargetLock(target + "/dir")
+	c.Assert(err, IsNil)
+	c.Assert(lock.Release(), IsNil)
+
+	// Locks which cannot be read are held until they were left alone for a while.
+	c.Assert(ioutil.WriteFile(getLockFile(target), []byte(`{"pid":`), 0600), IsNil)
+	_, err = acquireTargetLock(target)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errTargetLocked{})
+	old := time.Now().Add(-2 * unreadableLockAge)
+	c.Assert(os.Chtimes(getLockFile(target), old, old), IsNil)
+	lock, err = acquireTargetLock(target)
+	c.Assert(err, IsNil)
+
+	// A stale lock replaced by another process meanwhile is put back.
+	c.Assert(breakStaleLock(lock.path, []byte(`{"pid":`)), IsNil)
+	current, err := loadTargetLock(lock.path)
+	c.Assert(err, IsNil)
+	c.Assert(current.PID, Equals, os.Getpid())
+	c.Assert(lock.Release(), IsNil)
+	leftovers, err := filepath.Glob(filepath.Join(getLockDir(), "*"))
+	c.Assert(err, IsNil)
+	for _, leftover := range leftovers {
+		c.Assert(strings.HasSuffix(leftover, ".lock"), Equals, true, Commentf("%s was left behind", leftover))
+	}
+}
+
+func (s *CmdTestSuite) TestClearSessions(c *C) {
+	err := createSessionDir()
+	c.Assert(err, IsNil)
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	now := time.Date(2015, time.July, 30, 10, 30, 0, 0, time.UTC)
+	defer func() { globalClock = systemClock{} }()
+
+	// interrupted while the second entry was copied to a local folder
+	globalClock = fixedClock(now.Add(-10 * 24 * time.Hour))
+	stale := newSessionV2()
+	stale.Header.RootPath = root
+	dataWriter, err := stale.NewDataWriter()
+	c.Assert(err, IsNil)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		c.Assert(dataWriter.Append(copyURLs{
+			SourceContent: &client.Content{Name: "https://s3.amazonaws.com/bucket/" + name},
+			TargetContent: &client.Content{Name: name},
+		}), IsNil)
+	}
+	c.Assert(dataWriter.Commit(), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "a.txt"), []byte("alpha"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "b.txt"), []byte("br"), 0600), IsNil)
+	stale.Dispatch(0, "https://s3.amazonaws.com/bucket/a.txt")
+	stale.Done("https://s3.amazonaws.com/bucket/a.txt", 5)
+	stale.Dispatch(1, "https://s3.amazonaws.com/bucket/b.txt")
+	c.Assert(stale.Save(), IsNil)
+	c.Assert(stale.Header.InFlight, DeepEquals, []int{1})
+	c.Assert(stale.DataFP.Close(), IsNil)
+
+	running := newSessionV2()
+	defer running.Close()
+	c.Assert(running.Start(), IsNil)
+
+	globalClock = fixedClock(now.Add(-24 * time.Hour))
+	recent := newSessionV2()
+	defer recent.Close()
+	c.Assert(recent.Save(), IsNil)
+
+	globalClock = fixedClock(now)
+	messages, err := clearSessions(7 * 24 * time.Hour)
+	c.Assert(err, IsNil)
+	c.Assert(messages, DeepEquals, []SessionClearMessage{{SessionID: stale.SessionID, PartialFiles: []string{filepath.Join(root, "b.txt")}}})
+	c.Assert(isSession(stale.SessionID), Equals, false)
+	c.Assert(isSession(running.SessionID), Equals, true)
+	c.Assert(isSession(recent.SessionID), Equals, true)
+	_, err = os.Stat(filepath.Join(root, "a.txt"))
+	c.Assert(err, IsNil)
+	_, err = os.Stat(filepath.Join(root, "b.txt"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = clearSession(running.SessionID)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errSessionRunning{})
+	// a running session is not resumed by a second process
+	_, err = loadResumableSession(running.SessionID)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errSessionRunning{})
+	resumable, err := loadResumableSession(recent.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(resumable.DataFP.Close(), IsNil)
+}
+
+func (s *CmdTestSuite) TestSessionCheckpoint(c *C) {
+	err := createSessionDir()
+	c.Assert(err, IsNil)
+	now := time.Now()
+	globalClock = fixedClock(now)
+	defer func() { globalClock = systemClock{} }()
+
+	session := newSessionV2()
+	defer session.Close()
+	c.Assert(session.Start(), IsNil)
+	loaded := func() *sessionV2Header {
+		header, err := loadSessionV2Header(session.SessionID)
+		c.Assert(err, IsNil)
+		return header
+	}
+
+	// progress is saved after enough finished entries
+	for index := 0; index < sessionSaveEntries; index++ {
+		c.Assert(loaded().CopiedObjects, Equals, 0)
+		session.Dispatch(index, strconv.Itoa(index))
+		session.Done(strconv.Itoa(index), 1)
+		c.Assert(session.Checkpoint(), IsNil)
+	}
+	c.Assert(loaded().Copied, Equals, sessionSaveEntries)
+
+	// or some time after the last save, while the process still runs
+	session.Dispatch(sessionSaveEntries, "last")
+	session.Done("last", 1)
+	c.Assert(session.Checkpoint(), IsNil)
+	c.Assert(loaded().Copied, Equals, sessionSaveEntries)
+	globalClock = fixedClock(now.Add(sessionSaveInterval))
+	c.Assert(session.Checkpoint(), IsNil)
+	header := loaded()
+	c.Assert(header.Copied, Equals, sessionSaveEntries+1)
+	c.Assert(header.PID, Equals, os.Getpid())
+}
+
+func (s *CmdTestSuite) TestSessionStatus(c *C) {
+	err := createSessionDir()
+	c.Assert(err, IsNil)
+
+	session := newSessionV2()
+	defer session.Close()
+	c.Assert(session.Status(), Equals, sessionInterrupted)
+	c.Assert(session.Progress(), Equals, 0)
+	c.Assert(session.Start(), IsNil)
+	c.Assert(session.Status(), Equals, sessionRunning)
+
+	session.Header.TotalObjects = 4
+	session.Header.TotalBytes = 400
+	session.Header.CopiedObjects = 1
+	session.Header.CopiedBytes = 150
+	c.Assert(session.Progress(), Equals, 37)
+
+	session.Pause()
+	c.Assert(session.Status(), Equals, sessionPaused)
+	session.Terminate()
+	c.Assert(session.Status(), Equals, sessionInterrupted)
+	session.Header.Failed = 1
+	session.Fail()
+	c.Assert(session.Status(), Equals, sessionFailed)
+
+	// status is kept on disk, failures are forgotten once the session runs again
+	loaded, err := loadSessionV2(session.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(loaded.DataFP.Close(), IsNil)
+	c.Assert(loaded.Status(), Equals, sessionFailed)
+	c.Assert(loaded.Header.Failed, Equals, 1)
+	c.Assert(session.Start(), IsNil)
+	c.Assert(session.Header.Failed, Equals, 0)
+	c.Assert(session.Status(), Equals, sessionRunning)
+}
+
+func (s *CmdTestSuite) TestSessionDataCorruption(c *C) {
+	err := createSessionDir()
+	c.Assert(err, IsNil)
+
+	session := newSessionV2()
+	defer session.Close()
+	dataWriter, err := session.NewDataWriter()
+	c.Assert(err, IsNil)
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		c.Assert(dataWriter.Append(copyURLs{SourceContent: &client.Content{Name: name}}), IsNil)
+	}
+	c.Assert(dataWriter.Commit(), IsNil)
+	session.Header.TotalObjects = 4
+	c.Assert(session.Save(), IsNil)
+	_, err = os.Stat(getSessionFile(session.SessionID) + ".tmp")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	// a record torn by a power loss and one written before records had checksums
+	data, err := ioutil.ReadFile(getSessionDataFile(session.SessionID))
+	c.Assert(err, IsNil)
+	c.Assert(ioutil.WriteFile(getSessionDataFile(session.SessionID), append(data, []byte("{\"SourceContent\":{\"Na\t0badf00d\n")...), 0600), IsNil)
+	loaded, err := loadSessionV2(session.SessionID)
+	c.Assert(err, IsNil)
+	var names []string
+	scanner := loaded.NewDataScanner()
+	for scanner.Scan() {
+		var cpURLs copyURLs
+		c.Assert(json.Unmarshal(scanner.Bytes(), &cpURLs), IsNil)
+		names = append(names, cpURLs.SourceContent.Name)
+	}
+	c.Assert(scanner.Err(), IsNil)
+	c.Assert(scanner.Corrupted(), Equals, true)
+	c.Assert(names, DeepEquals, []string{"a.txt", "b.txt", "c.txt"})
+	c.Assert(loaded.DataFP.Close(), IsNil)
+
+	c.Assert(ioutil.WriteFile(getSessionDataFile(session.SessionID), []byte("{\"SourceContent\":{\"Name\":\"d.txt\"}}\n"), 0600), IsNil)
+	loaded, err = loadSessionV2(session.SessionID)
+	c.Assert(err, IsNil)
+	scanner = loaded.NewDataScanner()
+	c.Assert(scanner.Scan(), Equals, true)
+	c.Assert(scanner.Text(), Equals, "{\"SourceContent\":{\"Name\":\"d.txt\"}}")
+	c.Assert(scanner.Scan(), Equals, false)
+	c.Assert(scanner.Corrupted(), Equals, false)
+	c.Assert(loaded.DataFP.Close(), IsNil)
+}
+
+func (s *CmdTestSuite) TestSessionEncrypt(c *C) {
+	c.Assert(createSessionDir(), IsNil)
+	c.Assert(os.Setenv(configPassphraseEnv, "correct horse"), IsNil)
+	defer os.Unsetenv(configPassphraseEnv)
+	conf, err := loadConfigV1()
+	c.Assert(err, IsNil)
+	conf.Encrypted = true
+	c.Assert(saveConfigV1(conf), IsNil)
+	defer func() {
+		conf, err := loadConfigV1()
+		c.Assert(err, IsNil)
+		conf.Encrypted = false
+		c.Assert(saveConfigV1(conf), IsNil)
+	}()
+
+	session := newSessionV2()
+	defer session.Close()
+	c.Assert(session.Header.Encrypted, Equals, true)
+	session.Header.RootPath = "/home/secret-user"
+	session.Header.CommandArgs = []string{"/home/secret-user/...", "https://s3.amazonaws.com/secret-bucket/"}
+	dataWriter, err := session.NewDataWriter()
+	c.Assert(err, IsNil)
+	c.Assert(dataWriter.Append(copyURLs{SourceContent: &client.Content{Name: "/home/secret-user/a.txt"}}), IsNil)
+	c.Assert(dataWriter.Commit(), IsNil)
+	c.Assert(session.Save(), IsNil)
+	// saving does not seal the header in use
+	c.Assert(session.Header.RootPath, Equals, "/home/secret-user")
+
+	for _, file := range []string{getSessionFile(session.SessionID), getSessionDataFile(session.SessionID)} {
+		data, err := ioutil.ReadFile(file)
+		c.Assert(err, IsNil)
+		c.Assert(strings.Contains(string(data), "secret"), Equals, false, Commentf("%s is not encrypted", file))
+	}
+
+	loaded, err := loadSessionV2(session.SessionID)
+	c.Assert(err, IsNil)
+	defer loaded.DataFP.Close()
+	scanner := loaded.NewDataScanner()
+	c.Assert(scanner.Scan(), Equals, false)
+	c.Assert(iodine.ToError(scanner.Err()), FitsTypeOf, errWrongPassphrase{})
+
+	c.Assert(loaded.Decrypt(), IsNil)
+	c.Assert(loaded.Header.RootPath, Equals, "/home/secret-user")
+	c.Assert(loaded.Header.CommandArgs, DeepEquals, session.Header.CommandArgs)
+	scanner = loaded.NewDataScanner()
+	c.Assert(scanner.Scan(), Equals, true)
+	var cpURLs copyURLs
+	c.Assert(json.Unmarshal(scanner.Bytes(), &cpURLs), IsNil)
+	c.Assert(cpURLs.SourceContent.Name, Equals, "/home/secret-user/a.txt")
+	c.Assert(scanner.Scan(), Equals, false)
+	c.Assert(scanner.Err(), IsNil)
+}
@@ -26,15 +26,17 @@ func signalTrap(sig ...os.Signal) <-chan bool {
 	// channel to notify the caller.
 	trapCh := make(chan bool, 1)
 
-	go func(chan<- bool) {
-		// channel to receive signals.
-		sigCh := make(chan os.Signal, 1)
-		defer close(sigCh)
+	// channel to receive signals.
+	sigCh := make(chan os.Signal, 1)
 
-		// `signal.Notify` registers the given channel to
-		// receive notifications of the specified signals.
-		signal.Notify(sigCh, sig...)
+	// `signal.Notify` registers the given channel to receive
+	// notifications of the specified signals, before returning
+	// so that no signal sent after the trap is set up is missed.
+	signal.Notify(sigCh, sig...)
 
+	// sigCh stays registered, repeated signals are dropped
+	// instead of falling back to their default action.
+	go func(chan<- bool) {
 		// Wait for the signal
 		<-sigCh
 
@@ -0,0 +1,149 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// spillQueue - queue of JSON encoded items kept in memory up to budget bytes, beyond that all
+// items move to a temporary file so planning of enormous trees does not run out of memory
+type spillQueue struct {
+	budget uint64
+	used   uint64
+	items  [][]byte
+	count  int
+
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// newSpillQueue - empty queue holding up to budget bytes in memory
+func newSpillQueue(budget uint64) *spillQueue {
+	return &spillQueue{budget: budget}
+}
+
+// Push - append v to the end of the queue
+func (q *spillQueue) Push(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	q.count++
+	if q.file == nil && q.used+uint64(len(data)) <= q.budget {
+		q.items = append(q.items, data)
+		q.used += uint64(len(data))
+		return nil
+	}
+	if q.file == nil {
+		if err := q.spill(); err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+	}
+	if _, err := q.writer.Write(append(data, '\n')); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	return nil
+}
+
+// spill - move the items held in memory to a new temporary file
+func (q *spillQueue) spill() error {
+	file, err := ioutil.TempFile("", "mc-queue-")
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	q.file = file
+	q.writer = bufio.NewWriter(file)
+	for _, data := range q.items {
+		if _, err := q.writer.Write(append(data, '\n')); err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+	}
+	q.items = nil
+	q.used = 0
+	return nil
+}
+
+// Len - number of items pushed
+func (q *spillQueue) Len() int {
+	return q.count
+}
+
+// Spilled - true once the items no longer fit the memory budget
+func (q *spillQueue) Spilled() bool {
+	return q.file != nil
+}
+
+// Range - decode every item in push order with decode, stops at the first error
+func (q *spillQueue) Range(decode func(data []byte) error) error {
+	if q.file == nil {
+		for _, data := range q.items {
+			if err := decode(data); err != nil {
+				return NewIodine(iodine.New(err, nil))
+			}
+		}
+		return nil
+	}
+	if err := q.writer.Flush(); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	if _, err := q.file.Seek(0, os.SEEK_SET); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	// lines may be longer than a bufio.Scanner token
+	reader := bufio.NewReader(q.file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 1 {
+			if err := decode(line[:len(line)-1]); err != nil {
+				return NewIodine(iodine.New(err, nil))
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+	}
+	// later pushes append to the end
+	if _, err := q.file.Seek(0, os.SEEK_END); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	return nil
+}
+
+// Close - release the items and remove the temporary file
+func (q *spillQueue) Close() error {
+	q.items = nil
+	if q.file == nil {
+		return nil
+	}
+	name := q.file.Name()
+	q.file.Close()
+	q.file = nil
+	if err := os.Remove(name); err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	return nil
+}
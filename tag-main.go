@@ -0,0 +1,134 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var tagCmd = cli.Command{
+	Name:   "tag",
+	Usage:  "Set, list or remove tags of objects and buckets",
+	Action: runTagCmd,
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} set TARGET TAGS
+   mc {{.Name}} list TARGET [TARGET...]
+   mc {{.Name}} remove TARGET [TARGET...]
+
+DESCRIPTION:
+   TAGS are key=value pairs joined by ‘&’. Setting tags replaces all tags of the target.
+
+EXAMPLES:
+   1. Tag an object on Amazon S3 object storage.
+      $ mc {{.Name}} set s3:datasets/2015/census.csv "env=prod&tier=hot"
+
+   2. Tag a bucket on Minio object storage for cost reports.
+      $ mc {{.Name}} set https://play.minio.io:9000/photos "team=web"
+
+   3. List tags of objects as JSON.
+      $ mc --json {{.Name}} list s3:datasets/2015/census.csv s3:datasets/2015/survey.csv
+
+   4. Remove all tags of an object.
+      $ mc {{.Name}} remove s3:datasets/2015/census.csv
+`,
+}
+
+// runTagCmd - is a handler for mc tag command
+func runTagCmd(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "tag", exitUsage) // last argument is exit code
+	}
+	operation := ctx.Args().First()
+	args := ctx.Args().Tail()
+	var tags map[string]string
+	switch operation {
+	case "set":
+		if len(args) != 2 {
+			cli.ShowCommandHelpAndExit(ctx, "tag", exitUsage) // last argument is exit code
+		}
+		var err error
+		if tags, err = parseTagFilter(args[1]); err != nil {
+			console.Fatalf("Invalid tags ‘%s’, use key=value pairs joined by ‘&’. %s\n", args[1], err)
+		}
+		args = args[:1]
+	case "list", "remove":
+	default:
+		console.Fatalf("Unknown operation ‘%s’, please choose from [set, list, remove]. %s\n", operation, errInvalidArgument{})
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	config := mustGetMcConfig()
+	for _, arg := range args {
+		targetURL, err := getExpandedURL(arg, config.Aliases)
+		if err != nil {
+			switch e := iodine.ToError(err).(type) {
+			case errUnsupportedScheme:
+				console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+			default:
+				console.Fatalf("Unable to parse argument %s. %s\n", arg, err)
+			}
+		}
+		message, err := doTagCmd(targetURL, operation, tags)
+		if err != nil {
+			console.Fatalf("Unable to %s tags of ‘%s’. %s\n", operation, targetURL, err)
+		}
+		console.Print(message)
+	}
+}
+
+// doTagCmd - set tags of targetURL, list or remove them
+func doTagCmd(targetURL, operation string, tags map[string]string) (TagMessage, error) {
+	message := TagMessage{Target: targetURL, Operation: operation}
+	clnt, err := target2Client(targetURL)
+	if err != nil {
+		return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	switch operation {
+	case "set":
+		setter, ok := clnt.(client.TagSetter)
+		if !ok {
+			return message, NewIodine(iodine.New(errTagsNotSupported{URL: targetURL}, nil))
+		}
+		err = setter.SetObjectTags(tags)
+		message.Tags = tags
+	case "list":
+		tagger, ok := clnt.(client.Tagger)
+		if !ok {
+			return message, NewIodine(iodine.New(errTagsNotSupported{URL: targetURL}, nil))
+		}
+		message.Tags, err = tagger.GetObjectTags()
+	case "remove":
+		remover, ok := clnt.(client.TagRemover)
+		if !ok {
+			return message, NewIodine(iodine.New(errTagsNotSupported{URL: targetURL}, nil))
+		}
+		err = remover.RemoveObjectTags()
+		message.Tags = map[string]string{}
+	}
+	if err != nil {
+		return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	return message, nil
+}
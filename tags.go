@@ -0,0 +1,77 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// parseTagFilter - parse a tag filter or tags to set of the form "env=prod&tier=hot"
+func parseTagFilter(filter string) (map[string]string, error) {
+	values, err := url.ParseQuery(filter)
+	if err != nil {
+		return nil, NewIodine(iodine.New(errInvalidArgument{}, map[string]string{"Tags": filter}))
+	}
+	tags := make(map[string]string)
+	for key, value := range values {
+		if key == "" || len(value) != 1 {
+			return nil, NewIodine(iodine.New(errInvalidArgument{}, map[string]string{"Tags": filter}))
+		}
+		tags[key] = value[0]
+	}
+	return tags, nil
+}
+
+// matchTags - true if object carries all tags of the filter, tags are fetched once per object
+func matchTags(objectURL string, filter map[string]string) (bool, error) {
+	clnt, err := url2Client(objectURL)
+	if err != nil {
+		return false, NewIodine(iodine.New(err, nil))
+	}
+	tagger, ok := clnt.(client.Tagger)
+	if !ok {
+		return false, NewIodine(iodine.New(errTagsNotSupported{URL: objectURL}, nil))
+	}
+	tags, err := tagger.GetObjectTags()
+	if err != nil {
+		return false, NewIodine(iodine.New(err, map[string]string{"URL": objectURL}))
+	}
+	for key, value := range filter {
+		if tags[key] != value {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// listedURL - URL of content listed by clnt, names are relative to the parent of the listed URL when
+// recursive and to the listed URL itself otherwise, unless the listed URL is an object
+func listedURL(clnt client.Client, content *client.Content, recursive, isDir bool) string {
+	listURL := clnt.URL()
+	separator := string(listURL.Separator)
+	switch {
+	case recursive:
+		return listURL.String()[:strings.LastIndex(listURL.String(), separator)+1] + content.Name
+	case isDir:
+		return strings.TrimSuffix(listURL.String(), separator) + separator + content.Name
+	}
+	return listURL.String()
+}
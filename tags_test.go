@@ -0,0 +1,101 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestTagFilter(c *C) {
+	tags, err := parseTagFilter("env=prod&tier=hot")
+	c.Assert(err, IsNil)
+	c.Assert(tags, DeepEquals, map[string]string{"env": "prod", "tier": "hot"})
+
+	tags, err = parseTagFilter("project=a%26b")
+	c.Assert(err, IsNil)
+	c.Assert(tags, DeepEquals, map[string]string{"project": "a&b"})
+
+	_, err = parseTagFilter("env=prod&env=dev")
+	c.Assert(iodine.ToError(err), FitsTypeOf, errInvalidArgument{})
+	_, err = parseTagFilter("=prod")
+	c.Assert(iodine.ToError(err), FitsTypeOf, errInvalidArgument{})
+
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	object := filepath.Join(root, "dir", "object")
+	c.Assert(putTarget(object, 5, bytes.NewReader([]byte("hello"))), IsNil)
+
+	// filesystem has no tags
+	_, err = matchTags(object, tags)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errTagsNotSupported{})
+
+	clnt, err := url2Client(filepath.Join(root, "dir"))
+	c.Assert(err, IsNil)
+	c.Assert(listedURL(clnt, &client.Content{Name: "dir/object"}, true, true), Equals, object)
+	c.Assert(listedURL(clnt, &client.Content{Name: "object"}, false, true), Equals, object)
+	clnt, err = url2Client(object)
+	c.Assert(err, IsNil)
+	c.Assert(listedURL(clnt, &client.Content{Name: object}, false, false), Equals, object)
+}
+
+func (s *CmdTestSuite) TestTagCmd(c *C) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/bucket/census.csv")
+		switch r.Method {
+		case "PUT":
+			body, _ = ioutil.ReadAll(r.Body)
+		case "DELETE":
+			body = []byte("<Tagging><TagSet></TagSet></Tagging>")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Write(body)
+		}
+	}))
+	defer server.Close()
+
+	targetURL := server.URL + "/bucket/census.csv"
+	message, err := doTagCmd(targetURL, "set", map[string]string{"env": "prod", "tier": "hot"})
+	c.Assert(err, IsNil)
+	c.Assert(message.String(), Equals, "Set tags of ‘"+targetURL+"’ to ‘env=prod&tier=hot’.\n")
+	message, err = doTagCmd(targetURL, "list", nil)
+	c.Assert(err, IsNil)
+	c.Assert(message.Tags, DeepEquals, map[string]string{"env": "prod", "tier": "hot"})
+	c.Assert(message.String(), Equals, targetURL+"  env=prod&tier=hot\n")
+	message, err = doTagCmd(targetURL, "remove", nil)
+	c.Assert(err, IsNil)
+	message, err = doTagCmd(targetURL, "list", nil)
+	c.Assert(err, IsNil)
+	c.Assert(message.Tags, HasLen, 0)
+
+	// filesystem has no tags
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	_, err = doTagCmd(root, "list", nil)
+	c.Assert(iodine.ToError(err), FitsTypeOf, errTagsNotSupported{})
+}
@@ -0,0 +1,170 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var undeleteCmd = cli.Command{
+	Name:   "undelete",
+	Usage:  "Restore removed objects of versioned buckets",
+	Action: runUndeleteCmd,
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} TARGET [TARGET...]
+
+DESCRIPTION:
+   Removing an object of a versioned bucket leaves a delete marker as its latest version. Undelete removes
+   delete markers, making the version below them current again. Objects which are not removed are left as is.
+
+EXAMPLES:
+   1. Restore an object removed by mistake on Amazon S3 object storage.
+      $ mc {{.Name}} s3:docs/contracts/2015/acme.pdf
+
+   2. Restore all removed objects under a prefix on Minio object storage.
+      $ mc {{.Name}} https://play.minio.io:9000/backup/2015-Jun/...
+`,
+}
+
+// runUndeleteCmd - is a handler for mc undelete command
+func runUndeleteCmd(ctx *cli.Context) {
+	if !ctx.Args().Present() || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "undelete", exitUsage) // last argument is exit code
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	config := mustGetMcConfig()
+	for _, arg := range ctx.Args() {
+		targetURL, err := getExpandedURL(arg, config.Aliases)
+		if err != nil {
+			switch e := iodine.ToError(err).(type) {
+			case errUnsupportedScheme:
+				console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+			default:
+				console.Fatalf("Unable to parse argument %s. %s\n", arg, err)
+			}
+		}
+		if err := doUndeleteCmd(stripRecursiveURL(targetURL), isURLRecursive(targetURL)); err != nil {
+			console.Fatalf("Failed to undelete : %s. %s\n", targetURL, err)
+		}
+	}
+}
+
+// deletedObject - an object whose latest versions are delete markers, with the version they hide
+type deletedObject struct {
+	content   *client.Content // the version made current by removing markers
+	markers   []string        // version IDs of the delete markers, newest first
+	isDeleted bool            // the latest version is a delete marker
+}
+
+// findDeletedObjects - calls found for every removed object listed by lister which has a version to restore,
+// objects whose versions are all delete markers are left out
+func findDeletedObjects(lister client.VersionLister, recursive bool, found func(*deletedObject) error) error {
+	// versions of an object are listed together, newest first
+	var object *deletedObject
+	var name string
+	for entry := range lister.ListVersions(recursive) {
+		if entry.Err != nil {
+			return NewIodine(iodine.New(entry.Err, nil))
+		}
+		content := entry.Content
+		if content.Type.IsDir() {
+			continue
+		}
+		if content.Name != name {
+			name, object = content.Name, &deletedObject{isDeleted: content.IsDeleteMarker}
+		}
+		if !object.isDeleted || object.content != nil {
+			continue
+		}
+		if content.IsDeleteMarker {
+			object.markers = append(object.markers, content.VersionID)
+			continue
+		}
+		object.content = content
+		if err := found(object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// doUndeleteCmd - restore the removed object of targetURL, or all removed objects under it if recursive
+func doUndeleteCmd(targetURL string, recursive bool) error {
+	clnt, err := target2Client(targetURL)
+	if err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	lister, ok := clnt.(client.VersionLister)
+	if !ok {
+		return NewIodine(iodine.New(errVersionsNotSupported{URL: targetURL}, nil))
+	}
+	// objects starting with the name of a single object are listed as well
+	separator := string(clnt.URL().Separator)
+	base := targetURL[strings.LastIndex(targetURL, separator)+1:]
+	restored := 0
+	err = findDeletedObjects(lister, recursive, func(object *deletedObject) error {
+		if !recursive && object.content.Name != base {
+			return nil
+		}
+		objectClnt, err := target2Client(listedURL(clnt, object.content, recursive, false))
+		if err != nil {
+			return NewIodine(iodine.New(err, nil))
+		}
+		restored++
+		return doUndelete(objectClnt, object)
+	})
+	if err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	if !recursive && restored == 0 {
+		return NewIodine(iodine.New(errNotDeleted{URL: targetURL}, nil))
+	}
+	return nil
+}
+
+// doUndelete - remove the delete markers of object
+func doUndelete(clnt client.Client, object *deletedObject) error {
+	remover, ok := clnt.(client.VersionRemover)
+	if !ok {
+		return NewIodine(iodine.New(errVersionsNotSupported{URL: clnt.URL().String()}, nil))
+	}
+	start := time.Now()
+	var err error
+	for _, versionID := range object.markers {
+		if err = remover.RemoveVersion(versionID); err != nil {
+			break
+		}
+	}
+	console.RecordOperation(console.Operation{Command: "undelete", Target: clnt.URL().String(), Duration: time.Since(start), Err: err})
+	if err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"Target": clnt.URL().String()}))
+	}
+	console.Print(UndeleteMessage{Target: clnt.URL().String(), VersionID: object.content.VersionID})
+	return nil
+}
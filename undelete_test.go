@@ -0,0 +1,59 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/minio/mc/pkg/fakes3"
+	"github.com/minio/minio/pkg/iodine"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestUndelete(c *C) {
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+	// a.txt was removed twice, b.txt is current, c.txt was never more than a delete marker
+	versions := []byte(`<ListVersionsResult><IsTruncated>false</IsTruncated>
+<DeleteMarker><Key>docs/a.txt</Key><VersionId>v3</VersionId><IsLatest>true</IsLatest><LastModified>2015-06-03T10:00:00.000Z</LastModified></DeleteMarker>
+<DeleteMarker><Key>docs/a.txt</Key><VersionId>v2</VersionId><IsLatest>false</IsLatest><LastModified>2015-06-02T10:00:00.000Z</LastModified></DeleteMarker>
+<Version><Key>docs/a.txt</Key><VersionId>v1</VersionId><IsLatest>false</IsLatest><LastModified>2015-06-01T10:00:00.000Z</LastModified><Size>5</Size></Version>
+<Version><Key>docs/b.txt</Key><VersionId>v5</VersionId><IsLatest>true</IsLatest><LastModified>2015-06-04T10:00:00.000Z</LastModified><Size>6</Size></Version>
+<DeleteMarker><Key>docs/b.txt</Key><VersionId>v4</VersionId><IsLatest>false</IsLatest><LastModified>2015-06-02T10:00:00.000Z</LastModified></DeleteMarker>
+<DeleteMarker><Key>docs/c.txt</Key><VersionId>v6</VersionId><IsLatest>true</IsLatest><LastModified>2015-06-02T10:00:00.000Z</LastModified></DeleteMarker>
+</ListVersionsResult>`)
+	server.SetDocument("/bucket", "versions", versions)
+
+	c.Assert(doUndeleteCmd(server.URL+"/bucket/docs", true), IsNil)
+	removed := server.RemovedVersions()
+	sort.Strings(removed)
+	c.Assert(removed, DeepEquals, []string{"/bucket/docs/a.txt?versionId=v2", "/bucket/docs/a.txt?versionId=v3"})
+
+	c.Assert(doUndeleteCmd(server.URL+"/bucket/docs/a.txt", false), IsNil)
+	c.Assert(server.RemovedVersions(), HasLen, 4)
+	// current objects and objects which never had data are not restored
+	for _, name := range []string{"b.txt", "c.txt"} {
+		err := doUndeleteCmd(server.URL+"/bucket/docs/"+name, false)
+		c.Assert(iodine.ToError(err), FitsTypeOf, errNotDeleted{}, Commentf("%s", name))
+	}
+
+	// removing the delete markers fails like listing them
+	server.AddFault(fakes3.Fault{Method: "DELETE", Path: "/bucket/docs/a.txt", Code: "AccessDenied", Status: http.StatusForbidden, Times: 1})
+	exitCode, _ := getErrorCode(doUndeleteCmd(server.URL+"/bucket/docs/a.txt", false))
+	c.Assert(exitCode, Equals, exitPermission)
+}
@@ -102,9 +102,9 @@ https://dl.minio.io:9000 for continuous updates`
 // runUpdateCmd -
 func runUpdateCmd(ctx *cli.Context) {
 	if ctx.Args().First() == "help" {
-		cli.ShowCommandHelpAndExit(ctx, "update", 1) // last argument is exit code
+		cli.ShowCommandHelpAndExit(ctx, "update", exitUsage) // last argument is exit code
 	}
-	if !isMcConfigExists() {
+	if !isMcConfigAvailable() {
 		console.Fatalf("Please run \"mc config generate\". %s\n", NewIodine(iodine.New(errNotConfigured{}, nil)))
 	}
 	msg, err := doUpdateCheck()
@@ -17,9 +17,12 @@
 package main
 
 import (
+	"path/filepath"
 	"strings"
 
+	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/stdio"
 	"github.com/minio/minio/pkg/iodine"
 )
 
@@ -33,6 +36,56 @@ func isURLRecursive(urlStr string) bool {
 	return strings.HasSuffix(urlStr, recursiveSeparator)
 }
 
+// isStdioURL - find out if url is "-", standard input as a source and standard output as a target
+func isStdioURL(urlStr string) bool {
+	return urlStr == stdio.Name
+}
+
+// stdioArgs - command line with "-" kept in place among the arguments of a command. The cli takes the first
+// argument starting with "-" for the first flag and moves the arguments ahead of it behind the flags, which
+// swaps "-" with them. Flags of the command are moved ahead instead, arguments follow a "--" terminator
+func stdioArgs(args []string, commands []cli.Command) []string {
+	for index := 1; index < len(args); index++ {
+		for _, command := range commands {
+			if command.HasName(args[index]) {
+				return append(args[:index+1:index+1], stdioCommandArgs(args[index+1:])...)
+			}
+		}
+	}
+	return args
+}
+
+// stdioCommandArgs - arguments of a command with its flags ahead of "--" when "-" comes before them
+func stdioCommandArgs(args []string) []string {
+	firstFlagIndex, terminatorIndex, hasStdio := -1, len(args), false
+	for index, arg := range args {
+		if arg == "--" {
+			terminatorIndex = index
+			break
+		}
+		if isStdioURL(arg) {
+			hasStdio = hasStdio || firstFlagIndex == -1
+		} else if strings.HasPrefix(arg, "-") && firstFlagIndex == -1 {
+			firstFlagIndex = index
+		}
+	}
+	if !hasStdio {
+		return args
+	}
+	regularArgs := args[:terminatorIndex]
+	var flagArgs []string
+	if firstFlagIndex > -1 {
+		regularArgs = args[:firstFlagIndex]
+		flagArgs = args[firstFlagIndex:terminatorIndex]
+	}
+	reordered := append(append([]string{}, flagArgs...), "--")
+	reordered = append(reordered, regularArgs...)
+	if terminatorIndex < len(args) {
+		reordered = append(reordered, args[terminatorIndex+1:]...)
+	}
+	return reordered
+}
+
 // stripRecursiveURL - Strip "..." from the URL if present.
 func stripRecursiveURL(urlStr string) string {
 	if !isURLRecursive(urlStr) {
@@ -47,6 +100,11 @@ func stripRecursiveURL(urlStr string) string {
 
 // getExpandedURL - extracts URL string from a single cmd-line argument
 func getExpandedURL(arg string, aliases map[string]string) (urlStr string, err error) {
+	if globalLocalFlag {
+		// file: form of paths which would be taken for URLs, plain path otherwise
+		localURL := client.URL{Type: client.Filesystem, Path: filepath.FromSlash(arg), Separator: filepath.Separator}
+		return localURL.String(), nil
+	}
 	if _, err := client.Parse(urlStr); err != nil {
 		// Not a valid URL. Return error
 		return "", NewIodine(iodine.New(errInvalidURL{arg}, nil))
@@ -0,0 +1,121 @@
+/*
+ * Minio Client, (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var verifyEndpointCmd = cli.Command{
+	Name:        "verify-endpoint",
+	Usage:       "Soak test an object storage endpoint with verified uploads, downloads and listings",
+	Description: "Objects are written under ‘" + verifyPrefix + "/’ of the target and overwritten in place, they are not removed at the end",
+	Action:      runVerifyEndpointCmd,
+	Flags:       []cli.Flag{durationFlag, intervalFlag, mixFlag, sizeFlag, objectsFlag, reportFlag},
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} TARGET {{if .Description}}
+
+DESCRIPTION:
+   {{.Description}}{{end}}{{if .Flags}}
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}{{ end }}
+
+EXAMPLES:
+   1. Qualify a new appliance for a day, sampling errors and latencies every 10 minutes.
+      $ mc {{.Name}} --duration 24h --interval 10m --report appliance.json https://s3.example.com/qualify
+
+   2. Exercise mostly downloads of 16MiB objects for an hour.
+      $ mc {{.Name}} --mix put=10,get=80,list=10 --size 16MiB https://play.minio.io:9000/qualify
+
+`,
+}
+
+// runVerifyEndpointCmd - is a handler for mc verify-endpoint command
+func runVerifyEndpointCmd(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "verify-endpoint", exitUsage) // last argument is exit code
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	config := mustGetMcConfig()
+	targetURL, err := getExpandedURL(ctx.Args().First(), config.Aliases)
+	if err != nil {
+		switch e := iodine.ToError(err).(type) {
+		case errUnsupportedScheme:
+			console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+		default:
+			console.Fatalf("Unable to parse argument %s. %s\n", ctx.Args().First(), err)
+		}
+	}
+	mix, err := parseVerifyMix(ctx.String("mix"))
+	if err != nil {
+		console.Fatalf("Invalid operation mix ‘%s’. %s\n", ctx.String("mix"), NewIodine(iodine.New(err, nil)))
+	}
+	size, err := humanize.ParseBytes(ctx.String("size"))
+	if err != nil {
+		console.Fatalf("Invalid object size ‘%s’. %s\n", ctx.String("size"), errInvalidArgument{})
+	}
+	if ctx.Int("objects") <= 0 || ctx.Duration("interval") <= 0 {
+		console.Fatalf("Number of objects and interval must be positive. %s\n", errInvalidArgument{})
+	}
+	verifyConfig := verifyEndpointConfig{
+		TargetURL: targetURL,
+		Duration:  ctx.Duration("duration"),
+		Interval:  ctx.Duration("interval"),
+		Mix:       mix,
+		Size:      int64(size),
+		Objects:   ctx.Int("objects"),
+		Report:    ctx.String("report"),
+		Seed:      globalClock.Now().UnixNano(),
+	}
+
+	// interrupted runs still write their last interval to the report
+	stopCh := signalTrap(os.Interrupt, syscall.SIGTERM)
+	intervalCh := make(chan *verifyInterval)
+	doneCh := make(chan bool)
+	go func() {
+		for interval := range intervalCh {
+			console.PrintC(VerifyEndpointMessage{
+				Start:      interval.Start,
+				Operations: interval.Operations,
+				Errors:     interval.Errors,
+			})
+		}
+		close(doneCh)
+	}()
+	report, err := doVerifyEndpoint(verifyConfig, stopCh, intervalCh)
+	<-doneCh
+	if err != nil {
+		console.Fatalf("Unable to write report ‘%s’. %s\n", verifyConfig.Report, NewIodine(iodine.New(err, nil)))
+	}
+	if report.failed() {
+		console.Fatalf("Endpoint ‘%s’ failed verification, see ‘%s’ for details.\n", targetURL, verifyConfig.Report)
+	}
+}
@@ -0,0 +1,371 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Operations exercised by verify-endpoint
+const (
+	verifyOpPut  = "PUT"
+	verifyOpGet  = "GET"
+	verifyOpList = "LIST"
+)
+
+// verifyPrefix - folder under target holding all objects written by verify-endpoint
+const verifyPrefix = "mc-verify-endpoint"
+
+// maxIntervalErrors - number of error messages kept per interval in the report
+const maxIntervalErrors = 10
+
+// verifyWeight - relative frequency of an operation
+type verifyWeight struct {
+	Op     string
+	Weight int
+}
+
+// parseVerifyMix - parse a mix of the form "put=40,get=40,list=20"
+func parseVerifyMix(mix string) ([]verifyWeight, error) {
+	var weights []verifyWeight
+	total := 0
+	for _, field := range strings.Split(mix, ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			return nil, iodine.New(errInvalidArgument{}, map[string]string{"Mix": mix})
+		}
+		op := strings.ToUpper(kv[0])
+		switch op {
+		case verifyOpPut, verifyOpGet, verifyOpList:
+		default:
+			return nil, iodine.New(errInvalidArgument{}, map[string]string{"Mix": mix, "Operation": kv[0]})
+		}
+		weight, err := strconv.Atoi(kv[1])
+		if err != nil || weight < 0 {
+			return nil, iodine.New(errInvalidArgument{}, map[string]string{"Mix": mix, "Weight": kv[1]})
+		}
+		weights = append(weights, verifyWeight{Op: op, Weight: weight})
+		total += weight
+	}
+	if total == 0 {
+		return nil, iodine.New(errInvalidArgument{}, map[string]string{"Mix": mix})
+	}
+	return weights, nil
+}
+
+// verifyEndpointConfig - parameters of a verify-endpoint run
+type verifyEndpointConfig struct {
+	TargetURL string
+	Duration  time.Duration
+	Interval  time.Duration
+	Mix       []verifyWeight
+	Size      int64
+	Objects   int
+	Report    string
+	Seed      int64
+}
+
+// verifyStats - counters and latencies of one operation type
+type verifyStats struct {
+	Count        int64   `json:"count"`
+	Errors       int64   `json:"errors"`
+	Mismatches   int64   `json:"mismatches"`
+	MinLatency   float64 `json:"min-latency-ms"`
+	AvgLatency   float64 `json:"avg-latency-ms"`
+	MaxLatency   float64 `json:"max-latency-ms"`
+	totalLatency time.Duration
+}
+
+func (s *verifyStats) add(latency time.Duration, failed, mismatch bool) {
+	ms := float64(latency) / float64(time.Millisecond)
+	if s.Count == 0 || ms < s.MinLatency {
+		s.MinLatency = ms
+	}
+	if ms > s.MaxLatency {
+		s.MaxLatency = ms
+	}
+	s.Count++
+	s.totalLatency += latency
+	s.AvgLatency = float64(s.totalLatency) / float64(s.Count) / float64(time.Millisecond)
+	if failed {
+		s.Errors++
+	}
+	if mismatch {
+		s.Mismatches++
+	}
+}
+
+// verifyError - a failed or mismatching operation
+type verifyError struct {
+	Time    time.Time `json:"time"`
+	Op      string    `json:"operation"`
+	URL     string    `json:"url"`
+	Message string    `json:"message"`
+}
+
+// verifyInterval - one sample of the time series
+type verifyInterval struct {
+	Start      time.Time               `json:"start"`
+	Operations map[string]*verifyStats `json:"operations"`
+	Errors     []verifyError           `json:"errors,omitempty"`
+}
+
+// verifyReport - rewritten after every interval, a killed run keeps its samples
+type verifyReport struct {
+	Version   string                  `json:"version"`
+	Target    string                  `json:"target"`
+	Start     time.Time               `json:"start"`
+	End       time.Time               `json:"end"`
+	Mix       map[string]int          `json:"mix"`
+	Size      int64                   `json:"size"`
+	Objects   int                     `json:"objects"`
+	Totals    map[string]*verifyStats `json:"totals"`
+	Intervals []*verifyInterval       `json:"intervals"`
+}
+
+// failed - did any operation fail or return unexpected data
+func (r *verifyReport) failed() bool {
+	for _, stats := range r.Totals {
+		if stats.Errors > 0 || stats.Mismatches > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *verifyReport) save(file string) error {
+	reportBytes, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	// write aside and rename so readers never see a partial report
+	if err := ioutil.WriteFile(file+".tmp", append(reportBytes, '\n'), 0644); err != nil {
+		return iodine.New(err, nil)
+	}
+	return iodine.New(os.Rename(file+".tmp", file), nil)
+}
+
+// verifyEndpoint - state of a run, objects are overwritten in place as clients cannot remove them
+type verifyEndpoint struct {
+	config   verifyEndpointConfig
+	random   *rand.Rand
+	written  map[int][md5.Size]byte // checksum of last successful PUT of each object
+	report   *verifyReport
+	interval *verifyInterval
+}
+
+func (v *verifyEndpoint) objectURL(i int) (string, error) {
+	return urlJoinPath(v.config.TargetURL, fmt.Sprintf("%s/object-%04d", verifyPrefix, i))
+}
+
+// pickOp - choose next operation by weight, reads are turned into writes until something is written
+func (v *verifyEndpoint) pickOp() string {
+	total := 0
+	for _, w := range v.config.Mix {
+		total += w.Weight
+	}
+	n := v.random.Intn(total)
+	op := v.config.Mix[len(v.config.Mix)-1].Op
+	for _, w := range v.config.Mix {
+		if n < w.Weight {
+			op = w.Op
+			break
+		}
+		n -= w.Weight
+	}
+	if len(v.written) == 0 {
+		return verifyOpPut
+	}
+	return op
+}
+
+// writtenObject - a random object with known content
+func (v *verifyEndpoint) writtenObject() int {
+	var objects []int
+	for i := range v.written {
+		objects = append(objects, i)
+	}
+	sort.Ints(objects)
+	return objects[v.random.Intn(len(objects))]
+}
+
+func (v *verifyEndpoint) doPut() (string, bool, error) {
+	i := v.random.Intn(v.config.Objects)
+	objectURL, err := v.objectURL(i)
+	if err != nil {
+		return objectURL, false, iodine.New(err, nil)
+	}
+	data := make([]byte, v.config.Size)
+	v.random.Read(data)
+	// content is unknown after a failed PUT
+	delete(v.written, i)
+	if err := putTarget(objectURL, int64(len(data)), bytes.NewReader(data)); err != nil {
+		return objectURL, false, iodine.New(err, nil)
+	}
+	v.written[i] = md5.Sum(data)
+	return objectURL, false, nil
+}
+
+func (v *verifyEndpoint) doGet() (string, bool, error) {
+	i := v.writtenObject()
+	objectURL, err := v.objectURL(i)
+	if err != nil {
+		return objectURL, false, iodine.New(err, nil)
+	}
+	reader, _, err := getSource(objectURL)
+	if err != nil {
+		return objectURL, false, iodine.New(err, nil)
+	}
+	defer reader.Close()
+	hash := md5.New()
+	n, err := io.Copy(hash, reader)
+	if err != nil {
+		return objectURL, false, iodine.New(err, nil)
+	}
+	var sum [md5.Size]byte
+	copy(sum[:], hash.Sum(nil))
+	if n != v.config.Size || sum != v.written[i] {
+		return objectURL, true, iodine.New(errVerifyMismatch{URL: objectURL, Reason: "content differs from last upload"}, nil)
+	}
+	return objectURL, false, nil
+}
+
+func (v *verifyEndpoint) doList() (string, bool, error) {
+	listURL, err := urlJoinPath(v.config.TargetURL, verifyPrefix)
+	if err != nil {
+		return listURL, false, iodine.New(err, nil)
+	}
+	clnt, err := url2Client(listURL)
+	if err != nil {
+		return listURL, false, iodine.New(err, nil)
+	}
+	// backends name listed objects differently, match on object name suffix
+	listed := make(map[string]int64)
+	for entry := range clnt.List(true) {
+		if entry.Err != nil {
+			return listURL, false, iodine.New(entry.Err, nil)
+		}
+		name := entry.Content.Name
+		listed[name[strings.LastIndex(name, "/")+1:]] = entry.Content.Size
+	}
+	for i := range v.written {
+		name := fmt.Sprintf("object-%04d", i)
+		size, ok := listed[name]
+		if !ok {
+			return listURL, true, iodine.New(errVerifyMismatch{URL: listURL, Reason: name + " is missing"}, nil)
+		}
+		if size != v.config.Size {
+			return listURL, true, iodine.New(errVerifyMismatch{URL: listURL, Reason: name + " has wrong size"}, nil)
+		}
+	}
+	return listURL, false, nil
+}
+
+func (v *verifyEndpoint) record(op, url string, start time.Time, mismatch bool, err error) {
+	now := globalClock.Now().UTC()
+	latency := now.Sub(start)
+	for _, operations := range []map[string]*verifyStats{v.report.Totals, v.interval.Operations} {
+		if operations[op] == nil {
+			operations[op] = new(verifyStats)
+		}
+		operations[op].add(latency, err != nil && !mismatch, mismatch)
+	}
+	if err != nil && len(v.interval.Errors) < maxIntervalErrors {
+		v.interval.Errors = append(v.interval.Errors, verifyError{
+			Time:    now,
+			Op:      op,
+			URL:     url,
+			Message: iodine.ToError(err).Error(),
+		})
+	}
+}
+
+// doVerifyEndpoint - exercise target until duration elapses or stopCh fires,
+// every completed interval is saved to the report and sent on intervalCh
+func doVerifyEndpoint(config verifyEndpointConfig, stopCh <-chan bool, intervalCh chan<- *verifyInterval) (*verifyReport, error) {
+	defer close(intervalCh)
+	start := globalClock.Now().UTC()
+	mix := make(map[string]int)
+	for _, w := range config.Mix {
+		mix[w.Op] += w.Weight
+	}
+	v := &verifyEndpoint{
+		config:  config,
+		random:  rand.New(rand.NewSource(config.Seed)),
+		written: make(map[int][md5.Size]byte),
+		report: &verifyReport{
+			Version: "1.0.0",
+			Target:  config.TargetURL,
+			Start:   start,
+			End:     start,
+			Mix:     mix,
+			Size:    config.Size,
+			Objects: config.Objects,
+			Totals:  make(map[string]*verifyStats),
+		},
+	}
+	v.interval = &verifyInterval{Start: start, Operations: make(map[string]*verifyStats)}
+	flush := func(now time.Time) error {
+		v.report.End = now
+		v.report.Intervals = append(v.report.Intervals, v.interval)
+		intervalCh <- v.interval
+		v.interval = &verifyInterval{Start: now, Operations: make(map[string]*verifyStats)}
+		return iodine.New(v.report.save(config.Report), nil)
+	}
+	for {
+		select {
+		case <-stopCh:
+			return v.report, flush(globalClock.Now().UTC())
+		default:
+		}
+		now := globalClock.Now().UTC()
+		if now.Sub(start) >= config.Duration {
+			return v.report, flush(now)
+		}
+		if now.Sub(v.interval.Start) >= config.Interval {
+			if err := flush(now); err != nil {
+				return v.report, iodine.New(err, nil)
+			}
+		}
+		var url string
+		var mismatch bool
+		var err error
+		op := v.pickOp()
+		switch op {
+		case verifyOpPut:
+			url, mismatch, err = v.doPut()
+		case verifyOpGet:
+			url, mismatch, err = v.doGet()
+		case verifyOpList:
+			url, mismatch, err = v.doList()
+		}
+		v.record(op, url, now, mismatch, err)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"os"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var verifyManifestCmd = cli.Command{
+	Name:        "verify-manifest",
+	Usage:       "Check a target against a signed manifest written by ‘mc cp --manifest’",
+	Description: "The manifest signature is checked first, then size and SHA-256 checksum of every listed object. Nothing is written to the target",
+	Action:      runVerifyManifestCmd,
+	Flags:       []cli.Flag{publicKeyFlag},
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} MANIFEST [TARGET] {{if .Description}}
+
+DESCRIPTION:
+   {{.Description}}{{end}}{{if .Flags}}
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}{{ end }}
+
+EXAMPLES:
+   1. Check the target of a migration against its manifest, signed with the local manifest key.
+      $ mc {{.Name}} records-2015.json
+
+   2. Check a copy of the migrated bucket, trusting the key of the host which ran the migration.
+      $ mc {{.Name}} --public-key 9Lq3tTi1ZHyAPrB3LDJfjkfbQMiyA1KfA3nEd9UW0WE= records-2015.json s3:records-archive/2015
+
+`,
+}
+
+// runVerifyManifestCmd - is a handler for mc verify-manifest command
+func runVerifyManifestCmd(ctx *cli.Context) {
+	if len(ctx.Args()) < 1 || len(ctx.Args()) > 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "verify-manifest", exitUsage) // last argument is exit code
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	manifestPath := ctx.Args().First()
+	manifest, err := loadManifest(manifestPath)
+	if err != nil {
+		console.Fatalf("Unable to read manifest ‘%s’. %s\n", manifestPath, NewIodine(iodine.New(err, nil)))
+	}
+
+	var publicKey ed25519.PublicKey
+	if ctx.String("public-key") != "" {
+		key, err := base64.StdEncoding.DecodeString(ctx.String("public-key"))
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			console.Fatalf("Public key must be a base64 encoded ed25519 key. %s\n", errInvalidArgument{})
+		}
+		publicKey = ed25519.PublicKey(key)
+	} else {
+		privateKey, err := loadManifestKey(false)
+		if err != nil {
+			console.Fatalf("Unable to load local manifest key, use ‘--public-key’. %s\n", NewIodine(iodine.New(err, nil)))
+		}
+		publicKey = privateKey.Public().(ed25519.PublicKey)
+	}
+	if err := manifest.verifySignature(publicKey); err != nil {
+		console.Fatalf("Unable to trust manifest ‘%s’. %s\n", manifestPath, NewIodine(iodine.New(err, nil)))
+	}
+
+	targetURL := manifest.Target
+	if len(ctx.Args()) == 2 {
+		URLs, err := args2URLs(ctx.Args()[1:])
+		if err != nil {
+			console.Fatalf("Unknown URL type found %s. %s\n", ctx.Args()[1:], err)
+		}
+		targetURL = URLs[0]
+	}
+	message, err := doVerifyManifest(manifest, targetURL)
+	if err != nil {
+		console.Fatalf("Unable to check ‘%s’ against manifest. %s\n", targetURL, NewIodine(iodine.New(err, nil)))
+	}
+	console.PrintC(message)
+	if message.Failed > 0 {
+		os.Exit(exitPartialFailure)
+	}
+}
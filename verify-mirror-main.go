@@ -0,0 +1,119 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"math/rand"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var verifyMirrorCmd = cli.Command{
+	Name:        "verify-mirror",
+	Usage:       "Continuously compare a sample of source objects with their mirror and alert on drift",
+	Description: "Objects are compared by presence, size and ETag where both sides have a content hash. Nothing is written to source or target",
+	Action:      runVerifyMirrorCmd,
+	Flags:       []cli.Flag{sampleFlag, thresholdFlag, everyFlag, onceFlag, webhookFlag},
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} SOURCE TARGET {{if .Description}}
+
+DESCRIPTION:
+   {{.Description}}{{end}}{{if .Flags}}
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}{{ end }}
+
+EXAMPLES:
+   1. Compare 10% of the objects of a bucket with its replica every 10 minutes, posting alerts to a webhook.
+      $ mc {{.Name}} --webhook https://alerts.example.com/mc s3:photos https://play.minio.io:9000/photos
+
+   2. Check every object of a local backup once from a cron job, tolerating up to 5 drifted files.
+      $ mc {{.Name}} --once --sample 100 --threshold 5 /var/www s3:backup/www
+
+`,
+}
+
+// runVerifyMirrorCmd - is a handler for mc verify-mirror command
+func runVerifyMirrorCmd(ctx *cli.Context) {
+	if len(ctx.Args()) != 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "verify-mirror", exitUsage) // last argument is exit code
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	URLs, err := args2URLs(ctx.Args())
+	if err != nil {
+		console.Fatalf("One or more unknown URL types found %s. %s\n", ctx.Args(), err)
+	}
+	if isURLRecursive(URLs[0]) || isURLRecursive(URLs[1]) {
+		console.Fatalf("Source and target are compared recursively, remove ‘%s’. %s\n", recursiveSeparator, errInvalidArgument{})
+	}
+	sample := ctx.Float64("sample")
+	if sample <= 0 || sample > 100 || ctx.Int("threshold") < 0 || ctx.Duration("every") <= 0 {
+		console.Fatalf("Sample must be in (0, 100], threshold and wait between passes positive. %s\n", errInvalidArgument{})
+	}
+	config := verifyMirrorConfig{
+		SourceURL: URLs[0],
+		TargetURL: URLs[1],
+		Sample:    sample,
+		Threshold: ctx.Int("threshold"),
+		Webhook:   ctx.String("webhook"),
+		Seed:      globalClock.Now().UnixNano(),
+	}
+	random := rand.New(rand.NewSource(config.Seed))
+
+	stopCh := signalTrap(os.Interrupt, syscall.SIGTERM)
+	for {
+		pass, err := doVerifyMirrorPass(config, random)
+		if err != nil {
+			// unreachable source or target is worth an alert too, keep trying in daemon mode
+			console.Errorf("Unable to compare ‘%s’ with ‘%s’. %s\n", config.SourceURL, config.TargetURL, NewIodine(iodine.New(err, nil)))
+			if ctx.Bool("once") {
+				exitCode, _ := getErrorCode(err)
+				os.Exit(exitCode)
+			}
+		} else {
+			console.PrintC(pass)
+			if pass.Alert && config.Webhook != "" {
+				if err := postMirrorAlert(config.Webhook, pass); err != nil {
+					console.Errorln(NewIodine(iodine.New(err, nil)))
+				}
+			}
+			if ctx.Bool("once") {
+				if pass.Alert {
+					os.Exit(exitPartialFailure)
+				}
+				return
+			}
+		}
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(ctx.Duration("every")):
+		}
+	}
+}
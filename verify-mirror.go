@@ -0,0 +1,139 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// reasons a target object drifted from its source
+const (
+	driftMissing = "missing"
+	driftType    = "type"
+	driftSize    = "size"
+	driftETag    = "etag"
+)
+
+// maxReportedDrifts - drifted objects listed per pass, all of them are counted
+const maxReportedDrifts = 100
+
+type verifyMirrorConfig struct {
+	SourceURL string
+	TargetURL string
+	Sample    float64 // percentage of source objects compared per pass
+	Threshold int     // drifted objects tolerated before alerting
+	Webhook   string
+	Seed      int64
+}
+
+type mirrorDrift struct {
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
+}
+
+// isETagComparable - multipart and missing ETags are not content hashes
+func isETagComparable(etag string) bool {
+	return etag != "" && !strings.Contains(etag, "-")
+}
+
+// compareMirrorObject - reason target drifted from source, empty if they match
+func compareMirrorObject(sourceContent *client.Content, targetURL string) string {
+	_, targetContent, err := url2Stat(targetURL)
+	if err != nil {
+		return driftMissing
+	}
+	switch {
+	case !targetContent.Type.IsRegular():
+		return driftType
+	case sourceContent.Size != targetContent.Size:
+		return driftSize
+	case isETagComparable(sourceContent.ETag) && isETagComparable(targetContent.ETag) &&
+		strings.Trim(sourceContent.ETag, "\"") != strings.Trim(targetContent.ETag, "\""):
+		return driftETag
+	}
+	return ""
+}
+
+// doVerifyMirrorPass - compare a random sample of source objects with their copies under target,
+// nothing is written to either side
+func doVerifyMirrorPass(config verifyMirrorConfig, random *rand.Rand) (VerifyMirrorMessage, error) {
+	// names of recursive listings are relative to the listed folder when it ends with a separator
+	sourceURL := config.SourceURL
+	if u, err := client.Parse(sourceURL); err == nil && !strings.HasSuffix(sourceURL, string(u.Separator)) {
+		sourceURL += string(u.Separator)
+	}
+	sourceClnt, err := url2Client(sourceURL)
+	if err != nil {
+		return VerifyMirrorMessage{}, NewIodine(iodine.New(err, nil))
+	}
+	pass := VerifyMirrorMessage{
+		Source: config.SourceURL,
+		Target: config.TargetURL,
+		Start:  globalClock.Now().UTC(),
+	}
+	for contentCh := range sourceClnt.List(true) {
+		if contentCh.Err != nil {
+			return VerifyMirrorMessage{}, NewIodine(iodine.New(contentCh.Err, nil))
+		}
+		if !contentCh.Content.Type.IsRegular() {
+			continue
+		}
+		if config.Sample < 100 && random.Float64()*100 >= config.Sample {
+			continue
+		}
+		targetURL, err := urlJoinPath(config.TargetURL, contentCh.Content.Name)
+		if err != nil {
+			return VerifyMirrorMessage{}, NewIodine(iodine.New(err, nil))
+		}
+		pass.Checked++
+		reason := compareMirrorObject(contentCh.Content, targetURL)
+		if reason == "" {
+			continue
+		}
+		pass.Drifted++
+		if len(pass.Drifts) < maxReportedDrifts {
+			pass.Drifts = append(pass.Drifts, mirrorDrift{URL: targetURL, Reason: reason})
+		}
+	}
+	pass.Alert = pass.Drifted > config.Threshold
+	return pass, nil
+}
+
+// postMirrorAlert - send drift alert as JSON to a webhook
+func postMirrorAlert(webhook string, message VerifyMirrorMessage) error {
+	message.Version = "1.0.0"
+	data, err := json.Marshal(message)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	res, err := http.Post(webhook, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return NewIodine(iodine.New(errWebhookFailed{URL: webhook, Status: res.Status}, nil))
+	}
+	return nil
+}
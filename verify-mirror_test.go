@@ -0,0 +1,81 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestVerifyMirror(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	source := filepath.Join(root, "source")
+	target := filepath.Join(root, "target")
+	for _, name := range []string{"a.txt", "nested/b.txt", "nested/c.txt", "d.txt"} {
+		c.Assert(putTarget(filepath.Join(source, name), 5, bytes.NewReader([]byte("hello"))), IsNil)
+	}
+	c.Assert(putTarget(filepath.Join(target, "a.txt"), 5, bytes.NewReader([]byte("hello"))), IsNil)
+	c.Assert(putTarget(filepath.Join(target, "nested", "b.txt"), 5, bytes.NewReader([]byte("hello"))), IsNil)
+	c.Assert(putTarget(filepath.Join(target, "nested", "c.txt"), 3, bytes.NewReader([]byte("hel"))), IsNil)
+
+	config := verifyMirrorConfig{SourceURL: source, TargetURL: target, Sample: 100, Threshold: 1}
+	pass, err := doVerifyMirrorPass(config, rand.New(rand.NewSource(1)))
+	c.Assert(err, IsNil)
+	c.Assert(pass.Checked, Equals, 4)
+	c.Assert(pass.Drifted, Equals, 2)
+	c.Assert(pass.Alert, Equals, true)
+	drifts := make(map[string]string)
+	for _, drift := range pass.Drifts {
+		drifts[drift.URL] = drift.Reason
+	}
+	c.Assert(drifts, DeepEquals, map[string]string{
+		filepath.Join(target, "nested", "c.txt"): driftSize,
+		filepath.Join(target, "d.txt"):           driftMissing,
+	})
+
+	// a small sample checks fewer objects
+	config.Sample = 1
+	pass, err = doVerifyMirrorPass(config, rand.New(rand.NewSource(1)))
+	c.Assert(err, IsNil)
+	c.Assert(pass.Checked < 4, Equals, true)
+
+	// alerts are posted as JSON
+	var alert VerifyMirrorMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(json.NewDecoder(r.Body).Decode(&alert), IsNil)
+	}))
+	defer server.Close()
+	c.Assert(postMirrorAlert(server.URL, VerifyMirrorMessage{Source: source, Drifted: 2, Alert: true}), IsNil)
+	c.Assert(alert.Source, Equals, source)
+	c.Assert(alert.Drifted, Equals, 2)
+	c.Assert(alert.Version, Equals, "1.0.0")
+
+	failing := httptest.NewServer(http.NotFoundHandler())
+	defer failing.Close()
+	c.Assert(postMirrorAlert(failing.URL, VerifyMirrorMessage{}), Not(IsNil))
+}
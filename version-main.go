@@ -0,0 +1,119 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var versionCmd = cli.Command{
+	Name:   "version",
+	Usage:  "Manage versioning of buckets",
+	Action: runVersionCmd,
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} enable|suspend|info TARGET [TARGET...]
+
+DESCRIPTION:
+   Versioned buckets keep every version of their objects, removed objects can be restored with ‘mc undelete’.
+   Versioning cannot be turned off once enabled, only suspended. Objects written while it is suspended
+   replace their latest version.
+
+EXAMPLES:
+   1. Enable versioning of a bucket on Amazon S3 object storage.
+      $ mc {{.Name}} enable s3:contracts
+
+   2. Suspend versioning of a bucket on Minio object storage.
+      $ mc {{.Name}} suspend https://play.minio.io:9000/scratch
+
+   3. Show whether buckets are versioned, as JSON for scripts.
+      $ mc --json {{.Name}} info s3:contracts s3:scratch
+`,
+}
+
+// versioningStatus - S3 versioning status of each version subcommand which changes it
+var versioningStatus = map[string]string{
+	"enable":  "Enabled",
+	"suspend": "Suspended",
+}
+
+// runVersionCmd - is a handler for mc version command
+func runVersionCmd(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "version", exitUsage) // last argument is exit code
+	}
+	operation := ctx.Args().First()
+	if _, ok := versioningStatus[operation]; !ok && operation != "info" {
+		console.Fatalf("Unknown operation ‘%s’, please choose from [enable, suspend, info]. %s\n", operation, errInvalidArgument{})
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	config := mustGetMcConfig()
+	for _, arg := range ctx.Args().Tail() {
+		targetURL, err := getExpandedURL(arg, config.Aliases)
+		if err != nil {
+			switch e := iodine.ToError(err).(type) {
+			case errUnsupportedScheme:
+				console.Fatalf("Unknown type of URL %s. %s\n", e.url, err)
+			default:
+				console.Fatalf("Unable to parse argument %s. %s\n", arg, err)
+			}
+		}
+		message, err := doVersionCmd(targetURL, versioningStatus[operation])
+		if err != nil {
+			console.Fatalf("Unable to %s versioning of ‘%s’. %s\n", operation, targetURL, err)
+		}
+		console.Print(message)
+	}
+}
+
+// doVersionCmd - set versioning of the bucket of targetURL to status, with an empty status only read it
+func doVersionCmd(targetURL, status string) (VersioningMessage, error) {
+	message := VersioningMessage{Target: targetURL}
+	clnt, err := target2Client(targetURL)
+	if err != nil {
+		return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+	}
+	versioning, ok := clnt.(client.Versioning)
+	if !ok {
+		return message, NewIodine(iodine.New(errVersionsNotSupported{URL: targetURL}, nil))
+	}
+	if status != "" {
+		if err := versioning.SetVersioning(status); err != nil {
+			return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+		}
+	} else {
+		status, err = versioning.GetVersioning()
+		if err != nil {
+			return message, NewIodine(iodine.New(err, map[string]string{"Target": targetURL}))
+		}
+	}
+	message.Status = strings.ToLower(status)
+	if message.Status == "" {
+		message.Status = "unversioned"
+	}
+	return message, nil
+}
@@ -11,6 +11,9 @@ import (
 // Version autogenerated
 var Version = "2015-06-24T21:56:09.753217354Z"
 
+// CommitID autogenerated
+var CommitID = ""
+
 // Tag is of following format
 //
 //   [STRING]-[EPOCH]
@@ -0,0 +1,48 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/minio/mc/pkg/fakes3"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestVersion(c *C) {
+	server := fakes3.NewServer("bucket")
+	defer server.Close()
+	server.SetDocument("/bucket", "versioning", []byte(`<VersioningConfiguration><Status>Suspended</Status></VersioningConfiguration>`))
+
+	message, err := doVersionCmd(server.URL+"/bucket", "")
+	c.Assert(err, IsNil)
+	c.Assert(message.Status, Equals, "suspended")
+	c.Assert(message.String(), Equals, "Versioning of ‘"+server.URL+"/bucket’ is suspended.\n")
+	message, err = doVersionCmd(server.URL+"/bucket", versioningStatus["enable"])
+	c.Assert(err, IsNil)
+	c.Assert(message.Status, Equals, "enabled")
+	status, _ := server.Document("/bucket", "versioning")
+	c.Assert(string(status), Equals, "<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>")
+
+	// the status is not changed when the server refuses it
+	server.AddFault(fakes3.Fault{Method: "PUT", Subresource: "versioning", Status: http.StatusForbidden, Code: "AccessDenied", Times: 1})
+	_, err = doVersionCmd(server.URL+"/bucket", versioningStatus["suspend"])
+	exitCode, _ := getErrorCode(err)
+	c.Assert(exitCode, Equals, exitPermission)
+	status, _ = server.Document("/bucket", "versioning")
+	c.Assert(string(status), Equals, "<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>")
+}
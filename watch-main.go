@@ -0,0 +1,249 @@
+/*
+ * Minio Client, (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// Help message.
+var watchCmd = cli.Command{
+	Name:        "watch",
+	Usage:       "Print changes of files below a folder as they happen",
+	Description: "Local folders are watched with inotify on Linux, kqueue on BSD and OS X and ReadDirectoryChangesW on Windows, buckets with Minio Listen Bucket Notification",
+	Action:      runWatchCmd,
+	Flags:       []cli.Flag{replaySinceFlag, execFlag, forwardToFlag},
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}}{{if .Flags}} [ARGS...]{{end}} TARGET {{if .Description}}
+
+DESCRIPTION:
+   {{.Description}}{{end}}{{if .Flags}}
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}{{ end }}
+
+EXAMPLES:
+   1. Print files created or removed in a local folder.
+      $ mc {{.Name}} /var/spool/uploads
+
+   2. Print changes of a folder and all folders below it as JSON.
+      $ mc --json {{.Name}} photos/...
+
+   3. Catch up on uploads to a Minio bucket since the consumer went down, then keep printing new ones.
+      $ mc --json {{.Name}} --replay-since 2015-06-01T08:00:00Z https://play.minio.io:9000/uploads/...
+
+   4. Generate a thumbnail of every photo uploaded to a bucket, the command runs once per event.
+      $ mc {{.Name}} --exec 'convert-thumbnail "$MC_EVENT_URL"' https://s3.amazonaws.com/photos/...
+
+   5. Deliver events of a bucket to a consumer under development on the local machine.
+      $ mc {{.Name}} --forward-to http://localhost:8080/events https://play.minio.io:9000/uploads/...
+
+`,
+}
+
+// runWatchCmd - is a handler for mc watch command
+func runWatchCmd(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "watch", exitUsage) // last argument is exit code
+	}
+	if !isMcConfigAvailable() {
+		console.Fatalf("Please run \"mc config generate\". %s\n", errNotConfigured{})
+	}
+	URLs, err := args2URLs(ctx.Args())
+	if err != nil {
+		console.Fatalf("One or more unknown URL types found %s. %s\n", ctx.Args(), err)
+	}
+	targetURL := URLs[0]
+	recursive := isURLRecursive(targetURL)
+	targetURL = stripRecursiveURL(targetURL)
+	var since time.Time
+	if ctx.String("replay-since") != "" {
+		since, err = parseSince(ctx.String("replay-since"))
+		if err != nil {
+			console.Fatalf("Invalid date ‘%s’, use 2015-06-01 or RFC3339 format. %s\n", ctx.String("replay-since"), NewIodine(iodine.New(err, nil)))
+		}
+	}
+
+	command := ctx.String("exec")
+	endpoint := ctx.String("forward-to")
+	if endpoint != "" {
+		if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			console.Fatalf("Invalid endpoint ‘%s’, use an http or https URL. %s\n", endpoint, NewIodine(iodine.New(errInvalidURL{URL: endpoint}, nil)))
+		}
+	}
+
+	doneCh := make(chan struct{})
+	eventCh, err := watchURL(targetURL, recursive, since, doneCh)
+	if err != nil {
+		console.Fatalf("Unable to watch ‘%s’. %s\n", targetURL, err)
+	}
+	trapCh := signalTrap(os.Interrupt, syscall.SIGTERM)
+	for {
+		select {
+		case <-trapCh:
+			close(doneCh)
+			return
+		case event, ok := <-eventCh:
+			if !ok {
+				return
+			}
+			if event.Err != nil {
+				console.Errorln(NewIodine(iodine.New(event.Err, nil)))
+				continue
+			}
+			console.PrintC(WatchMessage{Time: event.Time, Type: event.Type, URL: event.URL, Size: event.Size})
+			if endpoint != "" {
+				if err := forwardEvent(endpoint, event); err != nil {
+					console.Errorf("Unable to forward ‘%s’ to ‘%s’. %s\n", event.URL, endpoint, err)
+				}
+			}
+			if command != "" {
+				if err := execEventCommand(command, event); err != nil {
+					console.Errorf("Command for ‘%s’ failed. %s\n", event.URL, err)
+				}
+			}
+		}
+	}
+}
+
+// watchURL - changes below URL until doneCh is closed, preceded by the changes since the time the server
+// kept unless it is zero
+func watchURL(targetURL string, recursive bool, since time.Time, doneCh <-chan struct{}) (<-chan client.EventInfo, error) {
+	clnt, err := url2Client(targetURL)
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	if !since.IsZero() {
+		replayer, ok := clnt.(client.Replayer)
+		if !ok {
+			return nil, NewIodine(iodine.New(errReplayNotSupported{URL: targetURL}, nil))
+		}
+		eventCh, err := replayer.WatchSince(since, recursive, doneCh)
+		if _, ok := iodine.ToError(err).(client.APINotImplemented); ok {
+			return nil, NewIodine(iodine.New(errReplayNotSupported{URL: targetURL}, nil))
+		}
+		if err != nil {
+			return nil, NewIodine(iodine.New(err, nil))
+		}
+		return eventCh, nil
+	}
+	watcher, ok := clnt.(client.Watcher)
+	if !ok {
+		return nil, NewIodine(iodine.New(errWatchNotSupported{URL: targetURL}, nil))
+	}
+	eventCh, err := watcher.Watch(recursive, doneCh)
+	if _, ok := iodine.ToError(err).(client.APINotImplemented); ok {
+		return nil, NewIodine(iodine.New(errWatchNotSupported{URL: targetURL}, nil))
+	}
+	if err != nil {
+		return nil, NewIodine(iodine.New(err, nil))
+	}
+	return eventCh, nil
+}
+
+// execEventCommand - run command through the shell with the fields of event in its environment, events
+// are handled one at a time. Output of the command goes to standard error in JSON mode, to keep the
+// standard output a stream of events.
+func execEventCommand(command string, event client.EventInfo) error {
+	cmd := shellCommand(command)
+	cmd.Env = append(os.Environ(),
+		"MC_EVENT_TYPE="+event.Type,
+		"MC_EVENT_URL="+event.URL,
+		"MC_EVENT_TIME="+event.Time.UTC().Format(time.RFC3339Nano),
+		"MC_EVENT_SIZE="+strconv.FormatInt(event.Size, 10),
+	)
+	var stdout io.Writer = os.Stdout
+	if globalJSONFlag {
+		stdout = os.Stderr
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return NewIodine(iodine.New(err, map[string]string{"Command": command}))
+	}
+	return nil
+}
+
+// Deliveries of events failing with a network error or a server error are retried, waiting twice as long
+// before each retry.
+var (
+	forwardRetries    = 3
+	forwardRetryDelay = time.Second
+	forwardClient     = &http.Client{Timeout: 30 * time.Second}
+)
+
+// forwardEvent - POST event to endpoint as the JSON of its watch message
+func forwardEvent(endpoint string, event client.EventInfo) error {
+	body, err := json.Marshal(WatchMessage{Version: "1.0.0", Time: event.Time, Type: event.Type, URL: event.URL, Size: event.Size})
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	delay := forwardRetryDelay
+	for retry := 0; ; retry++ {
+		err = postEvent(endpoint, body)
+		if err == nil {
+			return nil
+		}
+		// the endpoint refused the event, sending it again gets the same answer
+		if e, ok := iodine.ToError(err).(errForwardFailed); ok && !strings.HasPrefix(e.Status, "5") {
+			return err
+		}
+		if retry == forwardRetries {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// postEvent - a single delivery of body, any status but 2xx is a failure
+func postEvent(endpoint string, body []byte) error {
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := forwardClient.Do(req)
+	if err != nil {
+		return NewIodine(iodine.New(err, nil))
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return NewIodine(iodine.New(errForwardFailed{URL: endpoint, Status: resp.Status}, nil))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	. "gopkg.in/check.v1"
+)
+
+func (s *CmdTestSuite) TestExecEventCommand(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	output := filepath.Join(root, "event.txt")
+
+	event := client.EventInfo{
+		Type: client.EventCreate,
+		URL:  "https://s3.amazonaws.com/photos/a b.jpg",
+		Time: time.Date(2015, 6, 1, 8, 0, 0, 0, time.UTC),
+		Size: 1024,
+	}
+	err = execEventCommand(`echo "$MC_EVENT_TYPE|$MC_EVENT_URL|$MC_EVENT_TIME|$MC_EVENT_SIZE" > "`+output+`"`, event)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadFile(output)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "ObjectCreated|https://s3.amazonaws.com/photos/a b.jpg|2015-06-01T08:00:00Z|1024\n")
+
+	// commands exiting with a failure are reported
+	c.Assert(execEventCommand("exit 3", event), NotNil)
+}
+
+func (s *CmdTestSuite) TestForwardEvent(c *C) {
+	defer func(delay time.Duration) { forwardRetryDelay = delay }(forwardRetryDelay)
+	forwardRetryDelay = time.Millisecond
+
+	var received []WatchMessage
+	status := []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK, http.StatusBadRequest}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Assert(r.Method, Equals, "POST")
+		c.Assert(r.Header.Get("Content-Type"), Equals, "application/json")
+		var message WatchMessage
+		c.Assert(json.NewDecoder(r.Body).Decode(&message), IsNil)
+		received = append(received, message)
+		w.WriteHeader(status[len(received)-1])
+	}))
+	defer server.Close()
+
+	event := client.EventInfo{
+		Type: client.EventRemove,
+		URL:  "https://s3.amazonaws.com/photos/a.jpg",
+		Time: time.Date(2015, 6, 1, 8, 0, 0, 0, time.UTC),
+	}
+	// server errors are retried until delivered
+	c.Assert(forwardEvent(server.URL+"/events", event), IsNil)
+	c.Assert(received, HasLen, 3)
+	c.Assert(received[2].Type, Equals, client.EventRemove)
+	c.Assert(received[2].URL, Equals, event.URL)
+	c.Assert(received[2].Time.Equal(event.Time), Equals, true)
+
+	// rejected events are not
+	err := forwardEvent(server.URL+"/events", event)
+	c.Assert(err, NotNil)
+	c.Assert(received, HasLen, 4)
+}